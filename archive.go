@@ -0,0 +1,227 @@
+package cfg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jayacarlson/dbg"
+)
+
+type (
+	// archive holds the readers of the regular files of a zip or tar
+	// archive by their slash separated paths, each reading no more than max
+	// bytes when it's set
+	archive struct {
+		path  string
+		max   int64
+		files map[string]func() ([]byte, error)
+		close func() error
+	}
+)
+
+var (
+	ErrNotInArchive = errors.New("Config file not found in archive")
+)
+
+/*
+	Loads the config file at innerPath (slash separated) inside the zip or
+	 tar archive, so an application package can ship its default configs
+	 without them being extracted, e.g.
+
+		c, err := cfg.LoadConfigArchive("app.zip", "conf/app.cfg")
+
+	A tar archive may be gzip compressed (or use any registered
+	 decompressor, see RegisterDecompressor).  Includes are read from the
+	 same archive, relative to the including file unless they start with a
+	 '/'; a glob include matches nothing inside an archive.  The config's
+	 Source, and the names in any *IncludeError, are the inner path joined
+	 to the archive's path, e.g. app.zip/conf/app.cfg
+*/
+func LoadConfigArchive(archivePath, innerPath string) (*Config, error) {
+	return Options{}.LoadConfigArchive(archivePath, innerPath)
+}
+
+/*
+	As LoadConfigArchive, using these options; an Include set in the
+	 options is used in place of reading includes from the archive.  Only
+	 the files needed are read from the archive, MaxSize limiting each
+*/
+func (o Options) LoadConfigArchive(archivePath, innerPath string) (*Config, error) {
+	name, data, err := o.readArchived(archivePath, innerPath)
+	if nil != err {
+		return nil, err
+	}
+	c, err := o.parse(data)
+	if nil == c {
+		return nil, err
+	}
+	c.source = name
+	return c, err
+}
+
+/*
+	Reads the config file inside the archive and passes it to the handler
+	 as HandleConfigData does, see LoadConfigArchive
+*/
+func LoadConfigDataArchive(archivePath, innerPath string, f func(t ConfigType, label string, data []string)) error {
+	_, data, err := Options{}.readArchived(archivePath, innerPath)
+	if nil != err {
+		return err
+	}
+	return Options{}.handleConfigData("", data, f)
+}
+
+// ------------------------------------------------------------------------- //
+
+// readArchived returns the name and preprocessed text of the config file
+// inside the archive
+func (o Options) readArchived(archivePath, innerPath string) (string, string, error) {
+	a, err := openArchive(archivePath, o.MaxSize)
+	if dbg.ChkErr(err, "Failed to read config archive: %s (%v)", archivePath, err) {
+		return "", "", err
+	}
+	defer a.close()
+	if nil == o.Include {
+		o.Include = a.include
+	}
+	name, data, err := a.read(innerPath)
+	if nil != err {
+		return "", "", err
+	}
+	data, err = o.preprocess([]string{name}, data)
+	return name, data, err
+}
+
+// openArchive indexes the regular files of the archive, each read (no more
+// than max bytes of it when set) only when it's needed: a zip is read in
+// place, and a tar streamed again up to the file
+func openArchive(archivePath string, max int64) (*archive, error) {
+	f, err := os.Open(archivePath)
+	if nil != err {
+		return nil, err
+	}
+	magic := make([]byte, 4)
+	n, _ := io.ReadFull(f, magic)
+	f.Close()
+	a := &archive{archivePath, max, make(map[string]func() ([]byte, error)), func() error { return nil }}
+	if bytes.HasPrefix(magic[:n], []byte("PK\x03\x04")) || bytes.HasPrefix(magic[:n], []byte("PK\x05\x06")) {
+		zr, err := zip.OpenReader(archivePath)
+		if nil != err {
+			return nil, err
+		}
+		a.close = zr.Close
+		for _, zf := range zr.File {
+			if !zf.Mode().IsRegular() {
+				continue
+			}
+			zf := zf
+			a.files[path.Clean(zf.Name)] = func() ([]byte, error) {
+				r, err := zf.Open()
+				if nil != err {
+					return nil, err
+				}
+				defer r.Close()
+				return readAll(r, a.name(zf.Name), max)
+			}
+		}
+		return a, nil
+	}
+	err = a.walkTar(func(i int, hdr *tar.Header, _ io.Reader) (bool, error) {
+		if hdr.FileInfo().Mode().IsRegular() {
+			name := hdr.Name
+			a.files[path.Clean(name)] = func() (data []byte, err error) {
+				err = a.walkTar(func(j int, _ *tar.Header, r io.Reader) (bool, error) {
+					if i != j {
+						return false, nil
+					}
+					data, err = readAll(r, a.name(name), max)
+					return true, err
+				})
+				return data, err
+			}
+		}
+		return false, nil
+	})
+	if nil != err {
+		return nil, err
+	}
+	return a, nil
+}
+
+// walkTar streams the tar archive, decompressing it as it's read, calling f
+// with the index, header and reader of each entry until f returns true
+func (a *archive) walkTar(f func(i int, hdr *tar.Header, r io.Reader) (bool, error)) error {
+	fl, err := os.Open(a.path)
+	if nil != err {
+		return err
+	}
+	defer fl.Close()
+	br := bufio.NewReader(fl)
+	magic, _ := br.Peek(8)
+	fn, err := findDecompressor(magic)
+	if nil != err {
+		return err
+	}
+	var r io.Reader = br
+	if nil != fn {
+		if r, err = fn(br); nil != err {
+			return err
+		}
+		if rc, ok := r.(io.Closer); ok {
+			defer rc.Close()
+		}
+	}
+	tr := tar.NewReader(r)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if io.EOF == err {
+			return nil
+		}
+		if nil != err {
+			return err
+		}
+		if done, err := f(i, hdr, tr); done || nil != err {
+			return err
+		}
+	}
+}
+
+// read returns the name and text of the file at the slash separated path
+func (a *archive) read(innerPath string) (string, string, error) {
+	inner := path.Clean(strings.TrimPrefix(filepath.ToSlash(innerPath), "/"))
+	name := a.name(inner)
+	fn, ok := a.files[inner]
+	if !ok {
+		return name, "", &PathError{name, ErrNotInArchive}
+	}
+	data, err := fn()
+	if nil != err {
+		return name, "", err
+	}
+	text, err := decodeText(name, data, a.max)
+	return name, text, err
+}
+
+// include is the IncludeFunc of a config read from the archive, reading a
+// relative path from the directory of the including file
+func (a *archive) include(from, incPath string) (string, string, error) {
+	incPath = filepath.ToSlash(incPath)
+	if !strings.HasPrefix(incPath, "/") {
+		from = strings.TrimPrefix(filepath.ToSlash(from), filepath.ToSlash(a.path)+"/")
+		incPath = path.Join(path.Dir(from), incPath)
+	}
+	return a.read(incPath)
+}
+
+// name returns the name of an inner path, joined to the archive's path
+func (a *archive) name(innerPath string) string {
+	return filepath.Join(a.path, filepath.FromSlash(innerPath))
+}
@@ -0,0 +1,105 @@
+package cfg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+var (
+	archiveFiles = map[string]string{
+		"conf/app.cfg":      "name := app\n@include db.cfg\n@include /shared/log.cfg\n",
+		"conf/db.cfg":       "db (\n\tport := 5432\n)\n",
+		"shared/log.cfg":    "level := info\n",
+		"conf/missing.cfg":  "@include none.cfg\n",
+		"conf/cycle.cfg":    "@include cycle.cfg\n",
+		"conf/utf8bom.cfg":  "\xef\xbb\xbfbom := yes\n",
+		"conf/oversize.cfg": "big := 0123456789012345678901234567890123456789\n",
+		"conf/incbig.cfg":   "@include oversize.cfg\n",
+	}
+)
+
+// writeArchives writes the archive files as a zip, a tar and a tar.gz
+func writeArchives(dir string) []string {
+	var zb, tb bytes.Buffer
+	zw, tw := zip.NewWriter(&zb), tar.NewWriter(&tb)
+	for name, data := range archiveFiles {
+		w, _ := zw.Create(name)
+		w.Write([]byte(data))
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(data))
+	}
+	tw.WriteHeader(&tar.Header{Name: "conf/", Mode: 0755, Typeflag: tar.TypeDir})
+	zw.Close()
+	tw.Close()
+	var gb bytes.Buffer
+	gw := gzip.NewWriter(&gb)
+	gw.Write(tb.Bytes())
+	gw.Close()
+
+	paths := []string{filepath.Join(dir, "app.zip"), filepath.Join(dir, "app.tar"), filepath.Join(dir, "app.tgz")}
+	for i, b := range []*bytes.Buffer{&zb, &tb, &gb} {
+		ioutil.WriteFile(paths[i], b.Bytes(), 0644)
+	}
+	return paths
+}
+
+func TestLoadConfigArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+
+	for _, fl := range writeArchives(dir) {
+		c, err := LoadConfigArchive(fl, "conf/app.cfg")
+		if nil != err || "app" != c.ValueOr("name", "") || "5432" != c.ValueOr("db:port", "") || "info" != c.ValueOr("level", "") ||
+			filepath.Join(fl, "conf", "app.cfg") != c.Source() {
+			dbg.Error("%s: %v", fl, err)
+			t.Fail()
+			continue
+		}
+		values := map[string]string{}
+		err = LoadConfigDataArchive(fl, "/conf/utf8bom.cfg", func(t ConfigType, label string, data []string) {
+			values[label] = data[0]
+		})
+		if nil != err || "yes" != values["bom"] {
+			dbg.Error("%s data: %v %v", fl, err, values)
+			t.Fail()
+		}
+
+		var ie *IncludeError
+		if _, err := LoadConfigArchive(fl, "conf/missing.cfg"); !errors.As(err, &ie) || !errors.Is(err, ErrNotInArchive) {
+			dbg.Error("%s missing include: %v", fl, err)
+			t.Fail()
+		}
+		if _, err := LoadConfigArchive(fl, "conf/cycle.cfg"); !errors.Is(err, ErrIncludeCycle) {
+			dbg.Error("%s cycle: %v", fl, err)
+			t.Fail()
+		}
+		if _, err := LoadConfigArchive(fl, "conf/none.cfg"); !errors.Is(err, ErrNotInArchive) {
+			dbg.Error("%s not in archive: %v", fl, err)
+			t.Fail()
+		}
+		var tl *TooLargeError
+		if _, err := (Options{MaxSize: 32}).LoadConfigArchive(fl, "conf/oversize.cfg"); !errors.As(err, &tl) {
+			dbg.Error("%s too large: %v", fl, err)
+			t.Fail()
+		}
+		_, err = Options{MaxSize: 32}.LoadConfigArchive(fl, "conf/incbig.cfg")
+		if !errors.As(err, &ie) || !errors.As(err, &tl) || filepath.Join(fl, "conf", "oversize.cfg") != tl.Path {
+			dbg.Error("%s include too large: %v", fl, err)
+			t.Fail()
+		}
+	}
+	if _, err := LoadConfigArchive(filepath.Join(dir, "none.zip"), "app.cfg"); !os.IsNotExist(err) {
+		dbg.Error("no archive: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,215 @@
+package cfg
+
+import (
+	"io"
+	"strings"
+)
+
+type (
+	/*
+		An ASTNode is a node of the syntax tree returned by ParseAST, one of
+		 *GroupNode, *ValueNode, *BlockNode, *LinesNode, *ItemsNode,
+		 *CommentNode or *BlankNode
+	*/
+	ASTNode interface {
+		Type() ConfigType   // ConfigGroup of a *GroupNode, ConfigComment of comments & blank lines
+		Label() string      // "" for the root, comments & blank lines
+		Path() string       // the label path of the node, that of its group for comments & blank lines
+		Parent() *GroupNode // nil for the root
+		Pos() Position
+	}
+
+	/*
+		Where a node starts in the config text, from 1; Col counts bytes
+	*/
+	Position struct {
+		Line, Col int
+	}
+
+	// astNode holds what every ASTNode has
+	astNode struct {
+		parent *GroupNode
+		label  string
+		pos    Position
+	}
+
+	/*
+		A ( ) group, inline or not, and the root of the tree
+	*/
+	GroupNode struct {
+		astNode
+		Children []ASTNode
+	}
+
+	/*
+		A label := value, or a multi-line :== value
+	*/
+	ValueNode struct {
+		astNode
+		Value string
+	}
+
+	/*
+		A < > or heredoc block; Binary is set for the decoded data of a <b64
+		 or <hex block
+	*/
+	BlockNode struct {
+		astNode
+		Text   string
+		Binary bool
+	}
+
+	/*
+		A [ ] lines, : [ ] dict or [table section, and that of a custom
+		 section; Type gives the ConfigType of its data
+	*/
+	LinesNode struct {
+		astNode
+		Lines []string
+		kind  ConfigType
+	}
+
+	/*
+		A { } items section, inline or not
+	*/
+	ItemsNode struct {
+		astNode
+		Items []string
+	}
+
+	/*
+		A comment line, as it is less the TABs of its group
+	*/
+	CommentNode struct {
+		astNode
+		Text string
+	}
+
+	/*
+		A run of blank lines
+	*/
+	BlankNode struct {
+		astNode
+		Lines int
+	}
+)
+
+/*
+	Parses the config text into a syntax tree keeping its comments and
+	 blank lines, for tools that need more than the data of a Config, the
+	 root being a *GroupNode with no label.  Comments inside a section and
+	 preprocessing directives aren't kept, see EventReader
+*/
+func ParseAST(src string) (*GroupNode, error) {
+	return Options{}.ParseAST(src)
+}
+
+/*
+	As ParseAST, with the labels, comment prefixes and sections allowed by
+	 these options
+*/
+func (o Options) ParseAST(src string) (*GroupNode, error) {
+	o.KeepComments = false
+	er := o.NewEventReader(strings.NewReader(src))
+	er.trivia = true
+	root := &GroupNode{astNode: astNode{pos: Position{1, 1}}}
+	g, depth := root, 0
+	for {
+		e, err := er.Next()
+		if io.EOF == err {
+			return root, nil
+		}
+		if nil != err {
+			return nil, err
+		}
+		n := astNode{g, e.Path[strings.LastIndex(e.Path, ":")+1:], Position{e.Line, depth + 1}}
+		var node ASTNode
+		switch {
+		case EventEndGroup == e.Kind:
+			g, depth = g.parent, depth-1
+			continue
+		case EventStartGroup == e.Kind:
+			sub := &GroupNode{astNode: n}
+			g.Children = append(g.Children, sub)
+			g, depth = sub, depth+1
+			continue
+		case eventBlank == e.Kind:
+			if 0 != len(g.Children) {
+				if b, ok := g.Children[len(g.Children)-1].(*BlankNode); ok {
+					b.Lines++
+					continue
+				}
+			}
+			n.label = ""
+			node = &BlankNode{n, 1}
+		case eventComment == e.Kind:
+			n.label = ""
+			node = &CommentNode{n, e.Data[0]}
+		case ConfigValue == e.Type:
+			node = &ValueNode{n, e.Data[0]}
+		case ConfigBlock == e.Type || ConfigBinary == e.Type:
+			node = &BlockNode{n, strings.Join(e.Data, "\n"), ConfigBinary == e.Type}
+		case ConfigItems == e.Type:
+			node = &ItemsNode{n, e.Data}
+		default:
+			node = &LinesNode{n, e.Data, e.Type}
+		}
+		g.Children = append(g.Children, node)
+	}
+}
+
+/*
+	Calls f for the node and, while f returns true, the children of each
+	 group in order
+*/
+func WalkAST(n ASTNode, f func(n ASTNode) bool) {
+	if !f(n) {
+		return
+	}
+	if g, ok := n.(*GroupNode); ok {
+		for _, c := range g.Children {
+			WalkAST(c, f)
+		}
+	}
+}
+
+func (n *astNode) Label() string      { return n.label }
+func (n *astNode) Parent() *GroupNode { return n.parent }
+func (n *astNode) Pos() Position      { return n.pos }
+
+func (n *astNode) Path() string {
+	if nil == n.parent {
+		return n.label
+	}
+	if "" == n.label {
+		return n.parent.Path()
+	}
+	return joinPath(n.parent.Path(), n.label)
+}
+
+func (g *GroupNode) Type() ConfigType   { return ConfigGroup }
+func (v *ValueNode) Type() ConfigType   { return ConfigValue }
+func (c *CommentNode) Type() ConfigType { return ConfigComment }
+func (b *BlankNode) Type() ConfigType   { return ConfigComment }
+func (i *ItemsNode) Type() ConfigType   { return ConfigItems }
+func (l *LinesNode) Type() ConfigType   { return l.kind }
+
+func (b *BlockNode) Type() ConfigType {
+	if b.Binary {
+		return ConfigBinary
+	}
+	return ConfigBlock
+}
+
+/*
+	Returns the children of the group with the label, in order
+*/
+func (g *GroupNode) Lookup(label string) []ASTNode {
+	var found []ASTNode
+	for _, c := range g.Children {
+		if "" != label && label == c.Label() {
+			found = append(found, c)
+		}
+	}
+	return found
+}
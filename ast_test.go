@@ -0,0 +1,65 @@
+package cfg
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestParseAST(t *testing.T) {
+	src := "# app\nname := app\n\n\nsrv (\n\t# hosts\n\thosts { a b }\n\tmotd <\nhi\n\t>\n\tpt ( x := 1 )\n)\nenv : [\n\ta : b\n]\n"
+	root, err := ParseAST(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	got := []string{}
+	WalkAST(root, func(n ASTNode) bool {
+		p := n.Pos()
+		desc := n.Path() + " " + strconv.Itoa(int(n.Type()))
+		switch v := n.(type) {
+		case *ValueNode:
+			desc += " " + v.Value
+		case *BlockNode:
+			desc += " " + v.Text
+		case *ItemsNode:
+			desc += " " + strings.Join(v.Items, ",")
+		case *LinesNode:
+			desc += " " + strings.Join(v.Lines, ",")
+		case *CommentNode:
+			desc += " " + v.Text
+		case *BlankNode:
+			desc += " " + strconv.Itoa(v.Lines)
+		}
+		got = append(got, strconv.Itoa(p.Line)+":"+strconv.Itoa(p.Col)+" "+desc)
+		return true
+	})
+	expect := []string{
+		"1:1  4",
+		"1:1  8 # app",
+		"2:1 name 3 app",
+		"3:1  8 2",
+		"5:1 srv 4",
+		"6:2 srv 8 # hosts",
+		"7:2 srv:hosts 2 a,b",
+		"8:2 srv:motd 0 hi",
+		"11:2 srv:pt 4",
+		"11:3 srv:pt:x 3 1",
+		"13:1 env 5 a,b",
+	}
+	if strings.Join(expect, "\n") != strings.Join(got, "\n") {
+		dbg.Error("nodes:\n%s", strings.Join(got, "\n"))
+		t.Fail()
+	}
+	srv := root.Lookup("srv")
+	if 1 != len(srv) || root != srv[0].Parent() || nil != root.Parent() {
+		dbg.Error("Lookup of srv: %v", srv)
+		t.Fail()
+	}
+	if _, err = ParseAST("a (\n\tb := 1\n"); nil == err {
+		dbg.Error("ParseAST of an unended 4")
+		t.Fail()
+	}
+}
@@ -0,0 +1,99 @@
+package cfg
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// the [ ] attributes between the label and opener of a section
+	// 1: attributes
+	attrsRex = regexp.MustCompile(`^[^\s\[]+[ \t]*\[([^\]\n]*)\][ \t]*[,:]?[ \t]*[<\[{(]`)
+)
+
+/*
+	Returns the attributes of the section at the label path, given in [ ]
+	 between its label and opener as names, each with an optional =value,
+	 separated by commas:
+
+		cache [ttl=5m, optional] (
+			size := 64MiB
+		)
+		servers [readonly] [
+			alpha
+		]
+
+	so schemas and tools can attach behavior to a section without magic
+	 labels inside it; a name without a value has "".  Nil when the section
+	 has no attributes (or the label path isn't found), see Node.Attrs
+*/
+func (c *Config) Attributes(path string) map[string]string {
+	n := c.node(c.opts.internalPath(path))
+	if nil == n {
+		return nil
+	}
+	return n.Attrs
+}
+
+// ------------------------------------------------------------------------- //
+
+// sectionAttributes returns the attributes of the section opened by the
+// first line of the text as name & value pairs, nil when it has none
+func sectionAttributes(text string) []string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	x := attrsRex.FindStringSubmatch(text)
+	if nil == x {
+		return nil
+	}
+	pairs := []string{}
+	for _, a := range strings.Split(x[1], ",") {
+		name, value := a, ""
+		if i := strings.IndexByte(a, '='); i >= 0 {
+			name, value = a[:i], a[i+1:]
+		}
+		if name = strings.TrimSpace(name); "" != name {
+			pairs = append(pairs, name, strings.TrimSpace(value))
+		}
+	}
+	return pairs
+}
+
+// stripAttributes returns the line opening a section without any attributes
+func stripAttributes(line string) string {
+	x := attrsRex.FindStringSubmatchIndex(line)
+	if nil == x {
+		return line
+	}
+	return strings.TrimRight(line[:x[2]-1], " \t") + " " + strings.TrimLeft(line[x[3]+1:], " \t")
+}
+
+// pairsToMap returns the name & value pairs as a map
+func pairsToMap(pairs []string) map[string]string {
+	m := make(map[string]string, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		m[pairs[i]] = pairs[i+1]
+	}
+	return m
+}
+
+// encodeAttributes returns the attributes as written after a label, sorted
+// by name, "" for none
+func encodeAttributes(attrs map[string]string) string {
+	if 0 == len(attrs) {
+		return ""
+	}
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if v := attrs[name]; "" != v {
+			names[i] = name + "=" + v
+		}
+	}
+	return " [" + strings.Join(names, ", ") + "]"
+}
@@ -0,0 +1,94 @@
+package cfg
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestAttributes(t *testing.T) {
+	src := "cache [ttl=5m, optional] (\n\tsize := 64\n\tnotes [lang = en] <\n\ttext\n\t>\n)\n" +
+		"servers [readonly] [\nalpha\n]\nhosts [platform=linux] , {\na b, c\n}\nplain (\n\tx := 1\n)\n"
+	c, err := Parse(src)
+	if nil != err {
+		dbg.Error("Attributes: %v", err)
+		t.FailNow()
+	}
+	expect := map[string]map[string]string{
+		"cache":       {"ttl": "5m", "optional": ""},
+		"cache:notes": {"lang": "en"},
+		"servers":     {"readonly": ""},
+		"hosts":       {"platform": "linux"},
+		"plain":       nil,
+		"cache:size":  nil,
+	}
+	for path, want := range expect {
+		if got := c.Attributes(path); !reflect.DeepEqual(want, got) {
+			dbg.Error("Attributes %s: %v", path, got)
+			t.Fail()
+		}
+	}
+	if v, _ := c.Value("cache:notes"); "text" != v {
+		dbg.Error("Attributes block: %q", v)
+		t.Fail()
+	}
+	if l, _ := c.GetStringList("hosts"); !reflect.DeepEqual([]string{"a b", "c"}, l) {
+		dbg.Error("Attributes items: %v", l)
+		t.Fail()
+	}
+
+	if p, err := (Options{ParallelSections: true}).Parse(src); nil != err || !reflect.DeepEqual(expect["cache"], p.Attributes("cache")) {
+		dbg.Error("Attributes ParallelSections: %v", err)
+		t.Fail()
+	}
+	if f, err := Format([]byte(src)); nil != err || !strings.Contains(string(f), "cache [ttl=5m, optional] (") {
+		dbg.Error("Attributes Format: %v\n%s", err, f)
+		t.Fail()
+	}
+
+	// written back and read again
+	var buf bytes.Buffer
+	c.WriteTo(&buf)
+	if !strings.Contains(buf.String(), "cache [optional, ttl=5m] (\n") {
+		dbg.Error("Attributes WriteTo:\n%s", buf.String())
+		t.Fail()
+	}
+	r, err := Parse(buf.String())
+	if nil != err || !reflect.DeepEqual(c.Attributes("cache:notes"), r.Attributes("cache:notes")) ||
+		!reflect.DeepEqual(c.Attributes("servers"), r.Attributes("servers")) {
+		dbg.Error("Attributes round trip: %v\n%s", err, buf.String())
+		t.Fail()
+	}
+
+	// a clone has its own attributes
+	clone := c.Clone()
+	clone.Lookup("cache").Attrs["ttl"] = "1h"
+	if "5m" != c.Attributes("cache")["ttl"] {
+		dbg.Error("Attributes shared by a clone: %v", c.Attributes("cache"))
+		t.Fail()
+	}
+
+	er := NewEventReader(strings.NewReader(src))
+	attrs := map[string]map[string]string{}
+	for e, err := er.Next(); nil == err; e, err = er.Next() {
+		if nil != e.Attrs {
+			attrs[e.Path] = e.Attrs
+		}
+	}
+	if 4 != len(attrs) || "5m" != attrs["cache"]["ttl"] || "en" != attrs["cache:notes"]["lang"] {
+		dbg.Error("Attributes events: %v", attrs)
+		t.Fail()
+	}
+
+	entries := 0
+	HandleConfigData(src, func(ct ConfigType, label string, data []string) {
+		entries++
+	})
+	if 5 != entries {
+		dbg.Error("Attributes HandleConfigData: %d entries", entries)
+		t.Fail()
+	}
+}
@@ -0,0 +1,60 @@
+package cfg
+
+import (
+	"reflect"
+	"strings"
+)
+
+/*
+	Sets each string field of the struct pointed to by dst that's tagged
+	 with a label path to the raw value found there, without the type
+	 conversions of Unmarshal, as a first step from HandleConfigValues
+	 callbacks to structured access:
+
+		var s struct {
+			Host string `cfg:"db:host"`
+			Port string `cfg:"db:port"`
+			Mode string `cfg:"mode,optional"`
+		}
+		err := cfg.Bind(c, &s)
+
+	Untagged fields are left alone, as are the fields of label paths not
+	 found; every path missing (unless tagged optional) or not a value is a
+	 *PathError, more than one being returned as a *MultiError so they can
+	 all be reported at once.  A tagged field that isn't a string is a
+	 *PathError wrapping ErrUnsupported
+*/
+func Bind(c *Config, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if reflect.Ptr != rv.Kind() || rv.IsNil() || reflect.Struct != rv.Elem().Kind() {
+		return ErrNotPointer
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	var errs []error
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("cfg")
+		if "" != sf.PkgPath || "" == tag || "-" == tag {
+			continue
+		}
+		path, optional := tag, false
+		if j := strings.IndexByte(tag, ','); j >= 0 {
+			path, optional = tag[:j], "optional" == tag[j+1:]
+		}
+		if reflect.String != sf.Type.Kind() {
+			errs = append(errs, &PathError{path, ErrUnsupported})
+			continue
+		}
+		v, err := c.value(path)
+		if pe, ok := err.(*PathError); ok && optional && ErrNoSuchLabel == pe.Err {
+			continue
+		}
+		if nil != err {
+			errs = append(errs, err)
+			continue
+		}
+		rv.Field(i).SetString(v)
+	}
+	return joinErrors(errs)
+}
@@ -0,0 +1,47 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestBind(t *testing.T) {
+	c, err := Parse("mode := fast\ndb (\n\thost := x\n\tport := 0x50\n\tports { 80 }\n)\nmotd <\nhi\n>\n")
+	dbg.ChkErr(err, "Parse")
+	var s struct {
+		Host  string `cfg:"db:host"`
+		Port  string `cfg:"db:port"`
+		Motd  string `cfg:"motd"`
+		Mode  string `cfg:"mode,optional"`
+		Level string `cfg:"log:level,optional"`
+		Other string
+		count int
+	}
+	s.Level, s.Other = "info", "kept"
+	if err := Bind(c, &s); nil != err || "x" != s.Host || "0x50" != s.Port || "hi" != s.Motd || "fast" != s.Mode ||
+		"info" != s.Level || "kept" != s.Other {
+		dbg.Error("Bind: %+v %v", s, err)
+		t.Fail()
+	}
+
+	var bad struct {
+		User  string `cfg:"db:user"`
+		Ports string `cfg:"db:ports"`
+		Pass  string `cfg:"db:pass"`
+		Port  int    `cfg:"db:port"`
+		Host  string `cfg:"db:host"`
+	}
+	err = Bind(c, &bad)
+	var me *MultiError
+	if !errors.As(err, &me) || 4 != len(me.Errs) || !errors.Is(me.Errs[0], ErrNoSuchLabel) || !errors.Is(me.Errs[1], ErrWrongType) ||
+		!errors.Is(me.Errs[3], ErrUnsupported) || "x" != bad.Host {
+		dbg.Error("Bind errors: %v", err)
+		t.Fail()
+	}
+	if err := Bind(c, s); ErrNotPointer != err {
+		dbg.Error("Bind of a non-pointer: %v", err)
+		t.Fail()
+	}
+}
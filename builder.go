@@ -0,0 +1,166 @@
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+type (
+	/*
+		A Builder creates a Config in code, each method adds an entry to the
+		 group being built and returns the Builder so calls can be chained:
+
+			b := cfg.NewBuilder()
+			b.Value("name", "app")
+			b.Group("db").Value("host", "x").Items("ports", "80", "443")
+
+		Group returns a Builder for the new group, End returns to the
+		 enclosing one.  The first illegal label is reported by Config and
+		 WriteTo, later calls are then ignored
+	*/
+	Builder struct {
+		c      *Config
+		group  *Node
+		parent *Builder
+		err    *error // shared by all the Builders of a config
+	}
+)
+
+var (
+	ErrIllegalLabel = errors.New("Illegal config label")
+)
+
+/*
+	Returns a Builder for an empty Config
+*/
+func NewBuilder() *Builder {
+	return Options{}.NewBuilder()
+}
+
+/*
+	Returns a Builder for an empty Config, the labels allowed and the form
+	 of its output are those given by the options
+*/
+func (o Options) NewBuilder() *Builder {
+	c := newConfig()
+	c.opts = o
+	return &Builder{c: c, group: &c.root, err: new(error)}
+}
+
+/*
+	Adds a (data) group and returns a Builder for its entries, each call
+	 adds a new group so a label may be repeated
+*/
+func (b *Builder) Group(label string) *Builder {
+	n := b.add(ConfigGroup, label, nil)
+	if nil == n {
+		return b
+	}
+	return &Builder{c: b.c, group: n, parent: b, err: b.err}
+}
+
+/*
+	Returns the Builder of the enclosing group, or b itself at the top level
+*/
+func (b *Builder) End() *Builder {
+	if nil == b.parent {
+		return b
+	}
+	return b.parent
+}
+
+/*
+	Adds a ConfigValue
+*/
+func (b *Builder) Value(label, value string) *Builder {
+	b.add(ConfigValue, label, []string{value})
+	return b
+}
+
+/*
+	Adds a ConfigBlock holding the text
+*/
+func (b *Builder) Block(label, text string) *Builder {
+	b.add(ConfigBlock, label, []string{text})
+	return b
+}
+
+/*
+	Adds a ConfigBinary holding the data, written as base64
+*/
+func (b *Builder) Binary(label string, data []byte) *Builder {
+	b.add(ConfigBinary, label, []string{string(data)})
+	return b
+}
+
+/*
+	Adds ConfigLines
+*/
+func (b *Builder) Lines(label string, lines ...string) *Builder {
+	b.add(ConfigLines, label, append([]string{}, lines...))
+	return b
+}
+
+/*
+	Adds ConfigItems
+*/
+func (b *Builder) Items(label string, items ...string) *Builder {
+	b.add(ConfigItems, label, append([]string{}, items...))
+	return b
+}
+
+/*
+	Adds a ConfigDict from key & value pairs, as given by StringListToPairs
+*/
+func (b *Builder) Dict(label string, pairs ...string) *Builder {
+	b.add(ConfigDict, label, append([]string{}, pairs[:len(pairs)&^1]...))
+	return b
+}
+
+/*
+	Returns the built Config, or the first error found while building it
+*/
+func (b *Builder) Config() (*Config, error) {
+	if nil != *b.err {
+		return nil, *b.err
+	}
+	return b.c, nil
+}
+
+/*
+	Writes the built config in the cfg format, see Config.WriteTo
+*/
+func (b *Builder) WriteTo(w io.Writer) (int64, error) {
+	if nil != *b.err {
+		return 0, *b.err
+	}
+	return b.c.WriteTo(w)
+}
+
+/*
+	Returns the built config in the cfg format, "" if there was an error
+*/
+func (b *Builder) String() string {
+	var buf bytes.Buffer
+	b.WriteTo(&buf)
+	return buf.String()
+}
+
+// ------------------------------------------------------------------------- //
+
+// add appends a node to the group being built, nil after any error
+func (b *Builder) add(t ConfigType, label string, data []string) *Node {
+	if nil != *b.err {
+		return nil
+	}
+	path := joinPath(b.group.Path, label)
+	if !b.c.opts.syntax().label.MatchString(label) {
+		*b.err = &PathError{path, ErrIllegalLabel}
+		return nil
+	}
+	n := &Node{Type: t, Label: label, Path: path, Data: data, parent: b.group}
+	b.group.Children = append(b.group.Children, n)
+	b.c.nodes[path] = n
+	return n
+}
@@ -0,0 +1,66 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestBuilder(t *testing.T) {
+	b := NewBuilder()
+	b.Value("name", "app")
+	b.Group("db").Value("host", "x").Items("ports", "80", "443").
+		Group("pool").Value("size", "4").End().
+		Block("motd", "hello\n>\nthere")
+	b.Group("server").Value("host", "a")
+	b.Group("server").Value("host", "b").Dict("env", "k", "v")
+	want := "name := app\n" +
+		"db (\n" +
+		"\thost := x\n" +
+		"\tports {\n" +
+		"\t\t80 443\n" +
+		"\t}\n" +
+		"\tpool (\n" +
+		"\t\tsize := 4\n" +
+		"\t)\n" +
+		"\tmotd <<EOF\n" +
+		"\thello\n" +
+		"\t>\n" +
+		"\tthere\n" +
+		"\tEOF\n" +
+		")\n" +
+		"server (\n" +
+		"\thost := a\n" +
+		")\n" +
+		"server (\n" +
+		"\thost := b\n" +
+		"\tenv : [\n" +
+		"\t\tk : v\n" +
+		"\t]\n" +
+		")\n"
+	if want != b.String() {
+		dbg.Error("Builder gave:\n%s", b.String())
+		t.Fail()
+	}
+	c, err := Parse(b.String())
+	dbg.ChkErr(err, "Parse")
+	if v, _ := c.Value("db:pool:size"); "4" != v {
+		dbg.Error("Builder db:pool:size: %q", v)
+		t.Fail()
+	}
+	if v, _ := c.Value("server[0]:host"); "a" != v {
+		dbg.Error("Builder server[0]:host: %q", v)
+		t.Fail()
+	}
+
+	b = NewBuilder().Value("ok", "1").Value("not ok", "2").Value("later", "3")
+	if _, err := b.Config(); !errors.Is(err, ErrIllegalLabel) {
+		dbg.Error("Builder illegal label: %v", err)
+		t.Fail()
+	}
+	if "" != b.String() {
+		dbg.Error("Builder String with an error: %q", b.String())
+		t.Fail()
+	}
+}
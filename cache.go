@@ -0,0 +1,144 @@
+package cfg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type (
+	/*
+		A ParseCache holds the configs loaded with it (see Options.Cache),
+		 keyed by file; it's safe for concurrent use and the zero value is
+		 ready to use.  A file is unchanged if its size & modification time
+		 are, or failing that its SHA-256 hash, and the same goes for every
+		 file it includes; the directories of globbed includes must keep
+		 their modification time, so a new match is found
+	*/
+	ParseCache struct {
+		mu      sync.Mutex
+		entries map[string]*cacheEntry
+	}
+
+	cacheEntry struct {
+		files []cachedFile
+		c     *Config
+	}
+
+	cachedFile struct {
+		path string
+		dir  bool
+		size int64
+		mod  time.Time
+		sum  []byte
+		max  int64 // the MaxSize the file was read with
+
+		// modified as the load began, so always hashed
+		recent bool
+	}
+)
+
+/*
+	Removes every config from the cache
+*/
+func (pc *ParseCache) Clear() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.entries = nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// load returns a copy of the cached config for the key if its files are
+// unchanged, otherwise the config loaded by fn, which is then cached
+func (pc *ParseCache) load(o Options, flPath, key string, fn func(o Options) (*Config, error)) (*Config, error) {
+	if abs, err := filepath.Abs(flPath); nil == err {
+		key = abs + key[len(flPath):]
+	}
+	pc.mu.Lock()
+	e := pc.entries[key]
+	pc.mu.Unlock()
+	if nil != e && e.current() {
+		return e.c.Clone(), nil
+	}
+	// each file is noted as it's read, with the text parsed
+	since, files, ok := time.Now(), []cachedFile{}, true
+	o.seen = func(path, text string) {
+		// an include that isn't a file can't be checked, so isn't cached
+		f, found := snapshot(path, text, since, o.MaxSize)
+		files, ok = append(files, f), ok && found
+	}
+	c, err := fn(o)
+	if nil != err {
+		return nil, err
+	}
+	c.opts.seen = nil
+	if ok && 0 != len(files) {
+		pc.mu.Lock()
+		if nil == pc.entries {
+			pc.entries = make(map[string]*cacheEntry)
+		}
+		pc.entries[key] = &cacheEntry{files, c.Clone()}
+		pc.mu.Unlock()
+	}
+	return c, nil
+}
+
+// current checks that none of the files of the entry have changed
+func (e *cacheEntry) current() bool {
+	for _, f := range e.files {
+		if changed, err := f.changed(); nil != err || changed {
+			return false
+		}
+	}
+	return true
+}
+
+// changed checks whether the file has changed since its snapshot, a file
+// that's been removed having changed
+func (f cachedFile) changed() (bool, error) {
+	fi, err := os.Stat(f.path)
+	switch {
+	case os.IsNotExist(err):
+		return true, nil
+	case nil != err:
+		return false, err
+	case f.dir != fi.IsDir():
+		return true, nil
+	case f.mod.Equal(fi.ModTime()) && (f.dir || (!f.recent && f.size == fi.Size())):
+		return false, nil
+	case f.dir:
+		return true, nil
+	}
+	text, err := readText(f.path, f.max)
+	if nil != err {
+		return false, err
+	}
+	sum := sha256.Sum256([]byte(text))
+	return !bytes.Equal(f.sum, sum[:]), nil
+}
+
+// snapshot notes the size, modification time & the hash of the text read
+// of the regular file, or the modification time of the directory; anything
+// else (stdin, a pipe or device) can't be checked.  A file modified since
+// the load began is always hashed by changed, as it may have been written
+// after being read
+func snapshot(path, text string, since time.Time, max int64) (cachedFile, bool) {
+	if Stdin == path {
+		return cachedFile{}, false
+	}
+	fi, err := os.Stat(path)
+	if nil != err || !(fi.IsDir() || fi.Mode().IsRegular()) {
+		return cachedFile{}, false
+	}
+	f := cachedFile{path: path, dir: fi.IsDir(), size: fi.Size(), mod: fi.ModTime(), max: max}
+	if !f.dir {
+		sum := sha256.Sum256([]byte(text))
+		// allowing for the resolution of modification times
+		f.sum, f.recent = sum[:], !f.mod.Before(since.Add(-time.Second))
+	}
+	return f, true
+}
@@ -0,0 +1,78 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestParseCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	write := func(name, data string) string {
+		flPath := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(flPath), 0755)
+		dbg.ChkErr(ioutil.WriteFile(flPath, []byte(data), 0644), "WriteFile")
+		return flPath
+	}
+	main := write("app.cfg", "port := 8080\n@include inc.cfg\n@include conf.d/*.cfg\nprofile dev (\n\tport := 1\n)\n")
+	inc := write("inc.cfg", "host := x\n")
+
+	o := Options{Cache: &ParseCache{}}
+	first, err := o.LoadConfig(main)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	second, _ := o.LoadConfig(main)
+	if first == second || !reflect.DeepEqual(first.Flatten(), second.Flatten()) || main != second.Source() {
+		dbg.Error("ParseCache copy")
+		t.Fail()
+	}
+	// changes to a copy don't reach the cache
+	first.Lookup("port").Data[0] = "1"
+	if c, _ := o.LoadConfig(main); "8080" != c.ValueOr("port", "") {
+		dbg.Error("ParseCache shares the tree")
+		t.Fail()
+	}
+	if c, err := o.LoadConfigProfile(main, "dev"); nil != err || "1" != c.ValueOr("port", "") {
+		dbg.Error("ParseCache profile: %v", err)
+		t.Fail()
+	}
+
+	// the same content with a new time is still unchanged
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(inc, later, later)
+	if c, _ := o.LoadConfig(main); "x" != c.ValueOr("host", "") {
+		dbg.Error("ParseCache touched include")
+		t.Fail()
+	}
+	write("inc.cfg", "host := y\n")
+	if c, _ := o.LoadConfig(main); "y" != c.ValueOr("host", "") {
+		dbg.Error("ParseCache changed include")
+		t.Fail()
+	}
+	// a new match of a globbed include
+	os.MkdirAll(filepath.Join(dir, "conf.d"), 0755)
+	o.Cache.Clear()
+	o.LoadConfig(main)
+	earlier := time.Now().Add(-time.Minute)
+	write("conf.d/log.cfg", "log := debug\n")
+	os.Chtimes(filepath.Join(dir, "conf.d"), later.Add(time.Minute), later.Add(time.Minute))
+	if c, _ := o.LoadConfig(main); "debug" != c.ValueOr("log", "") {
+		dbg.Error("ParseCache new glob match")
+		t.Fail()
+	}
+	write("app.cfg", "port := 9090\n")
+	os.Chtimes(main, earlier, earlier)
+	if c, _ := o.LoadConfig(main); "9090" != c.ValueOr("port", "") {
+		dbg.Error("ParseCache changed file")
+		t.Fail()
+	}
+}
@@ -1,13 +1,13 @@
 package cfg
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"io/ioutil"
 	"regexp"
 	"strings"
 
 	"github.com/jayacarlson/dbg"
-	"github.com/jayacarlson/txt"
 )
 
 type (
@@ -19,13 +19,29 @@ const (
 	ConfigLines
 	ConfigItems
 	ConfigValue
+	ConfigGroup // only used by Config tree nodes, never passed to handlers
+	ConfigDict
+	ConfigBinary
+	ConfigTable
+	ConfigComment // only used by Config tree nodes, see Options.KeepComments
+
+	// the attributes of the section walked next, as key & value pairs; only
+	// passed to the handlers inside the package
+	configAttributes ConfigType = -1
 )
 
 var (
 	ErrIllegalDataBlock = errors.New("Illegal ConfigData() -- no leading TAB")
+	ErrIllegalInline    = errors.New("Illegal inline ConfigData() -- expected label := value")
+	ErrTableColumns     = errors.New("Table row has more cells than columns")
+	ErrMismatchedEnd    = errors.New("Wrong end char for config data")
+	ErrNoProgress       = errors.New("Config data parse made no progress")
 
-	// 1: label  2: ,  3: <|[|{|(  4: .*
-	findConfigStRex = regexp.MustCompile(`(?ms).*?^(\w+)[ \t]*(,)*[ \t]*(<|\[|{|\()[ \t]*\n(.*)`)
+	// the next line of a group to resume parsing at, see Options.Recover
+	resumeRex = regexp.MustCompile(`(?m)^[^\s#>\]})]`)
+
+	// 1: label  2: ,|:  3: <|[|{|(  4: b64|hex|table||  5: .*
+	findConfigStRex = regexp.MustCompile(`(?ms).*?^(\w+)[ \t]*(?:\[[^\]\n]*\][ \t]*)?(,|:)?[ \t]*(<|\[|{|\()(b64|hex|table|\|)?[ \t]*\n(.*)`)
 	// 1: -contents-  2: >|]|}|)  3: .*
 	findConfigEnRex = regexp.MustCompile(`(?ms)(.*?)\n^(>|\]|}|\))((\n|$).*)`)
 
@@ -41,8 +57,12 @@ var (
 	findConfigValueRex = regexp.MustCompile(`(?ms).*?^(\w+)[ \t]*:=[ \t]*(.*?)[ \t]*\n(.*)`)
 
 	// label < ... >
-	// 1: label  2: -blockData-  3: remaining
-	findConfigBlockRex = regexp.MustCompile(`(?ms).*?^(\w+)[ \t]*<\n(.*?)\n>((\n|$).*)`)
+	// 1: label  2: |  3: -blockData-  4: remaining
+	findConfigBlockRex = regexp.MustCompile(`(?ms).*?^(\w+)[ \t]*<(\|)?\n(.*?)\n>((\n|$).*)`)
+
+	// label <<TAG ... TAG
+	// 1: label  2: TAG
+	findConfigHeredocRex = regexp.MustCompile(`(?m)^(\w+)[ \t]*<<(\w+)[ \t]*\n`)
 
 	// label [ ... ]
 	// 1: label  2: -lineData-  3: remaining
@@ -52,10 +72,27 @@ var (
 	// 1: label  2: ,  3: -listData-  4: remaining  -- #2 may be empty or a comma
 	findConfigItemsRex = regexp.MustCompile(`(?ms).*?^(\w+)[ \t]*(,)*[ \t]*{\n(.*?)\n}((\n|$).*)`)
 
+	// label : [ ... ]
+	// 1: label  2: -dictData-  3: remaining
+	findConfigDictRex = regexp.MustCompile(`(?ms).*?^(\w+)[ \t]*:[ \t]*\[\n(.*?)\n\]((\n|$).*)`)
+
 	// 1: label 2: remaining
 	dictRex = regexp.MustCompile(`^(\w+)[ \t]*:[ \t]*(.*)`)
+
+	// label , { items }  or  label ( label := value ... ) on a single line
+	// 1: label  2: ,  3: -items-  4: -values-
+	findConfigInlineRex = regexp.MustCompile(`(?m)^(\w+)[ \t]*(,)?[ \t]*(?:{[ \t]*(.*?)[ \t]*}|\([ \t]*(.*?)[ \t]*\))[ \t]*$`)
+
+	// the labels of the values in an inline group
+	// 1: label
+	inlineValueRex = regexp.MustCompile(`(\w+)[ \t]*:=`)
 )
 
+/*
+	Splits each line into a key and value at its first ':', ignoring lines
+	 without one; a key found more than once has its last value, see
+	 DictOptions for other separators and duplicate keys
+*/
 func StringListToDict(l []string) map[string]string {
 	result := make(map[string]string)
 	for _, v := range l {
@@ -66,6 +103,54 @@ func StringListToDict(l []string) map[string]string {
 	return result
 }
 
+/*
+	Converts the lines of a ConfigTable into a map for each row keyed by the
+	 column names of the first line; cells are separated by commas when the
+	 first line holds one, otherwise by whitespace.  Missing cells of a row
+	 are empty, while a row with more cells than columns returns a
+	 *ListError for the row wrapping ErrTableColumns
+*/
+func LinesToTable(lines []string) ([]map[string]string, error) {
+	if 0 == len(lines) {
+		return []map[string]string{}, nil
+	}
+	split := strings.Fields
+	if strings.Contains(lines[0], ",") {
+		split = func(l string) []string {
+			cells := strings.Split(l, ",")
+			for i := range cells {
+				cells[i] = strings.TrimSpace(cells[i])
+			}
+			return cells
+		}
+	}
+	cols := split(lines[0])
+	rows := make([]map[string]string, 0, len(lines)-1)
+	for i, l := range lines[1:] {
+		cells := split(l)
+		if len(cells) > len(cols) {
+			return nil, &ListError{"", i, l, ErrTableColumns}
+		}
+		row := make(map[string]string, len(cols))
+		for j, c := range cols {
+			row[c] = ""
+			if j < len(cells) {
+				row[c] = cells[j]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+/*
+	As StringListToDict, but returns the keys and values as alternating
+	 entries in a slice, retaining the order they were found
+*/
+func StringListToPairs(l []string) []string {
+	return defaultSyntax.pairs(l)
+}
+
 /*
 	Scan config data looking for 'label := value' pairs
 
@@ -75,11 +160,21 @@ func StringListToDict(l []string) map[string]string {
 
 	The 'value' is the rest of the line with leading / trailing whitespace
 	removed.  The 'value' could be empty, e.g. "label :="
+
+	A short multi-line value can be given with ':==' ending the line, each
+	 following line must have a leading TAB (which is removed) or be empty,
+	 up to a closing line of just ':=='; the newlines between the lines are
+	 retained in the value, e.g.
+
+		motd :==
+			Welcome!
+			  Have a nice day
+		:==
+
+	gives the value "Welcome!\n  Have a nice day"
 */
 func HandleConfigValues(str string, f func(label, value string)) {
-	for x := findConfigValueRex.FindStringSubmatch(str); nil != x; x = findConfigValueRex.FindStringSubmatch(x[3]) {
-		f(x[1], x[2])
-	}
+	Options{}.HandleConfigValues(str, f)
 }
 
 /*
@@ -98,6 +193,16 @@ func HandleConfigValues(str string, f func(label, value string)) {
 	All text between the < & > characters is captured, including leading/trailing
 	whitespace; note the last \n before the ending > is consumed
 
+	For blocks that need to contain a line with a lone >, a heredoc style
+	 custom terminator can be used instead; the block then ends at the first
+	 line consisting of just the terminator:
+
+		html <<EOF
+		<pre>
+		>
+		</pre>
+		EOF
+
 	e.g. given some sample text of:
 
 		blockData1<
@@ -114,9 +219,7 @@ func HandleConfigValues(str string, f func(label, value string)) {
 	Then called with ("blockData2", "\t# block data\n\tmore stuff...")
 */
 func HandleConfigBlocks(str string, f func(label, block string)) {
-	for x := findConfigBlockRex.FindStringSubmatch(str); nil != x; x = findConfigBlockRex.FindStringSubmatch(x[3]) {
-		f(x[1], x[2])
-	}
+	Options{}.HandleConfigBlocks(str, f)
 }
 
 /*
@@ -154,9 +257,7 @@ func HandleConfigBlocks(str string, f func(label, block string)) {
 	Then called with ("lineData2", []string{"foo: bar, boo","goo, faz: gar"})
 */
 func HandleConfigLines(str string, f func(label string, lines []string)) {
-	for x := findConfigLinesRex.FindStringSubmatch(str); nil != x; x = findConfigLinesRex.FindStringSubmatch(x[3]) {
-		f(x[1], txt.ListToStringSlice(x[2]))
-	}
+	Options{}.HandleConfigLines(str, f)
 }
 
 /*
@@ -196,14 +297,51 @@ func HandleConfigLines(str string, f func(label string, lines []string)) {
 
 	Callback func would be called with ("listData1", []string{"item1.1","item1.2"})
 	Then called with ("listData2", []string{"item2.1","item2.2"})
+
+	A list of lists can be given with each sub-list on a line wrapped in
+	 (parens), or as an indented section wrapped in {braces}; the callback
+	 func is then called once for each sub-list with the same label, e.g.
+
+		hostTags {
+			(web prod)
+			{
+				db
+				prod
+			}
+		}
+
+	gives ("hostTags", []string{"web","prod"}) then ("hostTags", []string{"db","prod"})
 */
 func HandleConfigItems(str string, f func(label string, list []string)) {
-	for x := findConfigItemsRex.FindStringSubmatch(str); nil != x; x = findConfigItemsRex.FindStringSubmatch(x[4]) {
-		if "" == x[2] {
-			x[2] = " "
-		}
-		f(x[1], txt.SepListToStringSlice(x[3], x[2]))
-	}
+	Options{}.HandleConfigItems(str, f)
+}
+
+/*
+	Scan configuration information looking for a 'label' followed by a colon
+	 and an area of 'key : value' lines surrounded by [brackets]
+
+	The 'label' can be made of any word chars (letter, number or underscore)
+
+	The 'label' must start the line with the : [ the only text on the line
+
+	The ending ] must be 1st and only character on the closing line
+
+	Each line is split into a key and value as with StringListToDict, lines
+	 without a ':' are ignored along with the usual comment and empty lines
+
+	e.g. given some sample text of:
+
+		dictData : [
+			# dictionary data
+			alpha : apple sauce
+			beta: banana bread
+		]
+
+	Callback func would be called with ("dictData", map[string]string{
+	 "alpha": "apple sauce", "beta": "banana bread"})
+*/
+func HandleConfigDicts(str string, f func(label string, dict map[string]string)) {
+	Options{}.HandleConfigDicts(str, f)
 }
 
 /*
@@ -211,7 +349,7 @@ func HandleConfigItems(str string, f func(label string, list []string)) {
 
 	Scan configuration information, first looking for any ConfigValues; then
 	 looking for a 'label' and an area of text surrounded by (parens),
-	 {braces}, [brackets] or < & >
+	 {braces}, [brackets], :[brackets] or < & >
 
 	For config data wrapped by (parens), all lines MUST HAVE a leading \t or be a
 	 single \n which is then removed.  The 'label' is added to a labelPath; this
@@ -235,9 +373,42 @@ func HandleConfigItems(str string, f func(label string, list []string)) {
 	   f( ConfigValue, "data:label", []string{"value"} )
 	    then
 	   f( ConfigItems, "data:subdata:listData", []string{"alpha", "beta", "delta"} )
+
+	Lines can be made conditional on the OS, architecture, hostname or the
+	 environment with @if / @elif / @else / @end directives, e.g.
+
+		@if os == "linux"
+		log := /var/log/app
+		@elif env:STAGE != prod
+		log := ./app.log
+		@end
+
+	A ConfigDict is delivered with the keys and values as alternating entries
+	 of the data, see StringListToPairs
+
+	A block opened with <b64 or <hex holds base64 or hex encoded binary data,
+	 which is decoded and delivered as a ConfigBinary with the bytes as the
+	 single entry of the data; whitespace inside the block is ignored, e.g.
+
+		seed <hex
+			00 01 02 03
+			fe ff
+		>
+
+	Data that fails to decode is returned as a *PathError
+
+	A lines block opened with [table holds a table, delivered as a ConfigTable
+	 with the lines as data (see LinesToTable); the first line names the
+	 columns, e.g.
+
+		routes [table
+			path     target
+			/api     http://api:8080
+			/        http://web:80
+		]
 */
 func HandleConfigData(str string, f func(t ConfigType, label string, data []string)) error {
-	return handleConfigData("", str, f)
+	return Options{}.HandleConfigData(str, f)
 }
 
 // ------------------------------------------------------------------------- //
@@ -246,11 +417,11 @@ func HandleConfigData(str string, f func(t ConfigType, label string, data []stri
 	Reads the config file and passes returned name := value pairs to handler
 */
 func LoadConfigValues(flPath string, f func(label, value string)) error {
-	data, err := ioutil.ReadFile(flPath)
+	data, err := Options{}.readConfig(flPath)
 	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
 		return err
 	}
-	HandleConfigValues(string(data), f)
+	HandleConfigValues(data, f)
 	return nil
 }
 
@@ -258,11 +429,11 @@ func LoadConfigValues(flPath string, f func(label, value string)) error {
 	Reads the config file and passes returned block data to handler
 */
 func LoadConfigBlocks(flPath string, f func(label, data string)) error {
-	data, err := ioutil.ReadFile(flPath)
+	data, err := Options{}.readConfig(flPath)
 	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
 		return err
 	}
-	HandleConfigBlocks(string(data), f)
+	HandleConfigBlocks(data, f)
 	return nil
 }
 
@@ -270,11 +441,11 @@ func LoadConfigBlocks(flPath string, f func(label, data string)) error {
 	Reads the config file and passes returned line data to handler
 */
 func LoadConfigLines(flPath string, f func(label string, data []string)) error {
-	data, err := ioutil.ReadFile(flPath)
+	data, err := Options{}.readConfig(flPath)
 	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
 		return err
 	}
-	HandleConfigLines(string(data), f)
+	HandleConfigLines(data, f)
 	return nil
 }
 
@@ -282,11 +453,23 @@ func LoadConfigLines(flPath string, f func(label string, data []string)) error {
 	Reads the config file and passes returned list data to handler
 */
 func LoadConfigItems(flPath string, f func(label string, data []string)) error {
-	data, err := ioutil.ReadFile(flPath)
+	data, err := Options{}.readConfig(flPath)
+	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
+		return err
+	}
+	HandleConfigItems(data, f)
+	return nil
+}
+
+/*
+	Reads the config file and passes returned dictionary data to handler
+*/
+func LoadConfigDicts(flPath string, f func(label string, dict map[string]string)) error {
+	data, err := Options{}.readConfig(flPath)
 	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
 		return err
 	}
-	HandleConfigItems(string(data), f)
+	HandleConfigDicts(data, f)
 	return nil
 }
 
@@ -294,24 +477,123 @@ func LoadConfigItems(flPath string, f func(label string, data []string)) error {
 	Reads the config file and passes returned data to handler
 */
 func LoadConfigData(flPath string, f func(t ConfigType, label string, data []string)) error {
-	data, err := ioutil.ReadFile(flPath)
+	data, err := Options{}.readConfig(flPath)
 	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
 		return err
 	}
-	return handleConfigData("", string(data), f)
+	return Options{}.handleConfigData("", data, f)
 }
 
 // ------------------------------------------------------------------------- //
 
-func removeLeadingTabs(src string) (string, error) {
+// inlineItems splits the items of an inline items section, on commas when
+// the section has a ',' separator or the items contain one, otherwise on
+// whitespace
+func (o Options) inlineItems(s string, comma bool) []string {
+	if comma || strings.Contains(s, ",") {
+		return o.sepListToStringSlice(s, ",")
+	}
+	return o.sepListToStringSlice(s, " ")
+}
+
+// inlineValues splits the 'label := value' entries of an inline group onto
+// lines of their own, each value runs up to the next label
+func (s *syntax) inlineValues(str string) (string, error) {
+	x := s.inlineValue.FindAllStringSubmatchIndex(str, -1)
+	if "" == strings.TrimSpace(str) {
+		return "", nil
+	}
+	if 0 == len(x) || "" != strings.TrimSpace(str[:x[0][0]]) {
+		return "", ErrIllegalInline
+	}
+	result := ""
+	for i, m := range x {
+		end := len(str)
+		if i+1 < len(x) {
+			end = x[i+1][0]
+		}
+		result += str[m[2]:m[3]] + " := " + strings.TrimSpace(str[m[1]:end]) + "\n"
+	}
+	return result, nil
+}
+
+// joinPath adds the label to the label path
+func joinPath(lp, label string) string {
+	if "" == lp {
+		return label
+	}
+	return lp + ":" + label
+}
+
+// normalizeEOL converts \r\n and lone \r line endings to \n, so data from
+// Windows (or old Mac) editors matches the line anchored regexes
+func normalizeEOL(s string) string {
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
+
+// decodeBinary decodes the base64 or hex text of a binary block, all
+// whitespace inside the block is ignored
+func decodeBinary(enc, text string) ([]byte, error) {
+	text = strings.Join(strings.Fields(text), "")
+	if "hex" == enc {
+		return hex.DecodeString(text)
+	}
+	return base64.StdEncoding.DecodeString(text)
+}
+
+// heredoc returns the label, body and remaining text of the heredoc block
+// found by findConfigHeredocRex at h; the body is everything up to the line
+// consisting of just the terminating tag, excluding the last \n
+func heredoc(str string, h []int) (label, body, rest string, ok bool) {
+	label = str[h[2]:h[3]]
+	body, rest, ok = fenced(str[h[1]:], str[h[4]:h[5]])
+	return label, body, rest, ok
+}
+
+// fenced splits src at the first line consisting of just the end fence,
+// returning the text before it, excluding the last \n, and the text after
+func fenced(src, end string) (body, rest string, ok bool) {
+	for i := 0; i <= len(src); {
+		line, j := src[i:], strings.Index(src[i:], "\n")
+		if j >= 0 {
+			line = line[:j]
+		}
+		if end == strings.TrimRight(line, " \t") {
+			if i > 0 {
+				body = src[:i-1]
+			}
+			return body, src[i+len(line):], true
+		}
+		if j < 0 {
+			break
+		}
+		i += j + 1
+	}
+	return "", "", false
+}
+
+// removeLeadingTabs removes one level of indent from each line of a (data)
+// container, either a TAB or the space indent found by spaceIndent
+func (o Options) removeLeadingTabs(src string) (string, error) {
 	result := ""
 	if len(src) == 0 {
 		return "", nil
 	}
+	indent := o.spaceIndent(src)
 	for len(src) > 0 {
 		c, i := src[0], strings.Index(src, "\n")
 		if i > 0 && c == '\t' {
 			result += src[1 : i+1]
+		} else if i > 0 && "" != indent && strings.HasPrefix(src, indent) {
+			result += src[len(indent) : i+1]
+		} else if i > 0 && "" != indent && "" == strings.TrimSpace(src[:i]) {
+			// a whitespace only line is a blank line when indenting with spaces
+			result += "\n"
+		} else if i > 0 && o.isComment(src[:i]) {
+			// unindented comment lines are skipped when CommentPrefixes are set
 		} else if i == 0 && c == '\n' {
 			// because blank lines can be inside <blockdata> retain the blank line
 			result += "\n"
@@ -323,57 +605,261 @@ func removeLeadingTabs(src string) (string, error) {
 	return result, nil
 }
 
-func handleConfigData(lp, str string, f func(t ConfigType, label string, data []string)) error {
-	for "" != str {
-		// find any ConfigValues first
-		HandleConfigValues(str, func(l, v string) {
+func (o Options) handleConfigData(lp, str string, f func(t ConfigType, label string, data []string)) error {
+	return o.walk(lp, str, func(t ConfigType, label string, data []string) {
+		if ConfigGroup != t && ConfigComment != t && configAttributes != t {
+			f(t, label, data)
+		}
+	})
+}
+
+// walk passes the config data to f as handleConfigData does, also passing a
+// ConfigGroup (with nil data) at the start of each (data) container so each
+// occurrence of a repeated group label can be told apart, and with
+// KeepComments a ConfigComment with the lines of any text between entries
+func (o Options) walk(lp, str string, f func(t ConfigType, label string, data []string)) error {
+	rx := o.syntax()
+	var skip func(text string)
+	if o.KeepComments {
+		skip = func(text string) {
+			f(ConfigComment, joinPath(lp, "#"), strings.Split(strings.TrimSuffix(text, "\n"), "\n"))
+		}
+	}
+	sum := &checksum{}
+	f = sum.wrap(f)
+	for first, last := true, len(str)+1; "" != str; first = false {
+		if err := sum.verify(); nil != err {
+			return err
+		}
+		if len(str) >= last {
+			// every pass must consume some of the data
+			return &PathError{lp, ErrNoProgress}
+		}
+		last = len(str)
+		// find the ConfigValues before the next data container first
+		h, x := rx.heredoc.FindStringSubmatchIndex(str), rx.st.FindStringSubmatchIndex(str)
+		in := rx.inline.FindStringSubmatchIndex(str)
+		cs, sec := rx.findSection(str)
+		end := len(str)
+		if nil != x {
+			end = x[2]
+		}
+		if nil != h && h[2] < end {
+			end = h[2]
+		}
+		if nil != in && in[2] < end {
+			end = in[2]
+		}
+		if nil != cs && cs[2] < end {
+			end = cs[2]
+		}
+		region := str[:end]
+		if !first {
+			// the end of line of the last section
+			region = strings.TrimPrefix(region, "\n")
+		}
+		o.values(region, func(l, v string) {
 			if lp != "" {
 				l = lp + ":" + l
 			}
 			f(ConfigValue, l, []string{v})
-		})
-		s := findConfigStRex.FindStringSubmatch(str)
+		}, skip)
+		sum.want = checksumAnnotation(region)
+		if nil != cs && cs[2] == end {
+			label := str[cs[2]:cs[3]]
+			body, rest, ok := fenced(str[cs[1]:], sec.close)
+			if !ok {
+				if rest, ok := o.resume(str, cs[2], &PathError{joinPath(lp, label), ErrUnendedSection}); ok {
+					str = rest
+					continue
+				}
+				dbg.Error("Missing end for config data: %s %s ... %s", label, sec.open, sec.close)
+				break
+			}
+			if lp != "" {
+				label = lp + ":" + label
+			}
+			data, err := sec.fn(label, body)
+			if nil != err {
+				return &PathError{label, err}
+			}
+			if ConfigBlock == sec.t && 1 == len(data) {
+				if data[0], err = processBlock(label, data[0]); nil != err {
+					return err
+				}
+			}
+			f(sec.t, label, data)
+			str = rest
+			continue
+		}
+		if nil != in && in[2] == end {
+			label := str[in[2]:in[3]]
+			if lp != "" {
+				label = lp + ":" + label
+			}
+			if in[6] >= 0 {
+				items, err := o.expandItems(label, o.inlineItems(str[in[6]:in[7]], in[4] >= 0))
+				if nil != err {
+					return err
+				}
+				f(ConfigItems, label, items)
+			} else {
+				values, err := rx.inlineValues(str[in[8]:in[9]])
+				if nil != err {
+					dbg.Error("Illegal inline config data: %s", str[in[0]:in[1]])
+					return err
+				}
+				f(ConfigGroup, label, nil)
+				if err = o.walk(label, values, f); nil != err {
+					return err
+				}
+			}
+			str = str[in[1]:]
+			continue
+		}
+		if nil != h {
+			if nil == x || h[2] < x[2] {
+				label, body, rest, ok := heredoc(str, h)
+				if !ok {
+					if rest, ok := o.resume(str, h[2], &PathError{joinPath(lp, label), ErrUnendedSection}); ok {
+						str = rest
+						continue
+					}
+					dbg.Error("Missing end tag for config data: %s <<%s", label, str[h[4]:h[5]])
+					break
+				}
+				if lp != "" {
+					label = lp + ":" + label
+				}
+				body, err := processBlock(label, o.dedent(body, false))
+				if nil != err {
+					return err
+				}
+				f(ConfigBlock, label, []string{body})
+				str = rest
+				continue
+			}
+		}
+		s := rx.st.FindStringSubmatch(str)
 		if nil != s {
-			e := findConfigEnRex.FindStringSubmatch(s[4])
+			e := findConfigEnRex.FindStringSubmatch(s[5])
 			if nil == e {
+				if rest, ok := o.resume(str, x[2], &PathError{joinPath(lp, s[1]), ErrUnendedSection}); ok {
+					str = rest
+					continue
+				}
 				dbg.Error("Missing end char for config data: %s %s", s[1], s[3])
 				break
 			}
 			if e[2] != matching[s[3]] {
+				if rest, ok := o.resume(str, x[2], &PathError{joinPath(lp, s[1]), ErrMismatchedEnd}); ok {
+					str = rest
+					continue
+				}
 				dbg.Error("Invalid end char for config data: %s %s ... %s", s[1], s[3], e[2])
 				break
 			}
-			if "{" != s[3] && "" != s[2] {
-				dbg.Error("Illegal config data: %s %s -- comma", s[1], s[3])
+			if ("," == s[2] && "{" != s[3]) || (":" == s[2] && "[" != s[3]) ||
+				(("b64" == s[4] || "hex" == s[4] || "|" == s[4]) && "<" != s[3]) || ("table" == s[4] && ("[" != s[3] || "" != s[2])) {
+				dbg.Error("Illegal config data: %s %s %s%s", s[1], s[2], s[3], s[4])
 				break
 			}
 			lblPath := s[1]
 			if lp != "" {
 				lblPath = lp + ":" + lblPath
 			}
+			if attrs := sectionAttributes(str[x[2]:]); nil != attrs {
+				f(configAttributes, lblPath, attrs)
+			}
 			switch s[3] {
 			case "(":
-				st, err := removeLeadingTabs(e[1] + "\n")
+				st, err := o.removeLeadingTabs(e[1] + "\n")
 				if nil == err {
-					err = handleConfigData(lblPath, st, f)
+					f(ConfigGroup, lblPath, nil)
+					if nil != o.sections && "" == lp {
+						o.sections.spawn(o, lblPath, st)
+						break
+					}
+					err = o.walk(lblPath, st, f)
 				}
 				if nil != err {
 					return err
 				}
 			case "<":
-				f(ConfigBlock, lblPath, []string{e[1]})
+				if "" == s[4] || "|" == s[4] {
+					body, err := processBlock(lblPath, o.unescapeBlock(o.dedent(e[1], "|" == s[4])))
+					if nil != err {
+						return err
+					}
+					f(ConfigBlock, lblPath, []string{body})
+					break
+				}
+				b, err := decodeBinary(s[4], e[1])
+				if nil != err {
+					return &PathError{lblPath, err}
+				}
+				f(ConfigBinary, lblPath, []string{string(b)})
 			case "[":
-				f(ConfigLines, lblPath, txt.ListToStringSlice(e[1]))
+				if ":" == s[2] {
+					f(ConfigDict, lblPath, rx.pairs(o.listToStringSlice(e[1])))
+				} else if "table" == s[4] {
+					f(ConfigTable, lblPath, o.listToStringSlice(e[1]))
+				} else {
+					f(ConfigLines, lblPath, o.dataLines(e[1]))
+				}
 			default: //case "{":
 				if "" == s[2] {
 					s[2] = " "
 				}
-				f(ConfigItems, lblPath, txt.SepListToStringSlice(e[1], s[2]))
+				for _, l := range o.itemLists(e[1], s[2]) {
+					items, err := o.expandItems(lblPath, l)
+					if nil != err {
+						return err
+					}
+					f(ConfigItems, lblPath, items)
+				}
 			}
 			str = e[3]
 		} else {
 			str = ""
 		}
 	}
-	return nil
+	return sum.verify()
+}
+
+// resume records the error of the malformed section starting at str[at:]
+// when Options.Recover is set, returning the text from the next label of
+// the group after the section's first line; false when it isn't set
+func (o Options) resume(str string, at int, err error) (string, bool) {
+	if nil == o.recovered {
+		return "", false
+	}
+	*o.recovered = append(*o.recovered, err)
+	rest := ""
+	if i := strings.IndexByte(str[at:], '\n'); i >= 0 {
+		rest = str[at+i+1:]
+	}
+	if x := resumeRex.FindStringIndex(rest); nil != x {
+		return rest[x[0]:], true
+	}
+	return "", true
+}
+
+// spaceIndent returns the spaces used to indent the lines of a (data)
+// container; Options.IndentSpaces of them if set, otherwise those of the
+// first indented line, "" when that line is TAB indented
+func (o Options) spaceIndent(src string) string {
+	if o.IndentSpaces > 0 {
+		return strings.Repeat(" ", o.IndentSpaces)
+	}
+	for _, l := range strings.Split(src, "\n") {
+		if "" == strings.TrimSpace(l) {
+			continue
+		}
+		if ' ' != l[0] {
+			return ""
+		}
+		return l[:len(l)-len(strings.TrimLeft(l, " "))]
+	}
+	return ""
 }
@@ -20,6 +20,9 @@ blah blah blah`
 blah blah blah`
 	blk4 = `block4 blah blah
 # this is included in the block!
+blah blah blah`
+	blk5 = `block5 blah blah
+>
 blah blah blah`
 	dictTestList = `
 dictTest [
@@ -91,6 +94,11 @@ func TestConfigBlocks(t *testing.T) {
 				dbg.Error("block4<\n%s\n>", d)
 				t.Fail()
 			}
+		case "block5":
+			if d != blk5 {
+				dbg.Error("block5<<EOF\n%s\nEOF", d)
+				t.Fail()
+			}
 		default:
 			if l != "unknownBlock" {
 				dbg.Error("Unknown block: %s", l)
@@ -179,6 +187,11 @@ func TestConfigData(t *testing.T) {
 				dbg.Error("block3<\n%s\n>", d[0])
 				t.Fail()
 			}
+		case "testData:blocks:block5":
+			if ctp != ConfigBlock || d[0] != blk5 {
+				dbg.Error("block5<<EOF\n%s\nEOF", d[0])
+				t.Fail()
+			}
 
 		case "testData:lines:lines1":
 			if ctp != ConfigLines || !compareEntries(lst1, d) {
@@ -272,3 +285,122 @@ func TestStringListDict(t *testing.T) {
 		}
 	})
 }
+
+func TestConfigDicts(t *testing.T) {
+	HandleConfigDicts(string(conf), func(l string, d map[string]string) {
+		if l != "dict1" {
+			dbg.Error("Unknown label %s", l)
+			t.Fail()
+		} else if len(d) != 3 || d["alpha"] != "apple sauce" || d["beta"] != "banana bread" || d["delta"] != "date soup" {
+			dbg.Error("%s: %v", l, d)
+			t.Fail()
+		}
+	})
+}
+
+func TestLineEndings(t *testing.T) {
+	src := "name := value\r\nblk <\r\nline1\r\nline2\r\n>\r\ngrp (\r\n\tsub := yes\r\n\tl [\r\n\t\ta\r\n\t\tb\r\n\t]\r\n)\rold := mac\r"
+	got := map[string][]string{}
+	err := HandleConfigData(src, func(ct ConfigType, label string, data []string) {
+		got[label] = data
+	})
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"name":    "value",
+		"blk":     "line1\nline2",
+		"grp:sub": "yes",
+		"grp:l":   "a,b",
+		"old":     "mac",
+	}
+	for k, want := range expect {
+		if v := strings.Join(got[k], ","); want != v {
+			dbg.Error("%s: %q", k, v)
+			t.Fail()
+		}
+	}
+	HandleConfigValues("a := 1\r\nb := 2\r\n", func(label, value string) {
+		if strings.Contains(value, "\r") {
+			dbg.Error("%s: %q", label, value)
+			t.Fail()
+		}
+	})
+	HandleConfigBlocks("b <\r\nx\r\n>\r\n", func(label, block string) {
+		if "x" != block {
+			dbg.Error("%s: %q", label, block)
+			t.Fail()
+		}
+	})
+}
+
+func TestEncodings(t *testing.T) {
+	for _, fl := range []string{"testdata/utf8bom.cfg", "testdata/utf16le.cfg", "testdata/utf16be.cfg"} {
+		got := map[string]string{}
+		err := LoadConfigData(fl, func(ct ConfigType, label string, data []string) {
+			got[label] = data[0]
+		})
+		if nil != err || "value" != got["name"] || "café" != got["grp:sub"] {
+			dbg.Error("%s: %v %v", fl, got, err)
+			t.Fail()
+		}
+	}
+	if c, err := Parse("\ufeffname := value\n"); nil != err || "value" != c.ValueOr("name", "") {
+		dbg.Error("Parse with BOM: %v", err)
+		t.Fail()
+	}
+}
+
+func TestInlineSections(t *testing.T) {
+	src := `
+colors { red, green, blue }
+sizes { S M L }
+point ( x := 1  y := 2 )
+empty { }
+grp (
+	pair , { a b, c d }
+	sub ( name := some value  on := yes )
+)
+`
+	got := map[string]string{}
+	err := HandleConfigData(src, func(ct ConfigType, label string, data []string) {
+		got[label] = strings.Join(data, "|")
+	})
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"colors":       "red|green|blue",
+		"sizes":        "S|M|L",
+		"point:x":      "1",
+		"point:y":      "2",
+		"empty":        "",
+		"grp:pair":     "a b|c d",
+		"grp:sub:name": "some value",
+		"grp:sub:on":   "yes",
+	}
+	for k, want := range expect {
+		if v, ok := got[k]; !ok || want != v {
+			dbg.Error("%s: %q", k, v)
+			t.Fail()
+		}
+	}
+	if len(got) != len(expect) {
+		dbg.Error("unexpected entries: %v", got)
+		t.Fail()
+	}
+	items := []string{}
+	HandleConfigItems(src, func(label string, list []string) {
+		items = append(items, label)
+	})
+	if "colors sizes empty" != strings.Join(items, " ") {
+		dbg.Error("HandleConfigItems: %v", items)
+		t.Fail()
+	}
+	if err = HandleConfigData("bad ( oops )\n", func(ConfigType, string, []string) {}); ErrIllegalInline != err {
+		dbg.Error("bad inline group: %v", err)
+		t.Fail()
+	}
+}
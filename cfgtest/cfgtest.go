@@ -0,0 +1,186 @@
+/*
+	Package cfgtest holds helpers for testing code that reads cfg configs,
+	 so a package's tests needn't compare config data by hand, e.g.
+
+		c := cfgtest.MustParse(t, "db (\n\thost := x\n)\n")
+		cfgtest.AssertValue(t, c, "db:host", "x")
+		cfgtest.AssertGolden(t, c, "testdata/app.golden.cfg")
+
+	Failures are reported with t.Errorf, or t.Fatalf when the test can't go
+	 on, at the line of the test that called the helper
+*/
+package cfgtest
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/cfg"
+)
+
+var (
+	// run the tests with -cfgtest.update to rewrite the golden files
+	update = flag.Bool("cfgtest.update", false, "rewrite the golden files of cfgtest.AssertGolden")
+)
+
+/*
+	Parses the config data, failing the test now if it can't be
+*/
+func MustParse(t testing.TB, src string) *cfg.Config {
+	t.Helper()
+	c, err := cfg.Parse(src)
+	if nil != err {
+		t.Fatalf("cfgtest: parsing config: %v", err)
+	}
+	return c
+}
+
+/*
+	Loads the config file, failing the test now if it can't be
+*/
+func MustLoad(t testing.TB, flPath string) *cfg.Config {
+	t.Helper()
+	c, err := cfg.LoadConfig(flPath)
+	if nil != err {
+		t.Fatalf("cfgtest: loading %s: %v", flPath, err)
+	}
+	return c
+}
+
+/*
+	Checks the ConfigValue or ConfigBlock at the label path is want
+*/
+func AssertValue(t testing.TB, c *cfg.Config, path, want string) {
+	t.Helper()
+	got, ok := c.Value(path)
+	switch {
+	case !ok:
+		t.Errorf("cfgtest: %s: no value, want %q", path, want)
+	case want != got:
+		t.Errorf("cfgtest: %s = %q, want %q", path, got, want)
+	}
+}
+
+/*
+	Checks the entries of the ConfigLines or ConfigItems at the label path
+	 are want
+*/
+func AssertList(t testing.TB, c *cfg.Config, path string, want ...string) {
+	t.Helper()
+	got, err := c.GetStringList(path)
+	if nil != err {
+		t.Errorf("cfgtest: %s: %v, want %q", path, err, want)
+		return
+	}
+	AssertEntries(t, path, got, want)
+}
+
+/*
+	Checks there is no entry at the label path
+*/
+func AssertMissing(t testing.TB, c *cfg.Config, path string) {
+	t.Helper()
+	if n := c.Lookup(path); nil != n {
+		t.Errorf("cfgtest: %s: found %v, want none", path, n.Type)
+	}
+}
+
+/*
+	Checks the entries are want, reporting the first that differs; name
+	 identifies them in the failure, e.g. the label passed to a handler
+*/
+func AssertEntries(t testing.TB, name string, got, want []string) {
+	t.Helper()
+	for i := 0; i < len(got) && i < len(want); i++ {
+		if want[i] != got[i] {
+			t.Errorf("cfgtest: %s[%d] = %q, want %q", name, i, got[i], want[i])
+			return
+		}
+	}
+	if len(want) != len(got) {
+		t.Errorf("cfgtest: %s has %d entries, want %d: %q", name, len(got), len(want), got)
+	}
+}
+
+/*
+	Checks the configs hold the same data, whatever the formatting or order
+	 of their entries (see Config.Flatten), reporting each label path that
+	 differs
+*/
+func AssertSame(t testing.TB, got, want *cfg.Config) {
+	t.Helper()
+	a, b := got.Flatten(), want.Flatten()
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	for p, v := range b {
+		if g, ok := a[p]; !ok {
+			t.Errorf("cfgtest: %s: missing, want %q", p, v)
+		} else if g != v {
+			t.Errorf("cfgtest: %s = %q, want %q", p, g, v)
+		}
+	}
+	for p, g := range a {
+		if _, ok := b[p]; !ok {
+			t.Errorf("cfgtest: %s = %q, want none", p, g)
+		}
+	}
+}
+
+/*
+	Checks the config data survives being written out and parsed again,
+	 see Config.WriteTo, returning the parsed config
+*/
+func AssertRoundTrip(t testing.TB, src string) *cfg.Config {
+	t.Helper()
+	c := MustParse(t, src)
+	written := text(c)
+	again, err := cfg.Parse(written)
+	if nil != err {
+		t.Fatalf("cfgtest: parsing the written config: %v\n%s", err, written)
+	}
+	AssertSame(t, again, c)
+	return c
+}
+
+/*
+	Checks the config as written by WriteTo matches the golden file; run the
+	 tests with -cfgtest.update to write the file from the config instead
+*/
+func AssertGolden(t testing.TB, c *cfg.Config, golden string) {
+	t.Helper()
+	got := text(c)
+	if *update {
+		err := os.MkdirAll(filepath.Dir(golden), 0755)
+		if nil == err {
+			err = ioutil.WriteFile(golden, []byte(got), 0644)
+		}
+		if nil != err {
+			t.Fatalf("cfgtest: updating %s: %v", golden, err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(golden)
+	if os.IsNotExist(err) {
+		t.Fatalf("cfgtest: no golden file %s, run the tests with -cfgtest.update to write it", golden)
+	} else if nil != err {
+		t.Fatalf("cfgtest: reading %s: %v", golden, err)
+	}
+	if string(want) != got {
+		t.Errorf("cfgtest: config differs from %s:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
+
+// ------------------------------------------------------------------------- //
+
+// text is the config as WriteTo writes it, without redacting any values
+func text(c *cfg.Config) string {
+	var buf bytes.Buffer
+	c.WriteTo(&buf)
+	return buf.String()
+}
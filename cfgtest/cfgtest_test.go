@@ -0,0 +1,87 @@
+package cfgtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+// recorder is a testing.TB that records the failures
+type recorder struct {
+	testing.TB
+	errs  []string
+	fatal bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	r.fatal = true
+	panic(r)
+}
+
+// failures runs the helper calls, returning the failures they report
+func failures(t *testing.T, f func(tb testing.TB)) (r *recorder) {
+	r = &recorder{TB: t}
+	defer func() {
+		if p := recover(); nil != p && r != p {
+			panic(p)
+		}
+	}()
+	f(r)
+	return r
+}
+
+func TestAsserts(t *testing.T) {
+	c := MustParse(t, "name := app\ndb (\n\thost := x\n)\nhosts { a b }\n")
+	AssertValue(t, c, "db:host", "x")
+	AssertList(t, c, "hosts", "a", "b")
+	AssertMissing(t, c, "db:port")
+	AssertEntries(t, "hosts", []string{"a", "b"}, []string{"a", "b"})
+
+	for _, tc := range []struct {
+		want int
+		f    func(tb testing.TB)
+	}{
+		{1, func(tb testing.TB) { AssertValue(tb, c, "db:host", "y") }},
+		{2, func(tb testing.TB) { AssertValue(tb, c, "db:port", "1"); AssertMissing(tb, c, "name") }},
+		{3, func(tb testing.TB) {
+			AssertList(tb, c, "hosts", "a")
+			AssertList(tb, c, "name")
+			AssertEntries(tb, "x", []string{"a", "b"}, []string{"a", "c"})
+		}},
+		{3, func(tb testing.TB) {
+			AssertSame(tb, c, MustParse(tb, "name := app\ndb ( host := y )\nhosts { a b }\nport := 1\nother := 2\n"))
+		}},
+	} {
+		if r := failures(t, tc.f); tc.want != len(r.errs) {
+			dbg.Error("failures want %d: %q", tc.want, r.errs)
+			t.Fail()
+		}
+	}
+	if r := failures(t, func(tb testing.TB) { MustLoad(tb, "testdata/missing.cfg") }); !r.fatal {
+		dbg.Error("MustLoad didn't fail: %q", r.errs)
+		t.Fail()
+	}
+}
+
+func TestRoundTripGolden(t *testing.T) {
+	c := AssertRoundTrip(t, "name := app\nnotes <\nsome text\n>\nhosts { a b }\ndb (\n\thost := x\n\tenv : [\n\t\tk : v\n\t]\n)\n")
+	AssertGolden(t, c, "testdata/app.golden.cfg")
+	AssertSame(t, MustLoad(t, "testdata/app.golden.cfg"), c)
+
+	if r := failures(t, func(tb testing.TB) { AssertGolden(tb, MustParse(tb, "name := other\n"), "testdata/app.golden.cfg") }); 1 != len(r.errs) || r.fatal {
+		dbg.Error("AssertGolden differing: %q", r.errs)
+		t.Fail()
+	}
+	if r := failures(t, func(tb testing.TB) { AssertGolden(tb, c, "testdata/missing.cfg") }); !r.fatal {
+		dbg.Error("AssertGolden missing: %q", r.errs)
+		t.Fail()
+	}
+}
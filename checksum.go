@@ -0,0 +1,140 @@
+package cfg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type (
+	/*
+		A ChecksumError is returned when the data of a section doesn't match
+		 the '#sha256:' annotation before it, Want being the annotated hex
+		 digest and Got that of the data parsed
+	*/
+	ChecksumError struct {
+		Path string
+		Want string
+		Got  string
+	}
+
+	// checksum holds the '#sha256:' annotation waiting for the section
+	// it precedes, and the data of that section along with the calls
+	// passing it on held until it's verified, while a group is walked
+	checksum struct {
+		want, label string
+		data        []string
+		pending     []func()
+		err         error
+	}
+)
+
+const (
+	// the annotation before a section giving the SHA-256 of its data
+	ChecksumDirective = "#sha256: "
+)
+
+var (
+	ErrChecksumTarget = errors.New("Checksum annotation not followed by a data section")
+
+	// #sha256: hex
+	// 1: hex digest
+	checksumRex = regexp.MustCompile(`^#sha256:[ \t]*(\S+)$`)
+)
+
+func (e *ChecksumError) Error() string {
+	return e.Path + ": Config section checksum mismatch, want sha256 " + e.Want + " got " + e.Got
+}
+
+/*
+	Returns the hex SHA-256 digest of the data of a section as checked
+	 against its '#sha256:' annotation: the text of a block, the bytes of a
+	 binary section and the entries of any other section (the keys & values
+	 of a dict, each items list in turn) joined by newlines
+*/
+func Checksum(data []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(data, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ------------------------------------------------------------------------- //
+
+// checksumAnnotation returns the hex digest of a '#sha256:' annotation
+// ending the text, "" when the last line isn't one
+func checksumAnnotation(text string) string {
+	text = strings.TrimRight(text, " \t\n")
+	if i := strings.LastIndex(text, "\n"); i >= 0 {
+		text = text[i+1:]
+	}
+	x := checksumRex.FindStringSubmatch(strings.TrimSpace(text))
+	if nil == x {
+		return ""
+	}
+	return strings.ToLower(x[1])
+}
+
+// checksumLine returns the annotation of the section node as written by
+// the encoder
+func (c *Config) checksumLine(e Encoder, n *Node) string {
+	if e.Redact {
+		n = c.redacted(n)
+	}
+	data := n.Data
+	if ConfigDict == n.Type && nil != e.Order {
+		// the pairs as the encoder orders them
+		pairs := [][]string{}
+		for i := 0; i+1 < len(data); i += 2 {
+			pairs = append(pairs, data[i:i+2])
+		}
+		sort.SliceStable(pairs, func(i, j int) bool { return e.Order(pairs[i][0], pairs[j][0]) })
+		data = nil
+		for _, p := range pairs {
+			data = append(data, p...)
+		}
+	}
+	return ChecksumDirective + Checksum(data)
+}
+
+// wrap returns a handler passing each entry to f, collecting the data of the
+// section following an annotation and holding it back until verify
+func (s *checksum) wrap(f func(t ConfigType, label string, data []string)) func(t ConfigType, label string, data []string) {
+	return func(t ConfigType, label string, data []string) {
+		switch {
+		case "" == s.want || ConfigValue == t || ConfigComment == t || configAttributes == t:
+		case ConfigGroup == t:
+			if nil == s.err {
+				s.err = &PathError{label, ErrChecksumTarget}
+			}
+			s.want = ""
+		case "" == s.label || label == s.label:
+			// the items lists of a section come one by one
+			s.label, s.data = label, append(s.data, data...)
+			s.pending = append(s.pending, func() { f(t, label, data) })
+			return
+		}
+		f(t, label, data)
+	}
+}
+
+// verify checks the data collected against the annotation, passing it on
+// when it matches, and readies for the next one; an annotation no section
+// followed is dropped
+func (s *checksum) verify() error {
+	err := s.err
+	if nil == err && "" != s.label {
+		if got := Checksum(s.data); got != s.want {
+			err = &ChecksumError{s.label, s.want, got}
+		}
+	}
+	pending := s.pending
+	*s = checksum{}
+	if nil == err {
+		for _, call := range pending {
+			call()
+		}
+	}
+	return err
+}
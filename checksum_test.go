@@ -0,0 +1,71 @@
+package cfg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestChecksum(t *testing.T) {
+	blob := sha256.Sum256([]byte("hello"))
+	src := "#sha256: " + hex.EncodeToString(blob[:]) + "\nblob <b64\n\taGVsbG8=\n>\n" +
+		"g (\n\tx := 1\n\t#sha256: " + Checksum([]string{"a", "b"}) + "\n\tlist [\n\t\ta\n\t\tb\n\t]\n)\n"
+	if _, err := Parse(src); nil != err {
+		dbg.Error("Parse checksums: %v", err)
+		t.Fail()
+	}
+
+	bad := strings.Replace(src, "\t\tb\n", "\t\tc\n", 1)
+	err := HandleConfigData(bad, func(ct ConfigType, label string, data []string) {
+		if "g:list" == label {
+			dbg.Error("Data of a checksum mismatch handled: %v", data)
+			t.Fail()
+		}
+	})
+	if nil == err {
+		dbg.Error("HandleConfigData checksum mismatch")
+		t.Fail()
+	}
+	_, err = Parse(bad)
+	var ce *ChecksumError
+	if !errors.As(err, &ce) || "g:list" != ce.Path || Checksum([]string{"a", "c"}) != ce.Got {
+		dbg.Error("Parse checksum mismatch: %v", err)
+		t.Fail()
+	}
+
+	_, err = Parse("#sha256: " + Checksum(nil) + "\ng (\n\tx := 1\n)\n")
+	if !errors.Is(err, ErrChecksumTarget) {
+		dbg.Error("Parse checksum of a group: %v", err)
+		t.Fail()
+	}
+}
+
+func TestEncoderChecksums(t *testing.T) {
+	src := "# stale\ntext <\nsome\ntext\n>\nv := 1\ncolors : [\n\tred : 1\n\tblue : 2\n]\n"
+	c, err := Options{KeepComments: true}.Parse(src)
+	if nil != err {
+		dbg.Error("Parse for checksums: %v", err)
+		t.FailNow()
+	}
+	// a stale annotation kept as a comment is replaced
+	c.root.Children[0].Data = []string{"#sha256: 00"}
+	var buf bytes.Buffer
+	if _, err := (Encoder{Checksums: true, Order: Alphabetical}).Encode(&buf, c); nil != err {
+		dbg.Error("Encode checksums: %v", err)
+		t.FailNow()
+	}
+	out := buf.String()
+	if strings.Contains(out, "#sha256: 00") || 2 != strings.Count(out, ChecksumDirective) {
+		dbg.Error("Encode checksums:\n%s", out)
+		t.Fail()
+	}
+	if _, err := Parse(out); nil != err {
+		dbg.Error("Parse encoded checksums: %v\n%s", err, out)
+		t.Fail()
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/jayacarlson/cfg"
+	"github.com/jayacarlson/cfg/toml"
+	"github.com/jayacarlson/cfg/yaml"
+)
+
+var (
+	// the formats a config can be written as
+	toFormats = map[string]func(c *cfg.Config) ([]byte, error){
+		"json": func(c *cfg.Config) ([]byte, error) {
+			data, err := c.MarshalJSON()
+			if nil != err {
+				return nil, err
+			}
+			var buf bytes.Buffer
+			json.Indent(&buf, data, "", "\t")
+			return append(buf.Bytes(), '\n'), nil
+		},
+		"yaml": yaml.Marshal,
+		"toml": toml.Marshal,
+	}
+
+	// the formats a config can be read from
+	fromFormats = map[string]func(data []byte) (*cfg.Config, error){
+		"json": cfg.FromJSON,
+		"yaml": yaml.ToConfig,
+		"toml": toml.ToConfig,
+		"ini": func(data []byte) (*cfg.Config, error) {
+			return cfg.ParseINI(string(data))
+		},
+		"properties": func(data []byte) (*cfg.Config, error) {
+			return cfg.ParseProperties(string(data))
+		},
+		"env": func(data []byte) (*cfg.Config, error) {
+			return cfg.ParseDotEnv(string(data))
+		},
+	}
+)
+
+// runConvert writes the config file in another format, or a file of another
+// format as a config, to stdout
+func runConvert(fs *flag.FlagSet, args []string, stdout io.Writer) error {
+	to := fs.String("to", "", "write the config as `format`: json, yaml or toml")
+	from := fs.String("from", "", "read a file of `format` as a config: json, yaml, toml, ini, properties or env")
+	args, err := parseArgs(fs, args, 1)
+	if nil != err {
+		return err
+	}
+	if ("" == *to) == ("" == *from) {
+		return errUsage
+	}
+	if "" != *to {
+		marshal, ok := toFormats[*to]
+		if !ok {
+			return errUsage
+		}
+		c, err := cfg.LoadConfig(args[0])
+		if nil != err {
+			return err
+		}
+		data, err := marshal(c)
+		if nil == err {
+			_, err = stdout.Write(data)
+		}
+		return err
+	}
+	parse, ok := fromFormats[*from]
+	if !ok {
+		return errUsage
+	}
+	data, err := readInput(args[0])
+	if nil != err {
+		return err
+	}
+	c, err := parse(data)
+	if nil == err {
+		_, err = c.WriteTo(stdout)
+	}
+	return err
+}
+
+// readInput reads the file, or stdin for "-"
+func readInput(flPath string) ([]byte, error) {
+	if cfg.Stdin == flPath {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(flPath)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestConvert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	fl := filepath.Join(dir, "app.cfg")
+	ioutil.WriteFile(fl, []byte("name := app\ndb (\n\tport := 5432\n)\nhosts { a b }\n"), 0644)
+
+	for to, want := range map[string]string{
+		"json": "{\n\t\"name\": \"app\",\n\t\"db\": {\n\t\t\"port\": \"5432\"\n\t},",
+		"yaml": "name: \"app\"\ndb:\n",
+		"toml": "name = \"app\"\n",
+	} {
+		status, out, errs := cfgCmd("convert", "--to", to, fl)
+		if 0 != status || !strings.HasPrefix(out, want) {
+			dbg.Error("convert --to %s: %d %q %s", to, status, out, errs)
+			t.Fail()
+		}
+		// and back again
+		back := filepath.Join(dir, "app."+to)
+		ioutil.WriteFile(back, []byte(out), 0644)
+		status, out, errs = cfgCmd("convert", "--from", to, back)
+		if 0 != status || !strings.Contains(out, "port := 5432") || !strings.Contains(out, "name := app") {
+			dbg.Error("convert --from %s: %d %q %s", to, status, out, errs)
+			t.Fail()
+		}
+	}
+
+	for _, args := range [][]string{{"convert", fl}, {"convert", "--to", "xml", fl}, {"convert", "--to", "json", "--from", "json", fl}} {
+		if status, _, _ := cfgCmd(args...); 2 != status {
+			dbg.Error("convert usage %v: %d", args, status)
+			t.Fail()
+		}
+	}
+}
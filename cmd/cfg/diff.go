@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jayacarlson/cfg"
+)
+
+type (
+	// a change as written by -json
+	jsonChange struct {
+		Path string  `json:"path"`
+		Kind string  `json:"change"` // added, removed or modified
+		Old  *string `json:"old,omitempty"`
+		New  *string `json:"new,omitempty"`
+	}
+)
+
+// runDiff prints the label paths added, removed or changed from the first
+// config to the second, comparing their data rather than their text
+func runDiff(fs *flag.FlagSet, args []string, stdout io.Writer) error {
+	asJSON := fs.Bool("json", false, "print the changes as a JSON array")
+	args, err := parseArgs(fs, args, 2)
+	if nil != err {
+		return err
+	}
+	a, err := cfg.LoadConfig(args[0])
+	if nil != err {
+		return err
+	}
+	b, err := cfg.LoadConfig(args[1])
+	if nil != err {
+		return err
+	}
+	changes := []jsonChange{}
+	for _, ch := range cfg.Diff(a, b) {
+		changes = append(changes, jsonChange{ch.Path, ch.Kind.String(), dataText(ch.Type, ch.Old), dataText(ch.Type, ch.New)})
+	}
+	if *asJSON {
+		data, _ := json.MarshalIndent(changes, "", "\t")
+		_, err = fmt.Fprintf(stdout, "%s\n", data)
+		return err
+	}
+	for _, ch := range changes {
+		switch ch.Kind {
+		case "added":
+			fmt.Fprintf(stdout, "+ %s = %q\n", ch.Path, *ch.New)
+		case "removed":
+			fmt.Fprintf(stdout, "- %s = %q\n", ch.Path, *ch.Old)
+		default:
+			fmt.Fprintf(stdout, "~ %s: %q -> %q\n", ch.Path, *ch.Old, *ch.New)
+		}
+	}
+	return nil
+}
+
+// dataText writes the data of an entry as Config.Flatten does, nil for none
+func dataText(t cfg.ConfigType, data []string) *string {
+	if nil == data {
+		return nil
+	}
+	var s string
+	switch t {
+	case cfg.ConfigItems:
+		s = strings.Join(data, ",")
+	case cfg.ConfigDict:
+		pairs := []string{}
+		for i := 0; i+1 < len(data); i += 2 {
+			pairs = append(pairs, data[i]+" : "+data[i+1])
+		}
+		s = strings.Join(pairs, "\n")
+	default:
+		s = strings.Join(data, "\n")
+	}
+	return &s
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a.cfg"), filepath.Join(dir, "b.cfg")
+	ioutil.WriteFile(a, []byte("# old\nname := app\ndb (\n\thost := x\n\tport := 5432\n)\nhosts { a b }\n"), 0644)
+	ioutil.WriteFile(b, []byte("db ( host := x  port := 5433 )\nname   :=   app\nhosts { a b c }\ndebug := true\n"), 0644)
+
+	want := "~ db:port: \"5432\" -> \"5433\"\n+ debug = \"true\"\n~ hosts: \"a,b\" -> \"a,b,c\"\n"
+	if status, out, errs := cfgCmd("diff", a, b); 0 != status || want != out {
+		dbg.Error("diff: %d %q %s", status, out, errs)
+		t.Fail()
+	}
+	if status, out, _ := cfgCmd("diff", a, a); 0 != status || "" != out {
+		dbg.Error("diff same: %d %q", status, out)
+		t.Fail()
+	}
+	status, out, _ := cfgCmd("diff", "-json", b, a)
+	var changes []map[string]string
+	if err := json.Unmarshal([]byte(out), &changes); nil != err || 0 != status || 3 != len(changes) ||
+		"debug" != changes[1]["path"] || "removed" != changes[1]["change"] || "true" != changes[1]["old"] {
+		dbg.Error("diff -json: %d %q %v", status, out, err)
+		t.Fail()
+	}
+}
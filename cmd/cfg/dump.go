@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"strings"
+
+	"github.com/jayacarlson/cfg"
+)
+
+// runDump prints every entry of the config with its type and value, the
+// values of sensitive entries masked, for a user to send with a bug report
+func runDump(fs *flag.FlagSet, args []string, stdout io.Writer) error {
+	color := fs.Bool("color", false, "color the output with ANSI escapes")
+	width := fs.Int("width", 0, "cut values longer than this, -1 for whole values")
+	mask := fs.String("mask", "", "comma separated label path patterns of further entries to mask")
+	args, err := parseArgs(fs, args, 1)
+	if nil != err {
+		return err
+	}
+	c, err := cfg.LoadConfig(args[0])
+	if nil != err {
+		return err
+	}
+	opts := cfg.DumpOptions{Color: *color, MaxWidth: *width}
+	if "" != *mask {
+		opts.Sensitive = strings.Split(*mask, ",")
+	}
+	return c.Dump(stdout, opts)
+}
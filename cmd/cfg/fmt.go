@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/jayacarlson/cfg"
+)
+
+var (
+	errUnformatted = errors.New("Config files not formatted")
+)
+
+// runFmt formats each file, writing it to stdout, back to the file with -w,
+// as a diff with -d or just listing the files that change with -l
+func runFmt(fs *flag.FlagSet, args []string, stdout io.Writer) error {
+	write := fs.Bool("w", false, "write the result to the file instead of stdout")
+	diff := fs.Bool("d", false, "print a diff of the changes instead, failing if there are any")
+	list := fs.Bool("l", false, "list the files whose formatting differs")
+	if err := parseFlags(fs, args); nil != err {
+		return err
+	}
+	files := fs.Args()
+	if 0 == len(files) {
+		files = []string{cfg.Stdin}
+	}
+	if *write && cfg.Stdin == files[0] && 1 == len(files) {
+		return errUsage
+	}
+	changed := false
+	for _, fl := range files {
+		src, err := readInput(fl)
+		if nil != err {
+			return err
+		}
+		out, err := cfg.Format(src)
+		if nil != err {
+			return &cfg.PathError{Path: fl, Err: err}
+		}
+		same := bytes.Equal(src, out)
+		changed = changed || !same
+		switch {
+		case *list || *diff:
+			if same {
+				break
+			}
+			if *list {
+				fmt.Fprintln(stdout, fl)
+			}
+			if *diff {
+				io.WriteString(stdout, unifiedDiff(fl, string(src), string(out)))
+			}
+		case *write:
+			if same || cfg.Stdin == fl {
+				break
+			}
+			info, err := os.Stat(fl)
+			if nil == err {
+				err = ioutil.WriteFile(fl, out, info.Mode())
+			}
+			if nil != err {
+				return err
+			}
+		default:
+			stdout.Write(out)
+		}
+	}
+	if *diff && changed {
+		return errUnformatted
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestFmt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	messy, tidy := filepath.Join(dir, "messy.cfg"), filepath.Join(dir, "tidy.cfg")
+	src := "name:=app\ndb (\n\thost := x\n\tport:=   5432\n)\n"
+	want := "name := app\ndb (\n\thost := x\n\tport := 5432\n)\n"
+	ioutil.WriteFile(messy, []byte(src), 0644)
+	ioutil.WriteFile(tidy, []byte(want), 0644)
+
+	if status, out, errs := cfgCmd("fmt", messy); 0 != status || want != out {
+		dbg.Error("fmt: %d %q %s", status, out, errs)
+		t.Fail()
+	}
+	if status, out, _ := cfgCmd("fmt", "-l", messy, tidy); 0 != status || messy+"\n" != out {
+		dbg.Error("fmt -l: %d %q", status, out)
+		t.Fail()
+	}
+	status, out, errs := cfgCmd("fmt", "-d", tidy, messy)
+	diff := "--- " + messy + ".orig\n+++ " + messy + "\n@@ -1,5 +1,5 @@\n-name:=app\n+name := app\n db (\n \thost := x\n-\tport:=   5432\n+\tport := 5432\n )\n"
+	if 1 != status || diff != out || !strings.Contains(errs, "not formatted") {
+		dbg.Error("fmt -d: %d %q %s", status, out, errs)
+		t.Fail()
+	}
+	if status, out, _ := cfgCmd("fmt", "-d", tidy); 0 != status || "" != out {
+		dbg.Error("fmt -d formatted: %d %q", status, out)
+		t.Fail()
+	}
+
+	if status, out, _ := cfgCmd("fmt", "-w", messy); 0 != status || "" != out {
+		dbg.Error("fmt -w: %d %q", status, out)
+		t.Fail()
+	}
+	if data, _ := ioutil.ReadFile(messy); want != string(data) {
+		dbg.Error("fmt -w wrote: %q", data)
+		t.Fail()
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13"
+	want := "--- f.orig\n+++ f\n@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n" +
+		"@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n\\ No newline at end of file\n"
+	if got := unifiedDiff("f", a, b); want != got {
+		dbg.Error("unifiedDiff: %q", got)
+		t.Fail()
+	}
+	if got := unifiedDiff("f", "", "a\n"); "--- f.orig\n+++ f\n@@ -0,0 +1,1 @@\n+a\n" != got {
+		dbg.Error("unifiedDiff empty: %q", got)
+		t.Fail()
+	}
+
+	// the lines common to the start & end aren't compared with every other
+	big := strings.Repeat("x := 1\n", 100000)
+	want = "--- f.orig\n+++ f\n@@ -99998,7 +99998,7 @@\n x := 1\n x := 1\n x := 1\n-y := 1\n+y := 2\n x := 1\n x := 1\n x := 1\n"
+	if got := unifiedDiff("f", big+"y := 1\n"+big, big+"y := 2\n"+big); want != got {
+		dbg.Error("unifiedDiff big: %q", got)
+		t.Fail()
+	}
+}
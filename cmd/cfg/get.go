@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/jayacarlson/cfg"
+)
+
+// runGet prints a value or block as is, the entries of lines or items one
+// per line and those of a dictionary as 'key : value' lines
+func runGet(fs *flag.FlagSet, args []string, stdout io.Writer) error {
+	args, err := parseArgs(fs, args, 2)
+	if nil != err {
+		return err
+	}
+	c, err := cfg.LoadConfig(args[0])
+	if nil != err {
+		return err
+	}
+	path := args[1]
+	n := c.Lookup(path)
+	if nil == n {
+		return &cfg.PathError{Path: path, Err: cfg.ErrNoSuchLabel}
+	}
+	switch n.Type {
+	case cfg.ConfigValue, cfg.ConfigBlock:
+		v, _ := c.Value(path)
+		fmt.Fprintln(stdout, v)
+	case cfg.ConfigLines, cfg.ConfigItems:
+		for _, s := range n.Data {
+			fmt.Fprintln(stdout, s)
+		}
+	case cfg.ConfigDict:
+		for i := 0; i+1 < len(n.Data); i += 2 {
+			fmt.Fprintln(stdout, n.Data[i], ":", n.Data[i+1])
+		}
+	default:
+		return &cfg.PathError{Path: path, Err: cfg.ErrWrongType}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+	Command cfg reads and edits cfg config files from the shell, so a single
+	 setting can be checked or changed without writing any Go:
+
+		cfg get app.cfg db:host            prints the value at the label path
+		cfg set app.cfg db:port 5433       changes it in place
+		cfg convert --to json app.cfg      writes it as JSON, YAML or TOML
+		cfg convert --from yaml app.yml    writes a YAML file as a config
+		cfg fmt -d *.cfg                   shows the files' formatting changes
+		cfg validate --schema s.cfg *.cfg  checks them against a schema
+		cfg diff old.cfg new.cfg           lists the settings changed
+		cfg dump -mask '*:key' app.cfg     prints a masked view for debugging
+
+	A file of "-" reads the config from stdin.  Errors are written to stderr
+	 and exit with status 1, bad arguments with status 2
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+type (
+	// a subcommand, run with the arguments that follow its name
+	command struct {
+		args  string // the arguments shown in the usage
+		about string
+		run   func(fs *flag.FlagSet, args []string, stdout io.Writer) error
+	}
+)
+
+var (
+	errUsage = errors.New("Bad arguments")
+
+	commands = map[string]command{
+		"convert":  {"(-to format | -from format) file", "write the config as JSON, YAML or TOML, or another format as a config", runConvert},
+		"diff":     {"[-json] a.cfg b.cfg", "print the label paths added, removed or changed from a to b", runDiff},
+		"dump":     {"[-color] [-width n] [-mask patterns] file", "print every entry with its type and value, masking sensitive ones", runDump},
+		"fmt":      {"[-w | -d | -l] [file...]", "format the files in the canonical style, gofmt for configs", runFmt},
+		"get":      {"file path", "print the value, lines or items at the label path", runGet},
+		"set":      {"file path value", "set the value at the label path, editing the file in place", runSet},
+		"validate": {"-schema schema.cfg [-strict] file...", "check the files against the schema, printing every violation", runValidate},
+	}
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the subcommand named by the first argument, returning the exit
+// status
+func run(args []string, stdout, stderr io.Writer) int {
+	if 0 == len(args) {
+		usage(stderr)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "cfg: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+	fs := flag.NewFlagSet("cfg "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: cfg %s [flags] %s\n", args[0], cmd.args)
+		fs.PrintDefaults()
+	}
+	err := cmd.run(fs, args[1:], stdout)
+	switch {
+	case nil == err:
+		return 0
+	case flag.ErrHelp == err:
+		return 2
+	case errUsage == err:
+		fs.Usage()
+		return 2
+	}
+	fmt.Fprintf(stderr, "cfg %s: %v\n", args[0], err)
+	return 1
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: cfg command [flags] args...")
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "\n\tcfg %s %s\n\t\t%s\n", name, commands[name].args, commands[name].about)
+	}
+}
+
+// parseArgs parses the flags and checks n arguments follow them
+func parseArgs(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := parseFlags(fs, args); nil != err {
+		return nil, err
+	}
+	if n != fs.NArg() {
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+// parseFlags parses the flags, any error other than a request for help
+// being a usage error
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if nil != err && flag.ErrHelp != err {
+		err = errUsage
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+// cfgCmd runs the command line, returning the exit status and output
+func cfgCmd(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	status := run(args, &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestGetSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	fl := filepath.Join(dir, "app.cfg")
+	src := "# the app\ndb (\n\thost := localhost\n\tport := 5432\n)\nhosts { a b }\nnotes <\nline 1\nline 2\n>\n"
+	ioutil.WriteFile(fl, []byte(src), 0644)
+
+	for path, want := range map[string]string{
+		"db:host": "localhost\n", "hosts": "a\nb\n", "notes": "line 1\nline 2\n",
+	} {
+		if status, out, errs := cfgCmd("get", fl, path); 0 != status || want != out {
+			dbg.Error("get %s: %d %q %s", path, status, out, errs)
+			t.Fail()
+		}
+	}
+	if status, _, errs := cfgCmd("get", fl, "db:user"); 1 != status || !strings.Contains(errs, "db:user") {
+		dbg.Error("get missing: %d %s", status, errs)
+		t.Fail()
+	}
+	if status, _, _ := cfgCmd("get", fl, "db"); 1 != status {
+		dbg.Error("get group: %d", status)
+		t.Fail()
+	}
+
+	if status, _, errs := cfgCmd("set", fl, "db:port", "5433"); 0 != status {
+		dbg.Error("set: %d %s", status, errs)
+		t.Fail()
+	}
+	data, _ := ioutil.ReadFile(fl)
+	if strings.Replace(src, "5432", "5433", 1) != string(data) {
+		dbg.Error("set wrote: %q", data)
+		t.Fail()
+	}
+	if status, _, _ := cfgCmd("set", fl, "db:user", "x"); 1 != status {
+		dbg.Error("set missing: %d", status)
+		t.Fail()
+	}
+
+	if status, out, errs := cfgCmd("dump", "-mask", "db:host", fl); 0 != status || !strings.Contains(out, "  host  Value  *****\n") {
+		dbg.Error("dump: %d %q %s", status, out, errs)
+		t.Fail()
+	}
+
+	for _, args := range [][]string{nil, {"nope"}, {"get", fl}, {"set", fl, "db:port"}, {"get", "-x", fl, "db"}} {
+		if status, _, errs := cfgCmd(args...); 2 != status || !strings.Contains(errs, "usage") {
+			dbg.Error("usage %v: %d %s", args, status, errs)
+			t.Fail()
+		}
+	}
+}
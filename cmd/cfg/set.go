@@ -0,0 +1,20 @@
+package main
+
+import (
+	"flag"
+	"io"
+
+	"github.com/jayacarlson/cfg"
+)
+
+// runSet edits just the value in the file, keeping its comments & layout
+func runSet(fs *flag.FlagSet, args []string, stdout io.Writer) error {
+	args, err := parseArgs(fs, args, 3)
+	if nil != err {
+		return err
+	}
+	if cfg.Stdin == args[0] {
+		return errUsage
+	}
+	return cfg.SetValueInFile(args[0], args[1], args[2])
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+type (
+	// a line of a diff, kept (' '), removed ('-') or added ('+'), and the
+	// index of the lines before it in each text
+	diffLine struct {
+		op     byte
+		text   string
+		ai, bi int
+	}
+)
+
+const diffContext = 3
+
+// unifiedDiff returns the changes from a to b as a unified diff of the file,
+// "" if there are none
+func unifiedDiff(name, a, b string) string {
+	if a == b {
+		return ""
+	}
+	lines := diffLines(splitLines(a), splitLines(b))
+	var sb strings.Builder
+	sb.WriteString("--- " + name + ".orig\n+++ " + name + "\n")
+	for i := 0; i < len(lines); {
+		if ' ' == lines[i].op {
+			i++
+			continue
+		}
+		// a hunk runs until the context after a change doesn't reach another
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(lines) && j <= end+2*diffContext; j++ {
+			if ' ' != lines[j].op {
+				end = j
+			}
+		}
+		if end += diffContext + 1; end > len(lines) {
+			end = len(lines)
+		}
+		hunk := lines[start:end]
+		na, nb := 0, 0
+		for _, l := range hunk {
+			if '+' != l.op {
+				na++
+			}
+			if '-' != l.op {
+				nb++
+			}
+		}
+		sb.WriteString("@@ -" + hunkRange(hunk[0].ai, na) + " +" + hunkRange(hunk[0].bi, nb) + " @@\n")
+		for _, l := range hunk {
+			sb.WriteByte(l.op)
+			sb.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+// splitLines splits the text after each newline
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if "" == lines[len(lines)-1] {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the lines of a & b with those of their longest common
+// sequence kept and the rest removed or added; the lines the two start &
+// end with are kept first, so the table of common sequence lengths only
+// spans the lines between, those changed
+func diffLines(a, b []string) []diffLine {
+	lo, ea, eb := 0, len(a), len(b)
+	for lo < ea && lo < eb && a[lo] == b[lo] {
+		lo++
+	}
+	for ea > lo && eb > lo && a[ea-1] == b[eb-1] {
+		ea, eb = ea-1, eb-1
+	}
+	lcs := make([][]int, ea-lo+1)
+	for i := range lcs {
+		lcs[i] = make([]int, eb-lo+1)
+	}
+	for i := ea - lo - 1; i >= 0; i-- {
+		for j := eb - lo - 1; j >= 0; j-- {
+			switch {
+			case a[lo+i] == b[lo+j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	lines := []diffLine{}
+	for i := 0; i < lo; i++ {
+		lines = append(lines, diffLine{' ', a[i], i, i})
+	}
+	i, j := lo, lo
+	for i < ea || j < eb {
+		switch {
+		case i < ea && j < eb && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i], i, j})
+			i, j = i+1, j+1
+		case j == eb || i < ea && lcs[i-lo+1][j-lo] >= lcs[i-lo][j-lo+1]:
+			lines = append(lines, diffLine{'-', a[i], i, j})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j], i, j})
+			j++
+		}
+	}
+	for ; i < len(a); i, j = i+1, j+1 {
+		lines = append(lines, diffLine{' ', a[i], i, j})
+	}
+	return lines
+}
+
+// hunkRange writes the 1 based start line & count of a hunk, an empty one
+// starting at the line before
+func hunkRange(start, n int) string {
+	if 0 != n {
+		start++
+	}
+	return strconv.Itoa(start) + "," + strconv.Itoa(n)
+}
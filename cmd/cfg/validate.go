@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jayacarlson/cfg"
+)
+
+var (
+	errInvalid = errors.New("Config files not valid")
+)
+
+// runValidate checks each file against the schema, printing a 'file:line:'
+// diagnostic for every violation found
+func runValidate(fs *flag.FlagSet, args []string, stdout io.Writer) error {
+	schema := fs.String("schema", "", "the schema `file` to check against, see cfg.LoadSchema")
+	strict := fs.Bool("strict", false, "report label paths the schema doesn't declare")
+	if err := parseFlags(fs, args); nil != err {
+		return err
+	}
+	if "" == *schema || 0 == fs.NArg() {
+		return errUsage
+	}
+	s, err := cfg.LoadSchema(*schema)
+	if nil != err {
+		return err
+	}
+	valid := true
+	for _, fl := range fs.Args() {
+		// a file is loaded (rather than parsed) so its includes are found
+		o, src := cfg.Options{Strict: *strict}, []byte(nil)
+		var c *cfg.Config
+		if cfg.Stdin == fl {
+			if src, err = readInput(fl); nil == err {
+				c, err = o.Parse(string(src))
+			}
+		} else {
+			c, err = o.LoadConfig(fl)
+		}
+		if nil == err {
+			err = s.Validate(c)
+		}
+		if nil == err {
+			continue
+		}
+		valid = false
+		var se *cfg.SchemaError
+		if !errors.As(err, &se) {
+			fmt.Fprintf(stdout, "%s: %v\n", fl, err)
+			continue
+		}
+		if nil == src {
+			src, _ = readInput(fl)
+		}
+		for _, v := range se.Violations {
+			pos := fl
+			if line := cfg.LineOf(src, v.Path); line > 0 {
+				pos += ":" + strconv.Itoa(line)
+			}
+			fmt.Fprintf(stdout, "%s: %v\n", pos, v)
+		}
+	}
+	if !valid {
+		return errInvalid
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	schema, good, bad := filepath.Join(dir, "app.schema.cfg"), filepath.Join(dir, "good.cfg"), filepath.Join(dir, "bad.cfg")
+	ioutil.WriteFile(schema, []byte("name := string,required\ndb (\n\thost := string,required\n\tport := int,min=1,max=65535\n)\n"), 0644)
+	ioutil.WriteFile(good, []byte("name := app\ndb (\n\thost := x\n\tport := 5432\n)\n"), 0644)
+	ioutil.WriteFile(bad, []byte("# no name\ndb (\n\tport := 99999\n\tpotr := 1\n)\n"), 0644)
+
+	if status, out, errs := cfgCmd("validate", "--schema", schema, good); 0 != status || "" != out {
+		dbg.Error("validate good: %d %q %s", status, out, errs)
+		t.Fail()
+	}
+	status, out, errs := cfgCmd("validate", "-schema", schema, "-strict", good, bad)
+	want := []string{
+		bad + ": name: No such config label",
+		bad + ":2: db:host: No such config label",
+		bad + ":3: db:port: Config value out of range",
+		bad + ":4: db:potr: Unknown config label",
+	}
+	if 1 != status || strings.Join(want, "\n")+"\n" != out || !strings.Contains(errs, "not valid") {
+		dbg.Error("validate bad: %d %q %s", status, out, errs)
+		t.Fail()
+	}
+
+	ioutil.WriteFile(bad, []byte("db (\n\tport := 1\n"), 0644)
+	if status, out, _ := cfgCmd("validate", "-schema", schema, bad); 1 != status || !strings.HasPrefix(out, bad+": ") {
+		dbg.Error("validate unparsable: %d %q", status, out)
+		t.Fail()
+	}
+	if status, _, _ := cfgCmd("validate", good); 2 != status {
+		dbg.Error("validate without schema: %d", status)
+		t.Fail()
+	}
+}
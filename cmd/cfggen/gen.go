@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jayacarlson/cfg"
+)
+
+type (
+	// a struct type generated for the config or one of its groups
+	genStruct struct {
+		name   string
+		path   string // the label path of the group, "" for the config
+		fields []*genField
+	}
+
+	// a field of a generated struct and its accessor
+	genField struct {
+		name, label string
+		goType      string     // the type an accessor returns
+		def         string     // the Go expression of the default, "" for the zero value
+		group       *genStruct // the struct of a group
+		repeated    bool       // a slice of the groups with the label
+		size        bool       // a bytes value, kept as written and read by cfg.ParseSize
+		path        string
+	}
+
+	// builds the typed structs and accessors of a config from a Schema or
+	// a sample config
+	generator struct {
+		pkg, from string
+		root      *genStruct
+		sizes     []string // the label paths of the bytes values, checked by Load
+	}
+)
+
+func newGenerator(pkg, typeName, from string) *generator {
+	return &generator{pkg: pkg, from: from, root: &genStruct{name: typeName}}
+}
+
+// fromSchema adds a field for each label path the schema declares, with
+// the schema's type and default
+func (g *generator) fromSchema(s *cfg.Schema) error {
+	for _, f := range s.Fields() {
+		labels := strings.Split(f.Path, ":")
+		st := g.root
+		for _, label := range labels[:len(labels)-1] {
+			st = g.group(st, label, false).group
+		}
+		label := labels[len(labels)-1]
+		if cfg.SchemaGroup == f.Type {
+			g.group(st, label, false)
+			continue
+		}
+		gf := &genField{label: label, path: f.Path}
+		switch f.Type {
+		case cfg.SchemaValue, cfg.SchemaBlock:
+			gf.goType = "string"
+		case cfg.SchemaInt:
+			gf.goType = "int64"
+		case cfg.SchemaFloat:
+			gf.goType = "float64"
+		case cfg.SchemaBool:
+			gf.goType = "bool"
+		case cfg.SchemaDuration:
+			gf.goType = "time.Duration"
+		case cfg.SchemaBytes:
+			gf.goType, gf.size = "int64", true
+		case cfg.SchemaLines, cfg.SchemaItems:
+			gf.goType = "[]string"
+		case cfg.SchemaDict:
+			gf.goType = "map[string]string"
+		}
+		if nil != f.Default {
+			def, err := goLiteral(gf, f.Default)
+			if nil != err {
+				return fmt.Errorf("%s: %v", f.Path, err)
+			}
+			gf.def = def
+		}
+		if err := g.add(st, gf); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// fromSample adds a field for each entry of the sample config, its value
+// being the default and a value's type inferred by cfg.InferValue
+func (g *generator) fromSample(c *cfg.Config) error {
+	return g.sampleGroup(g.root, c.Nodes())
+}
+
+// sampleGroup adds the fields of the nodes of a group
+func (g *generator) sampleGroup(st *genStruct, nodes []*cfg.Node) error {
+	count := map[string]int{}
+	for _, n := range nodes {
+		count[n.Label]++
+	}
+	for _, n := range nodes {
+		gf := &genField{label: n.Label, path: n.Path}
+		switch n.Type {
+		case cfg.ConfigComment:
+			continue
+		case cfg.ConfigGroup:
+			sub := g.group(st, n.Label, count[n.Label] > 1)
+			if nil == sub.group {
+				return fmt.Errorf("%s: %v", n.Path, cfg.ErrWrongType)
+			}
+			if err := g.sampleGroup(sub.group, n.Children); nil != err {
+				return err
+			}
+			continue
+		case cfg.ConfigValue:
+			switch tv := cfg.InferValue(n.Data[0]); tv.Kind {
+			case cfg.KindInt:
+				gf.goType = "int64"
+			case cfg.KindFloat:
+				gf.goType = "float64"
+			case cfg.KindBool:
+				gf.goType = "bool"
+			case cfg.KindDuration:
+				gf.goType = "time.Duration"
+			default:
+				gf.goType = "string"
+			}
+		case cfg.ConfigBlock:
+			gf.goType = "string"
+		case cfg.ConfigLines, cfg.ConfigItems:
+			gf.goType = "[]string"
+		case cfg.ConfigDict:
+			gf.goType = "map[string]string"
+		case cfg.ConfigBinary:
+			gf.goType = "[]byte"
+		case cfg.ConfigTable:
+			gf.goType = "[]map[string]string"
+		default:
+			continue
+		}
+		if "[]map[string]string" != gf.goType && 0 != len(n.Data) {
+			def, err := goLiteral(gf, n.Data)
+			if nil != err {
+				return fmt.Errorf("%s: %v", n.Path, err)
+			}
+			gf.def = def
+		}
+		if err := g.add(st, gf); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// group returns the field of the struct for the group with the label,
+// adding it (and the struct of the group) if it's not there yet
+func (g *generator) group(st *genStruct, label string, repeated bool) *genField {
+	for _, gf := range st.fields {
+		if label == gf.label {
+			gf.repeated = gf.repeated || repeated
+			return gf
+		}
+	}
+	path := label
+	if "" != st.path {
+		path = st.path + ":" + label
+	}
+	gf := &genField{name: goName(label), label: label, path: path, repeated: repeated}
+	gf.group = &genStruct{name: st.name + gf.name, path: path}
+	st.fields = append(st.fields, gf)
+	return gf
+}
+
+// add adds a field to the struct, a repeated label of another type (or
+// that's also a group) being an error
+func (g *generator) add(st *genStruct, gf *genField) error {
+	for _, f := range st.fields {
+		if gf.label != f.label {
+			continue
+		}
+		if nil != f.group || gf.goType != f.goType {
+			return fmt.Errorf("%s: %v", gf.path, cfg.ErrWrongType)
+		}
+		return nil
+	}
+	gf.name = goName(gf.label)
+	for _, f := range st.fields {
+		if gf.name == f.name {
+			return fmt.Errorf("%s: field name %s already used by %s", gf.path, gf.name, f.label)
+		}
+	}
+	if gf.size {
+		g.sizes = append(g.sizes, gf.path)
+	}
+	st.fields = append(st.fields, gf)
+	return nil
+}
+
+// generate returns the gofmt'd source of the structs, the Load function
+// and the accessors
+func (g *generator) generate() ([]byte, error) {
+	var b bytes.Buffer
+	structs := g.structs(g.root, nil)
+	imports := []string{`"github.com/jayacarlson/cfg"`}
+	if g.uses("time.Duration") {
+		imports = append([]string{`"time"`, ""}, imports...)
+	}
+	if 0 != len(g.sizes) {
+		imports = append([]string{`"errors"`}, imports...)
+	}
+	fmt.Fprintf(&b, "// Code generated by cfggen from %s; DO NOT EDIT.\n\npackage %s\n\n", g.from, g.pkg)
+	fmt.Fprintf(&b, "import (\n%s\n)\n", strings.Join(imports, "\n"))
+
+	root := g.root.name
+	fmt.Fprintf(&b, "\n// Load%s loads the config file into a %s\n", root, root)
+	fmt.Fprintf(&b, "func Load%s(flPath string) (*%s, error) {\n", root, root)
+	fmt.Fprintf(&b, "c, err := cfg.LoadConfig(flPath)\nif nil != err {\nreturn nil, err\n}\n")
+	fmt.Fprintf(&b, "return New%s(c)\n}\n", root)
+	fmt.Fprintf(&b, "\n// New%s decodes the config into a %s\n", root, root)
+	fmt.Fprintf(&b, "func New%s(c *cfg.Config) (*%s, error) {\n", root, root)
+	if 0 != len(g.sizes) {
+		fmt.Fprintf(&b, "for _, path := range %#v {\n", g.sizes)
+		fmt.Fprintf(&b, "if _, err := c.GetBytes(path); nil != err && !errors.Is(err, cfg.ErrNoSuchLabel) {\nreturn nil, err\n}\n}\n")
+	}
+	fmt.Fprintf(&b, "v := &%s{}\nif err := c.Unmarshal(v); nil != err {\nreturn nil, err\n}\nreturn v, nil\n}\n", root)
+
+	for _, st := range structs {
+		if "" == st.path {
+			fmt.Fprintf(&b, "\n// %s holds the settings of %s\n", st.name, g.from)
+		} else {
+			fmt.Fprintf(&b, "\n// %s holds the settings of the %s group\n", st.name, st.path)
+		}
+		fmt.Fprintf(&b, "type %s struct {\n", st.name)
+		for _, f := range st.fields {
+			fmt.Fprintf(&b, "%s %s `cfg:%q`\n", f.name, f.fieldType(), f.label)
+		}
+		fmt.Fprintf(&b, "}\n")
+		for _, f := range st.fields {
+			f.accessor(&b, st.name)
+		}
+	}
+	return format.Source(b.Bytes())
+}
+
+// structs returns the struct and those of its groups, depth first
+func (g *generator) structs(st *genStruct, l []*genStruct) []*genStruct {
+	l = append(l, st)
+	for _, f := range st.fields {
+		if nil != f.group {
+			l = g.structs(f.group, l)
+		}
+	}
+	return l
+}
+
+// uses reports whether any field is of the type
+func (g *generator) uses(goType string) bool {
+	for _, st := range g.structs(g.root, nil) {
+		for _, f := range st.fields {
+			if goType == f.goType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldType returns the type of the struct field: a pointer for a value,
+// so one that's not set can be told from one set to its zero value
+func (f *genField) fieldType() string {
+	switch {
+	case nil != f.group && f.repeated:
+		return "[]" + f.group.name
+	case nil != f.group:
+		return "*" + f.group.name
+	case f.size:
+		return "*string"
+	case strings.HasPrefix(f.goType, "[]") || strings.HasPrefix(f.goType, "map["):
+		return f.goType
+	}
+	return "*" + f.goType
+}
+
+// accessor writes the Get method of the field, returning the default when
+// the field isn't set
+func (f *genField) accessor(b *bytes.Buffer, recv string) {
+	if nil != f.group {
+		fmt.Fprintf(b, "\n// Get%s returns the %s group, nil when it's not set\n", f.name, f.path)
+		fmt.Fprintf(b, "func (c *%s) Get%s() %s {\nif nil == c {\nreturn nil\n}\nreturn c.%s\n}\n", recv, f.name, f.fieldType(), f.name)
+		return
+	}
+	def, about := f.def, f.def
+	switch {
+	case "" == def:
+		def, about = f.zero(), "the zero value"
+	case len(about) > 40:
+		about = "its default"
+	}
+	fmt.Fprintf(b, "\n// Get%s returns %s, %s when it's not set\n", f.name, f.path, about)
+	fmt.Fprintf(b, "func (c *%s) Get%s() %s {\n", recv, f.name, f.goType)
+	fmt.Fprintf(b, "if nil == c || nil == c.%s {\nreturn %s\n}\n", f.name, def)
+	switch {
+	case f.size:
+		fmt.Fprintf(b, "n, err := cfg.ParseSize(*c.%s)\nif nil != err {\nreturn %s\n}\nreturn n\n}\n", f.name, def)
+	case "*" == f.fieldType()[:1]:
+		fmt.Fprintf(b, "return *c.%s\n}\n", f.name)
+	default:
+		fmt.Fprintf(b, "return c.%s\n}\n", f.name)
+	}
+}
+
+// zero returns the zero value of the field's type
+func (f *genField) zero() string {
+	switch f.goType {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int64", "float64", "time.Duration":
+		return "0"
+	}
+	return "nil"
+}
+
+// goLiteral returns the Go expression of the data of an entry as a value
+// of the field's type
+func goLiteral(f *genField, data []string) (string, error) {
+	switch f.goType {
+	case "string":
+		return strconv.Quote(strings.Join(data, "\n")), nil
+	case "[]byte":
+		return "[]byte(" + strconv.Quote(data[0]) + ")", nil
+	case "[]string":
+		l := make([]string, len(data))
+		for i, s := range data {
+			l[i] = strconv.Quote(s)
+		}
+		return "[]string{" + strings.Join(l, ", ") + "}", nil
+	case "map[string]string":
+		l := []string{}
+		for i := 0; i+1 < len(data); i += 2 {
+			l = append(l, strconv.Quote(data[i])+": "+strconv.Quote(data[i+1]))
+		}
+		sort.Strings(l)
+		return "map[string]string{" + strings.Join(l, ", ") + "}", nil
+	case "bool":
+		v, err := strconv.ParseBool(data[0])
+		return strconv.FormatBool(v), err
+	case "float64":
+		v, err := strconv.ParseFloat(data[0], 64)
+		return strconv.FormatFloat(v, 'g', -1, 64), err
+	case "time.Duration":
+		d, err := time.ParseDuration(data[0])
+		return durationLiteral(d), err
+	}
+	if f.size {
+		n, err := cfg.ParseSize(data[0])
+		return strconv.FormatInt(n, 10), err
+	}
+	n, err := strconv.ParseInt(data[0], 0, 64)
+	return strconv.FormatInt(n, 10), err
+}
+
+// durationLiteral returns the duration as a multiple of the largest unit
+// it's a whole number of
+func durationLiteral(d time.Duration) string {
+	for _, u := range []struct {
+		d    time.Duration
+		name string
+	}{{time.Hour, "Hour"}, {time.Minute, "Minute"}, {time.Second, "Second"}, {time.Millisecond, "Millisecond"}, {time.Microsecond, "Microsecond"}} {
+		if 0 != d && 0 == d%u.d {
+			return strconv.FormatInt(int64(d/u.d), 10) + " * time." + u.name
+		}
+	}
+	return "time.Duration(" + strconv.FormatInt(int64(d), 10) + ")"
+}
+
+// goName returns the exported Go name of a label, e.g. LogLevel for
+// log_level
+func goName(label string) string {
+	name := ""
+	for _, part := range strings.FieldsFunc(label, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		r := []rune(part)
+		name += string(unicode.ToUpper(r[0])) + string(r[1:])
+	}
+	if "" == name || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/cfg"
+	"github.com/jayacarlson/dbg"
+)
+
+// generated returns the source generated from the schema or sample
+func generated(schema, sample string) (string, error) {
+	g := newGenerator("app", "Config", "app.cfg")
+	if "" != schema {
+		s, err := cfg.ParseSchema(schema)
+		if nil != err {
+			return "", err
+		}
+		err = g.fromSchema(s)
+		if nil != err {
+			return "", err
+		}
+	} else {
+		c, err := cfg.Parse(sample)
+		if nil != err {
+			return "", err
+		}
+		err = g.fromSample(c)
+		if nil != err {
+			return "", err
+		}
+	}
+	src, err := g.generate()
+	if nil == err {
+		_, err = parser.ParseFile(token.NewFileSet(), "gen.go", src, 0)
+	}
+	return string(src), err
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := generated("log_level := string,default=info\ncache := bytes,default=1KiB\ndb (\n\tport := int\n\topts := dict\n)\n", "")
+	for _, want := range []string{
+		"\t\"errors\"\n", "LogLevel *string   `cfg:\"log_level\"`", "Db       *ConfigDb `cfg:\"db\"`",
+		"// GetLogLevel returns log_level, \"info\" when it's not set\n", "func (c *Config) GetCache() int64 {\n\tif nil == c || nil == c.Cache {\n\t\treturn 1024\n\t}\n\tn, err := cfg.ParseSize(*c.Cache)",
+		"range []string{\"cache\"}", "Opts map[string]string `cfg:\"opts\"`", "func (c *ConfigDb) GetPort() int64 {\n\tif nil == c || nil == c.Port {\n\t\treturn 0\n",
+	} {
+		if nil != err || !strings.Contains(src, want) {
+			dbg.Error("schema %v: %q not in\n%s", err, want, src)
+			t.Fail()
+		}
+	}
+
+	src, err = generated("", "# app\nrate := 0.5\nretry := 250ms\nsrv (\n\ton := true\n)\nsrv (\n\tname := b\n)\nenv : [\n\tb : 2\n\ta : 1\n]\n")
+	for _, want := range []string{
+		"Rate  *float64 ", "return 250 * time.Millisecond", "Srv   []ConfigSrv ", "On   *bool   `cfg:\"on\"`", "Name *string `cfg:\"name\"`",
+		"return map[string]string{\"a\": \"1\", \"b\": \"2\"}", "func (c *Config) GetSrv() []ConfigSrv {",
+	} {
+		if nil != err || !strings.Contains(src, want) {
+			dbg.Error("sample %v: %q not in\n%s", err, want, src)
+			t.Fail()
+		}
+	}
+
+	for _, bad := range []string{"a := 1\na := x\n", "a := 1\na ( b := 2 )\n", "x_y := 1\nxY := 2\n"} {
+		if _, err := generated("", bad); nil == err {
+			dbg.Error("%q generated", bad)
+			t.Fail()
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	for label, want := range map[string]string{"db": "Db", "log_level": "LogLevel", "tls-cert": "TlsCert", "2fa": "X2fa", "": "X"} {
+		if got := goName(label); want != got {
+			dbg.Error("goName(%q): %s", label, got)
+			t.Fail()
+		}
+	}
+	for d, want := range map[time.Duration]string{
+		2 * time.Hour: "2 * time.Hour", 90 * time.Second: "90 * time.Second", 1500 * time.Microsecond: "1500 * time.Microsecond",
+		0: "time.Duration(0)", 7: "time.Duration(7)",
+	} {
+		if got := durationLiteral(d); want != got {
+			dbg.Error("durationLiteral(%v): %s", d, got)
+			t.Fail()
+		}
+	}
+}
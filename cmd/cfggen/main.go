@@ -0,0 +1,116 @@
+/*
+	Command cfggen writes a Go file with a typed struct for a config, so its
+	 settings are read with compile-time checked accessors rather than by
+	 label path.  The struct is built from a schema (see cfg.LoadSchema) or
+	 from a sample config, whose values are taken as the defaults and typed
+	 by cfg.InferValue:
+
+		//go:generate go run github.com/jayacarlson/cfg/cmd/cfggen -schema app.schema.cfg -o config_gen.go
+		//go:generate go run github.com/jayacarlson/cfg/cmd/cfggen -type Settings -o settings_gen.go sample.cfg
+
+	Each group gets a struct of its own, named for the path to it (e.g.
+	 ConfigDb for db), and each entry a field with a cfg tag and a Get
+	 method returning its default when it's not set; the methods can be
+	 called on a nil struct, so c.GetDb().GetPort() is always safe.
+	 LoadConfig (LoadSettings etc) loads a file into the struct and
+	 NewConfig decodes an already loaded cfg.Config.
+
+	The package defaults to $GOPACKAGE, as set by go generate, and the file
+	 is written to stdout without -o.  Errors are written to stderr and exit
+	 with status 1, bad arguments with status 2
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/jayacarlson/cfg"
+)
+
+var (
+	errUsage = errors.New("Bad arguments")
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run generates the file for the arguments, returning the exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("cfggen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: cfggen [-type name] [-package name] [-o file] (-schema schema.cfg | sample.cfg)")
+		fs.PrintDefaults()
+	}
+	err := generate(fs, args, stdout)
+	switch {
+	case nil == err:
+		return 0
+	case flag.ErrHelp == err:
+		return 2
+	case errUsage == err:
+		fs.Usage()
+		return 2
+	}
+	fmt.Fprintf(stderr, "cfggen: %v\n", err)
+	return 1
+}
+
+// generate reads the schema or sample config and writes the Go file
+func generate(fs *flag.FlagSet, args []string, stdout io.Writer) error {
+	schema := fs.String("schema", "", "the schema `file` to generate from, see cfg.LoadSchema")
+	typeName := fs.String("type", "Config", "the `name` of the struct of the config")
+	pkg := fs.String("package", os.Getenv("GOPACKAGE"), "the package `name` of the file, main if not set")
+	out := fs.String("o", "", "the `file` to write, stdout if not set")
+	if err := fs.Parse(args); nil != err {
+		if flag.ErrHelp != err {
+			err = errUsage
+		}
+		return err
+	}
+	if "" == *pkg {
+		*pkg = "main"
+	}
+	if ("" == *schema) == (0 == fs.NArg()) || fs.NArg() > 1 || !token.IsIdentifier(*typeName) || !token.IsExported(*typeName) {
+		return errUsage
+	}
+
+	var g *generator
+	if "" != *schema {
+		s, err := cfg.LoadSchema(*schema)
+		if nil != err {
+			return err
+		}
+		g = newGenerator(*pkg, *typeName, *schema)
+		err = g.fromSchema(s)
+		if nil != err {
+			return err
+		}
+	} else {
+		c, err := cfg.LoadConfig(fs.Arg(0))
+		if nil != err {
+			return err
+		}
+		g = newGenerator(*pkg, *typeName, fs.Arg(0))
+		err = g.fromSample(c)
+		if nil != err {
+			return err
+		}
+	}
+	src, err := g.generate()
+	if nil != err {
+		return err
+	}
+	if "" == *out {
+		_, err = stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(*out, src, 0644)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+// cfggen runs the command line, returning the exit status and output
+func cfggen(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	status := run(args, &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	status, out, errs := cfggen("-schema", "../../testdata/app.schema.cfg", "-package", "app")
+	if 0 != status || !strings.HasPrefix(out, "// Code generated by cfggen") || !strings.Contains(out, "\npackage app\n") ||
+		!strings.Contains(out, "func LoadConfig(flPath string) (*Config, error) {") ||
+		!strings.Contains(out, "func (c *ConfigDb) GetPort() int64 {\n\tif nil == c || nil == c.Port {\n\t\treturn 5432\n\t}") {
+		dbg.Error("schema: %d %s\n%s", status, errs, out)
+		t.Fail()
+	}
+
+	dir, err := ioutil.TempDir("", "cfggen")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	sample, gen := filepath.Join(dir, "sample.cfg"), filepath.Join(dir, "settings_gen.go")
+	ioutil.WriteFile(sample, []byte("timeout := 1m30s\nhosts { a b }\n"), 0644)
+	os.Setenv("GOPACKAGE", "server")
+	defer os.Unsetenv("GOPACKAGE")
+	if status, _, errs := cfggen("-type", "Settings", "-o", gen, sample); 0 != status {
+		dbg.Error("sample: %d %s", status, errs)
+		t.Fail()
+	}
+	data, _ := ioutil.ReadFile(gen)
+	if !strings.Contains(string(data), "\npackage server\n") || !strings.Contains(string(data), "return 90 * time.Second") ||
+		!strings.Contains(string(data), "func LoadSettings(") {
+		dbg.Error("sample wrote:\n%s", data)
+		t.Fail()
+	}
+
+	if status, _, errs := cfggen(filepath.Join(dir, "none.cfg")); 1 != status || !strings.Contains(errs, "none.cfg") {
+		dbg.Error("missing file: %d %s", status, errs)
+		t.Fail()
+	}
+	for _, args := range [][]string{nil, {"-schema", "s.cfg", sample}, {sample, sample}, {"-type", "settings", sample}, {"-x", sample}} {
+		if status, _, _ := cfggen(args...); 2 != status {
+			dbg.Error("usage %q: %d", args, status)
+			t.Fail()
+		}
+	}
+}
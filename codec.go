@@ -0,0 +1,576 @@
+package cfg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	/*
+		A StreamDecoder reads config data from a reader as json.Decoder
+		 reads JSON: each Decode parses the next document of the data (see
+		 ParseDocuments) into a value, until io.EOF:
+
+			dec := cfg.NewDecoder(os.Stdin)
+			for {
+				var app App
+				if err := dec.Decode(&app); io.EOF == err {
+					break
+				} else if nil != err {
+					return err
+				}
+			}
+
+		The data is read a document at a time, so a stream of documents
+		 can be decoded as it arrives
+	*/
+	StreamDecoder struct {
+		r     io.Reader
+		o     Options
+		br    *bufio.Reader
+		lines []string // read but not yet split into a document
+		last  bool     // the lines end the data
+		split docSplitter
+		line  int       // the lines split into documents
+		next  *document // the next document, read by More
+		n     int       // the documents taken to decode
+		lex   *Lexer
+		err   error
+		eof   bool
+	}
+
+	/*
+		A StreamEncoder writes values as config data to a writer as
+		 json.Encoder writes JSON, each Encode after the first starting a
+		 new document with a DocumentSeparator line:
+
+			enc := cfg.NewEncoder(os.Stdout)
+			err := enc.Encode(app)
+	*/
+	StreamEncoder struct {
+		// How the config data is written, e.g. its Indent
+		Format Encoder
+
+		w    io.Writer
+		o    Options
+		docs int
+	}
+)
+
+var (
+	textMarshalerT = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+/*
+	Returns a StreamDecoder reading from r
+*/
+func NewDecoder(r io.Reader) *StreamDecoder {
+	return Options{}.NewDecoder(r)
+}
+
+/*
+	As NewDecoder, each document being parsed using these options
+*/
+func (o Options) NewDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{r: r, o: o}
+}
+
+/*
+	Parses the next document and stores it in the value pointed to by v: a
+	 *Config (or **Config) is given the Config itself, an *interface{} or
+	 *map[string]interface{} the map ToMap gives, and any other value is
+	 decoded as by Config.Unmarshal.  Returns io.EOF once every document
+	 has been decoded; a document that fails to parse is a *DocumentError
+*/
+func (d *StreamDecoder) Decode(v interface{}) error {
+	if !d.More() {
+		if nil != d.err {
+			return d.err
+		}
+		return io.EOF
+	}
+	doc := *d.next
+	d.next, d.n = nil, d.n+1
+	str, err := d.o.preprocess(nil, doc.text)
+	var c *Config
+	if nil == err {
+		c, err = d.o.parse(str)
+	}
+	if nil != err {
+		return &DocumentError{d.n - 1, doc.line, err}
+	}
+	switch t := v.(type) {
+	case *Config:
+		*t = *c
+	case **Config:
+		*t = c
+	case *interface{}:
+		*t = c.ToMap()
+	case *map[string]interface{}:
+		*t = c.ToMap()
+	default:
+		return c.Unmarshal(v)
+	}
+	return nil
+}
+
+/*
+	Reports whether there's another document to decode
+*/
+func (d *StreamDecoder) More() bool {
+	for nil == d.next {
+		doc, ok := d.readDocument()
+		if !ok {
+			return false
+		}
+		if "" != strings.TrimSpace(doc.text) {
+			d.next = &doc
+		}
+	}
+	return true
+}
+
+/*
+	Returns the next Token of the data not yet decoded as the Lexer gives
+	 them, io.EOF at the end; the first call reads the rest of the data,
+	 leaving no document to Decode
+*/
+func (d *StreamDecoder) Token() (Token, error) {
+	if nil == d.lex {
+		docs := []string{}
+		if nil != d.next {
+			docs, d.next = append(docs, d.next.text), nil
+		}
+		for doc, ok := d.readDocument(); ok; doc, ok = d.readDocument() {
+			docs = append(docs, doc.text)
+		}
+		if nil != d.err {
+			return Token{}, d.err
+		}
+		d.lex = d.o.NewLexer(strings.Join(docs, DocumentSeparator+"\n"))
+	}
+	t, ok := d.lex.Next()
+	if !ok {
+		return Token{}, io.EOF
+	}
+	return t, nil
+}
+
+/*
+	Makes Decode return an *UnknownError for the entries of a document no
+	 field is decoded from, as Options.Strict does
+*/
+func (d *StreamDecoder) DisallowUnknownFields() {
+	d.o.Strict = true
+}
+
+/*
+	Returns a StreamEncoder writing to w
+*/
+func NewEncoder(w io.Writer) *StreamEncoder {
+	return Options{}.NewEncoder(w)
+}
+
+/*
+	As NewEncoder, the labels allowed being those given by the options
+*/
+func (o Options) NewEncoder(w io.Writer) *StreamEncoder {
+	return &StreamEncoder{w: w, o: o}
+}
+
+/*
+	Writes the value as config data, see Marshal
+*/
+func (e *StreamEncoder) Encode(v interface{}) error {
+	c, err := e.o.marshal(v)
+	if nil != err {
+		return err
+	}
+	var buf bytes.Buffer
+	if 0 != e.docs {
+		buf.WriteString(DocumentSeparator + "\n")
+	}
+	e.Format.Encode(&buf, c)
+	if _, err = buf.WriteTo(e.w); nil == err {
+		e.docs++
+	}
+	return err
+}
+
+/*
+	Returns the value as config data, the reverse of Unmarshal.  A *Config
+	 is written as it is; the fields of a struct (or entries of a map with
+	 string keys, in sorted order) become the entries, labeled as Get
+	 matches them, using:
+
+		struct, map of structs      a ( ) group, as is a map of interface{}
+		                            each entry written as what it holds
+		map of other values         a : [ ] dictionary
+		[]byte                      a <b64 > block
+		slice of structs or maps    a repeated group
+		slice of slices             repeated { } items
+		other slices                { } items, or [ ] lines if an item
+		                            holds whitespace
+		string                      a := value, or a < > block if multi-line
+		Secret                      its Reveal
+		encoding.TextMarshaler      MarshalText, before any of below
+		time.Duration               its String
+		bool, int*, uint*, float*   strconv formatted
+		pointers, interfaces        what they hold, nil leaving the entry out
+
+	Any other value is a *PathError wrapping ErrUnsupported, as is a v that
+	 isn't a struct or map
+*/
+func Marshal(v interface{}) ([]byte, error) {
+	c, err := Options{}.marshal(v)
+	if nil != err {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	_, err = c.WriteTo(&buf)
+	return buf.Bytes(), err
+}
+
+/*
+	Returns the node as JSON, as Config.MarshalJSON gives it within its config
+*/
+func (n *Node) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := newConfig().jsonNode(&buf, n); nil != err {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+	Returns the node in the cfg format, as WriteTo writes it within its config
+*/
+func (n *Node) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	for _, l := range newConfig().encodeNode(Encoder{}, n) {
+		buf.WriteString(l)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+	Returns the config in the cfg format, as WriteTo writes it
+*/
+func (c *Config) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := c.WriteTo(&buf)
+	return buf.Bytes(), err
+}
+
+/*
+	Replaces the config with that parsed from the config data, as Parse
+*/
+func (c *Config) UnmarshalText(text []byte) error {
+	p, err := Parse(string(text))
+	if nil != err {
+		return err
+	}
+	*c = *p
+	return nil
+}
+
+/*
+	Replaces the config with that converted from the JSON, as FromJSON
+*/
+func (c *Config) UnmarshalJSON(b []byte) error {
+	p, err := FromJSON(b)
+	if nil != err {
+		return err
+	}
+	*c = *p
+	return nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// readDocument reads the lines of the next document, up to a separator line
+// or the end of the data; false once it's all been read, or on an error
+func (d *StreamDecoder) readDocument() (document, bool) {
+	if d.eof || nil != d.err {
+		return document{}, false
+	}
+	if nil == d.br {
+		d.start()
+	}
+	start, lines := d.line+1, []string{}
+	for {
+		line, more := d.readLine()
+		if nil != d.err {
+			return document{}, false
+		}
+		d.line++
+		if d.split.separates(line) {
+			return document{strings.Join(lines, "\n") + "\n", start}, true
+		}
+		if lines = append(lines, line); !more {
+			d.eof = true
+			return document{strings.Join(lines, "\n"), start}, true
+		}
+	}
+}
+
+// readLine returns the next line of the data less its end of line, false
+// for the last line (after the last end of line)
+func (d *StreamDecoder) readLine() (string, bool) {
+	for 0 == len(d.lines) {
+		text, err := d.br.ReadString('\n')
+		if nil != err && io.EOF != err {
+			d.err = err
+			return "", false
+		}
+		// a '\r' alone ends a line too
+		d.lines, d.last = strings.Split(normalizeEOL(text), "\n"), io.EOF == err
+		if !d.last {
+			d.lines = d.lines[:len(d.lines)-1]
+		}
+	}
+	line := d.lines[0]
+	d.lines = d.lines[1:]
+	return line, !d.last || 0 != len(d.lines)
+}
+
+// start readies the reader of the data, decompressed (as by decodeText)
+// when it's compressed and of no more than Options.MaxSize bytes when set
+func (d *StreamDecoder) start() {
+	max := d.o.MaxSize
+	d.br, d.split = bufio.NewReader(limitReader(d.r, "", max)), docSplitter{o: d.o}
+	magic, _ := d.br.Peek(8)
+	fn, err := findDecompressor(magic)
+	if nil == err && nil != fn {
+		var r io.Reader
+		if r, err = fn(d.br); nil == err {
+			d.br = bufio.NewReader(limitReader(r, "", max))
+		}
+	}
+	if nil != err {
+		d.err = err
+		return
+	}
+	bom, _ := d.br.Peek(3)
+	switch {
+	case bytes.HasPrefix(bom, []byte{0xef, 0xbb, 0xbf}):
+		d.br.Discard(3)
+	case bytes.HasPrefix(bom, []byte{0xff, 0xfe}) || bytes.HasPrefix(bom, []byte{0xfe, 0xff}):
+		// UTF-16 is transcoded whole
+		data, err := readAll(d.br, "", max)
+		var text string
+		if nil == err {
+			text, err = decodeText("", data, max)
+		}
+		d.br, d.err = bufio.NewReader(strings.NewReader(text)), err
+	}
+}
+
+// marshal returns the value as a Config, see Marshal
+func (o Options) marshal(v interface{}) (*Config, error) {
+	if c, ok := v.(*Config); ok {
+		return c, nil
+	}
+	rv := reflect.ValueOf(v)
+	for reflect.Ptr == rv.Kind() || reflect.Interface == rv.Kind() {
+		rv = rv.Elem()
+	}
+	b := o.NewBuilder()
+	var err error
+	switch rv.Kind() {
+	case reflect.Struct:
+		err = marshalFields(b, rv)
+	case reflect.Map:
+		err = marshalEntries(b, rv)
+	default:
+		err = &PathError{"", ErrUnsupported}
+	}
+	if nil != err {
+		return nil, err
+	}
+	return b.Config()
+}
+
+// marshalFields adds an entry for each field of the struct
+func marshalFields(b *Builder, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		label := sf.Tag.Get("cfg")
+		if "" != sf.PkgPath || "-" == label {
+			continue
+		}
+		if "" == label {
+			label = sf.Name
+		}
+		if err := marshalEntry(b, label, rv.Field(i)); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalEntries adds an entry for each entry of the map, in key order
+func marshalEntries(b *Builder, rv reflect.Value) error {
+	if reflect.String != rv.Type().Key().Kind() {
+		return &PathError{b.group.Path, ErrUnsupported}
+	}
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, k := range keys {
+		if err := marshalEntry(b, k.String(), rv.MapIndex(k)); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalEntry adds the entry for the value with the label
+func marshalEntry(b *Builder, label string, rv reflect.Value) error {
+	rv, ok := marshalIndirect(rv)
+	if !ok || ((reflect.Slice == rv.Kind() || reflect.Map == rv.Kind()) && rv.IsNil()) {
+		return nil
+	}
+	if s, ok, err := marshalString(rv); ok || nil != err {
+		if nil != err {
+			return &PathError{joinPath(b.group.Path, label), err}
+		}
+		if strings.Contains(s, "\n") {
+			b.Block(label, s)
+		} else {
+			b.Value(label, s)
+		}
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		return marshalFields(b.Group(label), rv)
+	case reflect.Map:
+		if group, _ := marshalIndirect(reflect.New(rv.Type().Elem()).Elem()); isComposite(group) || reflect.Interface == group.Kind() {
+			return marshalEntries(b.Group(label), rv)
+		}
+		if reflect.String != rv.Type().Key().Kind() {
+			return &PathError{joinPath(b.group.Path, label), ErrUnsupported}
+		}
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		pairs := make([]string, 0, 2*len(keys))
+		for _, k := range keys {
+			s, ok, err := marshalString(rv.MapIndex(k))
+			if !ok {
+				err = ErrUnsupported
+			}
+			if nil != err {
+				return &PathError{joinPath(b.group.Path, label) + ":" + k.String(), err}
+			}
+			pairs = append(pairs, k.String(), s)
+		}
+		b.Dict(label, pairs...)
+	case reflect.Slice, reflect.Array:
+		if reflect.Slice == rv.Kind() && reflect.Uint8 == rv.Type().Elem().Kind() {
+			b.Binary(label, rv.Bytes())
+			return nil
+		}
+		if elem, _ := marshalIndirect(reflect.New(rv.Type().Elem()).Elem()); isComposite(elem) || reflect.Slice == elem.Kind() {
+			// a repeated entry
+			for i := 0; i < rv.Len(); i++ {
+				if err := marshalEntry(b, label, rv.Index(i)); nil != err {
+					return err
+				}
+			}
+			return nil
+		}
+		items, lines := make([]string, 0, rv.Len()), false
+		for i := 0; i < rv.Len(); i++ {
+			s, ok, err := marshalString(rv.Index(i))
+			if !ok {
+				err = ErrUnsupported
+			}
+			if nil != err {
+				return &ListError{joinPath(b.group.Path, label), i, "", err}
+			}
+			lines = lines || "" == s || strings.ContainsAny(s, " \t\n")
+			items = append(items, s)
+		}
+		if lines {
+			b.Lines(label, items...)
+		} else {
+			b.Items(label, items...)
+		}
+	default:
+		return &PathError{joinPath(b.group.Path, label), ErrUnsupported}
+	}
+	return nil
+}
+
+// marshalIndirect follows pointers and interfaces to the value they hold,
+// stopping at a TextMarshaler; false for nil
+func marshalIndirect(rv reflect.Value) (reflect.Value, bool) {
+	for (reflect.Ptr == rv.Kind() || reflect.Interface == rv.Kind()) && !rv.Type().Implements(textMarshalerT) {
+		if rv.IsNil() {
+			if reflect.Ptr == rv.Kind() {
+				// the type pointed to, for the kind of entry
+				return reflect.New(rv.Type().Elem()).Elem(), false
+			}
+			return rv, false
+		}
+		rv = rv.Elem()
+	}
+	if reflect.Ptr == rv.Kind() && rv.IsNil() {
+		return rv, false
+	}
+	return rv, true
+}
+
+// isComposite reports whether the value is written as a group
+func isComposite(rv reflect.Value) bool {
+	if rv.Type().Implements(textMarshalerT) || reflect.PtrTo(rv.Type()).Implements(textMarshalerT) {
+		return false
+	}
+	return reflect.Struct == rv.Kind() || reflect.Map == rv.Kind()
+}
+
+// marshalString returns the value as a string, false for a value that
+// isn't written as one
+func marshalString(rv reflect.Value) (string, bool, error) {
+	rv, ok := marshalIndirect(rv)
+	if !ok {
+		return "", true, nil
+	}
+	if secretT == rv.Type() {
+		// its MarshalText masks it
+		return rv.String(), true, nil
+	}
+	if rv.Type().Implements(textMarshalerT) {
+		text, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), true, err
+	}
+	if rv.CanAddr() && rv.Addr().Type().Implements(textMarshalerT) {
+		text, err := rv.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), true, err
+	}
+	if durationT == rv.Type() {
+		return time.Duration(rv.Int()).String(), true, nil
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()), true, nil
+	}
+	return "", false, nil
+}
@@ -0,0 +1,161 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestCodec(t *testing.T) {
+	type server struct {
+		Host string
+		Port int `cfg:"port"`
+	}
+	type app struct {
+		Name    string
+		Debug   bool
+		Timeout time.Duration
+		Ratio   float64
+		IP      net.IP
+		Motd    string
+		Tags    []string
+		Args    []string
+		Limits  map[string]int
+		Servers []server
+		DB      *server
+		Skip    string `cfg:"-"`
+		None    *server
+		Key     []byte
+	}
+	in := app{"app", true, 5 * time.Second, 0.5, net.IPv4(10, 0, 0, 1), "hello\nworld", []string{"a", "b"}, []string{"x y", "z"},
+		map[string]int{"mem": 512, "cpu": 2}, []server{{"a", 1}, {"b", 2}}, &server{"db", 5432}, "skipped", nil, []byte{1, 2, 255}}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.Format.Indent = "  "
+	if err := enc.Encode(in); nil != err {
+		dbg.Error("Encode: %v", err)
+		t.FailNow()
+	}
+	dbg.ChkErr(enc.Encode(map[string]interface{}{"name": "second", "db": map[string]interface{}{"host": "x", "ports": []int{80}}}), "Encode")
+	if !strings.Contains(buf.String(), "\n---\ndb (\n  host := x\n  ports {\n    80\n  }\n)\nname := second\n") {
+		dbg.Error("Encode wrote:\n%s", buf.String())
+		t.Fail()
+	}
+
+	dec := NewDecoder(bytes.NewReader(buf.Bytes()))
+	var out app
+	if err := dec.Decode(&out); nil != err {
+		dbg.Error("Decode: %v", err)
+		t.FailNow()
+	}
+	in.Skip = ""
+	if !reflect.DeepEqual(in, out) {
+		dbg.Error("Decode round trip:\n%+v\n%+v", in, out)
+		t.Fail()
+	}
+	var m map[string]interface{}
+	if err := dec.Decode(&m); nil != err || "second" != m["name"] {
+		dbg.Error("Decode of a map: %v %v", m, err)
+		t.Fail()
+	}
+	if dec.More() || io.EOF != dec.Decode(&m) {
+		dbg.Error("Decode past the last document")
+		t.Fail()
+	}
+	dec = NewDecoder(bytes.NewReader(buf.Bytes()))
+	for n := 0; ; n++ {
+		tok, err := dec.Token()
+		if io.EOF == err {
+			if n < 20 {
+				dbg.Error("Token gave %d tokens", n)
+				t.Fail()
+			}
+			break
+		}
+		if 0 == n && (TokenLabel != tok.Kind || "Name" != tok.Text) {
+			dbg.Error("Token: %+v", tok)
+			t.Fail()
+		}
+	}
+
+	// the documents are read as they're decoded
+	var c *Config
+	errRead := errors.New("read")
+	dec = NewDecoder(io.MultiReader(strings.NewReader("a := 1\r\n---\r\n"), iotest.ErrReader(errRead)))
+	if err := dec.Decode(&c); nil != err || "1" != c.ValueOr("a", "") {
+		dbg.Error("Decode of a streamed document: %v", err)
+		t.Fail()
+	}
+	if err := dec.Decode(&c); errRead != err {
+		dbg.Error("Decode past the streamed document: %v", err)
+		t.Fail()
+	}
+
+	var te *TooLargeError
+	if err := (Options{MaxSize: 8}).NewDecoder(strings.NewReader("a := 1\nb := 2\n")).Decode(&c); !errors.As(err, &te) {
+		dbg.Error("Decode past MaxSize: %v", err)
+		t.Fail()
+	}
+
+	// strict & bad documents
+	dec = NewDecoder(strings.NewReader("port := 1\nhots := x\n---\nbad <\n"))
+	dec.DisallowUnknownFields()
+	var s server
+	var ue *UnknownError
+	if err := dec.Decode(&s); !errors.As(err, &ue) || 1 != s.Port {
+		dbg.Error("Decode of an unknown field: %v", err)
+		t.Fail()
+	}
+	var de *DocumentError
+	if err := (Options{Recover: true}).NewDecoder(strings.NewReader("a := 1\n---\nbad <\n")).Decode(&c); nil != err || "1" != c.ValueOr("a", "") {
+		dbg.Error("Decode of a *Config: %v", err)
+		t.Fail()
+	}
+	dec = NewDecoder(strings.NewReader("a := 1\n---\n\n---\nb ( oops )\n"))
+	dec.Decode(&c)
+	if err := dec.Decode(&c); !errors.As(err, &de) || 1 != de.Doc || 5 != de.Line {
+		dbg.Error("Decode of a bad document: %v", err)
+		t.Fail()
+	}
+
+	if _, err := Marshal([]string{"a"}); !errors.Is(err, ErrUnsupported) {
+		dbg.Error("Marshal of a slice: %v", err)
+		t.Fail()
+	}
+	if _, err := Marshal(struct{ C chan int }{}); !errors.Is(err, ErrUnsupported) {
+		dbg.Error("Marshal of a chan: %v", err)
+		t.Fail()
+	}
+
+	// the tree with the stdlib codecs
+	c, _ = Parse("db (\n\thost := x\n\tports { 80 443 }\n)\n")
+	if b, err := json.Marshal(c.Lookup("db")); nil != err || `{"host":"x","ports":["80","443"]}` != string(b) {
+		dbg.Error("Node MarshalJSON: %s %v", b, err)
+		t.Fail()
+	}
+	if b, _ := c.Lookup("db:host").MarshalText(); "host := x\n" != string(b) {
+		dbg.Error("Node MarshalText: %q", b)
+		t.Fail()
+	}
+	var wrapped struct{ Settings *Config }
+	if err := json.Unmarshal([]byte(`{"Settings":{"a":"1"}}`), &wrapped); nil != err || "1" != wrapped.Settings.ValueOr("a", "") {
+		dbg.Error("Config UnmarshalJSON: %v", err)
+		t.Fail()
+	}
+	var d Config
+	text, _ := c.MarshalText()
+	if err := d.UnmarshalText(text); nil != err || "x" != d.ValueOr("db:host", "") {
+		dbg.Error("Config UnmarshalText: %v", err)
+		t.Fail()
+	}
+}
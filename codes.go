@@ -0,0 +1,285 @@
+package cfg
+
+import (
+	"errors"
+)
+
+type (
+	/*
+		An ErrorCode is the stable, machine-readable code of an error of the
+		 package, e.g. "CFG001" for a section missing its end delimiter;
+		 unlike the error text it never changes, so front-ends can map it
+		 to a localized message and tests can check for it
+	*/
+	ErrorCode string
+
+	/*
+		A CodedError is an error giving its ErrorCode; every error type of
+		 the package is one, holding the fields of the failure (label path,
+		 line, value ...) for a message to be built from:
+
+			var ce cfg.CodedError
+			if errors.As(err, &ce) {
+				msg := catalog[ce.Code()]
+			}
+
+		An error wrapping another (such as a *PathError) gives the code of
+		 the error it wraps, the first with a code for those wrapping many
+	*/
+	CodedError interface {
+		error
+		Code() ErrorCode
+	}
+)
+
+const (
+	// parsing & loading
+	CodeMissingEndDelimiter    ErrorCode = "CFG001"
+	CodeMismatchedEndDelimiter ErrorCode = "CFG002"
+	CodeIllegalDataBlock       ErrorCode = "CFG003"
+	CodeIllegalInline          ErrorCode = "CFG004"
+	CodeIllegalSection         ErrorCode = "CFG005"
+	CodeTableColumns           ErrorCode = "CFG006"
+	CodeNoProgress             ErrorCode = "CFG007"
+	CodeBadCondition           ErrorCode = "CFG008"
+	CodeUnbalancedCondition    ErrorCode = "CFG009"
+	CodeMissingHostEnd         ErrorCode = "CFG010"
+	CodeMissingProfileEnd      ErrorCode = "CFG011"
+	CodeNoSuchProfile          ErrorCode = "CFG012"
+	CodeIncludeCycle           ErrorCode = "CFG013"
+	CodeIncludeDepth           ErrorCode = "CFG014"
+	CodeIncludeEnv             ErrorCode = "CFG015"
+	CodeUnknownReference       ErrorCode = "CFG016"
+	CodeCircularReference      ErrorCode = "CFG017"
+	CodeDuplicateAnchor        ErrorCode = "CFG018"
+	CodeUnendedAnchor          ErrorCode = "CFG019"
+	CodeBadRange               ErrorCode = "CFG020"
+	CodeRangeTooLarge          ErrorCode = "CFG021"
+	CodeDuplicateKey           ErrorCode = "CFG022"
+	CodeDuplicateLabel         ErrorCode = "CFG023"
+	CodeExprUnits              ErrorCode = "CFG024"
+	CodeExprDivide             ErrorCode = "CFG025"
+	CodeExprArgs               ErrorCode = "CFG026"
+	CodeNoSuchKey              ErrorCode = "CFG027"
+	CodeDecrypt                ErrorCode = "CFG028"
+	CodeChecksumTarget         ErrorCode = "CFG029"
+	CodeChecksumMismatch       ErrorCode = "CFG030"
+	CodeTooLarge               ErrorCode = "CFG031"
+	CodePanic                  ErrorCode = "CFG032"
+	CodeBadUTF16               ErrorCode = "CFG033"
+	CodeNoDecompressor         ErrorCode = "CFG034"
+	CodeIllegalLabel           ErrorCode = "CFG035"
+	CodeINISyntax              ErrorCode = "CFG036"
+	CodeDotEnvSyntax           ErrorCode = "CFG037"
+	CodeBadEscape              ErrorCode = "CFG038"
+	CodeJSONType               ErrorCode = "CFG039"
+	CodeUnsigned               ErrorCode = "CFG040"
+	CodeBadSignature           ErrorCode = "CFG041"
+	CodeSectionDelimiter       ErrorCode = "CFG042"
+	CodeSectionType            ErrorCode = "CFG043"
+	CodeConfigTypeName         ErrorCode = "CFG044"
+	CodeBadPattern             ErrorCode = "CFG045"
+	CodeBadOverride            ErrorCode = "CFG046"
+	CodeTemplate               ErrorCode = "CFG047"
+
+	// reading & decoding values
+	CodeNoSuchLabel         ErrorCode = "CFG101"
+	CodeWrongType           ErrorCode = "CFG102"
+	CodeNotPointer          ErrorCode = "CFG103"
+	CodeUnsupported         ErrorCode = "CFG104"
+	CodeDecoderType         ErrorCode = "CFG105"
+	CodeBadBool             ErrorCode = "CFG106"
+	CodeNotAllowed          ErrorCode = "CFG107"
+	CodePercentSum          ErrorCode = "CFG108"
+	CodeRelativeURL         ErrorCode = "CFG109"
+	CodeNoDefault           ErrorCode = "CFG110"
+	CodeNoSuchSecret        ErrorCode = "CFG111"
+	CodeUnknownSecretScheme ErrorCode = "CFG112"
+
+	// validation
+	CodeUnknownLabel ErrorCode = "CFG201"
+	CodeBadSchema    ErrorCode = "CFG202"
+	CodeOutOfRange   ErrorCode = "CFG203"
+	CodeNoMatch      ErrorCode = "CFG204"
+	CodeBadLength    ErrorCode = "CFG205"
+	CodeMissing      ErrorCode = "CFG206"
+	CodeRequires     ErrorCode = "CFG207"
+	CodeOrder        ErrorCode = "CFG208"
+
+	// everything else
+	CodeNotInArchive   ErrorCode = "CFG301"
+	CodeInlineEdit     ErrorCode = "CFG302"
+	CodePatchConflict  ErrorCode = "CFG303"
+	CodeReloaderClosed ErrorCode = "CFG304"
+	CodeNotFound       ErrorCode = "CFG305"
+	CodeHTTP           ErrorCode = "CFG306"
+)
+
+var (
+	// the name of each code and the error given it, nil for the codes
+	// of error types
+	errorCodes = []struct {
+		code ErrorCode
+		name string
+		err  error
+	}{
+		{CodeMissingEndDelimiter, "MissingEndDelimiter", ErrUnendedSection},
+		{CodeMismatchedEndDelimiter, "MismatchedEndDelimiter", ErrMismatchedEnd},
+		{CodeIllegalDataBlock, "IllegalDataBlock", ErrIllegalDataBlock},
+		{CodeIllegalInline, "IllegalInline", ErrIllegalInline},
+		{CodeIllegalSection, "IllegalSection", ErrIllegalSection},
+		{CodeTableColumns, "TableColumns", ErrTableColumns},
+		{CodeNoProgress, "NoProgress", ErrNoProgress},
+		{CodeBadCondition, "BadCondition", ErrBadCondition},
+		{CodeUnbalancedCondition, "UnbalancedCondition", ErrUnbalancedCond},
+		{CodeMissingHostEnd, "MissingHostEnd", ErrHostEnd},
+		{CodeMissingProfileEnd, "MissingProfileEnd", ErrProfileEnd},
+		{CodeNoSuchProfile, "NoSuchProfile", ErrNoSuchProfile},
+		{CodeIncludeCycle, "IncludeCycle", ErrIncludeCycle},
+		{CodeIncludeDepth, "IncludeDepth", ErrIncludeDepth},
+		{CodeIncludeEnv, "IncludeEnv", ErrIncludeEnv},
+		{CodeUnknownReference, "UnknownReference", ErrUnknownReference},
+		{CodeCircularReference, "CircularReference", ErrCircularReference},
+		{CodeDuplicateAnchor, "DuplicateAnchor", ErrDuplicateAnchor},
+		{CodeUnendedAnchor, "UnendedAnchor", ErrUnendedAnchor},
+		{CodeBadRange, "BadRange", ErrBadRange},
+		{CodeRangeTooLarge, "RangeTooLarge", ErrRangeTooLarge},
+		{CodeDuplicateKey, "DuplicateKey", ErrDuplicateKey},
+		{CodeDuplicateLabel, "DuplicateLabel", ErrDuplicateLabel},
+		{CodeExprUnits, "ExprUnits", ErrExprUnits},
+		{CodeExprDivide, "ExprDivide", ErrExprDivide},
+		{CodeExprArgs, "ExprArgs", ErrExprArgs},
+		{CodeNoSuchKey, "NoSuchKey", ErrNoSuchKey},
+		{CodeDecrypt, "Decrypt", ErrDecrypt},
+		{CodeChecksumTarget, "ChecksumTarget", ErrChecksumTarget},
+		{CodeChecksumMismatch, "ChecksumMismatch", nil},
+		{CodeTooLarge, "TooLarge", nil},
+		{CodePanic, "Panic", nil},
+		{CodeBadUTF16, "BadUTF16", ErrBadUTF16},
+		{CodeNoDecompressor, "NoDecompressor", ErrNoDecompressor},
+		{CodeIllegalLabel, "IllegalLabel", ErrIllegalLabel},
+		{CodeINISyntax, "INISyntax", ErrINISyntax},
+		{CodeDotEnvSyntax, "DotEnvSyntax", ErrDotEnvSyntax},
+		{CodeBadEscape, "BadEscape", ErrBadEscape},
+		{CodeJSONType, "JSONType", ErrJSONType},
+		{CodeUnsigned, "Unsigned", ErrUnsigned},
+		{CodeBadSignature, "BadSignature", ErrBadSignature},
+		{CodeSectionDelimiter, "SectionDelimiter", ErrSectionDelimiter},
+		{CodeSectionType, "SectionType", ErrSectionType},
+		{CodeConfigTypeName, "ConfigTypeName", ErrConfigType},
+		{CodeBadPattern, "BadPattern", ErrBadPattern},
+		{CodeBadOverride, "BadOverride", ErrBadOverride},
+		{CodeTemplate, "Template", nil},
+		{CodeNoSuchLabel, "NoSuchLabel", ErrNoSuchLabel},
+		{CodeWrongType, "WrongType", ErrWrongType},
+		{CodeNotPointer, "NotPointer", ErrNotPointer},
+		{CodeUnsupported, "Unsupported", ErrUnsupported},
+		{CodeDecoderType, "DecoderType", ErrDecoderType},
+		{CodeBadBool, "BadBool", nil},
+		{CodeNotAllowed, "NotAllowed", nil},
+		{CodePercentSum, "PercentSum", ErrPercentSum},
+		{CodeRelativeURL, "RelativeURL", ErrRelativeURL},
+		{CodeNoDefault, "NoDefault", ErrNoDefault},
+		{CodeNoSuchSecret, "NoSuchSecret", ErrNoSuchSecret},
+		{CodeUnknownSecretScheme, "UnknownSecretScheme", ErrUnknownSecretScheme},
+		{CodeUnknownLabel, "UnknownLabel", ErrUnknownLabel},
+		{CodeBadSchema, "BadSchema", ErrBadSchema},
+		{CodeOutOfRange, "OutOfRange", ErrOutOfRange},
+		{CodeNoMatch, "NoMatch", ErrNoMatch},
+		{CodeBadLength, "BadLength", ErrBadLength},
+		{CodeMissing, "Missing", nil},
+		{CodeRequires, "Requires", nil},
+		{CodeOrder, "Order", nil},
+		{CodeNotInArchive, "NotInArchive", ErrNotInArchive},
+		{CodeInlineEdit, "InlineEdit", ErrInlineEdit},
+		{CodePatchConflict, "PatchConflict", ErrPatchConflict},
+		{CodeReloaderClosed, "ReloaderClosed", ErrReloaderClosed},
+		{CodeNotFound, "NotFound", nil},
+		{CodeHTTP, "HTTP", nil},
+	}
+)
+
+/*
+	Returns the name of the code, e.g. "MissingEndDelimiter" for CFG001, ""
+	 for an unknown code
+*/
+func (c ErrorCode) Name() string {
+	for _, ec := range errorCodes {
+		if c == ec.code {
+			return ec.name
+		}
+	}
+	return ""
+}
+
+/*
+	Returns the code of the error, of the first error it wraps with one, ""
+	 for an error from outside the package (e.g. a file that can't be read)
+*/
+func CodeOf(err error) ErrorCode {
+	for nil != err {
+		for _, ec := range errorCodes {
+			if nil != ec.err && err == ec.err {
+				return ec.code
+			}
+		}
+		switch e := err.(type) {
+		case CodedError:
+			return e.Code()
+		case interface{ Unwrap() []error }:
+			return firstCode(e.Unwrap())
+		}
+		err = errors.Unwrap(err)
+	}
+	return ""
+}
+
+func (e *PathError) Code() ErrorCode      { return CodeOf(e.Err) }
+func (e *ListError) Code() ErrorCode      { return CodeOf(e.Err) }
+func (e *LineError) Code() ErrorCode      { return CodeOf(e.Err) }
+func (e *DocumentError) Code() ErrorCode  { return CodeOf(e.Err) }
+func (e *IncludeError) Code() ErrorCode   { return CodeOf(e.Err) }
+func (e *ReferenceError) Code() ErrorCode { return CodeOf(e.Err) }
+func (e *AnchorError) Code() ErrorCode    { return CodeOf(e.Err) }
+func (e *RefError) Code() ErrorCode       { return CodeOf(e.Err) }
+func (e *SecretError) Code() ErrorCode    { return CodeOf(e.Err) }
+func (e *MultiError) Code() ErrorCode     { return firstCode(e.Errs) }
+func (e *RuleError) Code() ErrorCode      { return firstCode(e.Errs) }
+func (e *ChecksumError) Code() ErrorCode  { return CodeChecksumMismatch }
+func (e *TooLargeError) Code() ErrorCode  { return CodeTooLarge }
+func (e *PanicError) Code() ErrorCode     { return CodePanic }
+func (e *BoolError) Code() ErrorCode      { return CodeBadBool }
+func (e *EnumError) Code() ErrorCode      { return CodeNotAllowed }
+func (e *MissingError) Code() ErrorCode   { return CodeMissing }
+func (e *RequiresError) Code() ErrorCode  { return CodeRequires }
+func (e *OrderError) Code() ErrorCode     { return CodeOrder }
+func (e *UnknownError) Code() ErrorCode   { return CodeUnknownLabel }
+func (e *NotFoundError) Code() ErrorCode  { return CodeNotFound }
+func (e *HTTPError) Code() ErrorCode      { return CodeHTTP }
+
+func (e *SchemaError) Code() ErrorCode {
+	errs := make([]error, len(e.Violations))
+	for i, v := range e.Violations {
+		errs[i] = v
+	}
+	return firstCode(errs)
+}
+
+func (e *TemplateError) Code() ErrorCode {
+	if c := CodeOf(e.Err); "" != c {
+		return c
+	}
+	return CodeTemplate
+}
+
+// ------------------------------------------------------------------------- //
+
+// firstCode returns the code of the first of the errors with one
+func firstCode(errs []error) ErrorCode {
+	for _, err := range errs {
+		if c := CodeOf(err); "" != c {
+			return c
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package cfg
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestErrorCodes(t *testing.T) {
+	_, err := Parse("b ( oops )\n")
+	if CodeIllegalInline != CodeOf(err) {
+		dbg.Error("CodeOf inline: %q %v", CodeOf(err), err)
+		t.Fail()
+	}
+	_, err = Parse("#sha256: 00\nlist [\n\ta\n]\n")
+	var ce CodedError
+	if !errors.As(err, &ce) || CodeChecksumMismatch != ce.Code() || "ChecksumMismatch" != ce.Code().Name() {
+		dbg.Error("CodedError checksum: %v", err)
+		t.Fail()
+	}
+
+	c, _ := Parse("n := x\n")
+	_, err = c.GetInt("missing")
+	if !errors.As(err, &ce) || CodeNoSuchLabel != ce.Code() || "CFG101" != string(CodeOf(err)) {
+		dbg.Error("CodedError missing: %v", err)
+		t.Fail()
+	}
+
+	for err, want := range map[error]ErrorCode{
+		ErrUnendedSection: CodeMissingEndDelimiter,
+		&PathError{"a", &ListError{"a", 1, "x", ErrBadRange}}: CodeBadRange,
+		&MultiError{[]error{os.ErrNotExist, &BoolError{"x"}}}: CodeBadBool,
+		&TemplateError{"t", 1, errors.New("bad")}:             CodeTemplate,
+		&PathError{"a", os.ErrNotExist}:                       "",
+		nil:                                                   "",
+	} {
+		if got := CodeOf(err); want != got {
+			dbg.Error("CodeOf %v: %q", err, got)
+			t.Fail()
+		}
+	}
+	if "MissingEndDelimiter" != CodeMissingEndDelimiter.Name() || "" != ErrorCode("CFG999").Name() {
+		dbg.Error("ErrorCode.Name")
+		t.Fail()
+	}
+
+	// each code is given once
+	seen := map[ErrorCode]bool{}
+	for _, ec := range errorCodes {
+		if seen[ec.code] || "" == ec.name {
+			dbg.Error("Error code %s repeated or unnamed", ec.code)
+			t.Fail()
+		}
+		seen[ec.code] = true
+	}
+}
@@ -0,0 +1,100 @@
+package cfg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"sync"
+)
+
+type (
+	/*
+		A Decompressor returns a reader of the decompressed form of the
+		 compressed data read by r
+	*/
+	Decompressor func(r io.Reader) (io.Reader, error)
+
+	magicDecompressor struct {
+		magic []byte
+		fn    Decompressor
+	}
+)
+
+var (
+	ErrNoDecompressor = errors.New("Compressed config data, no decompressor registered for its format")
+
+	decompressLock sync.RWMutex
+	decompressors  = []magicDecompressor{
+		{[]byte{0x1f, 0x8b}, func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		}},
+	}
+
+	// zstd, which needs a decompressor registered, see RegisterDecompressor
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+/*
+	Registers a decompressor for data starting with the magic bytes, used
+	 by the Load* functions so compressed config files are read as if they
+	 weren't; gzip is built in, and zstd can be added without this package
+	 depending on a zstd library, e.g.
+
+		cfg.RegisterDecompressor([]byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.Reader, error) {
+			return zstd.NewReader(r)
+		})
+
+	A later registration for the same magic bytes replaces the earlier one,
+	 and registering a nil fn removes it
+*/
+func RegisterDecompressor(magic []byte, fn Decompressor) {
+	decompressLock.Lock()
+	defer decompressLock.Unlock()
+	for i, d := range decompressors {
+		if bytes.Equal(d.magic, magic) {
+			decompressors = append(decompressors[:i:i], decompressors[i+1:]...)
+			break
+		}
+	}
+	if nil != fn {
+		decompressors = append(decompressors, magicDecompressor{append([]byte(nil), magic...), fn})
+	}
+}
+
+// ------------------------------------------------------------------------- //
+
+// decompress returns the data decompressed if it starts with the magic
+// bytes of a registered decompressor, otherwise the data unchanged; a
+// TooLargeError if max is set and it decompresses to more than max bytes
+func decompress(path string, data []byte, max int64) ([]byte, error) {
+	fn, err := findDecompressor(data)
+	if nil != err || nil == fn {
+		return data, err
+	}
+	r, err := fn(bytes.NewReader(data))
+	if nil != err {
+		return nil, err
+	}
+	if rc, ok := r.(io.Closer); ok {
+		defer rc.Close()
+	}
+	return readAll(r, path, max)
+}
+
+// findDecompressor returns the decompressor registered for the magic bytes
+// the data starts with, nil if it isn't compressed
+func findDecompressor(data []byte) (Decompressor, error) {
+	decompressLock.RLock()
+	defer decompressLock.RUnlock()
+	var fn Decompressor
+	for _, d := range decompressors {
+		if bytes.HasPrefix(data, d.magic) {
+			fn = d.fn
+		}
+	}
+	if nil == fn && bytes.HasPrefix(data, zstdMagic) {
+		return nil, ErrNoDecompressor
+	}
+	return fn, nil
+}
@@ -0,0 +1,64 @@
+package cfg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestCompressed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+
+	src, err := ioutil.ReadFile("testdata/testBlocks.cfg")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(src)
+	zw.Close()
+	gz := filepath.Join(dir, "testBlocks.cfg.gz")
+	ioutil.WriteFile(gz, buf.Bytes(), 0644)
+
+	want, _ := LoadConfig("testdata/testBlocks.cfg")
+	c, err := LoadConfig(gz)
+	if nil != err || !reflect.DeepEqual(want.Flatten(), c.Flatten()) {
+		dbg.Error("LoadConfig gzip: %v", err)
+		t.Fail()
+	}
+
+	zst := filepath.Join(dir, "app.cfg.zst")
+	ioutil.WriteFile(zst, []byte("\x28\xb5\x2f\xfd.cfg := y\n"), 0644)
+	if _, err := LoadConfig(zst); !errors.Is(err, ErrNoDecompressor) {
+		dbg.Error("LoadConfig zstd: %v", err)
+		t.Fail()
+	}
+	// a stand in for a zstd library
+	RegisterDecompressor([]byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.Reader, error) {
+		data, err := ioutil.ReadAll(r)
+		return strings.NewReader(string(data[5:])), err
+	})
+	defer RegisterDecompressor([]byte{0x28, 0xb5, 0x2f, 0xfd}, nil)
+	if c, err := LoadConfig(zst); nil != err || "y" != c.ValueOr("cfg", "") {
+		dbg.Error("LoadConfig registered: %v", err)
+		t.Fail()
+	}
+
+	ioutil.WriteFile(gz, buf.Bytes()[:20], 0644)
+	if _, err := LoadConfig(gz); nil == err {
+		dbg.Error("LoadConfig read truncated gzip data")
+		t.Fail()
+	}
+}
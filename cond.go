@@ -0,0 +1,126 @@
+package cfg
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+var (
+	ErrBadCondition   = errors.New("Malformed @if condition")
+	ErrUnbalancedCond = errors.New("Unbalanced @if / @else / @end")
+
+	// 1: if|elif|else|end  2: condition
+	condRex = regexp.MustCompile(`^\t*@(if|elif|else|end)\b[ \t]*(.*?)[ \t]*$`)
+	// 1: key  2: ==|!=  3: "value"  4: value
+	condExprRex = regexp.MustCompile(`^([\w:]+)[ \t]*(==|!=)[ \t]*(?:"([^"]*)"|(\S*))$`)
+)
+
+// ------------------------------------------------------------------------- //
+
+/*
+	conditionals removes the lines of any @if sections whose condition is not
+	 met, along with the directives themselves:
+
+		@if os == "linux"
+		log := /var/log/app
+		@elif env:STAGE != prod
+		log := ./app.log
+		@else
+		log := NUL
+		@end
+
+	A condition compares one of the keys below with a quoted or bare value:
+
+		os          runtime.GOOS
+		arch        runtime.GOARCH
+		hostname    os.Hostname(), or Options.Hostname
+		env:NAME    the environment variable NAME
+
+	Sections can be nested and the directives may have leading TABs so they
+	 can be used inside (data) containers
+*/
+func (o Options) conditionals(str string) (string, error) {
+	if !strings.Contains(str, "@") {
+		return str, nil
+	}
+	type state struct{ active, taken, parent bool }
+	stack := []state{}
+	active := true
+	lines := strings.Split(str, "\n")
+	result := lines[:0]
+	for i, line := range lines {
+		x := condRex.FindStringSubmatch(line)
+		if nil == x {
+			if active {
+				result = append(result, line)
+			}
+			continue
+		}
+		cerr := func(err error) error { return &LineError{i + 1, strings.TrimSpace(line), err} }
+		switch x[1] {
+		case "if":
+			ok, err := o.condition(x[2])
+			if nil != err {
+				return "", cerr(err)
+			}
+			stack = append(stack, state{active && ok, ok, active})
+		case "elif":
+			if 0 == len(stack) {
+				return "", cerr(ErrUnbalancedCond)
+			}
+			ok, err := o.condition(x[2])
+			if nil != err {
+				return "", cerr(err)
+			}
+			s := &stack[len(stack)-1]
+			s.active = s.parent && !s.taken && ok
+			s.taken = s.taken || ok
+		case "else":
+			if 0 == len(stack) || "" != x[2] {
+				return "", cerr(ErrUnbalancedCond)
+			}
+			s := &stack[len(stack)-1]
+			s.active = s.parent && !s.taken
+			s.taken = true
+		case "end":
+			if 0 == len(stack) || "" != x[2] {
+				return "", cerr(ErrUnbalancedCond)
+			}
+			stack = stack[:len(stack)-1]
+		}
+		active = true
+		if 0 != len(stack) {
+			active = stack[len(stack)-1].active
+		}
+	}
+	if 0 != len(stack) {
+		return "", &LineError{len(lines), "@if", ErrUnbalancedCond}
+	}
+	return strings.Join(result, "\n"), nil
+}
+
+// condition evaluates a single 'key == value' or 'key != value' condition
+func (o Options) condition(expr string) (bool, error) {
+	x := condExprRex.FindStringSubmatch(expr)
+	if nil == x {
+		return false, ErrBadCondition
+	}
+	var have string
+	switch {
+	case "os" == x[1]:
+		have = runtime.GOOS
+	case "arch" == x[1]:
+		have = runtime.GOARCH
+	case "hostname" == x[1]:
+		have = o.hostname()
+	case strings.HasPrefix(x[1], "env:"):
+		have = os.Getenv(x[1][4:])
+	default:
+		return false, ErrBadCondition
+	}
+	want := x[3] + x[4]
+	return (have == want) == ("==" == x[2]), nil
+}
@@ -0,0 +1,76 @@
+package cfg
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestConditionals(t *testing.T) {
+	os.Setenv("CFG_TEST_STAGE", "prod")
+	defer os.Unsetenv("CFG_TEST_STAGE")
+	src := `
+@if os == "` + runtime.GOOS + `"
+native := yes
+@else
+native := no
+@end
+@if env:CFG_TEST_STAGE == dev
+stage := dev
+@elif env:CFG_TEST_STAGE == prod
+stage := prod
+	@if arch != "` + runtime.GOARCH + `"
+stage := nested
+	@end
+@else
+stage := unknown
+@end
+group (
+	@if env:CFG_TEST_UNSET != ""
+	skipped := yes
+	@include not/resolved.cfg
+	@end
+	kept := yes
+)
+`
+	o := Options{Include: func(from, path string) (string, string, error) {
+		return "", "", os.ErrNotExist
+	}}
+	c, err := o.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"native":     "yes",
+		"stage":      "prod",
+		"group:kept": "yes",
+	}
+	for k, want := range expect {
+		if v, _ := c.Value(k); v != want {
+			dbg.Error("%s: %q", k, v)
+			t.Fail()
+		}
+	}
+	if _, ok := c.Value("group:skipped"); ok {
+		dbg.Error("group:skipped should not exist")
+		t.Fail()
+	}
+
+	var ce *LineError
+	if _, err = Parse("a := 1\n@if os = linux\n@end\n"); !errors.As(err, &ce) || 2 != ce.Line || !errors.Is(err, ErrBadCondition) {
+		dbg.Error("bad: %v", err)
+		t.Fail()
+	}
+	if _, err = Parse("@if os == linux\n"); !errors.Is(err, ErrUnbalancedCond) {
+		dbg.Error("unbalanced: %v", err)
+		t.Fail()
+	}
+	if _, err = Parse("@end\n"); !errors.Is(err, ErrUnbalancedCond) {
+		dbg.Error("unbalanced: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,557 @@
+package cfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	/*
+		A Node is a single entry in a parsed Config tree.  Group nodes, created
+		 for each (parens) data container, hold their entries in Children in
+		 the order found in the config data; all other nodes hold the same data
+		 that would be delivered to a HandleConfigData callback.
+	*/
+	Node struct {
+		Type     ConfigType
+		Label    string   // last element of the label path
+		Path     string   // full label path, e.g. "data:subdata:listData"
+		Data     []string // one entry for ConfigValue / ConfigBlock / ConfigBinary, key & value pairs for ConfigDict
+		Children []*Node  // only used by ConfigGroup nodes
+
+		// the attributes given in [ ] after the label of a section, a
+		//  name without a value having "", nil for none; see Attributes
+		Attrs map[string]string
+
+		parent *Node
+	}
+
+	/*
+		A Config is the result of parsing config data into a tree of Nodes that
+		 can be queried by label path rather than through a callback.
+	*/
+	Config struct {
+		root   Node
+		nodes  map[string]*Node
+		opts   Options
+		source string // path of the loaded config file
+		text   string // the text parsed, see Patch
+		units  []patchUnit
+		used   *usage   // the label paths read, see Unused
+		attrs  []string // the attributes of the next entry added
+
+		files  []cachedFile // the file & its includes as loaded, see Stale
+		loaded time.Time    // when the data was parsed
+
+		defaults *Config // the Options.Defaults parsed, see Deviations
+
+		encrypted map[*Node]encrypted // the @enc values decrypted
+
+		deprecated []Deprecation // the Options.Deprecated paths found
+	}
+
+	/*
+		A PathError records a failed lookup or conversion along with the label
+		 path that caused it
+	*/
+	PathError struct {
+		Path string
+		Err  error
+	}
+
+	/*
+		A LineError records a line of config data (or of an INI, .env or
+		 .properties file) that can't be read, along with its line number
+	*/
+	LineError struct {
+		Line int
+		Text string
+		Err  error
+	}
+)
+
+var (
+	ErrNoSuchLabel = errors.New("No such config label")
+	ErrWrongType   = errors.New("Config label is of the wrong type")
+
+	// label[index]
+	// 1: label  2: index
+	indexRex = regexp.MustCompile(`^(.+)\[(\d+)\]$`)
+)
+
+func (e *PathError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+func (e *LineError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + " \"" + e.Text + "\": " + e.Err.Error()
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	Parses the config data (see HandleConfigData) into a Config tree
+*/
+func Parse(str string) (*Config, error) {
+	return Options{}.Parse(str)
+}
+
+/*
+	Reads the config file and parses it into a Config tree
+*/
+func LoadConfig(flPath string) (*Config, error) {
+	return Options{}.LoadConfig(flPath)
+}
+
+/*
+	Returns the path of the file the config was loaded from, "" if the config
+	 was parsed from a string
+*/
+func (c *Config) Source() string {
+	return c.source
+}
+
+/*
+	Returns the node found at the label path, or nil if there is none
+
+	When a label is repeated the last entry is used, an earlier one can be
+	 selected by its index within the enclosing group, e.g. "server[0]:host"
+	 for the host of the first of a number of "server ( ... )" groups; all
+	 of the accessors accept indexed label paths
+*/
+func (c *Config) Lookup(path string) *Node {
+	if "" == path {
+		return &c.root
+	}
+	n := c.node(c.opts.internalPath(path))
+	c.use(n, true)
+	return n
+}
+
+/*
+	Returns every node found at the label path in the order they were found,
+	 e.g. the host of each of a number of repeated "server ( ... )" groups
+	 for the path "server:host"
+*/
+func (c *Config) LookupAll(path string) []*Node {
+	if "" == path {
+		return []*Node{&c.root}
+	}
+	nodes := c.lookup(c.opts.internalPath(path), false)
+	for _, n := range nodes {
+		c.use(n, true)
+	}
+	return nodes
+}
+
+/*
+	Returns the top level nodes of the config in the order they were found
+*/
+func (c *Config) Nodes() []*Node {
+	return c.root.Children
+}
+
+/*
+	Returns the entries of the group at the label path in the order they
+	 were found, the top level entries for ""; nil when the path isn't a
+	 group
+*/
+func (c *Config) Children(path string) []*Node {
+	n := c.Lookup(path)
+	if nil == n || ConfigGroup != n.Type {
+		return nil
+	}
+	return n.Children
+}
+
+/*
+	Calls f for each node of the tree depth first, in the order they were
+	 found, the entries of a group following it when f returns true for the
+	 group; comments kept by Options.KeepComments are included, so editors
+	 and formatters see the whole file
+*/
+func (c *Config) Walk(f func(n *Node) bool) {
+	var walk func(nodes []*Node)
+	walk = func(nodes []*Node) {
+		for _, n := range nodes {
+			if f(n) && ConfigGroup == n.Type {
+				walk(n.Children)
+			}
+		}
+	}
+	walk(c.root.Children)
+}
+
+/*
+	Returns a deep copy of the config, which can be changed without
+	 affecting the original
+*/
+func (c *Config) Clone() *Config {
+	clone := &Config{nodes: make(map[string]*Node, len(c.nodes)), opts: c.opts, source: c.source, files: c.files, loaded: c.loaded, text: c.text, used: &usage{}, defaults: c.defaults}
+	clone.deprecated = c.Deprecations()
+	copies := make(map[*Node]*Node)
+	var copyNode func(dst, src *Node)
+	copyNode = func(dst, src *Node) {
+		*dst = *src
+		if nil != src.Data {
+			dst.Data = append(make([]string, 0, len(src.Data)), src.Data...)
+		}
+		if nil != src.Attrs {
+			dst.Attrs = make(map[string]string, len(src.Attrs))
+			for k, v := range src.Attrs {
+				dst.Attrs[k] = v
+			}
+		}
+		if nil != src.Children {
+			dst.Children = make([]*Node, len(src.Children))
+		}
+		for i, ch := range src.Children {
+			n := &Node{}
+			copyNode(n, ch)
+			n.parent = dst
+			dst.Children[i] = n
+			copies[ch] = n
+		}
+	}
+	copyNode(&clone.root, &c.root)
+	for p, n := range c.nodes {
+		clone.nodes[p] = copies[n]
+	}
+	if nil != c.encrypted {
+		clone.encrypted = make(map[*Node]encrypted, len(c.encrypted))
+		for n, e := range c.encrypted {
+			clone.encrypted[copies[n]] = e
+		}
+	}
+	return clone
+}
+
+/*
+	Returns the string value for the label path; only ConfigValue and
+	 ConfigBlock entries have a value.  A @ref or @secret value that can't
+	 be resolved is false too, GetString giving why
+*/
+func (c *Config) Value(path string) (string, bool) {
+	n := c.node(c.opts.internalPath(path))
+	c.use(n, false)
+	if nil == n || (ConfigValue != n.Type && ConfigBlock != n.Type) {
+		return "", false
+	}
+	v, err := c.deref(n)
+	return v, nil == err
+}
+
+/*
+	Returns the dictionary for a ConfigDict label path
+*/
+func (c *Config) Dict(path string) (map[string]string, bool) {
+	n := c.node(c.opts.internalPath(path))
+	c.use(n, false)
+	if nil == n || ConfigDict != n.Type {
+		return nil, false
+	}
+	return n.dict(), true
+}
+
+/*
+	Returns the decoded data of a ConfigBinary label path
+*/
+func (c *Config) Binary(path string) ([]byte, bool) {
+	n := c.node(c.opts.internalPath(path))
+	c.use(n, false)
+	if nil == n || ConfigBinary != n.Type {
+		return nil, false
+	}
+	return []byte(n.Data[0]), true
+}
+
+/*
+	Returns the value for the label path, or def if there is no such value
+*/
+func (c *Config) ValueOr(path, def string) string {
+	if v, ok := c.Value(path); ok {
+		return v
+	}
+	return def
+}
+
+/*
+	Returns the first of the label paths that exists in the config, allowing
+	 layered keys to be used with any of the accessors, e.g.
+
+		d, err := c.GetDuration(c.FirstOf("service:timeout", "defaults:timeout"))
+
+	If none of the paths exist the first is returned, so any error reported
+	 refers to the preferred key
+*/
+func (c *Config) FirstOf(paths ...string) string {
+	for _, p := range paths {
+		if nil != c.node(c.opts.internalPath(p)) {
+			return p
+		}
+	}
+	if 0 == len(paths) {
+		return ""
+	}
+	return paths[0]
+}
+
+/*
+	Returns the value for the label path as a number of bytes, see ParseSize
+*/
+func (c *Config) GetBytes(path string) (int64, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return 0, err
+	}
+	n, err := ParseSize(v)
+	if nil != err {
+		return 0, &PathError{path, err}
+	}
+	return n, nil
+}
+
+/*
+	Returns the value for the label path as an integer, see ParseInt; when
+	 the Config was parsed with Options.DecimalOnly only decimal integers
+	 are accepted
+*/
+func (c *Config) GetInt(path string) (int64, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return 0, err
+	}
+	n, err := parseInt(v, c.opts.DecimalOnly)
+	if nil != err {
+		return 0, &PathError{path, err}
+	}
+	return n, nil
+}
+
+/*
+	Returns the value for the label path as a floating point number
+*/
+func (c *Config) GetFloat(path string) (float64, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if nil != err {
+		return 0, &PathError{path, err}
+	}
+	return f, nil
+}
+
+/*
+	Returns the value for the label path as a duration, see time.ParseDuration
+*/
+func (c *Config) GetDuration(path string) (time.Duration, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return 0, err
+	}
+	d, err := time.ParseDuration(v)
+	if nil != err {
+		return 0, &PathError{path, err}
+	}
+	return d, nil
+}
+
+/*
+	Returns the value for the label path as a file system path: a leading ~
+	 is replaced by the user's home directory, environment variables ($VAR or
+	 ${VAR}) are expanded and a relative path is made relative to the
+	 directory of the loaded config file, so it doesn't depend on the working
+	 directory; see Source
+*/
+func (c *Config) GetPath(path string) (string, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return "", err
+	}
+	v = os.ExpandEnv(v)
+	if "~" == v || strings.HasPrefix(v, "~/") {
+		home, err := os.UserHomeDir()
+		if nil != err {
+			return "", &PathError{path, err}
+		}
+		v = filepath.Join(home, v[1:])
+	}
+	if !filepath.IsAbs(v) && "" != c.source && "" != v {
+		v = filepath.Join(filepath.Dir(c.source), v)
+	}
+	return v, nil
+}
+
+/*
+	Returns the value for the label path as a boolean, see ParseBool
+*/
+func (c *Config) GetBool(path string) (bool, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return false, err
+	}
+	b, err := ParseBool(v)
+	if nil != err {
+		return false, &PathError{path, err}
+	}
+	return b, nil
+}
+
+/*
+	Returns the value for the label path validated against the allowed set,
+	 see ParseEnum
+*/
+func (c *Config) GetEnum(path string, allowed ...string) (string, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return "", err
+	}
+	e, err := ParseEnum(v, allowed...)
+	if nil != err {
+		return "", &PathError{path, err}
+	}
+	return e, nil
+}
+
+/*
+	Returns the string value for the label path, as Value does, or a
+	 *PathError: ErrNoSuchLabel, ErrWrongType or the *RefError or
+	 *SecretError of a value that can't be resolved
+*/
+func (c *Config) GetString(path string) (string, error) {
+	return c.value(path)
+}
+
+/*
+	Returns the value for the label path as a Secret, so it's masked if
+	 logged until Reveal is called
+*/
+func (c *Config) GetSecret(path string) (Secret, error) {
+	v, err := c.value(path)
+	return Secret(v), err
+}
+
+// ------------------------------------------------------------------------- //
+
+func newConfig() *Config {
+	c := &Config{nodes: make(map[string]*Node), used: &usage{}}
+	c.root.Type = ConfigGroup
+	return c
+}
+
+// node returns the node for the label path, which may select one of the
+// repeated entries of a label by index, e.g. "server[1]:host"
+func (c *Config) node(path string) *Node {
+	if n := c.nodes[path]; nil != n || !strings.Contains(path, "[") {
+		return n
+	}
+	nodes := c.lookup(path, true)
+	if 0 == len(nodes) {
+		return nil
+	}
+	return nodes[0]
+}
+
+// lookup walks the tree for the label path; when last is set only the last
+// of any repeated labels (or the indexed one) is followed, otherwise all
+// the matching entries are returned in file order
+func (c *Config) lookup(path string, last bool) []*Node {
+	nodes := []*Node{&c.root}
+	for _, elem := range strings.Split(path, ":") {
+		label, index := elem, -1
+		if x := indexRex.FindStringSubmatch(elem); nil != x {
+			label = x[1]
+			index, _ = strconv.Atoi(x[2])
+		}
+		next := []*Node{}
+		for _, n := range nodes {
+			matched := []*Node{}
+			for _, ch := range n.Children {
+				if label == ch.Label {
+					matched = append(matched, ch)
+				}
+			}
+			switch {
+			case index >= 0 && index < len(matched):
+				next = append(next, matched[index])
+			case index < 0 && last && 0 != len(matched):
+				next = append(next, matched[len(matched)-1])
+			case index < 0 && !last:
+				next = append(next, matched...)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// value is the error returning form of Value used by the typed accessors
+func (c *Config) value(path string) (string, error) {
+	n := c.node(c.opts.internalPath(path))
+	c.use(n, false)
+	if nil == n {
+		return "", &PathError{path, ErrNoSuchLabel}
+	}
+	if ConfigValue != n.Type && ConfigBlock != n.Type {
+		return "", &PathError{path, ErrWrongType}
+	}
+	v, err := c.deref(n)
+	if nil != err {
+		return "", &PathError{path, err}
+	}
+	return v, nil
+}
+
+// group returns the group node for the label path, creating it (and any of
+// its parents) if required
+func (c *Config) group(path string) *Node {
+	if "" == path {
+		return &c.root
+	}
+	if n := c.nodes[path]; nil != n && ConfigGroup == n.Type {
+		return n
+	}
+	parent, label := &c.root, path
+	if i := strings.LastIndex(path, ":"); i >= 0 {
+		parent, label = c.group(path[:i]), path[i+1:]
+	}
+	n := &Node{Type: ConfigGroup, Label: label, Path: path, parent: parent}
+	parent.Children = append(parent.Children, n)
+	c.nodes[path] = n
+	return n
+}
+
+// add is the walk callback used to build the tree; each ConfigGroup starts
+// a new group node, and a label that is repeated replaces the earlier entry
+// for lookups
+func (c *Config) add(t ConfigType, path string, data []string) {
+	if configAttributes == t {
+		c.attrs = data
+		return
+	}
+	parent, label := &c.root, path
+	if i := strings.LastIndex(path, ":"); i >= 0 {
+		parent, label = c.group(path[:i]), path[i+1:]
+	}
+	n := &Node{Type: t, Label: label, Path: path, Data: data, parent: parent}
+	if nil != c.attrs {
+		n.Attrs, c.attrs = pairsToMap(c.attrs), nil
+	}
+	parent.Children = append(parent.Children, n)
+	if ConfigComment != t {
+		c.nodes[path] = n
+	}
+}
@@ -0,0 +1,335 @@
+package cfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/pth"
+)
+
+const (
+	valueTypeTests = `
+cache := 64MiB
+buffer := 4 KB
+bogus := lots
+enabled := on
+disabled := No
+mode := 0o755
+mask := 0xFF
+count := 12
+ratio := 0.25
+`
+)
+
+func TestParse(t *testing.T) {
+	c, err := Parse(string(conf))
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if v, ok := c.Value("testData:lists:cherry"); !ok || v != "berry" {
+		dbg.Error("testData:lists:cherry: %s", v)
+		t.Fail()
+	}
+	if v, ok := c.Value("testData:blocks:block2"); !ok || v != blk2 {
+		dbg.Error("testData:blocks:block2: %s", v)
+		t.Fail()
+	}
+	if n := c.Lookup("testData:lists:items1"); nil == n || n.Type != ConfigItems || !compareEntries(itm1, n.Data) {
+		dbg.Error("testData:lists:items1: %v", n)
+		t.Fail()
+	}
+	if n := c.Lookup("testData:lines"); nil == n || n.Type != ConfigGroup || len(n.Children) != 4 {
+		dbg.Error("testData:lines: %v", n)
+		t.Fail()
+	}
+	if _, ok := c.Value("testData:lines"); ok {
+		dbg.Error("Group should not have a value")
+		t.Fail()
+	}
+	if nodes := c.Nodes(); nodes[0].Label != "block1" || nodes[len(nodes)-1].Label != "testData" {
+		dbg.Error("Unexpected top level nodes")
+		t.Fail()
+	}
+}
+
+func TestGetBytes(t *testing.T) {
+	c, _ := Parse(valueTypeTests)
+	if n, err := c.GetBytes("cache"); nil != err || n != 64<<20 {
+		dbg.Error("cache: %d %v", n, err)
+		t.Fail()
+	}
+	if n, err := c.GetBytes("buffer"); nil != err || n != 4000 {
+		dbg.Error("buffer: %d %v", n, err)
+		t.Fail()
+	}
+	if _, err := c.GetBytes("bogus"); nil == err {
+		dbg.Error("bogus should fail")
+		t.Fail()
+	}
+	if _, err := c.GetBytes("missing"); !errors.Is(err, ErrNoSuchLabel) {
+		dbg.Error("missing: %v", err)
+		t.Fail()
+	}
+}
+
+func TestGetBool(t *testing.T) {
+	c, _ := Parse(valueTypeTests)
+	if b, err := c.GetBool("enabled"); nil != err || !b {
+		dbg.Error("enabled: %v %v", b, err)
+		t.Fail()
+	}
+	if b, err := c.GetBool("disabled"); nil != err || b {
+		dbg.Error("disabled: %v %v", b, err)
+		t.Fail()
+	}
+	var be *BoolError
+	if _, err := c.GetBool("bogus"); !errors.As(err, &be) {
+		dbg.Error("bogus: %v", err)
+		t.Fail()
+	}
+}
+
+func TestGetInt(t *testing.T) {
+	c, _ := Parse(valueTypeTests)
+	if n, err := c.GetInt("mode"); nil != err || n != 0755 {
+		dbg.Error("mode: %d %v", n, err)
+		t.Fail()
+	}
+	if n, err := c.GetInt("mask"); nil != err || n != 255 {
+		dbg.Error("mask: %d %v", n, err)
+		t.Fail()
+	}
+	if f, err := c.GetFloat("ratio"); nil != err || f != 0.25 {
+		dbg.Error("ratio: %f %v", f, err)
+		t.Fail()
+	}
+	c, _ = Options{DecimalOnly: true}.Parse(valueTypeTests)
+	if _, err := c.GetInt("mask"); nil == err {
+		dbg.Error("mask should fail with DecimalOnly")
+		t.Fail()
+	}
+	if n, err := c.GetInt("count"); nil != err || n != 12 {
+		dbg.Error("count: %d %v", n, err)
+		t.Fail()
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	c, _ := Parse(string(conf))
+	if v := c.ValueOr("testData:apple", "none"); v != "tree" {
+		dbg.Error("testData:apple: %s", v)
+		t.Fail()
+	}
+	if v := c.ValueOr("testData:pear", "none"); v != "none" {
+		dbg.Error("testData:pear: %s", v)
+		t.Fail()
+	}
+	if p := c.FirstOf("testData:pear", "testData:lists:cherry", "testData:apple"); p != "testData:lists:cherry" {
+		dbg.Error("FirstOf: %s", p)
+		t.Fail()
+	}
+	if p := c.FirstOf("pear", "plum"); p != "pear" {
+		dbg.Error("FirstOf: %s", p)
+		t.Fail()
+	}
+}
+
+func TestDict(t *testing.T) {
+	c, _ := Parse(string(conf))
+	n := c.Lookup("dict1")
+	if nil == n || n.Type != ConfigDict || !compareEntries(n.Data, []string{"alpha", "apple sauce", "beta", "banana bread", "delta", "date soup"}) {
+		dbg.Error("dict1: %v", n)
+		t.Fail()
+	}
+	if d, ok := c.Dict("dict1"); !ok || d["beta"] != "banana bread" {
+		dbg.Error("dict1: %v", d)
+		t.Fail()
+	}
+	if _, ok := c.Dict("lines1"); ok {
+		dbg.Error("lines1 is not a dictionary")
+		t.Fail()
+	}
+	m, err := Get[map[string]string](c, "dict1")
+	if nil != err || len(m) != 3 || m["delta"] != "date soup" {
+		dbg.Error("dict1: %v %v", m, err)
+		t.Fail()
+	}
+}
+
+func TestBinaryBlocks(t *testing.T) {
+	const binary = `
+seed <hex
+	00 01 02 03
+	fe ff
+>
+keys (
+	cert <b64
+		aGVsbG8g
+		d29ybGQ=
+	>
+)
+`
+	c, err := Parse(binary)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if b, ok := c.Binary("seed"); !ok || string(b) != "\x00\x01\x02\x03\xfe\xff" {
+		dbg.Error("seed: %v", b)
+		t.Fail()
+	}
+	if b, err := Get[[]byte](c, "keys:cert"); nil != err || string(b) != "hello world" {
+		dbg.Error("keys:cert: %q %v", b, err)
+		t.Fail()
+	}
+	if v := c.Flatten()["keys:cert"]; v != "aGVsbG8gd29ybGQ=" {
+		dbg.Error("Flatten: %s", v)
+		t.Fail()
+	}
+	if _, err := Parse("bad <hex\nxyz\n>\n"); nil == err {
+		dbg.Error("Expected decode error")
+		t.Fail()
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	flPath := pth.AsRealPath("$/testdata/paths.cfg")
+	c, err := LoadConfig(flPath)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	os.Setenv("CFG_TEST_DIR", "/tmp/cfg")
+	defer os.Unsetenv("CFG_TEST_DIR")
+	home, _ := os.UserHomeDir()
+	expect := map[string]string{
+		"data": filepath.Join(filepath.Dir(flPath), "data/files"),
+		"abs":  "/var/lib/app",
+		"home": filepath.Join(home, "app"),
+		"env":  "/tmp/cfg/logs",
+	}
+	for k, want := range expect {
+		if v, err := c.GetPath(k); nil != err || v != want {
+			dbg.Error("%s: %q %v", k, v, err)
+			t.Fail()
+		}
+	}
+	if c.Source() != flPath {
+		dbg.Error("Source: %s", c.Source())
+		t.Fail()
+	}
+	c, _ = Parse("rel := a/b\n")
+	if v, _ := c.GetPath("rel"); v != "a/b" {
+		dbg.Error("rel: %s", v)
+		t.Fail()
+	}
+}
+
+func TestRepeatedGroups(t *testing.T) {
+	src := `
+name := cluster
+server (
+	host := alpha
+	port := 80
+)
+server (
+	host := beta
+)
+after := yes
+`
+	c, err := Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if 4 != len(c.Nodes()) {
+		dbg.Error("top level nodes: %d", len(c.Nodes()))
+		t.Fail()
+	}
+	for path, want := range map[string]string{
+		"server:host":    "beta",
+		"server[0]:host": "alpha",
+		"server[0]:port": "80",
+		"server[1]:host": "beta",
+		"after":          "yes",
+	} {
+		if v, _ := c.Value(path); v != want {
+			dbg.Error("%s: %q", path, v)
+			t.Fail()
+		}
+	}
+	if nil != c.Lookup("server[1]:port") || nil != c.Lookup("server[2]:host") {
+		dbg.Error("out of range lookups should fail")
+		t.Fail()
+	}
+	if hosts := c.LookupAll("server:host"); 2 != len(hosts) || "alpha" != hosts[0].Data[0] {
+		dbg.Error("LookupAll: %v", hosts)
+		t.Fail()
+	}
+
+	var servers struct {
+		Server []struct {
+			Host string
+			Port int
+		}
+	}
+	if err = c.Unmarshal(&servers); nil != err || 2 != len(servers.Server) || 80 != servers.Server[0].Port || "beta" != servers.Server[1].Host {
+		dbg.Error("Unmarshal: %+v %v", servers, err)
+		t.Fail()
+	}
+
+	got := []string{}
+	err = Options{IndexRepeats: true}.HandleConfigData(src, func(ct ConfigType, label string, data []string) {
+		got = append(got, label+"="+data[0])
+	})
+	expect := "name=cluster server[0]:host=alpha server[0]:port=80 server[1]:host=beta after=yes"
+	if nil != err || expect != strings.Join(got, " ") {
+		dbg.Error("IndexRepeats: %v %v", got, err)
+		t.Fail()
+	}
+}
+
+func TestWalk(t *testing.T) {
+	src := "zeta := 1\n# about db\ndb (\n\tport := 5432\n\thost := x\n\topts { b a }\n)\nalpha := 2\nserver (\n\tname := s1\n)\nserver (\n\tname := s2\n)\n"
+	c, err := Options{KeepComments: true}.Parse(src)
+	dbg.ChkErr(err, "Parse")
+
+	labels := func(nodes []*Node) string {
+		l := []string{}
+		for _, n := range nodes {
+			l = append(l, n.Label)
+		}
+		return strings.Join(l, " ")
+	}
+	if "zeta # db alpha server server" != labels(c.Children("")) || "port host opts" != labels(c.Children("db")) ||
+		"name" != labels(c.Children("server[0]")) || nil != c.Children("zeta") || nil != c.Children("none") {
+		dbg.Error("Children: %q %q", labels(c.Children("")), labels(c.Children("db")))
+		t.Fail()
+	}
+
+	got := []string{}
+	c.Walk(func(n *Node) bool {
+		got = append(got, n.Path)
+		return "db" != n.Path
+	})
+	if want := "zeta # db alpha server server:name server server:name"; want != strings.Join(got, " ") {
+		dbg.Error("Walk: %q", got)
+		t.Fail()
+	}
+	got = got[:0]
+	c.Walk(func(n *Node) bool {
+		if ConfigComment != n.Type {
+			got = append(got, n.Path)
+		}
+		return true
+	})
+	if want := "zeta db db:port db:host db:opts alpha server server:name server server:name"; want != strings.Join(got, " ") {
+		dbg.Error("Walk all: %q", got)
+		t.Fail()
+	}
+}
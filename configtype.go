@@ -0,0 +1,73 @@
+package cfg
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrConfigType = errors.New("Unknown config type name")
+
+	configTypeNames = []string{"Block", "Lines", "Items", "Value", "Group", "Dict", "Binary", "Table", "Comment"}
+)
+
+/*
+	Returns the name of the type, e.g. "Lines"; types of custom sections
+	 are "Custom" and "Custom+1" on, any others "ConfigType(n)"
+*/
+func (t ConfigType) String() string {
+	switch {
+	case t >= 0 && int(t) < len(configTypeNames):
+		return configTypeNames[t]
+	case ConfigCustom == t:
+		return "Custom"
+	case t > ConfigCustom:
+		return "Custom+" + strconv.Itoa(int(t-ConfigCustom))
+	}
+	return "ConfigType(" + strconv.Itoa(int(t)) + ")"
+}
+
+/*
+	Returns the ConfigType with the name given by String, ignoring case
+*/
+func ParseConfigType(name string) (ConfigType, error) {
+	for i, n := range configTypeNames {
+		if strings.EqualFold(n, name) {
+			return ConfigType(i), nil
+		}
+	}
+	if len(name) >= 6 && strings.EqualFold("Custom", name[:6]) {
+		if "" == name[6:] {
+			return ConfigCustom, nil
+		}
+		if n, err := strconv.Atoi(name[6:]); nil == err && n > 0 && '+' == name[6] {
+			return ConfigCustom + ConfigType(n), nil
+		}
+	}
+	if strings.HasPrefix(name, "ConfigType(") && strings.HasSuffix(name, ")") {
+		if n, err := strconv.Atoi(name[11 : len(name)-1]); nil == err {
+			return ConfigType(n), nil
+		}
+	}
+	return 0, ErrConfigType
+}
+
+/*
+	Encodes the type as its name, see String
+*/
+func (t ConfigType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+/*
+	Decodes a type name, see ParseConfigType
+*/
+func (t *ConfigType) UnmarshalText(text []byte) error {
+	ct, err := ParseConfigType(string(text))
+	if nil != err {
+		return err
+	}
+	*t = ct
+	return nil
+}
@@ -0,0 +1,51 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestConfigTypeNames(t *testing.T) {
+	for _, ct := range []ConfigType{ConfigBlock, ConfigLines, ConfigItems, ConfigValue, ConfigGroup,
+		ConfigDict, ConfigBinary, ConfigTable, ConfigComment, ConfigCustom, ConfigCustom + 2, 40} {
+		got, err := ParseConfigType(ct.String())
+		if nil != err || ct != got {
+			dbg.Error("%s parsed as %d: %v", ct, got, err)
+			t.Fail()
+		}
+	}
+	if "Items Custom+2 ConfigType(40)" != fmt.Sprint(ConfigItems, ConfigCustom+2, ConfigType(40)) {
+		dbg.Error("Names: %v", []ConfigType{ConfigItems, ConfigCustom + 2, 40})
+		t.Fail()
+	}
+	if ct, err := ParseConfigType("lines"); nil != err || ConfigLines != ct {
+		dbg.Error("lines: %v %v", ct, err)
+		t.Fail()
+	}
+	for _, bad := range []string{"", "Line", "Custom-1", "Custom+0", "Customx"} {
+		if _, err := ParseConfigType(bad); ErrConfigType != err {
+			dbg.Error("%q: %v", bad, err)
+			t.Fail()
+		}
+	}
+
+	var d struct {
+		Types map[string]ConfigType
+	}
+	b, _ := json.Marshal(map[string]interface{}{"Types": map[string]ConfigType{"a": ConfigDict}})
+	if `{"Types":{"a":"Dict"}}` != string(b) {
+		dbg.Error("Marshal: %s", b)
+		t.Fail()
+	}
+	if err := json.Unmarshal(b, &d); nil != err || ConfigDict != d.Types["a"] {
+		dbg.Error("Unmarshal: %v %v", d, err)
+		t.Fail()
+	}
+	if err := json.Unmarshal([]byte(`{"Types":{"a":"Nope"}}`), &d); nil == err {
+		dbg.Error("Unmarshal of a bad name succeeded")
+		t.Fail()
+	}
+}
@@ -0,0 +1,186 @@
+/*
+	Package consul reads config data from a key of the Consul KV store as a
+	 cfg.Source, through the Consul HTTP API so it needs no Consul library:
+
+		c, err := cfg.LoadSource(ctx, consul.New("app/config"))
+*/
+package consul
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	/*
+		A Source is a key of the Consul KV store, the zero value of each
+		 setting other than the Key using the Consul default
+	*/
+	Source struct {
+		// The HTTP API address, "" for $CONSUL_HTTP_ADDR or
+		//  http://127.0.0.1:8500
+		Addr string
+
+		// The KV key holding the config data
+		Key string
+
+		// The ACL token, "" for $CONSUL_HTTP_TOKEN
+		Token string
+
+		// The client used for the requests, nil for http.DefaultClient
+		Client *http.Client
+
+		// The longest a watch waits for a change before asking again, 0
+		//  for 5 minutes
+		Wait time.Duration
+
+		// The delay before a watch retries a failed request, 0 for 5
+		//  seconds
+		Retry time.Duration
+	}
+)
+
+var (
+	ErrNoKey = errors.New("No such Consul key")
+)
+
+/*
+	Returns the Source for the key using the default Consul settings
+*/
+func New(key string) *Source {
+	return &Source{Key: key}
+}
+
+/*
+	Returns the data of the key
+*/
+func (s *Source) Fetch(ctx context.Context) ([]byte, error) {
+	data, _, err := s.get(ctx, 0)
+	return data, err
+}
+
+/*
+	Sends the data of the key, then the new data each time it changes,
+	 using Consul blocking queries; failed requests are retried until the
+	 context is done, when the channel is closed
+*/
+func (s *Source) Watch(ctx context.Context) <-chan []byte {
+	ch := make(chan []byte)
+	go func() {
+		defer close(ch)
+		var last []byte
+		var index uint64
+		for nil == ctx.Err() {
+			data, next, err := s.get(ctx, index)
+			if nil != err {
+				if !sleep(ctx, s.retry()) {
+					return
+				}
+				continue
+			}
+			if next < index {
+				// the index went backwards, e.g. a restored snapshot
+				next = 0
+			}
+			index = next
+			if nil == last || !bytes.Equal(last, data) {
+				select {
+				case ch <- data:
+					last = data
+				case <-ctx.Done():
+					return
+				}
+			}
+			if 0 == index && !sleep(ctx, s.retry()) {
+				// without an index the next request wouldn't block
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// ------------------------------------------------------------------------- //
+
+// get reads the raw value of the key, as a blocking query waiting for a
+// change from the index unless it's 0, returning the new X-Consul-Index
+func (s *Source) get(ctx context.Context, index uint64) ([]byte, uint64, error) {
+	q := url.Values{"raw": {""}}
+	if 0 != index {
+		q.Set("index", strconv.FormatUint(index, 10))
+		q.Set("wait", strconv.Itoa(int(s.wait()/time.Second))+"s")
+	}
+	addr := s.Addr
+	if "" == addr {
+		addr = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if "" == addr {
+		addr = "http://127.0.0.1:8500"
+	} else if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/kv/"+strings.TrimPrefix(s.Key, "/")+"?"+q.Encode(), nil)
+	if nil != err {
+		return nil, 0, err
+	}
+	token := s.Token
+	if "" == token {
+		token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	if "" != token {
+		req.Header.Set("X-Consul-Token", token)
+	}
+	client := s.Client
+	if nil == client {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if nil != err {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	switch {
+	case http.StatusNotFound == resp.StatusCode:
+		return nil, next, ErrNoKey
+	case http.StatusOK != resp.StatusCode:
+		return nil, next, errors.New("Consul request for " + s.Key + " failed: " + resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	return data, next, err
+}
+
+func (s *Source) wait() time.Duration {
+	if 0 == s.Wait {
+		return 5 * time.Minute
+	}
+	return s.Wait
+}
+
+func (s *Source) retry() time.Duration {
+	if 0 == s.Retry {
+		return 5 * time.Second
+	}
+	return s.Retry
+}
+
+// sleep waits for the duration, returning false if the context is done
+// first
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
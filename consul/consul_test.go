@@ -0,0 +1,74 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/cfg"
+	"github.com/jayacarlson/dbg"
+)
+
+func TestSource(t *testing.T) {
+	var mu sync.Mutex
+	value, index := "port := 8080\n", uint64(10)
+	changed := make(chan bool, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if "/v1/kv/app/config" != r.URL.Path || "tok" != r.Header.Get("X-Consul-Token") {
+			http.NotFound(w, r)
+			return
+		}
+		if "" != r.URL.Query().Get("index") {
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+		w.Write([]byte(value))
+	}))
+	defer srv.Close()
+
+	src := &Source{Addr: srv.URL, Key: "app/config", Token: "tok", Retry: 10 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := cfg.LoadSource(ctx, src)
+	if port, _ := c.GetInt("port"); nil != err || 8080 != port {
+		dbg.Error("LoadSource: %d %v", port, err)
+		t.Fail()
+	}
+
+	ports := make(chan int64)
+	wctx, stop := context.WithCancel(ctx)
+	go cfg.WatchSource(wctx, src, func(c *cfg.Config, err error) {
+		port, _ := c.GetInt("port")
+		ports <- port
+	})
+	if port := <-ports; 8080 != port {
+		dbg.Error("WatchSource first: %d", port)
+		t.Fail()
+	}
+	mu.Lock()
+	value, index = "port := 9090\n", 11
+	mu.Unlock()
+	changed <- true
+	if port := <-ports; 9090 != port {
+		dbg.Error("WatchSource change: %d", port)
+		t.Fail()
+	}
+	stop()
+
+	if _, err := (&Source{Addr: srv.URL, Key: "none", Token: "tok"}).Fetch(ctx); !errors.Is(err, ErrNoKey) {
+		dbg.Error("Fetch missing key: %v", err)
+		t.Fail()
+	}
+}
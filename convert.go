@@ -0,0 +1,211 @@
+package cfg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+type (
+	/*
+		A BoolError is returned when a value is not one of the accepted
+		 boolean spellings
+	*/
+	BoolError struct {
+		Value string
+	}
+
+	/*
+		An EnumError is returned when a value is not one of the allowed set
+	*/
+	EnumError struct {
+		Value   string
+		Allowed []string
+	}
+)
+
+var (
+	// accepted boolean spellings, in the order they are listed in errors
+	boolTrue  = []string{"true", "yes", "on", "1", "t", "y"}
+	boolFalse = []string{"false", "no", "off", "0", "f", "n"}
+
+	// size suffixes, SI are powers of 1000, IEC are powers of 1024
+	sizeUnits = map[string]int64{
+		"":  1,
+		"B": 1,
+		"K": 1000, "KB": 1000, "KI": 1 << 10, "KIB": 1 << 10,
+		"M": 1000 * 1000, "MB": 1000 * 1000, "MI": 1 << 20, "MIB": 1 << 20,
+		"G": 1000 * 1000 * 1000, "GB": 1000 * 1000 * 1000, "GI": 1 << 30, "GIB": 1 << 30,
+		"T": 1e12, "TB": 1e12, "TI": 1 << 40, "TIB": 1 << 40,
+		"P": 1e15, "PB": 1e15, "PI": 1 << 50, "PIB": 1 << 50,
+		"E": 1e18, "EB": 1e18, "EI": 1 << 60, "EIB": 1 << 60,
+	}
+)
+
+func (e *BoolError) Error() string {
+	return "Invalid boolean \"" + e.Value + "\" -- expected one of: " +
+		strings.Join(boolTrue, ", ") + " / " + strings.Join(boolFalse, ", ")
+}
+
+func (e *EnumError) Error() string {
+	return "Invalid value \"" + e.Value + "\" -- expected one of: " + strings.Join(e.Allowed, ", ")
+}
+
+/*
+	Checks the value against the allowed set, ignoring case and leading /
+	 trailing whitespace; the matching entry from allowed is returned so the
+	 result always has the canonical spelling, otherwise an *EnumError
+*/
+func ParseEnum(s string, allowed ...string) (string, error) {
+	v := strings.TrimSpace(s)
+	for _, a := range allowed {
+		if strings.EqualFold(a, v) {
+			return a, nil
+		}
+	}
+	return "", &EnumError{s, allowed}
+}
+
+/*
+	Converts an integer value, accepting decimal as well as the Go style
+	 0x (hex), 0o (octal) and 0b (binary) prefixed forms, e.g. "0x1F",
+	 "0o755" and "0b1010"; a leading sign is allowed and the prefix is not
+	 case sensitive
+
+	Unlike strconv.ParseInt a plain leading zero does NOT make the value
+	 octal, "0755" is the decimal 755
+
+	Errors are returned as a *strconv.NumError with Func set to "ParseInt"
+*/
+func ParseInt(s string) (int64, error) {
+	return parseInt(s, false)
+}
+
+/*
+	Converts a boolean value, accepting the common synonyms regardless of case:
+
+		true:   true  yes  on   1  t  y
+		false:  false no   off  0  f  n
+
+	Leading / trailing whitespace is ignored; any other value returns a
+	 *BoolError
+*/
+func ParseBool(s string) (bool, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	for _, t := range boolTrue {
+		if t == v {
+			return true, nil
+		}
+	}
+	for _, f := range boolFalse {
+		if f == v {
+			return false, nil
+		}
+	}
+	return false, &BoolError{s}
+}
+
+/*
+	Converts a percentage, a decimal number followed by '%' with optional
+	 whitespace between them, e.g. "10%", "12.5 %" or "-3%"; the result is
+	 the number as written, so "10%" is 10 rather than 0.1
+
+	Errors are returned as a *strconv.NumError with Func set to "ParsePercent"
+*/
+func ParsePercent(s string) (float64, error) {
+	str := strings.TrimSpace(s)
+	if !strings.HasSuffix(str, "%") {
+		return 0, &strconv.NumError{Func: "ParsePercent", Num: s, Err: strconv.ErrSyntax}
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(str[:len(str)-1]), 64)
+	if nil != err {
+		return 0, &strconv.NumError{Func: "ParsePercent", Num: s, Err: err.(*strconv.NumError).Err}
+	}
+	return f, nil
+}
+
+func parseInt(s string, decimalOnly bool) (int64, error) {
+	str, neg := strings.TrimSpace(s), false
+	if "" != str && ('-' == str[0] || '+' == str[0]) {
+		str, neg = str[1:], '-' == str[0]
+	}
+	base := 10
+	if len(str) > 2 && '0' == str[0] && !decimalOnly {
+		switch str[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if 10 != base {
+			str = str[2:]
+		}
+	}
+	if "" == str || '-' == str[0] || '+' == str[0] {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrSyntax}
+	}
+	u, err := strconv.ParseUint(str, base, 64)
+	if nil != err {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: err.(*strconv.NumError).Err}
+	}
+	if neg {
+		if u > 1<<63 {
+			return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+		}
+		return -int64(u), nil
+	}
+	if u > math.MaxInt64 {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+	}
+	return int64(u), nil
+}
+
+/*
+	Converts a human readable size into a number of bytes
+
+	The size is a decimal number, optionally with a fraction, followed by an
+	 optional unit suffix; whitespace between the number and the unit is
+	 allowed and the suffix is not case sensitive:
+
+		SI  suffixes:  K KB M MB G GB T TB P PB E EB    (powers of 1000)
+		IEC suffixes:  Ki KiB Mi MiB Gi GiB ... EiB     (powers of 1024)
+
+	e.g. "512", "10KB", "4MiB", "2G" and "1.5 GB" are all valid sizes
+
+	Errors are returned as a *strconv.NumError with Func set to "ParseSize"
+*/
+func ParseSize(s string) (int64, error) {
+	str := strings.TrimSpace(s)
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return (r < '0' || r > '9') && '.' != r
+	})
+	if i < 0 {
+		i = len(str)
+	}
+	num, unit := str[:i], strings.ToUpper(strings.TrimSpace(str[i:]))
+	mult, ok := sizeUnits[unit]
+	if "" == num || !ok {
+		return 0, &strconv.NumError{Func: "ParseSize", Num: s, Err: strconv.ErrSyntax}
+	}
+	if !strings.Contains(num, ".") {
+		n, err := strconv.ParseInt(num, 10, 64)
+		if nil != err {
+			return 0, &strconv.NumError{Func: "ParseSize", Num: s, Err: err.(*strconv.NumError).Err}
+		}
+		if n > math.MaxInt64/mult {
+			return 0, &strconv.NumError{Func: "ParseSize", Num: s, Err: strconv.ErrRange}
+		}
+		return n * mult, nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if nil != err {
+		return 0, &strconv.NumError{Func: "ParseSize", Num: s, Err: strconv.ErrSyntax}
+	}
+	f *= float64(mult)
+	if f >= math.MaxInt64 {
+		return 0, &strconv.NumError{Func: "ParseSize", Num: s, Err: strconv.ErrRange}
+	}
+	return int64(f), nil
+}
@@ -0,0 +1,90 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestParseSize(t *testing.T) {
+	good := map[string]int64{
+		"512":    512,
+		"10KB":   10000,
+		"10kb":   10000,
+		"4MiB":   4 << 20,
+		"2G":     2000000000,
+		"1.5 GB": 1500000000,
+		"1KiB":   1024,
+		"8EiB":   0, // overflow, checked below
+	}
+	for s, want := range good {
+		n, err := ParseSize(s)
+		if "8EiB" == s {
+			if nil == err {
+				dbg.Error("ParseSize(%s) did not overflow", s)
+				t.Fail()
+			}
+			continue
+		}
+		if nil != err || n != want {
+			dbg.Error("ParseSize(%s) = %d, %v", s, n, err)
+			t.Fail()
+		}
+	}
+	for _, s := range []string{"", "KB", "10XB", "1.2.3M", "-5K"} {
+		if _, err := ParseSize(s); nil == err {
+			dbg.Error("ParseSize(%s) should fail", s)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	good := map[string]bool{
+		"yes": true, "On": true, "TRUE": true, "1": true, " y ": true,
+		"no": false, "OFF": false, "False": false, "0": false, "n": false,
+	}
+	for s, want := range good {
+		if b, err := ParseBool(s); nil != err || b != want {
+			dbg.Error("ParseBool(%s) = %v, %v", s, b, err)
+			t.Fail()
+		}
+	}
+	_, err := ParseBool("maybe")
+	if be, ok := err.(*BoolError); !ok || be.Value != "maybe" || !strings.Contains(err.Error(), "yes") {
+		dbg.Error("ParseBool(maybe) = %v", err)
+		t.Fail()
+	}
+}
+
+func TestParseInt(t *testing.T) {
+	good := map[string]int64{
+		"42": 42, "-42": -42, "0755": 755, "0x1F": 31, "0X1f": 31,
+		"0o755": 0755, "0b1010": 10, "-0x10": -16, "+7": 7,
+	}
+	for s, want := range good {
+		if n, err := ParseInt(s); nil != err || n != want {
+			dbg.Error("ParseInt(%s) = %d, %v", s, n, err)
+			t.Fail()
+		}
+	}
+	for _, s := range []string{"", "0x", "0b102", "1.5", "--1", "0x-1", "99999999999999999999"} {
+		if _, err := ParseInt(s); nil == err {
+			dbg.Error("ParseInt(%s) should fail", s)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseEnum(t *testing.T) {
+	if v, err := ParseEnum(" DEBUG ", "debug", "info", "warn"); nil != err || v != "debug" {
+		dbg.Error("ParseEnum: %s %v", v, err)
+		t.Fail()
+	}
+	_, err := ParseEnum("loud", "debug", "info")
+	if ee, ok := err.(*EnumError); !ok || len(ee.Allowed) != 2 || !strings.Contains(err.Error(), "debug, info") {
+		dbg.Error("ParseEnum: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,167 @@
+package cfg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+type (
+	/*
+		A KeyProvider gives the AES key (16, 24 or 32 bytes) of a key id, used
+		 to decrypt the @enc(id:data) values of a config, see Options.Keys
+	*/
+	KeyProvider interface {
+		Key(id string) ([]byte, error)
+	}
+
+	/*
+		A KeyFunc is a function used as a KeyProvider
+	*/
+	KeyFunc func(id string) ([]byte, error)
+
+	/*
+		StaticKeys is a KeyProvider of keys held in memory, by id
+	*/
+	StaticKeys map[string][]byte
+
+	// encrypted is an @enc value as parsed, the id of its key, its text
+	// and the plain text it was decrypted to
+	encrypted struct {
+		id, text, plain string
+	}
+)
+
+var (
+	ErrNoSuchKey = errors.New("No such config encryption key")
+	ErrDecrypt   = errors.New("Config value can't be decrypted")
+
+	// @enc(id:data)
+	// 1: key id  2: base64 nonce & sealed data
+	encRex = regexp.MustCompile(`^@enc\(([\w.-]*):([A-Za-z0-9+/=]+)\)$`)
+)
+
+func (f KeyFunc) Key(id string) ([]byte, error) {
+	return f(id)
+}
+
+func (k StaticKeys) Key(id string) ([]byte, error) {
+	if key, ok := k[id]; ok {
+		return key, nil
+	}
+	return nil, ErrNoSuchKey
+}
+
+/*
+	Encrypts the value with AES-GCM under the key of the id, giving the
+	 @enc(id:data) text to write in its place, e.g. with Set; the value is
+	 decrypted when the config is parsed with Options.Keys
+*/
+func EncryptValue(keys KeyProvider, id, value string) (string, error) {
+	gcm, err := keyCipher(keys, id)
+	if nil != err {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); nil != err {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return "@enc(" + id + ":" + base64.StdEncoding.EncodeToString(sealed) + ")", nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// decrypt replaces each @enc(id:data) value with its plain text, any failure
+// is a *PathError; each value decrypted is made sensitive, so String & Dump
+// redact it, and noted so WriteTo writes it encrypted
+func (c *Config) decrypt(keys KeyProvider) error {
+	var err error
+	c.flatten(&c.root, func(n *Node) {
+		if nil != err || ConfigValue != n.Type || !strings.HasPrefix(n.Data[0], "@enc(") {
+			return
+		}
+		v, id, e := decryptValue(keys, n.Data[0])
+		if nil != e {
+			err = &PathError{n.Path, e}
+			return
+		}
+		if nil == c.encrypted {
+			c.encrypted = make(map[*Node]encrypted)
+		}
+		if !c.sensitive(n.Path) {
+			c.MarkSensitive(matchQuote(n.Path))
+		}
+		c.encrypted[n] = encrypted{id, n.Data[0], v}
+		n.Data[0] = v
+	})
+	return err
+}
+
+// seal returns the value as written for the node: as is, unless the node
+// held an @enc value decrypted, which is the @enc text as parsed or, for a
+// changed value, the value encrypted again with the same key
+func (c *Config) seal(n *Node, value string) (string, error) {
+	e, ok := c.encrypted[n]
+	switch {
+	case !ok:
+		return value, nil
+	case e.plain == value:
+		return e.text, nil
+	case nil == c.opts.Keys:
+		return "", &PathError{n.Path, ErrNoSuchKey}
+	}
+	return EncryptValue(c.opts.Keys, e.id, value)
+}
+
+// decryptValue returns the plain text of the @enc(id:data) value and the id
+// of its key
+func decryptValue(keys KeyProvider, v string) (string, string, error) {
+	x := encRex.FindStringSubmatch(v)
+	if nil == x {
+		return "", "", ErrDecrypt
+	}
+	gcm, err := keyCipher(keys, x[1])
+	if nil != err {
+		return "", "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(x[2])
+	if nil != err || len(sealed) < gcm.NonceSize() {
+		return "", "", ErrDecrypt
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if nil != err {
+		return "", "", ErrDecrypt
+	}
+	return string(plain), x[1], nil
+}
+
+// matchQuote escapes the pattern characters of the label path, giving a
+// Sensitive pattern matching just that path
+func matchQuote(lp string) string {
+	var b strings.Builder
+	for _, r := range lp {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// keyCipher returns the AES-GCM cipher of the key id
+func keyCipher(keys KeyProvider, id string) (cipher.AEAD, error) {
+	key, err := keys.Key(id)
+	if nil != err {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if nil != err {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
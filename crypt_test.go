@@ -0,0 +1,94 @@
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestEncryptedValues(t *testing.T) {
+	keys := StaticKeys{"k1": []byte("0123456789abcdef0123456789abcdef")}
+	enc, err := EncryptValue(keys, "k1", "s3cret")
+	if nil != err || !strings.HasPrefix(enc, "@enc(k1:") || strings.Contains(enc, "s3cret") {
+		dbg.Error("EncryptValue: %s %v", enc, err)
+		t.FailNow()
+	}
+	src := "db (\n\tpassword := " + enc + "\n\turl := pg://app:${password}@host\n)\n"
+	c, err := Options{Keys: keys, Interpolate: true}.Parse(src)
+	if nil != err || "s3cret" != c.ValueOr("db:password", "") || "pg://app:s3cret@host" != c.ValueOr("db:url", "") {
+		dbg.Error("@enc: %v %v", err, c)
+		t.Fail()
+	}
+	if c, _ = Parse(src); enc != c.ValueOr("db:password", "") {
+		dbg.Error("@enc decrypted without Options.Keys")
+		t.Fail()
+	}
+
+	var pe *PathError
+	if _, err = (Options{Keys: StaticKeys{}}).Parse(src); !errors.As(err, &pe) || "db:password" != pe.Path || !errors.Is(err, ErrNoSuchKey) {
+		dbg.Error("@enc unknown key: %v", err)
+		t.Fail()
+	}
+	other := StaticKeys{"k1": []byte("fedcba9876543210fedcba9876543210")}
+	if _, err = (Options{Keys: other}).Parse(src); !errors.Is(err, ErrDecrypt) {
+		dbg.Error("@enc wrong key: %v", err)
+		t.Fail()
+	}
+	if _, err = (Options{Keys: keys}).Parse("a := @enc(k1:!!)\n"); !errors.Is(err, ErrDecrypt) {
+		dbg.Error("@enc bad data: %v", err)
+		t.Fail()
+	}
+}
+
+func TestEncryptedParsers(t *testing.T) {
+	keys := StaticKeys{"k1": []byte("0123456789abcdef0123456789abcdef")}
+	enc, _ := EncryptValue(keys, "k1", "s3cret")
+	o := Options{Keys: keys}
+
+	var got string
+	err := o.HandleConfigData("password := "+enc+"\n", func(t ConfigType, label string, data []string) {
+		got = data[0]
+	})
+	if nil != err || "s3cret" != got {
+		dbg.Error("HandleConfigData @enc: %q %v", got, err)
+		t.Fail()
+	}
+	ini, err := o.ParseINI("[db]\npassword = " + enc + "\n")
+	if nil != err || "s3cret" != ini.ValueOr("db:password", "") {
+		dbg.Error("ParseINI @enc: %v", err)
+		t.Fail()
+	}
+	props, err := o.ParseProperties("db.password=" + enc + "\n")
+	if nil != err || "s3cret" != props.ValueOr("db:password", "") {
+		dbg.Error("ParseProperties @enc: %v", err)
+		t.Fail()
+	}
+
+	// the plain text isn't written out
+	c, _ := o.Parse("db (\n\tpassword := " + enc + "\n)\n")
+	var buf bytes.Buffer
+	c.Dump(&buf, DumpOptions{})
+	if strings.Contains(c.String(), "s3cret") || strings.Contains(buf.String(), "s3cret") {
+		dbg.Error("@enc value shown:\n%s\n%s", c, buf.String())
+		t.Fail()
+	}
+	buf.Reset()
+	c.Clone().WriteTo(&buf)
+	if !strings.Contains(buf.String(), "password := "+enc) {
+		dbg.Error("WriteTo @enc:\n%s", buf.String())
+		t.Fail()
+	}
+
+	// a changed value is encrypted again with its key
+	c.root.Children[0].Children[0].Data[0] = "n3w"
+	buf.Reset()
+	c.WriteTo(&buf)
+	c, err = o.Parse(buf.String())
+	if nil != err || strings.Contains(buf.String(), "n3w") || "n3w" != c.ValueOr("db:password", "") {
+		dbg.Error("WriteTo changed @enc: %v\n%s", err, buf.String())
+		t.Fail()
+	}
+}
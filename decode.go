@@ -0,0 +1,402 @@
+package cfg
+
+import (
+	"encoding"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	/*
+		A MultiError lists every error found decoding or validating a config,
+		 rather than just the first, so they can all be fixed at once; each
+		 is usually a *PathError or *ListError naming the label path, and
+		 errors.Is and errors.As check each of them
+	*/
+	MultiError struct {
+		Errs []error
+	}
+)
+
+var (
+	ErrNotPointer    = errors.New("Unmarshal requires a non-nil pointer")
+	ErrUnsupported   = errors.New("Unsupported type for config decoding")
+	textUnmarshalerT = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationT        = reflect.TypeOf(time.Duration(0))
+	secretT          = reflect.TypeOf(Secret(""))
+)
+
+func (e *MultiError) Error() string {
+	s := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		s[i] = err.Error()
+	}
+	return strconv.Itoa(len(e.Errs)) + " config errors: " + strings.Join(s, "; ")
+}
+
+func (e *MultiError) Unwrap() []error {
+	return e.Errs
+}
+
+/*
+	Decodes the whole Config into the struct pointed to by v, see Get for the
+	 conversion rules used for each field; with Options.Strict entries that
+	 no field is decoded from give an *UnknownError.  Decoding carries on
+	 past a bad value, when more than one is found a *MultiError lists them
+*/
+func (c *Config) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if reflect.Ptr != rv.Kind() || rv.IsNil() {
+		return ErrNotPointer
+	}
+	errs := appendError(nil, c.decode(rv.Elem(), &c.root))
+	if c.opts.Strict {
+		errs = appendError(errs, c.strict(rv.Elem().Type(), &c.root))
+	}
+	return joinErrors(errs)
+}
+
+/*
+	Decodes the entry at the label path into a value of type T
+
+	Struct fields are matched to the labels of a ConfigGroup, either by a
+	 `cfg:"label"` tag or by the field name (ignoring case); a tag of "-"
+	 skips the field and labels missing from the config leave the field
+	 untouched.  Nested structs decode from sub groups, slices from
+	 ConfigItems or ConfigLines (or from each group of a repeated group
+	 label, or each sub-list for a slice of slices), slices of structs or
+	 maps from a ConfigTable, maps with string keys from a ConfigDict,
+	 []byte also from a ConfigBinary and everything else from a ConfigValue or ConfigBlock using:
+
+		RegisterDecoder             registered decoders are used first
+		encoding.TextUnmarshaler    UnmarshalText, used before any of below
+		string                      the value as is
+		bool                        ParseBool
+		int*, uint*                 ParseInt (honoring Options.DecimalOnly)
+		float*                      strconv.ParseFloat
+		time.Duration               time.ParseDuration
+		pointers                    allocated and decoded into
+*/
+func Get[T any](c *Config, path string) (T, error) {
+	var v T
+	n := &c.root
+	if "" != path {
+		n = c.node(c.opts.internalPath(path))
+	}
+	if nil == n {
+		return v, &PathError{path, ErrNoSuchLabel}
+	}
+	errs := appendError(nil, c.decode(reflect.ValueOf(&v).Elem(), n))
+	if c.opts.Strict {
+		errs = appendError(errs, c.strict(reflect.TypeOf(&v).Elem(), n))
+	}
+	return v, joinErrors(errs)
+}
+
+// ------------------------------------------------------------------------- //
+
+// appendError adds the error, or each of a *MultiError, to the errors
+func appendError(errs []error, err error) []error {
+	if me, ok := err.(*MultiError); ok {
+		return append(errs, me.Errs...)
+	}
+	if nil != err {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// joinErrors returns nil for no errors, a single error as is and a
+// *MultiError for more
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return &MultiError{errs}
+}
+
+// decode decodes the node into rv, carrying on past bad entries of a group
+// or list and returning the errors of all of them
+func (c *Config) decode(rv reflect.Value, n *Node) error {
+	c.use(n, false)
+	if err := validateNode(n); nil != err {
+		return err
+	}
+	if ConfigValue == n.Type || ConfigBlock == n.Type {
+		if fn := findDecoder(rv.Type(), n.Path); nil != fn {
+			v, err := c.deref(n)
+			if nil == err {
+				err = setDecoded(rv, fn, v)
+			}
+			if nil != err {
+				return &PathError{n.Path, err}
+			}
+			return nil
+		}
+	}
+	if reflect.Ptr == rv.Kind() && !rv.Type().Implements(textUnmarshalerT) {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return c.decode(rv.Elem(), n)
+	}
+	if reflect.Map == rv.Kind() && !isTextUnmarshaler(rv) {
+		if ConfigDict != n.Type {
+			return &PathError{n.Path, ErrWrongType}
+		}
+		rt := rv.Type()
+		if reflect.String != rt.Key().Kind() {
+			return &PathError{n.Path, ErrUnsupported}
+		}
+		m := reflect.MakeMapWithSize(rt, len(n.Data)/2)
+		var errs []error
+		for i := 0; i+1 < len(n.Data); i += 2 {
+			v := reflect.New(rt.Elem()).Elem()
+			if err := c.decodeString(v, n.Data[i+1]); nil != err {
+				errs = append(errs, &PathError{n.Path + ":" + n.Data[i], err})
+				continue
+			}
+			m.SetMapIndex(reflect.ValueOf(n.Data[i]).Convert(rt.Key()), v)
+		}
+		rv.Set(m)
+		return joinErrors(errs)
+	}
+	if reflect.Interface == rv.Kind() && 0 == rv.NumMethod() && ConfigValue != n.Type && ConfigBlock != n.Type {
+		rv.Set(reflect.ValueOf(c.mapEntry(n)))
+		return nil
+	}
+	if isTextUnmarshaler(rv) || (reflect.Struct != rv.Kind() && reflect.Slice != rv.Kind()) {
+		if ConfigValue != n.Type && ConfigBlock != n.Type {
+			return &PathError{n.Path, ErrWrongType}
+		}
+		v, err := c.deref(n)
+		if nil == err {
+			err = c.decodeString(rv, v)
+		}
+		if nil != err {
+			return &PathError{n.Path, err}
+		}
+		return nil
+	}
+	if reflect.Slice == rv.Kind() && reflect.Uint8 == rv.Type().Elem().Kind() && ConfigBinary == n.Type {
+		rv.SetBytes([]byte(n.Data[0]))
+		return nil
+	}
+	if reflect.Slice == rv.Kind() && ConfigTable == n.Type {
+		rows, err := LinesToTable(n.Data)
+		if nil != err {
+			err.(*ListError).Path = n.Path
+			return err
+		}
+		l := reflect.MakeSlice(rv.Type(), len(rows), len(rows))
+		var errs []error
+		for i, row := range rows {
+			if err := c.decodeRow(l.Index(i), row); nil != err {
+				errs = append(errs, &ListError{n.Path, i, n.Data[i+1], err})
+			}
+		}
+		rv.Set(l)
+		return joinErrors(errs)
+	}
+	if reflect.Slice == rv.Kind() && nil != n.parent && (ConfigGroup == n.Type || isListOfLists(rv.Type(), n)) {
+		// a slice of each of the repeated groups (or sub-lists) with the label
+		groups := []*Node{}
+		for _, ch := range n.parent.Children {
+			if n.Label == ch.Label && n.Type == ch.Type {
+				groups = append(groups, ch)
+			}
+		}
+		l := reflect.MakeSlice(rv.Type(), len(groups), len(groups))
+		var errs []error
+		for i, g := range groups {
+			errs = appendError(errs, c.decode(l.Index(i), g))
+		}
+		rv.Set(l)
+		return joinErrors(errs)
+	}
+	if reflect.Slice == rv.Kind() {
+		if ConfigItems != n.Type && ConfigLines != n.Type {
+			return &PathError{n.Path, ErrWrongType}
+		}
+		l := reflect.MakeSlice(rv.Type(), len(n.Data), len(n.Data))
+		fn := findDecoder(rv.Type().Elem(), n.Path)
+		var errs []error
+		for i, s := range n.Data {
+			var err error
+			if nil != fn {
+				err = setDecoded(l.Index(i), fn, s)
+			} else {
+				err = c.decodeString(l.Index(i), s)
+			}
+			if nil != err {
+				errs = append(errs, &ListError{n.Path, i, s, err})
+			}
+		}
+		rv.Set(l)
+		return joinErrors(errs)
+	}
+	if ConfigGroup != n.Type {
+		return &PathError{n.Path, ErrWrongType}
+	}
+	rt := rv.Type()
+	var errs []error
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if "" != sf.PkgPath {
+			continue
+		}
+		label := sf.Tag.Get("cfg")
+		if "-" == label {
+			continue
+		}
+		child := n.child(label, sf.Name)
+		if nil == child {
+			continue
+		}
+		errs = appendError(errs, c.decode(rv.Field(i), child))
+	}
+	return joinErrors(errs)
+}
+
+func (c *Config) decodeString(rv reflect.Value, s string) error {
+	if isTextUnmarshaler(rv) {
+		if reflect.Ptr == rv.Kind() {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			return rv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+		return rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if durationT == rv.Type() {
+		d, err := time.ParseDuration(s)
+		if nil == err {
+			rv.SetInt(int64(d))
+		}
+		return err
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Bool:
+		b, err := ParseBool(s)
+		if nil != err {
+			return err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := parseInt(s, c.opts.DecimalOnly)
+		if nil != err {
+			return err
+		}
+		if rv.OverflowInt(n) {
+			return &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := parseInt(s, c.opts.DecimalOnly)
+		if nil != err {
+			return err
+		}
+		if n < 0 || rv.OverflowUint(uint64(n)) {
+			return &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+		}
+		rv.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if nil != err {
+			return err
+		}
+		rv.SetFloat(f)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return c.decodeString(rv.Elem(), s)
+	case reflect.Interface:
+		if 0 != rv.NumMethod() {
+			return ErrUnsupported
+		}
+		rv.Set(reflect.ValueOf(c.inferred(s)))
+	default:
+		return ErrUnsupported
+	}
+	return nil
+}
+
+// decodeRow decodes a table row into a map with string keys, or a struct
+// with its fields matched to the column names as labels are to fields
+func (c *Config) decodeRow(rv reflect.Value, row map[string]string) error {
+	if reflect.Ptr == rv.Kind() {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return c.decodeRow(rv.Elem(), row)
+	}
+	rt := rv.Type()
+	switch {
+	case reflect.Map == rv.Kind() && reflect.String == rt.Key().Kind():
+		m := reflect.MakeMapWithSize(rt, len(row))
+		for k, s := range row {
+			v := reflect.New(rt.Elem()).Elem()
+			if err := c.decodeString(v, s); nil != err {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(rt.Key()), v)
+		}
+		rv.Set(m)
+	case reflect.Struct == rv.Kind():
+		for i := 0; i < rt.NumField(); i++ {
+			sf := rt.Field(i)
+			label := sf.Tag.Get("cfg")
+			if "" != sf.PkgPath || "-" == label {
+				continue
+			}
+			for col, s := range row {
+				if "" == s {
+					continue // a missing cell leaves the field untouched
+				}
+				if ("" != label && col == label) || ("" == label && strings.EqualFold(col, sf.Name)) {
+					if err := c.decodeString(rv.Field(i), s); nil != err {
+						return err
+					}
+				}
+			}
+		}
+	default:
+		return ErrUnsupported
+	}
+	return nil
+}
+
+// isListOfLists reports whether a list node is to be decoded along with any
+// repeats of its label into a slice of slices, e.g. [][]string
+func isListOfLists(rt reflect.Type, n *Node) bool {
+	et := rt.Elem()
+	return (ConfigItems == n.Type || ConfigLines == n.Type) && reflect.Slice == et.Kind() &&
+		reflect.Uint8 != et.Elem().Kind() && !et.Implements(textUnmarshalerT)
+}
+
+func isTextUnmarshaler(rv reflect.Value) bool {
+	return rv.Type().Implements(textUnmarshalerT) ||
+		(rv.CanAddr() && reflect.PtrTo(rv.Type()).Implements(textUnmarshalerT))
+}
+
+// child finds the entry of a group node by its tagged label, or failing
+// that by the field name ignoring case; as with lookups the last of any
+// repeated labels is used
+func (n *Node) child(label, name string) *Node {
+	for i := len(n.Children) - 1; i >= 0; i-- {
+		ch := n.Children[i]
+		if ("" != label && ch.Label == label) || ("" == label && strings.EqualFold(ch.Label, name)) {
+			return ch
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package cfg
+
+import (
+	"errors"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+type (
+	testLevel int
+
+	testServer struct {
+		Addr    netip.Addr
+		Port    uint16
+		Level   testLevel     `cfg:"log_level"`
+		Timeout time.Duration `cfg:"timeout"`
+		Tags    []string
+		Weights []float64
+		Ignored string `cfg:"-"`
+	}
+
+	testApp struct {
+		Name    string
+		Debug   bool
+		Server  testServer
+		Backups *testServer `cfg:"backup"`
+		Levels  []testLevel `cfg:"levels"`
+	}
+)
+
+const (
+	decodeTests = `
+name := demo
+debug := yes
+ignored := not used
+server (
+	addr := 10.0.0.1
+	port := 8080
+	log_level := warn
+	timeout := 5s
+	ignored := skipped
+	tags {
+		web frontend
+	}
+	weights {
+		0.5 1.5
+	}
+)
+backup (
+	addr := ::1
+	port := 0x50
+)
+levels {
+	debug warn
+}
+badPort := 70000
+badLevel := loud
+`
+)
+
+func (l *testLevel) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "debug":
+		*l = 1
+	case "warn":
+		*l = 2
+	default:
+		return errors.New("Unknown level")
+	}
+	return nil
+}
+
+func TestUnmarshal(t *testing.T) {
+	c, _ := Parse(decodeTests)
+	var app testApp
+	if err := c.Unmarshal(&app); nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if app.Name != "demo" || !app.Debug {
+		dbg.Error("name/debug: %v", app)
+		t.Fail()
+	}
+	s := app.Server
+	if s.Addr.String() != "10.0.0.1" || s.Port != 8080 || s.Level != 2 || s.Timeout != 5*time.Second || s.Ignored != "" {
+		dbg.Error("server: %v", s)
+		t.Fail()
+	}
+	if !compareEntries(s.Tags, []string{"web", "frontend"}) || len(s.Weights) != 2 || s.Weights[1] != 1.5 {
+		dbg.Error("server lists: %v", s)
+		t.Fail()
+	}
+	if nil == app.Backups || app.Backups.Addr.String() != "::1" || app.Backups.Port != 80 {
+		dbg.Error("backup: %v", app.Backups)
+		t.Fail()
+	}
+	if len(app.Levels) != 2 || app.Levels[0] != 1 {
+		dbg.Error("levels: %v", app.Levels)
+		t.Fail()
+	}
+}
+
+func TestGetGeneric(t *testing.T) {
+	c, _ := Parse(decodeTests)
+	if a, err := Get[netip.Addr](c, "server:addr"); nil != err || a.String() != "10.0.0.1" {
+		dbg.Error("server:addr: %v %v", a, err)
+		t.Fail()
+	}
+	if l, err := Get[testLevel](c, "server:log_level"); nil != err || l != 2 {
+		dbg.Error("server:log_level: %v %v", l, err)
+		t.Fail()
+	}
+	if _, err := Get[testLevel](c, "badLevel"); nil == err {
+		dbg.Error("badLevel should fail")
+		t.Fail()
+	}
+	if _, err := Get[uint16](c, "badPort"); nil == err {
+		dbg.Error("badPort should fail")
+		t.Fail()
+	}
+	if _, err := Get[string](c, "server"); !errors.Is(err, ErrWrongType) {
+		dbg.Error("server: %v", err)
+		t.Fail()
+	}
+}
+
+func TestUnmarshalAllErrors(t *testing.T) {
+	var v struct {
+		Port    int
+		Timeout time.Duration
+		Hosts   []int
+		Limits  map[string]int
+		Server  []struct{ Weight uint }
+		Name    string
+	}
+	c, _ := Parse("port := eighty\ntimeout := 5\nhosts { 1 x 3 y }\nlimits : [\n\ta : 1\n\tb : z\n]\n" +
+		"server (\n\tweight := -1\n)\nserver (\n\tweight := 2\n)\nname := ok\n")
+	err := c.Unmarshal(&v)
+	var me *MultiError
+	if !errors.As(err, &me) || 6 != len(me.Errs) || "ok" != v.Name || 2 != v.Server[1].Weight || 1 != v.Limits["a"] {
+		dbg.Error("Unmarshal all errors: %v", err)
+		t.FailNow()
+	}
+	paths := []string{}
+	for _, e := range me.Errs {
+		var pe *PathError
+		var le *ListError
+		switch {
+		case errors.As(e, &pe):
+			paths = append(paths, pe.Path)
+		case errors.As(e, &le):
+			paths = append(paths, le.Path+"["+strconv.Itoa(le.Index)+"]")
+		}
+	}
+	if want := "port timeout hosts[1] hosts[3] limits:b server:weight"; want != strings.Join(paths, " ") {
+		dbg.Error("Unmarshal all errors paths: %v", paths)
+		t.Fail()
+	}
+
+	c, _ = Parse("port := eighty\n")
+	if _, ok := c.Unmarshal(&v).(*PathError); !ok {
+		dbg.Error("Unmarshal single error not a *PathError")
+		t.Fail()
+	}
+	c, _ = Options{Strict: true}.Parse("port := eighty\nprot := 1\n")
+	if err = c.Unmarshal(&v); !errors.As(err, &me) || 2 != len(me.Errs) || !errors.Is(err, ErrUnknownLabel) {
+		dbg.Error("Unmarshal strict all errors: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,120 @@
+package cfg
+
+import (
+	"reflect"
+	"strings"
+)
+
+/*
+	Decodes the lines of a ConfigLines entry (e.g. as given to a
+	 HandleConfigLines callback) into the value pointed to by v, so lines
+	 holding small records needn't be split and copied field by field:
+
+		limits [
+			cpu : 2
+			memory : 512
+		]
+		users [
+			alice  1001  admin
+			bob    1002
+		]
+
+	A struct (or map with string keys) is decoded from 'key : value'
+	 lines, the keys matched to the fields as labels are by Get and lines
+	 without a ':' ignored.  A slice has an element for each line; a slice
+	 of structs has each line split at whitespace into columns, decoded
+	 into the fields in order (a field tagged `cfg:"-"` skipped) with any
+	 missing columns leaving the last fields untouched, while a slice of
+	 anything else decodes each line whole.  Values are converted as by
+	 Get, decoding carrying on past a bad value: each is a *PathError for
+	 its key, or a *ListError for its line, more than one being returned
+	 as a *MultiError
+*/
+func DecodeLines(lines []string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if reflect.Ptr != rv.Kind() || rv.IsNil() {
+		return ErrNotPointer
+	}
+	c, rv := newConfig(), rv.Elem()
+	if reflect.Slice != rv.Kind() || isTextUnmarshaler(rv) {
+		var errs []error
+		pairs := StringListToPairs(lines)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			// each pair is decoded on its own to know which is bad, a map
+			// being given the entry decoded
+			row, into := map[string]string{pairs[i]: pairs[i+1]}, rv
+			if reflect.Map == rv.Kind() {
+				into = reflect.New(rv.Type()).Elem()
+			}
+			if err := c.decodeRow(into, row); nil != err {
+				errs = append(errs, &PathError{pairs[i], err})
+				continue
+			}
+			if reflect.Map == rv.Kind() {
+				if rv.IsNil() {
+					rv.Set(reflect.MakeMap(rv.Type()))
+				}
+				for _, k := range into.MapKeys() {
+					rv.SetMapIndex(k, into.MapIndex(k))
+				}
+			}
+		}
+		return joinErrors(errs)
+	}
+	l := reflect.MakeSlice(rv.Type(), len(lines), len(lines))
+	et := rv.Type().Elem()
+	for reflect.Ptr == et.Kind() {
+		et = et.Elem()
+	}
+	var cols []string
+	if reflect.Struct == et.Kind() && !reflect.PtrTo(et).Implements(textUnmarshalerT) {
+		cols = fieldColumns(et)
+	}
+	var errs []error
+	for i, line := range lines {
+		var err error
+		if nil == cols {
+			err = c.decodeString(l.Index(i), line)
+		} else {
+			err = c.decodeColumns(l.Index(i), cols, strings.Fields(line))
+		}
+		if nil != err {
+			errs = append(errs, &ListError{"", i, line, err})
+		}
+	}
+	rv.Set(l)
+	return joinErrors(errs)
+}
+
+// ------------------------------------------------------------------------- //
+
+// fieldColumns returns the names of the fields of the struct type the
+// columns of a line are decoded into, in order, see DecodeLines
+func fieldColumns(rt reflect.Type) []string {
+	cols := []string{}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		label := sf.Tag.Get("cfg")
+		if "" != sf.PkgPath || "-" == label {
+			continue
+		}
+		if "" == label {
+			label = sf.Name
+		}
+		cols = append(cols, label)
+	}
+	return cols
+}
+
+// decodeColumns decodes the cells of a line into the struct fields named
+// by the columns
+func (c *Config) decodeColumns(rv reflect.Value, cols, cells []string) error {
+	if len(cells) > len(cols) {
+		return ErrTableColumns
+	}
+	row := make(map[string]string, len(cells))
+	for i, cell := range cells {
+		row[cols[i]] = cell
+	}
+	return c.decodeRow(rv, row)
+}
@@ -0,0 +1,69 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestDecodeLines(t *testing.T) {
+	type limits struct {
+		CPU     int
+		Memory  int64 `cfg:"mem"`
+		Timeout time.Duration
+		Skipped string `cfg:"-"`
+	}
+	var l limits
+	err := DecodeLines([]string{"cpu : 2", "mem : 0x200", "no separator", "timeout : 5s", "skipped : x"}, &l)
+	if nil != err || !reflect.DeepEqual(limits{2, 512, 5 * time.Second, ""}, l) {
+		dbg.Error("DecodeLines struct: %+v %v", l, err)
+		t.Fail()
+	}
+
+	var m map[string]int
+	if err := DecodeLines([]string{"a : 1", "b : x", "c : 3"}, &m); !reflect.DeepEqual(map[string]int{"a": 1, "c": 3}, m) {
+		dbg.Error("DecodeLines map: %v %v", m, err)
+		t.Fail()
+	} else if pe, ok := err.(*PathError); !ok || "b" != pe.Path {
+		dbg.Error("DecodeLines map error: %v", err)
+		t.Fail()
+	}
+
+	type user struct {
+		Name  string
+		UID   int
+		Role  string `cfg:"role"`
+		notes string
+	}
+	var users []user
+	err = DecodeLines([]string{"alice  1001  admin", "bob 1002", "carol x", "dave 1 a b"}, &users)
+	want := []user{{"alice", 1001, "admin", ""}, {"bob", 1002, "", ""}, {"carol", 0, "", ""}, {}}
+	if !reflect.DeepEqual(want, users) {
+		dbg.Error("DecodeLines records: %+v", users)
+		t.Fail()
+	}
+	var me *MultiError
+	var le *ListError
+	if !errors.As(err, &me) || 2 != len(me.Errs) || !errors.As(me.Errs[0], &le) || 2 != le.Index || !errors.Is(me.Errs[1], ErrTableColumns) {
+		dbg.Error("DecodeLines records errors: %v", err)
+		t.Fail()
+	}
+
+	var ptrs []*user
+	if err := DecodeLines([]string{"erin 7"}, &ptrs); nil != err || 1 != len(ptrs) || 7 != ptrs[0].UID {
+		dbg.Error("DecodeLines pointers: %v", err)
+		t.Fail()
+	}
+	var ports []uint16
+	if err := DecodeLines([]string{"80", "443"}, &ports); nil != err || !reflect.DeepEqual([]uint16{80, 443}, ports) {
+		dbg.Error("DecodeLines values: %v %v", ports, err)
+		t.Fail()
+	}
+	if err := DecodeLines([]string{"a : 1"}, l); ErrNotPointer != err {
+		dbg.Error("DecodeLines of a non-pointer: %v", err)
+		t.Fail()
+	}
+}
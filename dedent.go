@@ -0,0 +1,62 @@
+package cfg
+
+import (
+	"strings"
+)
+
+/*
+	Returns the text with the leading whitespace its lines have in common
+	 removed, so its first line (or those as far left) start at the left;
+	 lines of only whitespace are emptied and don't count, e.g.
+
+		    SELECT *
+		      FROM t
+
+	becomes "SELECT *\n  FROM t".  TABs and spaces are only in common when
+	 the lines use the same ones
+*/
+func Dedent(text string) string {
+	lines := strings.Split(text, "\n")
+	prefix := commonIndent(lines)
+	for i, l := range lines {
+		if "" == strings.TrimSpace(l) {
+			lines[i] = ""
+		} else {
+			lines[i] = l[len(prefix):]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ------------------------------------------------------------------------- //
+
+// commonIndent returns the leading whitespace the lines that aren't blank
+// have in common
+func commonIndent(lines []string) string {
+	prefix, found := "", false
+	for _, l := range lines {
+		if "" == strings.TrimSpace(l) {
+			continue
+		}
+		indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if !found {
+			prefix, found = indent, true
+			continue
+		}
+		i := 0
+		for i < len(prefix) && i < len(indent) && prefix[i] == indent[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return prefix
+}
+
+// dedent returns the block text dedented when Options.DedentBlocks or
+// marked is set, otherwise as it is
+func (o Options) dedent(text string, marked bool) string {
+	if !o.DedentBlocks && !marked {
+		return text
+	}
+	return Dedent(text)
+}
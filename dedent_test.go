@@ -0,0 +1,74 @@
+package cfg
+
+import (
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestDedent(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"    SELECT *\n      FROM t", "SELECT *\n  FROM t"},
+		{"  a\n\n \n  b", "a\n\n\nb"},
+		{"\t a\n\t\tb", " a\n\tb"},
+		{"a\n  b", "a\n  b"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := Dedent(test.text); test.want != got {
+			dbg.Error("Dedent %q: %q", test.text, got)
+			t.Fail()
+		}
+	}
+}
+
+func TestDedentBlocks(t *testing.T) {
+	src := "db (\n\tquery <|\n\t    SELECT *\n\t      FROM t\n\t>\n\tnotes <\n\t  as is\n\t>\n" +
+		"\tdoc <<END\n\t  heredoc\n\tEND\n)\n"
+	c, err := Parse(src)
+	if nil != err {
+		dbg.Error("Parse <|: %v", err)
+		t.FailNow()
+	}
+	for path, want := range map[string]string{"db:query": "SELECT *\n  FROM t", "db:notes": "  as is", "db:doc": "  heredoc"} {
+		if got, _ := c.Value(path); want != got {
+			dbg.Error("Parse <| %s: %q", path, got)
+			t.Fail()
+		}
+	}
+	c, err = Options{DedentBlocks: true}.Parse(src)
+	if nil != err {
+		dbg.Error("DedentBlocks: %v", err)
+		t.FailNow()
+	}
+	for path, want := range map[string]string{"db:notes": "as is", "db:doc": "heredoc"} {
+		if got, _ := c.Value(path); want != got {
+			dbg.Error("DedentBlocks %s: %q", path, got)
+			t.Fail()
+		}
+	}
+
+	blocks := map[string]string{}
+	HandleConfigBlocks("sql <|\n  x\n   y\n>\n", func(label, block string) {
+		blocks[label] = block
+	})
+	if "x\n y" != blocks["sql"] {
+		dbg.Error("HandleConfigBlocks <|: %q", blocks)
+		t.Fail()
+	}
+	var types []ConfigType
+	Scan([]byte("sql <|\n  x\n>\n"), func(e RawEntry) error {
+		types = append(types, e.Type)
+		return nil
+	})
+	if 1 != len(types) || ConfigBlock != types[0] {
+		dbg.Error("Scan <|: %v", types)
+		t.Fail()
+	}
+	if 1 != len(Lint([]byte("list [|\na\n]\n"))) {
+		dbg.Error("Lint [|: %v", Lint([]byte("list [|\na\n]\n")))
+		t.Fail()
+	}
+}
@@ -0,0 +1,166 @@
+package cfg
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoDefault = errors.New("No default config set")
+
+	defaultLock   sync.RWMutex
+	defaultConfig *Config
+)
+
+/*
+	Sets the config read by the package level accessors (Value, Int, Bool
+	 etc), so a small program can load its config once in main and read its
+	 settings anywhere:
+
+		c, err := cfg.LoadConfig("app.cfg")
+		...
+		cfg.SetDefault(c)
+		...
+		port, err := cfg.Int("db:port")
+
+	Setting nil removes the default; the config shouldn't be changed once
+	 it's the default, set a new one instead (e.g. from a Reloader's Subscribe)
+*/
+func SetDefault(c *Config) {
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+	defaultConfig = c
+}
+
+/*
+	Returns the config set by SetDefault, nil if there isn't one
+*/
+func Default() *Config {
+	defaultLock.RLock()
+	defer defaultLock.RUnlock()
+	return defaultConfig
+}
+
+/*
+	As Config.Value for the default config, false if there isn't one
+*/
+func Value(path string) (string, bool) {
+	c := Default()
+	if nil == c {
+		return "", false
+	}
+	return c.Value(path)
+}
+
+/*
+	As Config.ValueOr for the default config, def if there isn't one
+*/
+func ValueOr(path, def string) string {
+	c := Default()
+	if nil == c {
+		return def
+	}
+	return c.ValueOr(path, def)
+}
+
+/*
+	As Config.Dict for the default config, false if there isn't one
+*/
+func Dict(path string) (map[string]string, bool) {
+	c := Default()
+	if nil == c {
+		return nil, false
+	}
+	return c.Dict(path)
+}
+
+/*
+	As Config.GetInt for the default config; without one the error is a
+	 *PathError wrapping ErrNoDefault, as for each of the accessors below
+*/
+func Int(path string) (int64, error) {
+	c, err := defaultFor(path)
+	if nil != err {
+		return 0, err
+	}
+	return c.GetInt(path)
+}
+
+/*
+	As Config.GetFloat for the default config
+*/
+func Float(path string) (float64, error) {
+	c, err := defaultFor(path)
+	if nil != err {
+		return 0, err
+	}
+	return c.GetFloat(path)
+}
+
+/*
+	As Config.GetBool for the default config
+*/
+func Bool(path string) (bool, error) {
+	c, err := defaultFor(path)
+	if nil != err {
+		return false, err
+	}
+	return c.GetBool(path)
+}
+
+/*
+	As Config.GetDuration for the default config
+*/
+func Duration(path string) (time.Duration, error) {
+	c, err := defaultFor(path)
+	if nil != err {
+		return 0, err
+	}
+	return c.GetDuration(path)
+}
+
+/*
+	As Config.GetBytes for the default config
+*/
+func Bytes(path string) (int64, error) {
+	c, err := defaultFor(path)
+	if nil != err {
+		return 0, err
+	}
+	return c.GetBytes(path)
+}
+
+/*
+	As Config.GetStringList for the default config
+*/
+func StringList(path string) ([]string, error) {
+	c, err := defaultFor(path)
+	if nil != err {
+		return nil, err
+	}
+	return c.GetStringList(path)
+}
+
+/*
+	As Config.Unmarshal for the default config
+*/
+func Unmarshal(v interface{}) error {
+	c := Default()
+	if nil == c {
+		return ErrNoDefault
+	}
+	return c.Unmarshal(v)
+}
+
+// ------------------------------------------------------------------------- //
+
+// defaultFor returns the default config, or the error for reading the label
+// path without one
+func defaultFor(path string) (*Config, error) {
+	c := Default()
+	if nil == c {
+		return nil, &PathError{path, ErrNoDefault}
+	}
+	return c, nil
+}
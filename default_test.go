@@ -0,0 +1,54 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestDefault(t *testing.T) {
+	SetDefault(nil)
+	if _, err := Int("db:port"); !errors.Is(err, ErrNoDefault) {
+		dbg.Error("Int without default: %v", err)
+		t.Fail()
+	}
+	if v, ok := Value("name"); ok || "x" != ValueOr("name", "x") || nil != Default() {
+		dbg.Error("Value without default: %q", v)
+		t.Fail()
+	}
+	var v struct{ Name string }
+	if ErrNoDefault != Unmarshal(&v) {
+		dbg.Error("Unmarshal without default")
+		t.Fail()
+	}
+
+	c, err := Parse("name := app\ndb (\n\tport := 5432\n\tratio := 0.5\n\tssl := true\n\ttimeout := 2s\n\tcache := 1KiB\n)\nhosts { a b }\nenv : [\n\tk : v\n]\n")
+	dbg.ChkErr(err, "Parse")
+	SetDefault(c)
+	defer SetDefault(nil)
+	port, err1 := Int("db:port")
+	ratio, err2 := Float("db:ratio")
+	ssl, err3 := Bool("db:ssl")
+	timeout, err4 := Duration("db:timeout")
+	cache, err5 := Bytes("db:cache")
+	hosts, err6 := StringList("hosts")
+	env, _ := Dict("env")
+	name, _ := Value("name")
+	errs := appendError(appendError(appendError(nil, err1), err2), err3)
+	errs = appendError(appendError(appendError(errs, err4), err5), err6)
+	if 0 != len(errs) || 5432 != port || 0.5 != ratio || !ssl || 2*time.Second != timeout || 1024 != cache ||
+		2 != len(hosts) || "v" != env["k"] || "app" != name || c != Default() {
+		dbg.Error("accessors: %v", errs)
+		t.Fail()
+	}
+	if _, err := Int("name"); nil == err || "def" != ValueOr("db:none", "def") {
+		dbg.Error("bad value: %v", err)
+		t.Fail()
+	}
+	if err := Unmarshal(&v); nil != err || "app" != v.Name {
+		dbg.Error("Unmarshal: %v %q", err, v.Name)
+		t.Fail()
+	}
+}
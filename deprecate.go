@@ -0,0 +1,96 @@
+package cfg
+
+import (
+	"strings"
+
+	"github.com/jayacarlson/dbg"
+)
+
+type (
+	/*
+		A Deprecation names a label path that is no longer used, with any
+		 path replacing it and a message for whoever edits the config; a
+		 group path covers every entry in the group
+	*/
+	Deprecation struct {
+		Path        string
+		Replacement string // "" if it has no replacement
+		Message     string
+	}
+)
+
+func (d Deprecation) String() string {
+	s := "Config label " + d.Path + " is deprecated"
+	if "" != d.Replacement {
+		s += ", use " + d.Replacement
+	}
+	if "" != d.Message {
+		s += " -- " + d.Message
+	}
+	return s
+}
+
+/*
+	Returns the deprecated label paths (see Options.Deprecated and
+	 Schema.Deprecated) found while parsing or validating the config, each
+	 once, in the order they were found
+*/
+func (c *Config) Deprecations() []Deprecation {
+	return append([]Deprecation{}, c.deprecated...)
+}
+
+/*
+	Declares a deprecated label path; Validate passes it to the config's
+	 Options.OnDeprecated (or logs it) if the config has the path, and adds
+	 it to the config's Deprecations
+*/
+func (s *Schema) Deprecated(path, replacement, message string) *Schema {
+	s.deprecated = append(s.deprecated, Deprecation{path, replacement, message})
+	return s
+}
+
+// ------------------------------------------------------------------------- //
+
+// deprecations wraps the handler to report the first entry found for each
+// of the Deprecated label paths, adding it to the config if c is set; the
+// paths are checked before any Aliases rename them
+func (o Options) deprecations(c *Config, f func(t ConfigType, label string, data []string)) func(t ConfigType, label string, data []string) {
+	if 0 == len(o.Deprecated) {
+		return f
+	}
+	seen := make([]bool, len(o.Deprecated))
+	return func(t ConfigType, label string, data []string) {
+		for i, d := range o.Deprecated {
+			if !seen[i] && ConfigComment != t && (label == d.Path || strings.HasPrefix(label, d.Path+":")) {
+				seen[i] = true
+				o.deprecation(c, d)
+			}
+		}
+		f(t, label, data)
+	}
+}
+
+// deprecation reports a deprecated label path that was found, unless it
+// was reported for the config before
+func (o Options) deprecation(c *Config, d Deprecation) {
+	if nil != c && !c.deprecate(d) {
+		return
+	}
+	if nil != o.OnDeprecated {
+		o.OnDeprecated(d)
+		return
+	}
+	dbg.Info("%s", d)
+}
+
+// deprecate adds a deprecation to the config's Deprecations, returning false
+// if it's already there
+func (c *Config) deprecate(d Deprecation) bool {
+	for _, seen := range c.deprecated {
+		if seen == d {
+			return false
+		}
+	}
+	c.deprecated = append(c.deprecated, d)
+	return true
+}
@@ -0,0 +1,50 @@
+package cfg
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestDeprecated(t *testing.T) {
+	warned := []string{}
+	o := Options{
+		Deprecated: []Deprecation{
+			{"db:pass", "db:password", "removed in 2.0"},
+			{"legacy", "", "no longer used"},
+			{"cache:size", "cache:bytes", ""},
+		},
+		Aliases:      map[string]string{"db:pass": "db:password"},
+		OnDeprecated: func(d Deprecation) { warned = append(warned, d.String()) },
+	}
+	c, err := o.Parse("legacy (\n\ta := 1\n\tb := 2\n)\ndb (\n\tpass := secret\n)\ndb (\n\tpass := again\n)\n")
+	dbg.ChkErr(err, "Parse")
+	want := []string{
+		"Config label legacy is deprecated -- no longer used",
+		"Config label db:pass is deprecated, use db:password -- removed in 2.0",
+	}
+	if !reflect.DeepEqual(want, warned) || 2 != len(c.Deprecations()) || "legacy" != c.Deprecations()[0].Path {
+		dbg.Error("Deprecated: %v %v", warned, c.Deprecations())
+		t.Fail()
+	}
+	if v, _ := c.Value("db:password"); "again" != v {
+		dbg.Error("Deprecated alias: %q", v)
+		t.Fail()
+	}
+
+	warned = warned[:0]
+	o.HandleConfigData("cache (\n\tsize := 1\n)\n", func(ct ConfigType, label string, data []string) {})
+	if 1 != len(warned) {
+		dbg.Error("Deprecated HandleConfigData: %v", warned)
+		t.Fail()
+	}
+
+	warned = warned[:0]
+	c, _ = Options{OnDeprecated: o.OnDeprecated}.Parse("timeout := 5\nold (\n\tx := 1\n)\n")
+	err = NewSchema().Optional("timeout", SchemaInt).Deprecated("old:x", "new:x", "").Deprecated("gone", "", "").Validate(c)
+	if nil != err || 1 != len(warned) || !reflect.DeepEqual([]Deprecation{{"old:x", "new:x", ""}}, c.Deprecations()) {
+		dbg.Error("Schema Deprecated: %v %v %v", err, warned, c.Deprecations())
+		t.Fail()
+	}
+}
@@ -0,0 +1,185 @@
+package cfg
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type (
+	/*
+		How StringListToDict style lines are split into keys and values, and
+		 what's done with a key found more than once:
+
+			cfg.DictOptions{Separators: []string{"=>", "="}, Duplicates: cfg.DuplicatesError}
+	*/
+	DictOptions struct {
+		Separators []string        // between a key and its value, ":" if none
+		Duplicates DuplicatePolicy // DuplicatesAllowed is DuplicatesKeepLast
+		Join       string          // joins the values of a key with DuplicatesAppend, "," if ""
+	}
+
+	/*
+		A dictionary remembering the order its keys were first found in
+	*/
+	OrderedDict struct {
+		Keys   []string
+		Values map[string]string
+	}
+)
+
+var (
+	ErrDuplicateKey = errors.New("Duplicate dictionary key")
+)
+
+/*
+	Splits each line into a key and value using the options, ignoring lines
+	 without a separator; with DuplicatesError a repeated key is a
+	 *ListError for its line wrapping ErrDuplicateKey
+*/
+func (d DictOptions) StringListToDict(l []string) (map[string]string, error) {
+	od, err := d.StringListToOrderedDict(l)
+	if nil != err {
+		return nil, err
+	}
+	return od.Values, nil
+}
+
+/*
+	As DictOptions.StringListToDict, keeping the order of the keys
+*/
+func (d DictOptions) StringListToOrderedDict(l []string) (*OrderedDict, error) {
+	rex, join := d.rex(), d.Join
+	if "" == join {
+		join = ","
+	}
+	od := &OrderedDict{Values: make(map[string]string)}
+	for i, v := range l {
+		x := rex.FindStringSubmatch(v)
+		if nil == x {
+			continue
+		}
+		prev, found := od.Values[x[1]]
+		switch {
+		case !found:
+			od.Keys = append(od.Keys, x[1])
+		case DuplicatesError == d.Duplicates:
+			return nil, &ListError{"", i, v, ErrDuplicateKey}
+		case DuplicatesKeepFirst == d.Duplicates:
+			continue
+		case DuplicatesAppend == d.Duplicates:
+			x[2] = prev + join + x[2]
+		}
+		od.Values[x[1]] = x[2]
+	}
+	return od, nil
+}
+
+/*
+	As StringListToDict, keeping the order of the keys
+*/
+func StringListToOrderedDict(l []string) *OrderedDict {
+	od, _ := DictOptions{}.StringListToOrderedDict(l)
+	return od
+}
+
+/*
+	Splits each line into a key and value as StringListToDict does, the
+	 value into a list at its commas, e.g. "role : read, write"; the lists
+	 of a key found more than once are joined
+*/
+func StringListToMultiDict(l []string) map[string][]string {
+	return DictOptions{}.StringListToMultiDict(l)
+}
+
+/*
+	As StringListToMultiDict, splitting the lines at the options' separators;
+	 Duplicates and Join aren't used
+*/
+func (d DictOptions) StringListToMultiDict(l []string) map[string][]string {
+	rex := d.rex()
+	result := make(map[string][]string)
+	for _, v := range l {
+		if x := rex.FindStringSubmatch(v); nil != x {
+			addValues(result, x[1], x[2])
+		}
+	}
+	return result
+}
+
+/*
+	Returns the value of the key
+*/
+func (od *OrderedDict) Get(key string) (string, bool) {
+	v, ok := od.Values[key]
+	return v, ok
+}
+
+/*
+	Returns the ordered dictionary for a ConfigDict label path; a key given
+	 more than once has its last value, at the position it was first found
+*/
+func (c *Config) OrderedDict(path string) (*OrderedDict, bool) {
+	n := c.node(c.opts.internalPath(path))
+	c.use(n, false)
+	if nil == n || ConfigDict != n.Type {
+		return nil, false
+	}
+	od := &OrderedDict{Values: make(map[string]string, len(n.Data)/2)}
+	for i := 0; i+1 < len(n.Data); i += 2 {
+		if _, ok := od.Values[n.Data[i]]; !ok {
+			od.Keys = append(od.Keys, n.Data[i])
+		}
+		od.Values[n.Data[i]] = n.Data[i+1]
+	}
+	return od, true
+}
+
+/*
+	Returns the dictionary for a ConfigDict label path with each value split
+	 into a list, see StringListToMultiDict
+*/
+func (c *Config) MultiDict(path string) (map[string][]string, bool) {
+	n := c.node(c.opts.internalPath(path))
+	c.use(n, false)
+	if nil == n || ConfigDict != n.Type {
+		return nil, false
+	}
+	result := make(map[string][]string, len(n.Data)/2)
+	for i := 0; i+1 < len(n.Data); i += 2 {
+		addValues(result, n.Data[i], n.Data[i+1])
+	}
+	return result, true
+}
+
+// ------------------------------------------------------------------------- //
+
+// rex returns the regex splitting a line at the first of the separators,
+// trying the longer ones first so "=>" isn't read as "="
+func (d DictOptions) rex() *regexp.Regexp {
+	if 0 == len(d.Separators) {
+		return dictRex
+	}
+	seps := make([]string, len(d.Separators))
+	for i, s := range d.Separators {
+		seps[i] = regexp.QuoteMeta(s)
+	}
+	sort.SliceStable(seps, func(i, j int) bool { return len(seps[i]) > len(seps[j]) })
+	return regexp.MustCompile(`^(\w+)[ \t]*(?:` + strings.Join(seps, "|") + `)[ \t]*(.*)`)
+}
+
+// addValues adds the values of a dict entry, split at its commas with any
+// empty values dropped, to the list of the key
+func addValues(m map[string][]string, key, value string) {
+	values, ok := m[key]
+	if !ok {
+		values = []string{}
+	}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); "" != v {
+			values = append(values, v)
+		}
+	}
+	m[key] = values
+}
@@ -0,0 +1,70 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestDictOptions(t *testing.T) {
+	lines := []string{"b => 1", "a = 2", "no separator", "b = 3"}
+	tests := []struct {
+		opts   DictOptions
+		keys   string
+		expect map[string]string
+	}{
+		{DictOptions{Separators: []string{"=", "=>"}}, "b,a", map[string]string{"a": "2", "b": "3"}},
+		{DictOptions{Separators: []string{"=", "=>"}, Duplicates: DuplicatesKeepFirst}, "b,a", map[string]string{"a": "2", "b": "1"}},
+		{DictOptions{Separators: []string{"=", "=>"}, Duplicates: DuplicatesAppend, Join: "|"}, "b,a", map[string]string{"a": "2", "b": "1|3"}},
+		{DictOptions{}, "", map[string]string{}},
+	}
+	for i, test := range tests {
+		od, err := test.opts.StringListToOrderedDict(lines)
+		if nil != err || test.keys != strings.Join(od.Keys, ",") || !reflect.DeepEqual(test.expect, od.Values) {
+			dbg.Error("test %d: %v %v", i, od, err)
+			t.Fail()
+		}
+	}
+	_, err := DictOptions{Separators: []string{"=>", "="}, Duplicates: DuplicatesError}.StringListToDict(lines)
+	var le *ListError
+	if !errors.As(err, &le) || 3 != le.Index || !errors.Is(err, ErrDuplicateKey) {
+		dbg.Error("duplicate key: %v", err)
+		t.Fail()
+	}
+	if od := StringListToOrderedDict([]string{"z: 1", "y : 2"}); "z,y" != strings.Join(od.Keys, ",") {
+		dbg.Error("StringListToOrderedDict: %v", od)
+		t.Fail()
+	}
+
+	c, _ := Parse("env : [\n\tz : 1\n\ty : 2\n\tz : 3\n]\n")
+	od, ok := c.OrderedDict("env")
+	if v, _ := od.Get("z"); !ok || "z,y" != strings.Join(od.Keys, ",") || "3" != v {
+		dbg.Error("Config.OrderedDict: %v", od)
+		t.Fail()
+	}
+	if _, ok = c.OrderedDict("env:z"); ok {
+		dbg.Error("OrderedDict of a missing entry")
+		t.Fail()
+	}
+}
+
+func TestMultiDict(t *testing.T) {
+	expect := map[string][]string{"admin": {"read", "write", "delete"}, "guest": {"read"}, "none": {}}
+	if got := StringListToMultiDict([]string{"admin : read, write", "guest: read", "admin : delete", "none :"}); !reflect.DeepEqual(expect, got) {
+		dbg.Error("StringListToMultiDict: %v", got)
+		t.Fail()
+	}
+	got := DictOptions{Separators: []string{"="}}.StringListToMultiDict([]string{"a = 1,2", "b : 3"})
+	if !reflect.DeepEqual(map[string][]string{"a": {"1", "2"}}, got) {
+		dbg.Error("DictOptions.StringListToMultiDict: %v", got)
+		t.Fail()
+	}
+	c, _ := Parse("roles : [\n\tadmin : read, write\n\tadmin : delete\n\tguest : read\n]\n")
+	if md, ok := c.MultiDict("roles"); !ok || !reflect.DeepEqual(map[string][]string{"admin": {"read", "write", "delete"}, "guest": {"read"}}, md) {
+		dbg.Error("Config.MultiDict: %v", md)
+		t.Fail()
+	}
+}
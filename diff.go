@@ -0,0 +1,128 @@
+package cfg
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+type (
+	/*
+		How an entry differs between two configs, see Diff
+	*/
+	ChangeKind int
+
+	/*
+		A Change records an entry added, removed or modified at a label path,
+		 indexed for a label repeated in its group (e.g. server[1]:port); Type
+		 is the type of the new entry, or of the one removed, and Old & New
+		 hold their Data, as in a Node, nil for the entry that's missing
+	*/
+	Change struct {
+		Path     string
+		Kind     ChangeKind
+		Type     ConfigType
+		Old, New []string
+	}
+)
+
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+/*
+	Returns the entries that differ from config a to b, sorted by label path;
+	 configs are compared by their data, so comments, formatting and the
+	 order of the entries in a group don't matter.  Groups aren't entries of
+	 their own, each entry in an added or removed group is a Change, and an
+	 entry whose type changes is removed and added again
+*/
+func Diff(a, b *Config) []Change {
+	before, after := a.entries(), b.entries()
+	changes := []Change{}
+	for p, n := range after {
+		was, ok := before[p]
+		switch {
+		case !ok:
+			changes = append(changes, Change{p, ChangeAdded, n.Type, nil, n.Data})
+		case was.Type != n.Type:
+			changes = append(changes, Change{p, ChangeRemoved, was.Type, was.Data, nil}, Change{p, ChangeAdded, n.Type, nil, n.Data})
+		case !reflect.DeepEqual(was.Data, n.Data):
+			changes = append(changes, Change{p, ChangeModified, n.Type, was.Data, n.Data})
+		}
+	}
+	for p, was := range before {
+		if _, ok := after[p]; !ok {
+			changes = append(changes, Change{p, ChangeRemoved, was.Type, was.Data, nil})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Path == changes[j].Path {
+			return ChangeRemoved == changes[i].Kind && ChangeRemoved != changes[j].Kind
+		}
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+/*
+	Reports whether the configs hold the same data, i.e. Diff finds no
+	 changes between them
+*/
+func Equal(a, b *Config) bool {
+	return 0 == len(Diff(a, b))
+}
+
+/*
+	Parses the config data of each and reports whether they're Equal, so
+	 text differing only in its comments, formatting or entry order is
+	 the same config
+*/
+func EqualText(a, b string) (bool, error) {
+	ca, err := Parse(a)
+	if nil != err {
+		return false, err
+	}
+	cb, err := Parse(b)
+	if nil != err {
+		return false, err
+	}
+	return Equal(ca, cb), nil
+}
+
+/*
+	Returns the first label path, in Diff's order, whose entry differs
+	 between the configs, false if they're Equal
+*/
+func FirstDifference(a, b *Config) (string, bool) {
+	if changes := Diff(a, b); 0 != len(changes) {
+		return changes[0].Path, true
+	}
+	return "", false
+}
+
+// ------------------------------------------------------------------------- //
+
+// entries returns the nodes of the config that aren't groups, by their
+// indexed label paths
+func (c *Config) entries() map[string]*Node {
+	result := make(map[string]*Node)
+	indexedNodes(&c.root, "", func(path string, n *Node) {
+		result[path] = n
+	})
+	return result
+}
@@ -0,0 +1,61 @@
+package cfg
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestDiff(t *testing.T) {
+	a, _ := Parse("# base\nname := app\ndb (\n\thost := x\n\tport := 5432\n)\nhosts { a b }\n" +
+		"server (\n\tport := 1\n)\nserver (\n\tport := 2\n)\nmode := fast\n")
+	b, _ := Parse("hosts { a b c }\ndb ( port := 5432  host := y )\nname   :=   app\n" +
+		"server (\n\tport := 1\n)\nserver (\n\tport := 3\n)\nmode { fast }\nlog (\n\tlevel := info\n)\n")
+	want := []Change{
+		{"db:host", ChangeModified, ConfigValue, []string{"x"}, []string{"y"}},
+		{"hosts", ChangeModified, ConfigItems, []string{"a", "b"}, []string{"a", "b", "c"}},
+		{"log:level", ChangeAdded, ConfigValue, nil, []string{"info"}},
+		{"mode", ChangeRemoved, ConfigValue, []string{"fast"}, nil},
+		{"mode", ChangeAdded, ConfigItems, nil, []string{"fast"}},
+		{"server[1]:port", ChangeModified, ConfigValue, []string{"2"}, []string{"3"}},
+	}
+	if got := Diff(a, b); !reflect.DeepEqual(want, got) {
+		dbg.Error("Diff: %v", got)
+		t.Fail()
+	}
+	if got := Diff(a, a.Clone()); 0 != len(got) {
+		dbg.Error("Diff same: %v", got)
+		t.Fail()
+	}
+	if got := Diff(b, a); 6 != len(got) || ChangeRemoved != got[2].Kind || "removed" != got[2].Kind.String() {
+		dbg.Error("Diff reversed: %v", got)
+		t.Fail()
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a, _ := Parse("# base\nname := app\ndb (\n\thost := x\n)\n")
+	b, _ := Parse("db ( host := x )\nname   :=   app\n")
+	if !Equal(a, b) {
+		dbg.Error("Equal: %v", Diff(a, b))
+		t.Fail()
+	}
+	if p, ok := FirstDifference(a, b); ok {
+		dbg.Error("FirstDifference of equal configs: %s", p)
+		t.Fail()
+	}
+	b, _ = Parse("name := svc\ndb ( host := y )\n")
+	if p, ok := FirstDifference(a, b); Equal(a, b) || !ok || "db:host" != p {
+		dbg.Error("FirstDifference: %s", p)
+		t.Fail()
+	}
+	if same, err := EqualText("a := 1\nb := 2\n", "# two\nb   :=   2\na := 1\n"); nil != err || !same {
+		dbg.Error("EqualText: %v", err)
+		t.Fail()
+	}
+	if same, err := EqualText("a := 1\n", "a := 2\n"); nil != err || same {
+		dbg.Error("EqualText of different data: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,158 @@
+package cfg
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jayacarlson/dbg"
+)
+
+type (
+	/*
+		A DocumentError records the failure to parse a single document of
+		 a multi-document config, see ParseDocuments
+	*/
+	DocumentError struct {
+		Doc  int // the index of the document, from 0
+		Line int // the line the document starts on, from 1
+		Err  error
+	}
+
+	// document is the text of a single document and the line it starts on
+	document struct {
+		text string
+		line int
+	}
+
+	// docSplitter finds the top level separator lines of config data given
+	// to it a line at a time, skipping the lines of the sections as the
+	// EventReader does
+	docSplitter struct {
+		o      Options
+		closer string
+	}
+)
+
+const (
+	// the line separating the documents of a multi-document config
+	DocumentSeparator = "---"
+)
+
+func (e *DocumentError) Error() string {
+	return "document " + strconv.Itoa(e.Doc) + " (line " + strconv.Itoa(e.Line) + "): " + e.Err.Error()
+}
+
+func (e *DocumentError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	Parses config data holding a number of documents, each separated from
+	 the next by a top level line of just DocumentSeparator, into a Config
+	 per document, so many small configs can be shipped as a single file:
+
+		name := web
+		port := 80
+		---
+		name := db
+		port := 5432
+
+	Each document is parsed on its own, as by Parse, so the label paths of
+	 one don't clash with those of another; a --- inside a section is its
+	 text, not a separator, and empty documents are left out.  The first
+	 document failing to parse gives a *DocumentError, as do those with
+	 sections skipped by Options.Recover
+*/
+func ParseDocuments(str string) ([]*Config, error) {
+	return Options{}.ParseDocuments(str)
+}
+
+/*
+	As ParseDocuments, using these options for each document
+*/
+func (o Options) ParseDocuments(str string) ([]*Config, error) {
+	return o.documents(nil, str)
+}
+
+/*
+	Reads the config file and parses its documents, see ParseDocuments
+*/
+func LoadConfigDocuments(flPath string) ([]*Config, error) {
+	return Options{}.LoadConfigDocuments(flPath)
+}
+
+/*
+	As LoadConfigDocuments, using these options for each document
+*/
+func (o Options) LoadConfigDocuments(flPath string) ([]*Config, error) {
+	if nil == o.Include {
+		o.Include = o.FileInclude
+	}
+	o, loaded := o.loading(flPath)
+	data, err := o.readText(flPath)
+	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
+		return nil, err
+	}
+	docs, err := o.documents([]string{flPath}, data)
+	for _, c := range docs {
+		loaded(c)
+	}
+	return docs, err
+}
+
+// ------------------------------------------------------------------------- //
+
+// documents parses each of the documents of the config data, any includes
+// being relative to the last config of the chain
+func (o Options) documents(chain []string, str string) ([]*Config, error) {
+	docs, errs := []*Config{}, []error{}
+	for _, d := range o.splitDocuments(normalizeEOL(strings.TrimPrefix(str, "\ufeff"))) {
+		if "" == strings.TrimSpace(d.text) {
+			continue
+		}
+		text, err := o.preprocess(chain, d.text)
+		var c *Config
+		if nil == err {
+			c, err = o.parse(text)
+		}
+		if nil == c {
+			return nil, &DocumentError{len(docs), d.line, err}
+		}
+		if nil != err {
+			// sections skipped with Options.Recover
+			errs = append(errs, &DocumentError{len(docs), d.line, err})
+		}
+		docs = append(docs, c)
+	}
+	return docs, joinErrors(errs)
+}
+
+// splitDocuments splits the config data at each top level separator line
+func (o Options) splitDocuments(str string) []document {
+	docs := []document{}
+	lines := strings.Split(str, "\n")
+	start, split := 0, docSplitter{o: o}
+	for i, line := range lines {
+		if split.separates(line) {
+			docs = append(docs, document{strings.Join(lines[start:i], "\n") + "\n", start + 1})
+			start = i + 1
+		}
+	}
+	return append(docs, document{strings.Join(lines[start:], "\n"), start + 1})
+}
+
+// separates reports whether the line, the next of the data, is a separator
+func (s *docSplitter) separates(line string) bool {
+	trimmed := strings.TrimRight(line, " \t")
+	switch {
+	case "" != s.closer:
+		if s.closer == trimmed {
+			s.closer = ""
+		}
+	case DocumentSeparator == trimmed:
+		return true
+	case "" != trimmed && ' ' != line[0] && '\t' != line[0] && '#' != line[0] && !s.o.isComment(line):
+		_, s.closer, _ = s.o.opens(trimmed)
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestParseDocuments(t *testing.T) {
+	src := "---\nname := web\nport := 80\nnotes <\n---\n>\n---\n# db\nname := db\ndb (\n\tport := 5432\n)\n---\n\n---\n"
+	docs, err := ParseDocuments(src)
+	if nil != err || 2 != len(docs) {
+		dbg.Error("ParseDocuments: %d %v", len(docs), err)
+		t.FailNow()
+	}
+	if "web" != docs[0].ValueOr("name", "") || "---" != docs[0].ValueOr("notes", "") {
+		dbg.Error("ParseDocuments 0: %v", docs[0])
+		t.Fail()
+	}
+	if "db" != docs[1].ValueOr("name", "") || "5432" != docs[1].ValueOr("db:port", "") || "" != docs[1].ValueOr("port", "") {
+		dbg.Error("ParseDocuments 1: %v", docs[1])
+		t.Fail()
+	}
+
+	_, err = Options{Duplicates: DuplicatesError}.ParseDocuments("a := 1\n---\nb := 1\nb := 2\n")
+	var de *DocumentError
+	if !errors.As(err, &de) || 1 != de.Doc || 3 != de.Line {
+		dbg.Error("ParseDocuments error: %v", err)
+		t.Fail()
+	}
+
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "all.cfg")
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("a := 1\n---\na := 2\n"), 0644), "WriteFile")
+	docs, err = LoadConfigDocuments(flPath)
+	if nil != err || 2 != len(docs) || "2" != docs[1].ValueOr("a", "") || flPath != docs[1].Source() {
+		dbg.Error("LoadConfigDocuments: %v", err)
+		t.Fail()
+	}
+}
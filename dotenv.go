@@ -0,0 +1,149 @@
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	// the group holding the values of a .env file
+	DotEnvGroup = "env"
+)
+
+var (
+	ErrDotEnvSyntax = errors.New("Invalid .env line, expected KEY=VALUE")
+
+	// 1: key  2: value
+	dotEnvRex = regexp.MustCompile(`^(?:export[ \t]+)?(\w+)[ \t]*=[ \t]*(.*)$`)
+	// values that can be written without quotes
+	dotEnvBareRex = regexp.MustCompile(`^[\w./:@%+,=-]*$`)
+
+	dotEnvEscapes = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\$`, `$`, `\\`, `\`)
+	dotEnvQuotes  = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`)
+)
+
+/*
+	Reads the .env file into a Config tree, see ParseDotEnv
+*/
+func LoadDotEnv(flPath string) (*Config, error) {
+	return Options{}.LoadDotEnv(flPath)
+}
+
+/*
+	As LoadDotEnv, reading no more than the MaxSize of these options
+*/
+func (o Options) LoadDotEnv(flPath string) (*Config, error) {
+	o, loaded := o.loading(flPath)
+	data, err := o.readText(flPath)
+	if dbg.ChkErr(err, "Failed to read .env file: %s (%v)", flPath, err) {
+		return nil, err
+	}
+	c, err := ParseDotEnv(data)
+	if nil != err {
+		return nil, err
+	}
+	loaded(c)
+	return c, nil
+}
+
+/*
+	Parses .env data into a Config tree, each KEY=VALUE line giving the
+	 value env:KEY (see DotEnvGroup):
+
+		# comment
+		export KEY=value    the export prefix is ignored
+		KEY=value  # note  a comment following whitespace is removed
+		KEY='as is'         nothing in single quotes is changed
+		KEY="a\tb\n"        \n \r \t \" \$ & \\ escapes are replaced
+
+	A quoted value can run over several lines up to the closing quote, a
+	 later line for the same KEY replaces the earlier one for lookups
+*/
+func ParseDotEnv(str string) (*Config, error) {
+	c := newConfig()
+	lines := strings.Split(normalizeEOL(str), "\n")
+	for i := 0; i < len(lines); i++ {
+		text := strings.TrimSpace(lines[i])
+		if "" == text || '#' == text[0] {
+			continue
+		}
+		x := dotEnvRex.FindStringSubmatch(text)
+		if nil == x {
+			return nil, &LineError{i + 1, text, ErrDotEnvSyntax}
+		}
+		value, used, err := dotEnvValue(x[2], lines[i+1:])
+		if nil != err {
+			return nil, &LineError{i + 1, text, err}
+		}
+		c.add(ConfigValue, DotEnvGroup+":"+x[1], []string{value})
+		i += used
+	}
+	return c, nil
+}
+
+/*
+	Writes the values of the env group of the config (see DotEnvGroup) as
+	 KEY=VALUE lines, quoting the values that need it so ParseDotEnv (and
+	 shells) read them back unchanged
+*/
+func (c *Config) WriteDotEnv(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	if g := c.node(DotEnvGroup); nil != g && ConfigGroup == g.Type {
+		for _, n := range g.Children {
+			if ConfigValue != n.Type && ConfigBlock != n.Type {
+				continue
+			}
+			v := n.Data[0]
+			switch {
+			case dotEnvBareRex.MatchString(v):
+			case !strings.ContainsAny(v, "'\n\r"):
+				v = "'" + v + "'"
+			default:
+				v = `"` + dotEnvQuotes.Replace(v) + `"`
+			}
+			buf.WriteString(n.Label + "=" + v + "\n")
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+// ------------------------------------------------------------------------- //
+
+// dotEnvValue returns the value starting the line, along with the number
+// of following lines used by a quoted value running over several lines
+func dotEnvValue(v string, next []string) (string, int, error) {
+	if "" == v || ('"' != v[0] && '\'' != v[0]) {
+		if i := strings.Index(v, "#"); i >= 0 && (0 == i || ' ' == v[i-1] || '\t' == v[i-1]) {
+			v = v[:i]
+		}
+		return strings.TrimSpace(v), 0, nil
+	}
+	q := v[0]
+	text := v[1:]
+	for used := 0; ; used++ {
+		for i := 0; i < len(text); i++ {
+			switch {
+			case '"' == q && '\\' == text[i]:
+				i++
+			case q == text[i]:
+				rest := strings.TrimSpace(text[i+1:])
+				if "" != rest && '#' != rest[0] {
+					return "", 0, ErrDotEnvSyntax
+				}
+				if '"' == q {
+					return dotEnvEscapes.Replace(text[:i]), used, nil
+				}
+				return text[:i], used, nil
+			}
+		}
+		if used == len(next) {
+			return "", 0, ErrDotEnvSyntax
+		}
+		text += "\n" + next[used]
+	}
+}
@@ -0,0 +1,54 @@
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestDotEnv(t *testing.T) {
+	c, err := LoadDotEnv("testdata/app.env")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"env:PORT":     "8080",
+		"env:NAME":     "app",
+		"env:URL":      "http://x/#frag",
+		"env:GREETING": "hello\tworld\n",
+		"env:RAW":      `a $b \n`,
+		"env:CERT":     "line one\nline two",
+		"env:EMPTY":    "",
+	}
+	if got := c.Flatten(); !reflect.DeepEqual(expect, got) {
+		dbg.Error("LoadDotEnv: %q", got)
+		t.Fail()
+	}
+
+	var buf bytes.Buffer
+	if _, err := c.WriteDotEnv(&buf); nil != err {
+		dbg.Error(err.Error())
+		t.Fail()
+	}
+	want := "PORT=8080\nNAME=app\nURL='http://x/#frag'\nGREETING=\"hello\tworld\\n\"\nRAW='a $b \\n'\nCERT=\"line one\\nline two\"\nEMPTY=\n"
+	if want != buf.String() {
+		dbg.Error("WriteDotEnv: %q", buf.String())
+		t.Fail()
+	}
+	if back, err := ParseDotEnv(buf.String()); nil != err || !reflect.DeepEqual(expect, back.Flatten()) {
+		dbg.Error("WriteDotEnv round trip: %v", err)
+		t.Fail()
+	}
+
+	for _, src := range []string{"PORT\n", "A-B=1\n", "A=\"open\n", "A='x' y\n"} {
+		var de *LineError
+		if _, err := ParseDotEnv(src); !errors.Is(err, ErrDotEnvSyntax) || !errors.As(err, &de) || 1 != de.Line {
+			dbg.Error("ParseDotEnv %q: %v", src, err)
+			t.Fail()
+		}
+	}
+}
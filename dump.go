@@ -0,0 +1,159 @@
+package cfg
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type (
+	/*
+		DumpOptions alter the view of a config written by Config.Dump, the
+		 zero value giving plain text with values cut at DefaultDumpWidth
+	*/
+	DumpOptions struct {
+		// The labels, types, values & origins are colored with ANSI
+		//  escapes, for a terminal
+		Color bool
+
+		// Values are cut short with "..." past this many characters, 0 for
+		//  DefaultDumpWidth and -1 for whole values
+		MaxWidth int
+
+		// Returns where the entry of a label path came from, e.g.
+		//  Layers.Source.  The origin column is left out when this is nil
+		//  or every origin is ""
+		Origin func(path string) string
+
+		// Label path patterns of further entries to mask, added to the
+		//  Sensitive ones of the config, e.g. "*:token"
+		Sensitive []string
+	}
+)
+
+const (
+	DefaultDumpWidth = 60
+
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+/*
+	Writes a human view of the config for debugging, an aligned line for
+	 each entry giving its label (indented below its group), type, value
+	 and, given DumpOptions.Origin, origin:
+
+		db          Group
+		  host      Value  "db.local"          app.cfg
+		  password  Value  *****               app.cfg
+		  replicas  Items  [a, b, c]           app.cfg
+		  schema    Block  "CREATE TABLE ..."  app.cfg
+
+	The values of sensitive entries (see Options.Sensitive) are masked as
+	 Redacted, so the output of a single command can be asked of a user
+	 rather than their config files; comments are left out
+*/
+func (c *Config) Dump(w io.Writer, opts DumpOptions) error {
+	view := c
+	if 0 != len(opts.Sensitive) {
+		view = &Config{root: c.root, opts: c.opts}
+		view.MarkSensitive(opts.Sensitive...)
+	}
+	origin := opts.Origin
+	if nil == origin {
+		// the config's source may not be where an included entry came from
+		origin = func(string) string { return "" }
+	}
+	rows := [][4]string{}
+	var walk func(g *Node, indent string)
+	walk = func(g *Node, indent string) {
+		for _, n := range g.Children {
+			if ConfigComment == n.Type {
+				continue
+			}
+			r := view.redacted(n)
+			rows = append(rows, [4]string{indent + n.Label, n.Type.String(), opts.value(r), origin(n.Path)})
+			if ConfigGroup == n.Type {
+				walk(n, indent+"  ")
+			}
+		}
+	}
+	walk(&c.root, "")
+
+	widths := [4]int{}
+	for _, r := range rows {
+		for i, col := range r {
+			if l := utf8.RuneCountInString(col); l > widths[i] {
+				widths[i] = l
+			}
+		}
+	}
+	colors := [4]string{ansiCyan, ansiDim, ansiGreen, ansiDim}
+	var buf bytes.Buffer
+	for _, r := range rows {
+		line := ""
+		for i, col := range r {
+			if 3 == i && 0 == widths[3] {
+				break
+			}
+			if 0 != i {
+				line += "  "
+			}
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(col))
+			if opts.Color && "" != col {
+				color := colors[i]
+				switch {
+				case 0 == i && "Group" == r[1]:
+					color = ansiBold + ansiCyan
+				case 2 == i && Redacted == col:
+					color = ansiRed
+				}
+				col = color + col + ansiReset
+			}
+			line += col + pad
+		}
+		buf.WriteString(strings.TrimRight(line, " "))
+		buf.WriteByte('\n')
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ------------------------------------------------------------------------- //
+
+// value returns the data of the node as shown by Dump
+func (opts DumpOptions) value(n *Node) string {
+	v := ""
+	switch n.Type {
+	case ConfigGroup:
+		return ""
+	case ConfigValue, ConfigBlock:
+		if v = n.Data[0]; Redacted != v {
+			v = strconv.Quote(v)
+		}
+	case ConfigBinary:
+		v = strconv.Itoa(len(n.Data[0])) + " bytes"
+	case ConfigDict:
+		pairs := []string{}
+		for i := 0; i+1 < len(n.Data); i += 2 {
+			pairs = append(pairs, n.Data[i]+": "+n.Data[i+1])
+		}
+		v = "{" + strings.Join(pairs, ", ") + "}"
+	default:
+		v = "[" + strings.Join(n.Data, ", ") + "]"
+	}
+	max := opts.MaxWidth
+	if 0 == max {
+		max = DefaultDumpWidth
+	}
+	if max > 3 && utf8.RuneCountInString(v) > max {
+		v = string([]rune(v)[:max-3]) + "..."
+	}
+	return v
+}
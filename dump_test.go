@@ -0,0 +1,50 @@
+package cfg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestDump(t *testing.T) {
+	src := "# comment\ndb (\n\thost := db.local\n\tpassword := hunter2\n\treplicas { a b c }\n\ttoken := abc\n)\nname := app\n"
+	c, err := Options{Sensitive: []string{"*:password"}}.Parse(src)
+	if nil != err {
+		dbg.Error("Parse for Dump: %v", err)
+		t.FailNow()
+	}
+	var buf bytes.Buffer
+	if err = c.Dump(&buf, DumpOptions{Sensitive: []string{"db:token"}}); nil != err {
+		dbg.Error("Dump: %v", err)
+		t.FailNow()
+	}
+	want := "db          Group\n" +
+		"  host      Value  \"db.local\"\n" +
+		"  password  Value  *****\n" +
+		"  replicas  Items  [a, b, c]\n" +
+		"  token     Value  *****\n" +
+		"name        Value  \"app\"\n"
+	if want != buf.String() {
+		dbg.Error("Dump:\n%s", buf.String())
+		t.Fail()
+	}
+	if !strings.Contains(c.String(), "abc") || "abc" != c.ValueOr("db:token", "") {
+		dbg.Error("Dump changed the config")
+		t.Fail()
+	}
+
+	buf.Reset()
+	origin := func(path string) string { return "layer:" + strings.Split(path, ":")[0] }
+	if err = c.Dump(&buf, DumpOptions{Color: true, MaxWidth: 6, Origin: origin}); nil != err {
+		dbg.Error("Dump color: %v", err)
+		t.FailNow()
+	}
+	out := buf.String()
+	if !strings.Contains(out, ansiBold+ansiCyan+"db"+ansiReset) || !strings.Contains(out, ansiRed+Redacted+ansiReset) ||
+		!strings.Contains(out, "\"db...") || !strings.Contains(out, "layer:name") {
+		dbg.Error("Dump color:\n%q", out)
+		t.Fail()
+	}
+}
@@ -0,0 +1,91 @@
+package cfg
+
+import (
+	"errors"
+)
+
+type (
+	/*
+		A DuplicatePolicy selects what happens to a label found more than once
+		 in the same group, see Options.Duplicates
+	*/
+	DuplicatePolicy int
+)
+
+const (
+	DuplicatesAllowed   DuplicatePolicy = iota // every entry is kept, lookups use the last
+	DuplicatesError                            // a *PathError wrapping ErrDuplicateLabel
+	DuplicatesKeepFirst                        // later entries are dropped
+	DuplicatesKeepLast                         // earlier entries are dropped
+	DuplicatesAppend                           // values & lists are joined into one list
+)
+
+var (
+	ErrDuplicateLabel = errors.New("Duplicate config label")
+)
+
+// ------------------------------------------------------------------------- //
+
+// duplicates applies the policy to the entries of n and each of its groups;
+// repeated groups are always kept, see LookupAll
+func (c *Config) duplicates(n *Node, policy DuplicatePolicy) error {
+	first := map[string]*Node{}
+	kept := n.Children[:0]
+	for _, ch := range n.Children {
+		if ConfigGroup == ch.Type {
+			if err := c.duplicates(ch, policy); nil != err {
+				return err
+			}
+		}
+		if ConfigGroup == ch.Type || ConfigComment == ch.Type {
+			kept = append(kept, ch)
+			continue
+		}
+		prev := first[ch.Label]
+		if nil == prev {
+			first[ch.Label] = ch
+			kept = append(kept, ch)
+			continue
+		}
+		switch policy {
+		case DuplicatesError:
+			return &PathError{ch.Path, ErrDuplicateLabel}
+		case DuplicatesKeepFirst:
+			c.replaced(ch, prev)
+		case DuplicatesKeepLast:
+			prev.Type, prev.Data = ch.Type, ch.Data
+		case DuplicatesAppend:
+			if err := appendNode(prev, ch); nil != err {
+				return err
+			}
+			c.replaced(ch, prev)
+		}
+	}
+	n.Children = kept
+	return nil
+}
+
+// replaced points any lookup of the dropped node to the kept node
+func (c *Config) replaced(dropped, kept *Node) {
+	if dropped == c.nodes[dropped.Path] {
+		c.nodes[dropped.Path] = kept
+	}
+}
+
+// appendNode adds the data of n to that of prev; values become ConfigItems
+// with one item per value, lines and items are joined and any other type
+// can't be appended to
+func appendNode(prev, n *Node) error {
+	t := n.Type
+	if ConfigValue == t {
+		t = ConfigItems
+	}
+	switch {
+	case ConfigValue == prev.Type && ConfigItems == t:
+		prev.Type = ConfigItems
+	case prev.Type != t || (ConfigItems != t && ConfigLines != t):
+		return &PathError{n.Path, ErrWrongType}
+	}
+	prev.Data = append(append([]string(nil), prev.Data...), n.Data...)
+	return nil
+}
@@ -0,0 +1,341 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	// an entry found by scanning the lines of config data in place
+	entry struct {
+		label      string
+		kind       ConfigType // ConfigValue, ConfigBlock, ConfigGroup or -1 for any other
+		multi      bool       // a ':==' value or heredoc, ended by a tag line
+		start, end int        // first & last line of the entry
+	}
+)
+
+var (
+	ErrInlineEdit = errors.New("Entries of an inline group can't be edited in place")
+
+	// 1: label  2: value
+	editValueRex = regexp.MustCompile(`^([^\s]+?)[ \t]*:=(.*)$`)
+	// 1: label  2: ,|:  3: opener  4: b64|hex|table||
+	editSectionRex = regexp.MustCompile(`^([^\s]+?)[ \t]*(,|:)?[ \t]*(<|\[|{|\()(b64|hex|table|\|)?[ \t]*$`)
+	// 1: label  2: TAG
+	editHeredocRex = regexp.MustCompile(`^([^\s]+?)[ \t]*<<(\w+)[ \t]*$`)
+	// 1: label
+	editInlineRex = regexp.MustCompile(`^([^\s]+?)[ \t]*,?[ \t]*(?:{.*}|\(.*\))[ \t]*$`)
+)
+
+/*
+	Sets the value of the ConfigValue or ConfigBlock at the label path in the
+	 config file, editing just the value (or the lines of the block) in place
+	 so every other byte of the file is kept; see SetValue
+*/
+func SetValueInFile(flPath, path, value string) error {
+	src, err := ioutil.ReadFile(flPath)
+	if nil != err {
+		return err
+	}
+	out, err := SetValue(src, path, value)
+	if nil != err {
+		return err
+	}
+	return writeFile(flPath, out)
+}
+
+/*
+	Returns the config data with the value of the ConfigValue or ConfigBlock
+	 at the label path replaced, leaving the rest of the data untouched; a
+	 repeated label may be selected by index as with Lookup.  A value with
+	 more than one line is written as a ':==' multi-line value
+
+	The label path must exist, otherwise a *PathError is returned
+*/
+func SetValue(src []byte, path, value string) ([]byte, error) {
+	lines := strings.Split(string(src), "\n")
+	e, indent, err := findEntry(lines, path)
+	if nil != err {
+		return nil, err
+	}
+	if ConfigValue != e.kind && ConfigBlock != e.kind {
+		return nil, &PathError{path, ErrWrongType}
+	}
+	return []byte(strings.Join(setEntry(lines, e, indent, value), "\n")), nil
+}
+
+/*
+	Returns the line number (from 1) of the entry at the label path in the
+	 config data, found as SetValue finds it, e.g. to point a diagnostic at
+	 the entry; when it's not there (or is inside an inline group) it's the
+	 line of the closest group that would hold it, 0 for the top level
+*/
+func LineOf(src []byte, path string) int {
+	e, _, _ := findEntry(strings.Split(string(src), "\n"), path)
+	return e.start + 1
+}
+
+/*
+	Appends the node as a new section labelled label at the end of the
+	 config file, creating the file if it doesn't exist; the section is
+	 written as WriteTo would, separated from any existing entries by a
+	 blank line and using the line endings of the file
+*/
+func AppendSection(flPath, label string, node Node) error {
+	if !labelRex.MatchString(label) {
+		return &PathError{label, ErrIllegalLabel}
+	}
+	src, err := ioutil.ReadFile(flPath)
+	if nil != err && !os.IsNotExist(err) {
+		return err
+	}
+	node.Label = label
+	lines := newConfig().encodeNode(Encoder{}, &node)
+	eol := "\n"
+	if strings.Contains(string(src), "\r\n") {
+		eol = "\r\n"
+	}
+	text := strings.Join(lines, eol) + eol
+	if str := normalizeEOL(string(src)); "" != strings.TrimSpace(strings.TrimPrefix(str, "\ufeff")) {
+		switch {
+		case !strings.HasSuffix(str, "\n"):
+			text = eol + eol + text
+		case !strings.HasSuffix(str, "\n\n"):
+			text = eol + text
+		}
+	}
+	fl, err := os.OpenFile(flPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if nil != err {
+		return err
+	}
+	_, err = fl.WriteString(text)
+	if cerr := fl.Close(); nil == err {
+		err = cerr
+	}
+	return err
+}
+
+// ------------------------------------------------------------------------- //
+
+// findEntry finds the entry at the label path and the indent of its lines;
+// with an error it's the last group found on the way, a start of -1 if none
+func findEntry(lines []string, path string) (entry, string, error) {
+	lo, hi, indent, outer := 0, len(lines), "", ""
+	group := entry{start: -1}
+	elems := strings.Split(path, ":")
+	for i, elem := range elems {
+		label, index := elem, -1
+		if x := indexRex.FindStringSubmatch(elem); nil != x {
+			label = x[1]
+			index, _ = strconv.Atoi(x[2])
+		}
+		matched := []entry{}
+		for _, e := range scanEntries(lines, lo, hi, indent) {
+			if label == e.label {
+				matched = append(matched, e)
+			}
+		}
+		if index >= len(matched) || 0 == len(matched) {
+			return group, outer, &PathError{path, ErrNoSuchLabel}
+		}
+		e := matched[len(matched)-1]
+		if index >= 0 {
+			e = matched[index]
+		}
+		if i == len(elems)-1 {
+			return e, indent, nil
+		}
+		if ConfigGroup != e.kind {
+			return group, outer, &PathError{path, ErrNoSuchLabel}
+		}
+		if e.start == e.end {
+			return e, indent, &PathError{path, ErrInlineEdit}
+		}
+		group, lo, hi, outer = e, e.start+1, e.end, indent
+		indent = groupIndent(lines[lo:hi], indent)
+	}
+	return group, outer, &PathError{path, ErrNoSuchLabel}
+}
+
+// groupIndent returns the indent of the entries of a group, the lines of
+// which are within the indent of the group itself: a TAB or, as the
+// parser finds it, the spaces of its first line that isn't blank
+func groupIndent(lines []string, outer string) string {
+	for _, l := range lines {
+		if l = strings.TrimRight(l, " \t\r"); "" == l || !strings.HasPrefix(l, outer) {
+			continue
+		}
+		l = l[len(outer):]
+		if strings.HasPrefix(l, " ") {
+			return outer + l[:len(l)-len(strings.TrimLeft(l, " "))]
+		}
+		break
+	}
+	return outer + "\t"
+}
+
+// setEntry replaces the value of the entry, the lines of which have the
+// indent, returning the new lines
+func setEntry(lines []string, e entry, tabs string, value string) []string {
+	first := lines[e.start]
+	cr := ""
+	if strings.HasSuffix(first, "\r") {
+		cr = "\r"
+	}
+	indent := tabs
+	if x := editSectionRex.FindStringSubmatch(strings.TrimSpace(first)); ConfigBlock == e.kind && nil != x && "|" == x[4] {
+		// a <| block is dedented, so its lines keep the indent they have
+		if indent = commonIndent(lines[e.start+1 : e.end]); !strings.HasPrefix(indent, tabs) {
+			indent = tabs
+		}
+	}
+	body := strings.Split(value, "\n")
+	for i, l := range body {
+		if ConfigValue == e.kind {
+			l = "\t" + l
+		}
+		if "" != l {
+			l = indent + l
+		}
+		body[i] = l + cr
+	}
+	var repl []string
+	switch {
+	case ConfigBlock == e.kind && blockEnds(first, e.multi, body):
+		// a line of the text would end the block early, so it's written
+		// again as WriteTo would, with a heredoc tag it doesn't hold
+		repl = encodeBlock(e.label, value)
+		repl[0] = first[:strings.Index(first, e.label)] + repl[0]
+		for i := 1; i < len(repl); i++ {
+			if i == len(repl)-1 || "" != repl[i] {
+				repl[i] = tabs + repl[i]
+			}
+			repl[i] += cr
+		}
+		repl[0] += cr
+	case ConfigValue == e.kind && !e.multi && !strings.Contains(value, "\n") && strings.TrimSpace(value) == value:
+		// keep everything on the line but the value itself
+		line := strings.TrimSuffix(first, "\r")
+		off := len(line) - len(strings.TrimPrefix(line, "\ufeff")) + len(tabs)
+		x := editValueRex.FindStringSubmatchIndex(line[off:])
+		v := line[off+x[4] : off+x[5]]
+		lead := v[:len(v)-len(strings.TrimLeft(v, " \t"))]
+		trail := v[len(strings.TrimRight(v, " \t")):]
+		switch {
+		case "" == value:
+			// no separator is left after the ':='
+			lead, trail = "", ""
+		case "" == lead:
+			lead = " "
+		}
+		repl = []string{line[:off+x[4]] + lead + value + trail + cr}
+	case ConfigValue == e.kind && !e.multi:
+		// multi-line or padded, which a single line would lose
+		line := strings.TrimSuffix(first, "\r")
+		repl = append(append([]string{line[:strings.Index(line, ":=")+2] + "=" + cr}, body...), tabs+":=="+cr)
+	default:
+		// replace the lines between the opening & closing lines
+		repl = append(append([]string{first}, body...), lines[e.end])
+	}
+	result := append([]string{}, lines[:e.start]...)
+	result = append(result, repl...)
+	return append(result, lines[e.end+1:]...)
+}
+
+// blockEnds reports whether a line of the body (indented) would end the
+// block or heredoc opened by the first line early
+func blockEnds(first string, heredoc bool, body []string) bool {
+	closer := ">"
+	if heredoc {
+		x := editHeredocRex.FindStringSubmatch(strings.TrimSpace(first))
+		closer = x[2]
+	}
+	for _, l := range body {
+		l = strings.TrimSpace(l)
+		if closer == l || (!heredoc && 1 == len(l) && strings.Contains(">]})", l)) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanEntries finds the entries of the group between the lines lo and hi, the
+// lines of which have the indent
+func scanEntries(lines []string, lo, hi int, tabs string) []entry {
+	ends := func(i int, closer string) int {
+		for j := i + 1; j < hi; j++ {
+			if tabs+closer == strings.TrimRight(lines[j], " \t\r") {
+				return j
+			}
+		}
+		return -1
+	}
+	result := []entry{}
+	for i := lo; i < hi; i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if 0 == i {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if !strings.HasPrefix(line, tabs) || "" == line[len(tabs):] || strings.ContainsAny(line[len(tabs):len(tabs)+1], " \t#") {
+			continue
+		}
+		line = line[len(tabs):]
+		if x := editValueRex.FindStringSubmatch(line); nil != x {
+			if "=" == strings.TrimRight(x[2], " \t") && strings.HasPrefix(x[2], "=") {
+				if j := ends(i, ":=="); j > 0 {
+					result = append(result, entry{x[1], ConfigValue, true, i, j})
+					i = j
+				}
+				continue
+			}
+			result = append(result, entry{x[1], ConfigValue, false, i, i})
+			continue
+		}
+		if x := editHeredocRex.FindStringSubmatch(line); nil != x {
+			if j := ends(i, x[2]); j > 0 {
+				result = append(result, entry{x[1], ConfigBlock, true, i, j})
+				i = j
+			}
+			continue
+		}
+		if x := editSectionRex.FindStringSubmatch(line); nil != x {
+			kind := ConfigType(-1)
+			switch {
+			case "(" == x[3]:
+				kind = ConfigGroup
+			case "<" == x[3] && ("" == x[4] || "|" == x[4]):
+				kind = ConfigBlock
+			}
+			if j := ends(i, matching[x[3]]); j > 0 {
+				result = append(result, entry{x[1], kind, false, i, j})
+				i = j
+			}
+			continue
+		}
+		if x := editInlineRex.FindStringSubmatch(line); nil != x {
+			kind := ConfigType(-1)
+			if strings.HasSuffix(line, ")") {
+				kind = ConfigGroup
+			}
+			result = append(result, entry{x[1], kind, false, i, i})
+			continue
+		}
+		sectionLock.RLock()
+		for _, s := range sections {
+			if x := s.rex.FindStringSubmatch(line + "\n"); nil != x {
+				if j := ends(i, s.close); j > 0 {
+					result = append(result, entry{x[1], -1, false, i, j})
+					i = j
+				}
+				break
+			}
+		}
+		sectionLock.RUnlock()
+	}
+	return result
+}
@@ -0,0 +1,209 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	editSrc = "# leading comment\r\n" +
+		"name :=  old  \r\n" +
+		"text <\r\n" +
+		"some text\r\n" +
+		">\r\n" +
+		"grp (\r\n" +
+		"\tdeep := x\r\n" +
+		"\tsub (\r\n" +
+		"\t\tdeep := y\r\n" +
+		"\t)\r\n" +
+		"\tdeep := z\r\n" +
+		")\r\n"
+)
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		path, value, want string
+	}{
+		{"name", "new", "name :=  new  \r\n"},
+		{"text", "one\n\ntwo", "text <\r\none\r\n\r\ntwo\r\n>\r\n"},
+		{"grp:sub:deep", "w", "\t\tdeep := w\r\n"},
+		{"grp:deep[0]", "v", "\tdeep := v\r\n\tsub"},
+		{"grp:deep", "a\nb", "\tdeep :==\r\n\t\ta\r\n\t\tb\r\n\t:==\r\n)"},
+		{"name", "", "name :=\r\n"},
+	}
+	for _, test := range tests {
+		out, err := SetValue([]byte(editSrc), test.path, test.value)
+		if nil != err {
+			dbg.Error("SetValue %s: %v", test.path, err)
+			t.Fail()
+			continue
+		}
+		c, err := Parse(string(out))
+		if nil != err {
+			dbg.Error("Parse after SetValue %s: %v", test.path, err)
+			t.Fail()
+			continue
+		}
+		n := c.Lookup(test.path)
+		if nil == n || test.value != n.Data[0] && test.value != strings.Join(n.Data, "\n") {
+			dbg.Error("SetValue %s: got %v", test.path, n)
+			t.Fail()
+		}
+		if !strings.Contains(string(out), test.want) || len(out)-len(editSrc) > len(test.want) {
+			dbg.Error("SetValue %s edited too much:\n%q", test.path, out)
+			t.Fail()
+		}
+	}
+	for _, path := range []string{"missing", "grp:missing", "name:deep"} {
+		if _, err := SetValue([]byte(editSrc), path, "x"); nil == err {
+			dbg.Error("SetValue %s: expected an error", path)
+			t.Fail()
+		}
+	}
+}
+
+func TestSetValueEscapes(t *testing.T) {
+	src := "doc <<END\nx\nEND\nv := 1\ngrp (\n\tb <\n\t\tx\n\t>\n)\n"
+	tests := []struct {
+		path, value string
+	}{
+		{"doc", "END\ny"},
+		{"v", " padded "},
+		{"grp:b", ")\n  >\n>"},
+		{"grp:b", "\tindented\n  two"},
+	}
+	for _, test := range tests {
+		out, err := SetValue([]byte(src), test.path, test.value)
+		if nil != err {
+			dbg.Error("SetValue %s: %v", test.path, err)
+			t.Fail()
+			continue
+		}
+		c, err := Parse(string(out))
+		if nil != err || test.value != c.ValueOr(test.path, "") || nil == c.Lookup("grp:b") {
+			dbg.Error("SetValue %s %q: %v\n%s", test.path, test.value, err, out)
+			t.Fail()
+		}
+	}
+}
+
+func TestSetValueSpaceIndent(t *testing.T) {
+	src := "grp (\n    host := a\n    sub (\n      port := 1\n      doc <\n        text\n      >\n    )\n)\nx := 1\n"
+	tests := []struct {
+		path, value string
+	}{
+		{"grp:host", "b"},
+		{"grp:sub:port", "2"},
+		{"grp:sub:port", "two\nlines"},
+		{"grp:sub:doc", "new\ntext"},
+	}
+	for _, test := range tests {
+		out, err := SetValue([]byte(src), test.path, test.value)
+		if nil != err {
+			dbg.Error("SetValue %s: %v", test.path, err)
+			t.Fail()
+			continue
+		}
+		c, err := Parse(string(out))
+		if nil != err || test.value != c.ValueOr(test.path, "") || "1" != c.ValueOr("x", "") {
+			dbg.Error("SetValue %s %q: %v\n%s", test.path, test.value, err, out)
+			t.Fail()
+		}
+	}
+}
+
+func TestSetValueDedented(t *testing.T) {
+	src := "grp (\n\tsql <|\n\t    SELECT *\n\t      FROM t\n\t>\n)\nx := 1\n"
+	out, err := SetValue([]byte(src), "grp:sql", "SELECT id\n  FROM u")
+	want := "grp (\n\tsql <|\n\t    SELECT id\n\t      FROM u\n\t>\n)\nx := 1\n"
+	if nil != err || want != string(out) {
+		dbg.Error("SetValue of a <| block: %v\n%s", err, out)
+		t.FailNow()
+	}
+	if c, err := Parse(string(out)); nil != err || "SELECT id\n  FROM u" != c.ValueOr("grp:sql", "") {
+		dbg.Error("Parse after SetValue of a <| block: %v", err)
+		t.Fail()
+	}
+}
+
+func TestSetValueInFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "test.cfg")
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte(editSrc), 0640), "WriteFile")
+	if err := SetValueInFile(flPath, "grp:sub:deep", "tree"); nil != err {
+		dbg.Error("SetValueInFile: %v", err)
+		t.Fail()
+	}
+	data, _ := ioutil.ReadFile(flPath)
+	want := strings.Replace(editSrc, "deep := y", "deep := tree", 1)
+	if want != string(data) {
+		dbg.Error("SetValueInFile gave:\n%q", data)
+		t.Fail()
+	}
+	if info, _ := os.Stat(flPath); nil == info || 0640 != info.Mode().Perm() {
+		dbg.Error("SetValueInFile changed the file mode")
+		t.Fail()
+	}
+}
+
+func TestAppendSection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "new.cfg")
+	grp := Node{Type: ConfigGroup, Children: []*Node{
+		{Type: ConfigValue, Label: "host", Data: []string{"x"}},
+		{Type: ConfigItems, Label: "ports", Data: []string{"80", "443"}},
+	}}
+	tests := []struct {
+		start, want string
+	}{
+		{"", "a (\n\thost := x\n\tports {\n\t\t80 443\n\t}\n)\n"},
+		{"name := app", "name := app\n\nb := v\n"},
+		{"name := app\n", "name := app\n\nb := v\n"},
+		{"name := app\r\n\r\n", "name := app\r\n\r\nb := v\r\n"},
+	}
+	for i, test := range tests {
+		if "" != test.start {
+			dbg.ChkErr(ioutil.WriteFile(flPath, []byte(test.start), 0644), "WriteFile")
+		}
+		node, label := grp, "a"
+		if 0 != i {
+			node, label = Node{Type: ConfigValue, Data: []string{"v"}}, "b"
+		}
+		if err := AppendSection(flPath, label, node); nil != err {
+			dbg.Error("AppendSection: %v", err)
+			t.Fail()
+		}
+		data, _ := ioutil.ReadFile(flPath)
+		if test.want != string(data) {
+			dbg.Error("AppendSection gave:\n%q", data)
+			t.Fail()
+		}
+		os.Remove(flPath)
+	}
+	if err := AppendSection(flPath, "not ok", grp); nil == err {
+		dbg.Error("AppendSection with an illegal label didn't fail")
+		t.Fail()
+	}
+}
+
+func TestLineOf(t *testing.T) {
+	src := []byte("# top\nname := app\ndb (\n\thost := x\n\tsub (\n\t\tport := 1\n\t)\n)\nserver (\n\tport := 1\n)\nserver (\n\tport := 2\n)\ninline ( a := 1 )\n")
+	for path, line := range map[string]int{
+		"name": 2, "db": 3, "db:host": 4, "db:sub:port": 6, "db:sub:missing": 5, "db:missing:x": 3,
+		"server:port": 13, "server[0]:port": 10, "inline:a": 15, "missing": 0,
+	} {
+		if got := LineOf(src, path); line != got {
+			dbg.Error("LineOf %s: %d", path, got)
+			t.Fail()
+		}
+	}
+}
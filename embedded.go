@@ -0,0 +1,102 @@
+package cfg
+
+/*
+	Returns Options parsing every config over the defaults, so a program
+	 can ship its defaults compiled in and a user's file only needs the
+	 settings that differ, e.g.
+
+		//go:embed defaults.cfg
+		var defaults []byte
+
+		c, err := cfg.WithDefaults(defaults).LoadConfig("app.cfg")
+
+	See Options.Defaults
+*/
+func WithDefaults(defaults []byte) Options {
+	return Options{Defaults: defaults}
+}
+
+/*
+	Returns the options with Defaults set, see WithDefaults
+*/
+func (o Options) WithDefaults(defaults []byte) Options {
+	o.Defaults = defaults
+	return o
+}
+
+/*
+	Returns the config of the Options.Defaults the config was parsed over,
+	 nil when it had none
+*/
+func (c *Config) Defaults() *Config {
+	return c.defaults
+}
+
+/*
+	Returns the entries of the config that differ from its defaults, as
+	 Diff(c.Defaults(), c); without defaults every entry is a Change.  With
+	 Options.Interpolate the references of the defaults are resolved as they
+	 are in the config first, so a default such as 'url := http://${host}'
+	 only deviates when the config sets url itself
+*/
+func (c *Config) Deviations() []Change {
+	d := c.defaults
+	switch {
+	case nil == d:
+		d = newConfig()
+	case c.opts.Interpolate:
+		d = c.resolvedDefaults()
+	}
+	return Diff(d, c)
+}
+
+// ------------------------------------------------------------------------- //
+
+// underlay puts the entries of Options.Defaults beneath those of the
+// config, each entry of the config replacing that of the defaults with the
+// same label path
+func (c *Config) underlay() error {
+	if nil == c.opts.Defaults {
+		return nil
+	}
+	do := c.opts
+	do.Defaults, do.Interpolate, do.partial = nil, false, true
+	d, err := do.Parse(string(c.opts.Defaults))
+	if nil != err {
+		return err
+	}
+	base := d.Clone()
+	base.opts = c.opts
+	if err = base.mergeable(c); nil != err {
+		return err
+	}
+	base.merge(c)
+	c.root, c.nodes, c.defaults = base.root, base.nodes, d
+	for _, n := range c.root.Children {
+		n.parent = &c.root
+	}
+	return nil
+}
+
+// resolvedDefaults returns a copy of the defaults with the references of
+// each value resolved against the config, as the value would have been had
+// the config not set it; a value that can't be resolved is left as is
+func (c *Config) resolvedDefaults() *Config {
+	d := c.defaults.Clone()
+	done := make(map[*Node]int)
+	c.flatten(&c.root, func(n *Node) {
+		done[n] = 2
+	})
+	d.flatten(&d.root, func(n *Node) {
+		r := c.nodes[n.Path]
+		if ConfigValue != n.Type || nil == r || ConfigValue != r.Type {
+			return
+		}
+		v := *r
+		v.Data = []string{n.Data[0]}
+		if nil == c.resolve(&v, nil, done) {
+			n.Data = v.Data
+		}
+	})
+	return d
+}
@@ -0,0 +1,87 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	testDefaults = "name := app\nurl := http://${host}:${db:port}\ndb (\n\thost := localhost\n\tport := 5432\n)\ntags { a b }\n"
+)
+
+func TestWithDefaults(t *testing.T) {
+	o := WithDefaults([]byte(testDefaults))
+	c, err := o.Parse("db (\n\tport := 6543\n)\nextra := yes\n")
+	if nil != err || "app" != c.ValueOr("name", "") || "localhost" != c.ValueOr("db:host", "") || "6543" != c.ValueOr("db:port", "") ||
+		"yes" != c.ValueOr("extra", "") || nil == c.Defaults() || "5432" != c.Defaults().ValueOr("db:port", "") {
+		dbg.Error("WithDefaults: %v", err)
+		t.Fail()
+	}
+	changes := c.Deviations()
+	if 2 != len(changes) || "db:port" != changes[0].Path || ChangeModified != changes[0].Kind || "extra" != changes[1].Path ||
+		ChangeAdded != changes[1].Kind {
+		dbg.Error("Deviations: %+v", changes)
+		t.Fail()
+	}
+	if clone := c.Clone(); clone.Defaults() != c.Defaults() {
+		dbg.Error("Clone lost the defaults")
+		t.Fail()
+	}
+
+	// references resolve across the defaults and the config
+	c, err = o.WithDefaults([]byte(testDefaults)).Parse("host := example.com\n")
+	if nil != err || "http://${host}:${db:port}" != c.ValueOr("url", "") {
+		dbg.Error("no Interpolate: %v %s", err, c)
+		t.Fail()
+	}
+	o.Interpolate = true
+	if c, err = o.Parse("host := example.com\ndb (\n\tport := 80\n)\n"); nil != err || "http://example.com:80" != c.ValueOr("url", "") {
+		dbg.Error("Interpolate: %v", err)
+		t.Fail()
+	}
+	changes = c.Deviations()
+	if 2 != len(changes) || "db:port" != changes[0].Path || "host" != changes[1].Path {
+		dbg.Error("Deviations of interpolated defaults: %+v", changes)
+		t.Fail()
+	}
+	if c, err = o.Parse("host := example.com\nurl := http://${host}\n"); nil != err || 2 != len(c.Deviations()) {
+		dbg.Error("Deviations of a set url: %v %+v", err, c.Deviations())
+		t.Fail()
+	}
+
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a.cfg"), filepath.Join(dir, "b.cfg")
+	ioutil.WriteFile(a, []byte("name := first\n"), 0644)
+	ioutil.WriteFile(b, []byte("tags { c }\n"), 0644)
+	o.Interpolate = false
+	for _, load := range []func() (*Config, error){
+		func() (*Config, error) { return o.LoadConfig(a) },
+		func() (*Config, error) { return o.ParseFiles(a, b) },
+	} {
+		c, err := load()
+		if l, _ := c.GetStringList("tags"); nil != err || "first" != c.ValueOr("name", "") || "5432" != c.ValueOr("db:port", "") || 0 == len(l) {
+			dbg.Error("loaded: %v %s", err, c)
+			t.Fail()
+		}
+	}
+
+	if _, err := o.Parse("db := none\n"); !errors.Is(err, ErrWrongType) {
+		dbg.Error("group replaced by value: %v", err)
+		t.Fail()
+	}
+	if _, err := WithDefaults([]byte("g (\nx := 1\n)\n")).Parse("a := 1\n"); !errors.Is(err, ErrIllegalDataBlock) {
+		dbg.Error("bad defaults: %v", err)
+		t.Fail()
+	}
+	if c, err := Parse("a := 1\n"); nil != err || nil != c.Defaults() || 1 != len(c.Deviations()) {
+		dbg.Error("no defaults: %v", err)
+		t.Fail()
+	}
+}
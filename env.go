@@ -0,0 +1,48 @@
+package cfg
+
+import (
+	"os"
+	"strings"
+	"unicode"
+)
+
+/*
+	Replaces each value of the config that has an environment variable
+	 set for it, returning the label paths of the values replaced.  The
+	 variable for a label path is the prefix followed by each upper cased
+	 label joined by '_', with any '_' in a label doubled, so with the
+	 prefix "MYAPP_":
+
+		testData:blocks:banana      MYAPP_TESTDATA_BLOCKS_BANANA
+		db:max_conns                MYAPP_DB_MAX__CONNS
+
+	Any other character that can't be used in a variable name, e.g. the
+	 '-' of Options.LabelChars, is also given as '_'
+*/
+func ApplyEnvOverrides(c *Config, prefix string) []string {
+	applied := []string{}
+	for _, p := range c.valuePaths() {
+		if v, ok := os.LookupEnv(envName(prefix, p)); ok {
+			c.nodes[p].Data = []string{v}
+			applied = append(applied, p)
+		}
+	}
+	return applied
+}
+
+// ------------------------------------------------------------------------- //
+
+// envName returns the environment variable overriding the label path, see
+// ApplyEnvOverrides
+func envName(prefix, path string) string {
+	labels := strings.Split(path, ":")
+	for i, label := range labels {
+		labels[i] = strings.Map(func(r rune) rune {
+			if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return '_'
+			}
+			return unicode.ToUpper(r)
+		}, strings.ReplaceAll(label, "_", "__"))
+	}
+	return prefix + strings.Join(labels, "_")
+}
@@ -0,0 +1,48 @@
+package cfg
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestApplyEnvOverrides(t *testing.T) {
+	c, err := Options{LabelChars: "-"}.LoadConfig("testdata/testBlocks.cfg")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	extra, _ := Options{LabelChars: "-"}.Parse("db (\n\tmax_conns := 4\n\tmax-idle := 2\n\tmaxconns := 8\n)\n")
+	c.merge(extra)
+	env := map[string]string{
+		"MYAPP_TESTDATA_BLOCKS_BANANA": "tree",
+		"MYAPP_TESTDATA_APPLE":         "pie",
+		"MYAPP_DB_MAX__CONNS":          "16",
+		"MYAPP_DB_MAX_IDLE":            "1",
+		"MYAPP_UNKNOWN":                "x",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+	applied := ApplyEnvOverrides(c, "MYAPP_")
+	if !reflect.DeepEqual([]string{"db:max-idle", "db:max_conns", "testData:apple", "testData:blocks:banana"}, applied) {
+		dbg.Error("ApplyEnvOverrides: %v", applied)
+		t.Fail()
+	}
+	expect := map[string]string{
+		"testData:blocks:banana": "tree",
+		"testData:apple":         "pie",
+		"db:max_conns":           "16",
+		"db:max-idle":            "1",
+		"db:maxconns":            "8",
+	}
+	for p, want := range expect {
+		if got, _ := c.Value(p); want != got {
+			dbg.Error("ApplyEnvOverrides %s: %q", p, got)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cfg
+
+import (
+	"strings"
+)
+
+// escapedLine returns the line of a lines section less its indent and the
+// '\' escaping it, as written, when Options.SectionEscapes is set
+func (o Options) escapedLine(l string) (string, bool) {
+	l = strings.TrimLeft(l, " \t")
+	if !o.SectionEscapes || !strings.HasPrefix(l, `\`) {
+		return "", false
+	}
+	return l[1:], true
+}
+
+// unescapeBlock removes the '\' starting any line of the block text when
+// Options.SectionEscapes is set
+func (o Options) unescapeBlock(text string) string {
+	if !o.SectionEscapes || !strings.Contains(text, `\`) {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimPrefix(l, `\`)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ------------------------------------------------------------------------- //
+
+// escapeLines returns the entries of a lines section with a '\' added to
+// each that wouldn't be parsed back as written: blank, padded, starting with
+// a '\' or an '@' directive, or (unless kept by ListComments) a comment
+func (c *Config) escapeLines(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, l := range lines {
+		if result[i] = l; "" == l && c.opts.KeepBlankLines {
+			continue
+		}
+		if "" == l || strings.TrimSpace(l) != l || strings.HasPrefix(l, `\`) || strings.HasPrefix(l, "@") ||
+			(!c.opts.ListComments && c.opts.IsComment(l)) {
+			result[i] = `\` + l
+		}
+	}
+	return result
+}
+
+// escapeBlock returns the lines of the block text with a '\' added to each
+// line starting with one, read as an '@' directive or that would end the
+// block early, or nil when the text can't be written as a < > block
+func escapeBlock(text string) []string {
+	if "" == text {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, `\`) || strings.HasPrefix(strings.TrimLeft(l, " \t"), "@") || (1 == len(l) && strings.Contains(">]})", l)) {
+			lines[i] = `\` + l
+		}
+	}
+	return lines
+}
@@ -0,0 +1,70 @@
+package cfg
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestSectionEscapes(t *testing.T) {
+	src := "text <\nbefore\n\\>\n\\\\raw\n>\nlist [\n\t\\]\n\t\\# not a comment\n\t\\  padded  \n\t\\\n\t# a comment\n\tplain\n]\n"
+	o := Options{SectionEscapes: true}
+	c, err := o.Parse(src)
+	if nil != err {
+		dbg.Error("Parse escapes: %v", err)
+		t.FailNow()
+	}
+	if got, _ := c.Value("text"); "before\n>\n\\raw" != got {
+		dbg.Error("Parse escaped block: %q", got)
+		t.Fail()
+	}
+	want := []string{"]", "# not a comment", "  padded  ", "", "plain"}
+	if n := c.Lookup("list"); nil == n || !reflect.DeepEqual(want, n.Data) {
+		dbg.Error("Parse escaped lines: %v", n)
+		t.Fail()
+	}
+
+	// without the option the '\' is kept
+	c, err = Parse("text <\n\\x\n>\n")
+	if got, _ := c.Value("text"); nil != err || "\\x" != got {
+		dbg.Error("Parse unescaped block: %q %v", got, err)
+		t.Fail()
+	}
+}
+
+func TestSectionEscapesRoundTrip(t *testing.T) {
+	block := "]\n>\n\\a\n}\n@if os == \"none\"\n\t@include x.cfg\n@end\ntext"
+	lines := []string{"]", ">", "\\b", "", "  padded  ", "# hash", "@if os == \"none\"", "  @include x.cfg", "@end", "plain"}
+	c := newConfig()
+	c.opts.SectionEscapes = true
+	c.root.Children = []*Node{
+		{Type: ConfigGroup, Label: "g", Path: "g", Children: []*Node{
+			{Type: ConfigBlock, Label: "block", Path: "g:block", Data: []string{block}},
+			{Type: ConfigLines, Label: "lines", Path: "g:lines", Data: lines},
+		}},
+	}
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); nil != err {
+		dbg.Error("WriteTo escapes: %v", err)
+		t.FailNow()
+	}
+	if bytes.Contains(buf.Bytes(), []byte("<<")) {
+		dbg.Error("WriteTo escapes used a heredoc:\n%s", buf.String())
+		t.Fail()
+	}
+	r, err := Options{SectionEscapes: true, Include: FileInclude}.Parse(buf.String())
+	if nil != err {
+		dbg.Error("Parse written escapes: %v\n%s", err, buf.String())
+		t.FailNow()
+	}
+	if got, _ := r.Value("g:block"); block != got {
+		dbg.Error("Round trip block: %q\n%s", got, buf.String())
+		t.Fail()
+	}
+	if n := r.Lookup("g:lines"); nil == n || !reflect.DeepEqual(lines, n.Data) {
+		dbg.Error("Round trip lines: %v\n%s", n, buf.String())
+		t.Fail()
+	}
+}
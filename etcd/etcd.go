@@ -0,0 +1,238 @@
+/*
+	Package etcd reads config data from an etcd (v3) key as a cfg.Source,
+	 through the JSON gateway of the etcd API so it needs no etcd library:
+
+		c, err := cfg.LoadSource(ctx, etcd.New("/app/config"))
+*/
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	/*
+		A Source is an etcd key, the zero value of each setting other than
+		 the Key using the etcd default
+	*/
+	Source struct {
+		// The client URL of an etcd member, "" for http://127.0.0.1:2379
+		Endpoint string
+
+		// The key holding the config data
+		Key string
+
+		// Headers added to each request, e.g. the Authorization token
+		//  from /v3/auth/authenticate
+		Header http.Header
+
+		// The client used for the requests, nil for http.DefaultClient
+		Client *http.Client
+
+		// The delay before a watch retries a failed request, 0 for 5
+		//  seconds
+		Retry time.Duration
+	}
+
+	keyValue struct {
+		Value       string `json:"value"`
+		ModRevision string `json:"mod_revision"`
+	}
+
+	rangeResponse struct {
+		Header struct {
+			Revision string `json:"revision"`
+		} `json:"header"`
+		Kvs []keyValue `json:"kvs"`
+	}
+
+	watchResponse struct {
+		Result struct {
+			Canceled bool `json:"canceled"`
+			Events   []struct {
+				Type string   `json:"type"`
+				Kv   keyValue `json:"kv"`
+			} `json:"events"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+)
+
+var (
+	ErrNoKey = errors.New("No such etcd key")
+)
+
+/*
+	Returns the Source for the key using the default etcd settings
+*/
+func New(key string) *Source {
+	return &Source{Key: key}
+}
+
+/*
+	Returns the data of the key
+*/
+func (s *Source) Fetch(ctx context.Context) ([]byte, error) {
+	data, _, err := s.get(ctx)
+	return data, err
+}
+
+/*
+	Sends the data of the key, then the new data each time it's put, using
+	 an etcd watch; a failed watch is started again from the current data
+	 until the context is done, when the channel is closed.  A deleted key
+	 sends nothing, the config is kept until the key is put again
+*/
+func (s *Source) Watch(ctx context.Context) <-chan []byte {
+	ch := make(chan []byte)
+	go func() {
+		defer close(ch)
+		var last []byte
+		send := func(data []byte) bool {
+			if nil != last && bytes.Equal(last, data) {
+				return true
+			}
+			select {
+			case ch <- data:
+				last = data
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for nil == ctx.Err() {
+			data, rev, err := s.get(ctx)
+			if nil == err && send(data) {
+				err = s.watch(ctx, rev+1, send)
+			}
+			if nil != err && !sleep(ctx, s.retry()) {
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// ------------------------------------------------------------------------- //
+
+// get reads the value of the key along with the store revision
+func (s *Source) get(ctx context.Context) ([]byte, int64, error) {
+	resp, err := s.post(ctx, "/v3/kv/range", map[string]interface{}{"key": s.key()})
+	if nil != err {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	var r rangeResponse
+	if err = json.NewDecoder(resp.Body).Decode(&r); nil != err {
+		return nil, 0, err
+	}
+	rev, _ := strconv.ParseInt(r.Header.Revision, 10, 64)
+	if 0 == len(r.Kvs) {
+		return nil, rev, ErrNoKey
+	}
+	data, err := base64.StdEncoding.DecodeString(r.Kvs[0].Value)
+	return data, rev, err
+}
+
+// watch sends the value of each put of the key from the revision until the
+// watch fails or send returns false
+func (s *Source) watch(ctx context.Context, rev int64, send func([]byte) bool) error {
+	resp, err := s.post(ctx, "/v3/watch", map[string]interface{}{
+		"create_request": map[string]interface{}{"key": s.key(), "start_revision": strconv.FormatInt(rev, 10)},
+	})
+	if nil != err {
+		return err
+	}
+	defer resp.Body.Close()
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var w watchResponse
+		if err := dec.Decode(&w); nil != err {
+			return err
+		}
+		if nil != w.Error {
+			return errors.New("etcd watch of " + s.Key + " failed: " + w.Error.Message)
+		}
+		if w.Result.Canceled {
+			return errors.New("etcd watch of " + s.Key + " canceled")
+		}
+		for _, e := range w.Result.Events {
+			if "DELETE" == e.Type {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(e.Kv.Value)
+			if nil != err {
+				return err
+			}
+			if !send(data) {
+				return nil
+			}
+		}
+	}
+}
+
+func (s *Source) post(ctx context.Context, api string, body interface{}) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if nil != err {
+		return nil, err
+	}
+	endpoint := s.Endpoint
+	if "" == endpoint {
+		endpoint = "http://127.0.0.1:2379"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+api, bytes.NewReader(b))
+	if nil != err {
+		return nil, err
+	}
+	for k, v := range s.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := s.Client
+	if nil == client {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if nil != err {
+		return nil, err
+	}
+	if http.StatusOK != resp.StatusCode {
+		resp.Body.Close()
+		return nil, errors.New("etcd request for " + s.Key + " failed: " + resp.Status)
+	}
+	return resp, nil
+}
+
+func (s *Source) key() string {
+	return base64.StdEncoding.EncodeToString([]byte(s.Key))
+}
+
+func (s *Source) retry() time.Duration {
+	if 0 == s.Retry {
+		return 5 * time.Second
+	}
+	return s.Retry
+}
+
+// sleep waits for the duration, returning false if the context is done
+// first
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
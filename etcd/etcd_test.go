@@ -0,0 +1,79 @@
+package etcd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/cfg"
+	"github.com/jayacarlson/dbg"
+)
+
+func TestSource(t *testing.T) {
+	puts := make(chan string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/v3/kv/range":
+			if base64.StdEncoding.EncodeToString([]byte("/app/config")) != req["key"] {
+				w.Write([]byte(`{"header":{"revision":"7"}}`))
+				return
+			}
+			w.Write([]byte(`{"header":{"revision":"7"},"kvs":[{"value":"` + base64.StdEncoding.EncodeToString([]byte("port := 8080\n")) + `","mod_revision":"5"}]}`))
+		case "/v3/watch":
+			if cr, _ := req["create_request"].(map[string]interface{}); "8" != cr["start_revision"] {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"result":{"created":true}}` + "\n"))
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case v := <-puts:
+					w.Write([]byte(`{"result":{"events":[{"kv":{"value":"` + base64.StdEncoding.EncodeToString([]byte(v)) + `"}}]}}` + "\n"))
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+	}))
+	defer srv.Close()
+
+	src := &Source{Endpoint: srv.URL, Key: "/app/config", Retry: 10 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := cfg.LoadSource(ctx, src)
+	if port, _ := c.GetInt("port"); nil != err || 8080 != port {
+		dbg.Error("LoadSource: %d %v", port, err)
+		t.Fail()
+	}
+
+	ports := make(chan int64)
+	wctx, stop := context.WithCancel(ctx)
+	defer stop()
+	go cfg.WatchSource(wctx, src, func(c *cfg.Config, err error) {
+		port, _ := c.GetInt("port")
+		ports <- port
+	})
+	if port := <-ports; 8080 != port {
+		dbg.Error("WatchSource first: %d", port)
+		t.Fail()
+	}
+	puts <- "port := 9090\n"
+	if port := <-ports; 9090 != port {
+		dbg.Error("WatchSource put: %d", port)
+		t.Fail()
+	}
+
+	if _, err := (&Source{Endpoint: srv.URL, Key: "/none"}).Fetch(ctx); !errors.Is(err, ErrNoKey) {
+		dbg.Error("Fetch missing key: %v", err)
+		t.Fail()
+	}
+}
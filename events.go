@@ -0,0 +1,259 @@
+package cfg
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+type (
+	/*
+		What an Event reports, see EventReader
+	*/
+	EventKind int
+
+	/*
+		An Event is a single step of reading config data: the start or end
+		 of a (data) container, or an entry with the type, label path and
+		 data HandleConfigData would pass; Line is the line of the data it
+		 starts on, from 1.  Err is only set for the last Event sent by
+		 Events when reading fails
+	*/
+	Event struct {
+		Kind  EventKind
+		Type  ConfigType // ConfigGroup for the start & end of a container
+		Path  string
+		Data  []string
+		Attrs map[string]string // those of a section, see Config.Attributes
+		Line  int
+		Err   error
+	}
+
+	/*
+		An EventReader reads config data one line at a time, SAX style, so
+		 configs of any size are read while holding no more than a single
+		 entry (or the open containers) in memory:
+
+			er := cfg.NewEventReader(f)
+			for {
+				e, err := er.Next()
+				if io.EOF == err {
+					break
+				}
+				...
+			}
+
+		Preprocessing directives such as @include and @if aren't expanded
+	*/
+	EventReader struct {
+		o      Options
+		r      *bufio.Reader
+		line   int
+		groups []string // the label paths of the open containers
+		queue  []Event
+		trivia bool // also queue the comment & blank lines, for ParseAST
+	}
+)
+
+const (
+	EventEntry EventKind = iota
+	EventStartGroup
+	EventEndGroup
+
+	// queued for ParseAST only
+	eventComment
+	eventBlank
+)
+
+/*
+	Returns an EventReader of the config data read from r
+*/
+func NewEventReader(r io.Reader) *EventReader {
+	return Options{}.NewEventReader(r)
+}
+
+/*
+	As NewEventReader, using these options to read the entries
+*/
+func (o Options) NewEventReader(r io.Reader) *EventReader {
+	return &EventReader{o: o, r: bufio.NewReader(r)}
+}
+
+/*
+	Sends the Events of the config data read from r to the channel, which
+	 is closed once the data has all been read; a failure to read it is
+	 sent as an Event with Err set.  The channel must be read to the end,
+	 otherwise use an EventReader
+*/
+func Events(r io.Reader) <-chan Event {
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		er := NewEventReader(r)
+		for {
+			e, err := er.Next()
+			if io.EOF == err {
+				return
+			}
+			if nil != err {
+				ch <- Event{Err: err}
+				return
+			}
+			ch <- e
+		}
+	}()
+	return ch
+}
+
+/*
+	Returns the next Event, or io.EOF once the data has all been read; a
+	 container still open at the end of the data is a *PathError wrapping
+	 ErrUnendedSection
+*/
+func (er *EventReader) Next() (Event, error) {
+	for 0 == len(er.queue) {
+		if err := er.read(); nil != err {
+			return Event{}, err
+		}
+	}
+	e := er.queue[0]
+	er.queue = er.queue[1:]
+	return e, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// read reads the next line, and the rest of a section it starts, queueing
+// its Events
+func (er *EventReader) read() error {
+	line, ok, err := er.readLine()
+	if nil != err {
+		return err
+	}
+	depth := len(er.groups)
+	if !ok {
+		if 0 != depth {
+			return &PathError{er.groups[depth-1], ErrUnendedSection}
+		}
+		return io.EOF
+	}
+	start, lp, tabs := er.line, "", strings.Repeat("\t", depth)
+	if 0 != depth {
+		lp = er.groups[depth-1]
+		if tabs[1:]+")" == strings.TrimRight(line, " \t") {
+			er.queue = append(er.queue, Event{Kind: EventEndGroup, Type: ConfigGroup, Path: lp, Line: start})
+			er.groups = er.groups[:depth-1]
+			return nil
+		}
+	}
+	text := strings.TrimRight(strings.TrimPrefix(line, tabs), " \t")
+	switch {
+	case "" == strings.TrimSpace(text):
+		if er.trivia {
+			er.queue = append(er.queue, Event{Kind: eventBlank, Path: lp, Line: start})
+		}
+		return nil
+	case strings.HasPrefix(strings.TrimSpace(text), "#"), er.o.isComment(text):
+		if er.trivia {
+			er.queue = append(er.queue, Event{Kind: eventComment, Type: ConfigComment, Path: lp, Data: []string{text}, Line: start})
+		}
+		return nil
+	case !strings.HasPrefix(line, tabs):
+		return &PathError{lp, ErrIllegalDataBlock}
+	case ' ' == text[0] || '\t' == text[0]:
+		// not an entry
+		return nil
+	}
+	label, closer, group := er.o.opens(text)
+	if group {
+		path := joinPath(lp, label)
+		var attrs map[string]string
+		if a := sectionAttributes(text); nil != a {
+			attrs = pairsToMap(a)
+		}
+		er.queue = append(er.queue, Event{Kind: EventStartGroup, Type: ConfigGroup, Path: path, Attrs: attrs, Line: start})
+		er.groups = append(er.groups, path)
+		return nil
+	}
+	chunk := []string{text}
+	for last := text; "" != closer || er.o.LineContinuation && strings.HasSuffix(last, "\\"); {
+		l, ok, err := er.readLine()
+		if nil != err {
+			return err
+		}
+		if !ok {
+			if "" == closer {
+				break
+			}
+			return &PathError{joinPath(lp, label), ErrUnendedSection}
+		}
+		last = strings.TrimPrefix(l, tabs)
+		chunk = append(chunk, last)
+		if closer == strings.TrimRight(last, " \t") {
+			break
+		}
+	}
+	inline := ""
+	var attrs map[string]string
+	err = er.o.walk(lp, strings.Join(chunk, "\n")+"\n", func(t ConfigType, label string, data []string) {
+		switch t {
+		case ConfigComment:
+		case configAttributes:
+			attrs = pairsToMap(data)
+		case ConfigGroup:
+			inline = label
+			er.queue = append(er.queue, Event{Kind: EventStartGroup, Type: ConfigGroup, Path: label, Line: start})
+		default:
+			er.queue = append(er.queue, Event{Kind: EventEntry, Type: t, Path: label, Data: data, Attrs: attrs, Line: start})
+			attrs = nil
+		}
+	})
+	if "" != inline {
+		er.queue = append(er.queue, Event{Kind: EventEndGroup, Type: ConfigGroup, Path: inline, Line: start})
+	}
+	return err
+}
+
+// opens returns the label of the entry starting the line (less its indent)
+// and the line ending it, "" for an entry of a single line; group is set
+// for the start of a ( ) container
+func (o Options) opens(text string) (label, closer string, group bool) {
+	text = stripAttributes(text)
+	if x := formatSectionRex.FindStringSubmatch(text); nil != x && "(" == x[3] && "" == x[2] && "" == x[4] &&
+		o.syntax().label.MatchString(x[1]) {
+		return x[1], ")", true
+	}
+	if x := editValueRex.FindStringSubmatch(text); nil != x {
+		if "=" == strings.TrimSpace(x[2]) {
+			return x[1], ":==", false
+		}
+	} else if x := editHeredocRex.FindStringSubmatch(text); nil != x {
+		return x[1], x[2], false
+	} else if x := formatInlineRex.FindStringSubmatch(text); nil != x && !strings.ContainsAny(x[1], "({") {
+		// a single line
+	} else if cs, sec := o.syntax().findSection(text + "\n"); nil != cs && 0 == cs[0] {
+		return text[cs[2]:cs[3]], sec.close, false
+	} else if x := formatSectionRex.FindStringSubmatch(text); nil != x {
+		return x[1], matching[x[3]], false
+	}
+	return "", "", false
+}
+
+// readLine returns the next line without its end of line, false at the end
+// of the data
+func (er *EventReader) readLine() (string, bool, error) {
+	line, err := er.r.ReadString('\n')
+	if io.EOF == err {
+		if "" == line {
+			return "", false, nil
+		}
+		err = nil
+	}
+	if nil != err {
+		return "", false, err
+	}
+	if er.line++; 1 == er.line {
+		line = strings.TrimPrefix(line, "\ufeff")
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), true, nil
+}
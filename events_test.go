@@ -0,0 +1,69 @@
+package cfg
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestEvents(t *testing.T) {
+	src := "# servers\nname := app\n\nsrv (\n\thost := x\n\tmotd <\nhello\n\t>\n\tweb (\n\t\tports { 80 443 }\n\t)\n" +
+		"\tdb ( port := 5432 )\n)\nlog [\n\ta\n\tb\n]\n"
+	expect := []Event{
+		{Kind: EventEntry, Type: ConfigValue, Path: "name", Data: []string{"app"}, Line: 2},
+		{Kind: EventStartGroup, Type: ConfigGroup, Path: "srv", Line: 4},
+		{Kind: EventEntry, Type: ConfigValue, Path: "srv:host", Data: []string{"x"}, Line: 5},
+		{Kind: EventEntry, Type: ConfigBlock, Path: "srv:motd", Data: []string{"hello"}, Line: 6},
+		{Kind: EventStartGroup, Type: ConfigGroup, Path: "srv:web", Line: 9},
+		{Kind: EventEntry, Type: ConfigItems, Path: "srv:web:ports", Data: []string{"80", "443"}, Line: 10},
+		{Kind: EventEndGroup, Type: ConfigGroup, Path: "srv:web", Line: 11},
+		{Kind: EventStartGroup, Type: ConfigGroup, Path: "srv:db", Line: 12},
+		{Kind: EventEntry, Type: ConfigValue, Path: "srv:db:port", Data: []string{"5432"}, Line: 12},
+		{Kind: EventEndGroup, Type: ConfigGroup, Path: "srv:db", Line: 12},
+		{Kind: EventEndGroup, Type: ConfigGroup, Path: "srv", Line: 13},
+		{Kind: EventEntry, Type: ConfigLines, Path: "log", Data: []string{"a", "b"}, Line: 14},
+	}
+	got := []Event{}
+	for e := range Events(strings.NewReader(src)) {
+		if nil != e.Err {
+			dbg.Error(e.Err.Error())
+			t.FailNow()
+		}
+		got = append(got, e)
+	}
+	if len(expect) != len(got) {
+		dbg.Error("%d events, expected %d: %v", len(got), len(expect), got)
+		t.FailNow()
+	}
+	for i, e := range expect {
+		g := got[i]
+		if e.Kind != g.Kind || e.Type != g.Type || e.Path != g.Path || e.Line != g.Line ||
+			strings.Join(e.Data, "|") != strings.Join(g.Data, "|") {
+			dbg.Error("event %d is %+v, expected %+v", i, g, e)
+			t.Fail()
+		}
+	}
+
+	er := NewEventReader(strings.NewReader("a (\n\tb := 1\n"))
+	var err error
+	for nil == err {
+		_, err = er.Next()
+	}
+	var pe *PathError
+	if !errors.As(err, &pe) || "a" != pe.Path || !errors.Is(err, ErrUnendedSection) {
+		dbg.Error("unended group: %v", err)
+		t.Fail()
+	}
+	er = NewEventReader(strings.NewReader("a <\ntext\n"))
+	if _, err = er.Next(); !errors.Is(err, ErrUnendedSection) {
+		dbg.Error("unended block: %v", err)
+		t.Fail()
+	}
+	if _, err = NewEventReader(strings.NewReader("")).Next(); io.EOF != err {
+		dbg.Error("empty data: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,235 @@
+package cfg
+
+import (
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+	Flattens the config into a map keyed by the label paths generated by
+	 HandleConfigData, for systems that only understand flat key/value pairs:
+
+		ConfigValue, ConfigBlock    the value
+		ConfigLines, ConfigTable    the lines joined with "\n"
+		ConfigItems                 the items joined with ","
+		ConfigDict                  each entry as "path:key"
+		ConfigBinary                the data base64 encoded
+		custom sections             the data joined with "\n"
+
+	Groups only contribute their label to the paths of their entries; a label
+	 that is repeated has the value of the last entry.  The paths use the
+	 Options.PathSeparator the config was parsed with
+*/
+func (c *Config) Flatten() map[string]string {
+	result := make(map[string]string)
+	c.flatten(&c.root, func(n *Node) {
+		path := c.opts.externalPath(n.Path)
+		switch n.Type {
+		case ConfigLines, ConfigTable:
+			result[path] = strings.Join(n.Data, "\n")
+		case ConfigItems:
+			result[path] = strings.Join(n.Data, ",")
+		case ConfigDict:
+			for i := 0; i+1 < len(n.Data); i += 2 {
+				result[c.opts.externalPath(n.Path+":"+n.Data[i])] = n.Data[i+1]
+			}
+		case ConfigBinary:
+			result[path] = base64.StdEncoding.EncodeToString([]byte(n.Data[0]))
+		case ConfigValue, ConfigBlock:
+			result[path] = n.Data[0]
+		default:
+			// custom sections
+			result[path] = strings.Join(n.Data, "\n")
+		}
+	})
+	return result
+}
+
+/*
+	As Flatten, but the map values keep their type: a string for ConfigValue
+	 and ConfigBlock, a []string for ConfigLines and ConfigItems, a
+	 map[string]string for ConfigDict, a []map[string]string for ConfigTable,
+	 a []byte for ConfigBinary and the []string data of a custom section; with
+	 Options.InferTypes a ConfigValue has the type InferValue finds
+*/
+func (c *Config) FlattenTyped() map[string]interface{} {
+	result := make(map[string]interface{})
+	c.flatten(&c.root, func(n *Node) {
+		path := c.opts.externalPath(n.Path)
+		switch n.Type {
+		case ConfigLines, ConfigItems:
+			result[path] = append([]string(nil), n.Data...)
+		case ConfigDict:
+			result[path] = n.dict()
+		case ConfigTable:
+			result[path], _ = LinesToTable(n.Data)
+		case ConfigBinary:
+			result[path] = []byte(n.Data[0])
+		case ConfigValue, ConfigBlock:
+			if ConfigValue == n.Type {
+				result[path] = c.inferred(n.Data[0])
+			} else {
+				result[path] = n.Data[0]
+			}
+		default:
+			// custom sections
+			result[path] = append([]string(nil), n.Data...)
+		}
+	})
+	return result
+}
+
+/*
+	Returns the config as nested maps, for quick scripts and map decoders:
+
+		ConfigGroup                 a map[string]interface{}
+		ConfigValue, ConfigBlock    a string
+		ConfigLines, ConfigItems    a []string
+		ConfigDict                  a map[string]string
+		ConfigTable                 a []map[string]string
+		ConfigBinary                a []byte
+		custom sections             a []string
+
+	A label repeated in a group is a []interface{} holding each of the
+	 entries, in order.  With Options.InferTypes a ConfigValue has the type
+	 InferValue finds
+*/
+func (c *Config) ToMap() map[string]interface{} {
+	return c.mapGroup(&c.root)
+}
+
+/*
+	Parses the config data into nested maps, see Config.ToMap
+*/
+func DataToMap(str string) (map[string]interface{}, error) {
+	return Options{}.DataToMap(str)
+}
+
+/*
+	As DataToMap, using these options
+*/
+func (o Options) DataToMap(str string) (map[string]interface{}, error) {
+	c, err := o.Parse(str)
+	if nil != err {
+		return nil, err
+	}
+	return c.ToMap(), nil
+}
+
+/*
+	Returns a Config holding a ConfigValue for each entry of the map, keyed
+	 by label path as given by Flatten, e.g. "db:host"; entries are added in
+	 sorted order so the written config doesn't depend on map iteration
+*/
+func FromMap(m map[string]string) (*Config, error) {
+	paths := make([]string, 0, len(m))
+	for p := range m {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	c := newConfig()
+	for _, p := range paths {
+		elems := strings.Split(p, ":")
+		for i, elem := range elems {
+			if !labelRex.MatchString(elem) {
+				return nil, &PathError{p, ErrIllegalLabel}
+			}
+			if n := c.nodes[strings.Join(elems[:i+1], ":")]; nil != n && (i == len(elems)-1 || ConfigGroup != n.Type) {
+				return nil, &PathError{p, ErrWrongType}
+			}
+		}
+		c.add(ConfigValue, p, []string{m[p]})
+	}
+	return c, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// mapGroup returns the entries of the group as a map, see ToMap
+func (c *Config) mapGroup(g *Node) map[string]interface{} {
+	result, count := make(map[string]interface{}), make(map[string]int)
+	for _, n := range g.Children {
+		count[n.Label]++
+	}
+	for _, n := range g.Children {
+		if ConfigComment == n.Type {
+			continue
+		}
+		v := c.mapEntry(n)
+		if count[n.Label] > 1 {
+			list, _ := result[n.Label].([]interface{})
+			result[n.Label] = append(list, v)
+		} else {
+			result[n.Label] = v
+		}
+	}
+	return result
+}
+
+// mapEntry returns a node as ToMap gives it
+func (c *Config) mapEntry(n *Node) interface{} {
+	switch n.Type {
+	case ConfigGroup:
+		return c.mapGroup(n)
+	case ConfigDict:
+		return n.dict()
+	case ConfigTable:
+		rows, _ := LinesToTable(n.Data)
+		return rows
+	case ConfigBinary:
+		return []byte(n.Data[0])
+	case ConfigValue:
+		return c.inferred(n.Data[0])
+	case ConfigBlock:
+		return n.Data[0]
+	}
+	return append([]string(nil), n.Data...)
+}
+
+// flatten calls f for every non-group node below n, in file order
+func (c *Config) flatten(n *Node, f func(n *Node)) {
+	for _, ch := range n.Children {
+		if ConfigGroup == ch.Type {
+			c.flatten(ch, f)
+		} else if ConfigComment != ch.Type {
+			f(ch)
+		}
+	}
+}
+
+// indexed calls f for every non-group node below n as flatten does, giving
+// each entry of a label repeated within a group an indexed label path
+func (c *Config) indexed(n *Node, lp string, f func(t ConfigType, label string, data []string)) {
+	indexedNodes(n, lp, func(path string, n *Node) {
+		f(n.Type, path, n.Data)
+	})
+}
+
+// indexedNodes calls f with every non-group node below n and its indexed
+// label path, see indexed
+func indexedNodes(n *Node, lp string, f func(path string, n *Node)) {
+	count, seen := map[string]int{}, map[string]int{}
+	for _, ch := range n.Children {
+		count[ch.Label]++
+	}
+	for _, ch := range n.Children {
+		if ConfigComment == ch.Type {
+			continue
+		}
+		path := ch.Label
+		if count[ch.Label] > 1 {
+			path += "[" + strconv.Itoa(seen[ch.Label]) + "]"
+			seen[ch.Label]++
+		}
+		if "" != lp {
+			path = lp + ":" + path
+		}
+		if ConfigGroup == ch.Type {
+			indexedNodes(ch, path, f)
+		} else {
+			f(path, ch)
+		}
+	}
+}
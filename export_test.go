@@ -0,0 +1,76 @@
+package cfg
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestFlatten(t *testing.T) {
+	c, _ := Parse(string(conf))
+	flat := c.Flatten()
+	expect := map[string]string{
+		"testData:apple":         "tree",
+		"testData:blocks:block3": blk3,
+		"testData:lines:lines2":  "apple banana\ncherry\ndate\nfig   grape",
+		"testData:lists:items2":  "apple,banana,cherry,date,fig,grape",
+		"dict1:beta":             "banana bread",
+		"testData:lists:cherry":  "berry",
+		"testData:blocks:banana": "plant",
+		"testData:lines:cashew":  "nut",
+		"testData:blocks:block1": blk1,
+		"testData:lists:items1":  "apple,banana,cherry,date,{-},}-{,fig,grape",
+		"testData:lines:lines1":  "apple\nbanana cherry\ndate\n[ ]\n] [\nfig grape",
+		"testData:blocks:block2": blk2,
+	}
+	for k, v := range expect {
+		if flat[k] != v {
+			dbg.Error("%s: %q", k, flat[k])
+			t.Fail()
+		}
+	}
+	if _, ok := flat["testData:blocks"]; ok {
+		dbg.Error("Groups should not be flattened")
+		t.Fail()
+	}
+
+	typed := c.FlattenTyped()
+	if l, ok := typed["testData:lists:items1"].([]string); !ok || !compareEntries(itm1, l) {
+		dbg.Error("testData:lists:items1: %v", typed["testData:lists:items1"])
+		t.Fail()
+	}
+	if d, ok := typed["dict1"].(map[string]string); !ok || d["alpha"] != "apple sauce" {
+		dbg.Error("dict1: %v", typed["dict1"])
+		t.Fail()
+	}
+	if v, ok := typed["testData:apple"].(string); !ok || v != "tree" {
+		dbg.Error("testData:apple: %v", typed["testData:apple"])
+		t.Fail()
+	}
+}
+
+func TestDataToMap(t *testing.T) {
+	m, err := DataToMap("name := app\nmotd <\nhi\n>\nhosts { a b }\nenv : [\n\tk : v\n]\n" +
+		"db (\n\thost := x\n)\nsrv (\n\tp := 1\n)\nsrv (\n\tp := 2\n)\n")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]interface{}{
+		"name":  "app",
+		"motd":  "hi",
+		"hosts": []string{"a", "b"},
+		"env":   map[string]string{"k": "v"},
+		"db":    map[string]interface{}{"host": "x"},
+		"srv":   []interface{}{map[string]interface{}{"p": "1"}, map[string]interface{}{"p": "2"}},
+	}
+	if !reflect.DeepEqual(expect, m) {
+		dbg.Error("DataToMap: %v", m)
+		t.Fail()
+	}
+	if _, err = (Options{Recover: true}).DataToMap("a <\n"); nil == err {
+		dbg.Error("DataToMap of a malformed config")
+		t.Fail()
+	}
+}
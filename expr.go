@@ -0,0 +1,305 @@
+package cfg
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// a number of an expression, and the unit it's in
+	exprValue struct {
+		f    float64
+		unit exprUnit
+	}
+
+	exprUnit int
+
+	exprParser struct {
+		s   string
+		pos int
+		ops int // the operators and function calls read
+	}
+)
+
+const (
+	unitNone exprUnit = iota
+	unitSize
+	unitDuration
+)
+
+var (
+	ErrExprUnits  = errors.New("Config expression mixes units")
+	ErrExprDivide = errors.New("Config expression divides by zero")
+	ErrExprArgs   = errors.New("Wrong number of config expression function arguments")
+
+	// not an expression, the value is left as it is
+	errNotExpr = errors.New("not an expression")
+
+	// number & unit
+	// 1: number  2: unit
+	exprNumberRex = regexp.MustCompile(`^(\d+(?:\.\d+)?)([A-Za-zµ]*)`)
+	exprIdentRex  = regexp.MustCompile(`^[a-z]+`)
+
+	durationUnits = map[string]bool{"ns": true, "us": true, "µs": true, "ms": true, "s": true, "m": true, "h": true}
+
+	// the functions an expression can call; the arguments of each share a
+	// unit, which the result is in
+	exprFuncs = map[string]struct {
+		args int // -1 for any number
+		fn   func(args []float64) float64
+	}{
+		"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+		"ceil":  {1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+		"cpus":  {0, func([]float64) float64 { return float64(runtime.NumCPU()) }},
+		"floor": {1, func(a []float64) float64 { return math.Floor(a[0]) }},
+		"max":   {-1, func(a []float64) float64 { return extreme(a, 1) }},
+		"min":   {-1, func(a []float64) float64 { return extreme(a, -1) }},
+		"round": {1, func(a []float64) float64 { return math.Round(a[0]) }},
+	}
+)
+
+// ------------------------------------------------------------------------- //
+
+// evaluate replaces each ConfigValue that's an arithmetic expression with
+// its result, see Options.Expressions
+func (c *Config) evaluate() error {
+	var err error
+	c.flatten(&c.root, func(n *Node) {
+		if nil != err || ConfigValue != n.Type {
+			return
+		}
+		v, e := evalExpr(n.Data[0])
+		switch {
+		case nil == e:
+			n.Data[0] = v
+		case errNotExpr != e:
+			err = &PathError{n.Path, e}
+		}
+	})
+	return err
+}
+
+// evalExpr evaluates the expression, returning errNotExpr for text that
+// isn't one: anything the grammar doesn't allow, or a single number
+//
+//	expr    = term { ' + ' | ' - ' term }
+//	term    = unary { ' * ' | ' / ' | ' % ' unary }
+//	unary   = [ '-' ] ( number [ unit ] | '(' expr ')' | func '(' [ expr { ',' expr } ] ')' )
+func evalExpr(s string) (string, error) {
+	p := &exprParser{s: strings.TrimSpace(s)}
+	v, err := p.expr()
+	if nil == err && p.pos < len(p.s) {
+		err = errNotExpr
+	}
+	if nil == err && 0 == p.ops {
+		err = errNotExpr
+	}
+	if nil != err {
+		return "", err
+	}
+	switch v.unit {
+	case unitSize:
+		return strconv.FormatInt(int64(v.f), 10), nil
+	case unitDuration:
+		return time.Duration(v.f).String(), nil
+	}
+	if v.f == math.Trunc(v.f) && math.Abs(v.f) < 1<<53 {
+		return strconv.FormatInt(int64(v.f), 10), nil
+	}
+	return strconv.FormatFloat(v.f, 'g', -1, 64), nil
+}
+
+func (p *exprParser) expr() (exprValue, error) {
+	a, err := p.term()
+	for nil == err {
+		op := p.operator("+-")
+		if 0 == op {
+			break
+		}
+		var b exprValue
+		if b, err = p.term(); nil == err && a.unit != b.unit {
+			err = ErrExprUnits
+		}
+		if '+' == op {
+			a.f += b.f
+		} else {
+			a.f -= b.f
+		}
+	}
+	return a, err
+}
+
+func (p *exprParser) term() (exprValue, error) {
+	a, err := p.unary()
+	for nil == err {
+		op := p.operator("*/%")
+		if 0 == op {
+			break
+		}
+		var b exprValue
+		if b, err = p.unary(); nil != err {
+			break
+		}
+		switch {
+		case '*' == op && unitNone != a.unit && unitNone != b.unit:
+			err = ErrExprUnits
+		case '*' == op:
+			a.f *= b.f
+			if unitNone == a.unit {
+				a.unit = b.unit
+			}
+		case unitNone != b.unit && a.unit != b.unit:
+			err = ErrExprUnits
+		case 0 == b.f:
+			err = ErrExprDivide
+		case '/' == op:
+			a.f /= b.f
+			if a.unit == b.unit {
+				a.unit = unitNone
+			}
+		default:
+			a.f = math.Mod(a.f, b.f)
+		}
+	}
+	return a, err
+}
+
+func (p *exprParser) unary() (exprValue, error) {
+	p.space()
+	if p.pos >= len(p.s) {
+		return exprValue{}, errNotExpr
+	}
+	if '-' == p.s[p.pos] {
+		p.pos++
+		v, err := p.unary()
+		v.f = -v.f
+		return v, err
+	}
+	if '(' == p.s[p.pos] {
+		p.pos++
+		v, err := p.expr()
+		if nil == err && !p.expect(')') {
+			err = errNotExpr
+		}
+		return v, err
+	}
+	if x := exprNumberRex.FindStringSubmatch(p.s[p.pos:]); nil != x {
+		p.pos += len(x[0])
+		return exprNumber(x[1], x[2])
+	}
+	name := exprIdentRex.FindString(p.s[p.pos:])
+	fn, ok := exprFuncs[name]
+	if !ok {
+		return exprValue{}, errNotExpr
+	}
+	if p.pos += len(name); !p.expect('(') {
+		return exprValue{}, errNotExpr
+	}
+	args, err := p.args()
+	if nil != err {
+		return exprValue{}, err
+	}
+	p.ops++
+	if fn.args >= 0 && fn.args != len(args) || fn.args < 0 && 0 == len(args) {
+		return exprValue{}, ErrExprArgs
+	}
+	v := exprValue{unit: unitNone}
+	nums := make([]float64, len(args))
+	for i, a := range args {
+		if 0 != i && a.unit != v.unit {
+			return exprValue{}, ErrExprUnits
+		}
+		v.unit, nums[i] = a.unit, a.f
+	}
+	v.f = fn.fn(nums)
+	return v, nil
+}
+
+// args reads the arguments of a function call up to its ')'
+func (p *exprParser) args() ([]exprValue, error) {
+	args := []exprValue{}
+	if p.space(); p.expect(')') {
+		return args, nil
+	}
+	for {
+		v, err := p.expr()
+		if nil != err {
+			return nil, err
+		}
+		args = append(args, v)
+		if p.expect(')') {
+			return args, nil
+		}
+		if !p.expect(',') {
+			return nil, errNotExpr
+		}
+	}
+}
+
+// operator reads one of the binary operators, which must have whitespace
+// on both sides so that e.g. a date such as 2024-01-02 isn't subtracted
+func (p *exprParser) operator(ops string) byte {
+	p.space()
+	if p.pos+1 >= len(p.s) || !strings.ContainsRune(ops, rune(p.s[p.pos])) ||
+		!isSpace(p.s[p.pos-1]) || !isSpace(p.s[p.pos+1]) {
+		return 0
+	}
+	p.ops++
+	p.pos++
+	return p.s[p.pos-1]
+}
+
+func (p *exprParser) space() {
+	for p.pos < len(p.s) && isSpace(p.s[p.pos]) {
+		p.pos++
+	}
+}
+
+// expect skips whitespace and the character if it's next
+func (p *exprParser) expect(ch byte) bool {
+	if p.space(); p.pos < len(p.s) && ch == p.s[p.pos] {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// exprNumber returns the number in its unit: durations are in nanoseconds
+// and sizes in bytes
+func exprNumber(num, unit string) (exprValue, error) {
+	f, err := strconv.ParseFloat(num, 64)
+	switch {
+	case nil != err:
+		return exprValue{}, errNotExpr
+	case "" == unit:
+		return exprValue{f, unitNone}, nil
+	case durationUnits[unit]:
+		d, _ := time.ParseDuration("1" + unit)
+		return exprValue{f * float64(d), unitDuration}, nil
+	}
+	if mult, ok := sizeUnits[strings.ToUpper(unit)]; ok {
+		return exprValue{f * float64(mult), unitSize}, nil
+	}
+	return exprValue{}, errNotExpr
+}
+
+// extreme returns the largest of the numbers for a sign of 1, the smallest
+// for -1
+func extreme(nums []float64, sign float64) float64 {
+	result := nums[0]
+	for _, n := range nums[1:] {
+		if sign*n > sign*result {
+			result = n
+		}
+	}
+	return result
+}
+
+func isSpace(ch byte) bool {
+	return ' ' == ch || '\t' == ch
+}
@@ -0,0 +1,63 @@
+package cfg
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestExpressions(t *testing.T) {
+	src := "cpus := 4\nworkers := ${cpus} * 2\ncache := 64MB * 4\ntimeout := max(5s, 100ms * 20)\n" +
+		"ratio := 10 / 4\nslots := (1 + 2) * -3 % 4\nhalf := 1GiB / 512MiB\nthreads := cpus() + 1\n" +
+		"date := 2024-01-02\nname := a - b\nsize := 64MB\nbare := -5\n"
+	c, err := Options{Interpolate: true, Expressions: true}.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"workers": "8",
+		"cache":   "256000000",
+		"timeout": "5s",
+		"ratio":   "2.5",
+		"slots":   "-1",
+		"half":    "2",
+		"threads": strconv.Itoa(runtime.NumCPU() + 1),
+		"date":    "2024-01-02",
+		"name":    "a - b",
+		"size":    "64MB",
+		"bare":    "-5",
+	}
+	for p, v := range expect {
+		if got := c.ValueOr(p, ""); v != got {
+			dbg.Error("%s is %q, expected %q", p, got, v)
+			t.Fail()
+		}
+	}
+	if n, err := c.GetBytes("cache"); nil != err || 256000000 != n {
+		dbg.Error("GetBytes of an expression: %d %v", n, err)
+		t.Fail()
+	}
+	if c, _ = Parse("workers := 2 * 4\n"); "2 * 4" != c.ValueOr("workers", "") {
+		dbg.Error("expression evaluated without Options.Expressions")
+		t.Fail()
+	}
+
+	for src, want := range map[string]error{
+		"a := 5s + 1MB\n":    ErrExprUnits,
+		"a := 1MB * 2KB\n":   ErrExprUnits,
+		"a := 1 / 0\n":       ErrExprDivide,
+		"a := min()\n":       ErrExprArgs,
+		"a := abs(1s, 2s)\n": ErrExprArgs,
+	} {
+		_, err := Options{Expressions: true}.Parse(src)
+		var pe *PathError
+		if !errors.As(err, &pe) || "a" != pe.Path || !errors.Is(err, want) {
+			dbg.Error("%q: %v", src, err)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package cfg
+
+import (
+	"errors"
+)
+
+var (
+	// returned by the Scan handler of Extract once every path is found
+	errExtracted = errors.New("extracted")
+)
+
+/*
+	Returns the data of just the label paths asked for, as HandleConfigData
+	 would give it, scanning the config data (see Scan) only until each is
+	 found rather than parsing all of it, e.g.
+
+		m, err := cfg.Extract(data, "db:host", "db:port")
+		host := m["db:host"][0]
+
+	A path not found in the data is missing from the map, and for a label
+	 repeated only the first entry is used.  The paths name entries rather
+	 than groups, and as with Scan only the default syntax is read; the
+	 error is the *PathError of a malformed section found before the last
+	 of the paths
+*/
+func Extract(data []byte, paths ...string) (map[string][]string, error) {
+	result := make(map[string][]string, len(paths))
+	wanted := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		wanted[p] = true
+	}
+	if 0 == len(wanted) {
+		return result, nil
+	}
+	labels, prefix := [][]byte{}, ""
+	err := Scan(data, func(e RawEntry) error {
+		if e.Depth < len(labels) {
+			labels, prefix = labels[:e.Depth], ""
+		}
+		if ConfigGroup == e.Type {
+			labels, prefix = append(labels, e.Label), ""
+			return nil
+		}
+		if "" == prefix && 0 != len(labels) {
+			for _, l := range labels {
+				prefix += string(l) + ":"
+			}
+		}
+		path := prefix + string(e.Label)
+		if !wanted[path] {
+			return nil
+		}
+		d, err := extracted(e)
+		if nil != err {
+			return &PathError{path, err}
+		}
+		result[path] = d
+		if delete(wanted, path); 0 == len(wanted) {
+			return errExtracted
+		}
+		return nil
+	})
+	if nil != err && errExtracted != err {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// extracted returns the data of the entry as HandleConfigData gives it
+func extracted(e RawEntry) ([]string, error) {
+	switch e.Type {
+	case ConfigBlock, ConfigValue:
+		return []string{string(e.AppendText(nil))}, nil
+	case ConfigBinary:
+		b, err := decodeBinary(e.Encoding, string(e.AppendText(nil)))
+		if nil != err {
+			return nil, err
+		}
+		return []string{string(b)}, nil
+	case ConfigItems:
+		d := []string{}
+		e.EachItem(func(item []byte) bool {
+			d = append(d, string(item))
+			return true
+		})
+		return d, nil
+	case ConfigDict:
+		d := []string{}
+		e.EachPair(func(key, value []byte) bool {
+			d = append(d, string(key), string(value))
+			return true
+		})
+		return d, nil
+	}
+	d := []string{}
+	e.EachLine(func(line []byte) bool {
+		d = append(d, string(line))
+		return true
+	})
+	return d, nil
+}
@@ -0,0 +1,56 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestExtract(t *testing.T) {
+	paths := []string{"block1", "dict1", "testData:apple", "testData:blocks:block5", "testData:lists:items2",
+		"testData:lines:cashew", "testData:lines:lines1", "testData:none", "testData"}
+	want := map[string][]string{}
+	HandleConfigData(string(conf), func(t ConfigType, label string, data []string) {
+		if _, found := want[label]; !found {
+			for _, p := range paths {
+				if p == label {
+					want[label] = data
+				}
+			}
+		}
+	})
+	got, err := Extract(conf, paths...)
+	if nil != err || 7 != len(got) || !reflect.DeepEqual(want, got) {
+		dbg.Error("Extract: %v\n%q\n%q", err, got, want)
+		t.Fail()
+	}
+
+	// the scan stops once everything asked for is found
+	data := []byte("a := 1\ng (\n\tb <hex\n\t\t6869\n\t>\n)\nbad [\n")
+	if got, err := Extract(data, "g:b", "a", "a"); nil != err || "hi" != got["g:b"][0] || "1" != got["a"][0] {
+		dbg.Error("Extract stopped: %v %q", err, got)
+		t.Fail()
+	}
+	var pe *PathError
+	if _, err := Extract(data, "a", "c"); !errors.As(err, &pe) || "bad" != pe.Path {
+		dbg.Error("Extract error: %v", err)
+		t.Fail()
+	}
+	if _, err := Extract([]byte("b <hex\n\tzz\n>\n"), "b"); !errors.As(err, &pe) || "b" != pe.Path {
+		dbg.Error("Extract bad binary: %v", err)
+		t.Fail()
+	}
+	if got, err := Extract(conf); nil != err || 0 != len(got) {
+		dbg.Error("Extract nothing: %v %q", err, got)
+		t.Fail()
+	}
+}
+
+func BenchmarkExtract(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Extract(conf, "block2", "items1")
+	}
+}
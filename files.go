@@ -0,0 +1,90 @@
+package cfg
+
+import (
+	"github.com/jayacarlson/dbg"
+)
+
+/*
+	Reads each of the config files in turn, merging the later files over
+	 the earlier ones into a single Config tree, e.g. base, site & host
+	 override files; see Options.ParseFiles
+*/
+func ParseFiles(paths ...string) (*Config, error) {
+	return Options{}.ParseFiles(paths...)
+}
+
+/*
+	Reads and merges the config files as ParseFiles, passing the merged
+	 entries to the handler in the order of the first file they're found in
+*/
+func LoadConfigSet(paths []string, f func(t ConfigType, label string, data []string)) error {
+	c, err := ParseFiles(paths...)
+	if nil != err {
+		return err
+	}
+	c.flatten(&c.root, func(n *Node) {
+		f(n.Type, n.Path, n.Data)
+	})
+	return nil
+}
+
+/*
+	As ParseFiles, using these options.  An entry of a later file replaces
+	 the entry with the same label path, or with Options.AppendLists adds
+	 its items, lines or dictionary entries to it, and every other entry is
+	 added; replacing a value with a group, or a group with a value, is a
+	 *PathError wrapping ErrWrongType.  References are interpolated after
+	 merging, so a file can refer to values of the others
+*/
+func (o Options) ParseFiles(paths ...string) (*Config, error) {
+	fo := o
+	fo.Interpolate, fo.partial = false, true
+	configs := make([]*Config, len(paths))
+	for i, p := range paths {
+		c, err := fo.LoadConfig(p)
+		if nil != err {
+			return nil, err
+		}
+		configs[i] = c
+	}
+	return o.mergeFiles(paths, configs)
+}
+
+// ------------------------------------------------------------------------- //
+
+// mergeFiles merges the configs of the files in order, then interpolates
+// the result
+func (o Options) mergeFiles(paths []string, configs []*Config) (*Config, error) {
+	c := newConfig()
+	for i, next := range configs {
+		if 0 == i {
+			c = next
+			continue
+		}
+		if err := c.mergeable(next); dbg.ChkErr(err, "Failed to merge config file: %s (%v)", paths[i], err) {
+			return nil, err
+		}
+		c.merge(next)
+		for _, d := range next.deprecated {
+			c.deprecate(d)
+		}
+	}
+	c.opts = o
+	if err := c.underlay(); nil != err {
+		return nil, err
+	}
+	if o.Interpolate {
+		if err := c.interpolate(); nil != err {
+			return nil, err
+		}
+	}
+	if o.Expressions {
+		if err := c.evaluate(); nil != err {
+			return nil, err
+		}
+	}
+	if err := c.validated(); nil != err {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,70 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestParseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	write := func(name, data string) string {
+		flPath := filepath.Join(dir, name)
+		dbg.ChkErr(ioutil.WriteFile(flPath, []byte(data), 0644), "WriteFile")
+		return flPath
+	}
+	base := write("base.cfg", "root := /srv\nport := 8080\nhosts { a b }\ndb (\n\thost := localhost\n\tuser := app\n)\n")
+	site := write("site.cfg", "port := 80\nhosts { c }\ndb (\n\thost := db.site\n)\nlogs := ${root}/logs\n")
+	host := write("host.cfg", "db (\n\tpool := 4\n)\n")
+
+	c, err := Options{Interpolate: true}.ParseFiles(base, site, host)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"root": "/srv", "port": "80", "db:host": "db.site", "db:user": "app", "db:pool": "4", "logs": "/srv/logs",
+	}
+	for p, want := range expect {
+		if got, _ := c.Value(p); want != got {
+			dbg.Error("ParseFiles %s: %q", p, got)
+			t.Fail()
+		}
+	}
+	if l, _ := c.GetStringList("hosts"); !reflect.DeepEqual([]string{"c"}, l) || base != c.Source() {
+		dbg.Error("ParseFiles hosts: %v", l)
+		t.Fail()
+	}
+
+	c, err = Options{AppendLists: true}.ParseFiles(base, site)
+	if l, _ := c.GetStringList("hosts"); nil != err || !reflect.DeepEqual([]string{"a", "b", "c"}, l) {
+		dbg.Error("ParseFiles AppendLists: %v %v", l, err)
+		t.Fail()
+	}
+
+	labels := []string{}
+	err = LoadConfigSet([]string{base, host}, func(ct ConfigType, label string, data []string) {
+		labels = append(labels, label)
+	})
+	if want := []string{"root", "port", "hosts", "db:host", "db:user", "db:pool"}; nil != err || !reflect.DeepEqual(want, labels) {
+		dbg.Error("LoadConfigSet: %v %v", labels, err)
+		t.Fail()
+	}
+
+	bad := write("bad.cfg", "db := none\n")
+	if _, err := ParseFiles(base, bad); !errors.Is(err, ErrWrongType) {
+		dbg.Error("ParseFiles type change: %v", err)
+		t.Fail()
+	}
+	if _, err := ParseFiles(base, filepath.Join(dir, "missing.cfg")); nil == err {
+		dbg.Error("ParseFiles missing file")
+		t.Fail()
+	}
+}
@@ -0,0 +1,141 @@
+package cfg
+
+import (
+	"flag"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// configFlag is the flag.Value of a bound config value, checking any
+	// new value against the type inferred from the config data
+	configFlag struct {
+		n     *Node
+		check func(string) error
+		bool  bool
+	}
+
+	// declaredFlag wraps the flag.Value of a flag defined before BindFlags,
+	// so the config value follows the flag
+	declaredFlag struct {
+		flag.Value
+		n *Node
+	}
+)
+
+/*
+	Registers a flag on the flag set for each single line value of the
+	 config, named by its label path with '.' for ':' after the prefix, e.g.
+	 -db.host for db:host, and a value given on the command line replaces
+	 the config value when the flag set is parsed:
+
+		c, err := cfg.LoadConfig("app.cfg")
+		err = cfg.BindFlags(flag.CommandLine, c, "")
+		flag.Parse()
+		host, _ := c.Value("db:host")
+
+	The type of each flag is inferred from the config value, so a value of
+	 8080 only accepts integers and true / false (yes / no, on / off) are
+	 bool flags; a flag already defined on the set with the same name
+	 declares its own type instead, it's given the config value as its
+	 default and any error setting it is returned
+*/
+func BindFlags(fs *flag.FlagSet, c *Config, prefix string) error {
+	paths := make([]string, 0, len(c.nodes))
+	for p, n := range c.nodes {
+		if ConfigValue == n.Type && !strings.Contains(n.Data[0], "\n") {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		n := c.nodes[p]
+		name := prefix + strings.ReplaceAll(p, ":", ".")
+		if f := fs.Lookup(name); nil != f {
+			if err := f.Value.Set(n.Data[0]); nil != err {
+				return &PathError{p, err}
+			}
+			f.DefValue = f.Value.String()
+			f.Value = &declaredFlag{f.Value, n}
+			continue
+		}
+		fs.Var(c.inferFlag(n), name, "config value "+p)
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// inferFlag returns the flag.Value for the config value, checking new
+// values as an integer, float, duration, bool or any string
+func (c *Config) inferFlag(n *Node) *configFlag {
+	v := strings.TrimSpace(n.Data[0])
+	f := &configFlag{n: n}
+	isInt := func(s string) error {
+		_, err := parseInt(s, c.opts.DecimalOnly)
+		return err
+	}
+	isFloat := func(s string) error {
+		_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return err
+	}
+	isDuration := func(s string) error {
+		_, err := time.ParseDuration(strings.TrimSpace(s))
+		return err
+	}
+	switch {
+	case "" == v:
+	case nil == isInt(v):
+		f.check = isInt
+	case nil == isFloat(v) && strings.ContainsAny(v, "0123456789"):
+		f.check = isFloat
+	case nil == isDuration(v):
+		f.check = isDuration
+	default:
+		switch strings.ToLower(v) {
+		case "true", "false", "yes", "no", "on", "off":
+			f.check = func(s string) error {
+				_, err := ParseBool(s)
+				return err
+			}
+			f.bool = true
+		}
+	}
+	return f
+}
+
+func (f *configFlag) String() string {
+	if nil == f.n {
+		return ""
+	}
+	return f.n.Data[0]
+}
+
+func (f *configFlag) Set(s string) error {
+	if nil != f.check {
+		if err := f.check(s); nil != err {
+			return err
+		}
+	}
+	f.n.Data = []string{s}
+	return nil
+}
+
+func (f *configFlag) IsBoolFlag() bool {
+	return f.bool
+}
+
+func (f *declaredFlag) Set(s string) error {
+	if err := f.Value.Set(s); nil != err {
+		return err
+	}
+	f.n.Data = []string{f.Value.String()}
+	return nil
+}
+
+func (f *declaredFlag) IsBoolFlag() bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
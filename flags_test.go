@@ -0,0 +1,57 @@
+package cfg
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestBindFlags(t *testing.T) {
+	c, err := Parse("name := app\nport := 8080\ndebug := no\ndb (\n\thost := x\n\ttimeout := 5s\n\tratio := 0.5\n)\nverbose := false\n")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	verbose := fs.Bool("app.verbose", true, "declared")
+	if err := BindFlags(fs, c, "app."); nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if *verbose || nil == fs.Lookup("app.db.host") || "8080" != fs.Lookup("app.port").DefValue {
+		dbg.Error("BindFlags defaults: %v", *verbose)
+		t.Fail()
+	}
+	err = fs.Parse([]string{"-app.port=9090", "-app.debug", "-app.db.host", "y", "-app.db.timeout=1m", "-app.verbose"})
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"name": "app", "port": "9090", "debug": "true", "db:host": "y",
+		"db:timeout": "1m", "db:ratio": "0.5", "verbose": "true",
+	}
+	for k, want := range expect {
+		if got, _ := c.Value(k); want != got {
+			dbg.Error("BindFlags %s: %q", k, got)
+			t.Fail()
+		}
+	}
+
+	for _, args := range [][]string{{"-app.port=x"}, {"-app.db.ratio=fast"}, {"-app.db.timeout=5"}, {"-app.debug=maybe"}} {
+		if nil == fs.Parse(args) {
+			dbg.Error("BindFlags accepted %v", args)
+			t.Fail()
+		}
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("name", 0, "declared")
+	if err := BindFlags(fs, c, ""); nil == err {
+		dbg.Error("BindFlags declared int accepted %q", "app")
+		t.Fail()
+	}
+}
@@ -0,0 +1,280 @@
+package cfg
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrUnendedSection = errors.New("Missing end char for config data")
+
+	// any label (or other text, e.g. 'profile dev') ending with an opener
+	// 1: label  2: ,|:  3: opener  4: b64|hex|table||
+	formatSectionRex = regexp.MustCompile(`^(\S.*?)[ \t]*(,|:)?[ \t]*(<|\[|{|\()(b64|hex|table|\|)?[ \t]*$`)
+	// 1: label  2: ,  3: -items-  4: -values-
+	formatInlineRex = regexp.MustCompile(`^(\S+?)[ \t]*(,)?[ \t]*(?:{[ \t]*(.*?)[ \t]*}|\([ \t]*(.*?)[ \t]*\))[ \t]*$`)
+	// 1: key  2: value
+	formatPairRex = regexp.MustCompile(`^([^\s:]+)[ \t]*:[ \t]*(.*)$`)
+)
+
+/*
+	Returns the config data in the canonical style, the gofmt of config
+	 files: TAB indented groups, a single space around ':=' and before each
+	 opener, ', ' or ' ' separated items, trimmed lines and no trailing
+	 whitespace or repeated blank lines.  Comments, directives (@include,
+	 @if...) and the text of blocks & multi-line values are kept as they are,
+	 so the formatted data always parses to the same config
+*/
+func Format(src []byte) ([]byte, error) {
+	return Options{}.Format(src)
+}
+
+/*
+	As Format, using the comment prefixes, space indent and line
+	 continuation of the options; the blank lines of a lines section are
+	 kept with KeepBlankLines, as are the spaces of its escaped lines with
+	 SectionEscapes
+*/
+func (o Options) Format(src []byte) ([]byte, error) {
+	str := normalizeEOL(strings.TrimPrefix(string(src), "\ufeff"))
+	lines, err := o.format("", strings.Split(strings.TrimSuffix(str, "\n"), "\n"))
+	if nil != err || 0 == len(lines) {
+		return nil, err
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// format returns the formatted lines of a single level of config data, the
+// lines of a (data) container having had their indent removed
+func (o Options) format(lp string, lines []string) ([]string, error) {
+	result := []string{}
+	blank := false
+	emit := func(l ...string) {
+		if blank && 0 != len(result) {
+			result = append(result, "")
+		}
+		blank = false
+		result = append(result, l...)
+	}
+	// ends finds the line ending a section, as findConfigEnRex does
+	ends := func(label string, i int, closer string) (int, error) {
+		for j := i + 1; j < len(lines); j++ {
+			if 1 == len(lines[j]) && strings.Contains(">]})", lines[j]) {
+				if closer != lines[j] {
+					break
+				}
+				return j, nil
+			}
+		}
+		return 0, &PathError{joinPath(lp, label), ErrUnendedSection}
+	}
+	fencedAt := func(label string, i int, tag string) (int, error) {
+		for j := i + 1; j < len(lines); j++ {
+			if tag == strings.TrimRight(lines[j], " \t") {
+				return j, nil
+			}
+		}
+		return 0, &PathError{joinPath(lp, label), ErrUnendedSection}
+	}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		switch {
+		case "" == strings.TrimSpace(line):
+			blank = true
+			continue
+		case strings.HasPrefix(strings.TrimSpace(line), "#") || o.isComment(line):
+			emit(strings.TrimSpace(line))
+			continue
+		case ' ' == line[0] || '\t' == line[0]:
+			// not an entry, so keep any indent to keep it from becoming one
+			emit(line)
+			continue
+		}
+		if x := editValueRex.FindStringSubmatch(line); nil != x {
+			if strings.HasPrefix(x[2], "=") && "=" == strings.TrimSpace(x[2]) {
+				j, err := fencedAt(x[1], i, ":==")
+				if nil != err {
+					return nil, err
+				}
+				emit(x[1] + " :==")
+				emit(lines[i+1 : j]...)
+				emit(":==")
+				i = j
+				continue
+			}
+			if v := strings.TrimSpace(x[2]); "" != v {
+				emit(x[1] + " := " + v)
+			} else {
+				emit(x[1] + " :=")
+			}
+			for o.LineContinuation && strings.HasSuffix(lines[i], "\\") && i+1 < len(lines) {
+				i++
+				emit(lines[i])
+			}
+			continue
+		}
+		if x := editHeredocRex.FindStringSubmatch(line); nil != x {
+			j, err := fencedAt(x[1], i, x[2])
+			if nil != err {
+				return nil, err
+			}
+			emit(x[1] + " <<" + x[2])
+			emit(lines[i+1 : j]...)
+			emit(x[2])
+			i = j
+			continue
+		}
+		if x := formatInlineRex.FindStringSubmatch(line); nil != x && !strings.ContainsAny(x[1], "({") {
+			switch {
+			case strings.HasSuffix(line, ")") && "" == x[4]:
+				emit(x[1] + " ( )")
+			case strings.HasSuffix(line, ")"):
+				emit(x[1] + " ( " + x[4] + " )")
+			case "" != x[2]:
+				emit(x[1] + " , { " + strings.Join(splitItems(x[3], ","), ", ") + " }")
+			case strings.Contains(x[3], ","):
+				emit(x[1] + " { " + strings.Join(splitItems(x[3], ","), ", ") + " }")
+			default:
+				emit(x[1] + " { " + strings.Join(strings.Fields(x[3]), " ") + " }")
+			}
+			continue
+		}
+		if cs, sec := o.syntax().findSection(line + "\n"); nil != cs && 0 == cs[0] {
+			label := line[cs[2]:cs[3]]
+			j, err := fencedAt(label, i, sec.close)
+			if nil != err {
+				return nil, err
+			}
+			emit(label + " " + sec.open)
+			emit(lines[i+1 : j]...)
+			emit(sec.close)
+			i = j
+			continue
+		}
+		x := formatSectionRex.FindStringSubmatch(line)
+		if nil == x {
+			emit(line)
+			continue
+		}
+		label, closer := strings.Join(strings.Fields(x[1]), " "), matching[x[3]]
+		j, err := ends(label, i, closer)
+		if nil != err {
+			return nil, err
+		}
+		body := lines[i+1 : j]
+		open := label + " " + x[3] + x[4]
+		if "" != x[2] {
+			open = label + " " + x[2] + " " + x[3] + x[4]
+		}
+		switch x[3] {
+		case "(":
+			text, err := o.removeLeadingTabs(strings.Join(body, "\n") + "\n")
+			if nil == err && "" == strings.TrimSpace(text) {
+				text = ""
+			}
+			if nil == err {
+				body, err = o.format(joinPath(lp, label), strings.Split(strings.TrimSuffix(text, "\n"), "\n"))
+			}
+			if nil != err {
+				if _, ok := err.(*PathError); !ok {
+					err = &PathError{joinPath(lp, label), err}
+				}
+				return nil, err
+			}
+			body = indentLines(body)
+		case "<":
+			// block text is kept as it is
+		case "[":
+			body = o.formatList(body, ":" == x[2])
+		default:
+			body = formatItems(body, x[2])
+		}
+		if 0 == len(body) {
+			// a section needs a line between its opener & closer
+			body = []string{""}
+		}
+		emit(open)
+		emit(body...)
+		emit(closer)
+		i = j
+	}
+	return result, nil
+}
+
+// formatList trims the lines of a lines, table or dict section, giving
+// each dict entry a single ' : ' between key & value; blank lines are kept
+// with Options.KeepBlankLines and escaped lines keep their trailing spaces
+// with Options.SectionEscapes
+func (o Options) formatList(lines []string, dict bool) []string {
+	result := []string{}
+	for _, l := range lines {
+		if _, ok := o.escapedLine(l); ok {
+			result = append(result, "\t"+strings.TrimLeft(l, " \t"))
+			continue
+		}
+		if l = strings.TrimSpace(l); "" == l {
+			if o.KeepBlankLines {
+				result = append(result, "")
+			}
+			continue
+		}
+		if x := formatPairRex.FindStringSubmatch(l); dict && nil != x && !strings.HasPrefix(l, "#") {
+			l = x[1] + " : " + x[2]
+		}
+		result = append(result, "\t"+l)
+	}
+	return result
+}
+
+// formatItems rewrites the lines of an items section with single separators,
+// keeping any (sub-lists) and {sub-lists} as they are divided
+func formatItems(lines []string, sep string) []string {
+	result := []string{}
+	indent := "\t"
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		sub := strings.HasPrefix(l, "(") && strings.HasSuffix(l, ")")
+		if sub {
+			l = l[1 : len(l)-1]
+		}
+		switch {
+		case "" == l && !sub, strings.HasPrefix(l, "#"):
+			if "" != l {
+				result = append(result, indent+l)
+			}
+			continue
+		case "{" == l:
+			result = append(result, indent+l)
+			indent = "\t\t"
+			continue
+		case "}" == l:
+			indent = "\t"
+			result = append(result, indent+l)
+			continue
+		}
+		if "," == sep {
+			l = strings.Join(splitItems(l, ","), ", ")
+		} else {
+			l = strings.Join(strings.Fields(l), " ")
+		}
+		if sub {
+			l = "(" + l + ")"
+		}
+		result = append(result, indent+l)
+	}
+	return result
+}
+
+// splitItems splits the text into trimmed, non-empty items
+func splitItems(s, sep string) []string {
+	result := []string{}
+	for _, i := range strings.Split(s, sep) {
+		if i = strings.TrimSpace(i); "" != i {
+			result = append(result, i)
+		}
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	formatSrc = "\r\n" +
+		"# comment   \r\n" +
+		"name:=value  \r\n" +
+		"empty   :=\r\n" +
+		"\r\n" +
+		"\r\n" +
+		"multi:==\r\n" +
+		"\t  kept  \r\n" +
+		":==\r\n" +
+		"blk<\r\n" +
+		"  kept text  \r\n" +
+		">\r\n" +
+		"lst[\r\n" +
+		"   one   \r\n" +
+		"two\r\n" +
+		"]\r\n" +
+		"its,{\r\n" +
+		"a ,b,   c d\r\n" +
+		"}\r\n" +
+		"sp {\r\n" +
+		"  x    y\r\n" +
+		"(p   q)\r\n" +
+		"}\r\n" +
+		"d:[\r\n" +
+		"k:v\r\n" +
+		"  key   :  some value\r\n" +
+		"]\r\n" +
+		"inl{a,b}\r\n" +
+		"pt (x := 1  y := 2)\r\n" +
+		"grp(\r\n" +
+		"    nested   := 1\r\n" +
+		"    sub (\r\n" +
+		"        deep := 2\r\n" +
+		"        doc <<END\r\n" +
+		"          as is\r\n" +
+		"        END\r\n" +
+		"    )\r\n" +
+		"\r\n" +
+		")\r\n" +
+		"\r\n"
+
+	formatWant = `# comment
+name := value
+empty :=
+
+multi :==
+	  kept  
+:==
+blk <
+  kept text  
+>
+lst [
+	one
+	two
+]
+its , {
+	a, b, c d
+}
+sp {
+	x y
+	(p q)
+}
+d : [
+	k : v
+	key : some value
+]
+inl { a, b }
+pt ( x := 1  y := 2 )
+grp (
+	nested := 1
+	sub (
+		deep := 2
+		doc <<END
+		  as is
+		END
+	)
+)
+`
+)
+
+func TestFormat(t *testing.T) {
+	out, err := Format([]byte(formatSrc))
+	if nil != err {
+		dbg.Error("Format: %v", err)
+		t.FailNow()
+	}
+	if formatWant != string(out) {
+		dbg.Error("Format gave:\n%s", out)
+		t.Fail()
+	}
+	src, err := ioutil.ReadFile("testdata/testBlocks.cfg")
+	dbg.ChkErr(err, "ReadFile")
+	for _, data := range []string{formatSrc, string(src)} {
+		out, err := Format([]byte(data))
+		if nil != err {
+			dbg.Error("Format: %v", err)
+			t.Fail()
+			continue
+		}
+		again, _ := Format(out)
+		if string(out) != string(again) {
+			dbg.Error("Format is not stable:\n%s", again)
+			t.Fail()
+		}
+		before, err1 := Parse(data)
+		after, err2 := Parse(string(out))
+		if nil != err1 || nil != err2 || !reflect.DeepEqual(flatNodes(before), flatNodes(after)) {
+			dbg.Error("Format changed the config: %v %v", err1, err2)
+			t.Fail()
+		}
+	}
+	if _, err := Format([]byte("open (\n\tx := 1\n")); nil == err {
+		dbg.Error("Format of an unended group didn't fail")
+		t.Fail()
+	}
+}
+
+// flatNodes lists the type, path & data of each node of the config
+func flatNodes(c *Config) []interface{} {
+	result := []interface{}{}
+	c.flatten(&c.root, func(n *Node) {
+		result = append(result, n.Type, n.Path, n.Data)
+	})
+	return result
+}
+
+func TestFormatEqual(t *testing.T) {
+	for _, tc := range []struct {
+		opts Options
+		src  string
+	}{
+		{Options{}, "g (\n\n)\nx := 1\n"},
+		{Options{}, "l [\n\n]\nt [table\n\n]\nd : [\n\n]\ni {\n\n}\nx := 1\n"},
+		{Options{}, "a ()\nb ( )\nc {}\nx := 1\n"},
+		{Options{}, "g (\n\tl [\n\n\t]\n\th (\n\n\t)\n)\nx := 1\n"},
+		{Options{KeepBlankLines: true}, "l [\n\ta\n\n\tb\n\n]\nx := 1\n"},
+		{Options{SectionEscapes: true}, "l [\n\t\\  padded  \n\t\\\n\ta\n]\nx := 1\n"},
+	} {
+		out, err := tc.opts.Format([]byte(tc.src))
+		if nil != err {
+			dbg.Error("Format %q: %v", tc.src, err)
+			t.Fail()
+			continue
+		}
+		before, err1 := tc.opts.Parse(tc.src)
+		after, err2 := tc.opts.Parse(string(out))
+		if nil != err1 || nil != err2 || !Equal(before, after) {
+			dbg.Error("Format changed %q: %v %v\n%s", tc.src, err1, err2, out)
+			t.Fail()
+		}
+	}
+}
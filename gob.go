@@ -0,0 +1,107 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+type (
+	// the gob form of a Config: its tree of nodes and the options used by
+	// the accessors & WriteTo; functions such as Options.Include are lost
+	gobConfig struct {
+		Root   gobNode
+		Source string
+
+		QuotedValues, NoEscapes, Interpolate, LineContinuation bool
+		DecimalOnly, UnicodeLabels, QuotedItems                bool
+		SectionEscapes, Secrets                                bool
+		LabelChars                                             string
+		Sensitive                                              []string
+	}
+
+	gobNode struct {
+		Type     int // not a ConfigType, which gob would encode as its name
+		Label    string
+		Path     string
+		Data     []string
+		Children []gobNode
+		Attrs    map[string]string
+	}
+)
+
+/*
+	Encodes the parsed config with encoding/gob, so it can be cached or sent
+	 to another process and used without parsing the config data again
+*/
+func (c *Config) GobEncode() ([]byte, error) {
+	g := gobConfig{
+		Root:             toGob(&c.root),
+		Source:           c.source,
+		QuotedValues:     c.opts.QuotedValues,
+		QuotedItems:      c.opts.QuotedItems,
+		NoEscapes:        c.opts.NoEscapes,
+		Interpolate:      c.opts.Interpolate,
+		LineContinuation: c.opts.LineContinuation,
+		DecimalOnly:      c.opts.DecimalOnly,
+		UnicodeLabels:    c.opts.UnicodeLabels,
+		SectionEscapes:   c.opts.SectionEscapes,
+		Secrets:          c.opts.Secrets,
+		LabelChars:       c.opts.LabelChars,
+		Sensitive:        c.opts.Sensitive,
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&g); nil != err {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+	Decodes a config encoded by GobEncode, replacing the contents of c
+*/
+func (c *Config) GobDecode(data []byte) error {
+	var g gobConfig
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&g); nil != err {
+		return err
+	}
+	*c = *newConfig()
+	c.source = g.Source
+	c.opts = Options{
+		QuotedValues:     g.QuotedValues,
+		QuotedItems:      g.QuotedItems,
+		NoEscapes:        g.NoEscapes,
+		Interpolate:      g.Interpolate,
+		LineContinuation: g.LineContinuation,
+		DecimalOnly:      g.DecimalOnly,
+		UnicodeLabels:    g.UnicodeLabels,
+		SectionEscapes:   g.SectionEscapes,
+		Secrets:          g.Secrets,
+		LabelChars:       g.LabelChars,
+		Sensitive:        g.Sensitive,
+	}
+	c.fromGob(&c.root, g.Root.Children)
+	return nil
+}
+
+// ------------------------------------------------------------------------- //
+
+func toGob(n *Node) gobNode {
+	g := gobNode{int(n.Type), n.Label, n.Path, n.Data, nil, n.Attrs}
+	for _, ch := range n.Children {
+		g.Children = append(g.Children, toGob(ch))
+	}
+	return g
+}
+
+// fromGob rebuilds the children of the parent node, indexing them as the
+// parser does
+func (c *Config) fromGob(parent *Node, children []gobNode) {
+	for _, g := range children {
+		n := &Node{Type: ConfigType(g.Type), Label: g.Label, Path: g.Path, Data: g.Data, Attrs: g.Attrs, parent: parent}
+		parent.Children = append(parent.Children, n)
+		if ConfigComment != n.Type {
+			c.nodes[n.Path] = n
+		}
+		c.fromGob(n, g.Children)
+	}
+}
@@ -0,0 +1,41 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestGob(t *testing.T) {
+	c, err := Options{KeepComments: true, Sensitive: []string{"*:password"}}.Parse(
+		"# app\nname := app\ndb (\n\tpassword := secret\n\tports {\n\t\t80 443\n\t}\n)\nserver (\n\thost := a\n)\nserver (\n\thost := b\n)\n")
+	dbg.ChkErr(err, "Parse")
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c); nil != err {
+		dbg.Error("gob Encode: %v", err)
+		t.FailNow()
+	}
+	var d Config
+	if err := gob.NewDecoder(&buf).Decode(&d); nil != err {
+		dbg.Error("gob Decode: %v", err)
+		t.FailNow()
+	}
+	if c.String() != d.String() {
+		dbg.Error("gob round trip gave:\n%s", d.String())
+		t.Fail()
+	}
+	if v, _ := d.Value("server[0]:host"); "a" != v {
+		dbg.Error("gob server[0]:host: %q", v)
+		t.Fail()
+	}
+	if v, _ := d.Value("db:password"); "secret" != v {
+		dbg.Error("gob db:password: %q", v)
+		t.Fail()
+	}
+	if n := d.Lookup("db:ports"); nil == n || nil == n.parent || "db" != n.parent.Path {
+		dbg.Error("gob didn't link the nodes: %v", n)
+		t.Fail()
+	}
+}
@@ -0,0 +1,148 @@
+package cfg
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	/*
+		Functions called as config data is parsed, for tracing, e.g. to
+		 log or time the sections of a large config; any may be nil
+
+		OnSectionStart and OnSectionEnd are called for each (data)
+		 container, the end once its last entry is parsed, and for every
+		 other section (blocks, lines, items, dicts, tables etc) as it's
+		 parsed.  Paths use Options.PathSeparator
+	*/
+	Hooks struct {
+		OnSectionStart func(path string, t ConfigType)
+		OnSectionEnd   func(path string, t ConfigType)
+		OnValue        func(path, value string) // each label := value
+		OnError        func(err error)          // a parse failing, or with Options.Recover sections skipped
+	}
+
+	/*
+		Counts of the configs parsed with these Metrics set in the Options,
+		 safe for any number of goroutines.  A *Metrics is an expvar.Var,
+		 published as is by
+
+			expvar.Publish("cfg", m)
+
+		and Snapshot gives the counts for other collectors, e.g. a
+		 Prometheus CounterFunc returning float64(m.Snapshot().Sections)
+	*/
+	Metrics struct {
+		parses, errors, sections, values, bytes, nanos, last int64
+	}
+
+	/*
+		The counts of a Metrics at one time
+	*/
+	MetricsSnapshot struct {
+		Parses       int64         // config data parsed, by Parse, Load* & Handle* each file or text
+		Errors       int64         // parses that failed, or skipped sections with Options.Recover
+		Sections     int64         // the sections and (data) containers parsed
+		Values       int64         // the label := value entries parsed
+		Bytes        int64         // the bytes of config text parsed, once includes are expanded
+		Duration     time.Duration // the time spent parsing, in total
+		LastDuration time.Duration // the time the last parse took
+	}
+)
+
+/*
+	Returns the current counts
+*/
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	return MetricsSnapshot{
+		Parses:       atomic.LoadInt64(&m.parses),
+		Errors:       atomic.LoadInt64(&m.errors),
+		Sections:     atomic.LoadInt64(&m.sections),
+		Values:       atomic.LoadInt64(&m.values),
+		Bytes:        atomic.LoadInt64(&m.bytes),
+		Duration:     time.Duration(atomic.LoadInt64(&m.nanos)),
+		LastDuration: time.Duration(atomic.LoadInt64(&m.last)),
+	}
+}
+
+/*
+	Returns the counts as a JSON object, for expvar
+*/
+func (m *Metrics) String() string {
+	b, _ := json.Marshal(m.Snapshot())
+	return string(b)
+}
+
+// ------------------------------------------------------------------------- //
+
+// trace wraps the handler of the entries of parsed config data to call the
+// hooks and count the metrics, returning it with the function the error
+// of the parse is passed through once it's done
+func (o Options) trace(str string, f func(t ConfigType, label string, data []string)) (func(t ConfigType, label string, data []string), func(err error) error) {
+	h, m := o.Hooks, o.Metrics
+	if nil == h && nil == m {
+		return f, func(err error) error { return err }
+	}
+	if nil == h {
+		h = &Hooks{}
+	}
+	start, groups := time.Now(), []string{}
+	var sections, values int64
+	end := func(depth int) {
+		for len(groups) > depth {
+			if nil != h.OnSectionEnd {
+				h.OnSectionEnd(o.externalPath(groups[len(groups)-1]), ConfigGroup)
+			}
+			groups = groups[:len(groups)-1]
+		}
+	}
+	traced := func(t ConfigType, label string, data []string) {
+		depth := len(groups)
+		for depth > 0 && !strings.HasPrefix(label, groups[depth-1]+":") {
+			depth--
+		}
+		end(depth)
+		path := o.externalPath(label)
+		switch t {
+		case ConfigComment, configAttributes:
+		case ConfigValue:
+			values++
+			if nil != h.OnValue {
+				h.OnValue(path, data[0])
+			}
+		default:
+			sections++
+			if nil != h.OnSectionStart {
+				h.OnSectionStart(path, t)
+			}
+			if ConfigGroup == t {
+				groups = append(groups, label)
+			} else if nil != h.OnSectionEnd {
+				h.OnSectionEnd(path, t)
+			}
+		}
+		f(t, label, data)
+	}
+	done := func(err error) error {
+		end(0)
+		if nil != err && nil != h.OnError {
+			h.OnError(err)
+		}
+		if nil != m {
+			d := int64(time.Since(start))
+			atomic.AddInt64(&m.parses, 1)
+			atomic.AddInt64(&m.sections, sections)
+			atomic.AddInt64(&m.values, values)
+			atomic.AddInt64(&m.bytes, int64(len(str)))
+			atomic.AddInt64(&m.nanos, d)
+			atomic.StoreInt64(&m.last, d)
+			if nil != err {
+				atomic.AddInt64(&m.errors, 1)
+			}
+		}
+		return err
+	}
+	return traced, done
+}
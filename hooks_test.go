@@ -0,0 +1,65 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestHooks(t *testing.T) {
+	const data = "name := app\ndb (\n\thost := x\n\topts (\n\t\tssl := on\n\t)\n\ttags { a b }\n)\nserver (\n\tport := 1\n)\nserver (\n\tport := 2\n)\nnotes <\ntext\n>\n"
+	events := []string{}
+	h := &Hooks{
+		OnSectionStart: func(path string, t ConfigType) { events = append(events, "+"+path) },
+		OnSectionEnd:   func(path string, t ConfigType) { events = append(events, "-"+path) },
+		OnValue:        func(path, value string) { events = append(events, path+"="+value) },
+		OnError:        func(err error) { events = append(events, "!"+err.Error()) },
+	}
+	want := "name=app +db db:host=x +db:opts db:opts:ssl=on -db:opts +db:tags -db:tags -db +server server:port=1 -server " +
+		"+server server:port=2 -server +notes -notes"
+	m := &Metrics{}
+	for _, o := range []Options{{Hooks: h, Metrics: m}, {Hooks: h, Metrics: m, ParallelSections: true}} {
+		events = events[:0]
+		if _, err := o.Parse(data); nil != err || want != strings.Join(events, " ") {
+			dbg.Error("Parse hooks: %v\n%s", err, strings.Join(events, " "))
+			t.Fail()
+		}
+	}
+	events = events[:0]
+	(Options{Hooks: h, Metrics: m, PathSeparator: '.'}).HandleConfigData(data, func(t ConfigType, label string, data []string) {})
+	if strings.Replace(want, ":", ".", -1) != strings.Join(events, " ") {
+		dbg.Error("HandleConfigData hooks: %s", strings.Join(events, " "))
+		t.Fail()
+	}
+	events = events[:0]
+	const bad = "g (\nx := 1\n)\n"
+	if _, err := (Options{Hooks: h, Metrics: m}).Parse(bad); nil == err || !strings.HasPrefix(events[len(events)-1], "!") {
+		dbg.Error("error hook: %v %q", err, events)
+		t.Fail()
+	}
+
+	s := m.Snapshot()
+	if 4 != s.Parses || 1 != s.Errors || 3*6 != s.Sections || 3*5 != s.Values || 3*int64(len(data))+int64(len(bad)) != s.Bytes ||
+		s.Duration <= 0 || s.LastDuration <= 0 || s.LastDuration > s.Duration {
+		dbg.Error("metrics: %+v", s)
+		t.Fail()
+	}
+	var got MetricsSnapshot
+	if err := json.Unmarshal([]byte(m.String()), &got); nil != err || s != got {
+		dbg.Error("metrics JSON: %v %s", err, m)
+		t.Fail()
+	}
+	var v expvar.Var = m
+	if _, err := (Options{Metrics: m}).Parse("x := 1\n"); nil != err || !strings.Contains(v.String(), `"Parses":5`) {
+		dbg.Error("expvar: %v %s", err, v)
+		t.Fail()
+	}
+	if err := (Options{}).HandleConfigData(bad, func(t ConfigType, label string, data []string) {}); !errors.Is(err, ErrIllegalDataBlock) {
+		dbg.Error("no hooks: %v", err)
+		t.Fail()
+	}
+}
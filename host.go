@@ -0,0 +1,93 @@
+package cfg
+
+import (
+	"errors"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrHostEnd = errors.New("Missing ')' ending @host group")
+
+	// @host pattern, ... (
+	// 1: TABs  2: patterns
+	hostRex = regexp.MustCompile(`^(\t*)@host[ \t]+([^\s(][^(]*?)[ \t]*\([ \t]*$`)
+)
+
+/*
+	Reports whether the hostname matches any of the patterns, each a
+	 shell pattern as used by path.Match, e.g. "web-*" or "db-[0-9]*";
+	 the match ignores case, as hostnames do
+*/
+func MatchHost(hostname string, patterns ...string) bool {
+	hostname = strings.ToLower(hostname)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), hostname); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ------------------------------------------------------------------------- //
+
+// hostname returns the name of the machine, see Options.Hostname
+func (o Options) hostname() string {
+	if nil != o.Hostname {
+		return o.Hostname()
+	}
+	name, _ := os.Hostname()
+	return name
+}
+
+/*
+	hostGroups replaces each @host group with its lines, one TAB removed,
+	 when the machine's hostname matches one of its patterns, removing the
+	 group otherwise:
+
+		@host web-*, api-* (
+			workers := 16
+		)
+
+	so a config shared by a fleet can hold the settings of each role.  A
+	 group may be TAB indented inside a (data) container, its lines then
+	 joining the container, and groups may be nested
+*/
+func (o Options) hostGroups(str string) (string, error) {
+	if !strings.Contains(str, "@host") {
+		return str, nil
+	}
+	host := ""
+	lines := strings.Split(str, "\n")
+	for i := 0; i < len(lines); i++ {
+		x := hostRex.FindStringSubmatch(lines[i])
+		if nil == x {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && x[1]+")" != strings.TrimRight(lines[end], " \t") {
+			end++
+		}
+		if end == len(lines) {
+			return "", &LineError{i + 1, strings.TrimSpace(lines[i]), ErrHostEnd}
+		}
+		if "" == host {
+			host = o.hostname()
+		}
+		kept := []string{}
+		if MatchHost(host, strings.FieldsFunc(x[2], func(r rune) bool { return ',' == r || ' ' == r || '\t' == r })...) {
+			for _, l := range lines[i+1 : end] {
+				if strings.HasPrefix(l, x[1]+"\t") {
+					l = x[1] + l[len(x[1])+1:]
+				}
+				kept = append(kept, l)
+			}
+		}
+		lines = append(lines[:i], append(kept, lines[end+1:]...)...)
+		// nested groups are now at this level
+		i--
+	}
+	return strings.Join(lines, "\n"), nil
+}
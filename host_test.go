@@ -0,0 +1,68 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestHostGroups(t *testing.T) {
+	src := `workers := 4
+role := none
+@host web-*, API-* (
+	workers := 16
+	role := front
+	@host *.eu.* (
+		region := eu
+	)
+)
+@host db-[0-9]* (
+	role := db
+)
+server (
+	port := 80
+	@host web-02* (
+		port := 8080
+	)
+)
+@if hostname == "web-02.eu.example.com"
+named := yes
+@end
+`
+	for _, tc := range []struct {
+		host, workers, role, region, port, named string
+	}{
+		{"web-02.eu.example.com", "16", "front", "eu", "8080", "yes"},
+		{"API-01.us", "16", "front", "", "80", ""},
+		{"db-3", "4", "db", "", "80", ""},
+		{"dbx", "4", "none", "", "80", ""},
+	} {
+		host := tc.host
+		c, err := Options{Hostname: func() string { return host }}.Parse(src)
+		if nil != err {
+			dbg.Error("@host %s: %v", host, err)
+			t.Fail()
+			continue
+		}
+		if tc.workers != c.ValueOr("workers", "") || tc.role != c.ValueOr("role", "") || tc.region != c.ValueOr("region", "") ||
+			tc.port != c.ValueOr("server:port", "") || tc.named != c.ValueOr("named", "") {
+			dbg.Error("@host %s gave:\n%s", host, c.String())
+			t.Fail()
+		}
+	}
+
+	if !MatchHost("Web-01", "db-*", "web-0?") || MatchHost("web-01", "web") {
+		dbg.Error("MatchHost")
+		t.Fail()
+	}
+	var ce *LineError
+	if _, err := Parse("@host web-* (\n\ta := 1\n"); !errors.As(err, &ce) || ErrHostEnd != ce.Err || 1 != ce.Line {
+		dbg.Error("@host without an end: %v", err)
+		t.Fail()
+	}
+	if f := Lint([]byte(src)); 0 != len(f) {
+		dbg.Error("Lint of @host groups: %v", f)
+		t.Fail()
+	}
+}
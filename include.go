@@ -0,0 +1,215 @@
+package cfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type (
+	/*
+		An IncludeFunc returns the contents of the config named by an
+		 '@include path' line; from is the name of the including config,
+		 "" for data that didn't come from a file.  The returned name is
+		 used as 'from' for any includes inside the returned data.
+	*/
+	IncludeFunc func(from, path string) (name, data string, err error)
+
+	/*
+		An IncludeError records a failed include along with the chain of
+		 configs that led to it, outermost first
+	*/
+	IncludeError struct {
+		Chain []string
+		Path  string // the path given to @include
+		Err   error
+	}
+)
+
+const (
+	DefaultMaxIncludeDepth = 16
+)
+
+var (
+	ErrIncludeCycle = errors.New("Circular config include")
+	ErrIncludeDepth = errors.New("Config includes nested too deeply")
+	ErrIncludeEnv   = errors.New("Environment variable of config include not set")
+
+	// 1: leading TABs  2: -optional  3: path
+	includeRex = regexp.MustCompile(`(?m)^(\t*)@include(-optional)?[ \t]+(.*?)[ \t]*$`)
+)
+
+func (e *IncludeError) Error() string {
+	return "@include " + e.Path + " (" + strings.Join(e.Chain, " -> ") + "): " + e.Err.Error()
+}
+
+func (e *IncludeError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	The IncludeFunc used by the Load* functions; the path is read as a file,
+	 a relative path is relative to the directory of the including file
+
+	Whatever the IncludeFunc, an include path containing any of the glob
+	 characters *?[ (see filepath.Match) is first matched against the file
+	 system, e.g. '@include conf.d/*.cfg', and each match is included in
+	 sorted order; no matches includes nothing
+
+	An '@include env:NAME' line includes the file the environment variable
+	 names, so an operator can add a config fragment without touching the
+	 config, its being unset (or empty) an *IncludeError wrapping
+	 ErrIncludeEnv.  '@include-optional path' (or env:NAME) includes
+	 nothing when the file or variable is missing, any other failure is
+	 still an error
+*/
+func FileInclude(from, path string) (string, string, error) {
+	return Options{}.FileInclude(from, path)
+}
+
+/*
+	As FileInclude, reading no more than Options.MaxSize bytes of the file
+	 (or of its decompressed data)
+*/
+func (o Options) FileInclude(from, path string) (string, string, error) {
+	if !filepath.IsAbs(path) && "" != from {
+		path = filepath.Join(filepath.Dir(from), path)
+	}
+	data, err := readText(path, o.MaxSize)
+	if nil != err {
+		return "", "", err
+	}
+	return path, data, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// include resolves and expands a single include path, a path containing
+// any of the glob characters *?[ is matched against the file system, with
+// each of the matches included in sorted order; an env:NAME path includes
+// the file the environment variable names, and when optional is set a
+// missing file (or an unset variable) includes nothing
+func (o Options) include(chain []string, path string, optional bool) (string, error) {
+	name := ""
+	if 0 != len(chain) {
+		name = chain[len(chain)-1]
+	}
+	if strings.HasPrefix(path, "env:") {
+		v := os.Getenv(path[len("env:"):])
+		if "" == v && optional {
+			return "", nil
+		}
+		if "" == v {
+			return "", &IncludeError{chain, path, ErrIncludeEnv}
+		}
+		path = v
+	}
+	paths := []string{path}
+	if strings.ContainsAny(path, "*?[") {
+		dir, pattern := filepath.Dir(name), path
+		if !filepath.IsAbs(pattern) && "" != name {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if nil != err {
+			return "", &IncludeError{chain, path, err}
+		}
+		if nil != o.seen {
+			o.seen(filepath.Dir(pattern), "")
+		}
+		sort.Strings(matches)
+		paths = paths[:0]
+		for _, m := range matches {
+			if !filepath.IsAbs(path) && "" != name {
+				m, _ = filepath.Rel(dir, m)
+			}
+			paths = append(paths, m)
+		}
+	}
+	result := []string{}
+	max := o.MaxIncludeDepth
+	if 0 == max {
+		max = DefaultMaxIncludeDepth
+	}
+	for _, p := range paths {
+		incName, data, err := o.Include(name, p)
+		if nil != err && optional && (errors.Is(err, os.ErrNotExist) || errors.Is(err, ErrNotInArchive)) {
+			continue
+		}
+		if nil != err {
+			return "", &IncludeError{chain, p, err}
+		}
+		if o.MaxSize > 0 && int64(len(data)) > o.MaxSize {
+			return "", &IncludeError{chain, p, &TooLargeError{incName, o.MaxSize}}
+		}
+		if nil != o.seen {
+			o.seen(incName, data)
+		}
+		for _, c := range chain {
+			if filepath.Clean(c) == filepath.Clean(incName) {
+				return "", &IncludeError{append(chain[:len(chain):len(chain)], incName), p, ErrIncludeCycle}
+			}
+		}
+		if len(chain) >= max {
+			return "", &IncludeError{append(chain[:len(chain):len(chain)], incName), p, ErrIncludeDepth}
+		}
+		data, err = o.expand(append(chain[:len(chain):len(chain)], incName), data)
+		if nil != err {
+			return "", err
+		}
+		result = append(result, strings.TrimSuffix(data, "\n"))
+	}
+	return strings.Join(result, "\n"), nil
+}
+
+// preprocess expands the config data to be parsed, as expand and then
+// replacing any @ref lines; see references
+func (o Options) preprocess(chain []string, str string) (string, error) {
+	str, err := o.expand(chain, str)
+	if nil != err {
+		return "", err
+	}
+	return o.references(str)
+}
+
+// expand first removes any byte order mark, normalizes the line endings and
+// removes any @if sections whose conditions are not met (and @host groups
+// for other machines), then replaces each '@include path' (or
+// '@include-optional path') line with the resolved contents; an include
+// inside a (data) container has each of the included lines given the same
+// leading TABs as the @include line.  The chain holds the names of the
+// configs being expanded, outermost first
+func (o Options) expand(chain []string, str string) (string, error) {
+	str, err := o.conditionals(normalizeEOL(strings.TrimPrefix(str, "\ufeff")))
+	if nil == err {
+		str, err = o.hostGroups(str)
+	}
+	if nil != err || nil == o.Include || !strings.Contains(str, "@include") {
+		return str, err
+	}
+	result := includeRex.ReplaceAllStringFunc(str, func(line string) string {
+		if nil != err {
+			return line
+		}
+		x := includeRex.FindStringSubmatch(line)
+		var data string
+		data, err = o.include(chain, x[3], "" != x[2])
+		if nil != err || "" == x[1] {
+			return data
+		}
+		lines := strings.Split(data, "\n")
+		for i, l := range lines {
+			if "" != l {
+				lines[i] = x[1] + l
+			}
+		}
+		return strings.Join(lines, "\n")
+	})
+	if nil != err {
+		return "", err
+	}
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package cfg
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/pth"
+)
+
+func TestInclude(t *testing.T) {
+	c, err := LoadConfig(pth.AsRealPath("$/testdata/includeMain.cfg"))
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"name":        "main",
+		"host":        "db.example.com",
+		"port":        "5432",
+		"group:inner": "value",
+	}
+	for k, want := range expect {
+		if v, _ := c.Value(k); v != want {
+			dbg.Error("%s: %q", k, v)
+			t.Fail()
+		}
+	}
+	if l, _ := c.GetStringList("group:items"); !compareEntries(l, []string{"a", "b", "c"}) {
+		dbg.Error("group:items: %v", l)
+		t.Fail()
+	}
+
+	// Parse only handles includes when given a resolver
+	o := Options{Include: func(from, path string) (string, string, error) {
+		if "extra" != path {
+			return "", "", os.ErrNotExist
+		}
+		return path, "extra := yes", nil
+	}}
+	c, err = o.Parse("@include extra\n")
+	if v, _ := c.Value("extra"); nil != err || v != "yes" {
+		dbg.Error("extra: %q %v", v, err)
+		t.Fail()
+	}
+	if _, err = o.Parse("@include missing\n"); !errors.Is(err, os.ErrNotExist) {
+		dbg.Error("missing: %v", err)
+		t.Fail()
+	}
+	if c, _ = Parse("@include extra\n"); 0 != len(c.Nodes()) {
+		dbg.Error("Include without resolver")
+		t.Fail()
+	}
+}
+
+func TestGlobInclude(t *testing.T) {
+	c, err := LoadConfig(pth.AsRealPath("$/testdata/include/glob.cfg"))
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if v, _ := c.Value("a"); v != "first" {
+		dbg.Error("a: %q", v)
+		t.Fail()
+	}
+	// later files override earlier ones
+	if v, _ := c.Value("order"); v != "b" {
+		dbg.Error("order: %q", v)
+		t.Fail()
+	}
+	if _, ok := c.Value("ignored"); ok {
+		dbg.Error("Non matching file was included")
+		t.Fail()
+	}
+}
+
+func TestIncludeCycles(t *testing.T) {
+	files := map[string]string{
+		"a":    "@include b\n",
+		"b":    "\t@include c\n",
+		"c":    "@include a\n",
+		"deep": "@include deep2\n",
+	}
+	o := Options{Include: func(from, path string) (string, string, error) {
+		if "deep2" == path {
+			// a new name every time, never a cycle
+			return from + "+", "@include deep2\n", nil
+		}
+		return path, files[path], nil
+	}}
+	var ie *IncludeError
+	_, err := o.Parse("@include a\n")
+	if !errors.As(err, &ie) || !errors.Is(err, ErrIncludeCycle) || !compareEntries(ie.Chain, []string{"a", "b", "c", "a"}) {
+		dbg.Error("cycle: %v", err)
+		t.Fail()
+	}
+	o.MaxIncludeDepth = 4
+	_, err = o.Parse("@include deep\n")
+	if !errors.As(err, &ie) || !errors.Is(err, ErrIncludeDepth) || 5 != len(ie.Chain) {
+		dbg.Error("depth: %v", err)
+		t.Fail()
+	}
+}
+
+func TestEnvInclude(t *testing.T) {
+	db := pth.AsRealPath("$/testdata/include/db.cfg")
+	os.Setenv("CFG_TEST_EXTRA", db)
+	os.Unsetenv("CFG_TEST_NONE")
+	defer os.Unsetenv("CFG_TEST_EXTRA")
+	o := Options{Include: FileInclude}
+
+	c, err := o.Parse("name := x\ngroup (\n\t@include env:CFG_TEST_EXTRA\n)\n@include-optional env:CFG_TEST_NONE\n@include-optional /no/such/file.cfg\n")
+	if v, _ := c.Value("group:host"); nil != err || "db.example.com" != v {
+		dbg.Error("env include: %v %q", err, v)
+		t.Fail()
+	}
+	var ie *IncludeError
+	if _, err := o.Parse("@include env:CFG_TEST_NONE\n"); !errors.As(err, &ie) || !errors.Is(err, ErrIncludeEnv) || "env:CFG_TEST_NONE" != ie.Path {
+		dbg.Error("unset env include: %v", err)
+		t.Fail()
+	}
+	if _, err := o.Parse("@include /no/such/file.cfg\n"); !errors.Is(err, os.ErrNotExist) {
+		dbg.Error("missing include: %v", err)
+		t.Fail()
+	}
+	// only a missing file is skipped
+	o.Include = func(from, path string) (string, string, error) {
+		return "", "", errors.New("denied")
+	}
+	if _, err := o.Parse("@include-optional env:CFG_TEST_EXTRA\n"); !errors.As(err, &ie) || db != ie.Path {
+		dbg.Error("optional include error: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,208 @@
+package cfg
+
+import (
+	"reflect"
+	"strings"
+)
+
+type (
+	/*
+		A range of bytes of config text, from Start up to (not including) End
+	*/
+	Span struct {
+		Start, End int
+	}
+
+	// patchUnit is a top level entry of the text last parsed, with the lines
+	// before it that aren't entries, and the nodes parsed from it
+	patchUnit struct {
+		span  Span
+		nodes []*Node
+	}
+)
+
+/*
+	Brings the config up to date with its edited text, for editors and
+	 daemons parsing a config on every keystroke or reload: src is the whole
+	 new text and edited the range of it that changed, only the top level
+	 entries it touches being parsed again and spliced into the tree (the
+	 entries after them are kept, even when the edit changes their offsets).
+
+	The config must have come from Parse, LoadConfig or an earlier Patch of
+	 the text.  Each call checks the text outside edited is unchanged, and
+	 the whole text is parsed again when it isn't or when the options need
+	 that: Interpolate, Expressions, Keys, Defaults, a Duplicates policy, or
+	 preprocessing changing the text (e.g. @include and @if directives).
+	 On an error the config is left as it was, unless Options.Recover is
+	 set; changes made to the tree since the text was parsed are lost when
+	 their entries are parsed again
+*/
+func (c *Config) Patch(src []byte, edited Span) error {
+	o := c.opts
+	var chain []string
+	if "" != c.source {
+		chain = []string{c.source}
+	}
+	text := string(src)
+	str, err := o.preprocess(chain, text)
+	if nil != err {
+		return err
+	}
+	delta := len(text) - len(c.text)
+	if str != text || o.Interpolate || o.Expressions || nil != o.Keys || nil != o.Defaults || DuplicatesAllowed != o.Duplicates ||
+		edited.Start < 0 || edited.Start > edited.End || edited.End > len(text) || edited.End-delta < edited.Start ||
+		text[:edited.Start] != c.text[:edited.Start] || text[edited.End:] != c.text[edited.End-delta:] || !c.patchable() {
+		return c.reparse(str)
+	}
+
+	// the units from the one holding the edit up to the first unchanged
+	// unit after it, parsed again
+	first, last := c.unitAt(edited.Start), c.unitAt(edited.End-delta)
+	next, end := last+1, c.units[last].span.End+delta
+	var units []patchUnit
+	var errs []error
+	for at := c.units[first].span.Start; at < len(text) || 0 == len(units); {
+		u := patchUnit{span: Span{at, o.unitEnd(text, at)}}
+		if nodes, err := o.parseUnit(text[u.span.Start:u.span.End]); nil != nodes {
+			u.nodes, errs = nodes, appendError(errs, err)
+		} else if nil != err {
+			return err
+		}
+		units, at = append(units, u), u.span.End
+		for next < len(c.units) && c.units[next].span.Start+delta < at {
+			next++
+		}
+		if at >= end && (next == len(c.units) || c.units[next].span.Start+delta == at) {
+			break
+		}
+	}
+	for i := next; i < len(c.units); i++ {
+		c.units[i].span.Start += delta
+		c.units[i].span.End += delta
+	}
+	c.units = append(append(c.units[:first:first], units...), c.units[next:]...)
+	// the units share the array of the children they replace
+	children := make([]*Node, 0, len(c.root.Children))
+	for _, u := range c.units {
+		for _, n := range u.nodes {
+			n.parent = &c.root
+		}
+		children = append(children, u.nodes...)
+	}
+	c.root.Children = children
+	c.text = text
+	c.reindex()
+	if 0 == len(errs) {
+		return c.validated()
+	}
+	return joinErrors(errs)
+}
+
+// ------------------------------------------------------------------------- //
+
+// patchable splits the text last parsed into units, once, reporting whether
+// parsing them one at a time gives the nodes of the tree
+func (c *Config) patchable() bool {
+	if nil != c.units {
+		return true
+	}
+	units, children := []patchUnit{}, c.root.Children
+	for at := 0; at < len(c.text) || 0 == len(units); {
+		u := patchUnit{span: Span{at, c.opts.unitEnd(c.text, at)}}
+		nodes, err := c.opts.parseUnit(c.text[u.span.Start:u.span.End])
+		if nil != err || len(nodes) > len(children) || !sameNodes(nodes, children[:len(nodes)]) {
+			return false
+		}
+		u.nodes, children = children[:len(nodes):len(nodes)], children[len(nodes):]
+		units, at = append(units, u), u.span.End
+	}
+	if 0 != len(children) {
+		return false
+	}
+	c.units = units
+	return true
+}
+
+// reparse replaces the contents of the config with the parsed text
+func (c *Config) reparse(str string) error {
+	nc, err := c.opts.parse(str)
+	if nil == nc {
+		return err
+	}
+	c.root, c.nodes, c.text, c.units, c.deprecated, c.defaults = nc.root, nc.nodes, nc.text, nil, nc.deprecated, nc.defaults
+	for _, n := range c.root.Children {
+		n.parent = &c.root
+	}
+	return err
+}
+
+// unitAt returns the index of the unit holding the offset of the text last
+// parsed, the last unit for its end
+func (c *Config) unitAt(offset int) int {
+	for i, u := range c.units {
+		if offset < u.span.End {
+			return i
+		}
+	}
+	return len(c.units) - 1
+}
+
+// unitEnd returns the end of the unit of the text starting at the offset
+// at: any lines that aren't entries, then the lines of the next top level
+// entry as the EventReader reads them, to the end of the text if it's not
+// ended
+func (o Options) unitEnd(str string, at int) int {
+	closer, entry := "", false
+	for at < len(str) {
+		line, next := str[at:], len(str)
+		if i := strings.Index(line, "\n"); i >= 0 {
+			line, next = line[:i], at+i+1
+		}
+		at, line = next, strings.TrimRight(line, " \t")
+		switch {
+		case entry && "" != closer:
+			if closer == line {
+				return at
+			}
+			continue
+		case entry:
+		case "" == line, ' ' == line[0] || '\t' == line[0], '#' == line[0], o.isComment(line):
+			continue
+		default:
+			_, closer, _ = o.opens(line)
+			entry = true
+		}
+		if "" == closer && !(o.LineContinuation && strings.HasSuffix(line, "\\")) {
+			return at
+		}
+	}
+	return len(str)
+}
+
+// parseUnit returns the top level nodes of a unit of the text
+func (o Options) parseUnit(str string) ([]*Node, error) {
+	o.partial = true
+	c, err := o.parse(str)
+	if nil == c {
+		return nil, err
+	}
+	nodes := c.root.Children
+	if nil == nodes {
+		nodes = []*Node{}
+	}
+	return nodes, err
+}
+
+// sameNodes reports whether the nodes hold the same entries
+func sameNodes(a, b []*Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, n := range a {
+		if n.Type != b[i].Type || n.Path != b[i].Path || !reflect.DeepEqual(n.Data, b[i].Data) ||
+			!sameNodes(n.Children, b[i].Children) {
+			return false
+		}
+	}
+	return true
+}
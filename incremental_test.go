@@ -0,0 +1,80 @@
+package cfg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+// edit replaces the old text of src with new, returning the new text and
+// the span of it that changed
+func edit(src, old, new string) (string, Span) {
+	i := strings.Index(src, old)
+	return src[:i] + new + src[i+len(old):], Span{i, i + len(new)}
+}
+
+func TestPatch(t *testing.T) {
+	src := "first := 0\n# servers\nname := app\nsrv (\n\thost := a\n\tport := 1\n)\nnotes <\nsome text\n>\nlast := z\n"
+	c, err := Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	first, last := c.Lookup("first"), c.Lookup("last")
+	for i, step := range [][2]string{
+		{"\thost := a", "\thost := b"},
+		{"name := app\n", "name := app2\nextra := 2\n"},
+		{"some text\n>\n", "some text\n"},
+		{"last := z\n", "last := z\n>\nafter := 3\n"},
+		{"srv (\n\thost := b\n\tport := 1\n)\n", ""},
+	} {
+		var span Span
+		src, span = edit(src, step[0], step[1])
+		if err = c.Patch([]byte(src), span); nil != err {
+			dbg.Error("step %d: %v", i, err)
+			t.FailNow()
+		}
+		want, _ := Parse(src)
+		if !sameNodes(want.Nodes(), c.Nodes()) || c.String() != want.String() {
+			dbg.Error("step %d: patched\n%s\nexpected\n%s", i, c, want)
+			t.Fail()
+		}
+		if 0 == i && last != c.Lookup("last") {
+			dbg.Error("Unchanged entry after the edit was parsed again")
+			t.Fail()
+		}
+	}
+	if first != c.Lookup("first") || nil != c.Lookup("srv") {
+		dbg.Error("Unchanged entry before the edits was parsed again")
+		t.Fail()
+	}
+	if v, _ := c.Value("after"); "3" != v {
+		dbg.Error("after: %s", v)
+		t.Fail()
+	}
+
+	src = string(conf)
+	c, _ = Parse(src)
+	block := c.Lookup("block1")
+	src, span := edit(src, "cherry := berry", "cherry := pie")
+	if err = c.Patch([]byte(src), span); nil != err || nil == c.units || block != c.Lookup("block1") {
+		dbg.Error("testBlocks.cfg not patched: %v", err)
+		t.Fail()
+	}
+	if v, _ := c.Value("testData:lists:cherry"); "pie" != v {
+		dbg.Error("testData:lists:cherry: %s", v)
+		t.Fail()
+	}
+
+	// an edit whose span doesn't match is a full parse
+	if err = c.Patch([]byte("a := 1\n"), Span{0, 1}); nil != err || 1 != len(c.Nodes()) {
+		dbg.Error("Full parse: %v %v", err, c.Nodes())
+		t.Fail()
+	}
+	if err = c.Patch([]byte("a := 1\ng (\nb := 2\n)\n"), Span{7, 19}); !errors.Is(err, ErrIllegalDataBlock) || 1 != len(c.Nodes()) {
+		dbg.Error("Bad patch: %v %d", err, len(c.Nodes()))
+		t.Fail()
+	}
+}
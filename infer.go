@@ -0,0 +1,105 @@
+package cfg
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+type (
+	/*
+		The type InferValue finds a value to be
+	*/
+	ValueKind int
+
+	/*
+		A value with the type it was inferred to be, see InferValue
+	*/
+	TypedValue struct {
+		Kind  ValueKind
+		Text  string      // the value as written
+		Value interface{} // a string, int64, float64, bool or time.Duration
+	}
+)
+
+const (
+	KindString ValueKind = iota
+	KindInt
+	KindFloat
+	KindBool
+	KindDuration
+)
+
+var (
+	// decimal numbers only, without leading zeros, so e.g. 0755 or a zip
+	// code of 01234 stays a string
+	inferIntRex   = regexp.MustCompile(`^[-+]?(?:0|[1-9]\d*)$`)
+	inferFloatRex = regexp.MustCompile(`^[-+]?(?:0|[1-9]\d*)(?:\.\d+(?:[eE][-+]?\d+)?|[eE][-+]?\d+)$`)
+	// one or more numbers, each with a unit
+	inferDurationRex = regexp.MustCompile(`^[-+]?(?:\d+(?:\.\d+)?(?:ns|us|µs|ms|s|m|h))+$`)
+)
+
+func (k ValueKind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindBool:
+		return "bool"
+	case KindDuration:
+		return "duration"
+	}
+	return "ValueKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+/*
+	Classifies the value as an int (decimal, without leading zeros), float,
+	 bool (just true or false), duration (such as 1h30m) or, failing those,
+	 a string
+*/
+func InferValue(s string) TypedValue {
+	tv := TypedValue{KindString, s, s}
+	switch {
+	case "true" == s || "false" == s:
+		tv.Kind, tv.Value = KindBool, "true" == s
+	case inferIntRex.MatchString(s):
+		if n, err := strconv.ParseInt(s, 10, 64); nil == err {
+			tv.Kind, tv.Value = KindInt, n
+		}
+	case inferFloatRex.MatchString(s):
+		if f, err := strconv.ParseFloat(s, 64); nil == err {
+			tv.Kind, tv.Value = KindFloat, f
+		}
+	case inferDurationRex.MatchString(s):
+		if d, err := time.ParseDuration(s); nil == err {
+			tv.Kind, tv.Value = KindDuration, d
+		}
+	}
+	return tv
+}
+
+/*
+	Returns the value for the label path with its inferred type, see
+	 InferValue
+*/
+func (c *Config) Typed(path string) (TypedValue, bool) {
+	v, ok := c.Value(path)
+	if !ok {
+		return TypedValue{}, false
+	}
+	return InferValue(v), true
+}
+
+// ------------------------------------------------------------------------- //
+
+// inferred returns the value as it's given by ToMap, FlattenTyped & JSON,
+// typed when Options.InferTypes is set
+func (c *Config) inferred(v string) interface{} {
+	if c.opts.InferTypes {
+		return InferValue(v).Value
+	}
+	return v
+}
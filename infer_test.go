@@ -0,0 +1,67 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestInferTypes(t *testing.T) {
+	for s, want := range map[string]TypedValue{
+		"42":                    {KindInt, "42", int64(42)},
+		"-7":                    {KindInt, "-7", int64(-7)},
+		"0755":                  {KindString, "0755", "0755"},
+		"2.5":                   {KindFloat, "2.5", 2.5},
+		"1e3":                   {KindFloat, "1e3", 1000.0},
+		"true":                  {KindBool, "true", true},
+		"yes":                   {KindString, "yes", "yes"},
+		"1h30m":                 {KindDuration, "1h30m", 90 * time.Minute},
+		"64MB":                  {KindString, "64MB", "64MB"},
+		"a b":                   {KindString, "a b", "a b"},
+		"":                      {KindString, "", ""},
+		"1.2.3":                 {KindString, "1.2.3", "1.2.3"},
+		"999999999999999999999": {KindString, "999999999999999999999", "999999999999999999999"},
+	} {
+		if got := InferValue(s); !reflect.DeepEqual(want, got) {
+			dbg.Error("InferValue(%q) is %v %v, expected %v %v", s, got.Kind, got.Value, want.Kind, want.Value)
+			t.Fail()
+		}
+	}
+
+	src := "port := 8080\nratio := 0.5\ndebug := true\ntimeout := 5s\nname := app\nmotd <\n42\n>\ndb (\n\tport := 5432\n)\n"
+	c, _ := Options{InferTypes: true}.Parse(src)
+	m := c.ToMap()
+	if int64(8080) != m["port"] || 0.5 != m["ratio"] || true != m["debug"] || 5*time.Second != m["timeout"] ||
+		"42" != m["motd"] || int64(5432) != m["db"].(map[string]interface{})["port"] {
+		dbg.Error("ToMap: %v", m)
+		t.Fail()
+	}
+	if flat := c.FlattenTyped(); int64(5432) != flat["db:port"] || "app" != flat["name"] {
+		dbg.Error("FlattenTyped: %v", flat)
+		t.Fail()
+	}
+	b, err := json.Marshal(c)
+	if want := `{"port":8080,"ratio":0.5,"debug":true,"timeout":"5s","name":"app","motd":"42","db":{"port":5432}}`; nil != err || want != string(b) {
+		dbg.Error("MarshalJSON: %s %v", b, err)
+		t.Fail()
+	}
+	var s struct {
+		Port interface{}
+		DB   interface{}
+	}
+	if err = c.Unmarshal(&s); nil != err || int64(8080) != s.Port || !reflect.DeepEqual(map[string]interface{}{"port": int64(5432)}, s.DB) {
+		dbg.Error("Unmarshal: %v %v", s, err)
+		t.Fail()
+	}
+	if tv, ok := c.Typed("timeout"); !ok || KindDuration != tv.Kind || "duration" != tv.Kind.String() {
+		dbg.Error("Typed: %v", tv)
+		t.Fail()
+	}
+	if c, _ = Parse(src); "8080" != c.ToMap()["port"] {
+		dbg.Error("ToMap without InferTypes")
+		t.Fail()
+	}
+}
@@ -0,0 +1,140 @@
+package cfg
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jayacarlson/dbg"
+)
+
+var (
+	ErrINISyntax = errors.New("Invalid INI line, expected [section] or key = value")
+
+	// 1: section
+	iniSectionRex = regexp.MustCompile(`^\[[ \t]*([^\]]*?)[ \t]*\]$`)
+	// 1: key  2: value
+	iniPairRex = regexp.MustCompile(`^([^=]*?)[ \t]*=[ \t]*(.*)$`)
+	// a ; or # comment following whitespace
+	iniCommentRex = regexp.MustCompile(`[ \t]+[;#].*$`)
+)
+
+/*
+	Reads the INI file into a Config tree, see ParseINI
+*/
+func LoadINI(flPath string) (*Config, error) {
+	return Options{}.LoadINI(flPath)
+}
+
+/*
+	Parses INI data into a Config tree:
+
+		[section]           a ( ) group, a.b in the name nesting groups
+		key = value         a := value, in the group of the last [section]
+		; comment, # comment ignored, as is a comment following whitespace
+
+	Keys before the first [section] are at the top level, a repeated
+	 [section] adds to the same group, and a value in "quotes" or 'quotes'
+	 is taken as is (so may hold ; or #)
+*/
+func ParseINI(str string) (*Config, error) {
+	return Options{}.ParseINI(str)
+}
+
+/*
+	As LoadINI, using these options
+*/
+func (o Options) LoadINI(flPath string) (*Config, error) {
+	o, loaded := o.loading(flPath)
+	data, err := o.readText(flPath)
+	if dbg.ChkErr(err, "Failed to read INI file: %s (%v)", flPath, err) {
+		return nil, err
+	}
+	c, err := o.ParseINI(data)
+	if nil != err {
+		return nil, err
+	}
+	loaded(c)
+	return c, nil
+}
+
+/*
+	As ParseINI, using these options; the label characters of the options
+	 apply to section names and keys
+*/
+func (o Options) ParseINI(str string) (*Config, error) {
+	c := newConfig()
+	c.opts = o
+	label := o.syntax().label
+	section := ""
+	for i, line := range strings.Split(normalizeEOL(str), "\n") {
+		text := strings.TrimSpace(line)
+		fail := func(err error) (*Config, error) {
+			return nil, &LineError{i + 1, text, err}
+		}
+		if "" == text || ';' == text[0] || '#' == text[0] {
+			continue
+		}
+		if x := iniSectionRex.FindStringSubmatch(text); nil != x {
+			section = ""
+			for _, name := range strings.Split(x[1], ".") {
+				if !label.MatchString(name) {
+					return fail(ErrIllegalLabel)
+				}
+				section = joinPath(section, name)
+			}
+			if n := c.nodes[section]; nil != n && ConfigGroup != n.Type {
+				return fail(ErrWrongType)
+			}
+			c.group(section)
+			continue
+		}
+		x := iniPairRex.FindStringSubmatch(text)
+		if nil == x {
+			return fail(ErrINISyntax)
+		}
+		if !label.MatchString(x[1]) {
+			return fail(ErrIllegalLabel)
+		}
+		path := joinPath(section, x[1])
+		if n := c.nodes[path]; nil != n && ConfigGroup == n.Type {
+			return fail(ErrWrongType)
+		}
+		c.add(ConfigValue, path, []string{iniValue(x[2])})
+	}
+	var err error
+	if DuplicatesAllowed != o.Duplicates {
+		err = c.duplicates(&c.root, o.Duplicates)
+	}
+	if nil == err && nil != o.Keys {
+		err = c.decrypt(o.Keys)
+	}
+	if nil == err && o.Interpolate {
+		err = c.interpolate()
+	}
+	if nil == err && o.Expressions {
+		err = c.evaluate()
+	}
+	if nil == err {
+		err = c.validated()
+	}
+	if nil != err {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// iniValue removes the quotes from a quoted value, or any trailing comment
+// from one that isn't
+func iniValue(v string) string {
+	if len(v) >= 2 && ('"' == v[0] || '\'' == v[0]) {
+		end := strings.LastIndexByte(v, v[0])
+		rest := strings.TrimSpace(v[end+1:])
+		if end > 0 && ("" == rest || ';' == rest[0] || '#' == rest[0]) {
+			return v[1:end]
+		}
+	}
+	return iniCommentRex.ReplaceAllString(v, "")
+}
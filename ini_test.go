@@ -0,0 +1,57 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestINI(t *testing.T) {
+	c, err := LoadINI("testdata/legacy.ini")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"name":            "app",
+		"db:host":         "db.example.com",
+		"db:password":     "p;ss#1",
+		"db:replica:host": "replica",
+		"db:pool":         "4",
+	}
+	for k, want := range expect {
+		if got, ok := c.Value(k); !ok || got != want {
+			dbg.Error("%s: %q", k, got)
+			t.Fail()
+		}
+	}
+	if "testdata/legacy.ini" != c.Source() || 1 != len(c.LookupAll("db")) {
+		dbg.Error("INI source / sections: %s", c.Source())
+		t.Fail()
+	}
+
+	c, err = Options{LabelChars: "-"}.ParseINI("[log-file]\nmax-size = 'a # b'  # comment\n")
+	if got, _ := c.Value("log-file:max-size"); nil != err || "a # b" != got {
+		dbg.Error("INI label chars: %q (%v)", got, err)
+		t.Fail()
+	}
+
+	bad := map[string]error{
+		"[db]\nhost\n":        ErrINISyntax,
+		"[db\n":               ErrINISyntax,
+		"[log file]\n":        ErrIllegalLabel,
+		"max-size = 1\n":      ErrIllegalLabel,
+		"db = x\n[db]\n":      ErrWrongType,
+		"[db]\n[x]\ndb = 1\n": nil,
+		"[a.b]\n[a]\nb = 1\n": ErrWrongType,
+	}
+	for src, want := range bad {
+		_, err := ParseINI(src)
+		var ie *LineError
+		if !errors.Is(err, want) || nil != want && (!errors.As(err, &ie) || 0 == ie.Line) {
+			dbg.Error("ParseINI %q: %v", src, err)
+			t.Fail()
+		}
+	}
+}
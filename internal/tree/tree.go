@@ -0,0 +1,229 @@
+/*
+	Package tree converts a parsed cfg.Config into plain ordered values for
+	 the converters to other formats, and those values back into a Config
+*/
+package tree
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/jayacarlson/cfg"
+)
+
+type (
+	/*
+		A Map holds the entries of a group, dict or table row in order; each
+		 value is a string, []byte, *Map or []interface{} of those, and nil
+		 for a null value when converting to a Config
+	*/
+	Map struct {
+		Keys   []string
+		Values map[string]interface{}
+	}
+)
+
+/*
+	Returns the config as a Map:
+
+		ConfigGroup                 a *Map
+		ConfigValue, ConfigBlock    a string
+		ConfigLines, ConfigItems    an []interface{} of strings
+		ConfigDict                  a *Map of strings
+		ConfigTable                 an []interface{} of *Maps, one each row
+		ConfigBinary                a []byte
+		custom sections             an []interface{} of strings
+
+	A label repeated in a group is an []interface{} holding each entry
+*/
+func Build(c *cfg.Config) (*Map, error) {
+	return group(c.Nodes())
+}
+
+var (
+	ErrMixedArray = errors.New("Array mixes tables, arrays and values")
+)
+
+/*
+	Returns an empty Map
+*/
+func NewMap() *Map {
+	return &Map{Values: make(map[string]interface{})}
+}
+
+/*
+	Sets the value of the key, a new key is added after the existing ones
+*/
+func (m *Map) Set(key string, v interface{}) {
+	if _, ok := m.Values[key]; !ok {
+		m.Keys = append(m.Keys, key)
+	}
+	m.Values[key] = v
+}
+
+/*
+	Converts the Map into a Config, the reverse of Build:
+
+		*Map                        a ( ) group
+		string                      a := value, or a < > block if multi-line
+		nil                         an empty := value
+		[]byte                      a <b64 block
+		array of strings            { } items
+		array of *Maps              a repeated group
+		array of arrays             repeated { } items
+
+	An array mixing these, or holding arrays inside arrays inside arrays,
+	 returns a *cfg.PathError wrapping ErrMixedArray, a key that isn't a
+	 valid label one wrapping cfg.ErrIllegalLabel
+*/
+func Config(m *Map) (*cfg.Config, error) {
+	b := cfg.NewBuilder()
+	if err := build(b, "", m); nil != err {
+		return nil, err
+	}
+	return b.Config()
+}
+
+// ------------------------------------------------------------------------- //
+
+func build(b *cfg.Builder, lp string, m *Map) error {
+	for _, k := range m.Keys {
+		path := k
+		if "" != lp {
+			path = lp + ":" + k
+		}
+		switch v := m.Values[k].(type) {
+		case *Map:
+			if err := build(b.Group(k), path, v); nil != err {
+				return err
+			}
+		case []interface{}:
+			if err := buildArray(b, path, k, v); nil != err {
+				return err
+			}
+		case []byte:
+			b.Binary(k, v)
+		case string:
+			if strings.Contains(v, "\n") {
+				b.Block(k, v)
+			} else {
+				b.Value(k, v)
+			}
+		default:
+			b.Value(k, "")
+		}
+	}
+	return nil
+}
+
+func buildArray(b *cfg.Builder, path, label string, a []interface{}) error {
+	items, maps, lists := []string{}, 0, 0
+	for _, v := range a {
+		switch v := v.(type) {
+		case *Map:
+			maps++
+		case []interface{}:
+			lists++
+			if _, ok := scalars(v); !ok {
+				return &cfg.PathError{Path: path, Err: ErrMixedArray}
+			}
+		default:
+			s, _ := scalars([]interface{}{v})
+			items = append(items, s...)
+		}
+	}
+	switch {
+	case 0 == maps+lists:
+		b.Items(label, items...)
+	case maps == len(a):
+		for _, v := range a {
+			if err := build(b.Group(label), path, v.(*Map)); nil != err {
+				return err
+			}
+		}
+	case lists == len(a):
+		for _, v := range a {
+			s, _ := scalars(v.([]interface{}))
+			b.Items(label, s...)
+		}
+	default:
+		return &cfg.PathError{Path: path, Err: ErrMixedArray}
+	}
+	return nil
+}
+
+// scalars returns the array as items, false if it holds any arrays or maps
+func scalars(a []interface{}) ([]string, bool) {
+	result := []string{}
+	for _, v := range a {
+		switch v := v.(type) {
+		case string:
+			result = append(result, v)
+		case []byte:
+			result = append(result, string(v))
+		case nil:
+			result = append(result, "")
+		default:
+			return nil, false
+		}
+	}
+	return result, true
+}
+
+func group(nodes []*cfg.Node) (*Map, error) {
+	m, repeats := NewMap(), make(map[string][]interface{})
+	for _, n := range nodes {
+		if cfg.ConfigComment == n.Type {
+			continue
+		}
+		v, err := node(n)
+		if nil != err {
+			return nil, err
+		}
+		repeats[n.Label] = append(repeats[n.Label], v)
+		if 1 == len(repeats[n.Label]) {
+			m.Set(n.Label, v)
+		} else {
+			m.Set(n.Label, repeats[n.Label])
+		}
+	}
+	return m, nil
+}
+
+func node(n *cfg.Node) (interface{}, error) {
+	switch n.Type {
+	case cfg.ConfigGroup:
+		return group(n.Children)
+	case cfg.ConfigValue, cfg.ConfigBlock:
+		return n.Data[0], nil
+	case cfg.ConfigBinary:
+		return []byte(n.Data[0]), nil
+	case cfg.ConfigDict:
+		m := NewMap()
+		for i := 0; i+1 < len(n.Data); i += 2 {
+			m.Set(n.Data[i], n.Data[i+1])
+		}
+		return m, nil
+	case cfg.ConfigTable:
+		rows, err := cfg.LinesToTable(n.Data)
+		if nil != err {
+			return nil, &cfg.PathError{Path: n.Path, Err: err}
+		}
+		result := []interface{}{}
+		for _, row := range rows {
+			m := NewMap()
+			for col, v := range row {
+				m.Set(col, v)
+			}
+			sort.Strings(m.Keys)
+			result = append(result, m)
+		}
+		return result, nil
+	}
+	result := []interface{}{}
+	for _, d := range n.Data {
+		result = append(result, d)
+	}
+	return result, nil
+}
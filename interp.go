@@ -0,0 +1,105 @@
+package cfg
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+type (
+	/*
+		A ReferenceError records a ${label:path} reference that could not be
+		 resolved, the Chain holds the label paths being resolved, outermost
+		 first
+	*/
+	ReferenceError struct {
+		Chain []string
+		Ref   string
+		Err   error
+	}
+)
+
+var (
+	ErrUnknownReference  = errors.New("Reference to unknown config value")
+	ErrCircularReference = errors.New("Circular config value reference")
+
+	// $${ is an escaped literal ${
+	// 1: label path
+	interpRex = regexp.MustCompile(`\$\$\{|\$\{([^}]*)\}`)
+)
+
+func (e *ReferenceError) Error() string {
+	return "${" + e.Ref + "} (" + strings.Join(e.Chain, " -> ") + "): " + e.Err.Error()
+}
+
+func (e *ReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// ------------------------------------------------------------------------- //
+
+// interpolate replaces the ${label:path} references in every ConfigValue
+// with the referenced value, see Options.Interpolate
+func (c *Config) interpolate() error {
+	state := make(map[*Node]int)
+	var err error
+	c.flatten(&c.root, func(n *Node) {
+		if nil == err && ConfigValue == n.Type {
+			err = c.resolve(n, nil, state)
+		}
+	})
+	return err
+}
+
+// resolve interpolates the value of n, resolving any referenced values
+// first; state tracks nodes in progress (1) and those done (2)
+func (c *Config) resolve(n *Node, chain []string, state map[*Node]int) error {
+	if 2 == state[n] {
+		return nil
+	}
+	chain = append(chain[:len(chain):len(chain)], n.Path)
+	state[n] = 1
+	var err error
+	n.Data[0] = interpRex.ReplaceAllStringFunc(n.Data[0], func(ref string) string {
+		if nil != err {
+			return ref
+		}
+		if "$${" == ref {
+			return "${"
+		}
+		label := ref[2 : len(ref)-1]
+		r := c.reference(n, label)
+		if nil == r {
+			err = &ReferenceError{chain, label, ErrUnknownReference}
+			return ref
+		}
+		if 1 == state[r] {
+			err = &ReferenceError{append(chain, r.Path), label, ErrCircularReference}
+			return ref
+		}
+		if ConfigValue == r.Type {
+			if err = c.resolve(r, chain, state); nil != err {
+				return ref
+			}
+		}
+		return r.Data[0]
+	})
+	state[n] = 2
+	return err
+}
+
+// reference finds the value referenced from the node n, a label path is
+// looked for relative to the group holding n, then each enclosing group
+func (c *Config) reference(n *Node, label string) *Node {
+	label = c.opts.internalPath(label)
+	for g := n.parent; nil != g; g = g.parent {
+		path := label
+		if "" != g.Path {
+			path = g.Path + ":" + label
+		}
+		if r := c.node(path); nil != r && (ConfigValue == r.Type || ConfigBlock == r.Type) {
+			return r
+		}
+	}
+	return nil
+}
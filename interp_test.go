@@ -0,0 +1,74 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	interpTests = `
+base := /srv/app
+logs := ${base}/logs
+literal := $${base} costs $5
+app (
+	base := /opt/app
+	data := ${base}/data
+	root := ${logs}
+	other := ${db:host}:${db:port}
+)
+db (
+	host := ${name}.example.com
+	port := 5432
+)
+name := forward
+`
+)
+
+func TestInterpolate(t *testing.T) {
+	o := Options{Interpolate: true}
+	c, err := o.Parse(interpTests)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"logs":      "/srv/app/logs",
+		"literal":   "${base} costs $5",
+		"app:data":  "/opt/app/data",
+		"app:root":  "/srv/app/logs",
+		"app:other": "forward.example.com:5432",
+	}
+	for k, want := range expect {
+		if v, _ := c.Value(k); v != want {
+			dbg.Error("%s: %q", k, v)
+			t.Fail()
+		}
+	}
+	if v, _ := Parse(interpTests); v.ValueOr("logs", "") != "${base}/logs" {
+		dbg.Error("Interpolated without option")
+		t.Fail()
+	}
+
+	var re *ReferenceError
+	_, err = o.Parse("a := ${b}\nb := ${c}\nc := ${a}\n")
+	if !errors.As(err, &re) || !errors.Is(err, ErrCircularReference) || !compareEntries(re.Chain, []string{"a", "b", "c", "a"}) {
+		dbg.Error("cycle: %v", err)
+		t.Fail()
+	}
+	if _, err = o.Parse("a := ${nope}\n"); !errors.Is(err, ErrUnknownReference) {
+		dbg.Error("unknown: %v", err)
+		t.Fail()
+	}
+	seen := 0
+	o.HandleConfigData(interpTests, func(ct ConfigType, l string, d []string) {
+		if "app:root" == l && d[0] == "/srv/app/logs" {
+			seen++
+		}
+	})
+	if 0 == seen {
+		dbg.Error("HandleConfigData did not interpolate")
+		t.Fail()
+	}
+}
@@ -0,0 +1,254 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrJSONType = errors.New("JSON value can't be converted to config data")
+)
+
+/*
+	Returns the config as a JSON object mirroring the label paths, keeping the
+	 order of the entries:
+
+		ConfigGroup                 an object
+		ConfigValue, ConfigBlock    a string
+		ConfigLines, ConfigItems    an array of strings
+		ConfigDict                  an object of strings
+		ConfigTable                 an array of objects, one for each row
+		ConfigBinary                a base64 string
+		custom sections             an array of strings
+
+	A label repeated in a group, e.g. a number of server ( ... ) groups, is
+	 an array holding each of the entries.  With Options.InferTypes a
+	 ConfigValue InferValue finds to be a number or bool is written as one
+*/
+func (c *Config) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.jsonGroup(&buf, &c.root); nil != err {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+	Parses the config data and returns it as JSON, see Config.MarshalJSON
+*/
+func ToJSON(data []byte) ([]byte, error) {
+	c, err := Parse(string(data))
+	if nil != err {
+		return nil, err
+	}
+	return c.MarshalJSON()
+}
+
+/*
+	Converts a JSON object into a Config, keeping the order of its keys:
+
+		object                      a ( ) group
+		string                      a := value, or a < > block if multi-line
+		number, bool, null          a := value of the JSON text, "" for null
+		array of strings etc        { } items
+		array of objects            a repeated group
+		array of arrays             repeated { } items, see GetStringLists
+
+	Arrays mixing objects, arrays and other values, or holding arrays inside
+	 arrays inside arrays, return a *PathError wrapping ErrJSONType
+*/
+func FromJSON(b []byte) (*Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if t, err := dec.Token(); nil != err {
+		return nil, err
+	} else if json.Delim('{') != t {
+		return nil, &PathError{"", ErrJSONType}
+	}
+	bld := NewBuilder()
+	if err := jsonObject(dec, bld); nil != err {
+		return nil, err
+	}
+	return bld.Config()
+}
+
+// ------------------------------------------------------------------------- //
+
+// jsonObject adds the entries of the object, its '{' already read, to the
+// group being built
+func jsonObject(dec *json.Decoder, b *Builder) error {
+	for dec.More() {
+		t, err := dec.Token()
+		if nil != err {
+			return err
+		}
+		label := t.(string)
+		if t, err = dec.Token(); nil != err {
+			return err
+		}
+		switch t {
+		case json.Delim('{'):
+			err = jsonObject(dec, b.Group(label))
+		case json.Delim('['):
+			err = jsonArray(dec, b, label)
+		default:
+			if v := jsonScalar(t); strings.Contains(v, "\n") {
+				b.Block(label, v)
+			} else {
+				b.Value(label, v)
+			}
+		}
+		if nil != err {
+			return err
+		}
+	}
+	_, err := dec.Token() // the closing '}'
+	return err
+}
+
+// jsonArray adds the array, its '[' already read, as items, repeated groups
+// or repeated items
+func jsonArray(dec *json.Decoder, b *Builder, label string) error {
+	items, nested := []string{}, false
+	for dec.More() {
+		t, err := dec.Token()
+		if nil != err {
+			return err
+		}
+		switch t {
+		case json.Delim('{'):
+			nested = true
+			err = jsonObject(dec, b.Group(label))
+		case json.Delim('['):
+			nested = true
+			sub := []string{}
+			for nil == err && dec.More() {
+				if t, err = dec.Token(); nil == err {
+					if _, ok := t.(json.Delim); ok {
+						return &PathError{joinPath(b.group.Path, label), ErrJSONType}
+					}
+					sub = append(sub, jsonScalar(t))
+				}
+			}
+			if nil == err {
+				_, err = dec.Token()
+				b.Items(label, sub...)
+			}
+		default:
+			items = append(items, jsonScalar(t))
+		}
+		if nil != err {
+			return err
+		}
+		if nested && 0 != len(items) {
+			return &PathError{joinPath(b.group.Path, label), ErrJSONType}
+		}
+	}
+	if !nested {
+		b.Items(label, items...)
+	}
+	_, err := dec.Token() // the closing ']'
+	return err
+}
+
+// jsonScalar returns the text of a JSON string, number, bool or null
+func jsonScalar(t json.Token) string {
+	switch v := t.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	}
+	return ""
+}
+
+// jsonGroup writes the entries of the group node as a JSON object
+func (c *Config) jsonGroup(buf *bytes.Buffer, g *Node) error {
+	labels, entries := []string{}, make(map[string][]*Node)
+	for _, n := range g.Children {
+		if ConfigComment == n.Type {
+			continue
+		}
+		if _, ok := entries[n.Label]; !ok {
+			labels = append(labels, n.Label)
+		}
+		entries[n.Label] = append(entries[n.Label], n)
+	}
+	buf.WriteByte('{')
+	for i, label := range labels {
+		if 0 != i {
+			buf.WriteByte(',')
+		}
+		writeJSON(buf, label)
+		buf.WriteByte(':')
+		nodes := entries[label]
+		if 1 != len(nodes) {
+			buf.WriteByte('[')
+		}
+		for j, n := range nodes {
+			if 0 != j {
+				buf.WriteByte(',')
+			}
+			if err := c.jsonNode(buf, n); nil != err {
+				return err
+			}
+		}
+		if 1 != len(nodes) {
+			buf.WriteByte(']')
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// jsonNode writes a single node as JSON
+func (c *Config) jsonNode(buf *bytes.Buffer, n *Node) error {
+	switch n.Type {
+	case ConfigGroup:
+		return c.jsonGroup(buf, n)
+	case ConfigValue:
+		v := c.inferred(n.Data[0])
+		if _, ok := v.(time.Duration); ok {
+			v = n.Data[0]
+		}
+		writeJSON(buf, v)
+	case ConfigBlock:
+		writeJSON(buf, n.Data[0])
+	case ConfigBinary:
+		writeJSON(buf, []byte(n.Data[0]))
+	case ConfigDict:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Data); i += 2 {
+			if 0 != i {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, n.Data[i])
+			buf.WriteByte(':')
+			writeJSON(buf, n.Data[i+1])
+		}
+		buf.WriteByte('}')
+	case ConfigTable:
+		rows, err := LinesToTable(n.Data)
+		if nil != err {
+			return &PathError{n.Path, err}
+		}
+		writeJSON(buf, rows)
+	default:
+		writeJSON(buf, append([]string{}, n.Data...))
+	}
+	return nil
+}
+
+// writeJSON writes a string, []byte or other value that always marshals
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	data, _ := json.Marshal(v)
+	buf.Write(data)
+}
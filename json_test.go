@@ -0,0 +1,88 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestToJSON(t *testing.T) {
+	src := `name := app
+motd <
+hello
+>
+server (
+	host := a
+	ports {
+		80 443
+	}
+)
+server (
+	host := b
+	env : [
+		k : v
+	]
+)
+empty [
+	# none
+]
+routes [table
+	path target
+	/ web
+]
+`
+	want := `{"name":"app","motd":"hello","server":[{"host":"a","ports":["80","443"]},` +
+		`{"host":"b","env":{"k":"v"}}],"empty":[],"routes":[{"path":"/","target":"web"}]}`
+	data, err := ToJSON([]byte(src))
+	if nil != err {
+		dbg.Error("ToJSON: %v", err)
+		t.FailNow()
+	}
+	if want != string(data) {
+		dbg.Error("ToJSON gave: %s", data)
+		t.Fail()
+	}
+	c, _ := Parse(src)
+	data, err = json.Marshal(c)
+	if nil != err || want != string(data) {
+		dbg.Error("json.Marshal gave: %s (%v)", data, err)
+		t.Fail()
+	}
+	if !json.Valid(data) {
+		dbg.Error("ToJSON gave invalid JSON")
+		t.Fail()
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	src := `{
+		"name": "app", "port": 8080, "debug": true, "none": null,
+		"motd": "hello\nworld",
+		"db": {"host": "x", "ports": [80, 443]},
+		"server": [{"host": "a"}, {"host": "b"}],
+		"pairs": [["a", "b"], ["c"]],
+		"empty": []
+	}`
+	want := "name := app\nport := 8080\ndebug := true\nnone :=\n" +
+		"motd <\nhello\nworld\n>\n" +
+		"db (\n\thost := x\n\tports {\n\t\t80 443\n\t}\n)\n" +
+		"server (\n\thost := a\n)\nserver (\n\thost := b\n)\n" +
+		"pairs {\n\ta b\n}\npairs {\n\tc\n}\n" +
+		"empty {\n\n}\n"
+	c, err := FromJSON([]byte(src))
+	if nil != err {
+		dbg.Error("FromJSON: %v", err)
+		t.FailNow()
+	}
+	if want != c.String() {
+		dbg.Error("FromJSON gave:\n%s", c.String())
+		t.Fail()
+	}
+	for _, bad := range []string{`[1]`, `{"a": [1, {}]}`, `{"a": [[[1]]]}`, `{"bad key": 1}`, `{"a": `} {
+		if _, err := FromJSON([]byte(bad)); nil == err {
+			dbg.Error("FromJSON %s didn't fail", bad)
+			t.Fail()
+		}
+	}
+}
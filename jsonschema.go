@@ -0,0 +1,214 @@
+package cfg
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// jsonObj is a JSON object that keeps the order of its members
+	jsonObj []jsonMember
+
+	jsonMember struct {
+		key   string
+		value interface{}
+	}
+
+	// jsonSchemaNode holds the fields declared at and below a label path
+	jsonSchemaNode struct {
+		field    *SchemaField
+		labels   []string
+		children map[string]*jsonSchemaNode
+	}
+)
+
+var (
+	jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+	// the text accepted for the value types, ECMA 262 being without (?i)
+	jsonSchemaPatterns = map[SchemaType]string{
+		SchemaInt:      `^\s*[+-]?(0[xX][0-9a-fA-F]+|0[oO][0-7]+|0[bB][01]+|[0-9]+)\s*$`,
+		SchemaFloat:    `^[+-]?(([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?|[Ii]nf(inity)?|[Nn]a[Nn])$`,
+		SchemaBool:     `^\s*([Tt]([Rr][Uu][Ee])?|[Yy]([Ee][Ss])?|[Oo][Nn]|1|[Ff]([Aa][Ll][Ss][Ee])?|[Nn][Oo]?|[Oo][Ff][Ff]|0)\s*$`,
+		SchemaDuration: `^[+-]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`,
+		SchemaBytes:    `^\s*[0-9.]*[0-9][0-9.]*\s*([KkMmGgTtPpEe][Ii]?)?[Bb]?\s*$`,
+	}
+)
+
+/*
+	Returns a JSON Schema (draft 2020-12) document describing the JSON the
+	 config would give (see Config.MarshalJSON) when it's valid, so editors
+	 and CI checks that read JSON Schema can validate converted configs:
+
+		value types                 a string, with a pattern for the
+		                             int, float, bool, duration & bytes
+		                             types and the schema's Match
+		block                       a string
+		lines, items                an array of strings
+		dict                        an object of strings
+		group                       an object, or an array of them for
+		                             a repeated group
+
+	Required label paths (and the groups holding them) are listed as
+	 required, defaults become the default values and Len limits give the
+	 minLength, minItems or minProperties and their max; a Range can't be
+	 checked on a string so it's only given in the description
+*/
+func (s *Schema) JSONSchema() []byte {
+	root := &jsonSchemaNode{}
+	for i := range s.fields {
+		n := root
+		for _, label := range strings.Split(s.fields[i].Path, ":") {
+			n = n.child(label)
+		}
+		n.field = &s.fields[i]
+	}
+	doc := append(jsonObj{{"$schema", jsonSchemaDraft}}, root.object()...)
+	var buf bytes.Buffer
+	writeJSON(&buf, doc)
+	return buf.Bytes()
+}
+
+func (o jsonObj) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if 0 != i {
+			buf.WriteByte(',')
+		}
+		writeJSON(&buf, m.key)
+		buf.WriteByte(':')
+		writeJSON(&buf, m.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ------------------------------------------------------------------------- //
+
+func (n *jsonSchemaNode) child(label string) *jsonSchemaNode {
+	if nil == n.children {
+		n.children = make(map[string]*jsonSchemaNode)
+	}
+	ch, ok := n.children[label]
+	if !ok {
+		ch = &jsonSchemaNode{}
+		n.labels = append(n.labels, label)
+		n.children[label] = ch
+	}
+	return ch
+}
+
+// required reports whether the node or any below it is required
+func (n *jsonSchemaNode) required() bool {
+	if nil != n.field && n.field.Required {
+		return true
+	}
+	for _, ch := range n.children {
+		if ch.required() {
+			return true
+		}
+	}
+	return false
+}
+
+// object describes the node as an object of its children
+func (n *jsonSchemaNode) object() jsonObj {
+	props, required := jsonObj{}, []string{}
+	for _, label := range n.labels {
+		ch := n.children[label]
+		props = append(props, jsonMember{label, ch.schema()})
+		if ch.required() {
+			required = append(required, label)
+		}
+	}
+	o := jsonObj{{"type", "object"}, {"properties", props}}
+	if 0 != len(required) {
+		o = append(o, jsonMember{"required", required})
+	}
+	return o
+}
+
+// schema describes the node, a group if it has children
+func (n *jsonSchemaNode) schema() jsonObj {
+	f := n.field
+	if 0 != len(n.children) || nil != f && SchemaGroup == f.Type {
+		o := n.object()
+		if nil != f {
+			o = f.limits(o, "Properties")
+		}
+		return jsonObj{{"anyOf", []jsonObj{o, {{"type", "array"}, {"items", o}}}}}
+	}
+	o := jsonObj{}
+	switch f.Type {
+	case SchemaLines, SchemaItems:
+		item := jsonObj{{"type", "string"}}
+		if nil != f.Pattern {
+			item = append(item, jsonMember{"pattern", f.Pattern.String()})
+		}
+		o = f.limits(append(o, jsonMember{"type", "array"}, jsonMember{"items", item}), "Items")
+	case SchemaDict:
+		o = f.limits(append(o, jsonMember{"type", "object"}, jsonMember{"additionalProperties", jsonObj{{"type", "string"}}}), "Properties")
+	case SchemaBlock:
+		o = append(o, jsonMember{"type", "string"})
+	default:
+		o = append(o, jsonMember{"type", "string"})
+		p, ok := jsonSchemaPatterns[f.Type]
+		switch {
+		case ok && nil != f.Pattern:
+			// both patterns must match
+			o = jsonObj{{"allOf", []jsonObj{append(o, jsonMember{"pattern", p}), {{"pattern", f.Pattern.String()}}}}}
+		case ok:
+			o = append(o, jsonMember{"pattern", p})
+		case nil != f.Pattern:
+			o = append(o, jsonMember{"pattern", f.Pattern.String()})
+		}
+		o = f.limits(o, "Length")
+	}
+	o = append(o, jsonMember{"description", f.description()})
+	if nil != f.Default {
+		switch f.Type {
+		case SchemaLines, SchemaItems:
+			o = append(o, jsonMember{"default", f.Default})
+		default:
+			if 0 != len(f.Default) {
+				o = append(o, jsonMember{"default", f.Default[0]})
+			}
+		}
+	}
+	return o
+}
+
+// limits adds the Len limits of the field as the JSON Schema keywords with
+// the suffix, e.g. minItems & maxItems
+func (f *SchemaField) limits(o jsonObj, suffix string) jsonObj {
+	if f.MinLen > 0 {
+		o = append(o, jsonMember{"min" + suffix, f.MinLen})
+	}
+	if f.MaxLen > 0 {
+		o = append(o, jsonMember{"max" + suffix, f.MaxLen})
+	}
+	return o
+}
+
+// description names the schema type and any range of the field
+func (f *SchemaField) description() string {
+	d := f.Type.String()
+	if nil != f.Min {
+		d += ", at least " + f.bound(*f.Min)
+	}
+	if nil != f.Max {
+		d += ", at most " + f.bound(*f.Max)
+	}
+	return d
+}
+
+// bound writes a range bound as a value of the field's type is written
+func (f *SchemaField) bound(v float64) string {
+	if SchemaDuration == f.Type {
+		return time.Duration(v).String()
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
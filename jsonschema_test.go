@@ -0,0 +1,81 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestJSONSchema(t *testing.T) {
+	s := NewSchema().
+		Required("name", SchemaValue).Match(regexp.MustCompile(`^[a-z]+$`)).
+		Default("db:port", SchemaInt, "5432").Range(1, 65535).
+		Optional("db:timeout", SchemaDuration).Range(0, float64(time.Minute)).
+		Required("server:host", SchemaValue).
+		Default("tags", SchemaItems, "a", "b").Len(1, 4).
+		Optional("env", SchemaDict)
+	data := s.JSONSchema()
+	if !strings.HasPrefix(string(data), `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"name":`) {
+		dbg.Error("JSONSchema order: %s", data)
+		t.Fail()
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); nil != err {
+		dbg.Error("JSONSchema: %v %s", err, data)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual([]interface{}{"name", "server"}, doc["required"]) {
+		dbg.Error("JSONSchema required: %v", doc["required"])
+		t.Fail()
+	}
+	props := doc["properties"].(map[string]interface{})
+	db := props["db"].(map[string]interface{})["anyOf"].([]interface{})[0].(map[string]interface{})
+	port := db["properties"].(map[string]interface{})["port"].(map[string]interface{})
+	if "5432" != port["default"] || "int, at least 1, at most 65535" != port["description"] ||
+		!regexp.MustCompile(port["pattern"].(string)).MatchString("0x1F") {
+		dbg.Error("JSONSchema db:port: %v", port)
+		t.Fail()
+	}
+	timeout := db["properties"].(map[string]interface{})["timeout"].(map[string]interface{})
+	if "duration, at least 0s, at most 1m0s" != timeout["description"] {
+		dbg.Error("JSONSchema db:timeout: %v", timeout)
+		t.Fail()
+	}
+	tags := props["tags"].(map[string]interface{})
+	if "array" != tags["type"] || 1.0 != tags["minItems"] || 4.0 != tags["maxItems"] || 2 != len(tags["default"].([]interface{})) {
+		dbg.Error("JSONSchema tags: %v", tags)
+		t.Fail()
+	}
+	if env := props["env"].(map[string]interface{}); "object" != env["type"] {
+		dbg.Error("JSONSchema env: %v", env)
+		t.Fail()
+	}
+	if _, ok := props["name"].(map[string]interface{})["allOf"]; ok {
+		dbg.Error("JSONSchema name: %v", props["name"])
+		t.Fail()
+	}
+
+	for typ, ok := range map[SchemaType][]string{
+		SchemaBool:     {"yes", "Off", " T ", "0"},
+		SchemaDuration: {"1h30m", "-1.5s", "0", "300ms"},
+		SchemaBytes:    {"512", "10KB", "4MiB", "1.5 GB"},
+		SchemaFloat:    {"1e6", "-.5", "Inf"},
+	} {
+		re := regexp.MustCompile(jsonSchemaPatterns[typ])
+		for _, v := range ok {
+			if !re.MatchString(v) {
+				dbg.Error("JSONSchema %s pattern rejects %q", typ, v)
+				t.Fail()
+			}
+		}
+		if re.MatchString("bad") {
+			dbg.Error("JSONSchema %s pattern accepts bad", typ)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package cfg
+
+import (
+	"flag"
+	"os"
+	"sort"
+	"strings"
+)
+
+type (
+	/*
+		Layers merges a number of config sources into a single Config, each
+		 added in increasing priority, e.g. defaults < file < env < flags:
+
+			var l cfg.Layers
+			l.Add("defaults", defaults).Add("file", file)
+			l.AddEnv("env", "APP_").AddFlags("flags", flag.CommandLine, "")
+			c, err := l.Merge()
+			from := l.Source("db:host")     // "env" if APP_DB_HOST was set
+
+		The zero value is ready to use
+	*/
+	Layers struct {
+		layers  []layer
+		sources map[string]string // label path -> layer name
+	}
+
+	layer struct {
+		name string
+		load func(merged *Config) (*Config, error)
+	}
+)
+
+/*
+	Adds the config as the next layer, overriding the values of those
+	 added before it
+*/
+func (l *Layers) Add(name string, c *Config) *Layers {
+	l.layers = append(l.layers, layer{name, func(*Config) (*Config, error) {
+		return c, nil
+	}})
+	return l
+}
+
+/*
+	Adds a layer of environment variables, each overriding the value of
+	 the layers before it with the same label path; the variable for a
+	 label path is named as for ApplyEnvOverrides, e.g. APP_DB_HOST for
+	 db:host with the prefix "APP_"
+*/
+func (l *Layers) AddEnv(name, prefix string) *Layers {
+	l.layers = append(l.layers, layer{name, func(merged *Config) (*Config, error) {
+		env := newConfig()
+		for _, p := range merged.valuePaths() {
+			if v, ok := os.LookupEnv(envName(prefix, p)); ok {
+				env.add(ConfigValue, p, []string{v})
+			}
+		}
+		return env, nil
+	}})
+	return l
+}
+
+/*
+	Adds a layer of the flags set on the command line, once the flag set
+	 is parsed; flags named with the prefix give the label path of the rest
+	 of their name with ':' for '.', e.g. -db.host for db:host as with
+	 BindFlags, all other flags are ignored
+*/
+func (l *Layers) AddFlags(name string, fs *flag.FlagSet, prefix string) *Layers {
+	l.layers = append(l.layers, layer{name, func(merged *Config) (*Config, error) {
+		flags := newConfig()
+		label := merged.opts.syntax().label
+		var err error
+		fs.Visit(func(f *flag.Flag) {
+			if nil != err || !strings.HasPrefix(f.Name, prefix) {
+				return
+			}
+			path := strings.ReplaceAll(f.Name[len(prefix):], ".", ":")
+			for _, elem := range strings.Split(path, ":") {
+				if !label.MatchString(elem) {
+					err = &PathError{path, ErrIllegalLabel}
+					return
+				}
+			}
+			flags.add(ConfigValue, path, []string{f.Value.String()})
+		})
+		return flags, err
+	}})
+	return l
+}
+
+/*
+	Merges the layers, giving a new Config holding every entry of each
+	 layer with those of the later layers replacing the entries they share
+	 a label path with; a layer only replaces a value with a value (or a
+	 group with a group), otherwise a *PathError wrapping ErrWrongType is
+	 returned.  The options of the first layer are used for the result
+*/
+func (l *Layers) Merge() (*Config, error) {
+	c := newConfig()
+	l.sources = make(map[string]string)
+	for i, ly := range l.layers {
+		src, err := ly.load(c)
+		if nil != err {
+			return nil, err
+		}
+		if 0 == i {
+			c.opts = src.opts
+		}
+		if err = c.mergeable(src); nil != err {
+			return nil, err
+		}
+		c.merge(src)
+		src.flatten(&src.root, func(n *Node) {
+			l.sources[n.Path] = ly.name
+		})
+	}
+	return c, nil
+}
+
+/*
+	Returns the name of the layer the value of the label path came from in
+	 the last Merge, or "" if no layer has it
+*/
+func (l *Layers) Source(path string) string {
+	return l.sources[path]
+}
+
+/*
+	Returns the layer name of each label path of the last Merge
+*/
+func (l *Layers) Sources() map[string]string {
+	result := make(map[string]string, len(l.sources))
+	for p, name := range l.sources {
+		result[p] = name
+	}
+	return result
+}
+
+// ------------------------------------------------------------------------- //
+
+// mergeable checks that merging the other config would not replace a group
+// with a value or a value with a group
+func (c *Config) mergeable(other *Config) error {
+	var err error
+	other.flatten(&other.root, func(n *Node) {
+		if nil != err {
+			return
+		}
+		if old := c.nodes[n.Path]; nil != old && ConfigGroup == old.Type {
+			err = &PathError{n.Path, ErrWrongType}
+		}
+		elems := strings.Split(n.Path, ":")
+		for i := 1; i < len(elems) && nil == err; i++ {
+			if old := c.nodes[strings.Join(elems[:i], ":")]; nil != old && ConfigGroup != old.Type {
+				err = &PathError{n.Path, ErrWrongType}
+			}
+		}
+	})
+	return err
+}
+
+// valuePaths returns the sorted label paths of the single values
+func (c *Config) valuePaths() []string {
+	paths := []string{}
+	for p, n := range c.nodes {
+		if ConfigValue == n.Type || ConfigBlock == n.Type {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
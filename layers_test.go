@@ -0,0 +1,65 @@
+package cfg
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestLayers(t *testing.T) {
+	defaults, _ := Parse("port := 8080\nlog := info\ndb (\n\thost := localhost\n\tuser := app\n)\n")
+	file, _ := Parse("log := debug\ndb (\n\thost := db.internal\n)\nname := svc\n")
+	os.Setenv("LAYERS_TEST_DB_USER", "admin")
+	os.Setenv("LAYERS_TEST_LOG", "warn")
+	defer os.Unsetenv("LAYERS_TEST_DB_USER")
+	defer os.Unsetenv("LAYERS_TEST_LOG")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("log", "", "")
+	fs.String("port", "", "")
+	fs.Bool("v", false, "")
+	fs.Parse([]string{"-log=error", "-v"})
+
+	var l Layers
+	l.Add("defaults", defaults).Add("file", file)
+	l.AddEnv("env", "LAYERS_TEST_").AddFlags("flags", fs, "")
+	c, err := l.Merge()
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string][2]string{
+		"port":    {"8080", "defaults"},
+		"log":     {"error", "flags"},
+		"db:host": {"db.internal", "file"},
+		"db:user": {"admin", "env"},
+		"name":    {"svc", "file"},
+		"v":       {"true", "flags"},
+	}
+	for p, want := range expect {
+		if got, _ := c.Value(p); want[0] != got || want[1] != l.Source(p) {
+			dbg.Error("Layers %s: %q from %q", p, got, l.Source(p))
+			t.Fail()
+		}
+	}
+	if "" != l.Source("nope") || len(expect) != len(l.Sources()) {
+		dbg.Error("Layers sources: %v", l.Sources())
+		t.Fail()
+	}
+	if v, _ := defaults.Value("log"); "info" != v {
+		dbg.Error("Layers changed a layer: %q", v)
+		t.Fail()
+	}
+
+	group, _ := Parse("port (\n\tnum := 1\n)\n")
+	value, _ := Parse("db := x\n")
+	for _, bad := range []*Config{group, value} {
+		var l Layers
+		if _, err := l.Add("defaults", defaults).Add("bad", bad).Merge(); !errors.Is(err, ErrWrongType) {
+			dbg.Error("Layers merged a type change: %v", err)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package cfg
+
+import (
+	"strconv"
+	"strings"
+)
+
+type (
+	/*
+		The kind of a Token, see Lexer
+	*/
+	TokenKind int
+
+	/*
+		A Token is a piece of config text; Line and Col give where its Text
+		 starts, from 1, Col counting bytes
+	*/
+	Token struct {
+		Kind TokenKind
+		Text string
+		Line int
+		Col  int
+	}
+
+	/*
+		A Lexer splits config text into Tokens, without checking or parsing
+		 the data, for syntax highlighters, formatters and validators:
+
+			name := value       LABEL ASSIGN TEXT NEWLINE
+			srv (               LABEL OPEN_BLOCK NEWLINE
+				ports { 80 }    LABEL OPEN_BLOCK TEXT CLOSE NEWLINE
+			)                   CLOSE NEWLINE
+
+		The lines of a block, heredoc or multi-line value are each a single
+		 TEXT token, as are the lines of lines, items and dict sections other
+		 than comments.  A line that's none of the config syntax is a TEXT
+		 token; preprocessing directives aren't handled
+	*/
+	Lexer struct {
+		o     Options
+		lines []string
+		line  int     // the number of lines lexed
+		open  []lexed // the sections open at this line, innermost last
+		queue []Token
+	}
+
+	// lexed is an open section, and how its lines are lexed
+	lexed struct {
+		close string
+		mode  lexMode
+	}
+
+	lexMode int
+)
+
+const (
+	TokenLabel     TokenKind = iota // the label of an entry
+	TokenAssign                     // := or :== of a value
+	TokenOpenBlock                  // the opening delimiter of a section: < [ { ( : [ , { <<TAG ...
+	TokenText                       // a value, the text of a section line, or anything else
+	TokenClose                      // the closing delimiter of a section
+	TokenComment                    // a comment line
+	TokenNewline                    // the end of a line
+)
+
+const (
+	lexEntries lexMode = iota // the entries of a group
+	lexList                   // lines, items & dicts, which can have comments
+	lexRaw                    // blocks & multi-line values, just text
+)
+
+var tokenNames = []string{"LABEL", "ASSIGN", "OPEN_BLOCK", "TEXT", "CLOSE", "COMMENT", "NEWLINE"}
+
+func (k TokenKind) String() string {
+	if k >= 0 && int(k) < len(tokenNames) {
+		return tokenNames[k]
+	}
+	return "TokenKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+/*
+	Returns a Lexer of the config text
+*/
+func NewLexer(src string) *Lexer {
+	return Options{}.NewLexer(src)
+}
+
+/*
+	As NewLexer, with the labels, comment prefixes and sections allowed by
+	 these options
+*/
+func (o Options) NewLexer(src string) *Lexer {
+	src = normalizeEOL(strings.TrimPrefix(src, "\ufeff"))
+	lines := strings.SplitAfter(src, "\n")
+	if "" == lines[len(lines)-1] {
+		lines = lines[:len(lines)-1]
+	}
+	return &Lexer{o: o, lines: lines}
+}
+
+/*
+	Returns the next Token, false once the text has all been read
+*/
+func (l *Lexer) Next() (Token, bool) {
+	for 0 == len(l.queue) {
+		if l.line == len(l.lines) {
+			return Token{}, false
+		}
+		l.lex(l.lines[l.line])
+	}
+	t := l.queue[0]
+	l.queue = l.queue[1:]
+	return t, true
+}
+
+// ------------------------------------------------------------------------- //
+
+// lex queues the Tokens of the next line
+func (l *Lexer) lex(line string) {
+	l.line++
+	nl := strings.HasSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\n")
+	text := strings.TrimRight(line, " \t")
+	col := len(text) - len(strings.TrimLeft(text, " \t"))
+	text = text[col:]
+	mode, closed := lexEntries, false
+	if n := len(l.open); 0 != n {
+		if mode = l.open[n-1].mode; l.open[n-1].close == text {
+			l.emit(TokenClose, text, col)
+			l.open, closed = l.open[:n-1], true
+		}
+	}
+	switch {
+	case closed:
+	case lexRaw == mode && "" != line:
+		l.emit(TokenText, line, 0)
+	case "" == text:
+	case strings.HasPrefix(text, "#") || l.o.isComment(text):
+		l.emit(TokenComment, text, col)
+	case lexList == mode:
+		l.emit(TokenText, text, col)
+	default:
+		l.entry(text, col)
+	}
+	if nl {
+		l.emit(TokenNewline, "\n", len(line))
+	}
+}
+
+// entry queues the Tokens of an entry of a group, text starting at the
+// offset col of the line
+func (l *Lexer) entry(text string, col int) {
+	s := l.o.syntax()
+	if x := editValueRex.FindStringSubmatchIndex(text); nil != x {
+		l.emit(TokenLabel, text[:x[3]], col)
+		value := strings.TrimSpace(text[x[4]:])
+		if "=" == value {
+			l.emit(TokenAssign, ":==", col+x[4]-2)
+			l.open = append(l.open, lexed{":==", lexRaw})
+			return
+		}
+		if l.emit(TokenAssign, ":=", col+x[4]-2); "" != value {
+			l.emit(TokenText, value, col+len(text)-len(value))
+		}
+		return
+	}
+	if x := editHeredocRex.FindStringSubmatchIndex(text); nil != x {
+		l.emit(TokenLabel, text[:x[3]], col)
+		l.emit(TokenOpenBlock, "<<"+text[x[4]:x[5]], col+x[4]-2)
+		l.open = append(l.open, lexed{text[x[4]:x[5]], lexRaw})
+		return
+	}
+	if x := s.inline.FindStringSubmatchIndex(text); nil != x {
+		l.inline(text, col, x)
+		return
+	}
+	if cs, sec := s.findSection(text + "\n"); nil != cs && 0 == cs[0] {
+		l.emit(TokenLabel, text[:cs[3]], col)
+		l.emit(TokenOpenBlock, sec.open, col+strings.LastIndex(text, sec.open))
+		l.open = append(l.open, lexed{sec.close, lexRaw})
+		return
+	}
+	if x := formatSectionRex.FindStringSubmatchIndex(text); nil != x && s.label.MatchString(text[:x[3]]) {
+		at := x[6]
+		if x[4] >= 0 {
+			at = x[4]
+		}
+		l.emit(TokenLabel, text[:x[3]], col)
+		l.emit(TokenOpenBlock, text[at:], col+at)
+		mode := lexList
+		switch text[x[6]:x[7]] {
+		case "(":
+			mode = lexEntries
+		case "<":
+			mode = lexRaw
+		}
+		l.open = append(l.open, lexed{matching[text[x[6]:x[7]]], mode})
+		return
+	}
+	l.emit(TokenText, text, col)
+}
+
+// inline queues the Tokens of an inline items list or group matched by x
+func (l *Lexer) inline(text string, col int, x []int) {
+	l.emit(TokenLabel, text[:x[3]], col)
+	end := strings.LastIndexAny(text, "})")
+	open := x[3] + len(text[x[3]:]) - len(strings.TrimLeft(text[x[3]:], " \t"))
+	l.emit(TokenOpenBlock, strings.TrimRight(text[open:open+strings.IndexAny(text[open:], "{(")+1], " \t"), col+open)
+	if x[6] >= 0 && x[6] != x[7] {
+		l.emit(TokenText, text[x[6]:x[7]], col+x[6])
+	}
+	if x[8] >= 0 {
+		values := text[x[8]:x[9]]
+		v := l.o.syntax().inlineValue.FindAllStringSubmatchIndex(values, -1)
+		for i, m := range v {
+			l.emit(TokenLabel, values[m[2]:m[3]], col+x[8]+m[2])
+			l.emit(TokenAssign, ":=", col+x[8]+m[1]-2)
+			next := len(values)
+			if i+1 < len(v) {
+				next = v[i+1][0]
+			}
+			if value := strings.TrimSpace(values[m[1]:next]); "" != value {
+				l.emit(TokenText, value, col+x[8]+m[1]+strings.Index(values[m[1]:next], value))
+			}
+		}
+	}
+	l.emit(TokenClose, text[end:end+1], col+end)
+}
+
+// emit queues a Token starting at the (0 based) offset of the line
+func (l *Lexer) emit(kind TokenKind, text string, offset int) {
+	l.queue = append(l.queue, Token{kind, text, l.line, offset + 1})
+}
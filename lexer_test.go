@@ -0,0 +1,59 @@
+package cfg
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestLexer(t *testing.T) {
+	src := "# top\nname := my app\nsrv (\n\tmotd <\n  hi\n\n\t>\n\tports { 80 443 }\n\tpt ( x := 1  y := 2 )\n)\n" +
+		"env : [\n\t# c\n\ta : b\n]\nmsg <<END\n)\nEND\nbad line\n"
+	expect := []string{
+		"1:1 COMMENT # top", "1:6 NEWLINE",
+		"2:1 LABEL name", "2:6 ASSIGN :=", "2:9 TEXT my app", "2:15 NEWLINE",
+		"3:1 LABEL srv", "3:5 OPEN_BLOCK (", "3:6 NEWLINE",
+		"4:2 LABEL motd", "4:7 OPEN_BLOCK <", "4:8 NEWLINE",
+		"5:1 TEXT   hi", "5:5 NEWLINE",
+		"6:1 NEWLINE",
+		"7:2 CLOSE >", "7:3 NEWLINE",
+		"8:2 LABEL ports", "8:8 OPEN_BLOCK {", "8:10 TEXT 80 443", "8:17 CLOSE }", "8:18 NEWLINE",
+		"9:2 LABEL pt", "9:5 OPEN_BLOCK (", "9:7 LABEL x", "9:9 ASSIGN :=", "9:12 TEXT 1",
+		"9:15 LABEL y", "9:17 ASSIGN :=", "9:20 TEXT 2", "9:22 CLOSE )", "9:23 NEWLINE",
+		"10:1 CLOSE )", "10:2 NEWLINE",
+		"11:1 LABEL env", "11:5 OPEN_BLOCK : [", "11:8 NEWLINE",
+		"12:2 COMMENT # c", "12:5 NEWLINE",
+		"13:2 TEXT a : b", "13:7 NEWLINE",
+		"14:1 CLOSE ]", "14:2 NEWLINE",
+		"15:1 LABEL msg", "15:5 OPEN_BLOCK <<END", "15:10 NEWLINE",
+		"16:1 TEXT )", "16:2 NEWLINE",
+		"17:1 CLOSE END", "17:4 NEWLINE",
+		"18:1 TEXT bad line", "18:9 NEWLINE",
+	}
+	got := []string{}
+	for l := NewLexer(src); ; {
+		tok, ok := l.Next()
+		if !ok {
+			break
+		}
+		got = append(got, strings.TrimSuffix(strconv.Itoa(tok.Line)+":"+strconv.Itoa(tok.Col)+" "+tok.Kind.String()+" "+tok.Text, " \n"))
+	}
+	if strings.Join(expect, "\n") != strings.Join(got, "\n") {
+		dbg.Error("tokens:\n%s", strings.Join(got, "\n"))
+		t.Fail()
+	}
+
+	l := NewLexer("a := 1")
+	if tok, ok := l.Next(); !ok || TokenLabel != tok.Kind {
+		dbg.Error("first token of a line without a newline: %v", tok)
+		t.Fail()
+	}
+	l.Next()
+	l.Next()
+	if tok, ok := l.Next(); ok {
+		dbg.Error("token after the end of the text: %v", tok)
+		t.Fail()
+	}
+}
@@ -0,0 +1,69 @@
+package cfg
+
+import (
+	"io"
+	"io/ioutil"
+	"strconv"
+)
+
+type (
+	/*
+		A TooLargeError is returned for config data larger than
+		 Options.MaxSize, the path is that of the file or URL if known
+	*/
+	TooLargeError struct {
+		Path string
+		Max  int64
+	}
+
+	// limitedReader reads from r, failing with a TooLargeError once more
+	// than max bytes have been read
+	limitedReader struct {
+		r      io.Reader
+		path   string
+		max, n int64
+	}
+)
+
+func (e *TooLargeError) Error() string {
+	msg := "Config data larger than " + strconv.FormatInt(e.Max, 10) + " bytes"
+	if "" == e.Path {
+		return msg
+	}
+	return e.Path + ": " + msg
+}
+
+// ------------------------------------------------------------------------- //
+
+// readAll reads all of r, stopping with a TooLargeError once more than max
+// bytes have been read when max is set
+func readAll(r io.Reader, path string, max int64) ([]byte, error) {
+	if max <= 0 {
+		return ioutil.ReadAll(r)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, max+1))
+	if nil != err {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, &TooLargeError{path, max}
+	}
+	return data, nil
+}
+
+// limitReader returns a reader of r that fails once more than max bytes
+// have been read when max is set, r itself when it isn't
+func limitReader(r io.Reader, path string, max int64) io.Reader {
+	if max <= 0 {
+		return r
+	}
+	return &limitedReader{r, path, max, 0}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if l.n += int64(n); l.n > l.max {
+		return n, &TooLargeError{l.path, l.max}
+	}
+	return n, err
+}
@@ -0,0 +1,87 @@
+package cfg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	big := filepath.Join(dir, "big.cfg")
+	dbg.ChkErr(ioutil.WriteFile(big, []byte(strings.Repeat("a := 1\n", 100)), 0644), "WriteFile")
+
+	if _, err := (Options{MaxSize: 700}).LoadConfig(big); nil != err {
+		dbg.Error("MaxSize at limit: %v", err)
+		t.Fail()
+	}
+	_, err = Options{MaxSize: 699}.LoadConfig(big)
+	var te *TooLargeError
+	if !errors.As(err, &te) || big != te.Path || 699 != te.Max {
+		dbg.Error("MaxSize file: %v", err)
+		t.Fail()
+	}
+
+	// the limit applies to the decompressed data too
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	fmt.Fprint(w, strings.Repeat("b := 2\n", 1000))
+	w.Close()
+	gz := filepath.Join(dir, "big.cfg.gz")
+	dbg.ChkErr(ioutil.WriteFile(gz, b.Bytes(), 0644), "WriteFile")
+	if _, err = (Options{MaxSize: 1000}).LoadConfig(gz); !errors.As(err, &te) || gz != te.Path {
+		dbg.Error("MaxSize gzip: %v", err)
+		t.Fail()
+	}
+	if _, err = (Options{MaxSize: 10}).ParseReader(strings.NewReader("c := 3\nd := 4\n")); !errors.As(err, &te) {
+		dbg.Error("MaxSize reader: %v", err)
+		t.Fail()
+	}
+
+	// and to each included file
+	main := filepath.Join(dir, "main.cfg")
+	dbg.ChkErr(ioutil.WriteFile(main, []byte("@include big.cfg\n"), 0644), "WriteFile")
+	if _, err = (Options{MaxSize: 100}).LoadConfig(main); !errors.As(err, &te) || big != te.Path {
+		dbg.Error("MaxSize include: %v", err)
+		t.Fail()
+	}
+
+	gzMain := filepath.Join(dir, "gz.cfg")
+	dbg.ChkErr(ioutil.WriteFile(gzMain, []byte("@include big.cfg.gz\n"), 0644), "WriteFile")
+	if _, err = (Options{MaxSize: 1000}).LoadConfig(gzMain); !errors.As(err, &te) || gz != te.Path {
+		dbg.Error("MaxSize gzip include: %v", err)
+		t.Fail()
+	}
+	if _, _, err = (Options{MaxSize: 1000}).FileInclude(main, "big.cfg.gz"); !errors.As(err, &te) {
+		dbg.Error("MaxSize FileInclude: %v", err)
+		t.Fail()
+	}
+	env := filepath.Join(dir, ".env")
+	dbg.ChkErr(ioutil.WriteFile(env, []byte(strings.Repeat("A=1\n", 100)), 0644), "WriteFile")
+	if _, err = (Options{MaxSize: 100}).LoadDotEnv(env); !errors.As(err, &te) {
+		dbg.Error("MaxSize .env: %v", err)
+		t.Fail()
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("e := 5\n", 100))
+	}))
+	defer srv.Close()
+	_, err = LoadConfigURL(srv.URL, URLOptions{Options: Options{MaxSize: 100}})
+	if !errors.As(err, &te) || srv.URL != te.Path {
+		dbg.Error("MaxSize URL: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,247 @@
+package cfg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	/*
+		The severity of a lint Finding
+	*/
+	Severity int
+
+	/*
+		A Finding is a problem Lint found in config data, the line number
+		 is that of the line (counting from 1) it was found on
+	*/
+	Finding struct {
+		Line     int
+		Severity Severity
+		Message  string
+	}
+
+	// linter collects the findings of a single Lint
+	linter struct {
+		o        Options
+		findings []Finding
+	}
+)
+
+const (
+	SeverityError   Severity = iota // data that is rejected or lost
+	SeverityWarning                 // data that parses, but likely not as intended
+)
+
+func (s Severity) String() string {
+	if SeverityError == s {
+		return "error"
+	}
+	return "warning"
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("line %d: %s: %s", f.Line, f.Severity, f.Message)
+}
+
+/*
+	Checks the config data for likely mistakes without parsing it into a
+	 Config, returning the findings in line order:
+
+		errors      unmatched delimiters, an unended block, heredoc or
+		            multi-line value, lines of a ( ) group without an indent,
+		            a ',' separator with other than { or ':' with other than [
+		warnings    a TAB and space indented ( ) group, a label repeated
+		            within a group (outside @if sections) for an entry that
+		            isn't a group or items, trailing whitespace in a block
+
+	Comments, directives and the text of multi-line values are not checked
+*/
+func Lint(src []byte) []Finding {
+	return Options{}.Lint(src)
+}
+
+/*
+	As Lint, using the comment prefixes, space indent and line continuation
+	 of the options
+*/
+func (o Options) Lint(src []byte) []Finding {
+	str := normalizeEOL(strings.TrimPrefix(string(src), "\ufeff"))
+	l := &linter{o: o}
+	l.lint(strings.Split(strings.TrimSuffix(str, "\n"), "\n"), 1)
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		return l.findings[i].Line < l.findings[j].Line
+	})
+	return l.findings
+}
+
+// ------------------------------------------------------------------------- //
+
+func (l *linter) add(line int, s Severity, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{line, s, fmt.Sprintf(format, args...)})
+}
+
+// lint checks a single level of config data, the lines of a (data)
+// container having had their indent removed; first is the line number of
+// the first of the lines
+func (l *linter) lint(lines []string, first int) {
+	type seen struct {
+		line int
+		cond bool
+	}
+	labels := map[string]seen{}
+	cond := 0
+	label := func(name string, i int) {
+		if s, ok := labels[name]; ok && !s.cond && 0 == cond {
+			l.add(first+i, SeverityWarning, "Label %s repeated (line %d), only the last is used", name, s.line)
+		}
+		labels[name] = seen{first + i, 0 != cond}
+	}
+	// fenced finds the line ending a section, a line of just the tag
+	fenced := func(i int, tag string) int {
+		for j := i + 1; j < len(lines); j++ {
+			if tag == strings.TrimRight(lines[j], " \t") {
+				return j
+			}
+		}
+		l.add(first+i, SeverityError, "Missing end for %s, a line of %s", strings.TrimSpace(lines[i]), tag)
+		return len(lines)
+	}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+		if x := condRex.FindStringSubmatch(line); nil != x {
+			switch x[1] {
+			case "if":
+				cond++
+			case "end":
+				if cond > 0 {
+					cond--
+				}
+			}
+			continue
+		}
+		if hostRex.MatchString(line) {
+			// the lines of a @host group join this level once expanded
+			j := fenced(i, ")")
+			l.group(lines[i+1:j], first+i+1)
+			i = j
+			continue
+		}
+		switch {
+		case "" == trimmed || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "@") || l.o.isComment(line):
+			continue
+		case ' ' == line[0] || '\t' == line[0]:
+			continue
+		case 1 == len(line) && strings.Contains(">]})", line):
+			l.add(first+i, SeverityError, "Unmatched %s", line)
+			continue
+		}
+		if x := editValueRex.FindStringSubmatch(line); nil != x {
+			if strings.HasPrefix(x[2], "=") && "=" == strings.TrimSpace(x[2]) {
+				i = fenced(i, ":==")
+			}
+			label(x[1], i)
+			for l.o.LineContinuation && i < len(lines) && strings.HasSuffix(lines[i], "\\") && i+1 < len(lines) {
+				i++
+			}
+			continue
+		}
+		if x := editHeredocRex.FindStringSubmatch(line); nil != x {
+			j := fenced(i, x[2])
+			label(x[1], i)
+			l.trailing(x[1], lines, first, i, j)
+			i = j
+			continue
+		}
+		if x := formatInlineRex.FindStringSubmatch(line); nil != x && !strings.ContainsAny(x[1], "({") {
+			continue
+		}
+		if cs, sec := l.o.syntax().findSection(line + "\n"); nil != cs && 0 == cs[0] {
+			label(line[cs[2]:cs[3]], i)
+			i = fenced(i, sec.close)
+			continue
+		}
+		x := formatSectionRex.FindStringSubmatch(line)
+		if nil == x {
+			continue
+		}
+		name, opener, closer := strings.Join(strings.Fields(x[1]), " "), x[3], matching[x[3]]
+		switch {
+		case "," == x[2] && "{" != opener:
+			l.add(first+i, SeverityError, "A ',' separator is only used with { items }, not %s %s", name, opener)
+		case ":" == x[2] && "[" != opener:
+			l.add(first+i, SeverityError, "A ':' is only used with : [ dictionaries ], not %s %s", name, opener)
+		case ("b64" == x[4] || "hex" == x[4] || "|" == x[4]) && "<" != opener:
+			l.add(first+i, SeverityError, "%s is only used with < blocks, not %s %s", x[4], name, opener)
+		case "table" == x[4] && ("[" != opener || "" != x[2]):
+			l.add(first+i, SeverityError, "table is only used with [ lines ], not %s %s%s", name, x[2], opener)
+		}
+		j := i + 1
+		for ; j < len(lines); j++ {
+			if 1 == len(lines[j]) && strings.Contains(">]})", lines[j]) {
+				break
+			}
+		}
+		switch {
+		case j == len(lines):
+			l.add(first+i, SeverityError, "Unmatched %s for %s, missing a line of %s", opener, name, closer)
+		case closer != lines[j]:
+			l.add(first+j, SeverityError, "%s ends %s %s, expected %s", lines[j], name, opener, closer)
+		}
+		switch opener {
+		case "(":
+			l.group(lines[i+1:j], first+i+1)
+		case "<":
+			label(name, i)
+			l.trailing(name, lines, first, i, j)
+		case "[":
+			label(name, i)
+		}
+		i = j
+	}
+}
+
+// trailing warns of the lines of a block (between lines i & j) ending in
+// whitespace
+func (l *linter) trailing(name string, lines []string, first, i, j int) {
+	for k := i + 1; k < j && k < len(lines); k++ {
+		if strings.TrimRight(lines[k], " \t") != lines[k] {
+			l.add(first+k, SeverityWarning, "Trailing whitespace in block %s", name)
+		}
+	}
+}
+
+// group checks the indent of the lines of a ( ) group, then lints them
+// with it removed
+func (l *linter) group(lines []string, first int) {
+	indent := l.o.spaceIndent(strings.Join(lines, "\n"))
+	body := make([]string, len(lines))
+	kind, mixed := byte(0), false // the indent of the first indented line
+	for i, line := range lines {
+		switch {
+		case "" == strings.TrimSpace(line):
+			continue
+		case '\t' == line[0]:
+			body[i] = line[1:]
+		case "" != indent && strings.HasPrefix(line, indent):
+			body[i] = line[len(indent):]
+		case l.o.isComment(line):
+			continue
+		case ' ' == line[0] && '\t' == kind:
+			l.add(first+i, SeverityError, "Space indented line in a TAB indented ( ) group")
+			continue
+		default:
+			l.add(first+i, SeverityError, "Line of a ( ) group without a leading TAB or indent")
+			continue
+		}
+		if 0 == kind {
+			kind = line[0]
+		} else if kind != line[0] && !mixed {
+			l.add(first+i, SeverityWarning, "Mixed TAB and space indents in a ( ) group")
+			mixed = true
+		}
+	}
+	l.lint(body, first)
+}
@@ -0,0 +1,77 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestLint(t *testing.T) {
+	src := strings.Join([]string{
+		"name := a",       // 1
+		"name := b",       // 2 repeated
+		"@if os == linux", // 3
+		"log := /var/log", // 4
+		"@else",           // 5
+		"log := ./log",    // 6 not repeated, in an @if
+		"@end",            // 7
+		"hosts , [",       // 8 comma with [
+		"a",               // 9
+		"]",               // 10
+		"db (",            // 11
+		"\thost := x",     // 12
+		"    port := 1",   // 13 mixed indent
+		"\tnotes <",       // 14
+		"\ttrailing  ",    // 15 trailing whitespace
+		"\t>",             // 16
+		"\thost := y",     // 17 repeated
+		")",               // 18
+		"server (",        // 19
+		"\tport := 1",     // 20
+		")",               // 21
+		"server (",        // 22 repeated group is fine
+		"\tport := 2",     // 23
+		")",               // 24
+		"}",               // 25 unmatched
+		"list [",          // 26
+		"x",               // 27
+		")",               // 28 wrong closer
+		"bad (",           // 29
+		"unindented := 1", // 30
+		")",               // 31
+		"text <<EOF",      // 32
+		"never ended",     // 33
+	}, "\n")
+	want := []string{
+		"line 2: warning: Label name repeated (line 1), only the last is used",
+		"line 8: error: A ',' separator is only used with { items }, not hosts [",
+		"line 13: error: Space indented line in a TAB indented ( ) group",
+		"line 15: warning: Trailing whitespace in block notes",
+		"line 17: warning: Label host repeated (line 12), only the last is used",
+		"line 25: error: Unmatched }",
+		"line 28: error: ) ends list [, expected ]",
+		"line 30: error: Line of a ( ) group without a leading TAB or indent",
+		"line 32: error: Missing end for text <<EOF, a line of EOF",
+	}
+	got := []string{}
+	for _, f := range Lint([]byte(src)) {
+		got = append(got, f.String())
+	}
+	if strings.Join(want, "\n") != strings.Join(got, "\n") {
+		dbg.Error("Lint:\n%s", strings.Join(got, "\n"))
+		t.Fail()
+	}
+	if f := Lint([]byte("g (\n  a := 1\n\tb := 2\n  c := 3\n)\n")); 1 != len(f) || "line 3: warning: Mixed TAB and space indents in a ( ) group" != f[0].String() {
+		dbg.Error("Lint mixed: %v", f)
+		t.Fail()
+	}
+	if f := Lint([]byte("a <\nb\n")); 1 != len(f) || SeverityError != f[0].Severity || 1 != f[0].Line {
+		dbg.Error("Lint unended: %v", f)
+		t.Fail()
+	}
+	if f := Lint(conf); 2 != len(f) || "line 25: warning: Trailing whitespace in block block2" != f[0].String() || 95 != f[1].Line {
+		dbg.Error("Lint testBlocks: %v", f)
+		t.Fail()
+	}
+}
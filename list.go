@@ -0,0 +1,187 @@
+package cfg
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+type (
+	/*
+		A ListError records the failed conversion of a single element of a
+		 ConfigItems or ConfigLines entry
+	*/
+	ListError struct {
+		Path  string
+		Index int    // index of the element in the list
+		Text  string // original text of the element
+		Err   error
+	}
+)
+
+var (
+	ErrPercentSum = errors.New("Config percentages don't sum to 100%")
+)
+
+func (e *ListError) Error() string {
+	return e.Path + "[" + strconv.Itoa(e.Index) + "] \"" + e.Text + "\": " + e.Err.Error()
+}
+
+func (e *ListError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	Returns the entries of a ConfigItems or ConfigLines label path
+*/
+func (c *Config) GetStringList(path string) ([]string, error) {
+	l, err := c.list(path)
+	if nil != err {
+		return nil, err
+	}
+	return append([]string(nil), l...), nil
+}
+
+/*
+	Returns the rows of a ConfigTable label path, see LinesToTable
+*/
+func (c *Config) GetTable(path string) ([]map[string]string, error) {
+	n := c.node(c.opts.internalPath(path))
+	c.use(n, false)
+	if nil == n {
+		return nil, &PathError{path, ErrNoSuchLabel}
+	}
+	if ConfigTable != n.Type {
+		return nil, &PathError{path, ErrWrongType}
+	}
+	rows, err := LinesToTable(n.Data)
+	if le, ok := err.(*ListError); ok {
+		le.Path = path
+	}
+	return rows, err
+}
+
+/*
+	Returns the entries of each of the sub-lists of a ConfigItems label path,
+	 or of each repeated ConfigItems or ConfigLines entry with the label
+*/
+func (c *Config) GetStringLists(path string) ([][]string, error) {
+	nodes := c.LookupAll(path)
+	if 0 == len(nodes) {
+		return nil, &PathError{path, ErrNoSuchLabel}
+	}
+	result := make([][]string, len(nodes))
+	for i, n := range nodes {
+		if ConfigItems != n.Type && ConfigLines != n.Type {
+			return nil, &PathError{path, ErrWrongType}
+		}
+		result[i] = append([]string(nil), n.Data...)
+	}
+	return result, nil
+}
+
+/*
+	Returns the entries of a ConfigItems or ConfigLines label path converted
+	 to integers, see GetInt
+*/
+func (c *Config) GetIntList(path string) ([]int64, error) {
+	return convertList(c, path, func(s string) (int64, error) {
+		return parseInt(s, c.opts.DecimalOnly)
+	})
+}
+
+/*
+	Returns the entries of a ConfigItems or ConfigLines label path converted
+	 to floating point numbers
+*/
+func (c *Config) GetFloatList(path string) ([]float64, error) {
+	return convertList(c, path, func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	})
+}
+
+/*
+	Returns the entries of a ConfigItems or ConfigLines label path converted
+	 to durations, see time.ParseDuration
+*/
+func (c *Config) GetDurationList(path string) ([]time.Duration, error) {
+	return convertList(c, path, time.ParseDuration)
+}
+
+/*
+	Returns the entries of a ConfigItems or ConfigLines label path converted
+	 to numbers of bytes, see ParseSize, e.g. buffers { 4KiB, 64KiB, 1MiB }
+*/
+func (c *Config) GetSizeList(path string) ([]int64, error) {
+	return convertList(c, path, ParseSize)
+}
+
+/*
+	Returns the entries of a ConfigItems or ConfigLines label path converted
+	 from percentages, see ParsePercent; every entry needs its '%'
+*/
+func (c *Config) GetPercentList(path string) ([]float64, error) {
+	return convertList(c, path, ParsePercent)
+}
+
+/*
+	As GetPercentList for a list of shares that must sum to 100%, as in
+	 shares { 10%, 30%, 60% }, otherwise the error is a *PathError wrapping
+	 ErrPercentSum; a rounding difference of up to 0.001% is allowed
+*/
+func (c *Config) GetShares(path string) ([]float64, error) {
+	l, err := c.GetPercentList(path)
+	if nil != err {
+		return nil, err
+	}
+	sum := 0.0
+	for _, p := range l {
+		sum += p
+	}
+	if math.Abs(sum-100) > 0.001 {
+		return nil, &PathError{path, ErrPercentSum}
+	}
+	return l, nil
+}
+
+/*
+	Returns the entries of a ConfigItems or ConfigLines label path validated
+	 against the allowed set, see ParseEnum
+*/
+func (c *Config) GetEnumList(path string, allowed ...string) ([]string, error) {
+	return convertList(c, path, func(s string) (string, error) {
+		return ParseEnum(s, allowed...)
+	})
+}
+
+// ------------------------------------------------------------------------- //
+
+// list returns the raw entries of a ConfigItems or ConfigLines node
+func (c *Config) list(path string) ([]string, error) {
+	n := c.node(c.opts.internalPath(path))
+	c.use(n, false)
+	if nil == n {
+		return nil, &PathError{path, ErrNoSuchLabel}
+	}
+	if ConfigItems != n.Type && ConfigLines != n.Type {
+		return nil, &PathError{path, ErrWrongType}
+	}
+	return n.Data, nil
+}
+
+func convertList[T any](c *Config, path string, conv func(string) (T, error)) ([]T, error) {
+	l, err := c.list(path)
+	if nil != err {
+		return nil, err
+	}
+	result := make([]T, len(l))
+	for i, s := range l {
+		v, err := conv(s)
+		if nil != err {
+			return nil, &ListError{path, i, s, err}
+		}
+		result[i] = v
+	}
+	return result, nil
+}
@@ -0,0 +1,237 @@
+package cfg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	listTests = `
+timeout := 1m30s
+ports {
+	80 443 0x1F90
+}
+ratios , {
+	0.5, 1.25, 2
+}
+waits [
+	100ms
+	2s
+]
+log_level := Warn
+modes {
+	read WRITE
+}
+broken {
+	1 2 three 4
+}
+timeouts , { 500ms, 2s, 1m }
+buffers , { 4KiB, 1.5 KB, 2M }
+shares , { 10%, 30%, 60% }
+thirds , { 33.3333%, 33.3333%, 33.3334% }
+uneven , { 10%, 20% }
+unmarked , { 50%, 50 }
+`
+)
+
+func TestTypedLists(t *testing.T) {
+	c, _ := Parse(listTests)
+	if d, err := c.GetDuration("timeout"); nil != err || d != 90*time.Second {
+		dbg.Error("timeout: %v %v", d, err)
+		t.Fail()
+	}
+	if l, err := c.GetIntList("ports"); nil != err || len(l) != 3 || l[2] != 8080 {
+		dbg.Error("ports: %v %v", l, err)
+		t.Fail()
+	}
+	if l, err := c.GetFloatList("ratios"); nil != err || len(l) != 3 || l[1] != 1.25 {
+		dbg.Error("ratios: %v %v", l, err)
+		t.Fail()
+	}
+	if l, err := c.GetDurationList("waits"); nil != err || len(l) != 2 || l[0] != 100*time.Millisecond {
+		dbg.Error("waits: %v %v", l, err)
+		t.Fail()
+	}
+	if l, err := c.GetStringList("broken"); nil != err || !compareEntries(l, []string{"1", "2", "three", "4"}) {
+		dbg.Error("broken: %v %v", l, err)
+		t.Fail()
+	}
+	var le *ListError
+	if _, err := c.GetIntList("broken"); !errors.As(err, &le) || le.Index != 2 || le.Text != "three" {
+		dbg.Error("broken: %v", err)
+		t.Fail()
+	}
+	if _, err := c.GetIntList("timeout"); !errors.Is(err, ErrWrongType) {
+		dbg.Error("timeout: %v", err)
+		t.Fail()
+	}
+}
+
+func TestUnitLists(t *testing.T) {
+	c, _ := Parse(listTests)
+	if l, err := c.GetDurationList("timeouts"); nil != err || len(l) != 3 || l[0] != 500*time.Millisecond || l[2] != time.Minute {
+		dbg.Error("timeouts: %v %v", l, err)
+		t.Fail()
+	}
+	if l, err := c.GetSizeList("buffers"); nil != err || len(l) != 3 || l[0] != 4096 || l[1] != 1500 || l[2] != 2000000 {
+		dbg.Error("buffers: %v %v", l, err)
+		t.Fail()
+	}
+	if l, err := c.GetPercentList("shares"); nil != err || len(l) != 3 || l[1] != 30 {
+		dbg.Error("shares: %v %v", l, err)
+		t.Fail()
+	}
+	for _, p := range []string{"shares", "thirds"} {
+		if l, err := c.GetShares(p); nil != err || len(l) != 3 {
+			dbg.Error("%s: %v %v", p, l, err)
+			t.Fail()
+		}
+	}
+	if _, err := c.GetShares("uneven"); !errors.Is(err, ErrPercentSum) {
+		dbg.Error("uneven: %v", err)
+		t.Fail()
+	}
+	if l, err := c.GetPercentList("uneven"); nil != err || len(l) != 2 {
+		dbg.Error("uneven: %v %v", l, err)
+		t.Fail()
+	}
+	var le *ListError
+	if _, err := c.GetShares("unmarked"); !errors.As(err, &le) || le.Index != 1 || le.Text != "50" {
+		dbg.Error("unmarked: %v", err)
+		t.Fail()
+	}
+	if _, err := c.GetSizeList("timeouts"); !errors.As(err, &le) || le.Index != 0 {
+		dbg.Error("timeouts: %v", err)
+		t.Fail()
+	}
+	if v, err := ParsePercent(" 12.5 % "); nil != err || v != 12.5 {
+		dbg.Error("ParsePercent: %v %v", v, err)
+		t.Fail()
+	}
+}
+
+func TestEnums(t *testing.T) {
+	c, _ := Parse(listTests)
+	if v, err := c.GetEnum("log_level", "debug", "info", "warn"); nil != err || v != "warn" {
+		dbg.Error("log_level: %s %v", v, err)
+		t.Fail()
+	}
+	if _, err := c.GetEnum("log_level", "debug", "info"); nil == err {
+		dbg.Error("log_level should fail")
+		t.Fail()
+	}
+	if l, err := c.GetEnumList("modes", "read", "write"); nil != err || !compareEntries(l, []string{"read", "write"}) {
+		dbg.Error("modes: %v %v", l, err)
+		t.Fail()
+	}
+	var le *ListError
+	if _, err := c.GetEnumList("modes", "read"); !errors.As(err, &le) || le.Index != 1 {
+		dbg.Error("modes: %v", err)
+		t.Fail()
+	}
+}
+
+func TestStringLists(t *testing.T) {
+	src := `
+hostTags {
+	(web prod)
+	{
+		db
+		prod
+	}
+	cache
+}
+matrix , {
+	(1, 2, 3)
+	(4, 5, 6)
+}
+`
+	c, err := Options{}.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	l, err := c.GetStringLists("hostTags")
+	if nil != err || 3 != len(l) || "web prod" != strings.Join(l[0], " ") || "db prod" != strings.Join(l[1], " ") || "cache" != l[2][0] {
+		dbg.Error("hostTags: %v %v", l, err)
+		t.Fail()
+	}
+	if v, _ := c.GetStringList("hostTags[1]"); 2 != len(v) || "db" != v[0] {
+		dbg.Error("hostTags[1]: %v", v)
+		t.Fail()
+	}
+	m, err := Get[[][]int](c, "matrix")
+	if nil != err || 2 != len(m) || 6 != m[1][2] {
+		dbg.Error("matrix: %v %v", m, err)
+		t.Fail()
+	}
+	got := []string{}
+	err = Options{IndexRepeats: true}.HandleConfigData(src, func(ct ConfigType, label string, data []string) {
+		got = append(got, label+"="+strings.Join(data, " "))
+	})
+	if nil != err || 5 != len(got) || "matrix[1]=4 5 6" != got[4] {
+		dbg.Error("IndexRepeats: %v %v", got, err)
+		t.Fail()
+	}
+}
+
+func TestTables(t *testing.T) {
+	src := `
+routes [table
+	# routes served by the proxy
+	path     target             weight
+	/api     http://api:8080    2
+	/        http://web:80
+]
+users [table
+	name, full name
+	jd, Jay Doe
+]
+bad [table
+	a b
+	1 2 3
+]
+`
+	c, err := Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	rows, err := c.GetTable("routes")
+	if nil != err || 2 != len(rows) || "http://api:8080" != rows[0]["target"] || "" != rows[1]["weight"] {
+		dbg.Error("routes: %v %v", rows, err)
+		t.Fail()
+	}
+	if rows, _ = c.GetTable("users"); 1 != len(rows) || "Jay Doe" != rows[0]["full name"] {
+		dbg.Error("users: %v", rows)
+		t.Fail()
+	}
+	var le *ListError
+	if _, err = c.GetTable("bad"); !errors.As(err, &le) || "bad" != le.Path || 0 != le.Index || !errors.Is(err, ErrTableColumns) {
+		dbg.Error("bad: %v", err)
+		t.Fail()
+	}
+
+	type route struct {
+		Path   string
+		Target string `cfg:"target"`
+		Weight int
+	}
+	routes, err := Get[[]route](c, "routes")
+	if nil != err || 2 != len(routes) || 2 != routes[0].Weight || "/" != routes[1].Path {
+		dbg.Error("Get routes: %v %v", routes, err)
+		t.Fail()
+	}
+	if _, err = Get[[]route](c, "users"); nil != err {
+		dbg.Error("Get users: %v", err)
+		t.Fail()
+	}
+	if c, _ = Parse("t , [table\n\ta\n]\n"); nil != c.Lookup("t") {
+		dbg.Error("illegal table was parsed")
+		t.Fail()
+	}
+}
@@ -0,0 +1,125 @@
+package cfg
+
+type (
+	/*
+		A MergeMode is how Merge combines an entry of the source config with
+		 the entry of the destination it shares a label path with
+	*/
+	MergeMode int
+
+	/*
+		A MergeStrategy gives the MergeModes used by Merge; the mode of the
+		 longest Paths pattern (see MatchPath) matching an entry's label path
+		 is used for it, MergeDeep if none match:
+
+			cfg.MergeStrategy{
+				Lists: cfg.MergeAppend,
+				Paths: map[string]cfg.MergeMode{
+					"servers:*": cfg.MergeAppend,
+					"tls":       cfg.MergeReplace,
+				},
+			}
+	*/
+	MergeStrategy struct {
+		Lists MergeMode            // MergeAppend adds to items, lines and dictionaries merged by MergeDeep
+		Paths map[string]MergeMode // label path pattern -> mode
+	}
+)
+
+const (
+	MergeDeep    MergeMode = iota // groups are merged entry by entry, anything else is replaced
+	MergeReplace                  // the entry is replaced, a group with all of its entries
+	MergeAppend                   // lists are appended to, a group is added as another of its label
+	MergeKeep                     // the destination's entry is kept
+)
+
+/*
+	Merges the entries of src into dst; entries dst doesn't have are added
+	 and the others combined by the MergeMode the strategy gives for their
+	 label path.  Repeated groups are merged in order, the first src group
+	 of a label into the first dst group and so on.  Only MergeReplace and
+	 MergeKeep allow a group to meet a value, otherwise each is a *PathError
+	 wrapping ErrWrongType and, as a *MultiError for more than one, dst is
+	 left unchanged
+*/
+func Merge(dst, src *Config, strategy MergeStrategy) error {
+	merged, from := dst.Clone(), src.Clone()
+	errs := []error(nil)
+	mergeGroup(&merged.root, &from.root, strategy, &errs)
+	if 0 != len(errs) {
+		return joinErrors(errs)
+	}
+	dst.root = merged.root
+	for _, n := range dst.root.Children {
+		n.parent = &dst.root
+	}
+	dst.reindex()
+	return nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// mergeGroup merges the entries of the src group into the dst group, moving
+// those added from src to dst
+func mergeGroup(dst, src *Node, s MergeStrategy, errs *[]error) {
+	seen := make(map[string]int)
+	for _, n := range src.Children {
+		if ConfigComment == n.Type {
+			continue
+		}
+		i := nthChild(dst, n.Label, seen[n.Label])
+		seen[n.Label]++
+		mode := s.mode(n.Path)
+		if i < 0 {
+			n.parent = dst
+			dst.Children = append(dst.Children, n)
+			continue
+		}
+		old := dst.Children[i]
+		switch {
+		case MergeKeep == mode:
+		case MergeReplace == mode:
+			n.parent = dst
+			dst.Children[i] = n
+		case ConfigGroup == old.Type && ConfigGroup == n.Type:
+			if MergeAppend == mode {
+				n.parent = dst
+				dst.Children = append(dst.Children, n)
+			} else {
+				mergeGroup(old, n, s, errs)
+			}
+		case ConfigGroup == old.Type || ConfigGroup == n.Type:
+			*errs = append(*errs, &PathError{n.Path, ErrWrongType})
+		case old.Type == n.Type && (ConfigItems == n.Type || ConfigLines == n.Type || ConfigDict == n.Type) &&
+			(MergeAppend == mode || MergeDeep == mode && MergeAppend == s.Lists):
+			old.Data = append(old.Data[:len(old.Data):len(old.Data)], n.Data...)
+		default:
+			old.Type, old.Data = n.Type, n.Data
+		}
+	}
+}
+
+// nthChild returns the index of the nth non comment child of the group with
+// the label, -1 if it has fewer
+func nthChild(g *Node, label string, nth int) int {
+	for i, n := range g.Children {
+		if label == n.Label && ConfigComment != n.Type {
+			if 0 == nth {
+				return i
+			}
+			nth--
+		}
+	}
+	return -1
+}
+
+// mode returns the mode of the longest pattern matching the label path
+func (s MergeStrategy) mode(path string) MergeMode {
+	mode, best := MergeDeep, ""
+	for p, m := range s.Paths {
+		if (len(p) > len(best) || len(p) == len(best) && p < best) && MatchPath(p, path) {
+			mode, best = m, p
+		}
+	}
+	return mode
+}
@@ -0,0 +1,69 @@
+package cfg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestMerge(t *testing.T) {
+	base := "name := app\nhosts { a b }\ndb (\n\thost := x\n\tport := 5432\n)\n" +
+		"servers (\n\tweb (\n\t\tports { 80 }\n\t)\n)\ntls (\n\tcert := a.pem\n\tkey := a.key\n)\n"
+	over := "name := svc\nhosts { c }\ndb (\n\thost := y\n)\n" +
+		"servers (\n\tweb (\n\t\tports { 443 }\n\t)\n)\ntls (\n\tcert := b.pem\n)\nlog := info\n"
+	tests := []struct {
+		strategy MergeStrategy
+		expect   map[string]string
+	}{
+		{MergeStrategy{}, map[string]string{
+			"name": "svc", "hosts": "c", "db:host": "y", "db:port": "5432",
+			"servers:web:ports": "443", "tls:cert": "b.pem", "tls:key": "a.key", "log": "info",
+		}},
+		{MergeStrategy{Lists: MergeAppend}, map[string]string{
+			"hosts": "a,b,c", "servers:web:ports": "80,443",
+		}},
+		{MergeStrategy{Paths: map[string]MergeMode{"servers:*": MergeAppend, "tls": MergeReplace, "name": MergeKeep}},
+			map[string]string{
+				"name": "app", "hosts": "c", "servers:web[0]:ports": "80", "servers:web[1]:ports": "443",
+				"tls:cert": "b.pem", "tls:key": "",
+			}},
+	}
+	for i, test := range tests {
+		dst, _ := Parse(base)
+		src, _ := Parse(over)
+		if err := Merge(dst, src, test.strategy); nil != err {
+			dbg.Error("Merge %d: %v", i, err)
+			t.Fail()
+			continue
+		}
+		flat := map[string]string{}
+		for p, n := range dst.entries() {
+			flat[p] = strings.Join(n.Data, ",")
+		}
+		for p, v := range test.expect {
+			if v != flat[p] {
+				dbg.Error("Merge %d: %s is %q, expected %q", i, p, flat[p], v)
+				t.Fail()
+			}
+		}
+		if "y" != src.ValueOr("db:host", "") || nil == dst.Lookup("log") {
+			dbg.Error("Merge %d: lookups of the merged config failed", i)
+			t.Fail()
+		}
+	}
+
+	dst, _ := Parse("db := x\nname := app\n")
+	src, _ := Parse("db (\n\thost := y\n)\nname := svc\n")
+	err := Merge(dst, src, MergeStrategy{})
+	if !errors.Is(err, ErrWrongType) || "app" != dst.ValueOr("name", "") {
+		dbg.Error("Merge of a group over a value: %v", err)
+		t.Fail()
+	}
+	if err = Merge(dst, src, MergeStrategy{Paths: map[string]MergeMode{"db": MergeReplace}}); nil != err ||
+		"y" != dst.ValueOr("db:host", "") {
+		dbg.Error("Merge replacing a value with a group: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,101 @@
+package cfg
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+)
+
+var (
+	ErrRelativeURL = errors.New("URL is missing a scheme")
+)
+
+/*
+	Returns the value for the label path as an IPv4 or IPv6 address
+*/
+func (c *Config) GetIP(path string) (net.IP, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return nil, err
+	}
+	ip := net.ParseIP(v)
+	if nil == ip {
+		return nil, &PathError{path, &net.ParseError{Type: "IP address", Text: v}}
+	}
+	return ip, nil
+}
+
+/*
+	Returns the value for the label path as an IP network in CIDR notation,
+	 e.g. "192.168.0.0/16" or "2001:db8::/32"
+*/
+func (c *Config) GetCIDR(path string) (*net.IPNet, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return nil, err
+	}
+	_, ipNet, err := net.ParseCIDR(v)
+	if nil != err {
+		return nil, &PathError{path, err}
+	}
+	return ipNet, nil
+}
+
+/*
+	Returns the value for the label path as an IP address and port, e.g.
+	 "10.0.0.1:80" or "[::1]:8080"
+*/
+func (c *Config) GetAddrPort(path string) (netip.AddrPort, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return netip.AddrPort{}, err
+	}
+	ap, err := netip.ParseAddrPort(v)
+	if nil != err {
+		return netip.AddrPort{}, &PathError{path, err}
+	}
+	return ap, nil
+}
+
+/*
+	Returns the value for the label path split into host and port, unlike
+	 GetAddrPort the host may be a name, e.g. "db.example.com:5432"
+
+	The port must be numeric and in the range 0-65535
+*/
+func (c *Config) GetHostPort(path string) (string, int, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return "", 0, err
+	}
+	host, p, err := net.SplitHostPort(v)
+	if nil != err {
+		return "", 0, &PathError{path, err}
+	}
+	port, err := strconv.ParseUint(p, 10, 16)
+	if nil != err {
+		return "", 0, &PathError{path, err}
+	}
+	return host, int(port), nil
+}
+
+/*
+	Returns the value for the label path as an absolute URL, the URL must
+	 have a scheme, e.g. "https://example.com/api"
+*/
+func (c *Config) GetURL(path string) (*url.URL, error) {
+	v, err := c.value(path)
+	if nil != err {
+		return nil, err
+	}
+	u, err := url.Parse(v)
+	if nil != err {
+		return nil, &PathError{path, err}
+	}
+	if "" == u.Scheme {
+		return nil, &PathError{path, ErrRelativeURL}
+	}
+	return u, nil
+}
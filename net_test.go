@@ -0,0 +1,71 @@
+package cfg
+
+import (
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	netTests = `
+server (
+	ip := 10.1.2.3
+	ip6 := ::1
+	subnet := 192.168.0.0/16
+	listen := [::1]:8080
+	db := db.example.com:5432
+	api := https://example.com/api?v=1
+	relative := /just/a/path
+	bogus := 300.1.1.1
+	badport := example.com:99999
+)
+`
+)
+
+func TestNetAccessors(t *testing.T) {
+	c, err := Parse(netTests)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if ip, err := c.GetIP("server:ip"); nil != err || ip.String() != "10.1.2.3" {
+		dbg.Error("server:ip: %v %v", ip, err)
+		t.Fail()
+	}
+	if ip, err := c.GetIP("server:ip6"); nil != err || ip.String() != "::1" {
+		dbg.Error("server:ip6: %v %v", ip, err)
+		t.Fail()
+	}
+	if _, err := c.GetIP("server:bogus"); nil == err {
+		dbg.Error("server:bogus should fail")
+		t.Fail()
+	}
+	if n, err := c.GetCIDR("server:subnet"); nil != err || n.String() != "192.168.0.0/16" {
+		dbg.Error("server:subnet: %v %v", n, err)
+		t.Fail()
+	}
+	if ap, err := c.GetAddrPort("server:listen"); nil != err || ap.Port() != 8080 {
+		dbg.Error("server:listen: %v %v", ap, err)
+		t.Fail()
+	}
+	if _, err := c.GetAddrPort("server:db"); nil == err {
+		dbg.Error("server:db is not an AddrPort")
+		t.Fail()
+	}
+	if h, p, err := c.GetHostPort("server:db"); nil != err || h != "db.example.com" || p != 5432 {
+		dbg.Error("server:db: %s %d %v", h, p, err)
+		t.Fail()
+	}
+	if _, _, err := c.GetHostPort("server:badport"); nil == err {
+		dbg.Error("server:badport should fail")
+		t.Fail()
+	}
+	if u, err := c.GetURL("server:api"); nil != err || u.Host != "example.com" {
+		dbg.Error("server:api: %v %v", u, err)
+		t.Fail()
+	}
+	if _, err := c.GetURL("server:relative"); nil == err {
+		dbg.Error("server:relative should fail")
+		t.Fail()
+	}
+}
@@ -0,0 +1,934 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/txt"
+)
+
+type (
+	/*
+		Options alter how config data is parsed and how the values of the
+		 resulting Config are converted.  The zero value gives the default
+		 behavior used by the package level functions.
+	*/
+	Options struct {
+		// Only accept decimal integers, rejecting 0x, 0o and 0b prefixes
+		DecimalOnly bool
+
+		// Prefixes marking a comment line, e.g. []string{"#", ";", "//"};
+		//  when nil only '#' is used.  Comment lines are removed from lines,
+		//  items and dictionary data and, unlike '#' by default, may start
+		//  a line inside a (data) container without the leading TAB
+		CommentPrefixes []string
+
+		// Values wrapped in double or single quotes have the quotes removed,
+		//  keeping any whitespace inside them, e.g. label := "  padded  "
+		QuotedValues bool
+
+		// Items of { } sections (inline ones included) wrapped in double
+		//  or single quotes are single items, keeping any separator or
+		//  whitespace inside them, e.g. cities , { "new york", boston };
+		//  the quotes are removed as with QuotedValues, and WriteTo quotes
+		//  the items that need it
+		QuotedItems bool
+
+		// Ranges in the items of { } sections are expanded into the items
+		//  they stand for, e.g. 8000-8010 or node[01-20], see ExpandRanges;
+		//  a range that can't be expanded fails the parse
+		ExpandRanges bool
+
+		// The most items the ranges of a single items entry expand to with
+		//  ExpandRanges, 0 for DefaultMaxRangeItems
+		MaxRangeItems int
+
+		// Disables the processing of escape sequences, e.g. \n, \t, \\ and
+		//  \uXXXX, inside double quoted values; single quoted values are
+		//  always raw
+		NoEscapes bool
+
+		// A value ending with a '\' continues on the next line, the '\' and
+		//  the leading whitespace of the next line are removed, e.g.
+		//	command := /usr/bin/foo --flag-a \
+		//		--flag-b
+		//  gives the value "/usr/bin/foo --flag-a --flag-b"
+		LineContinuation bool
+
+		// Resolves '@include path' lines, see IncludeFunc; Load* default to
+		//  FileInclude while Parse & Handle* leave includes untouched unless
+		//  this is set
+		Include IncludeFunc
+
+		// The maximum nesting of includes, 0 uses DefaultMaxIncludeDepth;
+		//  exceeding it, or an include cycle, returns an *IncludeError
+		MaxIncludeDepth int
+
+		// Returns the name of the machine matched by @host groups and @if
+		//  hostname conditions, nil for os.Hostname; e.g. to test the
+		//  settings of each role of a fleet
+		Hostname func() string
+
+		// Replace ${label:path} references inside values with the referenced
+		//  ConfigValue or ConfigBlock; the label path is looked for relative
+		//  to the enclosing group then each of its parents, e.g.
+		//	base := /srv/app
+		//	logs := ${base}/logs
+		//  A $${ gives a literal ${; an unknown or circular reference returns
+		//  a *ReferenceError
+		Interpolate bool
+
+		// Replace each value that's an arithmetic expression with its result,
+		//  once any references are interpolated, e.g.
+		//	workers := ${cpus} * 2
+		//	cache   := 64MB * 4
+		//	timeout := max(5s, 100ms * 20)
+		//  Numbers may have a size unit (giving bytes) or a duration unit,
+		//  the binary operators + - * / % need spaces around them and only
+		//  abs, ceil, floor, round, min, max & cpus() can be called; values
+		//  that aren't expressions are left alone, while one that can't be
+		//  evaluated (e.g. adding a size to a duration) is a *PathError
+		Expressions bool
+
+		// Decrypts each @enc(id:data) value, as written by EncryptValue,
+		//  with the AES key of the id when the config is parsed, before any
+		//  references are interpolated; a value that can't be decrypted is
+		//  a *PathError.  The values decrypted are sensitive (see
+		//  Sensitive) and written encrypted by WriteTo.  When nil @enc
+		//  values are left as they are
+		Keys KeyProvider
+
+		// Resolves each @secret(scheme:ref) value when it's read, with the
+		//  resolver registered for the scheme (see RegisterSecretResolver);
+		//  otherwise such values are left as they are, so a config from an
+		//  untrusted source can't read local files or the environment
+		Secrets bool
+
+		// HandleConfigData passes the entries of a label repeated within a
+		//  group with indexed label paths, e.g. for two "server ( ... )" groups
+		//	server[0]:host    server[1]:host
+		//  rather than the same "server:host" path for both
+		IndexRepeats bool
+
+		// What happens when a label is found more than once in the same
+		//  group (repeated groups are always kept), e.g. DuplicatesError to
+		//  catch a key accidentally set twice; the zero value keeps each
+		//  entry, passing all of them to HandleConfigData handlers
+		Duplicates DuplicatePolicy
+
+		// Allow labels (and dictionary keys) of any Unicode letters, marks
+		//  and digits rather than just the ASCII word characters, e.g.
+		//	сервер := localhost
+		UnicodeLabels bool
+
+		// Additional characters allowed in labels (and dictionary keys), e.g.
+		//  "-." for INI / properties style keys such as log-level and
+		//  db.primary.host.  A ':' (or PathSeparator) always separates the
+		//  labels of a path, so db.primary.host is a single label looked up
+		//  as is, never as a path
+		LabelChars string
+
+		// The separator of the labels of the paths passed by
+		//  HandleConfigData, taken by the Config accessors and used by
+		//  Flatten, ${...} references and Options.MatchPath, e.g. '.' or
+		//  '/', 0 for ':'; a backslash escapes a separator that's part of a
+		//  label or dictionary key, see SplitPath.  Other options and the
+		//  paths of Nodes, Changes & errors always use ':'
+		PathSeparator rune
+
+		// Renamed label paths, old -> new, so existing config files keep
+		//  working; an old group path also renames everything in the group,
+		//  e.g. with {"db": "database"} the db:host entry becomes
+		//  database:host.  Applies to HandleConfigData and Parse
+		Aliases map[string]string
+
+		// Log a deprecation notice for each entry found by an old path
+		WarnAliases bool
+
+		// Label paths that are no longer used, each found by Parse or
+		//  HandleConfigData is passed to OnDeprecated and recorded in the
+		//  Config's Deprecations; an Aliases entry can also move it
+		Deprecated []Deprecation
+
+		// Called with each of the Deprecated label paths found, nil logs
+		//  them with dbg.Info
+		OnDeprecated func(d Deprecation)
+
+		// Parse keeps the comments, blank lines and any other text between
+		//  the entries of each group as ConfigComment nodes, so the config
+		//  is written back out (see WriteTo) with its documentation intact;
+		//  comments inside lines, items and dictionaries are still removed
+		KeepComments bool
+
+		// The comment lines of [ ] lines and { } items sections are kept, as
+		//  written less their TABs, so programs rewriting a config, or with
+		//  crontab-like data where they matter, don't lose them; IsComment
+		//  tells them from the data.  An items comment line is a single item
+		//  (a sub-list of its own in a section of sub-lists)
+		ListComments bool
+
+		// The blank lines of [ ] lines sections are kept as "" entries, for
+		//  data using them as record separators
+		KeepBlankLines bool
+
+		// The common indent of the lines of every < > block and heredoc
+		//  is removed (see Dedent), so blocks can be indented to read well
+		//  inside nested groups; a single block opened with <| is dedented
+		//  without it
+		DedentBlocks bool
+
+		// A line of a < > block or [ ] lines section starting with a '\'
+		//  has it removed, so a block can hold a line of just '>' (written
+		//  '\>') and a lines entry can be blank, start with a comment
+		//  prefix or keep its surrounding spaces (written '\  text  '); a
+		//  line really starting with '\' is written '\\'.  WriteTo escapes
+		//  such lines in turn, so any text round-trips.  Heredocs are raw
+		SectionEscapes bool
+
+		// Label path patterns of secrets, e.g. "*:password" or "auth:token",
+		//  each label matched as path.Match does; the String of a parsed
+		//  Config (and an Encoder with Redact set) shows ***** for the
+		//  values of matching entries, and of everything in a matching group
+		Sensitive []string
+
+		// The number of spaces indenting the lines inside a (data) container
+		//  when they aren't TAB indented; 0 uses the indent of the first
+		//  indented line, so each line needs either a TAB or that indent
+		IndentSpaces int
+
+		// When merging one config over another (ParseFiles, profiles and
+		//  Layers) items, lines and dictionaries are appended to those of
+		//  the same label path rather than replacing them; values, blocks
+		//  and tables are always replaced
+		AppendLists bool
+
+		// The time between the checks of a file for changes by Watch, 0
+		//  for DefaultWatchInterval
+		WatchInterval time.Duration
+
+		// The time a Reloader waits after a change for the writes to the
+		//  file to finish, 0 for DefaultReloadDelay
+		ReloadDelay time.Duration
+
+		// The time a Reloader waits after a Set for any more before
+		//  writing them to the file, 0 for DefaultWriteDelay
+		WriteDelay time.Duration
+
+		// Record each entry read by the accessors with the time and the
+		//  code that read it, see Config.AccessLog; the log grows for as
+		//  long as the config is used
+		AuditAccess bool
+
+		// When set, LoadConfig & LoadConfigProfile return a copy of the
+		//  config parsed before for a file (and any it includes) that
+		//  hasn't changed since, rather than parsing it again; a cache
+		//  should only be used with a single set of options
+		Cache *ParseCache
+
+		// The largest config file (or URL or Source data) in bytes the
+		//  Load* functions read, before and after it's decompressed; a
+		//  larger one gives a TooLargeError, 0 for no limit
+		MaxSize int64
+
+		// When set, Unmarshal & Get return an *UnknownError for entries
+		//  of a group without a matching struct field, and Schema.Validate
+		//  reports label paths the schema doesn't declare, so typos such
+		//  as 'timout := 5s' aren't silently ignored
+		Strict bool
+
+		// The number of goroutines ParseFilesParallel and ParallelSections
+		//  use at once, 0 for GOMAXPROCS
+		Workers int
+
+		// When set, Parse & LoadConfig parse the top level (data)
+		//  containers concurrently, for large files of many sections;
+		//  the tree built is the same
+		ParallelSections bool
+
+		// When set, values are given the type InferValue finds them to be
+		//  (int64, float64, bool, time.Duration or string) by ToMap,
+		//  FlattenTyped and MarshalJSON, and when decoded into an
+		//  interface{}, rather than always being strings
+		InferTypes bool
+
+		// When set, a section missing its end char, or ended by the wrong
+		//  one, is skipped and parsing resumes at the next label of its
+		//  group; Parse & LoadConfig return the config read along with a
+		//  *PathError wrapping ErrUnendedSection or ErrMismatchedEnd for
+		//  each section skipped (a *MultiError for more than one), as
+		//  HandleConfigData does once the rest is handled
+		Recover bool
+
+		// Checks of the whole config run once it's parsed (and merged, by
+		//  ParseFiles & profiles), after any registered validators; every
+		//  rule is run and the errors of those failing are returned
+		//  together as a *RuleError, see Requires and Ordered
+		Rules []Rule
+
+		// Called as the config data is parsed, by Parse, LoadConfig and
+		//  HandleConfigData (and the functions using them), see Hooks
+		Hooks *Hooks
+
+		// Config data every config is parsed over, e.g. defaults compiled
+		//  in with go:embed, see WithDefaults: the entries of the config
+		//  replace those of the defaults with the same label path (as a
+		//  profile's do), so the accessors, Unmarshal and rules see the
+		//  defaults of whatever the config doesn't set, and Deviations
+		//  lists what it changes
+		Defaults []byte
+
+		// Counts the configs parsed, their sections, values and bytes and
+		//  the time taken, for monitoring; one Metrics can be shared by
+		//  every Options of a program
+		Metrics *Metrics
+
+		// called with each file read (along with its text) & directory
+		//  globbed, see ParseCache and Config.Stale
+		seen func(path, text string)
+
+		// records the top level sections when ParallelSections is set
+		sections *sectionRecorder
+
+		// the malformed sections skipped when Recover is set
+		recovered *[]error
+
+		// set for data parsed to be merged, validated once it's merged
+		partial bool
+	}
+)
+
+/*
+	Parses the config data into a Config tree using these options
+*/
+func (o Options) Parse(str string) (*Config, error) {
+	str, err := o.preprocess(nil, str)
+	if nil != err {
+		return nil, err
+	}
+	return o.parse(str)
+}
+
+/*
+	Reads the config file and parses it into a Config tree using these options
+*/
+func (o Options) LoadConfig(flPath string) (*Config, error) {
+	if nil != o.Cache && Stdin != flPath {
+		return o.Cache.load(o, flPath, flPath, func(o Options) (*Config, error) {
+			o.Cache = nil
+			return o.LoadConfig(flPath)
+		})
+	}
+	o, loaded := o.loading(flPath)
+	data, err := o.readConfig(flPath)
+	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
+		return nil, err
+	}
+	c, err := o.parse(data)
+	if nil == c {
+		return nil, err
+	}
+	loaded(c)
+	return c, err
+}
+
+/*
+	As HandleConfigData, using these options
+*/
+func (o Options) HandleConfigData(str string, f func(t ConfigType, label string, data []string)) error {
+	str, err := o.preprocess(nil, str)
+	if nil != err {
+		return err
+	}
+	if handle := f; 0 != o.PathSeparator && ':' != o.PathSeparator {
+		f = func(t ConfigType, label string, data []string) {
+			handle(t, o.externalPath(label), data)
+		}
+	}
+	if o.Interpolate || o.Expressions || o.IndexRepeats || DuplicatesAllowed != o.Duplicates || nil != o.Keys {
+		// references may be to later values, repeats are only known once
+		// the whole group is seen and values must be decrypted, so parse
+		// everything first
+		c, err := o.parse(str)
+		if nil == c {
+			return err
+		}
+		if o.IndexRepeats {
+			c.indexed(&c.root, "", f)
+			return err
+		}
+		c.flatten(&c.root, func(n *Node) {
+			f(n.Type, n.Path, n.Data)
+		})
+		return err
+	}
+	var recovered []error
+	if o.Recover {
+		o.recovered = &recovered
+	}
+	f = o.deprecations(nil, o.aliased(f))
+	handle, done := o.trace(str, func(t ConfigType, label string, data []string) {
+		if ConfigGroup != t && ConfigComment != t && configAttributes != t {
+			f(t, label, data)
+		}
+	})
+	if err := o.walk("", str, handle); nil != err {
+		return done(err)
+	}
+	return done(joinErrors(recovered))
+}
+
+/*
+	As HandleConfigValues, using these options
+*/
+func (o Options) HandleConfigValues(str string, f func(label, value string)) {
+	o.values(normalizeEOL(str), f, nil)
+}
+
+// values passes each 'label := value' to f and, when skip is set, any other
+// text (comments, blank lines etc) before, between and after them to skip
+func (o Options) values(str string, f func(label, value string), skip func(text string)) {
+	rx := o.syntax().value
+	for x := rx.FindStringSubmatchIndex(str); nil != x; x = rx.FindStringSubmatchIndex(str) {
+		if nil != skip && x[2] > 0 {
+			skip(str[:x[2]])
+		}
+		label, value, rest := str[x[2]:x[3]], str[x[4]:x[5]], str[x[6]:]
+		if "=" == value && ":=" == str[x[4]-2:x[4]] {
+			v, r, ok := multiLineValue(rest)
+			if !ok {
+				dbg.Error("Illegal multi-line value: %s :== -- missing :== or leading TAB", label)
+				str = rest
+				continue
+			}
+			f(label, v)
+			str = r
+			continue
+		}
+		if o.LineContinuation {
+			value, rest = continueValue(value, rest)
+		}
+		f(label, o.unquote(value))
+		str = rest
+	}
+	if nil != skip && "" != str {
+		skip(str)
+	}
+}
+
+/*
+	As HandleConfigBlocks, using these options
+*/
+func (o Options) HandleConfigBlocks(str string, f func(label, block string)) {
+	str = normalizeEOL(str)
+	rx := o.syntax()
+	for "" != str {
+		x := rx.block.FindStringSubmatchIndex(str)
+		if h := rx.heredoc.FindStringSubmatchIndex(str); nil != h && (nil == x || h[2] < x[2]) {
+			label, body, rest, ok := heredoc(str, h)
+			if !ok {
+				dbg.Error("Missing end tag for config block: %s <<%s", label, str[h[4]:h[5]])
+				return
+			}
+			if body, err := processBlock(label, o.dedent(body, false)); !dbg.ChkErr(err, "Invalid config block: %v", err) {
+				f(label, body)
+			}
+			str = rest
+		} else if nil != x {
+			if body, err := processBlock(str[x[2]:x[3]], o.unescapeBlock(o.dedent(str[x[6]:x[7]], x[4] >= 0))); !dbg.ChkErr(err, "Invalid config block: %v", err) {
+				f(str[x[2]:x[3]], body)
+			}
+			str = str[x[8]:]
+		} else {
+			return
+		}
+	}
+}
+
+/*
+	As HandleConfigLines, using these options
+*/
+func (o Options) HandleConfigLines(str string, f func(label string, lines []string)) {
+	str = normalizeEOL(str)
+	rx := o.syntax().lines
+	for x := rx.FindStringSubmatch(str); nil != x; x = rx.FindStringSubmatch(x[3]) {
+		f(x[1], o.dataLines(x[2]))
+	}
+}
+
+/*
+	As HandleConfigItems, using these options
+*/
+func (o Options) HandleConfigItems(str string, f func(label string, list []string)) {
+	str = normalizeEOL(str)
+	rx := o.syntax()
+	for "" != str {
+		x, in := rx.items.FindStringSubmatchIndex(str), rx.inline.FindStringSubmatchIndex(str)
+		for nil != in && in[6] < 0 {
+			// skip any inline groups
+			str = str[in[1]:]
+			in = rx.inline.FindStringSubmatchIndex(str)
+			x = rx.items.FindStringSubmatchIndex(str)
+		}
+		switch {
+		case nil != in && (nil == x || in[2] < x[2]):
+			items, err := o.expandItems(str[in[2]:in[3]], o.inlineItems(str[in[6]:in[7]], in[4] >= 0))
+			if !dbg.ChkErr(err, "Invalid config items: %v", err) {
+				f(str[in[2]:in[3]], items)
+			}
+			str = str[in[1]:]
+		case nil != x:
+			sep := " "
+			if x[4] >= 0 {
+				sep = str[x[4]:x[5]]
+			}
+			for _, l := range o.itemLists(str[x[6]:x[7]], sep) {
+				if items, err := o.expandItems(str[x[2]:x[3]], l); !dbg.ChkErr(err, "Invalid config items: %v", err) {
+					f(str[x[2]:x[3]], items)
+				}
+			}
+			str = str[x[8]:]
+		default:
+			return
+		}
+	}
+}
+
+/*
+	As HandleConfigDicts, using these options
+*/
+func (o Options) HandleConfigDicts(str string, f func(label string, dict map[string]string)) {
+	str = normalizeEOL(str)
+	rx := o.syntax()
+	for x := rx.dict.FindStringSubmatch(str); nil != x; x = rx.dict.FindStringSubmatch(x[3]) {
+		d, p := make(map[string]string), rx.pairs(o.listToStringSlice(x[2]))
+		for i := 0; i+1 < len(p); i += 2 {
+			d[p[i]] = p[i+1]
+		}
+		f(x[1], d)
+	}
+}
+
+var (
+	ErrBadUTF16 = errors.New("Invalid UTF-16 config data -- odd number of bytes")
+)
+
+// ------------------------------------------------------------------------- //
+
+func (o Options) parse(str string) (*Config, error) {
+	c := newConfig()
+	c.opts, c.text, c.loaded = o, str, time.Now()
+	var err error
+	var recovered []error
+	if o.Recover {
+		o.recovered = &recovered
+	}
+	add, done := o.trace(str, o.deprecations(c, o.aliased(c.add)))
+	if o.ParallelSections {
+		err = o.walkParallel(str, add)
+	} else {
+		err = o.walk("", str, add)
+	}
+	if nil == err && DuplicatesAllowed != o.Duplicates {
+		err = c.duplicates(&c.root, o.Duplicates)
+	}
+	if nil == err && nil != o.Keys {
+		err = c.decrypt(o.Keys)
+	}
+	if nil == err && !o.partial {
+		err = c.underlay()
+	}
+	if nil == err && o.Interpolate {
+		err = c.interpolate()
+	}
+	if nil == err && o.Expressions && !o.partial {
+		err = c.evaluate()
+	}
+	if nil == err && !o.partial {
+		err = c.validated()
+	}
+	if nil != err {
+		return nil, done(err)
+	}
+	return c, done(joinErrors(recovered))
+}
+
+// readConfig reads the config file, expanding any @include lines
+func (o Options) readConfig(flPath string) (string, error) {
+	if nil == o.Include {
+		o.Include = o.FileInclude
+	}
+	data, err := o.readText(flPath)
+	if nil != err {
+		return "", err
+	}
+	return o.preprocess([]string{flPath}, data)
+}
+
+// readText reads a text file of no more than Options.MaxSize bytes, see
+// readText, noting the file read
+func (o Options) readText(flPath string) (string, error) {
+	data, err := readText(flPath, o.MaxSize)
+	if nil == err && nil != o.seen {
+		o.seen(flPath, data)
+	}
+	return data, err
+}
+
+// readText reads a text file, or stdin for the path Stdin, of no more than
+// max bytes when max is set, see decodeText
+func readText(flPath string, max int64) (string, error) {
+	data, err := readFile(flPath, max)
+	if nil != err {
+		return "", err
+	}
+	return decodeText(flPath, data, max)
+}
+
+// readFile reads the bytes of a file, or stdin for the path Stdin, see
+// readText
+func readFile(flPath string, max int64) ([]byte, error) {
+	r := stdin
+	if Stdin != flPath {
+		f, err := os.Open(flPath)
+		if nil != err {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return readAll(r, flPath, max)
+}
+
+// decodeText decompresses data in a registered compressed format, then
+// removes any UTF-8 byte order mark, transcoding UTF-16 (LE or BE) data
+// marked by a byte order mark to UTF-8; max, when set, limits the size of
+// the decompressed data
+func decodeText(path string, data []byte, max int64) (string, error) {
+	data, err := decompress(path, data, max)
+	if nil != err {
+		return "", err
+	}
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		return string(data[3:]), nil
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		order = binary.BigEndian
+	default:
+		return string(data), nil
+	}
+	if 0 != len(data)%2 {
+		return "", ErrBadUTF16
+	}
+	u := make([]uint16, 0, len(data)/2-1)
+	for i := 2; i < len(data); i += 2 {
+		u = append(u, order.Uint16(data[i:]))
+	}
+	return string(utf16.Decode(u)), nil
+}
+
+// aliased wraps the handler to rename any label paths found in Aliases
+func (o Options) aliased(f func(t ConfigType, label string, data []string)) func(t ConfigType, label string, data []string) {
+	if 0 == len(o.Aliases) {
+		return f
+	}
+	return func(t ConfigType, label string, data []string) {
+		from := ""
+		for old := range o.Aliases {
+			if len(old) > len(from) && (label == old || strings.HasPrefix(label, old+":")) {
+				from = old
+			}
+		}
+		if "" != from {
+			to := o.Aliases[from] + label[len(from):]
+			if o.WarnAliases && ConfigGroup != t {
+				dbg.Info("Config label %s is deprecated, use %s", label, to)
+			}
+			label = to
+		}
+		f(t, label, data)
+	}
+}
+
+/*
+	Reports whether the line of a lines or items section is a comment, one
+	 starting with a CommentPrefixes prefix or, when there are none, a '#';
+	 see ListComments
+*/
+func (o Options) IsComment(line string) bool {
+	if nil == o.CommentPrefixes {
+		return strings.HasPrefix(strings.TrimSpace(line), "#")
+	}
+	return o.isComment(line)
+}
+
+func (o Options) isComment(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, p := range o.CommentPrefixes {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// multiLineValue collects the TAB indented lines of a 'label :==' value up
+// to the closing ':==' line, returning the value and the remaining text
+func multiLineValue(src string) (string, string, bool) {
+	lines := []string{}
+	for "" != src {
+		line := src
+		if i := strings.Index(src, "\n"); i >= 0 {
+			line, src = src[:i], src[i+1:]
+		} else {
+			src = ""
+		}
+		switch {
+		case ":==" == strings.TrimRight(line, " \t"):
+			return strings.Join(lines, "\n"), src, true
+		case "" == line:
+			lines = append(lines, "")
+		case '\t' == line[0]:
+			lines = append(lines, line[1:])
+		default:
+			return "", src, false
+		}
+	}
+	return "", "", false
+}
+
+// continueValue folds the following lines onto a value ending with a '\',
+// returning the full value and the remaining text
+func continueValue(v, rest string) (string, string) {
+	for strings.HasSuffix(v, "\\") {
+		if "" == rest {
+			return v[:len(v)-1], rest
+		}
+		line := rest
+		if i := strings.Index(rest, "\n"); i >= 0 {
+			line, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = ""
+		}
+		v = v[:len(v)-1] + strings.TrimSpace(line)
+	}
+	return v, rest
+}
+
+// unquote removes a matching pair of quotes surrounding the value when
+// QuotedValues is set, other values are returned unchanged.  Double quoted
+// values have their escape sequences processed as Go strings do, unless
+// NoEscapes is set or the value is not a valid Go string
+func (o Options) unquote(v string) string {
+	if !o.QuotedValues {
+		return v
+	}
+	return o.unquoteString(v)
+}
+
+// unquoteString removes a matching pair of quotes surrounding the string,
+// as unquote does whatever the options
+func (o Options) unquoteString(v string) string {
+	if len(v) < 2 {
+		return v
+	}
+	q := v[0]
+	if ('"' != q && '\'' != q) || v[len(v)-1] != q {
+		return v
+	}
+	if '"' == q && !o.NoEscapes {
+		if u, err := strconv.Unquote(v); nil == err {
+			return u
+		}
+	}
+	return v[1 : len(v)-1]
+}
+
+// listToStringSlice splits the text into trimmed lines, removing empty and
+// comment lines
+func (o Options) listToStringSlice(s string) []string {
+	if nil == o.CommentPrefixes {
+		return txt.ListToStringSlice(s)
+	}
+	result := []string{}
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); "" != l && !o.isComment(l) {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// sectionLines is listToStringSlice for the lines of a lines or items
+// section, keeping the comment lines when ListComments is set
+func (o Options) sectionLines(s string) []string {
+	if !o.ListComments {
+		return o.listToStringSlice(s)
+	}
+	result := []string{}
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); "" != l {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// dataLines splits the text of a lines section, keeping its blank lines as
+// "" entries when KeepBlankLines is set and its escaped lines as written when
+// SectionEscapes is
+func (o Options) dataLines(s string) []string {
+	if !o.KeepBlankLines && !o.SectionEscapes {
+		return o.sectionLines(s)
+	}
+	result := []string{}
+	for _, l := range strings.Split(s, "\n") {
+		if e, ok := o.escapedLine(l); ok {
+			result = append(result, e)
+		} else if l = strings.TrimSpace(l); ("" == l && o.KeepBlankLines) || ("" != l && (o.ListComments || !o.IsComment(l))) {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// sectionItems is sepListToStringSlice for an items section, keeping each
+// comment line whole as an item when ListComments is set
+func (o Options) sectionItems(s, sep string) []string {
+	if !o.ListComments {
+		return o.sepListToStringSlice(s, sep)
+	}
+	result := []string{}
+	for _, l := range o.sectionLines(s) {
+		if o.IsComment(l) {
+			result = append(result, l)
+		} else {
+			result = append(result, o.sepListToStringSlice(l, sep)...)
+		}
+	}
+	return result
+}
+
+// itemLists splits the text of an items section into its items, or when the
+// section holds sub-lists, either lines wrapped in (parens) or TAB indented
+// sections wrapped in {braces}, into the items of each sub-list
+func (o Options) itemLists(s, sep string) [][]string {
+	lines := o.sectionLines(s)
+	nested := false
+	for _, l := range lines {
+		if "{" == l || (strings.HasPrefix(l, "(") && strings.HasSuffix(l, ")")) {
+			nested = true
+			break
+		}
+	}
+	if !nested {
+		return [][]string{o.sectionItems(s, sep)}
+	}
+	result := [][]string{}
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		switch {
+		case "{" == l:
+			j := i + 1
+			for j < len(lines) && "}" != lines[j] {
+				j++
+			}
+			result = append(result, o.sectionItems(strings.Join(lines[i+1:j], "\n"), sep))
+			i = j
+		case strings.HasPrefix(l, "(") && strings.HasSuffix(l, ")"):
+			result = append(result, o.sectionItems(l[1:len(l)-1], sep))
+		default:
+			result = append(result, o.sectionItems(l, sep))
+		}
+	}
+	return result
+}
+
+// sepListToStringSlice splits the lines of the text into trimmed items
+// separated by sep, a sep of " " splits on any run of whitespace
+func (o Options) sepListToStringSlice(s, sep string) []string {
+	if nil == o.CommentPrefixes && !o.QuotedItems {
+		return txt.SepListToStringSlice(s, sep)
+	}
+	result := []string{}
+	for _, l := range o.listToStringSlice(s) {
+		var items []string
+		if o.QuotedItems {
+			result = append(result, o.quotedItems(l, sep)...)
+			continue
+		}
+		if " " == sep {
+			items = strings.Fields(l)
+		} else {
+			items = strings.Split(l, sep)
+		}
+		for _, i := range items {
+			if i = strings.TrimSpace(i); "" != i {
+				result = append(result, i)
+			}
+		}
+	}
+	return result
+}
+
+// quotedItems splits the line into trimmed items separated by sep as
+// sepListToStringSlice does, taking an item wrapped in quotes whole, with
+// the quotes removed
+func (o Options) quotedItems(l, sep string) []string {
+	result := []string{}
+	for l = strings.TrimSpace(l); "" != l; {
+		n := -1
+		if end := closingQuote(l, !o.NoEscapes); end > 0 {
+			rest := l[end+1:]
+			if "" == rest || (" " == sep && strings.ContainsAny(rest[:1], " \t")) ||
+				(" " != sep && strings.HasPrefix(strings.TrimLeft(rest, " \t"), sep)) {
+				n = end + 1
+			}
+		}
+		if n < 0 {
+			if " " == sep {
+				n = strings.IndexAny(l, " \t")
+			} else {
+				n = strings.Index(l, sep)
+			}
+			if n < 0 {
+				n = len(l)
+			}
+		}
+		if item := strings.TrimSpace(l[:n]); "" != item {
+			result = append(result, o.unquoteString(item))
+		}
+		l = strings.TrimLeft(l[n:], " \t")
+		if " " != sep {
+			l = strings.TrimSpace(strings.TrimPrefix(l, sep))
+		}
+	}
+	return result
+}
+
+// closingQuote returns the index of the quote ending the quoted string the
+// text starts with, -1 when it doesn't start with one; a \ escapes the next
+// character of a double quoted string when escapes is set
+func closingQuote(s string, escapes bool) int {
+	if "" == s || ('"' != s[0] && '\'' != s[0]) {
+		return -1
+	}
+	for i := 1; i < len(s); i++ {
+		switch {
+		case escapes && '"' == s[0] && '\\' == s[i]:
+			i++
+		case s[0] == s[i]:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,515 @@
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	commentTests = `
+; INI style comment
+// C style comment
+name := value
+hosts {
+	; commented out host
+	alpha beta
+	// gamma
+	# delta
+}
+group (
+	lines [
+		first
+		; second
+	]
+; comment without a leading TAB
+	key := value
+)
+`
+)
+
+func TestCommentPrefixes(t *testing.T) {
+	o := Options{CommentPrefixes: []string{"#", ";", "//"}}
+	c, err := o.Parse(commentTests)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if l, _ := c.GetStringList("hosts"); !compareEntries(l, []string{"alpha", "beta"}) {
+		dbg.Error("hosts: %v", l)
+		t.Fail()
+	}
+	if l, _ := c.GetStringList("group:lines"); !compareEntries(l, []string{"first"}) {
+		dbg.Error("group:lines: %v", l)
+		t.Fail()
+	}
+	if v, _ := c.Value("group:key"); v != "value" {
+		dbg.Error("group:key: %s", v)
+		t.Fail()
+	}
+	if 3 != len(c.Nodes()) {
+		dbg.Error("Unexpected top level nodes: %d", len(c.Nodes()))
+		t.Fail()
+	}
+
+	// the default only has '#' comments
+	if _, err := Parse(commentTests); ErrIllegalDataBlock != err {
+		dbg.Error("Expected ErrIllegalDataBlock: %v", err)
+		t.Fail()
+	}
+	o.HandleConfigItems(commentTests, func(l string, d []string) {
+		if !compareEntries(d, []string{"alpha", "beta"}) {
+			dbg.Error("%s: %v", l, d)
+			t.Fail()
+		}
+	})
+}
+
+func TestQuotedValues(t *testing.T) {
+	const quoted = `
+padded := "  padded value  "
+raw := '  raw  '
+mixed := "not closed
+plain :=   plain  
+empty := ""
+`
+	expect := map[string]string{
+		"padded": "  padded value  ",
+		"raw":    "  raw  ",
+		"mixed":  "\"not closed",
+		"plain":  "plain",
+		"empty":  "",
+	}
+	Options{QuotedValues: true}.HandleConfigValues(quoted, func(l, v string) {
+		if expect[l] != v {
+			dbg.Error("%s: %q", l, v)
+			t.Fail()
+		}
+	})
+	HandleConfigValues(quoted, func(l, v string) {
+		if "padded" == l && v != `"  padded value  "` {
+			dbg.Error("%s: %q", l, v)
+			t.Fail()
+		}
+	})
+}
+
+func TestEscapedValues(t *testing.T) {
+	const escaped = `
+ctrl := "line1\nline2\ttabbed"
+slash := "C:\\temp"
+unicode := "caf\u00e9"
+raw := 'no\nescape'
+`
+	expect := map[string]string{
+		"ctrl":    "line1\nline2\ttabbed",
+		"slash":   "C:\\temp",
+		"unicode": "caf\u00e9",
+		"raw":     "no\\nescape",
+	}
+	Options{QuotedValues: true}.HandleConfigValues(escaped, func(l, v string) {
+		if expect[l] != v {
+			dbg.Error("%s: %q", l, v)
+			t.Fail()
+		}
+	})
+	Options{QuotedValues: true, NoEscapes: true}.HandleConfigValues(escaped, func(l, v string) {
+		if "ctrl" == l && v != `line1\nline2\ttabbed` {
+			dbg.Error("%s: %q", l, v)
+			t.Fail()
+		}
+	})
+}
+
+func TestQuotedItems(t *testing.T) {
+	const quoted = "cities , {\n\t\"new york\", boston, 'a, b'\n\t\"\", \"say \\\"hi\\\"\"\n}\n" +
+		"words {\n\t\"two words\" one \"x\"y\n}\ninline , { \"a, b\", c }\n"
+	o := Options{QuotedItems: true}
+	c, err := o.Parse(quoted)
+	if nil != err {
+		dbg.Error("QuotedItems: %v", err)
+		t.FailNow()
+	}
+	expect := map[string][]string{
+		"cities": {"new york", "boston", "a, b", "", `say "hi"`},
+		"words":  {"two words", "one", `"x"y`},
+		"inline": {"a, b", "c"},
+	}
+	for path, want := range expect {
+		if got, _ := c.GetStringList(path); !reflect.DeepEqual(want, got) {
+			dbg.Error("QuotedItems %s: %q", path, got)
+			t.Fail()
+		}
+	}
+
+	// written back quoted where needed
+	var buf bytes.Buffer
+	c.WriteTo(&buf)
+	r, err := o.Parse(buf.String())
+	if nil != err || 0 != len(Diff(c, r)) {
+		dbg.Error("QuotedItems round trip: %v\n%s", err, buf.String())
+		t.Fail()
+	}
+
+	if c, _ := Parse(quoted); nil == c {
+		dbg.Error("QuotedItems unset")
+		t.Fail()
+	} else if l, _ := c.GetStringList("inline"); 3 != len(l) {
+		dbg.Error("QuotedItems unset: %q", l)
+		t.Fail()
+	}
+}
+
+func TestLineContinuation(t *testing.T) {
+	const continued = `
+command := /usr/bin/foo --flag-a \
+	--flag-b \
+		--flag-c
+after := value
+dsn := host=db \
+`
+	expect := map[string]string{
+		"command": "/usr/bin/foo --flag-a --flag-b --flag-c",
+		"after":   "value",
+		"dsn":     "host=db ",
+	}
+	seen := 0
+	Options{LineContinuation: true}.HandleConfigValues(continued, func(l, v string) {
+		seen++
+		if expect[l] != v {
+			dbg.Error("%s: %q", l, v)
+			t.Fail()
+		}
+	})
+	if 3 != seen {
+		dbg.Error("Expected 3 values, got %d", seen)
+		t.Fail()
+	}
+}
+
+func TestMultiLineValues(t *testing.T) {
+	const multi = `
+motd :==
+	Welcome!
+	  Have a nice day
+
+	# not a comment
+:==
+after := value
+group (
+	sql :==
+		SELECT *
+		FROM t
+	:==
+)
+literal := =
+`
+	c, err := Parse(multi)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"motd":      "Welcome!\n  Have a nice day\n\n# not a comment",
+		"after":     "value",
+		"group:sql": "SELECT *\nFROM t",
+		"literal":   "=",
+	}
+	for k, want := range expect {
+		if v, _ := c.Value(k); v != want {
+			dbg.Error("%s: %q", k, v)
+			t.Fail()
+		}
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	src := `
+host := alpha
+port := 80
+host := beta
+grp (
+	host := gamma
+	host := delta
+)
+`
+	expect := map[DuplicatePolicy]string{
+		DuplicatesAllowed:   "host=alpha port=80 host=beta grp:host=gamma grp:host=delta",
+		DuplicatesKeepFirst: "host=alpha port=80 grp:host=gamma",
+		DuplicatesKeepLast:  "host=beta port=80 grp:host=delta",
+		DuplicatesAppend:    "host=alpha,beta port=80 grp:host=gamma,delta",
+	}
+	for policy, want := range expect {
+		got := ""
+		err := Options{Duplicates: policy}.HandleConfigData(src, func(ct ConfigType, label string, data []string) {
+			got += " " + label + "=" + strings.Join(data, ",")
+		})
+		if nil != err || want != got[1:] {
+			dbg.Error("policy %d: %q %v", policy, got, err)
+			t.Fail()
+		}
+	}
+
+	c, err := Options{Duplicates: DuplicatesKeepFirst}.Parse(src)
+	if v, _ := c.Value("host"); nil != err || "alpha" != v {
+		dbg.Error("keep first: %q %v", v, err)
+		t.Fail()
+	}
+	c, err = Options{Duplicates: DuplicatesAppend}.Parse(src)
+	if l, err := c.GetStringList("grp:host"); nil != err || 2 != len(l) {
+		dbg.Error("append: %v %v", l, err)
+		t.Fail()
+	}
+
+	var pe *PathError
+	_, err = Options{Duplicates: DuplicatesError}.Parse(src)
+	if !errors.As(err, &pe) || "host" != pe.Path || !errors.Is(err, ErrDuplicateLabel) {
+		dbg.Error("error: %v", err)
+		t.Fail()
+	}
+	_, err = Options{Duplicates: DuplicatesAppend}.Parse("a := 1\na <\ntext\n>\n")
+	if !errors.Is(err, ErrWrongType) {
+		dbg.Error("append block: %v", err)
+		t.Fail()
+	}
+}
+
+func TestSpaceIndent(t *testing.T) {
+	src := "top (\n    name := value\n\n    sub (\n        deep := yes\n    )\n    hosts {\n        a b\n    }\n\tmixed := tab\n)\n"
+	c, err := Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	for path, want := range map[string]string{
+		"top:name":     "value",
+		"top:sub:deep": "yes",
+		"top:mixed":    "tab",
+	} {
+		if v, _ := c.Value(path); want != v {
+			dbg.Error("%s: %q", path, v)
+			t.Fail()
+		}
+	}
+	if l, _ := c.GetStringList("top:hosts"); 2 != len(l) {
+		dbg.Error("top:hosts: %v", l)
+		t.Fail()
+	}
+	if _, err = Parse("top (\n    a := 1\n  b := 2\n)\n"); ErrIllegalDataBlock != err {
+		dbg.Error("inconsistent indent: %v", err)
+		t.Fail()
+	}
+	c, err = Options{IndentSpaces: 2}.Parse("top (\n  a := 1\n    b := 2\n)\n")
+	if v, _ := c.Value("top:a"); nil != err || "1" != v {
+		dbg.Error("IndentSpaces: %q %v", v, err)
+		t.Fail()
+	}
+}
+
+func TestUnicodeLabels(t *testing.T) {
+	src := "сервер := localhost\n日本語 (\n\tポート := 8080\n\t設定 : [\n\t\tキー : 値\n\t]\n)\nnaïve <\ntext\n>\n"
+	c, err := Options{UnicodeLabels: true}.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	for path, want := range map[string]string{
+		"сервер":  "localhost",
+		"日本語:ポート": "8080",
+		"naïve":   "text",
+	} {
+		if v, _ := c.Value(path); want != v {
+			dbg.Error("%s: %q", path, v)
+			t.Fail()
+		}
+	}
+	if d, _ := c.Dict("日本語:設定"); "値" != d["キー"] {
+		dbg.Error("dict: %v", d)
+		t.Fail()
+	}
+	if c, _ = Parse(src); nil != c.Lookup("сервер") {
+		dbg.Error("Unicode label matched without UnicodeLabels")
+		t.Fail()
+	}
+}
+
+func TestLabelChars(t *testing.T) {
+	src := "log-level := debug\ndb (\n\tprimary.host := db1\n\thosts.all {\n\t\ta b\n\t}\n)\n"
+	c, err := Options{LabelChars: "-.:"}.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if v, _ := c.Value("log-level"); "debug" != v {
+		dbg.Error("log-level: %q", v)
+		t.Fail()
+	}
+	if v, _ := c.Value("db:primary.host"); "db1" != v {
+		dbg.Error("db:primary.host: %q", v)
+		t.Fail()
+	}
+	if l, _ := c.GetStringList("db:hosts.all"); 2 != len(l) {
+		dbg.Error("db:hosts.all: %v", l)
+		t.Fail()
+	}
+	for chars, label := range map[string]string{"é": "café", "s": "rust", "]^": "a]^b", "\\": `a\b`} {
+		c, err := Options{LabelChars: chars}.Parse(label + " := 1\nx y := 2\n")
+		if nil != err || "1" != c.ValueOr(label, "") || nil != c.Lookup("x y") {
+			dbg.Error("LabelChars %q: %v", chars, err)
+			t.Fail()
+		}
+	}
+	if c, _ = Parse(src); nil != c.Lookup("log-level") {
+		dbg.Error("log-level matched without LabelChars")
+		t.Fail()
+	}
+}
+
+func TestAliases(t *testing.T) {
+	src := "timeout := 5s\ndb (\n\thost := localhost\n\tsub (\n\t\tx := 1\n\t)\n)\ndb_sub (\n\ty := 2\n)\n"
+	o := Options{Aliases: map[string]string{
+		"timeout": "net:timeout",
+		"db":      "database",
+		"db:sub":  "database:child",
+	}}
+	c, err := o.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	for path, want := range map[string]string{
+		"net:timeout":      "5s",
+		"database:host":    "localhost",
+		"database:child:x": "1",
+		"db_sub:y":         "2",
+	} {
+		if v, _ := c.Value(path); want != v {
+			dbg.Error("%s: %q", path, v)
+			t.Fail()
+		}
+	}
+	if nil != c.Lookup("db:host") || nil != c.Lookup("timeout") {
+		dbg.Error("old paths should not exist")
+		t.Fail()
+	}
+	got := []string{}
+	o.WarnAliases = true
+	o.HandleConfigData(src, func(ct ConfigType, label string, data []string) {
+		got = append(got, label)
+	})
+	if "net:timeout database:host database:child:x db_sub:y" != strings.Join(got, " ") {
+		dbg.Error("HandleConfigData: %v", got)
+		t.Fail()
+	}
+}
+
+func TestRecover(t *testing.T) {
+	src := "a := 1\nlist [\nx\n}\nb := 2\ng (\n\tc := 3\n\tdoc <<EOF\n\ttext\n\tf := 6\n)\n" +
+		"h (\n\te := 5\n)\nbad <\nno end\nd := 4\n"
+	c, err := Options{Recover: true}.Parse(src)
+	var me *MultiError
+	if nil == c || !errors.As(err, &me) || 3 != len(me.Errs) {
+		dbg.Error("Parse: %v %v", c, err)
+		t.FailNow()
+	}
+	for i, want := range []struct {
+		path string
+		err  error
+	}{{"list", ErrMismatchedEnd}, {"g:doc", ErrUnendedSection}, {"bad", ErrUnendedSection}} {
+		var pe *PathError
+		if !errors.As(me.Errs[i], &pe) || want.path != pe.Path || !errors.Is(pe, want.err) {
+			dbg.Error("error %d: %v", i, me.Errs[i])
+			t.Fail()
+		}
+	}
+	for p, v := range map[string]string{"a": "1", "b": "2", "g:c": "3", "g:f": "6", "h:e": "5", "d": "4"} {
+		if got := c.ValueOr(p, ""); v != got {
+			dbg.Error("%s is %q, expected %q", p, got, v)
+			t.Fail()
+		}
+	}
+
+	if _, err = (Options{Recover: true, ParallelSections: true}).Parse(src); !errors.As(err, &me) || 3 != len(me.Errs) {
+		dbg.Error("Parse with ParallelSections: %v", err)
+		t.Fail()
+	}
+	found := map[string]bool{}
+	err = Options{Recover: true}.HandleConfigData(src, func(t ConfigType, label string, data []string) {
+		found[label] = true
+	})
+	if !errors.As(err, &me) || !found["h:e"] {
+		dbg.Error("HandleConfigData: %v %v", found, err)
+		t.Fail()
+	}
+	if c, err = Parse(src); nil != err || "" != c.ValueOr("b", "") {
+		dbg.Error("Parse without Recover: %v", err)
+		t.Fail()
+	}
+}
+
+func TestListComments(t *testing.T) {
+	src := "jobs [\n\t# nightly\n\t0 2 * * * backup\n\t#0 3 * * * disabled\n]\nhosts {\n\talpha beta\n\t# gamma delta\n\tepsilon\n}\n"
+	o := Options{ListComments: true}
+	c, err := o.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if l, _ := c.GetStringList("jobs"); !compareEntries(l, []string{"# nightly", "0 2 * * * backup", "#0 3 * * * disabled"}) {
+		dbg.Error("jobs: %q", l)
+		t.Fail()
+	}
+	l, _ := c.GetStringList("hosts")
+	if !compareEntries(l, []string{"alpha", "beta", "# gamma delta", "epsilon"}) {
+		dbg.Error("hosts: %q", l)
+		t.Fail()
+	}
+	if !o.IsComment(l[2]) || o.IsComment(l[3]) {
+		dbg.Error("IsComment failed")
+		t.Fail()
+	}
+	if s := c.String(); !strings.Contains(s, "\talpha beta\n\t# gamma delta\n\tepsilon\n") {
+		dbg.Error("String: %s", s)
+		t.Fail()
+	}
+	c, _ = Parse(src)
+	if l, _ = c.GetStringList("jobs"); 1 != len(l) {
+		dbg.Error("Comments kept without ListComments: %q", l)
+		t.Fail()
+	}
+	if !(Options{CommentPrefixes: []string{";"}}).IsComment("; x") || (Options{CommentPrefixes: []string{";"}}).IsComment("# x") {
+		dbg.Error("IsComment with CommentPrefixes failed")
+		t.Fail()
+	}
+}
+
+func TestKeepBlankLines(t *testing.T) {
+	src := "g (\n\trecords [\n\t\tname a\n\t\tage 1\n\n\t\t# b is next\n\t\tname b\n\t\n\t]\n)\n"
+	o := Options{KeepBlankLines: true}
+	c, err := o.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	l, _ := c.GetStringList("g:records")
+	if !compareEntries(l, []string{"name a", "age 1", "", "name b", ""}) {
+		dbg.Error("records: %q", l)
+		t.Fail()
+	}
+	c, _ = o.Parse(c.String())
+	if w, _ := c.GetStringList("g:records"); !compareEntries(w, l) {
+		dbg.Error("Written records: %q", w)
+		t.Fail()
+	}
+	o.ListComments = true
+	o.HandleConfigLines(src, func(label string, lines []string) {
+		if 6 != len(lines) || "# b is next" != lines[3] {
+			dbg.Error("%s: %q", label, lines)
+			t.Fail()
+		}
+	})
+}
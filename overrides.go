@@ -0,0 +1,82 @@
+package cfg
+
+import (
+	"errors"
+	"strings"
+)
+
+type (
+	/*
+		Overrides is a flag.Value collecting each use of a repeated flag,
+		 for ApplyOverrides:
+
+			var sets cfg.Overrides
+			flag.Var(&sets, "set", "override a config value, path=value")
+			flag.Parse()
+			err := cfg.ApplyOverrides(c, sets)
+	*/
+	Overrides []string
+)
+
+var (
+	ErrBadOverride = errors.New("Config override is not path=value")
+)
+
+/*
+	Sets the value of the label path of each path=value override, in
+	 order; the path is either a label path or '.' separated, e.g.
+	 db:host=x or db.host=x, unless '.' is one of Options.LabelChars
+
+	A value (or block) is replaced, and a missing label path is added along
+	 with any groups needed to hold it.  An override of any other type of
+	 entry, or one below an entry that isn't a group, returns a *PathError
+	 wrapping ErrWrongType; the overrides before it are kept
+*/
+func ApplyOverrides(c *Config, overrides []string) error {
+	label := c.opts.syntax().label
+	dotted := !strings.Contains(c.opts.LabelChars, ".")
+	for _, o := range overrides {
+		i := strings.Index(o, "=")
+		if i <= 0 {
+			return &PathError{o, ErrBadOverride}
+		}
+		path, value := strings.TrimSpace(o[:i]), o[i+1:]
+		if dotted {
+			path = strings.ReplaceAll(path, ".", ":")
+		}
+		if n := c.node(path); nil != n {
+			if ConfigValue != n.Type && ConfigBlock != n.Type {
+				return &PathError{path, ErrWrongType}
+			}
+			n.Data = []string{value}
+			continue
+		}
+		elems := strings.Split(path, ":")
+		for i, elem := range elems {
+			if indexRex.MatchString(elem) {
+				// only an existing repeated entry can be indexed
+				return &PathError{path, ErrNoSuchLabel}
+			}
+			if !label.MatchString(elem) {
+				return &PathError{path, ErrIllegalLabel}
+			}
+			if n := c.nodes[strings.Join(elems[:i+1], ":")]; nil != n && ConfigGroup != n.Type {
+				return &PathError{path, ErrWrongType}
+			}
+		}
+		c.add(ConfigValue, path, []string{value})
+	}
+	return nil
+}
+
+func (o *Overrides) String() string {
+	if nil == o {
+		return ""
+	}
+	return strings.Join(*o, " ")
+}
+
+func (o *Overrides) Set(s string) error {
+	*o = append(*o, s)
+	return nil
+}
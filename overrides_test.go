@@ -0,0 +1,52 @@
+package cfg
+
+import (
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	c, _ := Parse("port := 8080\nhosts { a b }\ndb (\n\thost := x\n)\nserver (\n\tname := a\n)\nserver (\n\tname := b\n)\n")
+	var sets Overrides
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&sets, "set", "")
+	fs.Parse([]string{"-set", "port=9090", "-set", "db.host=y", "-set", "db:pool:size=4", "-set", "server[0]:name=c", "-set", "motd=a=b"})
+	if err := ApplyOverrides(c, sets); nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"port": "9090", "db:host": "y", "db:pool:size": "4", "server[0]:name": "c", "server[1]:name": "b", "motd": "a=b",
+	}
+	for p, want := range expect {
+		if got, _ := c.Value(p); want != got {
+			dbg.Error("ApplyOverrides %s: %q", p, got)
+			t.Fail()
+		}
+	}
+
+	bad := map[string]error{
+		"port":             ErrBadOverride,
+		"=x":               ErrBadOverride,
+		"hosts=x":          ErrWrongType,
+		"db=x":             ErrWrongType,
+		"port:num=1":       ErrWrongType,
+		"server[5]:name=x": ErrNoSuchLabel,
+		"a b=x":            ErrIllegalLabel,
+	}
+	for o, want := range bad {
+		if err := ApplyOverrides(c, []string{o}); !errors.Is(err, want) {
+			dbg.Error("ApplyOverrides %q: %v", o, err)
+			t.Fail()
+		}
+	}
+
+	c, _ = Options{LabelChars: "."}.Parse("log.level := info\n")
+	if err := ApplyOverrides(c, []string{"log.level=debug"}); nil != err || "debug" != c.ValueOr("log.level", "") {
+		dbg.Error("ApplyOverrides dotted label: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,133 @@
+package cfg
+
+import (
+	"runtime"
+	"sync"
+)
+
+type (
+	// sectionRecorder collects the entries of the top level (data)
+	// containers parsed in their own goroutines, see Options.ParallelSections
+	sectionRecorder struct {
+		wg    sync.WaitGroup
+		sem   chan struct{}
+		parts []*sectionPart
+	}
+
+	// sectionPart holds the entries of a section, or of the text between
+	// sections, in the order they're found
+	sectionPart struct {
+		entries   []sectionEntry
+		err       error
+		recovered []error // see Options.Recover
+	}
+
+	sectionEntry struct {
+		t     ConfigType
+		label string
+		data  []string
+	}
+)
+
+/*
+	As ParseFiles, with the files read and parsed by up to Options.Workers
+	 goroutines at a time (GOMAXPROCS if 0) before they're merged in order;
+	 the error returned is that of the first file in paths to fail
+*/
+func ParseFilesParallel(paths ...string) (*Config, error) {
+	return Options{}.ParseFilesParallel(paths...)
+}
+
+/*
+	As ParseFilesParallel, using these options
+*/
+func (o Options) ParseFilesParallel(paths ...string) (*Config, error) {
+	fo := o
+	fo.Interpolate, fo.partial = false, true
+	configs, errs := make([]*Config, len(paths)), make([]error, len(paths))
+	o.parallel(len(paths), func(i int) {
+		configs[i], errs[i] = fo.LoadConfig(paths[i])
+	})
+	for _, err := range errs {
+		if nil != err {
+			return nil, err
+		}
+	}
+	return o.mergeFiles(paths, configs)
+}
+
+// ------------------------------------------------------------------------- //
+
+// workers returns the number of goroutines to use at once
+func (o Options) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// parallel calls fn for 0..n-1 with at most o.workers() calls running at
+// once, returning when they're all done
+func (o Options) parallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, o.workers())
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// walkParallel walks the config data as walk does, with each top level
+// (data) container walked in its own goroutine, then passes the entries to
+// f in the order they're found; the error is that of the first failure
+func (o Options) walkParallel(str string, f func(t ConfigType, label string, data []string)) error {
+	r := &sectionRecorder{sem: make(chan struct{}, o.workers())}
+	o.sections = r
+	err := o.walk("", str, r.add)
+	r.wg.Wait()
+	for _, p := range r.parts {
+		if nil != p.err {
+			return p.err
+		}
+		if nil != o.recovered {
+			*o.recovered = append(*o.recovered, p.recovered...)
+		}
+		for _, e := range p.entries {
+			f(e.t, e.label, e.data)
+		}
+	}
+	return err
+}
+
+// add records an entry found by the top level walk
+func (r *sectionRecorder) add(t ConfigType, label string, data []string) {
+	if 0 == len(r.parts) {
+		r.parts = append(r.parts, &sectionPart{})
+	}
+	p := r.parts[len(r.parts)-1]
+	p.entries = append(p.entries, sectionEntry{t, label, data})
+}
+
+// spawn walks the body of a top level (data) container in a goroutine,
+// with the entries found after it recorded in a new part
+func (r *sectionRecorder) spawn(o Options, lp, str string) {
+	p := &sectionPart{}
+	r.parts = append(r.parts, p, &sectionPart{})
+	o.sections = nil
+	if nil != o.recovered {
+		o.recovered = &p.recovered
+	}
+	r.wg.Add(1)
+	r.sem <- struct{}{}
+	go func() {
+		defer func() { <-r.sem; r.wg.Done() }()
+		p.err = o.walk(lp, str, func(t ConfigType, label string, data []string) {
+			p.entries = append(p.entries, sectionEntry{t, label, data})
+		})
+	}()
+}
@@ -0,0 +1,77 @@
+package cfg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestParseFilesParallel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	paths := []string{}
+	for i := 0; i < 24; i++ {
+		flPath := filepath.Join(dir, fmt.Sprintf("%02d.cfg", i))
+		data := fmt.Sprintf("last := %d\nhosts { h%d }\nfile%d (\n\tn := %d\n)\n", i, i, i, i)
+		dbg.ChkErr(ioutil.WriteFile(flPath, []byte(data), 0644), "WriteFile")
+		paths = append(paths, flPath)
+	}
+
+	want, err := Options{AppendLists: true}.ParseFiles(paths...)
+	dbg.ChkErr(err, "ParseFiles")
+	c, err := Options{AppendLists: true, Workers: 3}.ParseFilesParallel(paths...)
+	if nil != err || want.String() != c.String() || !reflect.DeepEqual(want.Flatten(), c.Flatten()) {
+		dbg.Error("ParseFilesParallel: %v\n%s", err, c)
+		t.Fail()
+	}
+	if v, _ := c.Value("last"); "23" != v || paths[0] != c.Source() {
+		dbg.Error("ParseFilesParallel last: %q %q", v, c.Source())
+		t.Fail()
+	}
+
+	// the error is that of the first failing file
+	missing := []string{paths[0], filepath.Join(dir, "a.cfg"), filepath.Join(dir, "b.cfg")}
+	_, err = ParseFilesParallel(missing...)
+	if pe, ok := err.(*os.PathError); !ok || missing[1] != pe.Path {
+		dbg.Error("ParseFilesParallel missing: %v", err)
+		t.Fail()
+	}
+}
+
+func TestParallelSections(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/testBlocks.cfg")
+	dbg.ChkErr(err, "ReadFile")
+	want, err := Parse(string(data))
+	dbg.ChkErr(err, "Parse")
+	c, err := Options{ParallelSections: true, Workers: 2}.Parse(string(data))
+	if nil != err || want.String() != c.String() {
+		dbg.Error("ParallelSections: %v\n%s", err, c)
+		t.Fail()
+	}
+
+	var b strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&b, "v%d := %d\ns%d (\n\tn := %d\n\tsub (\n\t\tm := %d\n\t)\n)\n", i, i, i, i, i)
+	}
+	want, _ = Parse(b.String())
+	c, err = Options{ParallelSections: true}.Parse(b.String())
+	if nil != err || want.String() != c.String() {
+		dbg.Error("ParallelSections order: %v", err)
+		t.Fail()
+	}
+
+	// the error is that of the first failing section
+	bad := "a (\n\tx <hex\n\tzz\n\t>\n)\nb (\n\ty <b64\n\t!!\n\t>\n)\n"
+	_, err = Options{ParallelSections: true}.Parse(bad)
+	if pe, ok := err.(*PathError); !ok || "a:x" != pe.Path {
+		dbg.Error("ParallelSections error: %v", err)
+		t.Fail()
+	}
+}
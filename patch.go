@@ -0,0 +1,218 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrPatchConflict = errors.New("Config entry changed since the patch was made")
+)
+
+/*
+	Applies the changes of a patch, as returned by Diff, to the config, so
+	 Diff(a, b) applied to a copy of a gives b's data.  A change only applies
+	 to the entry it was made from: an entry modified or removed must still
+	 have the Old data and one added mustn't be there already, otherwise its
+	 change is a *PathError of ErrPatchConflict (a change already made to the
+	 entry isn't a conflict).  Either every change is applied or, with a
+	 *MultiError for more than one conflict, none are; groups left empty by
+	 the removed entries are removed too
+*/
+func ApplyPatch(c *Config, patch []Change) error {
+	clone := c.Clone()
+	x := &patchIndex{clone, clone.entries(), map[*Node]map[string]int{}}
+	emptied, errs := []*Node{}, []error(nil)
+	for i := 0; i < len(patch); i++ {
+		ch := patch[i]
+		if i+1 < len(patch) && x.typeChanged(ch, patch[i+1]) {
+			i++ // the type change has already been made
+			continue
+		}
+		parent, err := x.apply(ch)
+		if nil != err {
+			errs = append(errs, &PathError{ch.Path, err})
+			if i+1 < len(patch) && ChangeRemoved == ch.Kind && ch.Path == patch[i+1].Path {
+				i++ // the entry of a type change is only a conflict once
+			}
+		} else if nil != parent {
+			emptied = append(emptied, parent)
+		}
+	}
+	if 0 != len(errs) {
+		return joinErrors(errs)
+	}
+	for _, g := range emptied {
+		for ; nil != g.parent && 0 == len(g.Children); g = g.parent {
+			g.parent.Children = removeNode(g.parent.Children, g)
+		}
+	}
+	c.root = clone.root
+	for _, n := range c.root.Children {
+		n.parent = &c.root
+	}
+	c.reindex()
+	return nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// patchIndex is a config a patch is applied to, with its entries by indexed
+// label path and the number of children of each label in the groups changed
+type patchIndex struct {
+	c       *Config
+	entries map[string]*Node
+	labels  map[*Node]map[string]int
+}
+
+// typeChanged reports if the removal and addition of a type change are both
+// already made
+func (x *patchIndex) typeChanged(removed, added Change) bool {
+	if ChangeRemoved != removed.Kind || ChangeAdded != added.Kind || removed.Path != added.Path {
+		return false
+	}
+	cur := x.entries[added.Path]
+	return nil != cur && added.Type == cur.Type && reflect.DeepEqual(added.New, cur.Data)
+}
+
+// apply makes the change, returning the group an entry's removed from
+func (x *patchIndex) apply(ch Change) (*Node, error) {
+	cur := x.entries[ch.Path]
+	same := func(t ConfigType, data []string) bool {
+		return nil != cur && t == cur.Type && reflect.DeepEqual(data, cur.Data)
+	}
+	switch ch.Kind {
+	case ChangeAdded:
+		if same(ch.Type, ch.New) {
+			return nil, nil
+		}
+		if nil != cur {
+			return nil, ErrPatchConflict
+		}
+		return nil, x.add(ch)
+	case ChangeRemoved:
+		if nil == cur {
+			return nil, nil
+		}
+		if !same(ch.Type, ch.Old) {
+			return nil, ErrPatchConflict
+		}
+		repeated := x.count(cur.parent, cur.Label, -1) > 0
+		cur.parent.Children = removeNode(cur.parent.Children, cur)
+		x.indexed(ch.Path, nil, repeated)
+		return cur.parent, nil
+	case ChangeModified:
+		if same(ch.Type, ch.New) {
+			return nil, nil
+		}
+		if !same(ch.Type, ch.Old) {
+			return nil, ErrPatchConflict
+		}
+		cur.Data = append([]string{}, ch.New...)
+		return nil, nil
+	}
+	return nil, ErrPatchConflict
+}
+
+// add adds the entry, along with any groups needed to hold it; an index one
+// past the groups of a label adds another group with the label
+func (x *patchIndex) add(ch Change) error {
+	parent, repeated := &x.c.root, false
+	elems := strings.Split(ch.Path, ":")
+	for i, elem := range elems {
+		label, index := elem, -1
+		if m := indexRex.FindStringSubmatch(elem); nil != m {
+			label = m[1]
+			index, _ = strconv.Atoi(m[2])
+		}
+		if i == len(elems)-1 {
+			if !labelRex.MatchString(label) {
+				return ErrIllegalLabel
+			}
+			repeated = x.count(parent, label, 1) > 1 || repeated
+			n := x.c.schemaAdd(parent, ch.Type, label, append([]string{}, ch.New...))
+			x.indexed(ch.Path, n, repeated)
+			return nil
+		}
+		groups := []*Node{}
+		for _, n := range parent.Children {
+			if label == n.Label && ConfigComment != n.Type {
+				groups = append(groups, n)
+			}
+		}
+		switch {
+		case index >= 0 && index < len(groups):
+			parent = groups[index]
+		case index > len(groups):
+			return ErrNoSuchLabel
+		case index < 0 && 0 != len(groups):
+			parent = groups[len(groups)-1]
+		default:
+			repeated = x.count(parent, label, 1) > 1 || repeated
+			parent = x.c.schemaAdd(parent, ConfigGroup, label, nil)
+		}
+		if ConfigGroup != parent.Type {
+			return ErrWrongType
+		}
+	}
+	return nil
+}
+
+// count returns the number of children of the group with the label, once
+// delta is added for a child being added or removed
+func (x *patchIndex) count(g *Node, label string, delta int) int {
+	labels := x.labels[g]
+	if nil == labels {
+		labels = map[string]int{}
+		for _, n := range g.Children {
+			labels[n.Label]++
+		}
+		x.labels[g] = labels
+	}
+	labels[label] += delta
+	return labels[label]
+}
+
+// indexed records the entry added at the path, or removed for a nil n; when
+// a label repeated in its group is added or removed the indexes of the other
+// entries may change, so they're all found again
+func (x *patchIndex) indexed(path string, n *Node, repeated bool) {
+	switch {
+	case repeated || strings.Contains(path, "["):
+		x.entries = x.c.entries()
+	case nil != n:
+		x.entries[path] = n
+	default:
+		delete(x.entries, path)
+	}
+}
+
+// reindex rebuilds the label path lookups of the tree, the last of any
+// repeated entries being found for a path
+func (c *Config) reindex() {
+	c.nodes = make(map[string]*Node)
+	var walk func(g *Node)
+	walk = func(g *Node) {
+		for _, n := range g.Children {
+			if ConfigComment != n.Type {
+				c.nodes[n.Path] = n
+			}
+			if ConfigGroup == n.Type {
+				walk(n)
+			}
+		}
+	}
+	walk(&c.root)
+}
+
+// removeNode returns the nodes without n
+func removeNode(nodes []*Node, n *Node) []*Node {
+	for i, ch := range nodes {
+		if ch == n {
+			return append(nodes[:i:i], nodes[i+1:]...)
+		}
+	}
+	return nodes
+}
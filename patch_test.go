@@ -0,0 +1,69 @@
+package cfg
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestApplyPatch(t *testing.T) {
+	a, _ := Parse("name := app\ndb (\n\thost := x\n\tport := 5432\n)\nhosts { a b }\n" +
+		"server (\n\tport := 1\n)\nmode := fast\nold (\n\tgone := 1\n)\n")
+	b, _ := Parse("name := app\ndb (\n\thost := y\n\tport := 5432\n)\nhosts { a b c }\n" +
+		"server (\n\tport := 1\n)\nserver (\n\tport := 2\n)\nmode { fast }\nlog (\n\tlevel := info\n)\n")
+	patch := Diff(a, b)
+	c := a.Clone()
+	if err := ApplyPatch(c, patch); nil != err {
+		dbg.Error("ApplyPatch: %v", err)
+		t.FailNow()
+	}
+	if d := Diff(c, b); 0 != len(d) {
+		dbg.Error("ApplyPatch result differs: %v", d)
+		t.Fail()
+	}
+	if nil != c.Lookup("old") || "info" != c.ValueOr("log:level", "") || "2" != c.ValueOr("server:port", "") {
+		dbg.Error("ApplyPatch lookups: %v", c.Flatten())
+		t.Fail()
+	}
+	// applying it again changes nothing
+	if err := ApplyPatch(c, patch); nil != err || 0 != len(Diff(c, b)) {
+		dbg.Error("ApplyPatch again: %v", err)
+		t.Fail()
+	}
+
+	// the base has changed since the diff
+	c, _ = Parse("name := app\ndb (\n\thost := z\n\tport := 5432\n)\nhosts { a b }\n" +
+		"server (\n\tport := 1\n)\nmode := slow\nold (\n\tgone := 1\n)\nlog (\n\tlevel := debug\n)\n")
+	err := ApplyPatch(c, patch)
+	var me *MultiError
+	if !errors.As(err, &me) || 3 != len(me.Errs) || !errors.Is(err, ErrPatchConflict) {
+		dbg.Error("ApplyPatch conflicts: %v", err)
+		t.FailNow()
+	}
+	for i, path := range []string{"db:host", "log:level", "mode"} {
+		if pe, ok := me.Errs[i].(*PathError); !ok || path != pe.Path {
+			dbg.Error("ApplyPatch conflict %d: %v", i, me.Errs[i])
+			t.Fail()
+		}
+	}
+	if "z" != c.ValueOr("db:host", "") || "a,b" != c.Flatten()["hosts"] {
+		dbg.Error("ApplyPatch conflict applied: %v", c.Flatten())
+		t.Fail()
+	}
+}
+
+func TestApplyPatchLarge(t *testing.T) {
+	// each change finds its entry without walking the whole config
+	patch := []Change{}
+	for i := 0; i < 20000; i++ {
+		v := strconv.Itoa(i)
+		patch = append(patch, Change{"g:v" + v, ChangeAdded, ConfigValue, nil, []string{v}})
+	}
+	c, _ := Parse("g (\n\tv0 := 0\n)\n")
+	if err := ApplyPatch(c, patch); nil != err || "19999" != c.ValueOr("g:v19999", "") || 20000 != len(c.Flatten()) {
+		dbg.Error("ApplyPatch large: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,82 @@
+package cfg
+
+import (
+	"strings"
+)
+
+/*
+	Splits the label path into its labels at each sep, removing the escape
+	 rule's backslashes: a backslash makes the character after it part of
+	 the label, so with '.' as the separator "hosts.example\.com.port" is
+	 the labels hosts, example.com and port
+*/
+func SplitPath(path string, sep rune) []string {
+	labels := []string{}
+	var label strings.Builder
+	escaped := false
+	for _, r := range path {
+		switch {
+		case escaped:
+			label.WriteRune(r)
+			escaped = false
+		case '\\' == r:
+			escaped = true
+		case sep == r:
+			labels = append(labels, label.String())
+			label.Reset()
+		default:
+			label.WriteRune(r)
+		}
+	}
+	return append(labels, label.String())
+}
+
+/*
+	Returns the label with a backslash before each sep or backslash in it,
+	 for use in a label path, see SplitPath
+*/
+func EscapeLabel(label string, sep rune) string {
+	if !strings.ContainsRune(label, sep) && !strings.Contains(label, `\`) {
+		return label
+	}
+	var b strings.Builder
+	for _, r := range label {
+		if sep == r || '\\' == r {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+/*
+	As MatchPath, for a pattern and label path using the options' label
+	 path separator, see Options.PathSeparator
+*/
+func (o Options) MatchPath(pattern, path string) bool {
+	return MatchPath(o.internalPath(pattern), o.internalPath(path))
+}
+
+// ------------------------------------------------------------------------- //
+
+// internalPath converts a label path using Options.PathSeparator to the
+// ':' separated path the tree is keyed by; labels never hold a ':'
+func (o Options) internalPath(path string) string {
+	if 0 == o.PathSeparator || ':' == o.PathSeparator {
+		return path
+	}
+	return strings.Join(SplitPath(path, o.PathSeparator), ":")
+}
+
+// externalPath converts a ':' separated label path to one using
+// Options.PathSeparator, escaping the labels
+func (o Options) externalPath(path string) string {
+	if 0 == o.PathSeparator || ':' == o.PathSeparator {
+		return path
+	}
+	labels := strings.Split(path, ":")
+	for i, l := range labels {
+		labels[i] = EscapeLabel(l, o.PathSeparator)
+	}
+	return strings.Join(labels, string(o.PathSeparator))
+}
@@ -0,0 +1,69 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestPathSeparator(t *testing.T) {
+	for path, want := range map[string]string{
+		"a.b.c":                   "a|b|c",
+		`hosts.example\.com.port`: "hosts|example.com|port",
+		`a\\.b`:                   `a\|b`,
+		"":                        "",
+	} {
+		if got := strings.Join(SplitPath(path, '.'), "|"); want != got {
+			dbg.Error("SplitPath(%q) is %q, expected %q", path, got, want)
+			t.Fail()
+		}
+	}
+	if got := EscapeLabel(`a.b\c`, '.'); `a\.b\\c` != got {
+		dbg.Error("EscapeLabel is %q", got)
+		t.Fail()
+	}
+
+	o := Options{PathSeparator: '.', LabelChars: ".", Interpolate: true}
+	src := "db (\n\thost := x\n\turl := ${host}:5432\n)\nexample.com (\n\tport := 80\n)\n" +
+		"env : [\n\ta.b : c\n]\nsrv (\n\tp := 1\n)\nsrv (\n\tp := 2\n)\n"
+	c, err := o.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	for p, v := range map[string]string{"db.host": "x", "db.url": "x:5432", `example\.com.port`: "80", "srv[0].p": "1"} {
+		if got := c.ValueOr(p, ""); v != got {
+			dbg.Error("%s is %q, expected %q", p, got, v)
+			t.Fail()
+		}
+	}
+	if n, err := c.GetInt(`example\.com.port`); nil != err || 80 != n {
+		dbg.Error("GetInt: %d %v", n, err)
+		t.Fail()
+	}
+	if v, err := Get[string](c, "db.host"); nil != err || "x" != v || nil != c.Require("db.host", "srv.p") {
+		dbg.Error("Get: %q %v", v, err)
+		t.Fail()
+	}
+	if flat := c.Flatten(); "c" != flat[`env.a\.b`] || "80" != flat[`example\.com.port`] {
+		dbg.Error("Flatten: %v", flat)
+		t.Fail()
+	}
+	labels := []string{}
+	err = o.HandleConfigData(src, func(t ConfigType, label string, data []string) {
+		labels = append(labels, label)
+	})
+	if nil != err || "db.host,db.url,example\\.com.port,env,srv.p,srv.p" != strings.Join(labels, ",") {
+		dbg.Error("HandleConfigData: %v %v", labels, err)
+		t.Fail()
+	}
+	if !o.MatchPath("db.*", "db.host") || o.MatchPath("db.*", `db\.host`) {
+		dbg.Error("Options.MatchPath")
+		t.Fail()
+	}
+	if c, _ = Parse(src); "x" != c.ValueOr("db:host", "") || "" != c.ValueOr("db.host", "") {
+		dbg.Error("lookups without a PathSeparator")
+		t.Fail()
+	}
+}
@@ -0,0 +1,59 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+/*
+	Reads the config file merged with those of its platform override files
+	 found beside it, named as Go build files are, e.g. for app.cfg on
+	 linux/arm64:
+
+		app.cfg app_linux.cfg app_arm64.cfg app_linux_arm64.cfg
+
+	so the settings of one platform live in their own file and are picked
+	 up automatically; see PlatformFiles and ParseFiles
+*/
+func LoadConfigPlatform(flPath string) (*Config, error) {
+	return Options{}.LoadConfigPlatform(flPath)
+}
+
+/*
+	As LoadConfigPlatform, using these options
+*/
+func (o Options) LoadConfigPlatform(flPath string) (*Config, error) {
+	return o.ParseFiles(PlatformFiles(flPath)...)
+}
+
+/*
+	Returns the config file followed by the platform override files of it
+	 that exist, in the order they're merged: the _GOOS file, the _GOARCH
+	 file and then the _GOOS_GOARCH file of the running program.  The config
+	 file itself is always returned, whether or not it exists
+*/
+func PlatformFiles(flPath string) []string {
+	return platformFiles(flPath, runtime.GOOS, runtime.GOARCH)
+}
+
+// ------------------------------------------------------------------------- //
+
+// platformFiles returns the config file and the existing override files of
+// it for the platform
+func platformFiles(flPath, goos, goarch string) []string {
+	paths := []string{flPath}
+	if Stdin == flPath {
+		return paths
+	}
+	ext := filepath.Ext(flPath)
+	base := strings.TrimSuffix(flPath, ext)
+	for _, suffix := range []string{goos, goarch, goos + "_" + goarch} {
+		p := base + "_" + suffix + ext
+		if fi, err := os.Stat(p); nil == err && fi.Mode().IsRegular() {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
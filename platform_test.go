@@ -0,0 +1,59 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestPlatformFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	write := func(name, data string) string {
+		flPath := filepath.Join(dir, name)
+		dbg.ChkErr(ioutil.WriteFile(flPath, []byte(data), 0644), "WriteFile")
+		return flPath
+	}
+	base := write("app.cfg", "shell := sh\nsep := /\narch := any\npool := 4\n")
+	osFile := write("app_"+runtime.GOOS+".cfg", "sep := native\n")
+	archFile := write("app_"+runtime.GOARCH+".cfg", "arch := native\npool := 8\n")
+	bothFile := write("app_"+runtime.GOOS+"_"+runtime.GOARCH+".cfg", "pool := 16\n")
+	write("app_plan9.cfg", "shell := rc\n")
+
+	if l := PlatformFiles(base); !reflect.DeepEqual([]string{base, osFile, archFile, bothFile}, l) {
+		dbg.Error("PlatformFiles: %v", l)
+		t.Fail()
+	}
+	c, err := LoadConfigPlatform(base)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{"shell": "sh", "sep": "native", "arch": "native", "pool": "16"}
+	for p, want := range expect {
+		if got, _ := c.Value(p); want != got {
+			dbg.Error("LoadConfigPlatform %s: %q", p, got)
+			t.Fail()
+		}
+	}
+
+	plan9 := filepath.Join(dir, "app_plan9.cfg")
+	if l := platformFiles(base, "plan9", "mips"); !reflect.DeepEqual([]string{base, plan9}, l) {
+		dbg.Error("platformFiles plan9: %v", l)
+		t.Fail()
+	}
+	if l := platformFiles(filepath.Join(dir, "none.cfg"), runtime.GOOS, runtime.GOARCH); 1 != len(l) {
+		dbg.Error("platformFiles none: %v", l)
+		t.Fail()
+	}
+	if _, err := LoadConfigPlatform(filepath.Join(dir, "none.cfg")); !os.IsNotExist(err) {
+		dbg.Error("LoadConfigPlatform none: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,95 @@
+package cfg
+
+import (
+	"errors"
+	"sort"
+)
+
+/*
+	Returns the config data as SetValue would edit it, along with what Lint
+	 finds in the edited data and the errors parsing it gives (those of
+	 registered validators included) as findings of SeverityError on the
+	 line of the label path they're for, in line order; nothing is written,
+	 so a GUI or CLI can preview an edit before saving it:
+
+		out, findings, err := cfg.CheckEdit(src, "db:port", "80")
+		if nil == err && 0 == len(findings) {
+			err = cfg.SetValueInFile("app.cfg", "db:port", "80")
+		}
+
+	The error is only for an edit that can't be made, as with SetValue
+*/
+func CheckEdit(original []byte, path, newValue string) ([]byte, []Finding, error) {
+	return Options{}.CheckEdit(original, path, newValue)
+}
+
+/*
+	As CheckEdit, linting and parsing the edited data using these options,
+	 so their Rules are checked too
+*/
+func (o Options) CheckEdit(original []byte, path, newValue string) ([]byte, []Finding, error) {
+	return o.checkEdit(original, path, newValue, nil)
+}
+
+/*
+	As CheckEdit, also validating the edited config against the schema,
+	 each violation being a finding
+*/
+func (s *Schema) CheckEdit(original []byte, path, newValue string) ([]byte, []Finding, error) {
+	return Options{}.checkEdit(original, path, newValue, s)
+}
+
+// ------------------------------------------------------------------------- //
+
+// checkEdit makes the edit and checks the result, against the schema when
+// it's not nil
+func (o Options) checkEdit(src []byte, path, value string, s *Schema) ([]byte, []Finding, error) {
+	out, err := SetValue(src, path, value)
+	if nil != err {
+		return nil, nil, err
+	}
+	l := &linter{o: o, findings: o.Lint(out)}
+	c, err := o.Parse(string(out))
+	if nil == err && nil != s {
+		err = s.Validate(c)
+	}
+	for _, err := range editErrors(err) {
+		at := path
+		var pe *PathError
+		var re *RequiresError
+		if errors.As(err, &pe) {
+			at = pe.Path
+		} else if errors.As(err, &re) {
+			at = re.Path
+		}
+		l.add(LineOf(out, at), SeverityError, "%s", err.Error())
+	}
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		return l.findings[i].Line < l.findings[j].Line
+	})
+	return out, l.findings, nil
+}
+
+// editErrors splits the error of parsing or validating an edit into the
+// errors it holds
+func editErrors(err error) []error {
+	var errs []error
+	switch e := err.(type) {
+	case nil:
+	case *MultiError:
+		for _, err := range e.Errs {
+			errs = append(errs, editErrors(err)...)
+		}
+	case *RuleError:
+		for _, err := range e.Errs {
+			errs = append(errs, editErrors(err)...)
+		}
+	case *SchemaError:
+		for _, pe := range e.Violations {
+			errs = append(errs, pe)
+		}
+	default:
+		errs = append(errs, err)
+	}
+	return errs
+}
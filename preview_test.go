@@ -0,0 +1,43 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestCheckEdit(t *testing.T) {
+	src := []byte("name := app\nname := dup\ndb (\n\tport := 5432\n\thost := x\n)\n")
+	out, findings, err := CheckEdit(src, "db:port", "80")
+	if nil != err || !strings.Contains(string(out), "\tport := 80\n") {
+		dbg.Error("CheckEdit: %v\n%s", err, out)
+		t.FailNow()
+	}
+	if 1 != len(findings) || 2 != findings[0].Line || SeverityWarning != findings[0].Severity {
+		dbg.Error("CheckEdit lint findings: %v", findings)
+		t.Fail()
+	}
+
+	s := NewSchema().Required("db:port", SchemaInt).Range(1024, 65535)
+	_, findings, err = s.CheckEdit(src, "db:port", "80")
+	if nil != err || 2 != len(findings) || 4 != findings[1].Line || SeverityError != findings[1].Severity ||
+		!strings.Contains(findings[1].Message, "db:port") {
+		dbg.Error("Schema.CheckEdit: %v %v", err, findings)
+		t.Fail()
+	}
+	_, findings, _ = Options{Rules: []Rule{Requires("db:host", "db:user")}}.CheckEdit(src, "name", "x")
+	if 2 != len(findings) || 5 != findings[1].Line || !strings.Contains(findings[1].Message, "requires db:user") {
+		dbg.Error("Options.CheckEdit rules: %v", findings)
+		t.Fail()
+	}
+
+	if _, _, err := CheckEdit(src, "db:missing", "x"); nil == err {
+		dbg.Error("CheckEdit of a missing label path")
+		t.Fail()
+	}
+	if string(src) != "name := app\nname := dup\ndb (\n\tport := 5432\n\thost := x\n)\n" {
+		dbg.Error("CheckEdit changed the original")
+		t.Fail()
+	}
+}
@@ -0,0 +1,74 @@
+package cfg
+
+import (
+	"sync"
+)
+
+type (
+	/*
+		A BlockProcessor is given the text of a block at a label path as
+		 it's parsed, returning the text to keep in its place (e.g. with
+		 the common indent trimmed or a template expanded), or an error if
+		 it's not valid
+	*/
+	BlockProcessor func(path, block string) (string, error)
+
+	pathProcessor struct {
+		pattern string
+		fn      BlockProcessor
+	}
+)
+
+var (
+	processorLock sync.RWMutex
+	processors    []pathProcessor
+)
+
+/*
+	Registers a processor for the block (< >, heredoc or custom section
+	 giving a ConfigBlock) of every label path matching the pattern (see
+	 MatchPath), run as config data is parsed by Parse, the Load* functions
+	 and HandleConfigData, so what's passed on or kept in the Config is the
+	 processed text:
+
+		cfg.RegisterBlockProcessor("sql_*", func(path, block string) (string, error) {
+			return strings.TrimSpace(block), nil
+		})
+
+	An error stops the parse, returned as a *PathError for the block's label
+	 path; HandleConfigBlocks reports it and skips the block.  Processors
+	 matching a label path are run in turn in the order registered, each
+	 given the text returned by the last; registering a nil fn removes any
+	 processor for the pattern
+*/
+func RegisterBlockProcessor(pattern string, fn BlockProcessor) {
+	processorLock.Lock()
+	defer processorLock.Unlock()
+	for i, p := range processors {
+		if p.pattern == pattern {
+			processors = append(processors[:i], processors[i+1:]...)
+			break
+		}
+	}
+	if nil != fn {
+		processors = append(processors, pathProcessor{pattern, fn})
+	}
+}
+
+// ------------------------------------------------------------------------- //
+
+// processBlock runs the processors matching the label path on the block
+func processBlock(path, block string) (string, error) {
+	processorLock.RLock()
+	defer processorLock.RUnlock()
+	for _, p := range processors {
+		if MatchPath(p.pattern, path) {
+			b, err := p.fn(path, block)
+			if nil != err {
+				return "", &PathError{path, err}
+			}
+			block = b
+		}
+	}
+	return block, nil
+}
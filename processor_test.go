@@ -0,0 +1,53 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestRegisterBlockProcessor(t *testing.T) {
+	RegisterBlockProcessor("sql_*", func(path, block string) (string, error) {
+		return strings.ToUpper(strings.TrimSpace(block)), nil
+	})
+	defer RegisterBlockProcessor("sql_*", nil)
+	RegisterBlockProcessor("*:json", func(path, block string) (string, error) {
+		if !json.Valid([]byte(block)) {
+			return "", errors.New("Invalid JSON")
+		}
+		return block, nil
+	})
+	defer RegisterBlockProcessor("*:json", nil)
+
+	c, err := Parse("sql_get <\n  select 1\n>\nsql_put <<END\n  insert\nEND\nnotes <\n  as is\n>\n" +
+		"api (\n\tjson <\n\t{\"a\": 1}\n\t>\n)\n")
+	if nil != err {
+		dbg.Error("RegisterBlockProcessor: %v", err)
+		t.FailNow()
+	}
+	for path, want := range map[string]string{"sql_get": "SELECT 1", "sql_put": "INSERT", "notes": "  as is"} {
+		if got, _ := c.Value(path); want != got {
+			dbg.Error("RegisterBlockProcessor %s: %q", path, got)
+			t.Fail()
+		}
+	}
+
+	_, err = Parse("api (\n\tjson <\n\t{oops\n\t>\n)\n")
+	var pe *PathError
+	if !errors.As(err, &pe) || "api:json" != pe.Path {
+		dbg.Error("RegisterBlockProcessor invalid: %v", err)
+		t.Fail()
+	}
+
+	blocks := map[string]string{}
+	HandleConfigBlocks("sql_a <\nx\n>\n", func(label, block string) {
+		blocks[label] = block
+	})
+	if "X" != blocks["sql_a"] {
+		dbg.Error("RegisterBlockProcessor HandleConfigBlocks: %v", blocks)
+		t.Fail()
+	}
+}
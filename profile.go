@@ -0,0 +1,182 @@
+package cfg
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jayacarlson/dbg"
+)
+
+var (
+	ErrNoSuchProfile = errors.New("No such config profile")
+	ErrProfileEnd    = errors.New("Missing ')' ending config profile")
+
+	// profile name (
+	// 1: name
+	profileRex = regexp.MustCompile(`^profile[ \t]+(\w+)[ \t]*\([ \t]*$`)
+)
+
+/*
+	Reads the config file and parses it into a Config tree, merging the named
+	 profile over the base keys, see ParseProfile
+*/
+func LoadConfigProfile(flPath, profile string) (*Config, error) {
+	return Options{}.LoadConfigProfile(flPath, profile)
+}
+
+/*
+	Reads the config file and passes the data of the base keys merged with
+	 the named profile to handler, in the order the base keys were found
+	 followed by any keys only found in the profile
+*/
+func LoadConfigDataProfile(flPath, profile string, f func(t ConfigType, label string, data []string)) error {
+	c, err := LoadConfigProfile(flPath, profile)
+	if nil != err {
+		return err
+	}
+	c.flatten(&c.root, func(n *Node) {
+		f(n.Type, n.Path, n.Data)
+	})
+	return nil
+}
+
+/*
+	Parses the config data into a Config tree, merging the named profile over
+	 the base keys; a profile is a top level section holding TAB indented
+	 config data as a (data) container does:
+
+		port := 8080
+		profile dev (
+			debug := true
+		)
+		profile prod (
+			port := 80
+		)
+
+	Keys found in the profile replace the base keys of the same label path,
+	 every other profile is ignored.  An empty profile name only removes the
+	 profiles, otherwise an unknown name returns ErrNoSuchProfile
+*/
+func (o Options) ParseProfile(str, profile string) (*Config, error) {
+	str, err := o.preprocess(nil, str)
+	if nil != err {
+		return nil, err
+	}
+	return o.parseProfile(str, profile)
+}
+
+/*
+	As LoadConfigProfile, using these options
+*/
+func (o Options) LoadConfigProfile(flPath, profile string) (*Config, error) {
+	if nil != o.Cache && Stdin != flPath {
+		return o.Cache.load(o, flPath, flPath+"\x00"+profile, func(o Options) (*Config, error) {
+			o.Cache = nil
+			return o.LoadConfigProfile(flPath, profile)
+		})
+	}
+	o, loaded := o.loading(flPath)
+	data, err := o.readConfig(flPath)
+	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
+		return nil, err
+	}
+	c, err := o.parseProfile(data, profile)
+	if nil != err {
+		return nil, err
+	}
+	loaded(c)
+	return c, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+func (o Options) parseProfile(str, profile string) (*Config, error) {
+	base, prof, err := o.splitProfiles(str, profile)
+	if nil != err {
+		return nil, err
+	}
+	// references may be to or from the profile, so interpolate after merging
+	po := o
+	po.Interpolate, po.partial = false, true
+	c, err := po.parse(base)
+	if nil != err {
+		return nil, err
+	}
+	p, err := po.parse(prof)
+	if nil != err {
+		return nil, err
+	}
+	c.merge(p)
+	c.opts = o
+	if err = c.underlay(); nil != err {
+		return nil, err
+	}
+	if o.Interpolate {
+		if err = c.interpolate(); nil != err {
+			return nil, err
+		}
+	}
+	if o.Expressions {
+		if err = c.evaluate(); nil != err {
+			return nil, err
+		}
+	}
+	if err = c.validated(); nil != err {
+		return nil, err
+	}
+	return c, nil
+}
+
+// splitProfiles removes all profile sections from the config data, returning
+// the remaining base data and the TAB stripped data of the named profile
+func (o Options) splitProfiles(str, profile string) (base, prof string, err error) {
+	lines, found := strings.Split(str, "\n"), "" == profile
+	result, body := lines[:0], []string{}
+	for i := 0; i < len(lines); i++ {
+		x := profileRex.FindStringSubmatch(lines[i])
+		if nil == x {
+			result = append(result, lines[i])
+			continue
+		}
+		j := i + 1
+		for j < len(lines) && ")" != strings.TrimRight(lines[j], " \t") {
+			j++
+		}
+		if j == len(lines) {
+			dbg.Error("Missing end char for config profile: %s", x[1])
+			return "", "", ErrProfileEnd
+		}
+		if profile == x[1] {
+			body, found = append(body, lines[i+1:j]...), true
+		}
+		i = j
+	}
+	if !found {
+		dbg.Error("No such config profile: %s", profile)
+		return "", "", ErrNoSuchProfile
+	}
+	if 0 != len(body) {
+		if prof, err = o.removeLeadingTabs(strings.Join(body, "\n") + "\n"); nil != err {
+			return "", "", err
+		}
+	}
+	return strings.Join(result, "\n"), prof, nil
+}
+
+// merge replaces the data of each entry with the same label path the other
+// config has, adding those it doesn't have; with Options.AppendLists the
+// data of lists of the same type is appended instead
+func (c *Config) merge(other *Config) {
+	other.flatten(&other.root, func(n *Node) {
+		if old := c.nodes[n.Path]; nil != old && ConfigGroup != old.Type {
+			if c.opts.AppendLists && old.Type == n.Type && (ConfigItems == n.Type || ConfigLines == n.Type || ConfigDict == n.Type) {
+				old.Data = append(old.Data[:len(old.Data):len(old.Data)], n.Data...)
+				return
+			}
+			old.Type, old.Data = n.Type, n.Data
+			return
+		}
+		c.add(n.Type, n.Path, n.Data)
+	})
+}
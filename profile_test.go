@@ -0,0 +1,65 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestProfiles(t *testing.T) {
+	got := map[string]string{}
+	order := []string{}
+	err := LoadConfigDataProfile("testdata/profiles.cfg", "prod", func(ct ConfigType, label string, data []string) {
+		got[label] = data[0]
+		order = append(order, label)
+	})
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"port":    "80",
+		"db:host": "db.example.com",
+		"db:pool": "4",
+		"hosts":   "a",
+	}
+	for k, want := range expect {
+		if got[k] != want {
+			dbg.Error("%s: %q", k, got[k])
+			t.Fail()
+		}
+	}
+	if _, ok := got["debug"]; ok || 4 != len(order) || "hosts" != order[3] {
+		dbg.Error("profile order: %v", order)
+		t.Fail()
+	}
+
+	c, err := LoadConfigProfile("testdata/profiles.cfg", "dev")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if v, _ := c.Value("port"); "8080" != v {
+		dbg.Error("dev port: %q", v)
+		t.Fail()
+	}
+	if b, err := c.GetBool("debug"); nil != err || !b {
+		dbg.Error("dev debug: %v", err)
+		t.Fail()
+	}
+
+	c, err = Options{}.ParseProfile("port := 1\nprofile x (\n\tport := 2\n)\n", "")
+	if v, _ := c.Value("port"); nil != err || "1" != v {
+		dbg.Error("no profile: %q %v", v, err)
+		t.Fail()
+	}
+	if _, err = LoadConfigProfile("testdata/profiles.cfg", "test"); !errors.Is(err, ErrNoSuchProfile) {
+		dbg.Error("unknown profile: %v", err)
+		t.Fail()
+	}
+	if _, err = (Options{}).ParseProfile("profile x (\n\tport := 2\n", "x"); !errors.Is(err, ErrProfileEnd) {
+		dbg.Error("unterminated profile: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,205 @@
+package cfg
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/jayacarlson/dbg"
+)
+
+var (
+	ErrBadEscape = errors.New("Malformed \\uXXXX escape")
+)
+
+/*
+	Reads the Java .properties file into a Config tree, see ParseProperties
+*/
+func LoadProperties(flPath string) (*Config, error) {
+	return Options{}.LoadProperties(flPath)
+}
+
+/*
+	Parses Java .properties data into a Config tree, the '.' separated
+	 parts of each key becoming a label path, so db.host = x is the value
+	 db:host:
+
+		# comment, ! comment
+		key = value, key: value or key value
+		key = a long \
+		      value         a '\' ending a line continues it
+		key = caf\u00e9  \uXXXX, \t, \n, \r & \f escapes are replaced
+
+	A later entry for the same key replaces the earlier one for lookups;
+	 a key that is both a value and the start of other keys, e.g. a & a.b,
+	 is an ErrWrongType
+*/
+func ParseProperties(str string) (*Config, error) {
+	return Options{}.ParseProperties(str)
+}
+
+/*
+	As LoadProperties, using these options
+*/
+func (o Options) LoadProperties(flPath string) (*Config, error) {
+	o, loaded := o.loading(flPath)
+	data, err := o.readText(flPath)
+	if dbg.ChkErr(err, "Failed to read properties file: %s (%v)", flPath, err) {
+		return nil, err
+	}
+	c, err := o.ParseProperties(data)
+	if nil != err {
+		return nil, err
+	}
+	loaded(c)
+	return c, nil
+}
+
+/*
+	As ParseProperties, using these options; the label characters of the
+	 options apply to each part of a key, e.g. "-" for max-active
+*/
+func (o Options) ParseProperties(str string) (*Config, error) {
+	c := newConfig()
+	c.opts = o
+	label := o.syntax().label
+	lines := strings.Split(normalizeEOL(str), "\n")
+	for i := 0; i < len(lines); i++ {
+		start := i
+		text := strings.TrimLeft(lines[i], " \t\f")
+		if "" == text || '#' == text[0] || '!' == text[0] {
+			continue
+		}
+		// join any continuation lines, dropping their leading whitespace
+		for continued(text) && i+1 < len(lines) {
+			i++
+			text = text[:len(text)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if continued(text) {
+			text = text[:len(text)-1]
+		}
+		fail := func(err error) (*Config, error) {
+			return nil, &LineError{start + 1, strings.TrimSpace(text), err}
+		}
+		key, value, err := propertiesEntry(text)
+		if nil != err {
+			return fail(err)
+		}
+		path := ""
+		for _, part := range strings.Split(key, ".") {
+			if !label.MatchString(part) {
+				return fail(ErrIllegalLabel)
+			}
+			if n := c.nodes[path]; "" != path && nil != n && ConfigGroup != n.Type {
+				return fail(ErrWrongType)
+			}
+			path = joinPath(path, part)
+		}
+		if n := c.nodes[path]; nil != n && ConfigGroup == n.Type {
+			return fail(ErrWrongType)
+		}
+		c.add(ConfigValue, path, []string{value})
+	}
+	var err error
+	if DuplicatesAllowed != o.Duplicates {
+		err = c.duplicates(&c.root, o.Duplicates)
+	}
+	if nil == err && nil != o.Keys {
+		err = c.decrypt(o.Keys)
+	}
+	if nil == err && o.Interpolate {
+		err = c.interpolate()
+	}
+	if nil == err && o.Expressions {
+		err = c.evaluate()
+	}
+	if nil == err {
+		err = c.validated()
+	}
+	if nil != err {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// continued is true for a line ending in an odd number of '\'
+func continued(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return 1 == n%2
+}
+
+// propertiesEntry splits the logical line into its unescaped key & value;
+// the key ends at the first unescaped '=', ':' or whitespace
+func propertiesEntry(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if '\\' == line[i] {
+			i++
+		} else if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	key, err := unescapeProperties(line[:end])
+	if nil != err {
+		return "", "", err
+	}
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, ":") {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	value, err := unescapeProperties(rest)
+	return key, value, err
+}
+
+// unescapeProperties replaces the escapes of a key or value, a '\' before
+// any other character is dropped
+func unescapeProperties(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if '\\' != s[i] || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", ErrBadEscape
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if nil != err {
+				return "", ErrBadEscape
+			}
+			i += 4
+			// a UTF-16 surrogate pair is two escapes
+			if utf16.IsSurrogate(rune(r)) && i+7 <= len(s) && `\u` == s[i+1:i+3] {
+				if lo, err := strconv.ParseUint(s[i+3:i+7], 16, 16); nil == err {
+					if pair := utf16.DecodeRune(rune(r), rune(lo)); unicode.ReplacementChar != pair {
+						r = uint64(pair)
+						i += 6
+					}
+				}
+			}
+			b.WriteRune(rune(r))
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
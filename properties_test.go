@@ -0,0 +1,53 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestProperties(t *testing.T) {
+	c, err := Options{LabelChars: "-"}.LoadProperties("testdata/service.properties")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	expect := map[string]string{
+		"db:host":           "db.example.com",
+		"db:port":           "5432",
+		"db:user":           "admin",
+		"greeting":          "caf\u00e9 \U0001F600",
+		"list":              "one, two, three",
+		"path":              `C:\temp\x`,
+		"empty":             "",
+		"server:max-active": "8",
+	}
+	if got := c.Flatten(); !reflect.DeepEqual(expect, got) {
+		dbg.Error("LoadProperties: %q", got)
+		t.Fail()
+	}
+
+	bad := map[string]error{
+		"a.b = 1\na = 2\n": ErrWrongType,
+		"a = 1\na.b = 2\n": ErrWrongType,
+		"a\\ b = 1\n":      ErrIllegalLabel,
+		"a..b = 1\n":       ErrIllegalLabel,
+		"max-active = 1\n": ErrIllegalLabel,
+		"a = \\u12\n":      ErrBadEscape,
+	}
+	for src, want := range bad {
+		var pe *LineError
+		if _, err := ParseProperties(src); !errors.Is(err, want) || !errors.As(err, &pe) {
+			dbg.Error("ParseProperties %q: %v", src, err)
+			t.Fail()
+		}
+	}
+	// the line an entry continued over several lines starts on
+	var pe *LineError
+	if _, err := ParseProperties("\nx = 1\\\n\\u00zz"); !errors.As(err, &pe) || 2 != pe.Line {
+		dbg.Error("ParseProperties line: %v", err)
+		t.Fail()
+	}
+}
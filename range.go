@@ -0,0 +1,146 @@
+package cfg
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// the most items the ranges of a single items entry expand to, when
+	// Options.MaxRangeItems isn't set
+	DefaultMaxRangeItems = 10000
+)
+
+var (
+	ErrBadRange      = errors.New("Invalid range in config items")
+	ErrRangeTooLarge = errors.New("Config item ranges expand to too many items")
+
+	// 1: first  2: last
+	numRangeRex = regexp.MustCompile(`^(\d+)-(\d+)$`)
+	// 1: prefix  2: first  3: last  4: suffix
+	itemRangeRex = regexp.MustCompile(`^(.*?)\[(\d+|[a-zA-Z])-(\d+|[a-zA-Z])\](.*)$`)
+)
+
+/*
+	Returns the items with each range expanded into the items it stands
+	 for, in order:
+
+		8000-8003      8000 8001 8002 8003
+		node[08-10]    node08 node09 node10
+		rack[a-c]-pdu  racka-pdu rackb-pdu rackc-pdu
+
+	A bracketed range is of numbers or single letters of the same case, and
+	 an item may hold more than one, giving every combination; numbers with
+	 leading zeros are padded to the width of the first.  A range running
+	 backwards (or mixing numbers & letters) is an ErrBadRange, and one that
+	 would give more than max items (DefaultMaxRangeItems for 0) in all is
+	 an ErrRangeTooLarge; both are returned as a *ListError
+*/
+func ExpandRanges(items []string, max int) ([]string, error) {
+	if max <= 0 {
+		max = DefaultMaxRangeItems
+	}
+	result := []string{}
+	for i, item := range items {
+		expanded, err := expandRange(item, max-len(result))
+		if nil != err {
+			return nil, &ListError{"", i, item, err}
+		}
+		result = append(result, expanded...)
+	}
+	return result, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// expandItems expands the ranges of the items of the label path when
+// Options.ExpandRanges is set
+func (o Options) expandItems(label string, items []string) ([]string, error) {
+	if !o.ExpandRanges {
+		return items, nil
+	}
+	expanded, err := ExpandRanges(items, o.MaxRangeItems)
+	if le, ok := err.(*ListError); ok {
+		le.Path = label
+	}
+	return expanded, err
+}
+
+// expandRange returns the items the item stands for, no more than max
+func expandRange(item string, max int) ([]string, error) {
+	if x := numRangeRex.FindStringSubmatch(item); nil != x {
+		return rangeItems("", x[1], x[2], max)
+	}
+	x := itemRangeRex.FindStringSubmatch(item)
+	if nil == x {
+		if max < 1 {
+			return nil, ErrRangeTooLarge
+		}
+		return []string{item}, nil
+	}
+	heads, err := rangeItems(x[1], x[2], x[3], max)
+	if nil != err {
+		return nil, err
+	}
+	rest, err := expandRange(x[4], max)
+	if nil != err {
+		return nil, err
+	}
+	if len(heads)*len(rest) > max {
+		return nil, ErrRangeTooLarge
+	}
+	result := make([]string, 0, len(heads)*len(rest))
+	for _, h := range heads {
+		for _, r := range rest {
+			result = append(result, h+r)
+		}
+	}
+	return result, nil
+}
+
+// rangeItems returns the items from first to last, each with the prefix
+func rangeItems(prefix, first, last string, max int) ([]string, error) {
+	isNum := func(s string) bool { return '0' <= s[0] && s[0] <= '9' }
+	var from, to int
+	format := func(n int) string { return string(rune(n)) }
+	switch {
+	case isNum(first) && isNum(last):
+		f, err := strconv.Atoi(first)
+		if nil != err {
+			return nil, ErrBadRange
+		}
+		t, err := strconv.Atoi(last)
+		if nil != err {
+			return nil, ErrBadRange
+		}
+		from, to = f, t
+		width := 0
+		if len(first) > 1 && '0' == first[0] {
+			width = len(first)
+		}
+		format = func(n int) string {
+			s := strconv.Itoa(n)
+			if len(s) < width {
+				s = strings.Repeat("0", width-len(s)) + s
+			}
+			return s
+		}
+	case !isNum(first) && !isNum(last) && (first[0] >= 'a') == (last[0] >= 'a'):
+		from, to = int(first[0]), int(last[0])
+	default:
+		return nil, ErrBadRange
+	}
+	if from > to {
+		return nil, ErrBadRange
+	}
+	if to-from >= max {
+		return nil, ErrRangeTooLarge
+	}
+	result := make([]string, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		result = append(result, prefix+format(n))
+	}
+	return result, nil
+}
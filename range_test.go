@@ -0,0 +1,70 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestExpandRanges(t *testing.T) {
+	tests := []struct {
+		items, want []string
+	}{
+		{[]string{"8000-8003", "9090"}, []string{"8000", "8001", "8002", "8003", "9090"}},
+		{[]string{"node[08-10]"}, []string{"node08", "node09", "node10"}},
+		{[]string{"rack[a-c]-pdu"}, []string{"racka-pdu", "rackb-pdu", "rackc-pdu"}},
+		{[]string{"r[1-2]n[A-B]"}, []string{"r1nA", "r1nB", "r2nA", "r2nB"}},
+		{[]string{"x-y", "[]", "a[9-12]"}, []string{"x-y", "[]", "a9", "a10", "a11", "a12"}},
+	}
+	for _, test := range tests {
+		if got, err := ExpandRanges(test.items, 0); nil != err || !reflect.DeepEqual(test.want, got) {
+			dbg.Error("ExpandRanges %v: %v %v", test.items, got, err)
+			t.Fail()
+		}
+	}
+	for _, items := range [][]string{{"10-1"}, {"n[a-C]"}, {"n[1-c]"}} {
+		if _, err := ExpandRanges(items, 0); !errors.Is(err, ErrBadRange) {
+			dbg.Error("ExpandRanges %v: %v", items, err)
+			t.Fail()
+		}
+	}
+	if _, err := ExpandRanges([]string{"a", "1-3"}, 3); !errors.Is(err, ErrRangeTooLarge) {
+		dbg.Error("ExpandRanges max: %v", err)
+		t.Fail()
+	}
+	if _, err := ExpandRanges([]string{"0-99999999"}, 0); !errors.Is(err, ErrRangeTooLarge) {
+		dbg.Error("ExpandRanges default max: %v", err)
+		t.Fail()
+	}
+}
+
+func TestOptionsExpandRanges(t *testing.T) {
+	src := "ports , { 8000-8002, 9090 }\nnodes {\n\tnode[01-03]\n}\n"
+	c, err := Options{ExpandRanges: true}.Parse(src)
+	if nil != err {
+		dbg.Error("ExpandRanges: %v", err)
+		t.FailNow()
+	}
+	if l, _ := c.GetStringList("ports"); !reflect.DeepEqual([]string{"8000", "8001", "8002", "9090"}, l) {
+		dbg.Error("ExpandRanges ports: %v", l)
+		t.Fail()
+	}
+	if l, _ := c.GetStringList("nodes"); !reflect.DeepEqual([]string{"node01", "node02", "node03"}, l) {
+		dbg.Error("ExpandRanges nodes: %v", l)
+		t.Fail()
+	}
+	c, _ = Parse(src)
+	if l, _ := c.GetStringList("nodes"); 1 != len(l) {
+		dbg.Error("ExpandRanges unset: %v", l)
+		t.Fail()
+	}
+
+	_, err = Options{ExpandRanges: true, MaxRangeItems: 10}.Parse("grp (\n\tnodes { n[1-20] }\n)\n")
+	var le *ListError
+	if !errors.As(err, &le) || "grp:nodes" != le.Path || !errors.Is(err, ErrRangeTooLarge) {
+		dbg.Error("ExpandRanges too large: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,190 @@
+package cfg
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+type (
+	/*
+		An AnchorError records an '@ref name' that could not be expanded, the
+		 Chain holds the anchors being expanded, outermost first
+	*/
+	AnchorError struct {
+		Chain  []string
+		Anchor string
+		Err    error
+	}
+
+	/*
+		A RefError records an @ref(label:path) value that could not be
+		 resolved, the Chain holds the label paths of the values followed to
+		 reach it, outermost first
+	*/
+	RefError struct {
+		Chain []string
+		Ref   string
+		Err   error
+	}
+)
+
+var (
+	ErrDuplicateAnchor = errors.New("Duplicate config anchor")
+	ErrUnendedAnchor   = errors.New("Missing end of anchored config data")
+
+	// label &name := value  or  label &name followed by a section opener
+	// 1: TABs  2: label  3: name  4: remaining
+	anchorRex = regexp.MustCompile(`^(\t*)([^\s&]+)[ \t]*&(\w+)[ \t]*((?:[,:]?[ \t]*[<\[{(]|:=).*)$`)
+	// the opener ending the first line of a multi-line section
+	// 1: opener  2: heredoc TAG
+	anchorOpenRex = regexp.MustCompile(`^(?:[,:][ \t]*)?(?:(<|\[|{|\()(?:b64|hex|\|)?|<<(\w+))[ \t]*$`)
+
+	// @ref name
+	// 1: TABs  2: name
+	refRex = regexp.MustCompile(`^(\t*)@ref[ \t]+(\w+)[ \t]*$`)
+
+	// @ref(label:path)
+	// 1: label path
+	lazyRefRex = regexp.MustCompile(`^@ref\(([^()\s]+)\)$`)
+)
+
+func (e *AnchorError) Error() string {
+	return "@ref " + e.Anchor + " (" + strings.Join(e.Chain, " -> ") + "): " + e.Err.Error()
+}
+
+func (e *AnchorError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RefError) Error() string {
+	return "@ref(" + e.Ref + ") (" + strings.Join(e.Chain, " -> ") + "): " + e.Err.Error()
+}
+
+func (e *RefError) Unwrap() error {
+	return e.Err
+}
+
+// ------------------------------------------------------------------------- //
+
+/*
+	references replaces each '@ref name' line with the section anchored by
+	 a '&name' following its label, so a section defined once can be reused:
+
+		tls &tls (
+			cert := /etc/ssl/app.pem
+		)
+		web (
+			@ref tls
+		)
+
+	gives web:tls:cert as well as tls:cert.  Any section, or a single
+	 'label &name := value', can be anchored; the referenced lines are given
+	 the same leading TABs as the @ref line, and may themselves hold @ref
+	 lines.  An unknown or circular reference returns an *AnchorError
+*/
+func (o Options) references(str string) (string, error) {
+	if !strings.Contains(str, "&") && !strings.Contains(str, "@ref") {
+		return str, nil
+	}
+	lines := strings.Split(str, "\n")
+	anchors := map[string][]string{}
+	for i, line := range lines {
+		x := anchorRex.FindStringSubmatch(line)
+		if nil == x {
+			continue
+		}
+		if _, ok := anchors[x[3]]; ok {
+			return "", &AnchorError{nil, x[3], ErrDuplicateAnchor}
+		}
+		lines[i] = x[1] + x[2] + " " + x[4]
+		end := i
+		if m := anchorOpenRex.FindStringSubmatch(x[4]); nil != m {
+			closer := x[1] + matching[m[1]]
+			if "" != m[2] {
+				closer = x[1] + m[2]
+			}
+			for end++; end < len(lines) && closer != strings.TrimRight(lines[end], " \t"); end++ {
+			}
+			if end == len(lines) {
+				return "", &AnchorError{nil, x[3], ErrUnendedAnchor}
+			}
+		}
+		section := make([]string, 0, end-i+1)
+		for _, l := range lines[i : end+1] {
+			section = append(section, strings.TrimPrefix(l, x[1]))
+		}
+		anchors[x[3]] = section
+	}
+	result, err := expandRefs(lines, anchors, nil)
+	if nil != err {
+		return "", err
+	}
+	return strings.Join(result, "\n"), nil
+}
+
+// expandRefs replaces the @ref lines, chain holds the anchors being expanded
+func expandRefs(lines []string, anchors map[string][]string, chain []string) ([]string, error) {
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		x := refRex.FindStringSubmatch(line)
+		if nil == x {
+			result = append(result, line)
+			continue
+		}
+		section, ok := anchors[x[2]]
+		if !ok {
+			return nil, &AnchorError{chain, x[2], ErrUnknownReference}
+		}
+		for _, c := range chain {
+			if c == x[2] {
+				return nil, &AnchorError{append(chain[:len(chain):len(chain)], x[2]), x[2], ErrCircularReference}
+			}
+		}
+		section, err := expandRefs(section, anchors, append(chain[:len(chain):len(chain)], x[2]))
+		if nil != err {
+			return nil, err
+		}
+		for _, l := range section {
+			if "" != l {
+				l = x[1] + l
+			}
+			result = append(result, l)
+		}
+	}
+	return result, nil
+}
+
+// deref returns the value of a value or block node; a value of
+// '@ref(label:path)' is the value at that label path when it's read, so it
+// mirrors the value after any overrides or merges, following each @ref
+// value in turn, and with Options.Secrets an '@secret(scheme:ref)' is
+// resolved, see RegisterSecretResolver.  An unknown, non value or circular reference
+// returns a *RefError
+func (c *Config) deref(n *Node) (string, error) {
+	chain, seen := []string{}, map[*Node]bool{}
+	for {
+		if ConfigValue != n.Type {
+			return n.Data[0], nil
+		}
+		x := lazyRefRex.FindStringSubmatch(n.Data[0])
+		if nil == x && c.opts.Secrets {
+			return secret(n.Data[0])
+		}
+		if nil == x {
+			return n.Data[0], nil
+		}
+		chain, seen[n] = append(chain, n.Path), true
+		r := c.node(x[1])
+		switch {
+		case nil == r:
+			return "", &RefError{chain, x[1], ErrUnknownReference}
+		case ConfigValue != r.Type && ConfigBlock != r.Type:
+			return "", &RefError{chain, x[1], ErrWrongType}
+		case seen[r]:
+			return "", &RefError{append(chain, r.Path), x[1], ErrCircularReference}
+		}
+		c.use(r, false)
+		n = r
+	}
+}
@@ -0,0 +1,128 @@
+package cfg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestReferences(t *testing.T) {
+	src := `
+tls &tls (
+	cert := /etc/ssl/app.pem
+	ciphers {
+		a b
+	}
+)
+port &port := 443
+web (
+	@ref tls
+	@ref port
+	url := http://x/?a=1&b=2
+)
+api (
+	inner &inner (
+		@ref tls
+	)
+)
+other (
+	@ref inner
+)
+script <
+run &bg
+>
+`
+	c, err := Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	for path, want := range map[string]string{
+		"tls:cert":             "/etc/ssl/app.pem",
+		"port":                 "443",
+		"web:tls:cert":         "/etc/ssl/app.pem",
+		"web:port":             "443",
+		"web:url":              "http://x/?a=1&b=2",
+		"api:inner:tls:cert":   "/etc/ssl/app.pem",
+		"other:inner:tls:cert": "/etc/ssl/app.pem",
+		"script":               "run &bg",
+	} {
+		if v, _ := c.Value(path); want != v {
+			dbg.Error("%s: %q", path, v)
+			t.Fail()
+		}
+	}
+	if l, _ := c.GetStringList("web:tls:ciphers"); 2 != len(l) {
+		dbg.Error("web:tls:ciphers: %v", l)
+		t.Fail()
+	}
+
+	var ae *AnchorError
+	if _, err = Parse("a (\n\t@ref nope\n)\n"); !errors.As(err, &ae) || "nope" != ae.Anchor || !errors.Is(err, ErrUnknownReference) {
+		dbg.Error("unknown: %v", err)
+		t.Fail()
+	}
+	if _, err = Parse("a &a (\n\tb &b (\n\t\t@ref a\n\t)\n)\n"); !errors.Is(err, ErrCircularReference) {
+		dbg.Error("circular: %v", err)
+		t.Fail()
+	}
+	if _, err = Parse("a &x := 1\nb &x := 2\n"); !errors.Is(err, ErrDuplicateAnchor) {
+		dbg.Error("duplicate: %v", err)
+		t.Fail()
+	}
+}
+
+func TestLazyReferences(t *testing.T) {
+	c, err := Parse("db (\n\tport := 5432\n)\nproxy (\n\tport := @ref(db:port)\n\tcopy := @ref(proxy:port)\n)\n" +
+		"loop (\n\ta := @ref(loop:b)\n\tb := @ref(loop:a)\n)\nbad := @ref(db)\nnone := @ref(no:such)\n")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if v, err := c.GetInt("proxy:copy"); nil != err || 5432 != v {
+		dbg.Error("@ref chain: %d %v", v, err)
+		t.Fail()
+	}
+	// the reference is read when the value is, so it follows overrides
+	if err = ApplyOverrides(c, []string{"db:port=6543"}); nil != err || "6543" != c.ValueOr("proxy:port", "") {
+		dbg.Error("@ref after override: %v %v", c.ValueOr("proxy:port", ""), err)
+		t.Fail()
+	}
+	if port, err := Get[int](c, "proxy:port"); nil != err || 6543 != port {
+		dbg.Error("@ref decoded: %v %v", port, err)
+		t.Fail()
+	}
+	var re *RefError
+	if _, err = c.GetInt("loop:a"); !errors.As(err, &re) || !errors.Is(err, ErrCircularReference) ||
+		"loop:a -> loop:b -> loop:a" != strings.Join(re.Chain, " -> ") {
+		dbg.Error("circular @ref: %v", err)
+		t.Fail()
+	}
+	if _, err = c.GetInt("bad"); !errors.Is(err, ErrWrongType) {
+		dbg.Error("@ref to a group: %v", err)
+		t.Fail()
+	}
+	if _, ok := c.Value("none"); ok {
+		dbg.Error("@ref to an unknown value")
+		t.Fail()
+	}
+	var pe *PathError
+	if _, err = c.GetString("none"); !errors.As(err, &pe) || "none" != pe.Path || !errors.Is(err, ErrUnknownReference) {
+		dbg.Error("GetString of an unknown @ref: %v", err)
+		t.Fail()
+	}
+	if _, err = c.GetString("missing"); !errors.As(err, &pe) || ErrNoSuchLabel != pe.Err {
+		dbg.Error("GetString of a missing label: %v", err)
+		t.Fail()
+	}
+	if _, err = c.GetInt("none"); !errors.As(err, &re) || "no:such" != re.Ref || !errors.Is(err, ErrUnknownReference) {
+		dbg.Error("unknown @ref: %v", err)
+		t.Fail()
+	}
+	if n := c.Lookup("proxy:port"); "@ref(db:port)" != n.Data[0] {
+		dbg.Error("@ref node data: %v", n.Data)
+		t.Fail()
+	}
+}
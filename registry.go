@@ -0,0 +1,117 @@
+package cfg
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type (
+	/*
+		A DecoderFunc converts the text of a ConfigValue, ConfigBlock or a
+		 single list entry into a value assignable to the decoded type
+	*/
+	DecoderFunc func(value string) (interface{}, error)
+
+	labelDecoder struct {
+		pattern string
+		fn      DecoderFunc
+	}
+)
+
+var (
+	ErrDecoderType = errors.New("Decoder returned a value of the wrong type")
+
+	decoderLock   sync.RWMutex
+	typeDecoders  = make(map[reflect.Type]DecoderFunc)
+	labelDecoders []labelDecoder
+)
+
+/*
+	Registers a decoder used by Unmarshal & Get for every value of type t,
+	 e.g.
+
+		cfg.RegisterDecoder(reflect.TypeOf(color.RGBA{}), parseRGBA)
+
+	A registered decoder takes precedence over encoding.TextUnmarshaler and
+	 the built in conversions; registering a nil fn removes the decoder
+*/
+func RegisterDecoder(t reflect.Type, fn DecoderFunc) {
+	decoderLock.Lock()
+	defer decoderLock.Unlock()
+	if nil == fn {
+		delete(typeDecoders, t)
+	} else {
+		typeDecoders[t] = fn
+	}
+}
+
+/*
+	Registers a decoder used by Unmarshal & Get for every label path matching
+	 the pattern (see MatchPath), whatever the type being decoded into; for
+	 ConfigItems and ConfigLines the decoder is called for each entry
+
+	Label decoders are checked in the order registered and take precedence
+	 over decoders registered by type; registering a nil fn removes any
+	 decoder for the pattern
+*/
+func RegisterLabelDecoder(pattern string, fn DecoderFunc) {
+	decoderLock.Lock()
+	defer decoderLock.Unlock()
+	for i, ld := range labelDecoders {
+		if ld.pattern == pattern {
+			labelDecoders = append(labelDecoders[:i], labelDecoders[i+1:]...)
+			break
+		}
+	}
+	if nil != fn {
+		labelDecoders = append(labelDecoders, labelDecoder{pattern, fn})
+	}
+}
+
+/*
+	Reports whether the label path matches the pattern; the pattern and path
+	 are compared one label at a time with each label of the pattern using
+	 the path.Match syntax, so "servers:*:port" matches "servers:web:port"
+	 but not "servers:web:tls:port"
+*/
+func MatchPath(pattern, label string) bool {
+	p, l := strings.Split(pattern, ":"), strings.Split(label, ":")
+	if len(p) != len(l) {
+		return false
+	}
+	for i := range p {
+		if ok, _ := path.Match(p[i], l[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ------------------------------------------------------------------------- //
+
+func findDecoder(t reflect.Type, label string) DecoderFunc {
+	decoderLock.RLock()
+	defer decoderLock.RUnlock()
+	for _, ld := range labelDecoders {
+		if MatchPath(ld.pattern, label) {
+			return ld.fn
+		}
+	}
+	return typeDecoders[t]
+}
+
+func setDecoded(rv reflect.Value, fn DecoderFunc, s string) error {
+	v, err := fn(s)
+	if nil != err {
+		return err
+	}
+	dv := reflect.ValueOf(v)
+	if !dv.IsValid() || !dv.Type().AssignableTo(rv.Type()) {
+		return ErrDecoderType
+	}
+	rv.Set(dv)
+	return nil
+}
@@ -0,0 +1,80 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+type (
+	testRGB struct {
+		R, G, B uint8
+	}
+)
+
+const (
+	registryTests = `
+theme (
+	fg := 255,128,0
+	bg := 0,0,0
+	name := dark
+	palette [
+		1,2,3
+		4,5,6
+	]
+)
+`
+)
+
+func parseRGB(s string) (interface{}, error) {
+	var c testRGB
+	if _, err := fmt.Sscanf(s, "%d,%d,%d", &c.R, &c.G, &c.B); nil != err {
+		return nil, err
+	}
+	return c, nil
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	RegisterDecoder(reflect.TypeOf(testRGB{}), parseRGB)
+	RegisterLabelDecoder("theme:name", func(s string) (interface{}, error) {
+		return strings.ToUpper(s), nil
+	})
+	defer RegisterDecoder(reflect.TypeOf(testRGB{}), nil)
+	defer RegisterLabelDecoder("theme:name", nil)
+
+	var theme struct {
+		Theme struct {
+			Fg, Bg  testRGB
+			Name    string
+			Palette []testRGB
+		}
+	}
+	c, _ := Parse(registryTests)
+	if n, err := Get[testRGB](c, "theme:fg"); nil != err || n != (testRGB{255, 128, 0}) {
+		dbg.Error("theme:fg: %v %v", n, err)
+		t.Fail()
+	}
+	err := c.Unmarshal(&theme)
+	if nil != err || theme.Theme.Bg != (testRGB{}) || theme.Theme.Name != "DARK" || len(theme.Theme.Palette) != 2 || theme.Theme.Palette[1].B != 6 {
+		dbg.Error("theme: %v %v", theme, err)
+		t.Fail()
+	}
+	RegisterLabelDecoder("theme:name", func(s string) (interface{}, error) { return 42, nil })
+	if _, err := Get[string](c, "theme:name"); !errors.Is(err, ErrDecoderType) {
+		dbg.Error("theme:name: %v", err)
+		t.Fail()
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	if !MatchPath("servers:*:port", "servers:web:port") || MatchPath("servers:*:port", "servers:web:tls:port") {
+		t.Fail()
+	}
+	if !MatchPath("*", "anything") || MatchPath("a:b", "a") {
+		t.Fail()
+	}
+}
@@ -0,0 +1,254 @@
+package cfg
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	/*
+		A Reloader keeps the current Config of a watched file, replacing it
+		 once a change is complete and the new config is valid, and passing
+		 the label paths that changed to its subscribers:
+
+			r, err := cfg.NewReloader("app.cfg", validate)
+			r.Subscribe(func(c *cfg.Config, changed []string) { ... })
+			r.SubscribePattern("listen:*", func(c *cfg.Config, changes []cfg.Change) { ... })
+			port, err := r.Config().GetInt("port")
+
+		Config is safe to call from any goroutine and always returns a
+		 complete snapshot, a Config is never changed once published
+	*/
+	Reloader struct {
+		flPath   string
+		opts     Options
+		validate func(*Config) error
+		current  atomic.Value // *Config
+		stop     func()
+
+		reloading sync.Mutex // serializes reloads
+		writing   sync.Mutex // serializes write-backs, see Set
+
+		mu     sync.Mutex // guards the fields below
+		timer  *time.Timer
+		subs   []func(old, c *Config, changes []Change)
+		values []*valueSub
+		errs   []func(err error)
+		edits  []valueEdit // the Set values not yet written
+		writer *time.Timer
+		closed bool
+	}
+)
+
+const (
+	DefaultReloadDelay = 100 * time.Millisecond
+)
+
+/*
+	Loads the config file, checking it with validate (which may be nil),
+	 and watches it for changes, see Options.NewReloader
+*/
+func NewReloader(flPath string, validate func(*Config) error) (*Reloader, error) {
+	return Options{}.NewReloader(flPath, validate)
+}
+
+/*
+	As NewReloader, using these options to load the file.  A change is only
+	 loaded once the file has been left alone for Options.ReloadDelay (or
+	 DefaultReloadDelay), so a number of quick writes give a single reload;
+	 a new config that fails to load or validate is passed to the OnError
+	 functions and the current one kept.  The file is watched as by
+	 Options.Watch
+*/
+func (o Options) NewReloader(flPath string, validate func(*Config) error) (*Reloader, error) {
+	r := &Reloader{flPath: flPath, opts: o, validate: validate}
+	c, err := r.load()
+	if nil != err {
+		return nil, err
+	}
+	r.current.Store(c)
+	r.stop = o.poll(flPath, func(error) {
+		r.changed()
+	})
+	return r, nil
+}
+
+/*
+	Returns the current config
+*/
+func (r *Reloader) Config() *Config {
+	return r.current.Load().(*Config)
+}
+
+/*
+	Adds a function called with each new config and the sorted label paths
+	 (as given by Flatten) added, removed or changed by it; the functions
+	 are called in turn, from the reloading goroutine
+*/
+func (r *Reloader) Subscribe(f func(c *Config, changed []string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs = append(r.subs, func(old, c *Config, _ []Change) {
+		f(c, changedPaths(old, c))
+	})
+}
+
+/*
+	Adds a function called with each new config that changes an entry whose
+	 label path matches the pattern (see MatchPath), an index of a repeated
+	 label being ignored, e.g. "listen:*" for listen:port and listen[1]:port;
+	 it's given only the matching Changes, holding the old and new data
+*/
+func (r *Reloader) SubscribePattern(pattern string, f func(c *Config, changes []Change)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs = append(r.subs, func(_, c *Config, changes []Change) {
+		matched := []Change{}
+		for _, ch := range changes {
+			if MatchPath(pattern, ch.Path) || MatchPath(pattern, unindexed(ch.Path)) {
+				matched = append(matched, ch)
+			}
+		}
+		if 0 != len(matched) {
+			f(c, matched)
+		}
+	})
+}
+
+/*
+	Adds a function called with the error of each failed reload
+*/
+func (r *Reloader) OnError(f func(err error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, f)
+}
+
+/*
+	Stops watching the file, Config still returns the last config; no
+	 subscriber is called once Close returns, so they must not call it.
+	 Values given to Set and not yet written are written first, a failed
+	 write being returned (as well as passed to the OnError functions), and
+	 the channels of SubscribeString etc are closed
+*/
+func (r *Reloader) Close() error {
+	r.stop()
+	// wait for any reload or Set in progress, so every value given to Set
+	// is queued before the last are written
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+	r.mu.Lock()
+	r.closed = true
+	if nil != r.timer {
+		r.timer.Stop()
+	}
+	values, errs := r.values, r.errs
+	r.values = nil
+	r.mu.Unlock()
+	err := r.Flush()
+	if nil != err {
+		for _, f := range errs {
+			f(err)
+		}
+	}
+	for _, v := range values {
+		v.close()
+	}
+	return err
+}
+
+// ------------------------------------------------------------------------- //
+
+func (r *Reloader) load() (*Config, error) {
+	c, err := r.opts.LoadConfig(r.flPath)
+	if nil == err && nil != r.validate {
+		err = r.validate(c)
+	}
+	if nil != err {
+		return nil, err
+	}
+	return c, nil
+}
+
+// changed (re)starts the delay before reloading
+func (r *Reloader) changed() {
+	delay := r.opts.ReloadDelay
+	if 0 == delay {
+		delay = DefaultReloadDelay
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	if nil == r.timer {
+		r.timer = time.AfterFunc(delay, r.reload)
+	} else {
+		r.timer.Reset(delay)
+	}
+}
+
+func (r *Reloader) reload() {
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+	r.mu.Lock()
+	closed, subs, values, errs := r.closed, r.subs, r.values, r.errs
+	pending := 0 != len(r.edits)
+	r.mu.Unlock()
+	if closed || pending {
+		// the write-back of the Set values reloads once it's done
+		return
+	}
+	c, err := r.load()
+	if nil != err {
+		for _, f := range errs {
+			f(err)
+		}
+		return
+	}
+	old := r.Config()
+	changes := Diff(old, c)
+	if 0 == len(changes) {
+		return
+	}
+	r.current.Store(c)
+	for _, f := range subs {
+		f(old, c, changes)
+	}
+	for _, v := range values {
+		v.send(c)
+	}
+}
+
+// changedPaths returns the sorted label paths, as given by Flatten, added,
+// removed or changed by the new config
+func changedPaths(old, c *Config) []string {
+	before, after := old.Flatten(), c.Flatten()
+	changed := []string{}
+	for p, v := range after {
+		if was, ok := before[p]; !ok || was != v {
+			changed = append(changed, p)
+		}
+	}
+	for p := range before {
+		if _, ok := after[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// unindexed returns the label path without the indexes of repeated labels
+func unindexed(path string) string {
+	elems := strings.Split(path, ":")
+	for i, elem := range elems {
+		if x := indexRex.FindStringSubmatch(elem); nil != x {
+			elems[i] = x[1]
+		}
+	}
+	return strings.Join(elems, ":")
+}
@@ -0,0 +1,138 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "app.cfg")
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("port := 8080\nhost := x\nlog := info\n"), 0644), "WriteFile")
+
+	errNoPort := errors.New("no port")
+	validate := func(c *Config) error {
+		if _, err := c.GetInt("port"); nil != err {
+			return errNoPort
+		}
+		return nil
+	}
+	r, err := Options{WatchInterval: 5 * time.Millisecond, ReloadDelay: 50 * time.Millisecond}.NewReloader(flPath, validate)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	defer r.Close()
+	type reload struct {
+		c       *Config
+		changed []string
+	}
+	reloads, errs := make(chan reload, 10), make(chan error, 10)
+	r.Subscribe(func(c *Config, changed []string) {
+		reloads <- reload{c, changed}
+	})
+	r.OnError(func(err error) {
+		errs <- err
+	})
+
+	// quick writes give a single reload of the last one
+	for _, data := range []string{"port := 1\n", "port := 22\nhost := x\n", "port := 9090\nhost := x\nname := app\n"} {
+		dbg.ChkErr(ioutil.WriteFile(flPath, []byte(data), 0644), "WriteFile")
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case rl := <-reloads:
+		if want := []string{"log", "name", "port"}; !reflect.DeepEqual(want, rl.changed) || rl.c != r.Config() {
+			dbg.Error("Reloader changed: %v", rl.changed)
+			t.Fail()
+		}
+	case <-time.After(5 * time.Second):
+		dbg.Error("Reloader didn't reload")
+		t.FailNow()
+	}
+	time.Sleep(100 * time.Millisecond)
+	if 0 != len(reloads) || "9090" != r.Config().ValueOr("port", "") {
+		dbg.Error("Reloader reloads: %d", len(reloads))
+		t.Fail()
+	}
+
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("port := none\n"), 0644), "WriteFile")
+	select {
+	case err := <-errs:
+		if !errors.Is(err, errNoPort) || "9090" != r.Config().ValueOr("port", "") {
+			dbg.Error("Reloader invalid config: %v", err)
+			t.Fail()
+		}
+	case <-time.After(5 * time.Second):
+		dbg.Error("Reloader didn't validate")
+		t.Fail()
+	}
+
+	if _, err := NewReloader(filepath.Join(dir, "missing.cfg"), nil); nil == err {
+		dbg.Error("NewReloader of a missing file")
+		t.Fail()
+	}
+}
+
+func TestReloaderPatterns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "app.cfg")
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("listen (\n\tport := 80\n)\ndb (\n\thost := x\n)\nlog := info\n"), 0644), "WriteFile")
+	r, err := Options{WatchInterval: 5 * time.Millisecond, ReloadDelay: 20 * time.Millisecond}.NewReloader(flPath, nil)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	defer r.Close()
+	listen, db := make(chan []Change, 10), make(chan []Change, 10)
+	r.SubscribePattern("listen:*", func(_ *Config, changes []Change) {
+		listen <- changes
+	})
+	r.SubscribePattern("db:*", func(_ *Config, changes []Change) {
+		db <- changes
+	})
+
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("listen (\n\tport := 8080\n)\nlisten (\n\tport := 443\n)\n"+
+		"db (\n\thost := x\n)\nlog := debug\n"), 0644), "WriteFile")
+	select {
+	case changes := <-listen:
+		want := []Change{
+			{"listen:port", ChangeRemoved, ConfigValue, []string{"80"}, nil},
+			{"listen[0]:port", ChangeAdded, ConfigValue, nil, []string{"8080"}},
+			{"listen[1]:port", ChangeAdded, ConfigValue, nil, []string{"443"}},
+		}
+		if !reflect.DeepEqual(want, changes) {
+			dbg.Error("SubscribePattern changes: %v", changes)
+			t.Fail()
+		}
+	case <-time.After(5 * time.Second):
+		dbg.Error("Reloader didn't reload")
+		t.FailNow()
+	}
+	time.Sleep(50 * time.Millisecond)
+	if 0 != len(db) {
+		dbg.Error("SubscribePattern called without a matching change: %v", <-db)
+		t.Fail()
+	}
+}
+
+func TestChangedPaths(t *testing.T) {
+	// Subscribe is given the paths of Flatten, not the indexed ones of Diff
+	old, _ := Parse("d : [\n\ta : 1\n]\nsrv (\n\tport := 1\n)\nsrv (\n\tport := 2\n)\n")
+	c, _ := Parse("d : [\n\ta : 2\n]\nsrv (\n\tport := 1\n)\nsrv (\n\tport := 3\n)\nx := 1\n")
+	if want, got := []string{"d:a", "srv:port", "x"}, changedPaths(old, c); !reflect.DeepEqual(want, got) {
+		dbg.Error("changedPaths: %v", got)
+		t.Fail()
+	}
+}
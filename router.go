@@ -0,0 +1,131 @@
+package cfg
+
+import (
+	"errors"
+	"strings"
+)
+
+type (
+	/*
+		A Router passes each config entry to the handlers registered for
+		 label patterns matching it, so each part of an application can
+		 handle its own entries rather than one HandleConfigData callback
+		 handling them all:
+
+			r := cfg.NewRouter()
+			r.Handle("servers:*:port", serverPort)
+			r.Handle("features:{items}", features)
+			err := r.Run(data)
+
+		The zero value is ready to use
+	*/
+	Router struct {
+		routes   []route
+		notFound func(t ConfigType, label string, data []string)
+		err      error
+	}
+
+	route struct {
+		pattern string
+		typed   bool // only entries of type t match
+		t       ConfigType
+		fn      func(t ConfigType, label string, data []string)
+	}
+)
+
+var (
+	ErrBadPattern = errors.New("Invalid config label pattern")
+
+	// the types selected by a {type} pattern element
+	routeTypes = map[string]ConfigType{
+		"value": ConfigValue, "block": ConfigBlock, "lines": ConfigLines, "items": ConfigItems,
+		"dict": ConfigDict, "binary": ConfigBinary, "table": ConfigTable,
+	}
+)
+
+/*
+	Returns a Router without any handlers
+*/
+func NewRouter() *Router {
+	return &Router{}
+}
+
+/*
+	Registers the handler for the entries matching the label pattern, see
+	 MatchPath; a last element of the pattern naming a type in braces, one
+	 of {value}, {block}, {lines}, {items}, {dict}, {binary} or {table},
+	 only matches entries of that type at the rest of the pattern, so
+	 "features:{items}" is the features entry when it's items.  Every
+	 handler of a matching pattern is called, in the order they were
+	 registered; an unknown type makes Run return a *PathError of
+	 ErrBadPattern
+*/
+func (r *Router) Handle(pattern string, fn func(t ConfigType, label string, data []string)) *Router {
+	rt := route{pattern: pattern, fn: fn}
+	if i := strings.LastIndex(pattern, ":{"); i >= 0 && strings.HasSuffix(pattern, "}") {
+		t, ok := routeTypes[pattern[i+2:len(pattern)-1]]
+		if !ok && nil == r.err {
+			r.err = &PathError{pattern, ErrBadPattern}
+		}
+		rt.pattern, rt.typed, rt.t = pattern[:i], true, t
+	}
+	r.routes = append(r.routes, rt)
+	return r
+}
+
+/*
+	Registers the handler for the entries no pattern matches
+*/
+func (r *Router) NotFound(fn func(t ConfigType, label string, data []string)) *Router {
+	r.notFound = fn
+	return r
+}
+
+/*
+	Passes each entry of the config data to the handlers of the patterns
+	 it matches, see HandleConfigData
+*/
+func (r *Router) Run(data string) error {
+	if nil != r.err {
+		return r.err
+	}
+	return HandleConfigData(data, r.Dispatch)
+}
+
+/*
+	Reads the config file and passes each entry to the handlers of the
+	 patterns it matches, see LoadConfigData
+*/
+func (r *Router) Load(flPath string) error {
+	if nil != r.err {
+		return r.err
+	}
+	return LoadConfigData(flPath, r.Dispatch)
+}
+
+/*
+	Passes the entry to the handlers of the patterns it matches; it's the
+	 callback Run gives HandleConfigData, so a Router can be used with any
+	 of the Handle* and Load* functions taking one, or Options
+*/
+func (r *Router) Dispatch(t ConfigType, label string, data []string) {
+	matched := false
+	for _, rt := range r.routes {
+		if rt.matches(t, label) {
+			matched = true
+			rt.fn(t, label, data)
+		}
+	}
+	if !matched && nil != r.notFound {
+		r.notFound(t, label, data)
+	}
+}
+
+// ------------------------------------------------------------------------- //
+
+func (rt *route) matches(t ConfigType, label string) bool {
+	if rt.typed && rt.t != t {
+		return false
+	}
+	return MatchPath(rt.pattern, label)
+}
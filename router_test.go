@@ -0,0 +1,48 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestRouter(t *testing.T) {
+	src := "servers (\n\tweb (\n\t\tport := 80\n\t)\n\tapi (\n\t\tport := 8080\n\t\thost := x\n\t)\n)\n" +
+		"features { a b }\nother {\n\tc\n}\nname := app\n"
+	got := map[string][]string{}
+	record := func(key string) func(ConfigType, string, []string) {
+		return func(_ ConfigType, label string, data []string) {
+			got[key] = append(got[key], label+"="+strings.Join(data, ","))
+		}
+	}
+	r := NewRouter()
+	r.Handle("servers:*:port", record("port"))
+	r.Handle("servers:*:*", record("server"))
+	r.Handle("features:{items}", record("features"))
+	r.Handle("name:{items}", record("name"))
+	r.NotFound(record("rest"))
+	if err := r.Run(src); nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	want := map[string][]string{
+		"port":     {"servers:web:port=80", "servers:api:port=8080"},
+		"server":   {"servers:web:port=80", "servers:api:port=8080", "servers:api:host=x"},
+		"features": {"features=a,b"},
+		"rest":     {"other=c", "name=app"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		dbg.Error("Router: %v", got)
+		t.Fail()
+	}
+
+	var pe *PathError
+	if err := NewRouter().Handle("a:{nope}", record("x")).Run(src); !errors.As(err, &pe) || "a:{nope}" != pe.Path ||
+		!errors.Is(err, ErrBadPattern) {
+		dbg.Error("Router bad pattern: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,158 @@
+package cfg
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	/*
+		A Rule checks a whole Config after it's parsed, for constraints
+		 that span label paths
+	*/
+	Rule func(c *Config) error
+
+	/*
+		A RuleError lists the errors of every failed Rule, errors.Is and
+		 errors.As check each of them
+	*/
+	RuleError struct {
+		Errs []error
+	}
+
+	/*
+		A RequiresError records a label path found without another it
+		 requires, see Requires
+	*/
+	RequiresError struct {
+		Path     string
+		Required string
+	}
+
+	/*
+		An OrderError records a value more than one that should be larger,
+		 see Ordered
+	*/
+	OrderError struct {
+		Lower, Upper string // the label paths
+	}
+)
+
+func (e *RuleError) Error() string {
+	s := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		s[i] = err.Error()
+	}
+	return "Config rules failed: " + strings.Join(s, "; ")
+}
+
+func (e *RuleError) Unwrap() []error {
+	return e.Errs
+}
+
+func (e *RequiresError) Error() string {
+	return e.Path + " requires " + e.Required
+}
+
+func (e *OrderError) Error() string {
+	return e.Lower + " is more than " + e.Upper
+}
+
+/*
+	Runs every rule on the config, returning a *RuleError listing the errors
+	 of those that fail, or nil if none do; Options.Rules are run this way
+	 when a config is parsed
+*/
+func CheckRules(c *Config, rules ...Rule) error {
+	e := &RuleError{}
+	for _, r := range rules {
+		if err := r(c); nil != err {
+			e.Errs = append(e.Errs, err)
+		}
+	}
+	if 0 == len(e.Errs) {
+		return nil
+	}
+	return e
+}
+
+/*
+	A Rule that when the label path is found each of the required paths is
+	 too, e.g. Requires("tls:cert", "tls:key")
+*/
+func Requires(path string, required ...string) Rule {
+	return func(c *Config) error {
+		if nil == c.node(path) {
+			return nil
+		}
+		for _, r := range required {
+			if nil == c.node(r) {
+				return &RequiresError{path, r}
+			}
+		}
+		return nil
+	}
+}
+
+/*
+	A Rule that the values of the label paths that are found don't decrease,
+	 e.g. Ordered("pool:min", "pool:max"); values are compared as numbers,
+	 durations or sizes (see ParseSize), whichever all of them can be read as
+*/
+func Ordered(paths ...string) Rule {
+	return func(c *Config) error {
+		found, values := []string{}, []string{}
+		for _, p := range paths {
+			if v, ok := c.Value(p); ok {
+				found, values = append(found, p), append(values, v)
+			}
+		}
+		nums, err := orderValues(values)
+		if nil != err {
+			return err
+		}
+		for i := 1; i < len(nums); i++ {
+			if nums[i-1] > nums[i] {
+				return &OrderError{found[i-1], found[i]}
+			}
+		}
+		return nil
+	}
+}
+
+// ------------------------------------------------------------------------- //
+
+// orderValues converts the values to numbers by the first of the forms
+// that all of them are in
+func orderValues(values []string) ([]float64, error) {
+	var first error
+	for _, conv := range []func(string) (float64, error){
+		func(s string) (float64, error) {
+			d, err := time.ParseDuration(s)
+			return float64(d), err
+		},
+		func(s string) (float64, error) {
+			n, err := ParseSize(s)
+			return float64(n), err
+		},
+		func(s string) (float64, error) {
+			return strconv.ParseFloat(s, 64)
+		},
+	} {
+		nums := make([]float64, len(values))
+		var err error
+		for i, v := range values {
+			if nums[i], err = conv(v); nil != err {
+				break
+			}
+		}
+		if nil == err {
+			return nums, nil
+		}
+		if nil == first {
+			first = err
+		}
+	}
+	return nil, first
+}
@@ -0,0 +1,59 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestRules(t *testing.T) {
+	o := Options{Rules: []Rule{
+		Requires("tls:cert", "tls:key"),
+		Ordered("pool:min", "pool:max"),
+	}}
+	if _, err := o.Parse("tls (\n\tcert := a.pem\n\tkey := a.key\n)\npool (\n\tmin := 2\n\tmax := 10\n)\n"); nil != err {
+		dbg.Error("Rules valid: %v", err)
+		t.Fail()
+	}
+	_, err := o.Parse("tls (\n\tcert := a.pem\n)\npool (\n\tmin := 20\n\tmax := 10\n)\n")
+	var re *RuleError
+	var req *RequiresError
+	var oe *OrderError
+	if !errors.As(err, &re) || 2 != len(re.Errs) ||
+		!errors.As(err, &req) || "tls:key" != req.Required ||
+		!errors.As(err, &oe) || "pool:min" != oe.Lower || "pool:max" != oe.Upper {
+		dbg.Error("Rules failing: %v", err)
+		t.Fail()
+	}
+	if s := "Config rules failed: tls:cert requires tls:key; pool:min is more than pool:max"; nil == err || s != err.Error() {
+		dbg.Error("Rules message: %v", err)
+		t.Fail()
+	}
+}
+
+func TestOrdered(t *testing.T) {
+	for str, ok := range map[string]bool{
+		"a := 500ms\nb := 2s\n":    true,
+		"a := 2s\nb := 500ms\n":    false,
+		"a := 4KB\nb := 1MB\n":     true,
+		"a := 1MB\nb := 4KB\n":     false,
+		"a := -1.5\nb := -1\n":     true,
+		"a := 3\n":                 true,
+		"a := 3\nb := 3\nc := 2\n": false,
+	} {
+		c, err := Parse(str)
+		if nil == err {
+			err = CheckRules(c, Ordered("a", "b", "c"))
+		}
+		if ok != (nil == err) {
+			dbg.Error("Ordered %q: %v", str, err)
+			t.Fail()
+		}
+	}
+	c, _ := Parse("a := 1\nb := x\n")
+	if err := CheckRules(c, Ordered("a", "b")); nil == err {
+		dbg.Error("Ordered non-numeric passed")
+		t.Fail()
+	}
+}
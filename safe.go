@@ -0,0 +1,59 @@
+package cfg
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+type (
+	/*
+		A PanicError is returned by ParseSafe in place of a panic while
+		 parsing, holding the value the parser panicked with and the stack
+		 it panicked from
+	*/
+	PanicError struct {
+		Value interface{}
+		Stack []byte
+	}
+)
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("Config parser panic: %v", e.Value)
+}
+
+/*
+	Parses config data of any bytes, such as that given by a user, into a
+	 Config tree as Parse does, but never panicking: a panic while parsing
+	 is returned as a *PanicError, and the parser failing to make progress
+	 through the data as ErrNoProgress rather than looping.  The data is
+	 decoded as by LoadConfig, a byte order mark removed and UTF-16 data
+	 transcoded, see Options.ParseSafe
+*/
+func ParseSafe(data []byte) (*Config, error) {
+	return Options{}.ParseSafe(data)
+}
+
+/*
+	As ParseSafe, using these options; data larger than Options.MaxSize
+	 gives a *TooLargeError.  A panic in a registered Validator or
+	 BlockProcessor, or a function given in the options such as Include, is
+	 recovered as well.  Sections are parsed in turn, Options.ParallelSections
+	 being ignored
+*/
+func (o Options) ParseSafe(data []byte) (c *Config, err error) {
+	defer func() {
+		if v := recover(); nil != v {
+			c, err = nil, &PanicError{v, debug.Stack()}
+		}
+	}()
+	if o.MaxSize > 0 && int64(len(data)) > o.MaxSize {
+		return nil, &TooLargeError{"", o.MaxSize}
+	}
+	str, err := decodeText("", data, o.MaxSize)
+	if nil != err {
+		return nil, err
+	}
+	// a panic in another goroutine can't be recovered
+	o.ParallelSections = false
+	return o.Parse(str)
+}
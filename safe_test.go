@@ -0,0 +1,97 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestParseSafe(t *testing.T) {
+	c, err := ParseSafe([]byte("\xef\xbb\xbfname := app\ndb (\n\tport := 80\n)\n"))
+	if nil != err || "80" != c.ValueOr("db:port", "") {
+		dbg.Error("ParseSafe: %v", err)
+		t.Fail()
+	}
+	if _, err := ParseSafe([]byte{0xff, 0xfe, 'a'}); ErrBadUTF16 != err {
+		dbg.Error("ParseSafe of odd UTF-16: %v", err)
+		t.Fail()
+	}
+	var tl *TooLargeError
+	if _, err := (Options{MaxSize: 4}).ParseSafe([]byte("name := app\n")); !errors.As(err, &tl) {
+		dbg.Error("ParseSafe over MaxSize: %v", err)
+		t.Fail()
+	}
+
+	RegisterBlockProcessor("g:boom", func(path, block string) (string, error) {
+		var m map[string]string
+		m[path] = block
+		return block, nil
+	})
+	defer RegisterBlockProcessor("g:boom", nil)
+	var pe *PanicError
+	for _, o := range []Options{{}, {ParallelSections: true}} {
+		c, err := o.ParseSafe([]byte("g (\n\tboom <\n\ttext\n\t>\n)\n"))
+		if nil != c || !errors.As(err, &pe) || 0 == len(pe.Stack) {
+			dbg.Error("ParseSafe of a panicking processor: %v", err)
+			t.Fail()
+		}
+	}
+}
+
+// the config data the fuzz targets start from
+func fuzzSeeds(f *testing.F) {
+	for _, s := range []string{
+		"",
+		"name := value\n",
+		"g (\n\ta := 1\n\tsub (\n\t\tb := 2\n\t)\n)\n",
+		"text <\nblock\n>\nlines [\n\tone\n\ttwo\n]\nitems {\n\ta b c\n}\ndict : [\n\tk : v\n]\n",
+		"doc <<END\nheredoc\nEND\nin ( a := 1, b := 2 )\nlist, { x, y }\n",
+		"bin <b64\naGk=\n>\nt [table\n\ta b\n\t1 2\n]\ndd <|\n\t  x\n>\n",
+		"cache [ttl=5m, optional] (\n\tsize := 1\n)\nv :==\n multi\n==\n",
+		"g (\n\tunended [\n)\n", "x <\n", "[|\n", "a :=\n(\n)\n", "\t(\n",
+	} {
+		f.Add([]byte(s))
+	}
+	files, _ := filepath.Glob(filepath.Join("testdata", "*.cfg"))
+	for _, fl := range files {
+		if data, err := ioutil.ReadFile(fl); nil == err {
+			f.Add(data)
+		}
+	}
+}
+
+func FuzzParseSafe(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) > 4096 {
+			// parsing is quadratic in the number of sections
+			return
+		}
+		for _, o := range []Options{{}, {Recover: true, KeepComments: true}, {ExpandRanges: true, DedentBlocks: true, QuotedItems: true}} {
+			c, err := o.ParseSafe(data)
+			var pe *PanicError
+			if errors.As(err, &pe) {
+				t.Fatalf("ParseSafe panic: %v\n%s", pe.Value, pe.Stack)
+			}
+			if nil == err && nil == c {
+				t.Fatal("ParseSafe gave neither a config nor an error")
+			}
+		}
+	})
+}
+
+func FuzzLexer(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		l, n := NewLexer(string(data)), 0
+		for _, ok := l.Next(); ok; _, ok = l.Next() {
+			// each line gives a few tokens at most
+			if n++; n > 8*(len(data)+1) {
+				t.Fatal("Lexer made no progress")
+			}
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package cfg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+/*
+	Writes the config to the file as WriteTo does, through a temporary file
+	 in the same directory that is synced and then renamed over the file,
+	 so a crash never leaves a truncated config.  An existing file keeps its
+	 mode (and owner where the system allows), a new file is created 0644
+*/
+func SaveConfig(flPath string, c *Config) error {
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); nil != err {
+		return err
+	}
+	return writeFile(flPath, buf.Bytes())
+}
+
+// ------------------------------------------------------------------------- //
+
+// writeFile atomically replaces the contents of the file, see SaveConfig
+func writeFile(flPath string, data []byte) error {
+	info, err := os.Stat(flPath)
+	if nil != err && !os.IsNotExist(err) {
+		return err
+	}
+	dir := filepath.Dir(flPath)
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(flPath)+".*")
+	if nil != err {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	mode := os.FileMode(0644)
+	if nil != info {
+		mode = info.Mode().Perm()
+		chown(tmp, info)
+	}
+	if _, err = tmp.Write(data); nil == err {
+		err = tmp.Chmod(mode)
+	}
+	if nil == err {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); nil == err {
+		err = cerr
+	}
+	if nil == err {
+		err = os.Rename(tmp.Name(), flPath)
+	}
+	if nil != err {
+		return err
+	}
+	// persist the rename itself, not possible on every system
+	if d, err := os.Open(dir); nil == err {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cfg
+
+import (
+	"os"
+)
+
+// chown is a no-op where files don't have unix owners
+func chown(fl *os.File, info os.FileInfo) {
+}
@@ -0,0 +1,46 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestSaveConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "app.cfg")
+	c, err := Parse("name := app\ngrp (\n\thost := x\n)\n")
+	dbg.ChkErr(err, "Parse")
+
+	if err := SaveConfig(flPath, c); nil != err {
+		dbg.Error("SaveConfig: %v", err)
+		t.FailNow()
+	}
+	if info, _ := os.Stat(flPath); nil == info || 0644 != info.Mode().Perm() {
+		dbg.Error("SaveConfig new file mode: %v", info)
+		t.Fail()
+	}
+	dbg.ChkErr(os.Chmod(flPath, 0600), "Chmod")
+	if err := SaveConfig(flPath, c); nil != err {
+		dbg.Error("SaveConfig: %v", err)
+		t.Fail()
+	}
+	if info, _ := os.Stat(flPath); nil == info || 0600 != info.Mode().Perm() {
+		dbg.Error("SaveConfig didn't keep the file mode: %v", info)
+		t.Fail()
+	}
+	saved, err := LoadConfig(flPath)
+	if nil != err || "x" != saved.ValueOr("grp:host", "") {
+		dbg.Error("SaveConfig gave a different config: %v", err)
+		t.Fail()
+	}
+	if files, _ := ioutil.ReadDir(dir); 1 != len(files) {
+		dbg.Error("SaveConfig left %d files", len(files))
+		t.Fail()
+	}
+}
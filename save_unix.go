@@ -0,0 +1,16 @@
+//go:build unix
+
+package cfg
+
+import (
+	"os"
+	"syscall"
+)
+
+// chown gives the file the owner & group of the original file, which fails
+// (and is ignored) unless running as root or the owner
+func chown(fl *os.File, info os.FileInfo) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		fl.Chown(int(st.Uid), int(st.Gid))
+	}
+}
@@ -0,0 +1,500 @@
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+type (
+	/*
+		An entry of the config data found by Scan; Label and Text are
+		 subslices of the data scanned, nothing is copied, so they're only
+		 valid while the data is unchanged.  Text is the trimmed value, the
+		 items of an inline section, or the lines of a section as written
+		 (their indent included, less the last end of line), read with
+		 EachLine, EachItem, EachPair or AppendText
+	*/
+	RawEntry struct {
+		Type     ConfigType // ConfigGroup at the start of each group, with no Text
+		Depth    int        // the number of groups the entry is inside
+		Label    []byte
+		Text     []byte
+		Comma    bool   // the items of a ConfigItems are separated by commas
+		Encoding string // "b64" or "hex" of a ConfigBinary, whose Text is still encoded
+		tabs     int    // the indent of each line of a block or multi-line value
+	}
+
+	// scanner scans a group, up being that of the group it's in, for the
+	// label paths of errors
+	scanner struct {
+		f     func(e RawEntry) error
+		label []byte
+		up    *scanner
+	}
+)
+
+var (
+	ErrIllegalSection = errors.New("Illegal config section opener")
+)
+
+/*
+	Scans the config data, calling f with each entry in order, without the
+	 allocations of HandleConfigData: for programs where reading a config
+	 shows up in startup profiles.  Groups aren't given as label paths, f is
+	 called with a ConfigGroup entry at the start of each group and every
+	 entry has its Depth, so a path is kept with a stack of labels:
+
+		labels := [8][]byte{}
+		err := cfg.Scan(data, func(e cfg.RawEntry) error {
+			if cfg.ConfigGroup == e.Type {
+				labels[e.Depth] = e.Label
+			}
+			...
+		})
+
+	A malformed section is a *PathError, and an error returned by f stops
+	 the scan, returning it.  Only the default syntax is scanned: each
+	 entry's Type and data (through the RawEntry methods) are as given to a
+	 HandleConfigData handler without Options, except that a <b64 or <hex
+	 block is left encoded (its trimmed lines given by EachLine) and a <|
+	 block keeps its common indent; custom sections, preprocessing
+	 directives and Options aren't handled
+*/
+func Scan(data []byte, f func(e RawEntry) error) error {
+	if len(data) >= 3 && 0xEF == data[0] && 0xBB == data[1] && 0xBF == data[2] {
+		data = data[3:]
+	}
+	s := scanner{f, nil, nil}
+	return s.group(data, 0)
+}
+
+/*
+	Calls f with each line of the entry's Text until f returns false: for
+	 a ConfigBlock or ConfigValue every line less its indent, as they're
+	 joined in the data HandleConfigData gives, otherwise only the trimmed
+	 lines that aren't blank or comments
+*/
+func (e RawEntry) EachLine(f func(line []byte) bool) {
+	raw := ConfigBlock == e.Type || ConfigValue == e.Type
+	for i := 0; i <= len(e.Text); {
+		line, next := nextLine(e.Text, i)
+		i = next
+		if raw {
+			line, _ = unindent(line, e.tabs)
+		} else if line = bytes.TrimSpace(line); 0 == len(line) || '#' == line[0] {
+			continue
+		}
+		if !f(line) {
+			return
+		}
+	}
+}
+
+/*
+	Calls f with each item of a ConfigItems entry until f returns false
+*/
+func (e RawEntry) EachItem(f func(item []byte) bool) {
+	e.EachLine(func(line []byte) bool {
+		for 0 != len(line) {
+			var item []byte
+			if e.Comma {
+				item, line = cut(line, ',')
+				item = bytes.TrimSpace(item)
+			} else {
+				item, line = nextField(line)
+			}
+			if 0 != len(item) && !f(item) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+/*
+	Calls f with the key and value of each line of a ConfigDict entry until
+	 f returns false, ignoring lines that aren't a key : value
+*/
+func (e RawEntry) EachPair(f func(key, value []byte) bool) {
+	e.EachLine(func(line []byte) bool {
+		n := labelLen(line)
+		rest := bytes.TrimLeft(line[n:], " \t")
+		if 0 == n || 0 == len(rest) || ':' != rest[0] {
+			return true
+		}
+		return f(line[:n], bytes.TrimLeft(rest[1:], " \t"))
+	})
+}
+
+/*
+	Appends the lines given by EachLine to dst, each but the last followed
+	 by a \n; for a block or value this is its text as HandleConfigData
+	 gives it, reusing dst avoids allocating it for every entry
+*/
+func (e RawEntry) AppendText(dst []byte) []byte {
+	first := true
+	e.EachLine(func(line []byte) bool {
+		if !first {
+			dst = append(dst, '\n')
+		}
+		dst, first = append(dst, line...), false
+		return true
+	})
+	return dst
+}
+
+// ------------------------------------------------------------------------- //
+
+// group scans the lines of a group, each indented by depth TABs
+func (s *scanner) group(src []byte, depth int) error {
+	for i := 0; i <= len(src); {
+		line, next := nextLine(src, i)
+		text, ok := unindent(line, depth)
+		if !ok {
+			return s.fail(nil, ErrIllegalDataBlock)
+		}
+		n := labelLen(text)
+		if 0 == n {
+			// a blank or comment line, or other text that's ignored
+			i = next
+			continue
+		}
+		label, rest := text[:n], bytes.TrimLeft(text[n:], " \t")
+		var err error
+		switch {
+		case bytes.HasPrefix(rest, []byte(":=")):
+			next, err = s.value(src, next, depth, label, rest[2:])
+		case bytes.HasPrefix(rest, []byte("<<")):
+			next, err = s.heredoc(src, next, depth, label, rest[2:])
+		default:
+			next, err = s.section(src, next, depth, label, rest)
+		}
+		if nil != err {
+			return err
+		}
+		i = next
+	}
+	return nil
+}
+
+// value delivers a label := value, or the :== multi-line value starting at
+// the line at i, returning where the next entry starts
+func (s *scanner) value(src []byte, i, depth int, label, value []byte) (int, error) {
+	e := RawEntry{Type: ConfigValue, Depth: depth, Label: label}
+	if 0 == len(value) || '=' != value[0] || 0 != len(bytes.TrimRight(value[1:], " \t")) {
+		e.Text = bytes.Trim(value, " \t")
+		return i, s.f(e)
+	}
+	for at := i; at <= len(src); {
+		line, next := nextLine(src, at)
+		text, ok := unindent(line, depth)
+		switch {
+		case !ok:
+			return 0, s.fail(nil, ErrIllegalDataBlock)
+		case bytes.Equal(bytes.TrimRight(text, " \t"), []byte(":==")):
+			e.Text, e.tabs = src[i:lineEnd(i, at)], depth+1
+			return next, s.f(e)
+		case 0 != len(text) && '\t' != text[0]:
+			return 0, s.fail(label, ErrIllegalDataBlock)
+		}
+		at = next
+	}
+	return 0, s.fail(label, ErrUnendedSection)
+}
+
+// heredoc delivers the label <<TAG block starting at the line at i
+func (s *scanner) heredoc(src []byte, i, depth int, label, rest []byte) (int, error) {
+	tag := rest[:labelLen(rest)]
+	if 0 == len(tag) || 0 != len(bytes.TrimRight(rest[len(tag):], " \t")) {
+		return i, nil
+	}
+	for at := i; at <= len(src); {
+		line, next := nextLine(src, at)
+		text, ok := unindent(line, depth)
+		if !ok {
+			return 0, s.fail(nil, ErrIllegalDataBlock)
+		}
+		if bytes.Equal(bytes.TrimRight(text, " \t"), tag) {
+			return next, s.f(RawEntry{Type: ConfigBlock, Depth: depth, Label: label, Text: src[i:lineEnd(i, at)], tabs: depth})
+		}
+		at = next
+	}
+	return 0, s.fail(label, ErrUnendedSection)
+}
+
+// section delivers the inline or multi-line section opened by rest, the
+// text after its label, with its lines starting at i; any other text is
+// ignored
+func (s *scanner) section(src []byte, i, depth int, label, rest []byte) (int, error) {
+	var sep byte
+	if 0 != len(rest) && (',' == rest[0] || ':' == rest[0]) {
+		sep, rest = rest[0], bytes.TrimLeft(rest[1:], " \t")
+	}
+	if 0 == len(rest) || !strings.ContainsRune("<[{(", rune(rest[0])) {
+		return i, nil
+	}
+	open := rest[0]
+	if end := bytes.TrimRight(rest, " \t"); len(end) > 1 && ('{' == open || '(' == open) && closerOf(open) == end[len(end)-1] && ':' != sep {
+		return i, s.inline(depth, label, bytes.Trim(end[1:len(end)-1], " \t"), open, ',' == sep)
+	}
+	var enc []byte
+	rest = rest[1:]
+	for _, x := range [][]byte{[]byte("b64"), []byte("hex"), []byte("table"), []byte("|")} {
+		if bytes.HasPrefix(rest, x) {
+			enc, rest = x, rest[len(x):]
+			break
+		}
+	}
+	if 0 != len(bytes.TrimRight(rest, " \t")) {
+		return i, nil
+	}
+	table := 0 != len(enc) && 't' == enc[0]
+	if (',' == sep && '{' != open) || (':' == sep && '[' != open) ||
+		(0 != len(enc) && !table && '<' != open) || (table && ('[' != open || 0 != sep)) {
+		return 0, s.fail(label, ErrIllegalSection)
+	}
+	body, next, err := s.body(src, i, depth, label, closerOf(open))
+	if nil != err {
+		return 0, err
+	}
+	e := RawEntry{Depth: depth, Label: label, Text: body, tabs: depth}
+	switch {
+	case '(' == open:
+		e.Type, e.Text = ConfigGroup, nil
+		if err = s.f(e); nil == err {
+			sub := scanner{s.f, label, s}
+			err = sub.group(body, depth+1)
+		}
+		return next, err
+	case '{' == open:
+		return next, s.items(e, ',' == sep)
+	case '<' == open && 0 != len(enc) && '|' != enc[0]:
+		e.Type = ConfigBinary
+		if e.Encoding = "b64"; 'h' == enc[0] {
+			e.Encoding = "hex"
+		}
+	case '<' == open:
+		e.Type = ConfigBlock
+	case ':' == sep:
+		e.Type = ConfigDict
+	case table:
+		e.Type = ConfigTable
+	default:
+		e.Type = ConfigLines
+	}
+	return next, s.f(e)
+}
+
+// body returns the lines of the section starting at i, up to the line of
+// just its closer, and where the line after that starts
+func (s *scanner) body(src []byte, i, depth int, label []byte, closer byte) ([]byte, int, error) {
+	for at := i; at <= len(src); {
+		line, next := nextLine(src, at)
+		text, ok := unindent(line, depth)
+		if !ok {
+			return nil, 0, s.fail(nil, ErrIllegalDataBlock)
+		}
+		if 1 == len(text) && strings.IndexByte(">]})", text[0]) >= 0 {
+			if closer != text[0] {
+				return nil, 0, s.fail(label, ErrMismatchedEnd)
+			}
+			return src[i:lineEnd(i, at)], next, nil
+		}
+		at = next
+	}
+	return nil, 0, s.fail(label, ErrUnendedSection)
+}
+
+// items delivers an items section, as an entry for each of its sub-lists
+// when it has any, see Options.itemLists
+func (s *scanner) items(e RawEntry, comma bool) error {
+	e.Type, e.Comma = ConfigItems, comma
+	nested := false
+	e.EachLine(func(line []byte) bool {
+		nested = isSubList(line)
+		return !nested
+	})
+	if !nested {
+		return s.f(e)
+	}
+	text := e.Text
+	for i := 0; i <= len(text); {
+		line, next := nextLine(text, i)
+		l := bytes.TrimSpace(line)
+		switch i = next; {
+		case 0 == len(l) || '#' == l[0]:
+			continue
+		case 1 == len(l) && '{' == l[0]:
+			// the sub-list runs to a line of just a }
+			start, end := i, len(text)
+			for i <= len(text) {
+				line, after := nextLine(text, i)
+				if l := bytes.TrimSpace(line); 1 == len(l) && '}' == l[0] {
+					end, i = lineEnd(start, i), after
+					break
+				}
+				i = after
+			}
+			if start > end {
+				start = end
+			}
+			e.Text = text[start:end]
+		case isSubList(l):
+			e.Text = l[1 : len(l)-1]
+		default:
+			e.Text = l
+		}
+		if err := s.f(e); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// inline delivers an inline items list or group, text being what's inside
+// its braces or parens
+func (s *scanner) inline(depth int, label, text []byte, open byte, comma bool) error {
+	if '{' == open {
+		return s.f(RawEntry{Type: ConfigItems, Depth: depth, Label: label, Text: text, Comma: comma || bytes.IndexByte(text, ',') >= 0})
+	}
+	if err := s.f(RawEntry{Type: ConfigGroup, Depth: depth, Label: label}); nil != err {
+		return err
+	}
+	s = &scanner{s.f, label, s}
+	// each label := value runs up to the next label, as inlineValues splits
+	// them
+	e, from, found := RawEntry{Type: ConfigValue, Depth: depth + 1}, 0, false
+	for at := 0; ; {
+		j := bytes.Index(text[at:], []byte(":="))
+		if j < 0 {
+			break
+		}
+		j, at = j+at, j+at+2
+		end := j
+		for end > from && (' ' == text[end-1] || '\t' == text[end-1]) {
+			end--
+		}
+		start := end
+		for start > from && isLabelChar(text[start-1]) {
+			start--
+		}
+		if start == end {
+			continue
+		}
+		if found {
+			e.Text = bytes.Trim(text[from:start], " \t")
+			if err := s.f(e); nil != err {
+				return err
+			}
+		} else if 0 != len(bytes.Trim(text[:start], " \t")) {
+			return s.fail(nil, ErrIllegalInline)
+		}
+		e.Label, from, found = text[start:end], at, true
+	}
+	if !found {
+		if 0 != len(bytes.Trim(text, " \t")) {
+			return s.fail(nil, ErrIllegalInline)
+		}
+		return nil
+	}
+	e.Text = bytes.Trim(text[from:], " \t")
+	return s.f(e)
+}
+
+// fail returns the error for the entry with the label (or of the group
+// when it's nil) as a *PathError
+func (s *scanner) fail(label []byte, err error) error {
+	path := string(label)
+	for ; nil != s.up; s = s.up {
+		path = joinPath(string(s.label), path)
+	}
+	return &PathError{strings.TrimSuffix(path, ":"), err}
+}
+
+// nextLine returns the line of src starting at i, less its \n or \r\n, and
+// where the next line starts, len(src)+1 after the last line
+func nextLine(src []byte, i int) ([]byte, int) {
+	line, next := src[i:], len(src)+1
+	if j := bytes.IndexByte(line, '\n'); j >= 0 {
+		line, next = line[:j], i+j+1
+	}
+	if n := len(line); 0 != n && '\r' == line[n-1] {
+		line = line[:n-1]
+	}
+	return line, next
+}
+
+// lineEnd returns the end of the text from start up to the line at at,
+// excluding the \n ending the line before it
+func lineEnd(start, at int) int {
+	if at > start {
+		return at - 1
+	}
+	return start
+}
+
+// unindent returns the line less depth TABs, or as blank when it's just
+// fewer TABs; false for any other line without them
+func unindent(line []byte, depth int) ([]byte, bool) {
+	for i := 0; i < depth; i++ {
+		if i == len(line) {
+			return line[i:], true
+		}
+		if '\t' != line[i] {
+			return nil, false
+		}
+	}
+	return line[depth:], true
+}
+
+// labelLen returns the length of the label (\w+) starting the text
+func labelLen(text []byte) int {
+	n := 0
+	for n < len(text) && isLabelChar(text[n]) {
+		n++
+	}
+	return n
+}
+
+func isLabelChar(c byte) bool {
+	return '_' == c || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// closerOf returns the character closing a section opened by open
+func closerOf(open byte) byte {
+	switch open {
+	case '<':
+		return '>'
+	case '[':
+		return ']'
+	case '{':
+		return '}'
+	}
+	return ')'
+}
+
+// isSubList reports whether the trimmed line of an items section starts a
+// sub-list, being just a { or wrapped in (parens)
+func isSubList(l []byte) bool {
+	return (1 == len(l) && '{' == l[0]) || (len(l) >= 2 && '(' == l[0] && ')' == l[len(l)-1])
+}
+
+// cut splits the text at the first sep
+func cut(text []byte, sep byte) ([]byte, []byte) {
+	if i := bytes.IndexByte(text, sep); i >= 0 {
+		return text[:i], text[i+1:]
+	}
+	return text, nil
+}
+
+// nextField returns the first run of non-space characters of the text and the
+// text after it
+func nextField(text []byte) ([]byte, []byte) {
+	text = bytes.TrimLeft(text, " \t\v\f\r\n")
+	i := bytes.IndexAny(text, " \t\v\f\r\n")
+	if i < 0 {
+		return text, nil
+	}
+	return text[:i], text[i:]
+}
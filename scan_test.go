@@ -0,0 +1,150 @@
+package cfg
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	scanTests = "name := app\r\nnotes :==\n\tfirst\n\n\t\tsecond\n:==\nsrv (\n\tports { 80, 443 }\n\topts ( a := 1 b := two words )\n" +
+		"\tdoc <<EOF\n\ttext\n\t)\n\tEOF\n\tkeys : [\n\t\tid : 7\n\t\tjunk\n\t]\n\trows [table\n\t\tname age\n\t]\n" +
+		"\tsets {\n\t\t(a b)\n\t\t{\n\t\t\tc\n\t\t\t# d\n\t\t}\n\t\te f\n\t}\n\tice <b64\n\t\taGk=\n\t>\n)\nempty ( )\n"
+)
+
+// scanned returns the entries found by Scan as HandleConfigData would
+// give them, one line each
+func scanned(data string) ([]string, error) {
+	result, labels := []string{}, [8]string{}
+	err := Scan([]byte(data), func(e RawEntry) error {
+		label := string(e.Label)
+		if e.Depth > 0 {
+			label = strings.Join(labels[:e.Depth], ":") + ":" + label
+		}
+		var d []string
+		switch e.Type {
+		case ConfigGroup:
+			labels[e.Depth] = string(e.Label)
+			return nil
+		case ConfigBinary:
+			b, err := base64.StdEncoding.DecodeString(string(e.AppendText(nil)))
+			if nil != err {
+				return err
+			}
+			d = []string{string(b)}
+		case ConfigBlock, ConfigValue:
+			d = []string{string(e.AppendText(nil))}
+		case ConfigItems:
+			d = []string{}
+			e.EachItem(func(item []byte) bool {
+				d = append(d, string(item))
+				return true
+			})
+		case ConfigDict:
+			d = []string{}
+			e.EachPair(func(key, value []byte) bool {
+				d = append(d, string(key), string(value))
+				return true
+			})
+		default:
+			d = []string{}
+			e.EachLine(func(line []byte) bool {
+				d = append(d, string(line))
+				return true
+			})
+		}
+		result = append(result, fmt.Sprintf("%d %s %q", e.Type, label, d))
+		return nil
+	})
+	return result, err
+}
+
+func TestScan(t *testing.T) {
+	for _, data := range []string{string(conf), scanTests} {
+		want := []string{}
+		(Options{}).HandleConfigData(data, func(t ConfigType, label string, d []string) {
+			want = append(want, fmt.Sprintf("%d %s %q", t, label, d))
+		})
+		got, err := scanned(data)
+		if nil != err || !compareEntries(want, got) {
+			dbg.Error("Scan: %v\n%s", err, strings.Join(got, "\n"))
+			t.Fail()
+		}
+	}
+
+	for _, bad := range []struct {
+		data, path string
+		err        error
+	}{
+		{"g (\n\ta [\n\t\tx\n\t}\n)\n", "g:a", ErrMismatchedEnd},
+		{"g (\nx := 1\n)\n", "g", ErrIllegalDataBlock},
+		{"a <\ntext\n", "a", ErrUnendedSection},
+		{"g (\n\ti ( x )\n)\n", "g:i", ErrIllegalInline},
+		{"a , [\n]\n", "a", ErrIllegalSection},
+	} {
+		var pe *PathError
+		if _, err := scanned(bad.data); !errors.As(err, &pe) || bad.path != pe.Path || bad.err != pe.Err {
+			dbg.Error("%q: %v", bad.data, err)
+			t.Fail()
+		}
+	}
+	stop := errors.New("stop")
+	n := 0
+	if err := Scan(conf, func(e RawEntry) error { n++; return stop }); stop != err || 1 != n {
+		dbg.Error("Scan not stopped: %v %d", err, n)
+		t.Fail()
+	}
+}
+
+func TestScanAllocs(t *testing.T) {
+	var buf []byte
+	count := 0
+	f := func(e RawEntry) error {
+		switch e.Type {
+		case ConfigBlock, ConfigValue:
+			buf = e.AppendText(buf[:0])
+		case ConfigItems:
+			e.EachItem(func(item []byte) bool { count++; return true })
+		case ConfigDict:
+			e.EachPair(func(key, value []byte) bool { count++; return true })
+		default:
+			e.EachLine(func(line []byte) bool { count++; return true })
+		}
+		return nil
+	}
+	Scan(conf, f)
+	if n := testing.AllocsPerRun(10, func() { Scan(conf, f) }); 0 != n {
+		dbg.Error("Scan allocations: %v", n)
+		t.Fail()
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(conf)))
+	for i := 0; i < b.N; i++ {
+		Scan(conf, func(e RawEntry) error { return nil })
+	}
+}
+
+func BenchmarkHandleConfigData(b *testing.B) {
+	data := string(conf)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(conf)))
+	for i := 0; i < b.N; i++ {
+		HandleConfigData(data, func(t ConfigType, label string, d []string) {})
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	data := string(conf)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(conf)))
+	for i := 0; i < b.N; i++ {
+		Parse(data)
+	}
+}
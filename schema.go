@@ -0,0 +1,430 @@
+package cfg
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	/*
+		The type expected of a label path by a Schema
+	*/
+	SchemaType int
+
+	/*
+		A Schema declares the label paths a config is expected to have, the
+		 type of each, whether it's required and any default, e.g.
+
+			s := cfg.NewSchema().
+				Required("db:host", cfg.SchemaValue).
+				Default("db:port", cfg.SchemaInt, "5432").
+				Optional("db:replicas", cfg.SchemaItems)
+			err := s.Validate(c)
+
+		A label path below a repeated group is checked in each of them
+	*/
+	Schema struct {
+		fields     []SchemaField
+		deprecated []Deprecation
+	}
+
+	/*
+		A SchemaField is the declaration of a single label path of a Schema
+	*/
+	SchemaField struct {
+		Path     string
+		Type     SchemaType
+		Required bool
+		Default  []string // the data added by ApplyDefaults, nil for none
+
+		// Constraints on the data found, see Range, Match & Len
+		Min, Max       *float64       // bounds of a numeric value, nil for none
+		Pattern        *regexp.Regexp // matched by a value, or each line or item
+		MinLen, MaxLen int            // the length of a value, or count of lines, items or dict entries, 0 for no limit
+
+		Sensitive bool // the entry holds a secret, see Schema.Sensitive
+	}
+
+	/*
+		A SchemaError lists every violation of a Schema found in a config,
+		 each a *PathError giving the label path, indexed for an entry of
+		 a repeated group (e.g. server[1]:port), and one of ErrNoSuchLabel,
+		 ErrWrongType, ErrUnknownLabel, ErrOutOfRange, ErrNoMatch, ErrBadLength
+		 or the error converting the value
+	*/
+	SchemaError struct {
+		Source     string // the file the config was loaded from, if any
+		Violations []*PathError
+	}
+)
+
+const (
+	SchemaValue    SchemaType = iota // a single line value
+	SchemaInt                        // a value read by GetInt
+	SchemaFloat                      // a value read by GetFloat
+	SchemaBool                       // a value read by GetBool
+	SchemaDuration                   // a value read by GetDuration
+	SchemaBytes                      // a value read by GetBytes
+	SchemaBlock
+	SchemaLines
+	SchemaItems
+	SchemaDict
+	SchemaGroup
+)
+
+var (
+	ErrBadSchema  = errors.New("Invalid config schema entry")
+	ErrOutOfRange = errors.New("Config value out of range")
+	ErrNoMatch    = errors.New("Config value doesn't match the schema pattern")
+	ErrBadLength  = errors.New("Config entry is too short or too long")
+
+	schemaTypes = map[string]SchemaType{
+		"string": SchemaValue, "value": SchemaValue, "int": SchemaInt, "float": SchemaFloat,
+		"bool": SchemaBool, "duration": SchemaDuration, "bytes": SchemaBytes, "block": SchemaBlock,
+		"lines": SchemaLines, "items": SchemaItems, "dict": SchemaDict, "group": SchemaGroup,
+	}
+)
+
+func (t SchemaType) String() string {
+	for _, name := range []string{"string", "int", "float", "bool", "duration", "bytes", "block", "lines", "items", "dict", "group"} {
+		if schemaTypes[name] == t {
+			return name
+		}
+	}
+	return "SchemaType(" + strconv.Itoa(int(t)) + ")"
+}
+
+func (e *SchemaError) Error() string {
+	s := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		s[i] = v.Error()
+	}
+	msg := "Config schema violations"
+	if "" != e.Source {
+		msg += " in " + e.Source
+	}
+	return msg + ": " + strings.Join(s, ", ")
+}
+
+/*
+	Returns an empty Schema
+*/
+func NewSchema() *Schema {
+	return &Schema{}
+}
+
+/*
+	Declares a label path that must be in the config
+*/
+func (s *Schema) Required(path string, t SchemaType) *Schema {
+	s.fields = append(s.fields, SchemaField{Path: path, Type: t, Required: true})
+	return s
+}
+
+/*
+	Declares a label path that may be left out of the config
+*/
+func (s *Schema) Optional(path string, t SchemaType) *Schema {
+	s.fields = append(s.fields, SchemaField{Path: path, Type: t})
+	return s
+}
+
+/*
+	Declares an optional label path with the data ApplyDefaults adds when
+	 it's left out; a single value for the value types, the lines or items
+	 for SchemaLines & SchemaItems, key & value pairs for SchemaDict
+*/
+func (s *Schema) Default(path string, t SchemaType, def ...string) *Schema {
+	s.fields = append(s.fields, SchemaField{Path: path, Type: t, Default: append([]string{}, def...)})
+	return s
+}
+
+/*
+	Constrains the numeric value of the last declared label path to between
+	 min and max inclusive; a SchemaDuration is compared in nanoseconds, a
+	 SchemaBytes in bytes and a SchemaValue must be a number (math.Inf can
+	 be given for a bound that isn't needed)
+*/
+func (s *Schema) Range(min, max float64) *Schema {
+	if f := s.last(); nil != f {
+		f.Min, f.Max = &min, &max
+	}
+	return s
+}
+
+/*
+	Requires the value of the last declared label path, or each of its lines
+	 or items, to match the regular expression
+*/
+func (s *Schema) Match(re *regexp.Regexp) *Schema {
+	if f := s.last(); nil != f {
+		f.Pattern = re
+	}
+	return s
+}
+
+/*
+	Limits the last declared label path to between min and max runes for a
+	 value, or lines, items or dictionary entries; 0 for either is no limit
+*/
+func (s *Schema) Len(min, max int) *Schema {
+	if f := s.last(); nil != f {
+		f.MinLen, f.MaxLen = min, max
+	}
+	return s
+}
+
+/*
+	Marks the last declared label path as holding a secret; Validate and
+	 ApplyDefaults add it to the Options.Sensitive patterns of the config,
+	 so it's redacted when the config is written out for logging
+*/
+func (s *Schema) Sensitive() *Schema {
+	if f := s.last(); nil != f {
+		f.Sensitive = true
+	}
+	return s
+}
+
+/*
+	Returns the declared label paths in the order they were declared
+*/
+func (s *Schema) Fields() []SchemaField {
+	return append([]SchemaField{}, s.fields...)
+}
+
+/*
+	Checks the config against the schema, returning a *SchemaError listing
+	 every violation, or nil if there are none; with Options.Strict each
+	 undeclared label path is a violation of ErrUnknownLabel
+*/
+func (s *Schema) Validate(c *Config) error {
+	s.markSensitive(c)
+	e := &SchemaError{Source: c.source}
+	for _, f := range s.fields {
+		c.schemaNodes(&c.root, "", strings.Split(f.Path, ":"), func(path string, n, _ *Node, _ []string) {
+			var err error
+			switch {
+			case nil == n:
+				if f.Required {
+					err = ErrNoSuchLabel
+				}
+			default:
+				err = c.schemaCheck(f.Type, n)
+			}
+			if nil == err && nil != n {
+				err = c.schemaConstrain(&f, n)
+			}
+			if nil != err {
+				e.Violations = append(e.Violations, &PathError{path, err})
+			}
+		})
+	}
+	if c.opts.Strict {
+		e.Violations = append(e.Violations, s.undeclared(c)...)
+	}
+	for _, d := range s.deprecated {
+		if 0 != len(c.lookup(d.Path, false)) {
+			c.opts.deprecation(c, d)
+		}
+	}
+	if 0 == len(e.Violations) {
+		return nil
+	}
+	return e
+}
+
+/*
+	Adds the default of each left out label path that has one to the config,
+	 along with any groups needed to hold it; below a repeated group it's
+	 added to each of the groups left without it
+*/
+func (s *Schema) ApplyDefaults(c *Config) {
+	s.markSensitive(c)
+	for _, f := range s.fields {
+		if nil == f.Default {
+			continue
+		}
+		c.schemaNodes(&c.root, "", strings.Split(f.Path, ":"), func(_ string, n, parent *Node, missing []string) {
+			if nil != n || nil == parent {
+				return
+			}
+			for i, label := range missing {
+				t, data := ConfigGroup, []string(nil)
+				if i == len(missing)-1 {
+					t, data = f.Type.configType(), append([]string{}, f.Default...)
+					if ConfigValue == t && 0 == len(data) || ConfigBlock == t && 0 == len(data) {
+						data = []string{""}
+					}
+				}
+				parent = c.schemaAdd(parent, t, label, data)
+			}
+		})
+	}
+}
+
+// ------------------------------------------------------------------------- //
+
+// markSensitive adds the paths of the Sensitive fields the config doesn't
+// already treat as sensitive to its Sensitive patterns
+func (s *Schema) markSensitive(c *Config) {
+	for _, f := range s.fields {
+		if f.Sensitive && !c.sensitive(f.Path) {
+			c.MarkSensitive(f.Path)
+		}
+	}
+}
+
+// last is the field declared last, nil if there are none
+func (s *Schema) last() *SchemaField {
+	if 0 == len(s.fields) {
+		return nil
+	}
+	return &s.fields[len(s.fields)-1]
+}
+
+// configType is the type of node holding the schema type
+func (t SchemaType) configType() ConfigType {
+	switch t {
+	case SchemaBlock:
+		return ConfigBlock
+	case SchemaLines:
+		return ConfigLines
+	case SchemaItems:
+		return ConfigItems
+	case SchemaDict:
+		return ConfigDict
+	case SchemaGroup:
+		return ConfigGroup
+	}
+	return ConfigValue
+}
+
+// schemaCheck checks the node is of the schema type
+func (c *Config) schemaCheck(t SchemaType, n *Node) error {
+	if t.configType() != n.Type {
+		return ErrWrongType
+	}
+	if ConfigValue != n.Type {
+		return nil
+	}
+	v, err := c.deref(n)
+	if nil != err {
+		return err
+	}
+	switch t {
+	case SchemaValue, SchemaFloat:
+		if SchemaFloat == t {
+			_, err = strconv.ParseFloat(v, 64)
+		}
+	case SchemaBool:
+		_, err = ParseBool(v)
+	default:
+		_, err = schemaNumber(t, v, c.opts.DecimalOnly)
+	}
+	return err
+}
+
+// schemaConstrain checks the node, already of the field's type, meets the
+// field's constraints
+func (c *Config) schemaConstrain(f *SchemaField, n *Node) error {
+	size, items := len(n.Data), n.Data
+	switch n.Type {
+	case ConfigValue:
+		size = utf8.RuneCountInString(n.Data[0])
+		if nil != f.Min || nil != f.Max {
+			v, err := schemaNumber(f.Type, n.Data[0], c.opts.DecimalOnly)
+			if nil != err {
+				return err
+			}
+			if nil != f.Min && v < *f.Min || nil != f.Max && v > *f.Max {
+				return ErrOutOfRange
+			}
+		}
+	case ConfigDict:
+		size, items = len(n.Data)/2, nil
+	case ConfigBlock, ConfigGroup:
+		items = nil
+		if ConfigGroup == n.Type {
+			size = len(n.Children)
+		}
+	}
+	if f.MinLen > 0 && size < f.MinLen || f.MaxLen > 0 && size > f.MaxLen {
+		return ErrBadLength
+	}
+	if nil != f.Pattern {
+		for _, v := range items {
+			if !f.Pattern.MatchString(v) {
+				return ErrNoMatch
+			}
+		}
+	}
+	return nil
+}
+
+// schemaNumber reads a value of the schema type as a number, a float for
+// the types that aren't numeric
+func schemaNumber(t SchemaType, v string, decimalOnly bool) (float64, error) {
+	switch t {
+	case SchemaInt:
+		n, err := parseInt(v, decimalOnly)
+		return float64(n), err
+	case SchemaDuration:
+		d, err := time.ParseDuration(v)
+		return float64(d), err
+	case SchemaBytes:
+		n, err := ParseSize(v)
+		return float64(n), err
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+// schemaNodes calls f with each node of the remaining labels below the
+// group n, or for a group that holds none with a nil node, the group and
+// the labels it's missing; the group is nil when an entry that isn't a group
+// is in the way.  The path passed is indexed wherever a label is repeated
+func (c *Config) schemaNodes(n *Node, path string, elems []string, f func(path string, n, parent *Node, missing []string)) {
+	found := []*Node{}
+	for _, ch := range n.Children {
+		if elems[0] == ch.Label && ConfigComment != ch.Type {
+			found = append(found, ch)
+		}
+	}
+	p := strings.TrimPrefix(path+":"+elems[0], ":")
+	if 0 == len(found) {
+		f(strings.Join(append([]string{p}, elems[1:]...), ":"), nil, n, elems)
+		return
+	}
+	for i, ch := range found {
+		ip := p
+		if len(found) > 1 {
+			ip += "[" + strconv.Itoa(i) + "]"
+		}
+		switch {
+		case 1 == len(elems):
+			f(ip, ch, n, nil)
+		case ConfigGroup == ch.Type:
+			c.schemaNodes(ch, ip, elems[1:], f)
+		default:
+			f(strings.Join(append([]string{ip}, elems[1:]...), ":"), nil, nil, elems[1:])
+		}
+	}
+}
+
+// schemaAdd adds an entry to the group, which becomes the entry looked up
+// for its label path unless the group is an earlier one of a repeated label
+func (c *Config) schemaAdd(parent *Node, t ConfigType, label string, data []string) *Node {
+	n := &Node{Type: t, Label: label, Path: label, Data: data, parent: parent}
+	if &c.root != parent {
+		n.Path = parent.Path + ":" + label
+	}
+	parent.Children = append(parent.Children, n)
+	if &c.root == parent || c.nodes[parent.Path] == parent {
+		c.nodes[n.Path] = n
+	}
+	return n
+}
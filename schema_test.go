@@ -0,0 +1,119 @@
+package cfg
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestSchema(t *testing.T) {
+	c, err := Parse("name := app\nport := eighty\ntimeout := 5s\nhosts { a b }\n" +
+		"server (\n\thost := x\n\tport := 1\n)\nserver (\n\tport := 2\n)\nserver (\n\thost := z\n\tport := many\n)\n")
+	dbg.ChkErr(err, "Parse")
+	s := NewSchema().
+		Required("name", SchemaValue).
+		Required("port", SchemaInt).
+		Optional("timeout", SchemaDuration).
+		Required("hosts", SchemaLines).
+		Required("server:host", SchemaValue).
+		Required("server:port", SchemaInt).
+		Required("db:host", SchemaValue).
+		Default("debug", SchemaBool, "false").
+		Default("server:weight", SchemaInt, "1")
+
+	err = s.Validate(c)
+	var se *SchemaError
+	if !errors.As(err, &se) {
+		dbg.Error("Validate: %v", err)
+		t.FailNow()
+	}
+	want := []struct {
+		path string
+		err  error
+	}{
+		{"port", nil}, {"hosts", ErrWrongType}, {"server[1]:host", ErrNoSuchLabel},
+		{"server[2]:port", nil}, {"db:host", ErrNoSuchLabel},
+	}
+	if len(want) != len(se.Violations) {
+		dbg.Error("Validate: %v", err)
+		t.FailNow()
+	}
+	for i, w := range want {
+		v := se.Violations[i]
+		if w.path != v.Path || (nil != w.err && w.err != v.Err) || (nil == w.err && nil == v.Err) {
+			dbg.Error("Validate violation %d: %v", i, v)
+			t.Fail()
+		}
+	}
+
+	s.ApplyDefaults(c)
+	if b, err := c.GetBool("debug"); nil != err || b {
+		dbg.Error("ApplyDefaults debug: %v %v", b, err)
+		t.Fail()
+	}
+	nodes := c.LookupAll("server:weight")
+	if n, _ := c.GetInt("server:weight"); 3 != len(nodes) || 1 != n || "server:weight" != nodes[0].Path {
+		dbg.Error("ApplyDefaults server:weight: %d %d", len(nodes), n)
+		t.Fail()
+	}
+
+	c, _ = Parse("name := app\nport := 80\ntimeout := 5s\nhosts [\n\ta\n]\ndb (\n\thost := d\n)\n")
+	if err = NewSchema().Required("port", SchemaInt).Optional("timeout", SchemaDuration).Required("db:host", SchemaValue).Validate(c); nil != err {
+		dbg.Error("Validate valid: %v", err)
+		t.Fail()
+	}
+	NewSchema().Default("db:pool:size", SchemaInt, "4").ApplyDefaults(c)
+	if d, _ := c.GetDuration("timeout"); 5*time.Second != d || "4" != c.ValueOr("db:pool:size", "") {
+		dbg.Error("ApplyDefaults groups: %v", c.Flatten())
+		t.Fail()
+	}
+}
+
+func TestSchemaConstraints(t *testing.T) {
+	c, _ := Parse("port := 99999\nratio := 0.5\nname := Ab1\nwait := 90s\nhosts { a b c }\ntags [\n\tok\n\tBad\n]\n" +
+		"env : [\n\ta : 1\n]\nserver (\n\tport := 80\n)\nserver (\n\tport := -1\n)\n")
+	s := NewSchema().
+		Required("port", SchemaInt).Range(1, 65535).
+		Optional("ratio", SchemaFloat).Range(0, 1).
+		Optional("name", SchemaValue).Match(regexp.MustCompile(`^[a-z]+$`)).Len(1, 8).
+		Optional("wait", SchemaDuration).Range(0, float64(time.Minute)).
+		Required("hosts", SchemaItems).Len(1, 2).
+		Optional("tags", SchemaLines).Match(regexp.MustCompile(`^[a-z]+$`)).
+		Optional("env", SchemaDict).Len(2, 0).
+		Required("server:port", SchemaInt).Range(0, math.Inf(1))
+
+	err := s.Validate(c)
+	var se *SchemaError
+	if !errors.As(err, &se) {
+		dbg.Error("Validate constraints: %v", err)
+		t.FailNow()
+	}
+	want := []struct {
+		path string
+		err  error
+	}{
+		{"port", ErrOutOfRange}, {"name", ErrNoMatch}, {"wait", ErrOutOfRange},
+		{"hosts", ErrBadLength}, {"tags", ErrNoMatch}, {"env", ErrBadLength}, {"server[1]:port", ErrOutOfRange},
+	}
+	if len(want) != len(se.Violations) {
+		dbg.Error("Validate constraints: %v", err)
+		t.FailNow()
+	}
+	for i, w := range want {
+		if v := se.Violations[i]; w.path != v.Path || w.err != v.Err {
+			dbg.Error("Validate constraint %d: %v", i, v)
+			t.Fail()
+		}
+	}
+
+	c, _ = Parse("port := x\n")
+	err = NewSchema().Required("port", SchemaValue).Range(1, 2).Validate(c)
+	if !errors.As(err, &se) || 1 != len(se.Violations) || errors.Is(err, ErrOutOfRange) {
+		dbg.Error("Validate non-numeric range: %v", err)
+		t.Fail()
+	}
+}
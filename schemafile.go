@@ -0,0 +1,150 @@
+package cfg
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jayacarlson/dbg"
+)
+
+/*
+	Reads a Schema written in the cfg format, each value declaring its label
+	 path as 'type[,required][,sensitive][,constraint...][,default=value]', e.g.
+
+		name := string,required
+		db (
+			host := string,required
+			port := int,default=5432
+			password := string,required,sensitive
+			replicas := items,default=a b
+		)
+
+	The types are the names given by SchemaType.String, with value another
+	 name for string.  A default runs to the end of the entry so it may hold
+	 commas, the default of items is split on whitespace; dict and group
+	 entries can't have a default.  The constraints of Schema.Range, Len and
+	 Match are given as min=N, max=N, minlen=N, maxlen=N and match=regexp,
+	 e.g. 'port := int,min=1,max=65535'; the bounds of a duration or bytes
+	 entry are written as its values are (e.g. max=1m) and a match, like a
+	 default, runs to the end of the entry.  sensitive marks an entry
+	 holding a secret, see Schema.Sensitive
+*/
+func LoadSchema(flPath string) (*Schema, error) {
+	c, err := LoadConfig(flPath)
+	if nil != err {
+		return nil, err
+	}
+	return schemaOf(c)
+}
+
+/*
+	Parses a Schema written in the cfg format, see LoadSchema
+*/
+func ParseSchema(str string) (*Schema, error) {
+	c, err := Parse(str)
+	if nil != err {
+		return nil, err
+	}
+	return schemaOf(c)
+}
+
+// ------------------------------------------------------------------------- //
+
+// schemaOf declares a field for each value of the config
+func schemaOf(c *Config) (*Schema, error) {
+	s := NewSchema()
+	var err error
+	c.flatten(&c.root, func(n *Node) {
+		if nil != err || ConfigComment == n.Type {
+			return
+		}
+		if ConfigValue != n.Type {
+			err = &PathError{n.Path, ErrBadSchema}
+			return
+		}
+		var f SchemaField
+		f, err = schemaField(n.Path, n.Data[0])
+		if nil == err {
+			s.fields = append(s.fields, f)
+		}
+	})
+	if dbg.ChkErr(err, "Failed to read config schema (%v)", err) {
+		return nil, err
+	}
+	return s, nil
+}
+
+// schemaField reads a 'type[,required][,sensitive][,constraint...][,default=value]'
+// declaration
+func schemaField(path, spec string) (SchemaField, error) {
+	f := SchemaField{Path: path}
+	name, opts := spec, ""
+	if i := strings.Index(spec, ","); i >= 0 {
+		name, opts = spec[:i], spec[i+1:]
+	}
+	t, ok := schemaTypes[strings.TrimSpace(name)]
+	if !ok {
+		return f, &PathError{path, ErrBadSchema}
+	}
+	f.Type = t
+	for "" != opts {
+		opt := strings.TrimLeft(opts, " \t")
+		if strings.HasPrefix(opt, "default=") {
+			def := strings.TrimSpace(opt[len("default="):])
+			switch t {
+			case SchemaDict, SchemaGroup:
+				return f, &PathError{path, ErrBadSchema}
+			case SchemaItems:
+				f.Default = strings.Fields(def)
+			default:
+				f.Default = []string{def}
+			}
+			break
+		}
+		if strings.HasPrefix(opt, "match=") {
+			re, err := regexp.Compile(strings.TrimSpace(opt[len("match="):]))
+			if nil != err {
+				return f, &PathError{path, ErrBadSchema}
+			}
+			f.Pattern = re
+			break
+		}
+		opt, opts = opts, ""
+		if i := strings.Index(opt, ","); i >= 0 {
+			opt, opts = opt[:i], opt[i+1:]
+		}
+		name, arg := strings.TrimSpace(opt), ""
+		if i := strings.Index(name, "="); i >= 0 {
+			name, arg = strings.TrimSpace(name[:i]), strings.TrimSpace(name[i+1:])
+		}
+		var err error
+		switch name {
+		case "required":
+			if f.Required = true; "" != arg {
+				err = ErrBadSchema
+			}
+		case "sensitive":
+			if f.Sensitive = true; "" != arg {
+				err = ErrBadSchema
+			}
+		case "min", "max":
+			var v float64
+			if v, err = schemaNumber(t, arg, false); "min" == name {
+				f.Min = &v
+			} else {
+				f.Max = &v
+			}
+		case "minlen":
+			f.MinLen, err = strconv.Atoi(arg)
+		case "maxlen":
+			f.MaxLen, err = strconv.Atoi(arg)
+		default:
+			err = ErrBadSchema
+		}
+		if nil != err {
+			return f, &PathError{path, ErrBadSchema}
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,65 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestLoadSchema(t *testing.T) {
+	s, err := LoadSchema("testdata/app.schema.cfg")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	want := []SchemaField{
+		{Path: "name", Type: SchemaValue, Required: true},
+		{Path: "debug", Type: SchemaBool, Default: []string{"false"}},
+		{Path: "db:host", Type: SchemaValue, Required: true},
+		{Path: "db:port", Type: SchemaInt, Default: []string{"5432"}},
+		{Path: "db:dsn", Type: SchemaValue, Default: []string{"user=app, sslmode=disable"}},
+		{Path: "tags", Type: SchemaItems, Default: []string{"a", "b"}},
+		{Path: "server:weight", Type: SchemaInt},
+		{Path: "server:port", Type: SchemaInt, Required: true},
+	}
+	if got := s.Fields(); !reflect.DeepEqual(want, got) {
+		dbg.Error("LoadSchema: %v", got)
+		t.Fail()
+	}
+
+	c, _ := Parse("name := app\ndb (\n\thost := x\n)\nserver (\n\tport := 80\n)\n")
+	s.ApplyDefaults(c)
+	if err = s.Validate(c); nil != err || "5432" != c.ValueOr("db:port", "") {
+		dbg.Error("LoadSchema Validate: %v", err)
+		t.Fail()
+	}
+
+	for _, bad := range []string{"a := text\n", "a := int,optional\n", "a := dict,default=x\n",
+		"a := int,min=x\n", "a := value,match=(\n", "a := items,maxlen\n", "a [\n\tint\n]\n"} {
+		if _, err = ParseSchema(bad); !errors.Is(err, ErrBadSchema) {
+			dbg.Error("ParseSchema %q: %v", bad, err)
+			t.Fail()
+		}
+	}
+	s, err = ParseSchema("port := int,required,min=1,max=65535\nwait := duration,max=1m\n" +
+		"hosts := items,minlen=1,maxlen=2,match=^[a-z]+$\n")
+	f := s.Fields()
+	if nil != err || 3 != len(f) || !f[0].Required || 65535 != *f[0].Max || 60e9 != *f[1].Max ||
+		2 != f[2].MaxLen || !f[2].Pattern.MatchString("ab") {
+		dbg.Error("ParseSchema constraints: %v %v", f, err)
+		t.Fail()
+	}
+	c, _ = Parse("port := 0\nwait := 2m\nhosts { a B c }\n")
+	var se *SchemaError
+	if err = s.Validate(c); !errors.As(err, &se) || 3 != len(se.Violations) {
+		dbg.Error("ParseSchema constraints Validate: %v", err)
+		t.Fail()
+	}
+
+	if "duration" != SchemaDuration.String() || "string" != SchemaValue.String() {
+		dbg.Error("SchemaType String: %s", SchemaDuration)
+		t.Fail()
+	}
+}
@@ -0,0 +1,84 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	/*
+		A NotFoundError lists the paths searched for a config file when
+		 none of them exist
+	*/
+	NotFoundError struct {
+		App      string
+		Searched []string
+	}
+)
+
+func (e *NotFoundError) Error() string {
+	return "No config file found for " + e.App + ", searched: " + strings.Join(e.Searched, ", ")
+}
+
+/*
+	Loads the first config file found for the app, returning the path used;
+	 for the app name "app" the paths searched, in order, are:
+
+		$XDG_CONFIG_HOME/app/app.cfg    ~/.config/app/app.cfg if not set
+		~/.app.cfg
+		/etc/app/app.cfg
+		./app.cfg                       in the working directory
+
+	A *NotFoundError is returned if none of them exist
+*/
+func FindAndLoad(appName string) (*Config, string, error) {
+	return Options{}.FindAndLoad(appName)
+}
+
+/*
+	Returns the first existing config file for the app, see FindAndLoad
+*/
+func FindConfig(appName string) (string, error) {
+	paths := searchPaths(appName)
+	for _, p := range paths {
+		if fi, err := os.Stat(p); nil == err && fi.Mode().IsRegular() {
+			return p, nil
+		}
+	}
+	return "", &NotFoundError{appName, paths}
+}
+
+/*
+	As FindAndLoad, using these options
+*/
+func (o Options) FindAndLoad(appName string) (*Config, string, error) {
+	flPath, err := FindConfig(appName)
+	if nil != err {
+		return nil, "", err
+	}
+	c, err := o.LoadConfig(flPath)
+	if nil != err {
+		return nil, flPath, err
+	}
+	return c, flPath, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// searchPaths returns the config file paths for the app in the order they
+// are searched, leaving out those in an unknown home directory
+func searchPaths(appName string) []string {
+	file := appName + ".cfg"
+	home, _ := os.UserHomeDir()
+	paths := []string{}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); "" != xdg {
+		paths = append(paths, filepath.Join(xdg, appName, file))
+	} else if "" != home {
+		paths = append(paths, filepath.Join(home, ".config", appName, file))
+	}
+	if "" != home {
+		paths = append(paths, filepath.Join(home, "."+file))
+	}
+	return append(paths, filepath.Join("/etc", appName, file), file)
+}
@@ -0,0 +1,50 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestFindAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	os.Setenv("HOME", filepath.Join(dir, "home"))
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+	app := "cfgsearchtest"
+
+	var nf *NotFoundError
+	if _, _, err := FindAndLoad(app); !errors.As(err, &nf) || 4 != len(nf.Searched) {
+		dbg.Error("FindAndLoad with no file: %v", err)
+		t.Fail()
+	}
+
+	home := filepath.Join(dir, "home", "."+app+".cfg")
+	os.MkdirAll(filepath.Dir(home), 0755)
+	ioutil.WriteFile(home, []byte("from := home\n"), 0644)
+	if c, p, err := FindAndLoad(app); nil != err || home != p || "home" != c.ValueOr("from", "") {
+		dbg.Error("FindAndLoad home: %s %v", p, err)
+		t.Fail()
+	}
+
+	xdg := filepath.Join(dir, "xdg", app, app+".cfg")
+	os.MkdirAll(filepath.Dir(xdg), 0755)
+	ioutil.WriteFile(xdg, []byte("from := xdg\n"), 0644)
+	if c, p, err := FindAndLoad(app); nil != err || xdg != p || "xdg" != c.ValueOr("from", "") || xdg != c.Source() {
+		dbg.Error("FindAndLoad xdg: %s %v", p, err)
+		t.Fail()
+	}
+
+	os.Unsetenv("XDG_CONFIG_HOME")
+	if p, err := FindConfig(app); nil != err || home != p {
+		dbg.Error("FindConfig without XDG_CONFIG_HOME: %s %v", p, err)
+		t.Fail()
+	}
+}
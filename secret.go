@@ -0,0 +1,166 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type (
+	/*
+		A SecretResolver returns the secret a reference names, the ref being
+		 the text after the scheme of '@secret(scheme:ref)'
+	*/
+	SecretResolver interface {
+		ResolveSecret(ref string) (string, error)
+	}
+
+	/*
+		A SecretFunc is a function used as a SecretResolver
+	*/
+	SecretFunc func(ref string) (string, error)
+
+	/*
+		A Secret is a string that's shown as Redacted when formatted or
+		 marshaled as text (e.g. to JSON), so logging a struct holding one
+		 can't leak it; Reveal gives the value itself, as does cfg.Marshal
+	*/
+	Secret string
+
+	/*
+		A SecretError records an @secret(scheme:ref) value that could not be
+		 resolved
+	*/
+	SecretError struct {
+		Ref string
+		Err error
+	}
+)
+
+var (
+	ErrUnknownSecretScheme = errors.New("No secret resolver registered for the scheme")
+	ErrNoSuchSecret        = errors.New("No such secret")
+
+	/*
+		Runs the command of the ref, split at whitespace without any shell,
+		 and gives its output less the trailing newline, e.g.
+		 @secret(exec:pass show db); it isn't registered by default, as a
+		 config file could then run anything:
+
+			cfg.RegisterSecretResolver("exec", cfg.ExecSecrets)
+	*/
+	ExecSecrets = SecretFunc(func(ref string) (string, error) {
+		args := strings.Fields(ref)
+		if 0 == len(args) {
+			return "", ErrNoSuchSecret
+		}
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		if nil != err {
+			return "", err
+		}
+		return strings.TrimRight(string(out), "\r\n"), nil
+	})
+
+	secretLock      sync.RWMutex
+	secretResolvers = map[string]SecretResolver{
+		"env": SecretFunc(func(ref string) (string, error) {
+			if v, ok := os.LookupEnv(ref); ok {
+				return v, nil
+			}
+			return "", ErrNoSuchSecret
+		}),
+		"file": SecretFunc(func(ref string) (string, error) {
+			data, err := ioutil.ReadFile(ref)
+			if nil != err {
+				return "", err
+			}
+			return strings.TrimRight(string(data), "\r\n"), nil
+		}),
+	}
+
+	// @secret(scheme:ref)
+	// 1: scheme  2: ref
+	secretRex = regexp.MustCompile(`^@secret\((\w+):(.*)\)$`)
+)
+
+func (f SecretFunc) ResolveSecret(ref string) (string, error) {
+	return f(ref)
+}
+
+/*
+	Returns the value of the secret
+*/
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	return Redacted
+}
+
+func (s Secret) GoString() string {
+	return Redacted
+}
+
+// Format gives Redacted for every verb, so neither %x nor %q shows the value
+func (s Secret) Format(f fmt.State, _ rune) {
+	io.WriteString(f, Redacted)
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+func (e *SecretError) Error() string {
+	return "@secret(" + e.Ref + "): " + e.Err.Error()
+}
+
+func (e *SecretError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	Registers the resolver of '@secret(scheme:ref)' values for the scheme,
+	 so secrets are kept out of config files and looked up each time the
+	 value is read, when parsed with Options.Secrets; env (an environment
+	 variable) and file (the contents of a file, less any trailing newline,
+	 e.g. @secret(file:/run/secrets/db)) are built in.  A later registration for the scheme replaces the earlier
+	 one and registering nil removes it
+*/
+func RegisterSecretResolver(scheme string, r SecretResolver) {
+	secretLock.Lock()
+	defer secretLock.Unlock()
+	if nil == r {
+		delete(secretResolvers, scheme)
+	} else {
+		secretResolvers[scheme] = r
+	}
+}
+
+// ------------------------------------------------------------------------- //
+
+// secret resolves the value if it's an @secret(scheme:ref), returning any
+// other value as it is
+func secret(v string) (string, error) {
+	x := secretRex.FindStringSubmatch(v)
+	if nil == x {
+		return v, nil
+	}
+	secretLock.RLock()
+	r, ok := secretResolvers[x[1]]
+	secretLock.RUnlock()
+	if !ok {
+		return "", &SecretError{x[1] + ":" + x[2], ErrUnknownSecretScheme}
+	}
+	s, err := r.ResolveSecret(x[2])
+	if nil != err {
+		return "", &SecretError{x[1] + ":" + x[2], err}
+	}
+	return s, nil
+}
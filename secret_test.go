@@ -0,0 +1,135 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "db_pass")
+	ioutil.WriteFile(flPath, []byte("s3cret\n"), 0600)
+	os.Setenv("SECRET_TEST_TOKEN", "tok")
+	defer os.Unsetenv("SECRET_TEST_TOKEN")
+	RegisterSecretResolver("vault", SecretFunc(func(ref string) (string, error) {
+		if "db/pass" == ref {
+			return "v4ult", nil
+		}
+		return "", ErrNoSuchSecret
+	}))
+	defer RegisterSecretResolver("vault", nil)
+
+	src := "db (\n\tpassword := @secret(file:" + flPath + ")\n\ttoken := @secret(env:SECRET_TEST_TOKEN)\n" +
+		"\tvault := @secret(vault:db/pass)\n\tcopy := @ref(db:token)\n)\nmissing := @secret(env:SECRET_TEST_NONE)\n" +
+		"unknown := @secret(nope:x)\n"
+	// without Options.Secrets the values are as written
+	c, err := Parse(src)
+	if v, err := c.value("db:token"); nil != err || "@secret(env:SECRET_TEST_TOKEN)" != v {
+		dbg.Error("secret resolved without Options.Secrets: %q %v", v, err)
+		t.Fail()
+	}
+	c, err = Options{Secrets: true}.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	for p, v := range map[string]string{"db:password": "s3cret", "db:token": "tok", "db:vault": "v4ult", "db:copy": "tok"} {
+		if got, err := c.value(p); nil != err || v != got {
+			dbg.Error("%s is %q: %v", p, got, err)
+			t.Fail()
+		}
+	}
+	if "@secret(env:SECRET_TEST_TOKEN)" != c.Lookup("db:token").Data[0] {
+		dbg.Error("secret stored in the tree: %v", c.Lookup("db:token").Data)
+		t.Fail()
+	}
+	var se *SecretError
+	if _, err = c.value("missing"); !errors.As(err, &se) || "env:SECRET_TEST_NONE" != se.Ref || !errors.Is(err, ErrNoSuchSecret) {
+		dbg.Error("missing secret: %v", err)
+		t.Fail()
+	}
+	if _, err = c.value("unknown"); !errors.Is(err, ErrUnknownSecretScheme) {
+		dbg.Error("unknown secret scheme: %v", err)
+		t.Fail()
+	}
+	if v, err := ExecSecrets.ResolveSecret("echo hi"); nil != err || "hi" != v {
+		dbg.Error("ExecSecrets: %q %v", v, err)
+		t.Fail()
+	}
+}
+
+func TestSecretType(t *testing.T) {
+	s := Secret("hunter2")
+	var v struct {
+		User     string
+		Password Secret
+	}
+	v.User, v.Password = "app", s
+	for _, got := range []string{fmt.Sprint(s), fmt.Sprintf("%v %+v %#v %q %x", s, v, v, s, s), s.String()} {
+		if strings.Contains(got, "hunter2") || !strings.Contains(got, Redacted) {
+			dbg.Error("Secret formatted as %s", got)
+			t.Fail()
+		}
+	}
+	if data, _ := json.Marshal(v); strings.Contains(string(data), "hunter2") {
+		dbg.Error("Secret marshaled as %s", data)
+		t.Fail()
+	}
+	if "hunter2" != s.Reveal() {
+		dbg.Error("Reveal: %s", s.Reveal())
+		t.Fail()
+	}
+
+	c, _ := Parse("db (\n\tuser := app\n\tpassword := hunter2\n)\n")
+	schema, err := ParseSchema("db (\n\tuser := string\n\tpassword := string,sensitive\n)\n")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if err = schema.Validate(c); nil != err {
+		dbg.Error(err.Error())
+		t.Fail()
+	}
+	flat := c.FlattenTyped()
+	if "hunter2" != flat["db:password"] || "app" != flat["db:user"] {
+		dbg.Error("FlattenTyped of a sensitive entry: %#v", flat["db:password"])
+		t.Fail()
+	}
+	if strings.Contains(c.String(), "hunter2") {
+		dbg.Error("sensitive schema entry not redacted: %s", c.String())
+		t.Fail()
+	}
+	if pw, err := c.GetSecret("db:password"); nil != err || "hunter2" != pw.Reveal() {
+		dbg.Error("GetSecret: %v", err)
+		t.Fail()
+	}
+	data, err := Marshal(v)
+	var back struct {
+		User     string
+		Password Secret
+	}
+	if nil == err {
+		var mc *Config
+		if mc, err = Parse(string(data)); nil == err {
+			err = mc.Unmarshal(&back)
+		}
+	}
+	if nil != err || v != back {
+		dbg.Error("Marshal round trip of a Secret: %v\n%s", err, data)
+		t.Fail()
+	}
+	if pw, err := Get[Secret](c, "db:password"); nil != err || "hunter2" != pw.Reveal() {
+		dbg.Error("Get of a Secret: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,96 @@
+package cfg
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type (
+	/*
+		A SectionFunc converts the text of a custom section, everything
+		 between the opening and closing delimiters, into the data passed to
+		 HandleConfigData handlers and held by the Config tree node
+	*/
+	SectionFunc func(label, text string) ([]string, error)
+
+	section struct {
+		open, close string
+		t           ConfigType
+		fn          SectionFunc
+		rex         *regexp.Regexp // label line ending with the opener
+	}
+)
+
+const (
+	// ConfigType values from ConfigCustom up are free for custom sections
+	ConfigCustom ConfigType = 64
+)
+
+var (
+	ErrSectionDelimiter = errors.New("Custom section delimiter clashes with the config syntax")
+	ErrSectionType      = errors.New("Custom section type must be ConfigCustom or above")
+
+	sectionLock sync.RWMutex
+	sections    []*section
+)
+
+/*
+	Registers a custom section kind, a label followed by the open delimiter
+	 ending the line, up to a line consisting of just the close delimiter;
+	 the text of the section is converted by fn and delivered as type t, e.g.
+
+		cfg.RegisterSection("<json", "json>", ConfigJSON, checkJSON)
+
+		schema <json
+		{ "type": "object" }
+		json>
+
+	Delimiters may be any number of characters but must not be one of the
+	 built in openers (<, [, {, (, <b64, <hex and <<TAG) or contain any
+	 whitespace.  Registering a nil fn removes the section kind
+*/
+func RegisterSection(open, close string, t ConfigType, fn SectionFunc) error {
+	sectionLock.Lock()
+	defer sectionLock.Unlock()
+	for i, s := range sections {
+		if s.open == open {
+			sections = append(sections[:i], sections[i+1:]...)
+			break
+		}
+	}
+	if nil == fn {
+		return nil
+	}
+	if t < ConfigCustom {
+		return ErrSectionType
+	}
+	opener := "x " + open + "\n"
+	if "" == open || "" == close || strings.ContainsAny(open+close, " \t\r\n") ||
+		findConfigStRex.MatchString(opener) || findConfigHeredocRex.MatchString(opener) ||
+		findConfigValueRex.MatchString(opener) || strings.HasPrefix(open, "<<") {
+		return ErrSectionDelimiter
+	}
+	rex := regexp.MustCompile(`(?m)^(\S+?)[ \t]*` + regexp.QuoteMeta(open) + `[ \t]*\n`)
+	sections = append(sections, &section{open, close, t, fn, rex})
+	return nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// findSection returns the first custom section in str with a valid label,
+// the index of its label line (as FindStringSubmatchIndex) and the section
+func (s *syntax) findSection(str string) ([]int, *section) {
+	sectionLock.RLock()
+	defer sectionLock.RUnlock()
+	var first []int
+	var sec *section
+	for _, cs := range sections {
+		x := cs.rex.FindStringSubmatchIndex(str)
+		if nil != x && s.label.MatchString(str[x[2]:x[3]]) && (nil == first || x[0] < first[0]) {
+			first, sec = x, cs
+		}
+	}
+	return first, sec
+}
@@ -0,0 +1,74 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	testJSON = ConfigCustom + iota
+)
+
+func TestRegisterSection(t *testing.T) {
+	err := RegisterSection("<json", "json>", testJSON, func(label, text string) ([]string, error) {
+		if !json.Valid([]byte(text)) {
+			return nil, errors.New("invalid JSON")
+		}
+		return []string{text}, nil
+	})
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	defer RegisterSection("<json", "json>", testJSON, nil)
+
+	src := `
+name := app
+schema <json
+{ "type": "object",
+  "note": ")"
+}
+json>
+grp (
+	inner <json
+	[1, 2]
+	json>
+)
+after := yes
+`
+	got := map[string]string{}
+	types := map[string]ConfigType{}
+	err = HandleConfigData(src, func(ct ConfigType, label string, data []string) {
+		got[label], types[label] = data[0], ct
+	})
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if testJSON != types["schema"] || "{ \"type\": \"object\",\n  \"note\": \")\"\n}" != got["schema"] {
+		dbg.Error("schema: %d %q", types["schema"], got["schema"])
+		t.Fail()
+	}
+	if testJSON != types["grp:inner"] || "[1, 2]" != got["grp:inner"] || "yes" != got["after"] || "app" != got["name"] {
+		dbg.Error("entries: %v", got)
+		t.Fail()
+	}
+	if err = HandleConfigData("bad <json\n{\njson>\n", func(ConfigType, string, []string) {}); nil == err {
+		dbg.Error("expected a section error")
+		t.Fail()
+	}
+
+	for _, open := range []string{"<", "(", "<b64", "<<EOF", ":=", "a b"} {
+		if err = RegisterSection(open, ">", testJSON, func(string, string) ([]string, error) { return nil, nil }); ErrSectionDelimiter != err {
+			dbg.Error("%q: %v", open, err)
+			t.Fail()
+		}
+	}
+	if err = RegisterSection("<x", "x>", ConfigBlock, func(string, string) ([]string, error) { return nil, nil }); ErrSectionType != err {
+		dbg.Error("type: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,68 @@
+package cfg
+
+import (
+	"context"
+)
+
+type (
+	/*
+		A Source supplies config data from outside the file system, e.g. a
+		 key of etcd or Consul (see the etcd & consul sub-packages).  Fetch
+		 returns the current data; Watch sends the data each time it changes
+		 until the context is done, then closes the channel
+	*/
+	Source interface {
+		Fetch(ctx context.Context) ([]byte, error)
+		Watch(ctx context.Context) <-chan []byte
+	}
+)
+
+/*
+	Fetches the config data of the source and parses it into a Config tree
+	 as Parse does
+*/
+func LoadSource(ctx context.Context, s Source) (*Config, error) {
+	return Options{}.LoadSource(ctx, s)
+}
+
+/*
+	Parses the config data of the source each time it changes, passing the
+	 new Config (or the parse error) to f, until the context is done; the
+	 data is decoded as LoadConfig does, e.g. gzip data is decompressed
+*/
+func WatchSource(ctx context.Context, s Source, f func(c *Config, err error)) {
+	Options{}.WatchSource(ctx, s, f)
+}
+
+/*
+	As LoadSource, using these options
+*/
+func (o Options) LoadSource(ctx context.Context, s Source) (*Config, error) {
+	data, err := s.Fetch(ctx)
+	if nil != err {
+		return nil, err
+	}
+	return o.parseSource(data)
+}
+
+/*
+	As WatchSource, using these options
+*/
+func (o Options) WatchSource(ctx context.Context, s Source, f func(c *Config, err error)) {
+	for data := range s.Watch(ctx) {
+		f(o.parseSource(data))
+	}
+}
+
+// ------------------------------------------------------------------------- //
+
+func (o Options) parseSource(data []byte) (*Config, error) {
+	if o.MaxSize > 0 && int64(len(data)) > o.MaxSize {
+		return nil, &TooLargeError{"", o.MaxSize}
+	}
+	str, err := decodeText("", data, o.MaxSize)
+	if nil != err {
+		return nil, err
+	}
+	return o.Parse(str)
+}
@@ -0,0 +1,45 @@
+package cfg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+type testSource []string
+
+func (s testSource) Fetch(ctx context.Context) ([]byte, error) {
+	return []byte(s[0]), nil
+}
+
+func (s testSource) Watch(ctx context.Context) <-chan []byte {
+	ch := make(chan []byte, len(s))
+	for _, data := range s {
+		ch <- []byte(data)
+	}
+	close(ch)
+	return ch
+}
+
+func TestSource(t *testing.T) {
+	src := testSource{"\ufeffport := 8080\n", "port := 9090\n", "bad ( oops )\n"}
+	c, err := Options{DecimalOnly: true}.LoadSource(context.Background(), src)
+	if port, _ := c.GetInt("port"); nil != err || 8080 != port || !c.opts.DecimalOnly {
+		dbg.Error("LoadSource: %d %v", port, err)
+		t.Fail()
+	}
+	ports, errs := []int64{}, 0
+	WatchSource(context.Background(), src, func(c *Config, err error) {
+		if nil != err {
+			errs++
+			return
+		}
+		port, _ := c.GetInt("port")
+		ports = append(ports, port)
+	})
+	if 2 != len(ports) || 9090 != ports[1] || 1 != errs {
+		dbg.Error("WatchSource: %v %d", ports, errs)
+		t.Fail()
+	}
+}
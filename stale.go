@@ -0,0 +1,78 @@
+package cfg
+
+import (
+	"crypto/sha256"
+	"time"
+)
+
+type (
+	/*
+		SourceInfo describes the data a Config was parsed from, as noted
+		 when it was loaded, see Config.SourceInfo
+	*/
+	SourceInfo struct {
+		Path    string    // the file loaded, "" for data parsed from a string
+		ModTime time.Time // the file's modification time, zero for a string
+		Size    int64     // the bytes of the file, or of the string parsed
+		Hash    []byte    // the SHA-256 hash of the text read (or parsed)
+		Loaded  time.Time // when the data was parsed
+	}
+)
+
+/*
+	Returns what's known of the data the config was parsed from: the file
+	 loaded along with its modification time, size & hash as it was read,
+	 or for config data parsed from a string its size & hash
+*/
+func (c *Config) SourceInfo() SourceInfo {
+	info := SourceInfo{Path: c.source, Loaded: c.loaded}
+	if 0 != len(c.files) {
+		f := c.files[0]
+		info.ModTime, info.Size, info.Hash = f.mod, f.size, f.sum
+		return info
+	}
+	sum := sha256.Sum256([]byte(c.text))
+	info.Size, info.Hash = int64(len(c.text)), sum[:]
+	return info
+}
+
+/*
+	Reports whether the file the config was loaded from, or any file it
+	 included, has changed (or been removed) since it was read, so a long
+	 running tool can offer to reload it.  A file whose modification time
+	 changed is only stale if its contents did too.  Always false for a
+	 config parsed from a string or read from stdin; an error is that of
+	 failing to check a file
+*/
+func (c *Config) Stale() (bool, error) {
+	for _, f := range c.files {
+		if changed, err := f.changed(); nil != err || changed {
+			return changed, err
+		}
+	}
+	return false, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// loading returns the options noting the file to be loaded, and any files
+// it includes, as they're read, and the function recording them on the
+// config once it's loaded; a file changing as it's read is then seen by
+// Stale.  Only a main file that's a regular file is noted
+func (o Options) loading(flPath string) (Options, func(c *Config)) {
+	since, files, seen := time.Now(), []cachedFile{}, o.seen
+	o.seen = func(path, text string) {
+		if nil != seen {
+			seen(path, text)
+		}
+		if f, found := snapshot(path, text, since, o.MaxSize); found {
+			files = append(files, f)
+		}
+	}
+	return o, func(c *Config) {
+		c.source, c.opts.seen = flPath, seen
+		if 0 != len(files) && flPath == files[0].path {
+			c.files = files
+		}
+	}
+}
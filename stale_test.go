@@ -0,0 +1,72 @@
+package cfg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestStale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath, incPath := filepath.Join(dir, "app.cfg"), filepath.Join(dir, "db.cfg")
+	data := []byte("name := app\n@include db.cfg\n")
+	dbg.ChkErr(ioutil.WriteFile(flPath, data, 0644), "WriteFile")
+	dbg.ChkErr(ioutil.WriteFile(incPath, []byte("port := 80\n"), 0644), "WriteFile")
+
+	before := time.Now()
+	c, err := LoadConfig(flPath)
+	if nil != err {
+		dbg.Error("LoadConfig: %v", err)
+		t.FailNow()
+	}
+	fi, _ := os.Stat(flPath)
+	sum := sha256.Sum256(data)
+	info := c.SourceInfo()
+	if flPath != info.Path || int64(len(data)) != info.Size || !fi.ModTime().Equal(info.ModTime) ||
+		!bytes.Equal(sum[:], info.Hash) || info.Loaded.Before(before) {
+		dbg.Error("SourceInfo: %+v", info)
+		t.Fail()
+	}
+	if stale, err := c.Stale(); stale || nil != err {
+		dbg.Error("Stale of an unchanged config: %v %v", stale, err)
+		t.Fail()
+	}
+	// touched, but the same contents
+	later := fi.ModTime().Add(time.Minute)
+	os.Chtimes(flPath, later, later)
+	if stale, err := c.Clone().Stale(); stale || nil != err {
+		dbg.Error("Stale of a touched config: %v %v", stale, err)
+		t.Fail()
+	}
+	dbg.ChkErr(ioutil.WriteFile(incPath, []byte("port := 81\n"), 0644), "WriteFile")
+	if stale, err := c.Stale(); !stale || nil != err {
+		dbg.Error("Stale of a changed include: %v %v", stale, err)
+		t.Fail()
+	}
+
+	c, _ = LoadConfig(flPath)
+	os.Remove(flPath)
+	if stale, err := c.Stale(); !stale || nil != err {
+		dbg.Error("Stale of a removed config: %v %v", stale, err)
+		t.Fail()
+	}
+
+	c, err = Parse("name := app\n")
+	sum = sha256.Sum256([]byte("name := app\n"))
+	if info := c.SourceInfo(); nil != err || "" != info.Path || 12 != info.Size || !bytes.Equal(sum[:], info.Hash) || info.Loaded.IsZero() {
+		dbg.Error("SourceInfo of parsed data: %+v", info)
+		t.Fail()
+	}
+	if stale, err := c.Stale(); stale || nil != err {
+		dbg.Error("Stale of parsed data: %v %v", stale, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,40 @@
+//go:build unix
+
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestLoadFIFO(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	fifo := filepath.Join(dir, "app.cfg")
+	if err = syscall.Mkfifo(fifo, 0600); nil != err {
+		t.Skip("mkfifo:", err)
+	}
+	go func() {
+		fl, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+		if nil == err {
+			fl.WriteString("name := app\n")
+			fl.Close()
+		}
+	}()
+	// the data is read once, by the parse
+	c, err := LoadConfig(fifo)
+	if nil != err || "app" != c.ValueOr("name", "") {
+		dbg.Error("LoadConfig of a FIFO: %v", err)
+		t.FailNow()
+	}
+	if stale, err := c.Stale(); stale || nil != err {
+		dbg.Error("Stale of a FIFO: %v %v", stale, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,56 @@
+package cfg
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+/*
+	The path the Load* functions read as stdin rather than a file, so config
+	 data can be piped in, e.g. 'generate-config | mytool -c -'; relative
+	 includes are then relative to the working directory
+*/
+const Stdin = "-"
+
+// where Stdin is read from, replaced by tests
+var stdin io.Reader = os.Stdin
+
+/*
+	Reads the config data from r and parses it into a Config tree as Parse
+	 does, decompressing and decoding it as the Load* functions do a file
+*/
+func ParseReader(r io.Reader) (*Config, error) {
+	return Options{}.ParseReader(r)
+}
+
+/*
+	As ParseReader, using these options
+*/
+func (o Options) ParseReader(r io.Reader) (*Config, error) {
+	data, err := readAll(r, "", o.MaxSize)
+	if nil != err {
+		return nil, err
+	}
+	str, err := decodeText("", data, o.MaxSize)
+	if nil != err {
+		return nil, err
+	}
+	return o.Parse(str)
+}
+
+/*
+	Reads the config data from r and passes it to the handler as
+	 HandleConfigData does
+*/
+func HandleConfigReader(r io.Reader, f func(t ConfigType, label string, data []string)) error {
+	data, err := ioutil.ReadAll(r)
+	if nil != err {
+		return err
+	}
+	str, err := decodeText("", data, 0)
+	if nil != err {
+		return err
+	}
+	return HandleConfigData(str, f)
+}
@@ -0,0 +1,54 @@
+package cfg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestStdin(t *testing.T) {
+	defer func() { stdin = os.Stdin }()
+	stdin = strings.NewReader("name := piped\n@include testdata/include/db.cfg\n")
+	c, err := LoadConfig(Stdin)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if v, _ := c.Value("name"); "piped" != v || Stdin != c.Source() || "5432" != c.Flatten()["port"] {
+		dbg.Error("LoadConfig stdin: %q %q", v, c.Source())
+		t.Fail()
+	}
+
+	stdin = strings.NewReader("a := 1\nb := 2\n")
+	labels := []string{}
+	err = LoadConfigValues(Stdin, func(label, value string) {
+		labels = append(labels, label+"="+value)
+	})
+	if nil != err || "a=1 b=2" != strings.Join(labels, " ") {
+		dbg.Error("LoadConfigValues stdin: %v %v", labels, err)
+		t.Fail()
+	}
+
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	w.Write([]byte("\ufeffgroup (\n\tport := 80\n)\n"))
+	w.Close()
+	c, err = ParseReader(&b)
+	if v, _ := c.GetInt("group:port"); nil != err || 80 != v {
+		dbg.Error("ParseReader: %v %v", v, err)
+		t.Fail()
+	}
+
+	labels = labels[:0]
+	err = HandleConfigReader(strings.NewReader("items { x y }\n"), func(ct ConfigType, label string, data []string) {
+		labels = append(labels, label+"="+strings.Join(data, ","))
+	})
+	if nil != err || "items=x,y" != strings.Join(labels, " ") {
+		dbg.Error("HandleConfigReader: %v %v", labels, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,148 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+type (
+	/*
+		An UnknownError lists every label path found in the config that
+		 isn't decoded into a field, see Options.Strict, along with the
+		 closest field label for any near misses
+	*/
+	UnknownError struct {
+		Unknown     []string
+		Suggestions map[string]string // unknown path -> field label path
+	}
+)
+
+var (
+	ErrUnknownLabel = errors.New("Unknown config label")
+)
+
+func (e *UnknownError) Error() string {
+	s := make([]string, len(e.Unknown))
+	for i, p := range e.Unknown {
+		s[i] = p
+		if sg, ok := e.Suggestions[p]; ok {
+			s[i] += " (did you mean " + sg + "?)"
+		}
+	}
+	return "Unknown config labels: " + strings.Join(s, ", ")
+}
+
+func (e *UnknownError) Is(target error) bool {
+	return ErrUnknownLabel == target
+}
+
+// ------------------------------------------------------------------------- //
+
+// strict returns an *UnknownError for the entries below n that decoding
+// into the type leaves unused, nil if there are none
+func (c *Config) strict(rt reflect.Type, n *Node) error {
+	e := &UnknownError{Suggestions: make(map[string]string)}
+	c.unknownFields(rt, n, e)
+	if 0 == len(e.Unknown) {
+		return nil
+	}
+	return e
+}
+
+// unknownFields adds the entries of the group node n without a field of the
+// struct type, and those of the groups decoded into its struct fields
+func (c *Config) unknownFields(rt reflect.Type, n *Node, e *UnknownError) {
+	for reflect.Ptr == rt.Kind() || (reflect.Slice == rt.Kind() && reflect.Uint8 != rt.Elem().Kind()) {
+		rt = rt.Elem()
+	}
+	if reflect.Struct != rt.Kind() || ConfigGroup != n.Type || reflect.PtrTo(rt).Implements(textUnmarshalerT) {
+		return
+	}
+	for _, ch := range n.Children {
+		if ConfigComment == ch.Type {
+			continue
+		}
+		if sf, ok := field(rt, ch.Label); ok {
+			c.unknownFields(sf.Type, ch, e)
+			continue
+		}
+		if e.has(ch.Path) {
+			continue // in a repeated group
+		}
+		e.Unknown = append(e.Unknown, ch.Path)
+		labels := []string{}
+		for i := 0; i < rt.NumField(); i++ {
+			if sf := rt.Field(i); "" == sf.PkgPath && "-" != sf.Tag.Get("cfg") {
+				labels = append(labels, fieldLabel(sf))
+			}
+		}
+		if sg := closest(ch.Label, labels); "" != sg {
+			e.Suggestions[ch.Path] = strings.TrimPrefix(n.Path+":"+sg, ":")
+		}
+	}
+}
+
+func (e *UnknownError) has(path string) bool {
+	for _, p := range e.Unknown {
+		if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// field returns the struct field a label is decoded into, see Node.child
+func field(rt reflect.Type, label string) (reflect.StructField, bool) {
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("cfg")
+		if "" != sf.PkgPath || "-" == tag {
+			continue
+		}
+		if ("" != tag && label == tag) || ("" == tag && strings.EqualFold(label, sf.Name)) {
+			return sf, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// fieldLabel is the label a struct field is decoded from
+func fieldLabel(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("cfg"); "" != tag {
+		return tag
+	}
+	return sf.Name
+}
+
+// undeclared returns a violation for each entry of the config that neither
+// the schema declares nor is in a declared group or dictionary, reporting
+// just the group of an undeclared group
+func (s *Schema) undeclared(c *Config) []*PathError {
+	declared, open := map[string]bool{}, map[string]bool{}
+	for _, f := range s.fields {
+		if SchemaGroup == f.Type {
+			open[f.Path] = true
+		}
+		elems := strings.Split(f.Path, ":")
+		for i := range elems {
+			declared[strings.Join(elems[:i+1], ":")] = true
+		}
+	}
+	result, seen := []*PathError{}, map[string]bool{}
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, ch := range n.Children {
+			switch {
+			case ConfigComment == ch.Type || open[ch.Path]:
+			case declared[ch.Path]:
+				walk(ch)
+			case !seen[ch.Path]:
+				seen[ch.Path] = true
+				result = append(result, &PathError{ch.Path, ErrUnknownLabel})
+			}
+		}
+	}
+	walk(&c.root)
+	return result
+}
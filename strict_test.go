@@ -0,0 +1,59 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestStrict(t *testing.T) {
+	type server struct {
+		Host    string
+		Timeout time.Duration
+	}
+	var app struct {
+		Name    string
+		Servers []server `cfg:"server"`
+		Skip    string   `cfg:"-"`
+	}
+	data := "name := app\nskip := x\nextra (\n\ta := 1\n)\n" +
+		"server (\n\thost := a\n\ttimout := 5s\n)\nserver (\n\thost := b\n\ttimout := 1s\n)\n"
+	c, _ := Parse(data)
+	if err := c.Unmarshal(&app); nil != err || 2 != len(app.Servers) {
+		dbg.Error("Unmarshal not strict: %v", err)
+		t.Fail()
+	}
+
+	c, _ = Options{Strict: true}.Parse(data)
+	err := c.Unmarshal(&app)
+	var ue *UnknownError
+	if !errors.As(err, &ue) || !errors.Is(err, ErrUnknownLabel) {
+		dbg.Error("Unmarshal strict: %v", err)
+		t.FailNow()
+	}
+	if want := []string{"skip", "extra", "server:timout"}; !reflect.DeepEqual(want, ue.Unknown) ||
+		"server:Timeout" != ue.Suggestions["server:timout"] {
+		dbg.Error("Unmarshal strict: %v %v", ue.Unknown, ue.Suggestions)
+		t.Fail()
+	}
+	if _, err = Get[server](c, "server"); !errors.As(err, &ue) || 1 != len(ue.Unknown) {
+		dbg.Error("Get strict: %v", err)
+		t.Fail()
+	}
+	if _, err = Get[string](c, "server[0]:host"); nil != err {
+		dbg.Error("Get strict value: %v", err)
+		t.Fail()
+	}
+
+	s := NewSchema().Required("name", SchemaValue).Optional("server:host", SchemaValue).Optional("extra", SchemaGroup)
+	err = s.Validate(c)
+	var se *SchemaError
+	if !errors.As(err, &se) || 2 != len(se.Violations) || "skip" != se.Violations[0].Path || "server:timout" != se.Violations[1].Path ||
+		ErrUnknownLabel != se.Violations[1].Err {
+		dbg.Error("Validate strict: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,137 @@
+package cfg
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// valueSub is a subscription to the value of a label path, see
+	// Reloader.subscribeValue
+	valueSub struct {
+		send  func(c *Config)
+		close func()
+	}
+)
+
+/*
+	Returns a channel given the value for the label path now and then each
+	 time a reload changes it, so a component can bind a setting instead of
+	 reading the config:
+
+		level, cancel := r.SubscribeString("log:level")
+		defer cancel()
+		for l := range level {
+			...
+		}
+
+	The channel holds only the latest value, one not yet read is replaced
+	 by a newer one, and nothing is sent while the label path is missing.
+	 The channel is closed by cancel or Close; cancel must not be called
+	 from a subscriber
+*/
+func (r *Reloader) SubscribeString(path string) (<-chan string, func()) {
+	return subscribe(r, func(c *Config) (string, bool) {
+		return c.Value(path)
+	})
+}
+
+/*
+	As SubscribeString, for the value as an integer (see Config.GetInt); a
+	 value that isn't an integer is not sent
+*/
+func (r *Reloader) SubscribeInt(path string) (<-chan int64, func()) {
+	return subscribe(r, func(c *Config) (int64, bool) {
+		v, err := c.GetInt(path)
+		return v, nil == err
+	})
+}
+
+/*
+	As SubscribeString, for the value as a floating point number (see
+	 Config.GetFloat)
+*/
+func (r *Reloader) SubscribeFloat(path string) (<-chan float64, func()) {
+	return subscribe(r, func(c *Config) (float64, bool) {
+		v, err := c.GetFloat(path)
+		return v, nil == err
+	})
+}
+
+/*
+	As SubscribeString, for the value as a boolean (see Config.GetBool)
+*/
+func (r *Reloader) SubscribeBool(path string) (<-chan bool, func()) {
+	return subscribe(r, func(c *Config) (bool, bool) {
+		v, err := c.GetBool(path)
+		return v, nil == err
+	})
+}
+
+/*
+	As SubscribeString, for the value as a duration (see Config.GetDuration)
+*/
+func (r *Reloader) SubscribeDuration(path string) (<-chan time.Duration, func()) {
+	return subscribe(r, func(c *Config) (time.Duration, bool) {
+		v, err := c.GetDuration(path)
+		return v, nil == err
+	})
+}
+
+// ------------------------------------------------------------------------- //
+
+// subscribe returns a channel given the value get finds in the current
+// config and then each new one, when it finds one that's changed
+func subscribe[T comparable](r *Reloader, get func(c *Config) (T, bool)) (<-chan T, func()) {
+	ch, ok := make(chan T, 1), false
+	var last T
+	cancel := r.subscribeValue(func(c *Config) {
+		if v, found := get(c); found && (!ok || v != last) {
+			last, ok = v, true
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- v
+		}
+	}, func() { close(ch) })
+	return ch, cancel
+}
+
+// subscribeValue calls send with the current config and then each new one,
+// returning the function cancelling the subscription; done is called once,
+// when cancelled or the Reloader is closed, after which send isn't called.
+// send is only called while holding r.reloading, so it's never called
+// concurrently
+func (r *Reloader) subscribeValue(send func(c *Config), done func()) func() {
+	var once sync.Once
+	v := &valueSub{send: send, close: func() { once.Do(done) }}
+
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+	r.mu.Lock()
+	closed := r.closed
+	if !closed {
+		r.values = append(r.values, v)
+	}
+	r.mu.Unlock()
+	if closed {
+		v.close()
+		return v.close
+	}
+	send(r.Config())
+	return func() {
+		r.mu.Lock()
+		for i, s := range r.values {
+			if s == v {
+				r.values = append(r.values[:i:i], r.values[i+1:]...)
+				break
+			}
+		}
+		r.mu.Unlock()
+		// wait for any reload sending to the subscription
+		r.reloading.Lock()
+		v.close()
+		r.reloading.Unlock()
+	}
+}
@@ -0,0 +1,69 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestReloaderSubscribeValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "app.cfg")
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("level := info\npool := 4\ndebug := no\n"), 0644), "WriteFile")
+	r, err := Options{WatchInterval: 5 * time.Millisecond, ReloadDelay: 20 * time.Millisecond}.NewReloader(flPath, nil)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	level, _ := r.SubscribeString("level")
+	pool, cancelPool := r.SubscribeInt("pool")
+	debug, _ := r.SubscribeBool("debug")
+	timeout, _ := r.SubscribeDuration("timeout")
+	if "info" != <-level || 4 != <-pool || false != <-debug || 0 != len(timeout) {
+		dbg.Error("Subscribe initial values")
+		t.Fail()
+	}
+
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("level := debug\npool := 4\ndebug := yes\ntimeout := 2s\n"), 0644), "WriteFile")
+	select {
+	case l := <-level:
+		if "debug" != l {
+			dbg.Error("SubscribeString: %q", l)
+			t.Fail()
+		}
+	case <-time.After(5 * time.Second):
+		dbg.Error("Reloader didn't reload")
+		t.FailNow()
+	}
+	if d := <-timeout; 2*time.Second != d || true != <-debug {
+		dbg.Error("SubscribeDuration: %v", d)
+		t.Fail()
+	}
+	if 0 != len(pool) {
+		dbg.Error("SubscribeInt sent an unchanged value: %d", <-pool)
+		t.Fail()
+	}
+
+	cancelPool()
+	if _, ok := <-pool; ok {
+		dbg.Error("SubscribeInt channel open after cancel")
+		t.Fail()
+	}
+	cancelPool()
+	r.Close()
+	if _, ok := <-level; ok {
+		dbg.Error("SubscribeString channel open after Close")
+		t.Fail()
+	}
+	floats, _ := r.SubscribeFloat("pool")
+	if _, ok := <-floats; ok {
+		dbg.Error("SubscribeFloat channel open after Close")
+		t.Fail()
+	}
+}
@@ -0,0 +1,108 @@
+package cfg
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+type (
+	// syntax holds the regexes used to find each type of config data for a
+	// given set of label characters
+	syntax struct {
+		st, value, block, heredoc, lines, items, dict, pair *regexp.Regexp
+		inline, inlineValue, label                          *regexp.Regexp
+	}
+)
+
+const (
+	// the characters of a label by default & with Options.UnicodeLabels
+	asciiLabelChars   = `\w`
+	unicodeLabelChars = `\pL\pN\pM_`
+)
+
+var (
+	defaultSyntax = &syntax{
+		st:      findConfigStRex,
+		value:   findConfigValueRex,
+		block:   findConfigBlockRex,
+		heredoc: findConfigHeredocRex,
+		lines:   findConfigLinesRex,
+		items:   findConfigItemsRex,
+		dict:    findConfigDictRex,
+		pair:    dictRex,
+
+		inline:      findConfigInlineRex,
+		inlineValue: inlineValueRex,
+		label:       labelRex,
+	}
+	syntaxes sync.Map // label chars -> *syntax
+
+	labelRex = regexp.MustCompile(`^(\w+)$`)
+)
+
+// syntax returns the regexes matching the labels allowed by the options
+func (o Options) syntax() *syntax {
+	chars := asciiLabelChars
+	if o.UnicodeLabels {
+		chars = unicodeLabelChars
+	}
+	for _, r := range o.LabelChars {
+		switch {
+		case ':' == r || unicode.IsSpace(r):
+		case r < utf8.RuneSelf && !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			// ASCII punctuation, escaped so it can't end the class or
+			// give a range
+			chars += `\` + string(r)
+		default:
+			// as is, an escaped letter being a class of its own (\w, \s)
+			chars += string(r)
+		}
+	}
+	if asciiLabelChars == chars {
+		return defaultSyntax
+	}
+	return labelSyntax(chars)
+}
+
+// labelSyntax builds (once) the regexes for labels made of the characters
+// of a regexp character class
+func labelSyntax(chars string) *syntax {
+	if s, ok := syntaxes.Load(chars); ok {
+		return s.(*syntax)
+	}
+	// the label is always the first (\w+) of each regex
+	label := "([" + chars + "]+)"
+	rex := func(r *regexp.Regexp) *regexp.Regexp {
+		return regexp.MustCompile(strings.Replace(r.String(), `(\w+)`, label, 1))
+	}
+	s := &syntax{
+		st:      rex(findConfigStRex),
+		value:   rex(findConfigValueRex),
+		block:   rex(findConfigBlockRex),
+		heredoc: rex(findConfigHeredocRex),
+		lines:   rex(findConfigLinesRex),
+		items:   rex(findConfigItemsRex),
+		dict:    rex(findConfigDictRex),
+		pair:    rex(dictRex),
+
+		inline:      rex(findConfigInlineRex),
+		inlineValue: rex(inlineValueRex),
+		label:       rex(labelRex),
+	}
+	actual, _ := syntaxes.LoadOrStore(chars, s)
+	return actual.(*syntax)
+}
+
+// pairs is StringListToPairs using the label characters of the syntax
+func (s *syntax) pairs(l []string) []string {
+	result := []string{}
+	for _, v := range l {
+		if x := s.pair.FindStringSubmatch(v); nil != x {
+			result = append(result, x[1], x[2])
+		}
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package cfg
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/jayacarlson/dbg"
+)
+
+type (
+	/*
+		A TemplateError records a failure to parse or execute the template
+		 of LoadTemplated, with the line of the template file it's on
+	*/
+	TemplateError struct {
+		Path string
+		Line int
+		Err  error
+	}
+)
+
+var (
+	// the name and position text/template gives its errors
+	// 1: line
+	templateLineRex = regexp.MustCompile(`^template: .*?:(\d+)(?::\d+)?: `)
+)
+
+func (e *TemplateError) Error() string {
+	return e.Path + ":" + strconv.Itoa(e.Line) + ": " + templateLineRex.ReplaceAllString(e.Err.Error(), "")
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	Reads the config file as a text/template, executes it with the data
+	 and functions and parses the result into a Config tree, so configs
+	 generated per host can be kept as a single file:
+
+		listen := {{ .Host }}:{{ .Port }}
+		{{ if .Debug }}log := debug{{ end }}
+
+	A missing map key is an error rather than "<no value>".  Errors of the
+	 template are *TemplateErrors giving the line of the template; @include
+	 lines are expanded once the template has been executed
+*/
+func LoadTemplated(flPath string, data interface{}, funcs template.FuncMap) (*Config, error) {
+	return Options{}.LoadTemplated(flPath, data, funcs)
+}
+
+/*
+	As LoadTemplated, using these options; Options.Cache isn't used, as the
+	 result depends on the data
+*/
+func (o Options) LoadTemplated(flPath string, data interface{}, funcs template.FuncMap) (*Config, error) {
+	o, loaded := o.loading(flPath)
+	text, err := o.readText(flPath)
+	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
+		return nil, err
+	}
+	t, err := template.New(filepath.Base(flPath)).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if nil != err {
+		return nil, templateError(flPath, err)
+	}
+	var b strings.Builder
+	if err = t.Execute(&b, data); nil != err {
+		return nil, templateError(flPath, err)
+	}
+	if nil == o.Include {
+		o.Include = o.FileInclude
+	}
+	str, err := o.preprocess([]string{flPath}, b.String())
+	if nil != err {
+		return nil, err
+	}
+	c, err := o.parse(str)
+	if nil != err {
+		return nil, err
+	}
+	loaded(c)
+	return c, nil
+}
+
+// ------------------------------------------------------------------------- //
+
+// templateError gives the error of text/template the line it reports
+func templateError(flPath string, err error) error {
+	line := 0
+	if x := templateLineRex.FindStringSubmatch(err.Error()); nil != x {
+		line, _ = strconv.Atoi(x[1])
+	}
+	return &TemplateError{flPath, line, err}
+}
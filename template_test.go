@@ -0,0 +1,48 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestLoadTemplated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+
+	flPath := filepath.Join(dir, "app.cfg")
+	ioutil.WriteFile(flPath, []byte("# {{ .Host }}\nlisten := {{ .Host }}:{{ .Port }}\n"+
+		"workers := {{ double .CPUs }}\n{{ range .Tags }}tag{{ . }} := on\n{{ end }}"), 0644)
+	funcs := template.FuncMap{"double": func(n int) int { return 2 * n }}
+	data := map[string]interface{}{"Host": "web1", "Port": 8080, "CPUs": 4, "Tags": []string{"a", "b"}}
+	c, err := LoadTemplated(flPath, data, funcs)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if "web1:8080" != c.ValueOr("listen", "") || "8" != c.ValueOr("workers", "") ||
+		"on" != c.ValueOr("tagb", "") || flPath != c.Source() {
+		dbg.Error("LoadTemplated: %v", c.Flatten())
+		t.Fail()
+	}
+
+	delete(data, "Port")
+	var te *TemplateError
+	if _, err = LoadTemplated(flPath, data, funcs); !errors.As(err, &te) || 2 != te.Line ||
+		!strings.HasPrefix(err.Error(), flPath+":2: ") {
+		dbg.Error("LoadTemplated missing key: %v", err)
+		t.Fail()
+	}
+	ioutil.WriteFile(flPath, []byte("a := 1\n\nb := {{ nope }}\n"), 0644)
+	if _, err = LoadTemplated(flPath, data, funcs); !errors.As(err, &te) || 3 != te.Line {
+		dbg.Error("LoadTemplated unknown function: %v", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,432 @@
+package toml
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jayacarlson/cfg"
+	"github.com/jayacarlson/cfg/internal/tree"
+)
+
+type (
+	/*
+		A SyntaxError records TOML that can't be read along with its line
+		 number in the document
+	*/
+	SyntaxError struct {
+		Line int
+		Text string
+		Err  error
+	}
+
+	parser struct {
+		s   string
+		pos int
+	}
+)
+
+var (
+	ErrSyntax       = errors.New("Invalid TOML")
+	ErrDuplicateKey = errors.New("TOML key defined more than once")
+
+	// an array mixing tables, arrays and values
+	ErrMixedArray = tree.ErrMixedArray
+
+	bareRex   = regexp.MustCompile(`^[A-Za-z0-9_-]+`)
+	numberRex = regexp.MustCompile(`^[+-]?(\d|inf|nan|0x|0o|0b)`)
+	dateRex   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	timeRex   = regexp.MustCompile(`^ \d{2}:`)
+)
+
+func (e *SyntaxError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + " \"" + e.Text + "\": " + e.Err.Error()
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	Converts the TOML document into a Config:
+
+		table                       a ( ) group
+		array of tables             a repeated group
+		string                      a := value, or a < > block if multi-line
+		integer, float, bool, date  a := value of the TOML text
+		array of values             { } items
+		array of arrays             repeated { } items
+		inline table                a ( ) group
+
+	Dotted keys are nested groups, so a.b = 1 is the value a:b; numbers
+	 lose any '_' separators so they can be read by GetInt & GetFloat
+*/
+func ToConfig(data []byte) (*cfg.Config, error) {
+	m, err := parse(data)
+	if nil != err {
+		return nil, err
+	}
+	return tree.Config(m)
+}
+
+// ------------------------------------------------------------------------- //
+
+func parse(data []byte) (*tree.Map, error) {
+	str := strings.TrimPrefix(string(data), "\ufeff")
+	p := &parser{s: strings.ReplaceAll(str, "\r\n", "\n")}
+	root := tree.NewMap()
+	table := root
+	defined := make(map[*tree.Map]bool) // tables given by a [header]
+	for p.blank(); p.pos < len(p.s); p.blank() {
+		start := p.pos
+		if '[' == p.s[p.pos] {
+			array := strings.HasPrefix(p.s[p.pos:], "[[")
+			if p.pos++; array {
+				p.pos++
+			}
+			keys, err := p.keys()
+			if nil == err && (!p.expect("]") || array && !p.expect("]")) {
+				err = ErrSyntax
+			}
+			if nil == err {
+				table, err = header(root, keys, array)
+			}
+			if nil == err && !array && defined[table] {
+				err = ErrDuplicateKey
+			}
+			if nil != err {
+				return nil, p.error(start, err)
+			}
+			defined[table] = true
+		} else {
+			keys, err := p.keys()
+			if nil == err && !p.expect("=") {
+				err = ErrSyntax
+			}
+			var v interface{}
+			if nil == err {
+				v, err = p.value()
+			}
+			if nil == err {
+				err = set(table, keys, v)
+			}
+			if nil != err {
+				return nil, p.error(start, err)
+			}
+		}
+		// the rest of the line may only be a comment
+		p.space()
+		if p.pos < len(p.s) && '#' == p.s[p.pos] {
+			p.comment()
+		}
+		if p.pos < len(p.s) && '\n' != p.s[p.pos] {
+			return nil, p.error(p.pos, ErrSyntax)
+		}
+	}
+	return root, nil
+}
+
+func (p *parser) error(pos int, err error) error {
+	line := 1 + strings.Count(p.s[:pos], "\n")
+	text := p.s[strings.LastIndex(p.s[:pos], "\n")+1:]
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[:i]
+	}
+	return &SyntaxError{line, strings.TrimSpace(text), err}
+}
+
+// header returns the table (or new element of an array of tables) for the
+// keys of a [header] or [[header]]
+func header(root *tree.Map, keys []string, array bool) (*tree.Map, error) {
+	t := root
+	for i, k := range keys {
+		last := i == len(keys)-1
+		switch v := t.Values[k].(type) {
+		case nil:
+			if _, ok := t.Values[k]; ok {
+				return nil, ErrDuplicateKey
+			}
+			n := tree.NewMap()
+			if last && array {
+				t.Set(k, []interface{}{n})
+			} else {
+				t.Set(k, n)
+			}
+			t = n
+		case *tree.Map:
+			if last && array {
+				return nil, ErrDuplicateKey
+			}
+			t = v
+		case []interface{}:
+			if 0 == len(v) {
+				return nil, ErrDuplicateKey
+			}
+			n, ok := v[len(v)-1].(*tree.Map)
+			if !ok {
+				return nil, ErrDuplicateKey
+			}
+			if last && array {
+				n = tree.NewMap()
+				t.Set(k, append(v, n))
+			}
+			t = n
+		default:
+			return nil, ErrDuplicateKey
+		}
+	}
+	return t, nil
+}
+
+// set sets the value of the dotted keys in the table
+func set(t *tree.Map, keys []string, v interface{}) error {
+	for _, k := range keys[:len(keys)-1] {
+		switch n := t.Values[k].(type) {
+		case *tree.Map:
+			t = n
+		case nil:
+			if _, ok := t.Values[k]; ok {
+				return ErrDuplicateKey
+			}
+			m := tree.NewMap()
+			t.Set(k, m)
+			t = m
+		default:
+			return ErrDuplicateKey
+		}
+	}
+	k := keys[len(keys)-1]
+	if _, ok := t.Values[k]; ok {
+		return ErrDuplicateKey
+	}
+	t.Set(k, v)
+	return nil
+}
+
+// keys reads a bare, quoted or dotted key
+func (p *parser) keys() ([]string, error) {
+	result := []string{}
+	for {
+		p.space()
+		if p.pos >= len(p.s) {
+			return nil, ErrSyntax
+		}
+		switch c := p.s[p.pos]; {
+		case '"' == c || '\'' == c:
+			k, err := p.str()
+			if nil != err {
+				return nil, err
+			}
+			result = append(result, k)
+		default:
+			k := bareRex.FindString(p.s[p.pos:])
+			if "" == k {
+				return nil, ErrSyntax
+			}
+			p.pos += len(k)
+			result = append(result, k)
+		}
+		if p.space(); !p.expect(".") {
+			return result, nil
+		}
+	}
+}
+
+// value reads a string, array, inline table or other value
+func (p *parser) value() (interface{}, error) {
+	p.space()
+	if p.pos >= len(p.s) {
+		return nil, ErrSyntax
+	}
+	switch p.s[p.pos] {
+	case '"', '\'':
+		return p.str()
+	case '[':
+		p.pos++
+		result := []interface{}{}
+		for p.blank(); !p.expect("]"); p.blank() {
+			v, err := p.value()
+			if nil != err {
+				return nil, err
+			}
+			result = append(result, v)
+			if p.blank(); !p.expect(",") && !strings.HasPrefix(p.s[p.pos:], "]") {
+				return nil, ErrSyntax
+			}
+		}
+		return result, nil
+	case '{':
+		p.pos++
+		t := tree.NewMap()
+		for p.space(); !p.expect("}"); p.space() {
+			keys, err := p.keys()
+			if nil == err && !p.expect("=") {
+				err = ErrSyntax
+			}
+			var v interface{}
+			if nil == err {
+				v, err = p.value()
+			}
+			if nil == err {
+				err = set(t, keys, v)
+			}
+			if nil != err {
+				return nil, err
+			}
+			if p.space(); !p.expect(",") && !strings.HasPrefix(p.s[p.pos:], "}") {
+				return nil, ErrSyntax
+			}
+		}
+		return t, nil
+	}
+	end := p.pos + strings.IndexAny(p.s[p.pos:]+"\n", " \t\n,]}#")
+	v := p.s[p.pos:end]
+	if dateRex.MatchString(v) && timeRex.MatchString(p.s[end:]) {
+		// a date time with a space between the date and time
+		end++
+		end += strings.IndexAny(p.s[end:]+"\n", " \t\n,]}#")
+		v = p.s[p.pos:end]
+	}
+	p.pos = end
+	switch {
+	case "" == v:
+		return nil, ErrSyntax
+	case "true" == v || "false" == v || len(v) >= 10 && dateRex.MatchString(v[:10]):
+		return v, nil
+	case numberRex.MatchString(v):
+		return strings.ReplaceAll(v, "_", ""), nil
+	}
+	return nil, ErrSyntax
+}
+
+// str reads a basic or literal string, either of which may be multi-line
+func (p *parser) str() (string, error) {
+	q := p.s[p.pos : p.pos+1]
+	if strings.HasPrefix(p.s[p.pos:], q+q+q) {
+		p.pos += 3
+		if strings.HasPrefix(p.s[p.pos:], "\n") {
+			p.pos++
+		}
+		end := strings.Index(p.s[p.pos:], q+q+q)
+		if end < 0 {
+			return "", ErrSyntax
+		}
+		// up to two more quotes are part of the string
+		end += p.pos
+		for i := 0; i < 2 && end+3 < len(p.s) && q[0] == p.s[end+3]; i++ {
+			end++
+		}
+		s := p.s[p.pos:end]
+		p.pos = end + 3
+		if "'" == q {
+			return s, nil
+		}
+		return unescape(s, true)
+	}
+	for i := p.pos + 1; i < len(p.s) && '\n' != p.s[i]; i++ {
+		switch {
+		case '"' == q[0] && '\\' == p.s[i]:
+			i++
+		case q[0] == p.s[i]:
+			s := p.s[p.pos+1 : i]
+			p.pos = i + 1
+			if "'" == q {
+				return s, nil
+			}
+			return unescape(s, false)
+		}
+	}
+	return "", ErrSyntax
+}
+
+// unescape processes the escapes of a basic string, in a multi-line string
+// a '\' ending a line also removes the whitespace that follows it
+func unescape(s string, multi bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if '\\' != c {
+			b.WriteByte(c)
+			continue
+		}
+		if i++; i >= len(s) {
+			return "", ErrSyntax
+		}
+		switch c = s[i]; c {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case 'e':
+			b.WriteByte(0x1b)
+		case '"', '\\':
+			b.WriteByte(c)
+		case 'u', 'U':
+			n := 4
+			if 'U' == c {
+				n = 8
+			}
+			if i+1+n > len(s) {
+				return "", ErrSyntax
+			}
+			r, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			if nil != err {
+				return "", ErrSyntax
+			}
+			b.WriteRune(rune(r))
+			i += n
+		default:
+			rest := strings.TrimLeft(s[i:], " \t")
+			if !multi || !strings.HasPrefix(rest, "\n") {
+				return "", ErrSyntax
+			}
+			rest = strings.TrimLeft(rest, " \t\n")
+			i = len(s) - len(rest) - 1
+		}
+	}
+	return b.String(), nil
+}
+
+// blank skips whitespace, newlines and comments
+func (p *parser) blank() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n':
+			p.pos++
+		case '#':
+			p.comment()
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) comment() {
+	if i := strings.Index(p.s[p.pos:], "\n"); i >= 0 {
+		p.pos += i
+	} else {
+		p.pos = len(p.s)
+	}
+}
+
+func (p *parser) space() {
+	for p.pos < len(p.s) && (' ' == p.s[p.pos] || '\t' == p.s[p.pos]) {
+		p.pos++
+	}
+}
+
+// expect skips the text if it's next
+func (p *parser) expect(text string) bool {
+	if strings.HasPrefix(p.s[p.pos:], text) {
+		p.pos += len(text)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package toml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/cfg"
+	"github.com/jayacarlson/dbg"
+)
+
+func TestToConfig(t *testing.T) {
+	src := `# application settings
+name = "app"   # trailing comment
+port = 8_080
+debug = true
+when = 1979-05-27 07:32:00Z
+"quoted" = 'C:\path'
+motd = """
+hello
+  world"""
+joined = """\
+    one \
+    two"""
+escaped = "tab\there \u00e9"
+site.url = "http://x"
+tags = [ "a", "b c", ]
+pairs = [ [1, 2], [3] ]
+
+[db]
+host = "x"
+pool = { size = 4, idle.max = 2 }
+
+[db.replica]
+host = "y"
+
+[[server]]
+host = "a"
+
+[[server]]
+host = "b"
+[server.tls]
+cert = "b.pem"
+`
+	c, err := ToConfig([]byte(src))
+	if nil != err {
+		dbg.Error("ToConfig: %v", err)
+		t.FailNow()
+	}
+	checks := map[string]string{
+		"name": "app", "port": "8080", "debug": "true", "when": "1979-05-27 07:32:00Z",
+		"motd": "hello\n  world", "joined": "one two", "escaped": "tab\there é",
+		"site:url": "http://x", "db:host": "x", "db:pool:size": "4", "db:pool:idle:max": "2",
+		"db:replica:host": "y", "quoted": `C:\path`, "server[0]:host": "a", "server[1]:tls:cert": "b.pem",
+	}
+	for path, v := range checks {
+		if got, ok := c.Value(path); !ok || v != got {
+			dbg.Error("ToConfig %s: %q", path, got)
+			t.Fail()
+		}
+	}
+	if l, _ := c.GetStringList("tags"); !reflect.DeepEqual([]string{"a", "b c"}, l) {
+		dbg.Error("ToConfig tags: %v", l)
+		t.Fail()
+	}
+	if l, _ := c.GetStringLists("pairs"); 2 != len(l) || "3" != l[1][0] {
+		dbg.Error("ToConfig pairs: %v", l)
+		t.Fail()
+	}
+	if n, _ := c.GetInt("port"); 8080 != n {
+		dbg.Error("ToConfig port: %d", n)
+		t.Fail()
+	}
+
+	bad := map[string]error{
+		"a = 1\na = 2\n":           ErrDuplicateKey,
+		"[a]\n[a]\n":               ErrDuplicateKey,
+		"a = \"open\n":             ErrSyntax,
+		"a = [1, 2\n":              ErrSyntax,
+		"a = 1 b = 2\n":            ErrSyntax,
+		"a = [1, {b = 2}]\n":       ErrMixedArray,
+		"a = bare\n":               ErrSyntax,
+		"\"not a label\" = 1\n":    cfg.ErrIllegalLabel,
+		"[[a]]\nx = 1\n[a]\n":      ErrDuplicateKey,
+		"s = \"bad \\q escape\"\n": ErrSyntax,
+	}
+	for src, want := range bad {
+		if _, err := ToConfig([]byte(src)); !errors.Is(err, want) {
+			dbg.Error("ToConfig %q: %v", src, err)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,137 @@
+/*
+	Package toml converts cfg config data to TOML, e.g. to migrate to it or
+	 to feed standard tooling, and TOML documents to cfg configs; it needs no
+	 TOML library.  Groups become
+	 tables, repeated groups and table rows arrays of tables, and all values
+	 are written as strings
+*/
+package toml
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/jayacarlson/cfg"
+	"github.com/jayacarlson/cfg/internal/tree"
+)
+
+var (
+	bareKeyRex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+/*
+	Returns the config as a TOML document, see tree.Build for the mapping
+	 of each type of config data; binary data is written base64 encoded
+*/
+func Marshal(c *cfg.Config) ([]byte, error) {
+	m, err := tree.Build(c)
+	if nil != err {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeTable(&buf, m, nil)
+	return bytes.TrimPrefix(buf.Bytes(), []byte("\n")), nil
+}
+
+/*
+	Parses the config data and returns it as TOML
+*/
+func Convert(data []byte) ([]byte, error) {
+	c, err := cfg.Parse(string(data))
+	if nil != err {
+		return nil, err
+	}
+	return Marshal(c)
+}
+
+// ------------------------------------------------------------------------- //
+
+// writeTable writes the key/value pairs of the table, then its sub tables
+func writeTable(buf *bytes.Buffer, m *tree.Map, path []string) {
+	for _, k := range m.Keys {
+		if v := m.Values[k]; !isTable(v) && !isTableArray(v) {
+			buf.WriteString(key(k) + " = " + value(v) + "\n")
+		}
+	}
+	for _, k := range m.Keys {
+		p := append(path[:len(path):len(path)], key(k))
+		switch v := m.Values[k].(type) {
+		case *tree.Map:
+			buf.WriteString("\n[" + strings.Join(p, ".") + "]\n")
+			writeTable(buf, v, p)
+		case []interface{}:
+			if !isTableArray(v) {
+				break
+			}
+			for _, t := range v {
+				buf.WriteString("\n[[" + strings.Join(p, ".") + "]]\n")
+				writeTable(buf, t.(*tree.Map), p)
+			}
+		}
+	}
+}
+
+func isTable(v interface{}) bool {
+	_, ok := v.(*tree.Map)
+	return ok
+}
+
+// isTableArray reports a non-empty array of only tables
+func isTableArray(v interface{}) bool {
+	a, ok := v.([]interface{})
+	if !ok || 0 == len(a) {
+		return false
+	}
+	for _, i := range a {
+		if !isTable(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// value returns the inline form of a value
+func value(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return quote(v)
+	case []byte:
+		return quote(base64.StdEncoding.EncodeToString(v))
+	case *tree.Map:
+		pairs := []string{}
+		for _, k := range v.Keys {
+			pairs = append(pairs, key(k)+" = "+value(v.Values[k]))
+		}
+		if 0 == len(pairs) {
+			return "{}"
+		}
+		return "{ " + strings.Join(pairs, ", ") + " }"
+	case []interface{}:
+		items := []string{}
+		for _, i := range v {
+			items = append(items, value(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return `""`
+}
+
+func key(k string) string {
+	if bareKeyRex.MatchString(k) {
+		return k
+	}
+	return quote(k)
+}
+
+// quote returns the string as a JSON string, which is also a valid TOML
+// basic string
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,47 @@
+package toml
+
+import (
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestConvert(t *testing.T) {
+	src := "name := app\n" +
+		"motd <\nhello\nworld\n>\n" +
+		"server (\n\thost := a\n\tports {\n\t\t80 443\n\t}\n\tsub (\n\t\tdeep := 1\n\t)\n)\n" +
+		"server (\n\thost := b\n)\n" +
+		"env : [\n\tk : v\n]\n" +
+		"routes [table\n\tpath target\n\t/ web\n]\n" +
+		"tags {\n\ta b\n}\n"
+	want := `name = "app"
+motd = "hello\nworld"
+tags = ["a", "b"]
+
+[[server]]
+host = "a"
+ports = ["80", "443"]
+
+[server.sub]
+deep = "1"
+
+[[server]]
+host = "b"
+
+[env]
+k = "v"
+
+[[routes]]
+path = "/"
+target = "web"
+`
+	data, err := Convert([]byte(src))
+	if nil != err {
+		dbg.Error("Convert: %v", err)
+		t.FailNow()
+	}
+	if want != string(data) {
+		dbg.Error("Convert gave:\n%s", data)
+		t.Fail()
+	}
+}
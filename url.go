@@ -0,0 +1,175 @@
+package cfg
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	/*
+		URLOptions alter how LoadConfigURL fetches config data, the zero
+		 value using http.DefaultClient with a 30 second timeout
+	*/
+	URLOptions struct {
+		// The client used for the request, nil for http.DefaultClient
+		Client *http.Client
+
+		// The time allowed for the whole request, 0 for 30 seconds
+		Timeout time.Duration
+
+		// Headers added to the request, e.g. an Authorization token
+		Header http.Header
+
+		// When set, the response is kept along with its ETag and
+		//  Last-Modified headers, and a later request for the same URL
+		//  is conditional; a 304 Not Modified reply uses the kept data
+		Cache *URLCache
+
+		// The options used to parse the config data
+		Options Options
+	}
+
+	/*
+		A URLCache holds the last response for each URL fetched with it,
+		 it's safe for concurrent use and the zero value is ready to use
+	*/
+	URLCache struct {
+		mu      sync.Mutex
+		entries map[string]urlEntry
+	}
+
+	/*
+		An HTTPError records a failed (non 2xx) response to a config request
+	*/
+	HTTPError struct {
+		URL        string
+		StatusCode int
+		Status     string
+	}
+
+	urlEntry struct {
+		etag, modified string
+		data           []byte
+	}
+)
+
+const (
+	defaultURLTimeout = 30 * time.Second
+)
+
+func (e *HTTPError) Error() string {
+	return e.URL + ": " + e.Status
+}
+
+/*
+	Fetches the config data from the http(s) URL and parses it into a
+	 Config tree, as Parse would (so any @include is only handled with an
+	 Options.Include resolver); at most one URLOptions is used
+*/
+func LoadConfigURL(url string, opts ...URLOptions) (*Config, error) {
+	o := urlOptions(opts)
+	data, err := o.fetch(url)
+	if nil != err {
+		return nil, err
+	}
+	c, err := o.Options.Parse(data)
+	if nil != err {
+		return nil, err
+	}
+	c.source = url
+	return c, nil
+}
+
+/*
+	Fetches the config data from the http(s) URL and passes it to the
+	 handler as HandleConfigData does, see LoadConfigURL
+*/
+func LoadConfigDataURL(url string, f func(t ConfigType, label string, data []string), opts ...URLOptions) error {
+	o := urlOptions(opts)
+	data, err := o.fetch(url)
+	if nil != err {
+		return err
+	}
+	return o.Options.HandleConfigData(data, f)
+}
+
+// ------------------------------------------------------------------------- //
+
+func urlOptions(opts []URLOptions) URLOptions {
+	if 0 == len(opts) {
+		return URLOptions{}
+	}
+	return opts[0]
+}
+
+// fetch returns the text of the URL, or of the cached response when the
+// server replies that it hasn't changed
+func (o URLOptions) fetch(url string) (string, error) {
+	timeout := o.Timeout
+	if 0 == timeout {
+		timeout = defaultURLTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if nil != err {
+		return "", err
+	}
+	for k, v := range o.Header {
+		req.Header[k] = v
+	}
+	cached, ok := o.Cache.get(url)
+	if ok {
+		if "" != cached.etag {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if "" != cached.modified {
+			req.Header.Set("If-Modified-Since", cached.modified)
+		}
+	}
+	client := o.Client
+	if nil == client {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if nil != err {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if ok && http.StatusNotModified == resp.StatusCode {
+		return decodeText(url, cached.data, o.Options.MaxSize)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", &HTTPError{url, resp.StatusCode, resp.Status}
+	}
+	data, err := readAll(resp.Body, url, o.Options.MaxSize)
+	if nil != err {
+		return "", err
+	}
+	o.Cache.put(url, urlEntry{resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), data})
+	return decodeText(url, data, o.Options.MaxSize)
+}
+
+func (c *URLCache) get(url string) (urlEntry, bool) {
+	if nil == c {
+		return urlEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[url]
+	return e, ok
+}
+
+func (c *URLCache) put(url string, e urlEntry) {
+	if nil == c || ("" == e.etag && "" == e.modified) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if nil == c.entries {
+		c.entries = make(map[string]urlEntry)
+	}
+	c.entries[url] = e
+}
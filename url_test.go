@@ -0,0 +1,78 @@
+package cfg
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestLoadConfigURL(t *testing.T) {
+	requests, conditional := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/app.cfg":
+			if "secret" != r.Header.Get("X-Token") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if `"v1"` == r.Header.Get("If-None-Match") {
+				conditional++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte("\xef\xbb\xbfport := 8080\ndb (\n\thost := x\n)\n"))
+		case "/slow.cfg":
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte("port := 1\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	opts := URLOptions{Header: http.Header{"X-Token": {"secret"}}, Cache: &URLCache{}}
+	for i := 0; i < 2; i++ {
+		c, err := LoadConfigURL(srv.URL+"/app.cfg", opts)
+		if nil != err {
+			dbg.Error(err.Error())
+			t.FailNow()
+		}
+		if port, _ := c.GetInt("port"); 8080 != port || "x" != c.ValueOr("db:host", "") || srv.URL+"/app.cfg" != c.Source() {
+			dbg.Error("LoadConfigURL: %d %s", port, c.Source())
+			t.Fail()
+		}
+	}
+	if 2 != requests || 1 != conditional {
+		dbg.Error("LoadConfigURL cache: %d requests, %d conditional", requests, conditional)
+		t.Fail()
+	}
+
+	labels := []string{}
+	err := LoadConfigDataURL(srv.URL+"/app.cfg", func(ct ConfigType, label string, data []string) {
+		labels = append(labels, label)
+	}, opts)
+	if nil != err || 2 != len(labels) || "db:host" != labels[1] {
+		dbg.Error("LoadConfigDataURL: %v %v", labels, err)
+		t.Fail()
+	}
+
+	var he *HTTPError
+	if _, err := LoadConfigURL(srv.URL + "/app.cfg"); !errors.As(err, &he) || http.StatusForbidden != he.StatusCode {
+		dbg.Error("LoadConfigURL without header: %v", err)
+		t.Fail()
+	}
+	if _, err := LoadConfigURL(srv.URL + "/none.cfg"); !errors.As(err, &he) || http.StatusNotFound != he.StatusCode {
+		dbg.Error("LoadConfigURL missing: %v", err)
+		t.Fail()
+	}
+	if _, err := LoadConfigURL(srv.URL+"/slow.cfg", URLOptions{Timeout: 20 * time.Millisecond}); nil == err {
+		dbg.Error("LoadConfigURL ignored the timeout")
+		t.Fail()
+	}
+}
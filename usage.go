@@ -0,0 +1,116 @@
+package cfg
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	/*
+		An Access records an entry read by one of the accessors, see
+		 Options.AuditAccess; the Caller is the file:line of the code
+		 outside this package that read it
+	*/
+	Access struct {
+		Path   string
+		Time   time.Time
+		Caller string
+	}
+
+	// usage records the label paths of a Config that have been read, the
+	// accessors may be called from any number of goroutines
+	usage struct {
+		mu    sync.Mutex
+		paths map[string]bool
+		log   []Access
+	}
+)
+
+/*
+	Returns the label paths of the entries that haven't been read by any of
+	 the accessors (Value, the Get* functions, Unmarshal etc) in the order
+	 they're found; an entry of a group returned by Lookup or LookupAll
+	 counts as read.  Applications can warn about settings left behind
+	 after their code stopped using them.
+*/
+func (c *Config) Unused() []string {
+	result, seen := []string{}, map[string]bool{}
+	if nil == c.used {
+		return result
+	}
+	c.used.mu.Lock()
+	defer c.used.mu.Unlock()
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, ch := range n.Children {
+			switch {
+			case ConfigComment == ch.Type || c.used.paths[ch.Path]:
+			case ConfigGroup == ch.Type:
+				walk(ch)
+			case !seen[ch.Path]:
+				seen[ch.Path] = true
+				result = append(result, ch.Path)
+			}
+		}
+	}
+	walk(&c.root)
+	return result
+}
+
+/*
+	Returns the entries read by the accessors, in the order they were read,
+	 when the config was parsed with Options.AuditAccess; nil otherwise
+*/
+func (c *Config) AccessLog() []Access {
+	if nil == c.used {
+		return nil
+	}
+	c.used.mu.Lock()
+	defer c.used.mu.Unlock()
+	return append([]Access(nil), c.used.log...)
+}
+
+// ------------------------------------------------------------------------- //
+
+// use records the label path of the node as read, all of its entries too
+// when the node is a group and all is set
+func (c *Config) use(n *Node, all bool) {
+	if nil == n || nil == c.used || (ConfigGroup == n.Type && !all) {
+		return
+	}
+	c.used.mu.Lock()
+	defer c.used.mu.Unlock()
+	if nil == c.used.paths {
+		c.used.paths = make(map[string]bool)
+	}
+	c.used.paths[n.Path] = true
+	if c.opts.AuditAccess {
+		c.used.log = append(c.used.log, Access{n.Path, time.Now(), caller()})
+	}
+}
+
+// caller returns the file:line of the first caller outside this package,
+// its tests being outside it
+func caller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		f, more := frames.Next()
+		pkg, test := strings.HasPrefix(f.Function, "github.com/jayacarlson/cfg."), strings.HasSuffix(f.File, "_test.go")
+		if !pkg || test || !more {
+			return f.File + ":" + strconv.Itoa(f.Line)
+		}
+	}
+}
+
+// dict returns the dictionary of a ConfigDict node
+func (n *Node) dict() map[string]string {
+	d := make(map[string]string, len(n.Data)/2)
+	for i := 0; i+1 < len(n.Data); i += 2 {
+		d[n.Data[i]] = n.Data[i+1]
+	}
+	return d
+}
@@ -0,0 +1,73 @@
+package cfg
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestUnused(t *testing.T) {
+	c, _ := Parse("name := app\nold := x\nport := 80\nhosts { a b }\ndb (\n\thost := d\n\tlegacy := y\n)\n" +
+		"tls (\n\tcert := c\n\tkey := k\n)\nserver (\n\tport := 1\n\tstale := 2\n)\n")
+	want := []string{"name", "old", "port", "hosts", "db:host", "db:legacy", "tls:cert", "tls:key", "server:port", "server:stale"}
+	if got := c.Unused(); !reflect.DeepEqual(want, got) {
+		dbg.Error("Unused: %v", got)
+		t.Fail()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Value("name")
+			c.GetInt("port")
+		}()
+	}
+	wg.Wait()
+	c.GetStringList("hosts")
+	c.Value("db:host")
+	c.Lookup("tls")
+	var s struct{ Port int }
+	if _, err := Get[struct{ Port int }](c, "server"); nil != err {
+		dbg.Error(err.Error())
+		t.Fail()
+	}
+	c.Unmarshal(&s)
+	want = []string{"old", "db:legacy", "server:stale"}
+	if got := c.Unused(); !reflect.DeepEqual(want, got) {
+		dbg.Error("Unused after reads: %v", got)
+		t.Fail()
+	}
+	if got := c.Clone().Unused(); 10 != len(got) {
+		dbg.Error("Unused of Clone: %v", got)
+		t.Fail()
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	c, _ := Options{AuditAccess: true}.Parse("db (\n\thost := x\n\tport := 5432\n)\nname := app\n")
+	before := time.Now()
+	c.Value("db:host")
+	c.GetInt("db:port")
+	c.Value("missing")
+	log := c.AccessLog()
+	if 2 != len(log) || "db:host" != log[0].Path || "db:port" != log[1].Path {
+		dbg.Error("AccessLog: %v", log)
+		t.FailNow()
+	}
+	for _, a := range log {
+		if a.Time.Before(before) || !strings.Contains(a.Caller, "usage_test.go:") {
+			dbg.Error("AccessLog entry: %+v", a)
+			t.Fail()
+		}
+	}
+	if c, _ = Parse("a := 1\n"); "1" != c.ValueOr("a", "") || nil != c.AccessLog() {
+		dbg.Error("AccessLog without Options.AuditAccess: %v", c.AccessLog())
+		t.Fail()
+	}
+}
@@ -0,0 +1,98 @@
+package cfg
+
+import (
+	"sort"
+	"strings"
+)
+
+type (
+	/*
+		A MissingError lists every required label path that was not found,
+		 along with the closest existing path for any near misses
+	*/
+	MissingError struct {
+		Missing     []string
+		Suggestions map[string]string // missing path -> existing path
+	}
+)
+
+func (e *MissingError) Error() string {
+	s := make([]string, len(e.Missing))
+	for i, p := range e.Missing {
+		s[i] = p
+		if sg, ok := e.Suggestions[p]; ok {
+			s[i] += " (did you mean " + sg + "?)"
+		}
+	}
+	return "Missing required config labels: " + strings.Join(s, ", ")
+}
+
+/*
+	Checks that every label path exists in the config, returning a single
+	 *MissingError listing all of those that do not
+*/
+func (c *Config) Require(paths ...string) error {
+	var e *MissingError
+	for _, p := range paths {
+		if nil != c.node(c.opts.internalPath(p)) {
+			continue
+		}
+		if nil == e {
+			e = &MissingError{Suggestions: make(map[string]string)}
+		}
+		e.Missing = append(e.Missing, p)
+		if sg := c.suggest(p); "" != sg {
+			e.Suggestions[p] = sg
+		}
+	}
+	if nil == e {
+		return nil
+	}
+	return e
+}
+
+// ------------------------------------------------------------------------- //
+
+// suggest finds the existing label path closest to the given path, only
+// paths within a small edit distance are considered near misses
+func (c *Config) suggest(path string) string {
+	paths := make([]string, 0, len(c.nodes))
+	for p := range c.nodes {
+		paths = append(paths, p)
+	}
+	return closest(path, paths)
+}
+
+// closest returns the candidate within a small edit distance of the path
+// that is closest to it, "" if there isn't one
+func closest(path string, paths []string) string {
+	sort.Strings(paths)
+	best, bestDist := "", len(path)/3+1
+	for _, p := range paths {
+		if d := editDistance(strings.ToLower(path), strings.ToLower(p)); d < bestDist {
+			best, bestDist = p, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a & b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev, cur := make([]int, len(rb)+1), make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
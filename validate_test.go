@@ -0,0 +1,30 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestRequire(t *testing.T) {
+	c, _ := Parse(string(conf))
+	if err := c.Require("testData:apple", "testData:lists:items1"); nil != err {
+		dbg.Error(err.Error())
+		t.Fail()
+	}
+	err := c.Require("testData:aple", "testData:apple", "db:host")
+	var me *MissingError
+	if !errors.As(err, &me) || len(me.Missing) != 2 {
+		dbg.Error("Require: %v", err)
+		t.FailNow()
+	}
+	if me.Suggestions["testData:aple"] != "testData:apple" {
+		dbg.Error("Suggestion: %v", me.Suggestions)
+		t.Fail()
+	}
+	if _, ok := me.Suggestions["db:host"]; ok {
+		dbg.Error("Unexpected suggestion: %v", me.Suggestions)
+		t.Fail()
+	}
+}
@@ -0,0 +1,97 @@
+package cfg
+
+import (
+	"sync"
+)
+
+type (
+	/*
+		A Validator checks the value of a label path, e.g. that a port is
+		 in range or a file exists, returning an error if it's not valid
+	*/
+	Validator func(path, value string) error
+
+	pathValidator struct {
+		pattern string
+		fn      Validator
+	}
+)
+
+var (
+	validatorLock sync.RWMutex
+	validators    []pathValidator
+)
+
+/*
+	Registers a validator for every label path matching the pattern (see
+	 MatchPath), run by Parse & the Load* functions that build a Config for
+	 each ConfigValue & ConfigBlock, and for each entry of ConfigItems &
+	 ConfigLines, and by Unmarshal & Get for the entries decoded.  An error
+	 is returned as a *PathError (or a *ListError for a list entry), those
+	 of every failing entry together as a *MultiError
+
+	Validators are run in the order registered; registering a nil fn
+	 removes any validator for the pattern
+*/
+func RegisterValidator(pattern string, fn Validator) {
+	validatorLock.Lock()
+	defer validatorLock.Unlock()
+	for i, v := range validators {
+		if v.pattern == pattern {
+			validators = append(validators[:i], validators[i+1:]...)
+			break
+		}
+	}
+	if nil != fn {
+		validators = append(validators, pathValidator{pattern, fn})
+	}
+}
+
+// ------------------------------------------------------------------------- //
+
+// validate runs the validators matching the label path on the value
+func validate(path, value string) error {
+	validatorLock.RLock()
+	defer validatorLock.RUnlock()
+	for _, v := range validators {
+		if MatchPath(v.pattern, path) {
+			if err := v.fn(path, value); nil != err {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateNode runs the validators on the value or the list entries of
+// the node
+func validateNode(n *Node) error {
+	switch n.Type {
+	case ConfigValue, ConfigBlock:
+		if err := validate(n.Path, n.Data[0]); nil != err {
+			return &PathError{n.Path, err}
+		}
+	case ConfigItems, ConfigLines:
+		var errs []error
+		for i, s := range n.Data {
+			if err := validate(n.Path, s); nil != err {
+				errs = append(errs, &ListError{n.Path, i, s, err})
+			}
+		}
+		return joinErrors(errs)
+	}
+	return nil
+}
+
+// validated runs the validators on every entry of the config, then the
+// Options.Rules, returning all of the errors found
+func (c *Config) validated() error {
+	var errs []error
+	c.flatten(&c.root, func(n *Node) {
+		errs = appendError(errs, validateNode(n))
+	})
+	if 0 != len(c.opts.Rules) {
+		errs = appendError(errs, CheckRules(c, c.opts.Rules...))
+	}
+	return joinErrors(errs)
+}
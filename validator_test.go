@@ -0,0 +1,77 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestRegisterValidator(t *testing.T) {
+	errPort := errors.New("Port out of range")
+	RegisterValidator("*:port", func(path, value string) error {
+		if n, err := strconv.Atoi(value); nil != err || n < 1 || n > 65535 {
+			return errPort
+		}
+		return nil
+	})
+	defer RegisterValidator("*:port", nil)
+	RegisterValidator("hosts", func(path, value string) error {
+		if "" == value || "-" == value {
+			return errors.New("Empty host")
+		}
+		return nil
+	})
+	defer RegisterValidator("hosts", nil)
+
+	if _, err := Parse("db (\n\tport := 5432\n)\nhosts { a b }\n"); nil != err {
+		dbg.Error("RegisterValidator valid: %v", err)
+		t.Fail()
+	}
+	_, err := Parse("db (\n\tport := 99999\n)\n")
+	var pe *PathError
+	if !errors.As(err, &pe) || "db:port" != pe.Path || !errors.Is(err, errPort) {
+		dbg.Error("RegisterValidator port: %v", err)
+		t.Fail()
+	}
+	_, err = Parse("hosts { a - c }\n")
+	var le *ListError
+	if !errors.As(err, &le) || 1 != le.Index {
+		dbg.Error("RegisterValidator hosts: %v", err)
+		t.Fail()
+	}
+
+	_, err = Parse("a (\n\tport := 0\n)\nb (\n\tport := 70000\n)\nhosts { - b - }\n")
+	var me *MultiError
+	if !errors.As(err, &me) || 4 != len(me.Errs) || !errors.As(me.Errs[1], &pe) || "b:port" != pe.Path {
+		dbg.Error("RegisterValidator all errors: %v", err)
+		t.Fail()
+	}
+
+	// a later file can fix the value of an earlier one
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	base, site := filepath.Join(dir, "base.cfg"), filepath.Join(dir, "site.cfg")
+	ioutil.WriteFile(base, []byte("web (\n\tport := 0\n)\n"), 0644)
+	ioutil.WriteFile(site, []byte("web (\n\tport := 80\n)\n"), 0644)
+	if _, err = ParseFiles(base, site); nil != err {
+		dbg.Error("RegisterValidator ParseFiles: %v", err)
+		t.Fail()
+	}
+	if _, err = ParseFiles(site, base); !errors.Is(err, errPort) {
+		dbg.Error("RegisterValidator ParseFiles invalid: %v", err)
+		t.Fail()
+	}
+
+	// and the decoded entries of a config built in code
+	c, _ := NewBuilder().Group("api").Value("port", "-1").End().Config()
+	if _, err = Get[int](c, "api:port"); !errors.Is(err, errPort) {
+		dbg.Error("RegisterValidator Get: %v", err)
+		t.Fail()
+	}
+}
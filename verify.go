@@ -0,0 +1,173 @@
+package cfg
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jayacarlson/dbg"
+)
+
+type (
+	/*
+		A Keyring holds the keys a signed config may be verified with, a
+		 signature matching any one of them is accepted
+	*/
+	Keyring struct {
+		HMACKeys   [][]byte // HMAC-SHA256 secret keys
+		PublicKeys []ed25519.PublicKey
+	}
+)
+
+const (
+	// the extension of a detached signature file, e.g. app.cfg.sig
+	SignatureExt = ".sig"
+
+	// the line starting a trailing signature, the last line of the file
+	SignatureDirective = "@signature "
+)
+
+var (
+	ErrUnsigned     = errors.New("Config file is not signed")
+	ErrBadSignature = errors.New("Config file signature not verified")
+)
+
+/*
+	Loads the config file as LoadConfig does once its signature has been
+	 verified with the keyring; the signature is the text of a detached
+	 signature file (the path + SignatureExt) or else a last line of the
+	 file of '@signature ' and the signature, which signs the bytes before
+	 that line.  A signature is the text returned by SignHMAC or
+	 SignEd25519, e.g. 'ed25519:<base64>'.
+
+	Included files must be signed in the same way, a file without a valid
+	 signature gives a PathError of ErrUnsigned or ErrBadSignature
+*/
+func LoadVerified(flPath string, keys Keyring) (*Config, error) {
+	return Options{}.LoadVerified(flPath, keys)
+}
+
+/*
+	As LoadVerified, using these options; any Options.Include is replaced
+	 by the verified reading of included files
+*/
+func (o Options) LoadVerified(flPath string, keys Keyring) (*Config, error) {
+	o, loaded := o.loading(flPath)
+	data, err := o.readVerified(flPath, keys)
+	if dbg.ChkErr(err, "Failed to read config file: %s (%v)", flPath, err) {
+		return nil, err
+	}
+	o.Include = func(from, path string) (string, string, error) {
+		if !filepath.IsAbs(path) && "" != from {
+			path = filepath.Join(filepath.Dir(from), path)
+		}
+		data, err := o.readVerified(path, keys)
+		return path, data, err
+	}
+	data, err = o.preprocess([]string{flPath}, data)
+	if nil != err {
+		return nil, err
+	}
+	c, err := o.parse(data)
+	if nil != err {
+		return nil, err
+	}
+	loaded(c)
+	return c, nil
+}
+
+/*
+	Returns the signature of the data made with an HMAC-SHA256 key
+*/
+func SignHMAC(data, key []byte) string {
+	return "hmac-sha256:" + base64.StdEncoding.EncodeToString(hmacSum(data, key))
+}
+
+/*
+	Returns the signature of the data made with an ed25519 private key
+*/
+func SignEd25519(data []byte, key ed25519.PrivateKey) string {
+	return "ed25519:" + base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+}
+
+// ------------------------------------------------------------------------- //
+
+// readVerified reads the text of a file once its signature is verified
+func (o Options) readVerified(flPath string, keys Keyring) (string, error) {
+	data, err := readFile(flPath, o.MaxSize)
+	if nil != err {
+		return "", err
+	}
+	if nil != o.seen {
+		// the text of the whole file, as Stale reads it
+		if text, err := decodeText(flPath, data, o.MaxSize); nil == err {
+			o.seen(flPath, text)
+		}
+	}
+	sig, err := readFile(flPath+SignatureExt, o.MaxSize)
+	switch {
+	case nil == err:
+	case os.IsNotExist(err):
+		data, sig = trailingSignature(data)
+	default:
+		return "", err
+	}
+	if 0 == len(sig) {
+		return "", &PathError{flPath, ErrUnsigned}
+	}
+	if !keys.verify(data, strings.TrimSpace(string(sig))) {
+		return "", &PathError{flPath, ErrBadSignature}
+	}
+	return decodeText(flPath, data, o.MaxSize)
+}
+
+// trailingSignature splits off a last line of '@signature ...', returning
+// the data before it and the signature, or the data unchanged and nil if
+// there isn't one
+func trailingSignature(data []byte) ([]byte, []byte) {
+	text := bytes.TrimRight(data, " \t\r\n")
+	start := bytes.LastIndexByte(text, '\n') + 1
+	if !bytes.HasPrefix(text[start:], []byte(SignatureDirective)) {
+		return data, nil
+	}
+	return data[:start], text[start+len(SignatureDirective):]
+}
+
+// verify checks the signature with each key of its kind
+func (k Keyring) verify(data []byte, sig string) bool {
+	i := strings.Index(sig, ":")
+	if i < 0 {
+		return false
+	}
+	b, err := base64.StdEncoding.DecodeString(sig[i+1:])
+	if nil != err {
+		return false
+	}
+	switch sig[:i] {
+	case "hmac-sha256":
+		for _, key := range k.HMACKeys {
+			if hmac.Equal(hmacSum(data, key), b) {
+				return true
+			}
+		}
+	case "ed25519":
+		for _, key := range k.PublicKeys {
+			if ed25519.PublicKeySize == len(key) && ed25519.Verify(key, data, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hmacSum(data, key []byte) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write(data)
+	return m.Sum(nil)
+}
@@ -0,0 +1,70 @@
+package cfg
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestLoadVerified(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	write := func(name, data string) string {
+		flPath := filepath.Join(dir, name)
+		dbg.ChkErr(ioutil.WriteFile(flPath, []byte(data), 0644), "WriteFile")
+		return flPath
+	}
+	pub, priv, err := ed25519.GenerateKey(nil)
+	dbg.ChkErr(err, "GenerateKey")
+	secret := []byte("edge secret")
+	keys := Keyring{HMACKeys: [][]byte{[]byte("old"), secret}, PublicKeys: []ed25519.PublicKey{pub}}
+
+	// a detached ed25519 signature
+	data := "host := edge1\n@include db.cfg\n"
+	main := write("main.cfg", data)
+	write("main.cfg.sig", SignEd25519([]byte(data), priv)+"\n")
+	// a trailing HMAC signature
+	db := "db (\n\tport := 5432\n)\n"
+	write("db.cfg", db+SignatureDirective+SignHMAC([]byte(db), secret)+"\n")
+
+	c, err := LoadVerified(main, keys)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	if v, _ := c.Value("db:port"); "5432" != v || main != c.Source() {
+		dbg.Error("LoadVerified: %q", v)
+		t.Fail()
+	}
+
+	// tampering with the main file or an include
+	write("main.cfg", "host := evil\n@include db.cfg\n")
+	if _, err = LoadVerified(main, keys); !errors.Is(err, ErrBadSignature) {
+		dbg.Error("LoadVerified tampered: %v", err)
+		t.Fail()
+	}
+	write("main.cfg", data)
+	write("db.cfg", "db (\n\tport := 1\n)\n"+SignatureDirective+SignHMAC([]byte(db), secret)+"\n")
+	if _, err = LoadVerified(main, keys); !errors.Is(err, ErrBadSignature) {
+		dbg.Error("LoadVerified tampered include: %v", err)
+		t.Fail()
+	}
+	write("db.cfg", db)
+	if _, err = LoadVerified(main, keys); !errors.Is(err, ErrUnsigned) {
+		dbg.Error("LoadVerified unsigned include: %v", err)
+		t.Fail()
+	}
+
+	// only the keys of the keyring are accepted
+	write("db.cfg", db+SignatureDirective+SignHMAC([]byte(db), []byte("other"))+"\n")
+	if _, err = LoadVerified(main, Keyring{PublicKeys: []ed25519.PublicKey{pub}}); !errors.Is(err, ErrBadSignature) {
+		dbg.Error("LoadVerified wrong key: %v", err)
+		t.Fail()
+	}
+}
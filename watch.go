@@ -0,0 +1,86 @@
+package cfg
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWatchInterval = time.Second
+)
+
+/*
+	Watches the config file, loading it again each time it changes and
+	 passing the new Config (or the error loading it) to f, until stop is
+	 called; see Options.Watch
+*/
+func Watch(flPath string, f func(*Config, error)) (stop func()) {
+	return Options{}.Watch(flPath, f)
+}
+
+/*
+	As Watch, using these options.  The file is checked every
+	 Options.WatchInterval (DefaultWatchInterval if 0) so it works on every
+	 file system; a change of its size or modification time, or its
+	 replacement by another file as SaveConfig does, loads it again.  A
+	 missing file passes the error once, and the file is loaded when it
+	 comes back.  Only the watched file is checked, not any it includes
+
+	f is called from the watching goroutine, never after stop returns, so
+	 it must not call stop itself
+*/
+func (o Options) Watch(flPath string, f func(*Config, error)) (stop func()) {
+	return o.poll(flPath, func(err error) {
+		if nil != err {
+			f(nil, err)
+		} else {
+			f(o.LoadConfig(flPath))
+		}
+	})
+}
+
+// ------------------------------------------------------------------------- //
+
+// poll calls changed each time the file changes, or with the error once it
+// can't be found, until stop is called
+func (o Options) poll(flPath string, changed func(err error)) (stop func()) {
+	interval := o.WatchInterval
+	if 0 == interval {
+		interval = DefaultWatchInterval
+	}
+	done, finished := make(chan struct{}), make(chan struct{})
+	last, lastErr := os.Stat(flPath)
+	go func() {
+		defer close(finished)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+			}
+			fi, err := os.Stat(flPath)
+			switch {
+			case nil != err:
+				if nil == lastErr {
+					changed(err)
+				}
+				last, lastErr = nil, err
+				continue
+			case nil == lastErr && os.SameFile(last, fi) && last.ModTime().Equal(fi.ModTime()) && last.Size() == fi.Size():
+				continue
+			}
+			last, lastErr = fi, nil
+			changed(nil)
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
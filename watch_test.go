@@ -0,0 +1,61 @@
+package cfg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "app.cfg")
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("port := 8080\n"), 0644), "WriteFile")
+
+	type result struct {
+		c   *Config
+		err error
+	}
+	results := make(chan result, 10)
+	stop := Options{WatchInterval: 10 * time.Millisecond}.Watch(flPath, func(c *Config, err error) {
+		results <- result{c, err}
+	})
+	next := func() result {
+		select {
+		case r := <-results:
+			return r
+		case <-time.After(5 * time.Second):
+			return result{nil, os.ErrDeadlineExceeded}
+		}
+	}
+
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("port := 9090\n\n"), 0644), "WriteFile")
+	if r := next(); nil != r.err || "9090" != r.c.ValueOr("port", "") {
+		dbg.Error("Watch change: %v", r.err)
+		t.Fail()
+	}
+	os.Remove(flPath)
+	if r := next(); !os.IsNotExist(r.err) {
+		dbg.Error("Watch remove: %v", r.err)
+		t.Fail()
+	}
+	c, _ := Parse("port := 80\n")
+	dbg.ChkErr(SaveConfig(flPath, c), "SaveConfig")
+	if r := next(); nil != r.err || "80" != r.c.ValueOr("port", "") {
+		dbg.Error("Watch replace: %v", r.err)
+		t.Fail()
+	}
+	stop()
+	stop()
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("port := 1\n"), 0644), "WriteFile")
+	time.Sleep(50 * time.Millisecond)
+	if 0 != len(results) {
+		dbg.Error("Watch after stop: %d results", len(results))
+		t.Fail()
+	}
+}
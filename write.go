@@ -0,0 +1,466 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	/*
+		An Encoder writes a Config in the cfg format, the zero value writing
+		 the entries in their original order as Config.WriteTo does
+	*/
+	Encoder struct {
+		// When set, the entries of each group and the keys of each dict
+		//  are sorted by label, e.g. with Alphabetical; the sort is stable
+		//  so repeated labels keep their order, and comments stay with the
+		//  entry they precede
+		Order func(a, b string) bool
+
+		// The indent of each level of group entries & section lines, ""
+		//  for a TAB; a space indent is read back by the indent detection
+		//  of the parser (or Options.IndentSpaces)
+		Indent string
+
+		// Items are written this many to a line, 0 for all on one line
+		ItemsPerLine int
+
+		// Items are always written comma separated ('label , {'), rather
+		//  than only when an item holds whitespace
+		CommaItems bool
+
+		// The ':=' of consecutive single line values in a group are lined
+		//  up, padding the shorter labels
+		AlignValues bool
+
+		// Values of the entries matching the Sensitive patterns of the
+		//  config (see Options.Sensitive) are written as Redacted
+		Redact bool
+
+		// Each data section is written after a '#sha256:' annotation of its
+		//  data (see Checksum), replacing any annotation the config kept
+		//  before it, so the parser can detect a corrupted or altered section
+		Checksums bool
+	}
+)
+
+const (
+	Redacted = "*****"
+)
+
+/*
+	Writes the config in the cfg format, TAB indenting the entries of each
+	 group; parsing the output gives the same tree of nodes.  Values that
+	 can't be written on a single line use the ':==' multi-line form (or
+	 quotes when parsed with Options.QuotedValues), and blocks holding a line
+	 that would end them early are written as a heredoc (or with the line
+	 escaped when parsed with Options.SectionEscapes)
+
+	A config parsed with Options.KeepComments has its comments and blank
+	 lines written back in place, so editing and saving a file only changes
+	 the edited entries
+*/
+func (c *Config) WriteTo(w io.Writer) (int64, error) {
+	return Encoder{}.Encode(w, c)
+}
+
+/*
+	Writes the config in the cfg format as WriteTo, with the entries in the
+	 order given by the encoder
+*/
+func (e Encoder) Encode(w io.Writer, c *Config) (int64, error) {
+	return writeLines(w, c.encode(e, &c.root))
+}
+
+/*
+	Writes just the entry at the label path in the cfg format as WriteTo,
+	 e.g. a "tls ( ... )" group with all of its entries, to show or export
+	 part of a config.  A nested entry is written as if at the top level,
+	 its lines indented as it needs on its own, so the fragment parses as a
+	 config of that one entry ("" writing the whole config); a missing path
+	 gives a *PathError wrapping ErrNoSuchLabel
+*/
+func (c *Config) WriteSection(w io.Writer, path string) (int64, error) {
+	return Encoder{}.EncodeSection(w, c, path)
+}
+
+/*
+	As WriteSection, with the encoder's options
+*/
+func (e Encoder) EncodeSection(w io.Writer, c *Config, path string) (int64, error) {
+	if "" == path {
+		return e.Encode(w, c)
+	}
+	n := c.node(c.opts.internalPath(path))
+	if nil == n {
+		return 0, &PathError{path, ErrNoSuchLabel}
+	}
+	return writeLines(w, c.encode(e, &Node{Type: ConfigGroup, Children: []*Node{n}}))
+}
+
+/*
+	Returns the config in the cfg format with the values of any sensitive
+	 entries redacted, for logging and debug dumps; see Options.Sensitive
+*/
+func (c *Config) String() string {
+	var buf bytes.Buffer
+	Encoder{Redact: true}.Encode(&buf, c)
+	return buf.String()
+}
+
+/*
+	Adds label path patterns to the Sensitive ones of the config, e.g. for a
+	 config created with NewBuilder or FromMap
+*/
+func (c *Config) MarkSensitive(patterns ...string) {
+	c.opts.Sensitive = append(c.opts.Sensitive[:len(c.opts.Sensitive):len(c.opts.Sensitive)], patterns...)
+}
+
+/*
+	Orders labels alphabetically, for use as Encoder.Order
+*/
+func Alphabetical(a, b string) bool {
+	return a < b
+}
+
+// ------------------------------------------------------------------------- //
+
+// encode returns the lines for the entries of the group node
+func (c *Config) encode(e Encoder, g *Node) []string {
+	lines, run := []string{}, []int{}
+	for _, n := range e.sorted(g.Children) {
+		nl := c.encodeNode(e, n)
+		if e.Checksums && ConfigGroup != n.Type && ConfigValue != n.Type && ConfigComment != n.Type {
+			if k := len(lines) - 1; k >= 0 && "" != checksumAnnotation(lines[k]) {
+				lines = lines[:k]
+			}
+			lines = append(lines, c.checksumLine(e, n))
+		}
+		if e.AlignValues && ConfigValue == n.Type && 1 == len(nl) && strings.HasPrefix(nl[0], n.Label+" :=") {
+			run = append(run, len(lines))
+		} else {
+			alignValues(lines, run)
+			run = run[:0]
+		}
+		lines = append(lines, nl...)
+	}
+	alignValues(lines, run)
+	return lines
+}
+
+// alignValues pads the labels of the value lines (at the indices given) to
+// line up their ':='
+func alignValues(lines []string, run []int) {
+	width := 0
+	for _, i := range run {
+		if j := strings.Index(lines[i], " :="); j > width {
+			width = j
+		}
+	}
+	for _, i := range run {
+		j := strings.Index(lines[i], " :=")
+		lines[i] = lines[i][:j] + strings.Repeat(" ", width-j) + lines[i][j:]
+	}
+}
+
+// sorted returns the nodes in the encoder order, each keeping the comments
+// that precede it; comments after the last entry stay at the end
+func (e Encoder) sorted(nodes []*Node) []*Node {
+	if nil == e.Order {
+		return nodes
+	}
+	units, unit := [][]*Node{}, []*Node{}
+	for _, n := range nodes {
+		if unit = append(unit, n); ConfigComment != n.Type {
+			units, unit = append(units, unit), []*Node{}
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool {
+		return e.Order(units[i][len(units[i])-1].Label, units[j][len(units[j])-1].Label)
+	})
+	result := []*Node{}
+	for _, u := range units {
+		result = append(result, u...)
+	}
+	return append(result, unit...)
+}
+
+func (c *Config) encodeNode(e Encoder, n *Node) []string {
+	label := n.Label
+	if attrs := encodeAttributes(n.Attrs); "" != attrs && ConfigValue != n.Type && ConfigComment != n.Type {
+		lines := c.encodeNode(e, &Node{Type: n.Type, Label: n.Label, Path: n.Path, Data: n.Data, Children: n.Children})
+		switch {
+		case ConfigGroup == n.Type && 0 == len(n.Children):
+			// an inline group can't have attributes
+			return []string{label + attrs + " (", "", ")"}
+		case !strings.HasPrefix(lines[0], label+" <<"):
+			// a heredoc can't have attributes
+			lines[0] = label + attrs + lines[0][len(label):]
+		}
+		return lines
+	}
+	if e.Redact && ConfigGroup != n.Type && ConfigComment != n.Type {
+		n = c.redacted(n)
+	}
+	switch n.Type {
+	case ConfigComment:
+		return n.Data
+	case ConfigGroup:
+		if 0 == len(n.Children) {
+			return []string{label + " ( )"}
+		}
+		return append(append([]string{label + " ("}, e.indentLines(c.encode(e, n))...), ")")
+	case ConfigValue:
+		if _, ok := c.encrypted[n]; ok {
+			text, err := c.seal(n, n.Data[0])
+			if nil != err {
+				text = Redacted
+			}
+			return []string{label + " := " + text}
+		}
+		return c.encodeValue(label, n.Data[0])
+	case ConfigBlock:
+		if lines := escapeBlock(n.Data[0]); c.opts.SectionEscapes && nil != lines {
+			return append(append([]string{label + " <"}, lines...), ">")
+		}
+		return encodeBlock(label, n.Data[0])
+	case ConfigLines:
+		lines := n.Data
+		if c.opts.SectionEscapes {
+			lines = c.escapeLines(n.Data)
+		}
+		return e.fence(label+" [", padded(lines), "]")
+	case ConfigTable:
+		return e.fence(label+" [table", padded(n.Data), "]")
+	case ConfigItems:
+		open, sep := label+" {", " "
+		comment := func(i string) bool { return c.opts.ListComments && c.opts.IsComment(i) }
+		items := n.Data
+		if c.opts.QuotedItems {
+			items = make([]string, len(n.Data))
+			for x, i := range n.Data {
+				if items[x] = i; !comment(i) {
+					items[x] = c.encodeItem(i)
+				}
+			}
+		}
+		for _, i := range items {
+			if !comment(i) && (e.CommaItems || strings.ContainsAny(i, " \t")) {
+				open, sep = label+" , {", ", "
+			}
+		}
+		// kept comments are rows of their own
+		rows, row := []string{}, []string{}
+		for _, i := range items {
+			if comment(i) {
+				if 0 != len(row) {
+					rows, row = append(rows, strings.Join(row, sep)), nil
+				}
+				rows = append(rows, i)
+				continue
+			}
+			if row = append(row, i); len(row) == e.ItemsPerLine {
+				rows, row = append(rows, strings.Join(row, sep)), nil
+			}
+		}
+		if 0 != len(row) {
+			rows = append(rows, strings.Join(row, sep))
+		}
+		return e.fence(open, padded(rows), "}")
+	case ConfigDict:
+		keys, pairs := []int{}, []string{}
+		for i := 0; i+1 < len(n.Data); i += 2 {
+			keys = append(keys, i)
+		}
+		if nil != e.Order {
+			sort.SliceStable(keys, func(i, j int) bool { return e.Order(n.Data[keys[i]], n.Data[keys[j]]) })
+		}
+		for _, i := range keys {
+			pairs = append(pairs, n.Data[i]+" : "+n.Data[i+1])
+		}
+		return e.fence(label+" : [", padded(pairs), "]")
+	case ConfigBinary:
+		enc, rows := base64.StdEncoding.EncodeToString([]byte(n.Data[0])), []string{}
+		for ; len(enc) > 76; enc = enc[76:] {
+			rows = append(rows, enc[:76])
+		}
+		return e.fence(label+" <b64", append(rows, enc), ">")
+	}
+	sectionLock.RLock()
+	defer sectionLock.RUnlock()
+	for _, s := range sections {
+		if s.t == n.Type {
+			return append(append([]string{label + " " + s.open}, n.Data...), s.close)
+		}
+	}
+	return nil
+}
+
+// redacted returns a copy of the node with the data of any sensitive entry
+// (or dict key) replaced, the node itself when nothing is sensitive
+func (c *Config) redacted(n *Node) *Node {
+	if 0 == len(c.opts.Sensitive) {
+		return n
+	}
+	r := *n
+	r.Data = append([]string(nil), n.Data...)
+	redact := c.sensitive(n.Path)
+	if redact && ConfigBinary == n.Type {
+		r.Type = ConfigBlock
+	}
+	for i := range r.Data {
+		if ConfigDict == n.Type && (0 != i%2 || i+1 == len(r.Data)) {
+			continue
+		}
+		switch {
+		case ConfigDict == n.Type && (redact || c.sensitive(n.Path+":"+r.Data[i])):
+			r.Data[i+1] = Redacted
+		case ConfigDict != n.Type && redact:
+			r.Data[i] = Redacted
+		}
+	}
+	return &r
+}
+
+// sensitive reports whether the label path, or the path of any of its
+// groups, matches one of the Sensitive patterns
+func (c *Config) sensitive(lp string) bool {
+	elems := strings.Split(lp, ":")
+	for _, p := range c.opts.Sensitive {
+		pat := strings.Split(p, ":")
+		if len(pat) > len(elems) {
+			continue
+		}
+		matched := true
+		for i, pe := range pat {
+			if ok, _ := path.Match(pe, elems[i]); !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeItem quotes an item that wouldn't survive the round trip as it is,
+// one that's empty, padded, starts with a quote or holds a comma
+func (c *Config) encodeItem(i string) string {
+	if "" != i && strings.TrimSpace(i) == i && !strings.ContainsAny(i[:1], "\"'") && !strings.Contains(i, ",") {
+		return i
+	}
+	if c.opts.NoEscapes && !strings.Contains(i, "'") {
+		return "'" + i + "'"
+	}
+	return strconv.Quote(i)
+}
+
+// encodeValue writes a value on a single line when it survives the round
+// trip, otherwise quoted or as a ':==' multi-line value
+func (c *Config) encodeValue(label, v string) []string {
+	if c.opts.Interpolate {
+		v = strings.ReplaceAll(v, "${", "$${")
+	}
+	quoted := "" != v && (strings.ContainsAny(v[:1], "\"'") || strings.ContainsAny(v[len(v)-1:], "\"'"))
+	padded := strings.TrimSpace(v) != v
+	if !strings.Contains(v, "\n") && c.opts.QuotedValues && (quoted || padded) {
+		if c.opts.NoEscapes {
+			return []string{label + " := '" + v + "'"}
+		}
+		return []string{label + " := " + strconv.Quote(v)}
+	}
+	if strings.Contains(v, "\n") || padded || (c.opts.LineContinuation && strings.HasSuffix(v, "\\")) {
+		return append(append([]string{label + " :=="}, indentLines(strings.Split(v, "\n"))...), ":==")
+	}
+	if "" == v {
+		return []string{label + " :="}
+	}
+	return []string{label + " := " + v}
+}
+
+// encodeBlock writes a block, using a heredoc when the text holds a line
+// that would end the block early, or is empty
+func encodeBlock(label, text string) []string {
+	lines := strings.Split(text, "\n")
+	heredoc := "" == text
+	for _, l := range lines {
+		if 1 == len(l) && strings.Contains(">]})", l) {
+			heredoc = true
+		}
+	}
+	if !heredoc {
+		return append(append([]string{label + " <"}, lines...), ">")
+	}
+	tag := "EOF"
+	for i := 1; hasLine(lines, tag); i++ {
+		tag = "EOF" + strconv.Itoa(i)
+	}
+	return append(append([]string{label + " <<" + tag}, lines...), tag)
+}
+
+func hasLine(lines []string, tag string) bool {
+	for _, l := range lines {
+		if tag == strings.TrimRight(l, " \t") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeLines writes each line followed by a newline
+func writeLines(w io.Writer, lines []string) (int64, error) {
+	var buf bytes.Buffer
+	for _, l := range lines {
+		buf.WriteString(l)
+		buf.WriteByte('\n')
+	}
+	return buf.WriteTo(w)
+}
+
+// fence returns the indented lines between the opening and closing lines
+func (e Encoder) fence(open string, lines []string, close string) []string {
+	return append(append([]string{open}, e.indentLines(lines)...), close)
+}
+
+// padded returns the lines of a section, a single blank one when empty as
+// a section needs a line between its opener & closer
+func padded(lines []string) []string {
+	if 0 == len(lines) {
+		return []string{""}
+	}
+	return lines
+}
+
+// indentLines adds the encoder indent to each non-empty line
+func (e Encoder) indentLines(lines []string) []string {
+	if "" == e.Indent || "\t" == e.Indent {
+		return indentLines(lines)
+	}
+	result := make([]string, len(lines))
+	for i, l := range lines {
+		if "" != l {
+			l = e.Indent + l
+		}
+		result[i] = l
+	}
+	return result
+}
+
+// indentLines adds a TAB to each non-empty line
+func indentLines(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, l := range lines {
+		if "" != l {
+			l = "\t" + l
+		}
+		result[i] = l
+	}
+	return result
+}
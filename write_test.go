@@ -0,0 +1,353 @@
+package cfg
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+const (
+	writeTests = `
+name := value
+empty :=
+padded :==
+	  two spaces
+:==
+motd :==
+	line 1
+
+	line 3
+:==
+blk <
+some text
+>
+closer <<END
+a
+>
+END
+nothing <<EOF
+EOF
+grp (
+	sub (
+		deep := yes
+		lines [
+			one line
+			two
+		]
+	)
+	void ( )
+	items {
+		a b c
+	}
+	spaced , {
+		a b, c
+	}
+	dict : [
+		k : v w
+	]
+	seed <hex
+		00 01 02 ff
+	>
+	routes [table
+		a b
+		1 2
+	]
+)
+`
+)
+
+func TestWriteTo(t *testing.T) {
+	for _, o := range []Options{{}, {QuotedValues: true}, {Interpolate: true}} {
+		c, err := o.Parse(writeTests + "q := \" 'x' \"\nref := $${literal}\n")
+		if nil != err {
+			dbg.Error(err.Error())
+			t.FailNow()
+		}
+		var buf bytes.Buffer
+		n, err := c.WriteTo(&buf)
+		if nil != err || int64(buf.Len()) != n {
+			dbg.Error("WriteTo: %d %v", n, err)
+			t.Fail()
+		}
+		c2, err := o.Parse(buf.String())
+		if nil != err {
+			dbg.Error(err.Error())
+			t.FailNow()
+		}
+		if !reflect.DeepEqual(c.FlattenTyped(), c2.FlattenTyped()) {
+			dbg.Error("round trip %+v:\n%s\n%v\n%v", o, buf.String(), c.FlattenTyped(), c2.FlattenTyped())
+			t.Fail()
+		}
+	}
+
+	c, err := LoadConfig("testdata/testBlocks.cfg")
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	var buf bytes.Buffer
+	c.WriteTo(&buf)
+	if c2, _ := Parse(buf.String()); nil == c2 || !reflect.DeepEqual(c.FlattenTyped(), c2.FlattenTyped()) {
+		dbg.Error("testBlocks.cfg round trip:\n%s", buf.String())
+		t.Fail()
+	}
+}
+
+func TestWriteEmptySections(t *testing.T) {
+	for _, src := range []string{"l [\n\n]\n", "t [table\n\n]\n", "d : [\n\n]\n", "i {\n\n}\n"} {
+		for _, o := range []Options{{}, {SectionEscapes: true}} {
+			c, err := o.Parse(src + "x := 1\n")
+			if nil != err || 2 != len(flatNodes(c))/3 {
+				dbg.Error("Parse %q: %v", src, err)
+				t.Fail()
+				continue
+			}
+			var buf bytes.Buffer
+			c.WriteTo(&buf)
+			if c2, err := o.Parse(buf.String()); nil != err || !reflect.DeepEqual(flatNodes(c), flatNodes(c2)) {
+				dbg.Error("Empty section round trip %+v: %v\n%s", o, err, buf.String())
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestKeepComments(t *testing.T) {
+	src := `# application settings
+name := app
+
+# the database
+db (
+	# primary server
+	host := localhost
+
+	pool := 4
+	# trailing group comment
+)
+
+lines [
+	one
+]
+# end of file
+`
+	c, err := Options{KeepComments: true}.Parse(src)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	var buf bytes.Buffer
+	c.WriteTo(&buf)
+	if src != buf.String() {
+		dbg.Error("round trip:\n%s", buf.String())
+		t.Fail()
+	}
+	plain, _ := Parse(src)
+	if !reflect.DeepEqual(plain.Flatten(), c.Flatten()) {
+		dbg.Error("comments changed the entries: %v", c.Flatten())
+		t.Fail()
+	}
+	got := 0
+	Options{KeepComments: true}.HandleConfigData(src, func(ct ConfigType, label string, data []string) {
+		got++
+	})
+	if 4 != got {
+		dbg.Error("HandleConfigData passed %d entries", got)
+		t.Fail()
+	}
+}
+
+func TestEncoderOrder(t *testing.T) {
+	src := `zeta := 1
+# about alpha
+alpha := 2
+grp (
+	b := x
+	a := y
+	b := z
+)
+d : [
+	y : 1
+	x : 2
+]
+`
+	want := `# about alpha
+alpha := 2
+d : [
+	x : 2
+	y : 1
+]
+grp (
+	a := y
+	b := x
+	b := z
+)
+zeta := 1
+`
+	c, err := Options{KeepComments: true}.Parse(src)
+	dbg.ChkErr(err, "Parse")
+	var buf bytes.Buffer
+	Encoder{Order: Alphabetical}.Encode(&buf, c)
+	if want != buf.String() {
+		dbg.Error("sorted:\n%s", buf.String())
+		t.Fail()
+	}
+	buf.Reset()
+	Encoder{}.Encode(&buf, c)
+	if src != buf.String() {
+		dbg.Error("original order:\n%s", buf.String())
+		t.Fail()
+	}
+
+	m := map[string]string{"z": "1", "db:port": "80", "db:host": "x", "a": "2"}
+	for i := 0; i < 5; i++ {
+		c, err := FromMap(m)
+		dbg.ChkErr(err, "FromMap")
+		buf.Reset()
+		c.WriteTo(&buf)
+		if "a := 2\ndb (\n\thost := x\n\tport := 80\n)\nz := 1\n" != buf.String() {
+			dbg.Error("FromMap:\n%s", buf.String())
+			t.Fail()
+		}
+		if !reflect.DeepEqual(m, c.Flatten()) {
+			dbg.Error("FromMap flattens to %v", c.Flatten())
+			t.Fail()
+		}
+	}
+	if _, err := FromMap(map[string]string{"a": "1", "a:b": "2"}); !errors.Is(err, ErrWrongType) {
+		dbg.Error("FromMap value & group: %v", err)
+		t.Fail()
+	}
+}
+
+func TestEncoderStyle(t *testing.T) {
+	src := `name := app
+longer_name := 2
+multi :==
+	a
+	b
+:==
+x := 3
+grp (
+	ab := 1
+	a := 2
+	ports {
+		80 443 8080
+	}
+)
+`
+	want := `name        := app
+longer_name := 2
+multi :==
+	a
+	b
+:==
+x := 3
+grp (
+  ab := 1
+  a  := 2
+  ports , {
+    80, 443
+    8080
+  }
+)
+`
+	c, err := Parse(src)
+	dbg.ChkErr(err, "Parse")
+	var buf bytes.Buffer
+	Encoder{Indent: "  ", ItemsPerLine: 2, CommaItems: true, AlignValues: true}.Encode(&buf, c)
+	if want != buf.String() {
+		dbg.Error("styled:\n%s", buf.String())
+		t.Fail()
+	}
+	again, err := Parse(buf.String())
+	if nil != err || !reflect.DeepEqual(c.FlattenTyped(), again.FlattenTyped()) {
+		dbg.Error("styled output parses differently: %v", err)
+		t.Fail()
+	}
+}
+
+func TestRedact(t *testing.T) {
+	src := `user := admin
+db (
+	password := secret
+	host := x
+)
+auth : [
+	token : abc
+	realm : app
+]
+keys (
+	list {
+		k1 k2
+	}
+)
+`
+	want := `user := admin
+db (
+	password := *****
+	host := x
+)
+auth : [
+	token : *****
+	realm : app
+]
+keys (
+	list {
+		***** *****
+	}
+)
+`
+	c, err := Options{Sensitive: []string{"*:password", "auth:token"}}.Parse(src)
+	dbg.ChkErr(err, "Parse")
+	c.MarkSensitive("keys")
+	if want != c.String() {
+		dbg.Error("redacted:\n%s", c.String())
+		t.Fail()
+	}
+	if v, _ := c.Value("db:password"); "secret" != v {
+		dbg.Error("redacted value: %q", v)
+		t.Fail()
+	}
+	var buf bytes.Buffer
+	c.WriteTo(&buf)
+	if src != buf.String() {
+		dbg.Error("WriteTo redacted:\n%s", buf.String())
+		t.Fail()
+	}
+}
+
+func TestWriteSection(t *testing.T) {
+	src := "name := app\nserver (\n\ttls (\n\t\tcert := a.pem\n\t\tciphers { x y }\n\t\tnested (\n\t\t\tdeep := 1\n\t\t)\n\t)\n\tport := 443\n)\n"
+	c, err := Parse(src)
+	if nil != err {
+		dbg.Error("Parse for WriteSection: %v", err)
+		t.FailNow()
+	}
+	var buf bytes.Buffer
+	if _, err = c.WriteSection(&buf, "server:tls"); nil != err {
+		dbg.Error("WriteSection: %v", err)
+		t.FailNow()
+	}
+	want := "tls (\n\tcert := a.pem\n\tciphers {\n\t\tx y\n\t}\n\tnested (\n\t\tdeep := 1\n\t)\n)\n"
+	if want != buf.String() {
+		dbg.Error("WriteSection:\n%s", buf.String())
+		t.Fail()
+	}
+	if f, err := Parse(buf.String()); nil != err || "1" != f.ValueOr("tls:nested:deep", "") {
+		dbg.Error("Parse the written section: %v", err)
+		t.Fail()
+	}
+
+	buf.Reset()
+	if _, err = (Encoder{Indent: "  "}).EncodeSection(&buf, c, "server:port"); nil != err || "port := 443\n" != buf.String() {
+		dbg.Error("EncodeSection value: %q %v", buf.String(), err)
+		t.Fail()
+	}
+	if _, err = c.WriteSection(&buf, "server:nope"); !errors.Is(err, ErrNoSuchLabel) {
+		dbg.Error("WriteSection missing: %v", err)
+		t.Fail()
+	}
+}
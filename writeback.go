@@ -0,0 +1,154 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"time"
+)
+
+type (
+	// valueEdit is a value given to Reloader.Set, waiting to be written
+	valueEdit struct {
+		path, value string
+	}
+)
+
+const (
+	DefaultWriteDelay = 500 * time.Millisecond
+)
+
+var (
+	ErrReloaderClosed = errors.New("Reloader is closed")
+)
+
+/*
+	Sets the value of the ConfigValue or ConfigBlock at the label path,
+	 so an application can persist a setting changed by its user through
+	 the Reloader that loaded it:
+
+		err := r.Set("ui:theme", "dark")
+
+	The current config is replaced at once by a copy holding the value
+	 (once it passes the validate function) and the subscribers are called
+	 as for a reload; the value is written to the file in place, as by
+	 SetValueInFile, once no other Set has been made for Options.WriteDelay
+	 (or DefaultWriteDelay), so a number of quick changes give a single
+	 atomic write.  A value read from @enc(id:data) is written encrypted
+	 with the same key (see Options.Keys).  A failed write is passed to the
+	 OnError functions and returned by Close, see Flush.  The label path must exist, otherwise a
+	 *PathError is returned
+*/
+func (r *Reloader) Set(path, value string) error {
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+	r.mu.Lock()
+	closed, subs, values := r.closed, r.subs, r.values
+	r.mu.Unlock()
+	if closed {
+		return ErrReloaderClosed
+	}
+	old := r.Config()
+	n := old.node(old.opts.internalPath(path))
+	if nil == n {
+		return &PathError{path, ErrNoSuchLabel}
+	}
+	if ConfigValue != n.Type && ConfigBlock != n.Type {
+		return &PathError{path, ErrWrongType}
+	}
+	// a value decrypted is written encrypted with the same key
+	text, err := old.seal(n, value)
+	if nil != err {
+		return err
+	}
+	c := old.Clone()
+	c.node(c.opts.internalPath(path)).Data = []string{value}
+	if nil != r.validate {
+		if err := r.validate(c); nil != err {
+			return err
+		}
+	}
+	// the file is edited by the label path as written there
+	r.queue(old.opts.internalPath(path), text)
+	changes := Diff(old, c)
+	if 0 == len(changes) {
+		return nil
+	}
+	r.current.Store(c)
+	for _, f := range subs {
+		f(old, c, changes)
+	}
+	for _, v := range values {
+		v.send(c)
+	}
+	return nil
+}
+
+/*
+	Writes the values given to Set and not yet written to the file now,
+	 rather than waiting for Options.WriteDelay.  The values are dropped
+	 if the write fails (e.g. for a label path given by an included file),
+	 the current config keeping them until the file is next reloaded
+*/
+func (r *Reloader) Flush() error {
+	r.writing.Lock()
+	defer r.writing.Unlock()
+	r.mu.Lock()
+	edits := r.edits
+	if nil != r.writer {
+		r.writer.Stop()
+	}
+	r.mu.Unlock()
+	if 0 == len(edits) {
+		return nil
+	}
+	src, err := ioutil.ReadFile(r.flPath)
+	for _, e := range edits {
+		if nil == err {
+			src, err = SetValue(src, e.path, e.value)
+		}
+	}
+	if nil == err {
+		err = writeFile(r.flPath, src)
+	}
+	r.mu.Lock()
+	r.edits = r.edits[len(edits):]
+	r.mu.Unlock()
+	// reload what was written, along with any other change to the file
+	// skipped while the values were waiting
+	r.changed()
+	return err
+}
+
+// ------------------------------------------------------------------------- //
+
+// queue adds the value to those waiting to be written, (re)starting the
+// delay before writing them
+func (r *Reloader) queue(path, value string) {
+	delay := r.opts.WriteDelay
+	if 0 == delay {
+		delay = DefaultWriteDelay
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.edits = append(r.edits, valueEdit{path, value})
+	if nil == r.writer {
+		r.writer = time.AfterFunc(delay, r.writeBack)
+	} else {
+		r.writer.Reset(delay)
+	}
+}
+
+// writeBack is the end of the write delay, passing a failed write to the
+// OnError functions
+func (r *Reloader) writeBack() {
+	err := r.Flush()
+	if nil == err {
+		return
+	}
+	r.mu.Lock()
+	errs := r.errs
+	r.mu.Unlock()
+	for _, f := range errs {
+		f(err)
+	}
+}
@@ -0,0 +1,163 @@
+package cfg
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestReloaderSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "app.cfg")
+	src := "# user settings\nui (\n\ttheme := light\n\tsize := 12\n)\nitems { a b }\n"
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte(src), 0644), "WriteFile")
+
+	errSize := errors.New("bad size")
+	validate := func(c *Config) error {
+		if n, err := c.GetInt("ui:size"); nil != err || n < 6 {
+			return errSize
+		}
+		return nil
+	}
+	r, err := Options{WatchInterval: 5 * time.Millisecond, ReloadDelay: 20 * time.Millisecond, WriteDelay: 50 * time.Millisecond}.NewReloader(flPath, validate)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	changed := make(chan []string, 10)
+	r.Subscribe(func(c *Config, paths []string) {
+		changed <- paths
+	})
+
+	// the config changes at once, the file once the writes stop
+	old := r.Config()
+	for _, theme := range []string{"dark", "solar", "dark"} {
+		if err := r.Set("ui:theme", theme); nil != err {
+			dbg.Error("Set: %v", err)
+			t.Fail()
+		}
+	}
+	if "dark" != r.Config().ValueOr("ui:theme", "") || "light" != old.ValueOr("ui:theme", "") {
+		dbg.Error("Set didn't replace the config: %s", r.Config().ValueOr("ui:theme", ""))
+		t.Fail()
+	}
+	if 3 != len(changed) || !reflect.DeepEqual([]string{"ui:theme"}, <-changed) {
+		dbg.Error("Set subscribers called %d times", len(changed))
+		t.Fail()
+	}
+	if data, _ := ioutil.ReadFile(flPath); src != string(data) {
+		dbg.Error("Set wrote before the delay: %q", data)
+		t.Fail()
+	}
+	time.Sleep(300 * time.Millisecond)
+	want := "# user settings\nui (\n\ttheme := dark\n\tsize := 12\n)\nitems { a b }\n"
+	if data, _ := ioutil.ReadFile(flPath); want != string(data) {
+		dbg.Error("Set wrote: %q", data)
+		t.Fail()
+	}
+	// the reload of the written file changes nothing
+	for len(changed) > 0 {
+		<-changed
+	}
+	time.Sleep(100 * time.Millisecond)
+	if 0 != len(changed) || "dark" != r.Config().ValueOr("ui:theme", "") {
+		dbg.Error("Set write-back reloaded: %v", <-changed)
+		t.Fail()
+	}
+
+	// bad label paths and values
+	var pe *PathError
+	if err := r.Set("ui:missing", "x"); !errors.As(err, &pe) || ErrNoSuchLabel != pe.Err {
+		dbg.Error("Set of a missing label: %v", err)
+		t.Fail()
+	}
+	if err := r.Set("items", "x"); !errors.As(err, &pe) || ErrWrongType != pe.Err {
+		dbg.Error("Set of items: %v", err)
+		t.Fail()
+	}
+	if err := r.Set("ui:size", "2"); errSize != err || "12" != r.Config().ValueOr("ui:size", "") {
+		dbg.Error("Set of an invalid value: %v", err)
+		t.Fail()
+	}
+
+	// Close writes what's waiting
+	dbg.ChkErr(r.Set("ui:size", "14"), "Set")
+	r.Close()
+	want = "# user settings\nui (\n\ttheme := dark\n\tsize := 14\n)\nitems { a b }\n"
+	if data, _ := ioutil.ReadFile(flPath); want != string(data) {
+		dbg.Error("Close didn't write: %q", data)
+		t.Fail()
+	}
+	if err := r.Set("ui:size", "16"); ErrReloaderClosed != err {
+		dbg.Error("Set after Close: %v", err)
+		t.Fail()
+	}
+}
+
+func TestReloaderSetEncrypted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "app.cfg")
+	keys := StaticKeys{"k1": []byte("0123456789abcdef0123456789abcdef")}
+	enc, _ := EncryptValue(keys, "k1", "s3cret")
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("password := "+enc+"\n"), 0644), "WriteFile")
+
+	o := Options{Keys: keys, WatchInterval: 5 * time.Millisecond, WriteDelay: time.Hour}
+	r, err := o.NewReloader(flPath, nil)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	dbg.ChkErr(r.Set("password", "n3w"), "Set")
+	r.Close()
+	data, _ := ioutil.ReadFile(flPath)
+	c, err := o.Parse(string(data))
+	if nil != err || !strings.HasPrefix(string(data), "password := @enc(k1:") || strings.Contains(string(data), "n3w") ||
+		"n3w" != c.ValueOr("password", "") {
+		dbg.Error("Set of an @enc value wrote %q: %v", data, err)
+		t.Fail()
+	}
+}
+
+func TestReloaderSetPathSeparator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	dbg.ChkErr(err, "TempDir")
+	defer os.RemoveAll(dir)
+	flPath := filepath.Join(dir, "app.cfg")
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("ui (\n\ttheme := light\n)\n"), 0644), "WriteFile")
+
+	o := Options{PathSeparator: '.', WriteDelay: time.Hour}
+	r, err := o.NewReloader(flPath, nil)
+	if nil != err {
+		dbg.Error(err.Error())
+		t.FailNow()
+	}
+	dbg.ChkErr(r.Set("ui.theme", "dark"), "Set")
+	if err := r.Flush(); nil != err {
+		dbg.Error("Flush with a PathSeparator: %v", err)
+		t.Fail()
+	}
+	if data, _ := ioutil.ReadFile(flPath); "ui (\n\ttheme := dark\n)\n" != string(data) {
+		dbg.Error("Set with a PathSeparator wrote %q", data)
+		t.Fail()
+	}
+
+	// a value that can't be written is an error of Close
+	dbg.ChkErr(r.Set("ui.theme", "solar"), "Set")
+	dbg.ChkErr(ioutil.WriteFile(flPath, []byte("other := 1\n"), 0644), "WriteFile")
+	var pe *PathError
+	if err := r.Close(); !errors.As(err, &pe) || ErrNoSuchLabel != pe.Err {
+		dbg.Error("Close of a failed write: %v", err)
+		t.Fail()
+	}
+}
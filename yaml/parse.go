@@ -0,0 +1,701 @@
+package yaml
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jayacarlson/cfg"
+	"github.com/jayacarlson/cfg/internal/tree"
+)
+
+type (
+	/*
+		A SyntaxError records YAML that can't be read along with its line
+		 number in the document
+	*/
+	SyntaxError struct {
+		Line int
+		Text string
+		Err  error
+	}
+
+	parser struct {
+		lines   []string
+		i       int // the next line to be read
+		anchors map[string]interface{}
+	}
+
+	// the position in the text of a flow collection, e.g. [a, {b: c}]
+	flow struct {
+		p   *parser
+		s   string
+		pos int
+	}
+)
+
+var (
+	ErrSyntax       = errors.New("Invalid or unsupported YAML")
+	ErrUnknownAlias = errors.New("YAML alias of an unknown anchor")
+	ErrNotMapping   = errors.New("YAML document is not a mapping")
+	ErrBinary       = errors.New("Invalid !!binary YAML data")
+
+	// a sequence holding a mix of mappings, sequences and values
+	ErrMixedSequence = tree.ErrMixedArray
+)
+
+func (e *SyntaxError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + " \"" + e.Text + "\": " + e.Err.Error()
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	Converts the first document of the YAML data into a Config, which must
+	 be a mapping:
+
+		mapping                     a ( ) group
+		scalar                      a := value, or a < > block if multi-line
+		null, ~ or no value         an empty := value
+		!!binary scalar             a <b64 block
+		sequence of scalars         { } items
+		sequence of mappings        a repeated group
+		sequence of sequences       repeated { } items
+
+	Scalars keep their text, so 8080 and true are the values "8080" and
+	 "true".  An alias (*name) is replaced by a copy of the value with that
+	 anchor (&name), and a '<<' merge key adds the entries of the aliased
+	 mapping(s) not given in the mapping itself.  Block and flow styles,
+	 quoted scalars and | or > block scalars are read; complex keys, tags
+	 other than !!binary and multiple documents are not
+*/
+func ToConfig(data []byte) (*cfg.Config, error) {
+	m, err := parse(data)
+	if nil != err {
+		return nil, err
+	}
+	return tree.Config(m)
+}
+
+// ------------------------------------------------------------------------- //
+
+// parse reads the first document of the YAML data
+func parse(data []byte) (*tree.Map, error) {
+	str := strings.TrimPrefix(string(data), "\ufeff")
+	str = strings.ReplaceAll(str, "\r\n", "\n")
+	p := &parser{lines: strings.Split(str, "\n"), anchors: make(map[string]interface{})}
+	// skip any directives and the start of the document, the document ends
+	// at the next start or end marker
+	start := 0
+	for j, l := range p.lines {
+		if t := strings.TrimSpace(l); strings.HasPrefix(l, "%") || "" == t || strings.HasPrefix(t, "#") {
+			continue
+		} else if "---" == t || strings.HasPrefix(l, "--- ") {
+			start = j + 1
+			if "---" != t {
+				p.lines[j] = strings.TrimPrefix(l, "--- ")
+				start = j
+			}
+		}
+		break
+	}
+	for j := start; j < len(p.lines); j++ {
+		if t := strings.TrimRight(p.lines[j], " \t"); "---" == t || "..." == t || strings.HasPrefix(t, "--- ") {
+			p.lines = p.lines[:j]
+			break
+		}
+	}
+	p.i = start
+	v, err := p.node(0)
+	if nil != err {
+		return nil, err
+	}
+	if j := p.skip(); j < len(p.lines) {
+		return nil, p.error(j, ErrSyntax)
+	}
+	switch v := v.(type) {
+	case *tree.Map:
+		return v, nil
+	case nil:
+		return tree.NewMap(), nil
+	}
+	return nil, p.error(start, ErrNotMapping)
+}
+
+func (p *parser) error(line int, err error) error {
+	if line >= len(p.lines) {
+		line = len(p.lines) - 1
+	}
+	return &SyntaxError{line + 1, strings.TrimSpace(p.lines[line]), err}
+}
+
+// skip returns the next line (from p.i) that isn't blank or a comment
+func (p *parser) skip() int {
+	j := p.i
+	for j < len(p.lines) {
+		if t := strings.TrimSpace(p.lines[j]); "" != t && !strings.HasPrefix(t, "#") {
+			break
+		}
+		j++
+	}
+	return j
+}
+
+// node reads the block node at the next line, if it's indented at least min
+func (p *parser) node(min int) (interface{}, error) {
+	j := p.skip()
+	if j >= len(p.lines) {
+		return nil, nil
+	}
+	ind := indentOf(p.lines[j])
+	if ind < min {
+		return nil, nil
+	}
+	if '\t' == p.lines[j][ind] {
+		return nil, p.error(j, ErrSyntax)
+	}
+	content := stripComment(p.lines[j][ind:])
+	if isSeqItem(content) {
+		return p.sequence(ind)
+	}
+	if _, _, ok := splitKey(content); ok {
+		return p.mapping(ind)
+	}
+	p.i = j + 1
+	rest, anchor, tag := props(content)
+	v, err := p.body(rest, ind-1, j)
+	if nil != err {
+		return nil, err
+	}
+	return p.finish(v, anchor, tag, j)
+}
+
+// sequence reads the '- item' lines at the indent
+func (p *parser) sequence(ind int) (interface{}, error) {
+	result := []interface{}{}
+	for {
+		j := p.skip()
+		if j >= len(p.lines) || ind != indentOf(p.lines[j]) {
+			break
+		}
+		content := stripComment(p.lines[j][ind:])
+		if !isSeqItem(content) {
+			break
+		}
+		rest := strings.TrimLeft(content[1:], " ")
+		col := ind + len(content) - len(rest)
+		rest, anchor, tag := props(rest)
+		var v interface{}
+		var err error
+		if _, _, ok := splitKey(rest); ok || isSeqItem(rest) {
+			// a mapping or sequence starting on the '-' line
+			p.lines[j] = strings.Repeat(" ", col) + rest
+			p.i = j
+			v, err = p.node(col)
+		} else {
+			p.i = j + 1
+			v, err = p.value(rest, ind, j, false)
+		}
+		if nil == err {
+			v, err = p.finish(v, anchor, tag, j)
+		}
+		if nil != err {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// mapping reads the 'key: value' lines at the indent
+func (p *parser) mapping(ind int) (interface{}, error) {
+	m, explicit := tree.NewMap(), make(map[string]bool)
+	for {
+		j := p.skip()
+		if j >= len(p.lines) || ind != indentOf(p.lines[j]) {
+			break
+		}
+		content := stripComment(p.lines[j][ind:])
+		key, rest, ok := splitKey(content)
+		if !ok {
+			if isSeqItem(content) {
+				break
+			}
+			return nil, p.error(j, ErrSyntax)
+		}
+		p.i = j + 1
+		rest, anchor, tag := props(rest)
+		v, err := p.value(rest, ind, j, true)
+		if nil == err {
+			v, err = p.finish(v, anchor, tag, j)
+		}
+		if nil != err {
+			return nil, err
+		}
+		if "<<" != key {
+			m.Set(key, v)
+			explicit[key] = true
+			continue
+		}
+		merges, ok := v.([]interface{})
+		if !ok {
+			merges = []interface{}{v}
+		}
+		for _, mv := range merges {
+			mm, ok := mv.(*tree.Map)
+			if !ok {
+				return nil, p.error(j, ErrSyntax)
+			}
+			for _, k := range mm.Keys {
+				if _, ok := m.Values[k]; !ok && !explicit[k] {
+					m.Set(k, mm.Values[k])
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// value reads the value following a 'key:' or '-' at the indent, from the
+// rest of the line j or the more indented lines after it; a mapping value
+// may also be a sequence at the same indent
+func (p *parser) value(rest string, ind, j int, inMap bool) (interface{}, error) {
+	if "" != rest {
+		return p.body(rest, ind, j)
+	}
+	k := p.skip()
+	if k >= len(p.lines) {
+		return nil, nil
+	}
+	switch next := indentOf(p.lines[k]); {
+	case next > ind:
+		return p.node(ind + 1)
+	case next == ind && inMap && isSeqItem(stripComment(p.lines[k][ind:])):
+		return p.sequence(ind)
+	}
+	return nil, nil
+}
+
+// body reads a value starting on line j, any following lines must be
+// indented more than parent
+func (p *parser) body(rest string, parent, j int) (interface{}, error) {
+	if "" == rest {
+		// an anchor or tag of a null
+		return nil, nil
+	}
+	switch rest[0] {
+	case '*':
+		v, ok := p.anchors[rest[1:]]
+		if !ok {
+			return nil, p.error(j, ErrUnknownAlias)
+		}
+		return v, nil
+	case '|', '>':
+		return p.blockScalar(rest, parent, j)
+	case '[', '{':
+		for !balanced(rest) && p.i < len(p.lines) {
+			rest += " " + stripComment(strings.TrimSpace(p.lines[p.i]))
+			p.i++
+		}
+		f := &flow{p: p, s: rest}
+		v, err := f.value()
+		if f.space(); nil == err && f.pos != len(f.s) {
+			err = ErrSyntax
+		}
+		if nil != err {
+			return nil, p.error(j, err)
+		}
+		return v, nil
+	case '"', '\'':
+		for {
+			s, n, err := quoted(rest)
+			if nil == err && n == len(rest) {
+				return s, nil
+			}
+			if nil == err || p.i >= len(p.lines) {
+				return nil, p.error(j, ErrSyntax)
+			}
+			rest += " " + strings.TrimSpace(p.lines[p.i])
+			p.i++
+		}
+	}
+	// a plain scalar, continued by any more indented lines
+	for p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if t := strings.TrimSpace(l); "" == t || strings.HasPrefix(t, "#") || indentOf(l) <= parent {
+			break
+		}
+		t := stripComment(strings.TrimSpace(l))
+		if _, _, ok := splitKey(t); ok || isSeqItem(t) {
+			return nil, p.error(p.i, ErrSyntax)
+		}
+		rest += " " + t
+		p.i++
+	}
+	return plain(rest), nil
+}
+
+// blockScalar reads the lines of a | or > block scalar
+func (p *parser) blockScalar(header string, parent, j int) (interface{}, error) {
+	chomp, indent := byte(0), 0
+	for _, c := range header[1:] {
+		switch {
+		case '-' == c || '+' == c:
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			indent = parent + 1 + int(c-'1')
+		case ' ' == c:
+		default:
+			return nil, p.error(j, ErrSyntax)
+		}
+	}
+	lines := []string{}
+	for ; p.i < len(p.lines); p.i++ {
+		l := p.lines[p.i]
+		if "" == strings.TrimSpace(l) {
+			lines = append(lines, "")
+			continue
+		}
+		if 0 == indent {
+			indent = indentOf(l)
+		}
+		if indentOf(l) < indent || indentOf(l) <= parent {
+			break
+		}
+		lines = append(lines, l[indent:])
+	}
+	trailing := 0
+	for ; len(lines) > 0 && "" == lines[len(lines)-1]; trailing++ {
+		lines = lines[:len(lines)-1]
+	}
+	var s string
+	if '|' == header[0] {
+		s = strings.Join(lines, "\n")
+	} else {
+		s = fold(lines)
+	}
+	switch {
+	case 0 == len(lines) || '-' == chomp:
+	case '+' == chomp:
+		s += strings.Repeat("\n", trailing+1)
+	default:
+		s += "\n"
+	}
+	return s, nil
+}
+
+// finish applies the tag and records the anchor of a value
+func (p *parser) finish(v interface{}, anchor, tag string, j int) (interface{}, error) {
+	if "!!binary" == tag {
+		s, _ := v.(string)
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+		if nil != err {
+			return nil, p.error(j, ErrBinary)
+		}
+		v = b
+	}
+	if "" != anchor {
+		p.anchors[anchor] = v
+	}
+	return v, nil
+}
+
+// value reads a flow node
+func (f *flow) value() (interface{}, error) {
+	f.space()
+	if f.pos >= len(f.s) {
+		return nil, ErrSyntax
+	}
+	anchor := ""
+	if '&' == f.s[f.pos] {
+		anchor = f.token()
+		f.space()
+	}
+	var v interface{}
+	var err error
+	if "" != anchor && (f.pos >= len(f.s) || strings.ContainsRune(",]}", rune(f.s[f.pos]))) {
+		// an anchored null
+		f.p.anchors[anchor] = nil
+		return nil, nil
+	}
+	switch c := f.s[f.pos]; c {
+	case '[':
+		v, err = f.sequence()
+	case '{':
+		v, err = f.mapping()
+	case '"', '\'':
+		var n int
+		v, n, err = quoted(f.s[f.pos:])
+		f.pos += n
+	case '*':
+		name := f.token()
+		var ok bool
+		if v, ok = f.p.anchors[name]; !ok {
+			err = ErrUnknownAlias
+		}
+	default:
+		v = plain(f.scalar(false))
+	}
+	if "" != anchor && nil == err {
+		f.p.anchors[anchor] = v
+	}
+	return v, err
+}
+
+func (f *flow) sequence() (interface{}, error) {
+	result := []interface{}{}
+	f.pos++
+	for f.space(); f.pos < len(f.s) && ']' != f.s[f.pos]; f.space() {
+		v, err := f.value()
+		if nil != err {
+			return nil, err
+		}
+		result = append(result, v)
+		if f.space(); f.pos < len(f.s) && ',' == f.s[f.pos] {
+			f.pos++
+		} else if f.pos >= len(f.s) || ']' != f.s[f.pos] {
+			return nil, ErrSyntax
+		}
+	}
+	if f.pos >= len(f.s) {
+		return nil, ErrSyntax
+	}
+	f.pos++
+	return result, nil
+}
+
+func (f *flow) mapping() (interface{}, error) {
+	m := tree.NewMap()
+	f.pos++
+	for f.space(); f.pos < len(f.s) && '}' != f.s[f.pos]; f.space() {
+		var key string
+		if c := f.s[f.pos]; '"' == c || '\'' == c {
+			k, n, err := quoted(f.s[f.pos:])
+			if nil != err {
+				return nil, err
+			}
+			key, f.pos = k, f.pos+n
+		} else {
+			key = f.scalar(true)
+		}
+		var v interface{}
+		if f.space(); f.pos < len(f.s) && ':' == f.s[f.pos] {
+			f.pos++
+			if f.space(); f.pos < len(f.s) && !strings.ContainsRune(",}", rune(f.s[f.pos])) {
+				var err error
+				if v, err = f.value(); nil != err {
+					return nil, err
+				}
+			}
+		}
+		m.Set(key, v)
+		if f.space(); f.pos < len(f.s) && ',' == f.s[f.pos] {
+			f.pos++
+		} else if f.pos >= len(f.s) || '}' != f.s[f.pos] {
+			return nil, ErrSyntax
+		}
+	}
+	if f.pos >= len(f.s) {
+		return nil, ErrSyntax
+	}
+	f.pos++
+	return m, nil
+}
+
+// scalar reads a plain flow scalar, a key also ends at a ': '
+func (f *flow) scalar(key bool) string {
+	start := f.pos
+	for ; f.pos < len(f.s); f.pos++ {
+		c := f.s[f.pos]
+		if strings.ContainsRune(",[]{}", rune(c)) {
+			break
+		}
+		if ':' == c && (key || f.pos+1 == len(f.s) || strings.ContainsRune(" ,[]{}", rune(f.s[f.pos+1]))) {
+			break
+		}
+	}
+	return strings.TrimSpace(f.s[start:f.pos])
+}
+
+// token reads an &anchor or *alias name
+func (f *flow) token() string {
+	start := f.pos + 1
+	for f.pos++; f.pos < len(f.s) && !strings.ContainsRune(" ,[]{}", rune(f.s[f.pos])); f.pos++ {
+	}
+	return f.s[start:f.pos]
+}
+
+func (f *flow) space() {
+	for f.pos < len(f.s) && (' ' == f.s[f.pos] || '\t' == f.s[f.pos]) {
+		f.pos++
+	}
+}
+
+// indentOf returns the number of leading spaces
+func indentOf(l string) int {
+	return len(l) - len(strings.TrimLeft(l, " "))
+}
+
+func isSeqItem(content string) bool {
+	return "-" == content || strings.HasPrefix(content, "- ")
+}
+
+// splitKey splits a 'key: value' line, the key may be quoted
+func splitKey(content string) (key, rest string, ok bool) {
+	if "" == content || isSeqItem(content) || strings.ContainsRune("[{?|>*&!%@`#", rune(content[0])) {
+		return "", "", false
+	}
+	after := ""
+	if '"' == content[0] || '\'' == content[0] {
+		k, n, err := quoted(content)
+		if nil != err {
+			return "", "", false
+		}
+		key, after = k, content[n:]
+		if a := strings.TrimLeft(after, " "); strings.HasPrefix(a, ":") {
+			after = a
+		}
+	} else {
+		i := strings.Index(content, ": ")
+		if i < 0 && strings.HasSuffix(content, ":") {
+			i = len(content) - 1
+		}
+		if i < 0 {
+			return "", "", false
+		}
+		key, after = strings.TrimSpace(content[:i]), content[i:]
+	}
+	if !strings.HasPrefix(after, ":") || (len(after) > 1 && ' ' != after[1]) {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(after[1:]), true
+}
+
+// props removes any &anchor and !tag from the start of a value
+func props(rest string) (string, string, string) {
+	anchor, tag := "", ""
+	for "" != rest && ('&' == rest[0] || '!' == rest[0]) {
+		token := rest
+		if i := strings.IndexAny(rest, " \t"); i >= 0 {
+			token = rest[:i]
+		}
+		if '&' == rest[0] {
+			anchor = token[1:]
+		} else {
+			tag = token
+		}
+		rest = strings.TrimSpace(rest[len(token):])
+	}
+	return rest, anchor, tag
+}
+
+// stripComment removes a trailing comment, a # at the start or after
+// whitespace that isn't inside quotes
+func stripComment(s string) string {
+	var q byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 0 != q:
+			if '\\' == c && '"' == q {
+				i++
+			} else if c == q {
+				q = 0
+			}
+		case ('"' == c || '\'' == c) && (0 == i || strings.ContainsRune(" \t:[{,", rune(s[i-1]))):
+			q = c
+		case '#' == c && (0 == i || ' ' == s[i-1] || '\t' == s[i-1]):
+			return strings.TrimRight(s[:i], " \t")
+		}
+	}
+	return strings.TrimRight(s, " \t")
+}
+
+// balanced reports whether the brackets of a flow collection are closed
+func balanced(s string) bool {
+	depth := 0
+	var q byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 0 != q:
+			if '\\' == c && '"' == q {
+				i++
+			} else if c == q {
+				q = 0
+			}
+		case '"' == c || '\'' == c:
+			q = c
+		case '[' == c || '{' == c:
+			depth++
+		case ']' == c || '}' == c:
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// quoted reads the quoted scalar at the start of s, returning it and the
+// length of s used
+func quoted(s string) (string, int, error) {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case '"' == q && '\\' == s[i]:
+			i++
+		case '\'' == q && '\'' == s[i] && i+1 < len(s) && '\'' == s[i+1]:
+			i++
+		case q == s[i]:
+			inner := s[1:i]
+			if '\'' == q {
+				return strings.ReplaceAll(inner, "''", "'"), i + 1, nil
+			}
+			if v, err := strconv.Unquote(`"` + inner + `"`); nil == err {
+				return v, i + 1, nil
+			}
+			return inner, i + 1, nil
+		}
+	}
+	return "", 0, ErrSyntax
+}
+
+// plain returns the value of a plain scalar, nil for a null
+func plain(s string) interface{} {
+	switch s = strings.TrimSpace(s); s {
+	case "~", "null", "Null", "NULL":
+		return nil
+	}
+	return s
+}
+
+// fold joins the lines of a > block scalar, a single line break becomes a
+// space while blank and more indented lines keep their line breaks
+func fold(lines []string) string {
+	more := func(l string) bool {
+		return "" != l && (' ' == l[0] || '\t' == l[0])
+	}
+	s := ""
+	for i, l := range lines {
+		switch {
+		case 0 == i:
+			s = l
+		case "" == l:
+			s += "\n"
+		case "" == lines[i-1]:
+			if more(l) {
+				s += "\n"
+			}
+			s += l
+		default:
+			if more(l) || more(lines[i-1]) {
+				s += "\n" + l
+			} else {
+				s += " " + l
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,110 @@
+package yaml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+)
+
+func TestToConfig(t *testing.T) {
+	src := `%YAML 1.2
+---
+# application settings
+name: app   # trailing comment
+port: 8080
+empty:
+quoted: "a # b\tc"
+single: 'it''s'
+url: http://x:80/path
+base: &base
+  host: localhost
+  tags: [a, "b c", {k: v}]
+prod:
+  <<: *base
+  host: prod.example
+servers:
+  - host: a
+    port: 1
+  - host: b
+list:
+- one
+- two
+pairs:
+  - [a, b]
+  - - c
+    - d
+motd: |
+  hello
+
+    world
+folded: >-
+  one
+  two
+
+  three
+seed: !!binary |
+  AP8=
+plain: first
+  second
+...
+ignored: yes
+`
+	c, err := ToConfig([]byte(src))
+	if nil == err || !errors.Is(err, ErrMixedSequence) {
+		dbg.Error("ToConfig mixed sequence: %v", err)
+		t.Fail()
+	}
+	src = strings.Replace(src, `tags: [a, "b c", {k: v}]`, `tags: [a, "b c"]`, 1)
+	c, err = ToConfig([]byte(src))
+	if nil != err {
+		dbg.Error("ToConfig: %v", err)
+		t.FailNow()
+	}
+	checks := map[string]string{
+		"name": "app", "port": "8080", "empty": "", "quoted": "a # b\tc", "single": "it's",
+		"url": "http://x:80/path", "prod:host": "prod.example", "base:host": "localhost",
+		"servers[1]:host": "b", "motd": "hello\n\n  world\n", "folded": "one two\nthree",
+		"plain": "first second",
+	}
+	for path, v := range checks {
+		if got, ok := c.Value(path); !ok || v != got {
+			dbg.Error("ToConfig %s: %q", path, got)
+			t.Fail()
+		}
+	}
+	if nil != c.Lookup("ignored") {
+		dbg.Error("ToConfig read past the end of the document")
+		t.Fail()
+	}
+	if tags, _ := c.GetStringList("prod:tags"); 2 != len(tags) || "b c" != tags[1] {
+		dbg.Error("ToConfig merged tags: %v", tags)
+		t.Fail()
+	}
+	if l, _ := c.GetStringList("list"); 2 != len(l) || "two" != l[1] {
+		dbg.Error("ToConfig list: %v", l)
+		t.Fail()
+	}
+	if l, _ := c.GetStringLists("pairs"); 2 != len(l) || "d" != l[1][1] {
+		dbg.Error("ToConfig pairs: %v", l)
+		t.Fail()
+	}
+	if b, _ := c.Binary("seed"); "\x00\xff" != string(b) {
+		dbg.Error("ToConfig seed: %q", b)
+		t.Fail()
+	}
+	// properties of a null, which mustn't panic
+	for _, null := range []string{"a:\n  &x\n", "&a", "!!str", "a: [&x, *x]\n"} {
+		if _, err := ToConfig([]byte(null)); nil != err {
+			dbg.Error("ToConfig %q: %v", null, err)
+			t.Fail()
+		}
+	}
+	for _, bad := range []string{"- a\n- b\n", "a: *none\n", "a: [1, 2\n", "a: b\n c: d\n  - x\n", "a: [&x"} {
+		if _, err := ToConfig([]byte(bad)); nil == err {
+			dbg.Error("ToConfig %q didn't fail", bad)
+			t.Fail()
+		}
+	}
+}
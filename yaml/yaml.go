@@ -0,0 +1,141 @@
+/*
+	Package yaml converts cfg config data to YAML, e.g. to migrate to it or
+	 to feed standard tooling, and YAML documents to cfg configs; it needs no
+	 YAML library.  Values are always written as strings, multi-line blocks
+	 as literal |- scalars where that keeps the text exactly
+*/
+package yaml
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/jayacarlson/cfg"
+	"github.com/jayacarlson/cfg/internal/tree"
+)
+
+var (
+	plainKeyRex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+)
+
+/*
+	Returns the config as a YAML document, see tree.Build for the mapping
+	 of each type of config data
+*/
+func Marshal(c *cfg.Config) ([]byte, error) {
+	m, err := tree.Build(c)
+	if nil != err {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if 0 == len(m.Keys) {
+		buf.WriteString("{}\n")
+	}
+	writeMap(&buf, m, "")
+	return buf.Bytes(), nil
+}
+
+/*
+	Parses the config data and returns it as YAML
+*/
+func Convert(data []byte) ([]byte, error) {
+	c, err := cfg.Parse(string(data))
+	if nil != err {
+		return nil, err
+	}
+	return Marshal(c)
+}
+
+// ------------------------------------------------------------------------- //
+
+func writeMap(buf *bytes.Buffer, m *tree.Map, indent string) {
+	for _, k := range m.Keys {
+		buf.WriteString(indent + key(k) + ":")
+		writeValue(buf, m.Values[k], indent)
+	}
+}
+
+// writeValue writes the value following a 'key:' or '-'
+func writeValue(buf *bytes.Buffer, v interface{}, indent string) {
+	switch v := v.(type) {
+	case string:
+		buf.WriteString(scalar(v, indent+"  ") + "\n")
+	case []byte:
+		buf.WriteString(" !!binary " + base64.StdEncoding.EncodeToString(v) + "\n")
+	case *tree.Map:
+		if 0 == len(v.Keys) {
+			buf.WriteString(" {}\n")
+			break
+		}
+		buf.WriteString("\n")
+		writeMap(buf, v, indent+"  ")
+	case []interface{}:
+		if 0 == len(v) {
+			buf.WriteString(" []\n")
+			break
+		}
+		buf.WriteString("\n")
+		for _, i := range v {
+			buf.WriteString(indent + "  -")
+			if m, ok := i.(*tree.Map); ok && 0 != len(m.Keys) {
+				// the first key goes on the '-' line
+				var item bytes.Buffer
+				writeMap(&item, m, indent+"    ")
+				buf.WriteString(" " + strings.TrimPrefix(item.String(), indent+"    "))
+				continue
+			}
+			writeValue(buf, i, indent+"  ")
+		}
+	}
+}
+
+// scalar returns a string as a literal block when that keeps the text,
+// otherwise double quoted
+func scalar(s, indent string) string {
+	literal := strings.Contains(s, "\n") && !strings.ContainsAny(s, "\r") &&
+		!strings.HasSuffix(s, "\n") && !strings.HasPrefix(s, " ") && !strings.HasPrefix(s, "\t")
+	lines := strings.Split(s, "\n")
+	for _, l := range lines {
+		if "" != l && "" == strings.TrimSpace(l) {
+			literal = false
+		}
+	}
+	if !literal {
+		return " " + quote(s)
+	}
+	for i, l := range lines {
+		if "" != l {
+			lines[i] = indent + l
+		}
+	}
+	return " |-\n" + strings.Join(lines, "\n")
+}
+
+func key(k string) string {
+	if plainKeyRex.MatchString(k) && !reserved(k) {
+		return k
+	}
+	return quote(k)
+}
+
+// reserved reports keys YAML would read as a bool or null
+func reserved(k string) bool {
+	switch strings.ToLower(k) {
+	case "y", "n", "yes", "no", "on", "off", "true", "false", "null":
+		return true
+	}
+	return false
+}
+
+// quote returns the string as a JSON string, which is a valid YAML double
+// quoted scalar
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
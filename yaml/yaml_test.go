@@ -0,0 +1,51 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jayacarlson/cfg"
+	"github.com/jayacarlson/dbg"
+)
+
+func TestConvert(t *testing.T) {
+	src := "name := app\n" +
+		"motd <\nhello\n\n  world\n>\n" +
+		"yes := \"quoted\" value\n" +
+		"server (\n\thost := a\n\tports {\n\t\t80 443\n\t}\n)\n" +
+		"server (\n\thost := b\n\tenv : [\n\t\tk : v\n\t]\n)\n" +
+		"seed <hex\n00 ff\n>\n" +
+		"none ( )\n"
+	want := `name: "app"
+motd: |-
+  hello
+
+    world
+"yes": "\"quoted\" value"
+server:
+  - host: "a"
+    ports:
+      - "80"
+      - "443"
+  - host: "b"
+    env:
+      k: "v"
+seed: !!binary AP8=
+none: {}
+`
+	data, err := Convert([]byte(src))
+	if nil != err {
+		dbg.Error("Convert: %v", err)
+		t.FailNow()
+	}
+	if want != string(data) {
+		dbg.Error("Convert gave:\n%s", data)
+		t.Fail()
+	}
+	c, _ := cfg.Parse(src)
+	back, err := ToConfig(data)
+	if nil != err || !reflect.DeepEqual(c.Flatten(), back.Flatten()) {
+		dbg.Error("ToConfig of the YAML gave:\n%v (%v)", back, err)
+		t.Fail()
+	}
+}